@@ -0,0 +1,128 @@
+package attest
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sparseth/bus"
+	"sparseth/ethstore"
+	"sparseth/log"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// signTimeout is the maximum duration
+// of signing a single attestation.
+const signTimeout = 10 * time.Second
+
+// domain separates attestations from
+// other messages signed by the same key.
+const domain = "sparseth attestation"
+
+// ErrInvalidSignature is returned if an
+// attestation is not signed by its signer.
+var ErrInvalidSignature = errors.New("invalid attestation signature")
+
+// Payload returns the message signed for the
+// specified attestation, which is the domain
+// followed by the block number, block hash,
+// account, and root.
+func Payload(a *ethstore.Attestation) []byte {
+	payload := make([]byte, 0, len(domain)+8+common.HashLength+common.AddressLength+common.HashLength)
+	payload = append(payload, domain...)
+	payload = binary.BigEndian.AppendUint64(payload, a.Number)
+	payload = append(payload, a.BlockHash.Bytes()...)
+	payload = append(payload, a.Account.Bytes()...)
+	payload = append(payload, a.Root.Bytes()...)
+	return payload
+}
+
+// Verify checks whether the specified attestation
+// is signed by its signer. The payload is signed
+// as an EIP-191 personal message, so third parties
+// can also verify it with common wallet tooling.
+func Verify(a *ethstore.Attestation) error {
+	if len(a.Signature) != crypto.SignatureLength {
+		return ErrInvalidSignature
+	}
+	pub, err := crypto.SigToPub(accounts.TextHash(Payload(a)), a.Signature)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidSignature, err)
+	}
+	if crypto.PubkeyToAddress(*pub) != a.Signer {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// Attester signs and stores an attestation
+// for each successful verification.
+type Attester struct {
+	verifications <-chan *bus.Verification
+	signer        Signer
+	store         *ethstore.AttestationStore
+	log           log.Logger
+}
+
+// NewAttester creates a new Attester that signs
+// the successful verifications received on the
+// specified channel with the specified signer,
+// and stores the attestations in the specified
+// store.
+func NewAttester(verifications <-chan *bus.Verification, signer Signer, store *ethstore.AttestationStore, log log.Logger) *Attester {
+	return &Attester{
+		verifications: verifications,
+		signer:        signer,
+		store:         store,
+		log:           log.With("component", "attester"),
+	}
+}
+
+// RunContext attests all successful verifications
+// until the context is canceled, or the channel is
+// closed. Failed attestations are logged.
+func (a *Attester) RunContext(ctx context.Context) error {
+	for {
+		select {
+		case v, ok := <-a.verifications:
+			if !ok {
+				return nil
+			}
+			if v.Failed() {
+				continue
+			}
+			if err := a.attest(ctx, v); err != nil {
+				a.log.Warn("failed to attest verification", "account", v.Account.Hex(), "num", v.Number, "err", err)
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// attest signs and stores an attestation
+// of the specified verification.
+func (a *Attester) attest(ctx context.Context, v *bus.Verification) error {
+	attestation := &ethstore.Attestation{
+		Number:    v.Number,
+		BlockHash: v.Hash,
+		Account:   v.Account,
+		Root:      v.Root,
+		Signer:    a.signer.Address(),
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, signTimeout)
+	defer cancel()
+
+	sig, err := a.signer.SignText(ctx, Payload(attestation))
+	if err != nil {
+		return err
+	}
+	attestation.Signature = sig
+
+	return a.store.Put(attestation)
+}
@@ -0,0 +1,78 @@
+package attest
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sparseth/bus"
+	"sparseth/ethstore"
+	"sparseth/internal/log"
+	"sparseth/storage/mem"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const testKey = "b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291"
+
+func newTestSigner(t *testing.T) *KeySigner {
+	path := filepath.Join(t.TempDir(), "key")
+	if err := os.WriteFile(path, []byte(testKey+"\n"), 0o600); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	signer, err := NewKeySigner(path, "")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	return signer
+}
+
+func TestAttester_RunContext(t *testing.T) {
+	t.Run("should sign and store successful verifications", func(t *testing.T) {
+		db := mem.New()
+		defer db.Close()
+
+		verifications := make(chan *bus.Verification, 2)
+		verifications <- &bus.Verification{Kind: bus.StateMismatch, Account: common.HexToAddress("0x01"), Number: 10}
+		verifications <- &bus.Verification{Kind: bus.VerificationOK, Account: common.HexToAddress("0x02"), Number: 10, Root: common.HexToHash("0x03")}
+		close(verifications)
+
+		signer := newTestSigner(t)
+		store := ethstore.NewAttestationStore(db)
+		if err := NewAttester(verifications, signer, store, log.New(slog.DiscardHandler)).RunContext(t.Context()); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		attestations, err := store.Get(10)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(attestations) != 1 || attestations[0].Account != common.HexToAddress("0x02") {
+			t.Fatalf("expected attestation of 0x02, got %+v", attestations)
+		}
+		if attestations[0].Signer != signer.Address() {
+			t.Errorf("expected signer %s, got %s", signer.Address().Hex(), attestations[0].Signer.Hex())
+		}
+		if err = Verify(attestations[0]); err != nil {
+			t.Errorf("expected valid signature, got %v", err)
+		}
+	})
+}
+
+func TestVerify(t *testing.T) {
+	t.Run("should reject tampered attestation", func(t *testing.T) {
+		signer := newTestSigner(t)
+		a := &ethstore.Attestation{Number: 10, Root: common.HexToHash("0x03"), Signer: signer.Address()}
+		sig, err := signer.SignText(t.Context(), Payload(a))
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		a.Signature = sig
+
+		a.Root = common.HexToHash("0x04")
+		if err = Verify(a); !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("expected %v, got %v", ErrInvalidSignature, err)
+		}
+	})
+}
@@ -0,0 +1,258 @@
+package attest
+
+import (
+	"context"
+	"fmt"
+	"sparseth/bus"
+	"sparseth/ethstore"
+	"sparseth/log"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// PeerLag is the number of blocks by which
+// comparisons trail the committed blocks, so
+// that peers have attested them as well.
+const PeerLag = 8
+
+// peerTimeout is the maximum duration of
+// fetching the attestations of a peer.
+const peerTimeout = 10 * time.Second
+
+// peerAttestation is the JSON encoding of an
+// attestation served by a peer, see the
+// stats_attestations method.
+type peerAttestation struct {
+	Number    hexutil.Uint64 `json:"number"`
+	BlockHash common.Hash    `json:"blockHash"`
+	Account   common.Address `json:"account"`
+	Root      common.Hash    `json:"root"`
+	Signer    common.Address `json:"signer"`
+	Signature hexutil.Bytes  `json:"signature"`
+}
+
+// PeerStatus is the status of a peer.
+type PeerStatus struct {
+	URL string
+	// Signer is the signer of the last
+	// valid attestation of the peer.
+	Signer common.Address
+	// Checked is the number of the last
+	// block compared with the peer.
+	Checked uint64
+	// Agreements and Divergences count the
+	// compared attestations with the same
+	// and a different root, respectively.
+	Agreements  uint64
+	Divergences uint64
+	// Err is the last error of fetching
+	// attestations, or empty.
+	Err string
+}
+
+// peer is a node serving attestations.
+type peer struct {
+	status PeerStatus
+	key    string
+	c      *rpc.Client
+}
+
+// attestations fetches the attestations of
+// the block with the specified number. The
+// peer is dialed on first use.
+func (p *peer) attestations(ctx context.Context, num uint64) ([]*peerAttestation, error) {
+	ctx, cancel := context.WithTimeout(ctx, peerTimeout)
+	defer cancel()
+
+	if p.c == nil {
+		var opts []rpc.ClientOption
+		if p.key != "" {
+			opts = append(opts, rpc.WithHeader("Authorization", "Bearer "+p.key))
+		}
+		c, err := rpc.DialOptions(ctx, p.status.URL, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to peer: %w", err)
+		}
+		p.c = c
+	}
+
+	var attestations []*peerAttestation
+	if err := p.c.CallContext(ctx, &attestations, "stats_attestations", hexutil.Uint64(num)); err != nil {
+		return nil, fmt.Errorf("failed to fetch attestations: %w", err)
+	}
+	return attestations, nil
+}
+
+// Federation compares the attestations of the
+// node with the attestations of its peers, i.e.,
+// other nodes monitoring the same accounts, and
+// raises an alert if a peer attests a different
+// root of an account at the same block.
+type Federation struct {
+	commits <-chan *bus.BlockCommit
+	peers   []*peer
+	store   *ethstore.AttestationStore
+	alerts  *bus.Topic[*bus.Alert]
+	log     log.Logger
+	mu      sync.Mutex
+}
+
+// NewFederation creates a new Federation that
+// compares the attestations in the specified
+// store with those served by the peers at the
+// specified URLs, using the specified API key,
+// if any, once the blocks received on the
+// specified channel are PeerLag blocks old.
+// Divergences are published to the specified
+// alerts topic.
+func NewFederation(commits <-chan *bus.BlockCommit, urls []string, key string, store *ethstore.AttestationStore, alerts *bus.Topic[*bus.Alert], log log.Logger) *Federation {
+	peers := make([]*peer, len(urls))
+	for i, url := range urls {
+		peers[i] = &peer{
+			status: PeerStatus{URL: url},
+			key:    key,
+		}
+	}
+	return &Federation{
+		commits: commits,
+		peers:   peers,
+		store:   store,
+		alerts:  alerts,
+		log:     log.With("component", "federation"),
+	}
+}
+
+// RunContext compares the attestations of all
+// committed blocks until the context is canceled,
+// or the commits channel is closed.
+func (f *Federation) RunContext(ctx context.Context) error {
+	defer f.close()
+
+	for {
+		select {
+		case commit, ok := <-f.commits:
+			if !ok {
+				return nil
+			}
+			if commit.Number < PeerLag {
+				continue
+			}
+			f.compare(ctx, commit.Number-PeerLag)
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// Status returns the status of each peer.
+func (f *Federation) Status() []PeerStatus {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	statuses := make([]PeerStatus, len(f.peers))
+	for i, p := range f.peers {
+		statuses[i] = p.status
+	}
+	return statuses
+}
+
+// compare compares the attestations of the block
+// with the specified number with those of all
+// peers. Blocks the node did not attest, e.g.,
+// as they failed to verify, are skipped.
+func (f *Federation) compare(ctx context.Context, num uint64) {
+	own, err := f.store.Get(num)
+	if err != nil {
+		f.log.Warn("failed to load attestations", "num", num, "err", err)
+		return
+	}
+	if len(own) == 0 {
+		return
+	}
+	attested := make(map[common.Address]*ethstore.Attestation, len(own))
+	for _, a := range own {
+		attested[a.Account] = a
+	}
+
+	for _, p := range f.peers {
+		theirs, err := p.attestations(ctx, num)
+		f.mu.Lock()
+		p.status.Checked = num
+		if err != nil {
+			p.status.Err = err.Error()
+			f.mu.Unlock()
+			f.log.Debug("failed to compare attestations", "peer", p.status.URL, "num", num, "err", err)
+			continue
+		}
+		p.status.Err = ""
+		for _, a := range theirs {
+			f.compareOne(p, attested[a.Account], a)
+		}
+		f.mu.Unlock()
+	}
+}
+
+// compareOne compares the specified attestation
+// of the specified peer with the specified one
+// of the node, which may be nil.
+func (f *Federation) compareOne(p *peer, own *ethstore.Attestation, a *peerAttestation) {
+	theirs := &ethstore.Attestation{
+		Number:    uint64(a.Number),
+		BlockHash: a.BlockHash,
+		Account:   a.Account,
+		Root:      a.Root,
+		Signer:    a.Signer,
+		Signature: a.Signature,
+	}
+	if err := Verify(theirs); err != nil {
+		f.raise(theirs, fmt.Sprintf("peer %s served an invalid attestation: %v", p.status.URL, err), nil)
+		return
+	}
+	p.status.Signer = theirs.Signer
+
+	if own == nil || own.BlockHash != theirs.BlockHash {
+		// Not monitored by the node,
+		// or attested on another fork
+		return
+	}
+	if own.Root == theirs.Root {
+		p.status.Agreements++
+		return
+	}
+
+	p.status.Divergences++
+	f.log.Warn("peer attested a different root", "peer", p.status.URL, "signer", theirs.Signer.Hex(), "account", own.Account.Hex(), "num", own.Number, "root", own.Root.Hex(), "peerRoot", theirs.Root.Hex())
+	f.raise(theirs, fmt.Sprintf("peer %s (%s) attested a different root", p.status.URL, theirs.Signer.Hex()), own)
+}
+
+// raise publishes an alert concerning the
+// specified attestation of a peer, compared
+// with the specified one of the node, if any.
+func (f *Federation) raise(theirs *ethstore.Attestation, msg string, own *ethstore.Attestation) {
+	alert := &bus.Alert{
+		Source:  "federation",
+		Account: theirs.Account,
+		Number:  theirs.Number,
+		Hash:    theirs.BlockHash,
+		Message: msg,
+	}
+	if own != nil {
+		alert.Kind = "root"
+		alert.Expected = own.Root.Hex()
+		alert.Actual = theirs.Root.Hex()
+	}
+	f.alerts.Publish(alert)
+}
+
+// close closes the connections to all peers.
+func (f *Federation) close() {
+	for _, p := range f.peers {
+		if p.c != nil {
+			p.c.Close()
+		}
+	}
+}
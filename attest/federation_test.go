@@ -0,0 +1,104 @@
+package attest
+
+import (
+	"log/slog"
+	"net/http/httptest"
+	"sparseth/bus"
+	"sparseth/ethstore"
+	"sparseth/internal/log"
+	"sparseth/storage/mem"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// testPeerAPI serves the attestations
+// of a peer in the stats namespace.
+type testPeerAPI struct {
+	attestations []*peerAttestation
+}
+
+func (api *testPeerAPI) Attestations(hexutil.Uint64) []*peerAttestation {
+	return api.attestations
+}
+
+func newTestPeer(t *testing.T, signer *KeySigner, roots map[common.Address]common.Hash) string {
+	api := &testPeerAPI{}
+	for addr, root := range roots {
+		a := &ethstore.Attestation{Number: 10, BlockHash: common.HexToHash("0x0a"), Account: addr, Root: root, Signer: signer.Address()}
+		sig, err := signer.SignText(t.Context(), Payload(a))
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		api.attestations = append(api.attestations, &peerAttestation{
+			Number:    hexutil.Uint64(a.Number),
+			BlockHash: a.BlockHash,
+			Account:   a.Account,
+			Root:      a.Root,
+			Signer:    a.Signer,
+			Signature: sig,
+		})
+	}
+
+	server := rpc.NewServer()
+	if err := server.RegisterName("stats", api); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	srv := httptest.NewServer(server)
+	t.Cleanup(func() {
+		srv.Close()
+		server.Stop()
+	})
+	return srv.URL
+}
+
+func TestFederation_RunContext(t *testing.T) {
+	agreed := common.HexToAddress("0x01")
+	diverged := common.HexToAddress("0x02")
+
+	t.Run("should alert on different root of peer", func(t *testing.T) {
+		db := mem.New()
+		defer db.Close()
+
+		store := ethstore.NewAttestationStore(db)
+		for _, addr := range []common.Address{agreed, diverged} {
+			err := store.Put(&ethstore.Attestation{Number: 10, BlockHash: common.HexToHash("0x0a"), Account: addr, Root: common.HexToHash("0x0b")})
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+		}
+
+		url := newTestPeer(t, newTestSigner(t), map[common.Address]common.Hash{
+			agreed:   common.HexToHash("0x0b"),
+			diverged: common.HexToHash("0x0c"),
+		})
+
+		alerts := bus.NewTopic[*bus.Alert]("alerts", log.New(slog.DiscardHandler))
+		received := alerts.Subscribe("test")
+
+		commits := make(chan *bus.BlockCommit, 1)
+		commits <- &bus.BlockCommit{Number: 10 + PeerLag}
+		close(commits)
+
+		f := NewFederation(commits, []string{url}, "", store, alerts, log.New(slog.DiscardHandler))
+		if err := f.RunContext(t.Context()); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		select {
+		case alert := <-received:
+			if alert.Account != diverged || alert.Kind != "root" || alert.Actual != common.HexToHash("0x0c").Hex() {
+				t.Errorf("expected root alert of %s, got %+v", diverged.Hex(), alert)
+			}
+		default:
+			t.Fatalf("expected alert, got none")
+		}
+
+		status := f.Status()[0]
+		if status.Agreements != 1 || status.Divergences != 1 || status.Checked != 10 || status.Err != "" {
+			t.Errorf("expected 1 agreement and 1 divergence at block 10, got %+v", status)
+		}
+	})
+}
@@ -0,0 +1,124 @@
+package attest
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Signer is the interface of a
+// key that signs attestations.
+type Signer interface {
+	// Address returns the address
+	// of the signing key.
+	Address() common.Address
+
+	// SignText signs the specified data as
+	// an EIP-191 personal message, and
+	// returns the 65-byte signature with
+	// a recovery id of 0 or 1.
+	SignText(ctx context.Context, data []byte) ([]byte, error)
+}
+
+// KeySigner is a Signer that holds
+// the private key in memory.
+type KeySigner struct {
+	key *ecdsa.PrivateKey
+}
+
+// NewKeySigner creates a new KeySigner from
+// the private key in the file at the specified
+// path, either hex-encoded, or an encrypted
+// keystore file that is decrypted with the
+// specified password.
+func NewKeySigner(path, password string) (*KeySigner, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		key, err := keystore.DecryptKey(data, password)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt key file: %w", err)
+		}
+		return &KeySigner{key: key.PrivateKey}, nil
+	}
+
+	key, err := crypto.HexToECDSA(strings.TrimPrefix(strings.TrimSpace(string(data)), "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse key file: %w", err)
+	}
+	return &KeySigner{key: key}, nil
+}
+
+// Address implements Signer.
+func (s *KeySigner) Address() common.Address {
+	return crypto.PubkeyToAddress(s.key.PublicKey)
+}
+
+// SignText implements Signer.
+func (s *KeySigner) SignText(_ context.Context, data []byte) ([]byte, error) {
+	return crypto.Sign(accounts.TextHash(data), s.key)
+}
+
+// ExternalSigner is a Signer that delegates to
+// an external signer implementing the account_
+// namespace of Clef, e.g., one backed by a KMS
+// or HSM, so that the key never enters the node.
+type ExternalSigner struct {
+	c    *rpc.Client
+	addr common.Address
+}
+
+// NewExternalSigner creates a new ExternalSigner
+// that signs with the account of the specified
+// address via the signer at the specified URL.
+func NewExternalSigner(ctx context.Context, url string, addr common.Address) (*ExternalSigner, error) {
+	c, err := rpc.DialContext(ctx, url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to external signer: %w", err)
+	}
+	return &ExternalSigner{
+		c:    c,
+		addr: addr,
+	}, nil
+}
+
+// Address implements Signer.
+func (s *ExternalSigner) Address() common.Address {
+	return s.addr
+}
+
+// SignText implements Signer.
+func (s *ExternalSigner) SignText(ctx context.Context, data []byte) ([]byte, error) {
+	var sig hexutil.Bytes
+	if err := s.c.CallContext(ctx, &sig, "account_signData", accounts.MimetypeTextPlain, s.addr, hexutil.Bytes(data)); err != nil {
+		return nil, fmt.Errorf("failed to sign with external signer: %w", err)
+	}
+	if len(sig) != crypto.SignatureLength {
+		return nil, fmt.Errorf("invalid signature length %d", len(sig))
+	}
+	// Clef returns the recovery
+	// id in Ethereum's 27/28 form
+	if sig[crypto.RecoveryIDOffset] >= 27 {
+		sig[crypto.RecoveryIDOffset] -= 27
+	}
+	return sig, nil
+}
+
+// Close closes the connection
+// to the external signer.
+func (s *ExternalSigner) Close() {
+	s.c.Close()
+}
@@ -0,0 +1,92 @@
+package bus
+
+import (
+	"cmp"
+	"slices"
+	"sparseth/log"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Bus is the internal event bus of the node.
+// Subsystems publish events to its topics,
+// and any subsystem may subscribe to them
+// without further wiring.
+type Bus struct {
+	// Headers receives all confirmed block
+	// headers, in order.
+	Headers *Topic[*types.Header]
+	// Upcoming receives held back block headers
+	// shortly before they are published to
+	// Headers, e.g., to prefetch block data.
+	Upcoming *Topic[*types.Header]
+	// Results receives the result of each
+	// block processed by a monitor.
+	Results *Topic[*Result]
+	// Commits receives all blocks processed
+	// by all monitors, in order.
+	Commits *Topic[*BlockCommit]
+	// Persisted receives all committed blocks,
+	// in order, once their digest is persisted.
+	Persisted *Topic[*BlockCommit]
+	// Alerts receives conditions that
+	// require the operator's attention.
+	Alerts *Topic[*Alert]
+	// Verifications receives the outcome of
+	// verifying each account at each block.
+	Verifications *Topic[*Verification]
+	// Sync receives changes of the
+	// block sync status.
+	Sync *Topic[*SyncStatus]
+	log  log.Logger
+}
+
+// New creates a new Bus with
+// empty topics.
+func New(log log.Logger) *Bus {
+	return &Bus{
+		Headers:       NewTopic[*types.Header]("headers", log),
+		Upcoming:      NewTopic[*types.Header]("upcoming", log),
+		Results:       NewTopic[*Result]("results", log),
+		Commits:       NewTopic[*BlockCommit]("commits", log),
+		Persisted:     NewTopic[*BlockCommit]("persisted", log),
+		Alerts:        NewTopic[*Alert]("alerts", log),
+		Verifications: NewTopic[*Verification]("verifications", log),
+		Sync:          NewTopic[*SyncStatus]("sync", log),
+		log:           log.With("component", "bus"),
+	}
+}
+
+// Stats returns the queue statistics of all
+// subscribers of all topics, by topic and id.
+func (b *Bus) Stats() []*QueueStats {
+	var stats []*QueueStats
+	stats = append(stats, b.Headers.Stats()...)
+	stats = append(stats, b.Upcoming.Stats()...)
+	stats = append(stats, b.Results.Stats()...)
+	stats = append(stats, b.Commits.Stats()...)
+	stats = append(stats, b.Persisted.Stats()...)
+	stats = append(stats, b.Alerts.Stats()...)
+	stats = append(stats, b.Verifications.Stats()...)
+	stats = append(stats, b.Sync.Stats()...)
+
+	slices.SortFunc(stats, func(a, b *QueueStats) int {
+		return cmp.Or(cmp.Compare(a.Topic, b.Topic), cmp.Compare(a.ID, b.ID))
+	})
+	return stats
+}
+
+// Close closes all subscriber
+// channels of all topics.
+func (b *Bus) Close() {
+	b.log.Info("shutting down")
+
+	b.Headers.Close()
+	b.Upcoming.Close()
+	b.Results.Close()
+	b.Commits.Close()
+	b.Persisted.Close()
+	b.Alerts.Close()
+	b.Verifications.Close()
+	b.Sync.Close()
+}
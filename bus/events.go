@@ -0,0 +1,201 @@
+package bus
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"slices"
+)
+
+// Result is the result of a
+// block processed by a monitor.
+type Result struct {
+	// Monitor is the name of the monitor
+	// that processed the block.
+	Monitor string
+	// Number is the block number.
+	Number uint64
+	// Hash is the block hash.
+	Hash common.Hash
+	// Err is the processing error,
+	// or nil if the block is verified.
+	Err error
+	// Providers lists the URLs of the RPC
+	// providers that served data for the
+	// block.
+	Providers []string
+	// Digest is the digest of the verified
+	// outputs of the block, or zero if the
+	// block is not verified.
+	Digest common.Hash
+	// Activity counts the verified events or
+	// re-executed transactions of the block
+	// per account, or is nil if the block is
+	// not verified.
+	Activity map[common.Address]uint64
+}
+
+// Verified checks whether the
+// block has been verified.
+func (r *Result) Verified() bool {
+	return r.Err == nil
+}
+
+// BlockCommit is a block that has been
+// processed by all monitors.
+type BlockCommit struct {
+	// Number is the block number.
+	Number uint64
+	// Hash is the block hash.
+	Hash common.Hash
+	// Results holds the result of
+	// each monitor for the block.
+	Results []*Result
+	// Monitors lists the monitors expected
+	// to process the block, by name.
+	Monitors []string
+	// Digest is the digest of the block
+	// over the digests of all results.
+	Digest common.Hash
+}
+
+// Verified checks whether the block has
+// been verified by all monitors. A block
+// without the result of an expected
+// monitor is not verified.
+func (c *BlockCommit) Verified() bool {
+	if len(c.Missing()) > 0 {
+		return false
+	}
+	for _, r := range c.Results {
+		if !r.Verified() {
+			return false
+		}
+	}
+	return true
+}
+
+// Missing returns the names of all expected
+// monitors without a result for the block.
+func (c *BlockCommit) Missing() []string {
+	var missing []string
+	for _, name := range c.Monitors {
+		if !slices.ContainsFunc(c.Results, func(r *Result) bool { return r.Monitor == name }) {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
+// Alert is a condition that requires
+// the operator's attention, e.g., a
+// state mismatch of an account.
+type Alert struct {
+	// Source is the name of the
+	// component raising the alert.
+	Source string
+	// Account is the affected account,
+	// or the zero address if none.
+	Account common.Address
+	// Number is the number of the
+	// block the alert refers to.
+	Number uint64
+	// Hash is the hash of the block the
+	// alert refers to, or zero if unknown.
+	Hash common.Hash
+	// Message describes the condition.
+	Message string
+	// Kind names the mismatched value of a
+	// failed verification, e.g., balance,
+	// or is empty for other conditions.
+	Kind string
+	// Expected and Actual are the proven and
+	// the derived value of a failed verification.
+	Expected string
+	Actual   string
+}
+
+// VerificationKind is the outcome of
+// verifying an account at a block.
+type VerificationKind string
+
+const (
+	// VerificationOK indicates that the derived
+	// outputs of the account match the proven
+	// state.
+	VerificationOK VerificationKind = "ok"
+	// StateMismatch indicates that the re-executed
+	// state of the account differs from the proven
+	// state, e.g., its balance.
+	StateMismatch VerificationKind = "state-mismatch"
+	// EventChainMismatch indicates that the hash
+	// chain over the events of the account differs
+	// from the proven hash chain head.
+	EventChainMismatch VerificationKind = "event-chain-mismatch"
+	// UninitializedReadViolation indicates that
+	// re-execution read a value that was neither
+	// proven nor allowlisted.
+	UninitializedReadViolation VerificationKind = "uninitialized-read-violation"
+)
+
+// Verification is the outcome of verifying
+// an account at a block, as published by
+// all processors.
+type Verification struct {
+	Kind VerificationKind
+	// Source is the name of the
+	// verifying component.
+	Source string
+	// Account is the verified account,
+	// or the zero address if unknown.
+	Account common.Address
+	// Number is the block number.
+	Number uint64
+	// Hash is the block hash.
+	Hash common.Hash
+	// Message describes a failed
+	// verification, or is empty.
+	Message string
+	// Value names the mismatched value of a
+	// failed verification, e.g., balance, and
+	// Expected and Actual are the proven and
+	// the derived value, if known.
+	Value    string
+	Expected string
+	Actual   string
+	// Root is the verified storage root in
+	// sparse mode, or the verified hash chain
+	// head in event mode, if verified.
+	Root common.Hash
+}
+
+// Failed checks whether the
+// verification failed.
+func (v *Verification) Failed() bool {
+	return v.Kind != VerificationOK
+}
+
+// SyncState is the state of the block sync.
+type SyncState string
+
+const (
+	// SyncStateSyncing indicates that past block
+	// headers are downloaded, see SyncStatus.
+	SyncStateSyncing SyncState = "syncing"
+	// SyncStateFollowing indicates that new block
+	// headers are received as they are produced.
+	SyncStateFollowing SyncState = "following"
+	// SyncStateStalled indicates that no new block
+	// header has been received for a while.
+	SyncStateStalled SyncState = "stalled"
+	// SyncStateReconnecting indicates that the
+	// block header subscription was lost.
+	SyncStateReconnecting SyncState = "reconnecting"
+)
+
+// SyncStatus is the status of the block sync.
+type SyncStatus struct {
+	// State is the current sync state.
+	State SyncState
+	// Head is the number of the last
+	// published block header.
+	Head uint64
+}
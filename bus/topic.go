@@ -0,0 +1,290 @@
+package bus
+
+import (
+	"errors"
+	"fmt"
+	"sparseth/log"
+	"sync"
+	"sync/atomic"
+)
+
+// subscriptionBuffer is the default number
+// of events buffered per subscriber.
+const subscriptionBuffer = 1024
+
+// OverflowPolicy decides what happens once the
+// queue of a subscriber is full.
+type OverflowPolicy string
+
+const (
+	// DropNewest drops the published event for
+	// the subscriber, so that the producer never
+	// blocks.
+	DropNewest OverflowPolicy = "drop-newest"
+	// DropOldest drops the oldest queued event in
+	// favor of the published one, so that the
+	// subscriber always receives the latest
+	// events, and backfills dropped events
+	// itself, e.g., from the store.
+	DropOldest OverflowPolicy = "drop-oldest"
+	// Block blocks the producer until the
+	// subscriber has room, so that no event
+	// is dropped.
+	Block OverflowPolicy = "block"
+)
+
+// ErrUnknownPolicy is returned when an
+// unsupported overflow policy is requested.
+var ErrUnknownPolicy = errors.New("unknown overflow policy")
+
+// ParsePolicy parses the specified
+// overflow policy.
+func ParsePolicy(s string) (OverflowPolicy, error) {
+	switch p := OverflowPolicy(s); p {
+	case DropNewest, DropOldest, Block:
+		return p, nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnknownPolicy, s)
+	}
+}
+
+// Options configures the queue of
+// a single subscriber.
+type Options struct {
+	// Size is the capacity of the queue,
+	// subscriptionBuffer applies if zero.
+	Size int
+	// Policy applies once the queue is full,
+	// DropNewest applies if empty.
+	Policy OverflowPolicy
+}
+
+// QueueStats describes the queue
+// of a single subscriber.
+type QueueStats struct {
+	Topic  string
+	ID     string
+	Policy OverflowPolicy
+	// Depth is the number of queued events.
+	Depth    int
+	Capacity int
+	// Dropped is the number of events dropped
+	// since the subscriber subscribed.
+	Dropped uint64
+}
+
+// subscription is the bounded
+// queue of a single subscriber.
+type subscription[T any] struct {
+	ch      chan T
+	policy  OverflowPolicy
+	dropped atomic.Uint64
+	// done is closed on unsubscribe, so
+	// that a blocked producer returns.
+	done   chan struct{}
+	once   sync.Once
+	mu     sync.Mutex
+	closed bool
+}
+
+// send enqueues the specified event according
+// to the overflow policy, and reports whether
+// an event was dropped.
+func (s *subscription[T]) send(ev T) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return false
+	}
+
+	switch s.policy {
+	case Block:
+		select {
+		case s.ch <- ev:
+		case <-s.done:
+		}
+		return false
+	case DropOldest:
+		var dropped bool
+		for {
+			select {
+			case s.ch <- ev:
+				return dropped
+			default:
+			}
+			select {
+			case <-s.ch:
+				s.dropped.Add(1)
+				dropped = true
+			default:
+				// Drained concurrently
+			}
+		}
+	default:
+		select {
+		case s.ch <- ev:
+			return false
+		default:
+			s.dropped.Add(1)
+			return true
+		}
+	}
+}
+
+// close closes the queue, after
+// unblocking a blocked producer.
+func (s *subscription[T]) close() {
+	s.once.Do(func() {
+		close(s.done)
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		s.closed = true
+		close(s.ch)
+	})
+}
+
+// Topic broadcasts events of a single type
+// to multiple subscribers.
+//
+// Each subscriber has a bounded queue, and an
+// overflow policy that applies once its queue
+// is full. By default, events are dropped for
+// subscribers that fall behind, so that
+// publishing never blocks.
+type Topic[T any] struct {
+	name string
+	subs map[string]*subscription[T]
+	log  log.Logger
+	mu   sync.Mutex
+	// pubMu serializes publishers, so that all
+	// subscribers receive events in order, even
+	// while a producer is blocked.
+	pubMu sync.Mutex
+}
+
+// NewTopic returns a new topic with the
+// specified name and no subscriptions.
+func NewTopic[T any](name string, log log.Logger) *Topic[T] {
+	return &Topic[T]{
+		name: name,
+		subs: make(map[string]*subscription[T]),
+		log:  log.With("component", "bus", "topic", name),
+	}
+}
+
+// Subscribe registers a new subscriber to receive
+// events, with the default options. If the specified
+// id is already subscribed, the existing channel
+// is returned.
+func (t *Topic[T]) Subscribe(id string) <-chan T {
+	return t.SubscribeWith(id, Options{})
+}
+
+// SubscribeWith registers a new subscriber to receive
+// events, with the specified queue options. If the
+// specified id is already subscribed, the existing
+// channel is returned.
+func (t *Topic[T]) SubscribeWith(id string, opts Options) <-chan T {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if sub, exists := t.subs[id]; exists {
+		return sub.ch
+	}
+
+	if opts.Size <= 0 {
+		opts.Size = subscriptionBuffer
+	}
+	if opts.Policy == "" {
+		opts.Policy = DropNewest
+	}
+
+	t.log.Info("new subscription", "id", id, "size", opts.Size, "policy", opts.Policy)
+	sub := &subscription[T]{
+		ch:     make(chan T, opts.Size),
+		policy: opts.Policy,
+		done:   make(chan struct{}),
+	}
+	t.subs[id] = sub
+	return sub.ch
+}
+
+// Unsubscribe removes the subscriber with the
+// given id and closes its channel. If no
+// subscriber with the specified id exists,
+// Unsubscribe does nothing.
+func (t *Topic[T]) Unsubscribe(id string) {
+	t.mu.Lock()
+	sub, exists := t.subs[id]
+	delete(t.subs, id)
+	t.mu.Unlock()
+
+	if exists {
+		t.log.Info("unsubscribe", "id", id)
+		sub.close()
+	}
+}
+
+// Publish sends the specified event to all
+// active subscribers. Publishing to a nil
+// topic does nothing.
+//
+// Publish blocks while the queue of any
+// subscriber with the Block policy is full.
+func (t *Topic[T]) Publish(ev T) {
+	if t == nil {
+		return
+	}
+
+	t.pubMu.Lock()
+	defer t.pubMu.Unlock()
+
+	t.mu.Lock()
+	subs := make(map[string]*subscription[T], len(t.subs))
+	for id, sub := range t.subs {
+		subs[id] = sub
+	}
+	t.mu.Unlock()
+
+	for id, sub := range subs {
+		if sub.send(ev) {
+			t.log.Warn("dropping event for subscriber", "id", id, "policy", sub.policy)
+		}
+	}
+}
+
+// Stats returns the queue statistics
+// of all subscribers, in no order.
+func (t *Topic[T]) Stats() []*QueueStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	stats := make([]*QueueStats, 0, len(t.subs))
+	for id, sub := range t.subs {
+		stats = append(stats, &QueueStats{
+			Topic:    t.name,
+			ID:       id,
+			Policy:   sub.policy,
+			Depth:    len(sub.ch),
+			Capacity: cap(sub.ch),
+			Dropped:  sub.dropped.Load(),
+		})
+	}
+	return stats
+}
+
+// Close closes and removes all
+// subscriber channels.
+func (t *Topic[T]) Close() {
+	t.mu.Lock()
+	subs := t.subs
+	t.subs = make(map[string]*subscription[T])
+	t.mu.Unlock()
+
+	for _, sub := range subs {
+		sub.close()
+	}
+}
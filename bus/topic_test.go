@@ -0,0 +1,192 @@
+package bus
+
+import (
+	"github.com/ethereum/go-ethereum/core/types"
+	"log/slog"
+	"math/big"
+	"sparseth/internal/log"
+	"testing"
+	"time"
+)
+
+func TestTopic_Subscribe(t *testing.T) {
+	t.Run("should return same channel for same id", func(t *testing.T) {
+		topic := NewTopic[*types.Header]("headers", log.New(slog.DiscardHandler))
+
+		this := topic.Subscribe("id")
+		that := topic.Subscribe("id")
+
+		if this != that {
+			t.Errorf("expected %v, got %v", that, this)
+		}
+	})
+
+	t.Run("should return different channel for different id", func(t *testing.T) {
+		topic := NewTopic[*types.Header]("headers", log.New(slog.DiscardHandler))
+
+		this := topic.Subscribe("this")
+		that := topic.Subscribe("that")
+
+		if this == that {
+			t.Errorf("expected different channel")
+		}
+	})
+}
+
+func TestTopic_Unsubscribe(t *testing.T) {
+	t.Run("should close channel for id", func(t *testing.T) {
+		topic := NewTopic[*types.Header]("headers", log.New(slog.DiscardHandler))
+
+		sub := topic.Subscribe("sub")
+		topic.Unsubscribe("sub")
+
+		_, open := <-sub
+		if open {
+			t.Errorf("expected closed channel")
+		}
+	})
+}
+
+func TestTopic_Close(t *testing.T) {
+	t.Run("should close all channels on close", func(t *testing.T) {
+		topic := NewTopic[*types.Header]("headers", log.New(slog.DiscardHandler))
+
+		first := topic.Subscribe("first")
+		second := topic.Subscribe("second")
+
+		topic.Close()
+
+		_, open := <-first
+		if open {
+			t.Errorf("expected closed channel")
+		}
+
+		_, open = <-second
+		if open {
+			t.Errorf("expected closed channel")
+		}
+	})
+}
+
+func TestTopic_Publish(t *testing.T) {
+	t.Run("should publish event to all subscribers", func(t *testing.T) {
+		topic := NewTopic[*types.Header]("headers", log.New(slog.DiscardHandler))
+
+		sub := topic.Subscribe("sub")
+		head := &types.Header{
+			Number: big.NewInt(1),
+		}
+		topic.Publish(head)
+
+		select {
+		case rcv := <-sub:
+			if rcv.Number.Cmp(head.Number) != 0 {
+				t.Errorf("expected %v, got %v", head, rcv)
+			}
+		case <-time.After(time.Second):
+			t.Errorf("timeout: did not receive event")
+		}
+	})
+
+	t.Run("should not block on full subscriber", func(t *testing.T) {
+		topic := NewTopic[int]("ints", log.New(slog.DiscardHandler))
+
+		sub := topic.Subscribe("sub")
+		for i := range subscriptionBuffer + 1 {
+			topic.Publish(i)
+		}
+
+		if len(sub) != subscriptionBuffer {
+			t.Errorf("expected %d buffered events, got %d", subscriptionBuffer, len(sub))
+		}
+	})
+
+	t.Run("should drop oldest events on full subscriber", func(t *testing.T) {
+		topic := NewTopic[int]("ints", log.New(slog.DiscardHandler))
+
+		sub := topic.SubscribeWith("sub", Options{Size: 2, Policy: DropOldest})
+		for i := range 4 {
+			topic.Publish(i)
+		}
+
+		if first, second := <-sub, <-sub; first != 2 || second != 3 {
+			t.Errorf("expected events 2 and 3, got %d and %d", first, second)
+		}
+	})
+
+	t.Run("should block until subscriber has room", func(t *testing.T) {
+		topic := NewTopic[int]("ints", log.New(slog.DiscardHandler))
+
+		sub := topic.SubscribeWith("sub", Options{Size: 1, Policy: Block})
+		topic.Publish(0)
+
+		done := make(chan struct{})
+		go func() {
+			topic.Publish(1)
+			close(done)
+		}()
+
+		select {
+		case <-done:
+			t.Fatalf("expected publish to block")
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		if ev := <-sub; ev != 0 {
+			t.Errorf("expected event 0, got %d", ev)
+		}
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatalf("timeout: publish did not return")
+		}
+		if ev := <-sub; ev != 1 {
+			t.Errorf("expected event 1, got %d", ev)
+		}
+	})
+
+	t.Run("should unblock producer on unsubscribe", func(t *testing.T) {
+		topic := NewTopic[int]("ints", log.New(slog.DiscardHandler))
+
+		topic.SubscribeWith("sub", Options{Size: 1, Policy: Block})
+		topic.Publish(0)
+
+		done := make(chan struct{})
+		go func() {
+			topic.Publish(1)
+			close(done)
+		}()
+		time.Sleep(10 * time.Millisecond)
+		topic.Unsubscribe("sub")
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatalf("timeout: publish did not return")
+		}
+	})
+
+	t.Run("should do nothing on nil topic", func(t *testing.T) {
+		var topic *Topic[int]
+		topic.Publish(1)
+	})
+}
+
+func TestTopic_Stats(t *testing.T) {
+	t.Run("should report depth and drops", func(t *testing.T) {
+		topic := NewTopic[int]("ints", log.New(slog.DiscardHandler))
+
+		topic.SubscribeWith("sub", Options{Size: 2})
+		for i := range 5 {
+			topic.Publish(i)
+		}
+
+		stats := topic.Stats()
+		if len(stats) != 1 {
+			t.Fatalf("expected 1 subscriber, got %d", len(stats))
+		}
+		if s := stats[0]; s.Depth != 2 || s.Capacity != 2 || s.Dropped != 3 || s.Policy != DropNewest {
+			t.Errorf("expected depth 2 of 2 with 3 drops, got %+v", s)
+		}
+	})
+}
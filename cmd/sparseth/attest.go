@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sparseth/attest"
+	userconfig "sparseth/config"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// newSigner creates the signer of attestations,
+// either from the key file at the specified path,
+// or the external signer at the specified URL, or
+// returns nil if neither is specified.
+func newSigner(ctx context.Context, keyPath, passwordPath, signerURL, account string) (attest.Signer, error) {
+	switch {
+	case keyPath != "" && signerURL != "":
+		return nil, fmt.Errorf("--attest-key and --attest-signer are mutually exclusive")
+	case keyPath != "":
+		var password string
+		if passwordPath != "" {
+			data, err := os.ReadFile(passwordPath)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read password file: %w", err)
+			}
+			password = strings.TrimRight(string(data), "\r\n")
+		}
+		return attest.NewKeySigner(keyPath, password)
+	case signerURL != "":
+		if err := userconfig.ValidateAddress(account, false); err != nil {
+			return nil, fmt.Errorf("invalid --attest-account: %w", err)
+		}
+		return attest.NewExternalSigner(ctx, signerURL, common.HexToAddress(account))
+	default:
+		return nil, nil
+	}
+}
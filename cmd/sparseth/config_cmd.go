@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sparseth/node"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// configCmdTimeout is the maximum duration
+// of a config subcommand.
+const configCmdTimeout = 30 * time.Second
+
+// runConfigCommand runs the config subcommand with
+// the specified arguments, and returns the exit code.
+//
+//	sparseth config diff [--api <url>] [--api-key <key>] <path>
+//	sparseth config apply [--api <url>] [--api-key <key>] [--dry-run] <path>
+func runConfigCommand(args []string) int {
+	if len(args) == 0 || (args[0] != "diff" && args[0] != "apply") {
+		fmt.Fprintln(os.Stderr, "usage: sparseth config <diff|apply> [--api <url>] [--api-key <key>] [--dry-run] <path>")
+		return 2
+	}
+
+	fs := newFlagSet("config "+args[0], "[--api <url>] [--api-key <key>] [--dry-run] <path>")
+	apiURL := fs.String("api", "http://localhost:8550", "URL of the JSON-RPC API of the running node")
+	apiKey := fs.String("api-key", os.Getenv("API_KEY"), "API key of an unscoped tenant, if the node requires API keys")
+	dryRun := fs.Bool("dry-run", false, "Only show the changes, do not apply them")
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "expected exactly one config file path")
+		return 2
+	}
+
+	// The node resolves the path on its host,
+	// so relative paths must be made absolute
+	path, err := filepath.Abs(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid path: %v\n", err)
+		return 2
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), configCmdTimeout)
+	defer cancel()
+
+	client, err := dialAPI(ctx, *apiURL, *apiKey)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to connect to node: %v\n", err)
+		return 1
+	}
+	defer client.Close()
+
+	apply := args[0] == "apply" && !*dryRun
+
+	var plan *node.ConfigPlan
+	if err = client.CallContext(ctx, &plan, "admin_applyConfig", path, !apply); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to %s config: %v\n", args[0], err)
+		return 1
+	}
+
+	printPlan(plan, apply)
+	return 0
+}
+
+// dialAPI connects to the JSON-RPC API of a running
+// node at the specified URL, authenticating with the
+// specified API key, if any.
+func dialAPI(ctx context.Context, url, key string) (*rpc.Client, error) {
+	var opts []rpc.ClientOption
+	if key != "" {
+		opts = append(opts, rpc.WithHeader("Authorization", "Bearer "+key))
+	}
+	return rpc.DialOptions(ctx, url, opts...)
+}
+
+// printPlan prints the specified plan
+// in a human-readable format.
+func printPlan(plan *node.ConfigPlan, applied bool) {
+	if plan.IsEmpty() {
+		fmt.Printf("no changes, %d accounts unchanged\n", plan.Unchanged)
+		return
+	}
+
+	for _, section := range []struct {
+		sign    string
+		changes []*node.AccountChange
+	}{
+		{"+", plan.Added},
+		{"-", plan.Removed},
+		{"~", plan.Changed},
+	} {
+		for _, change := range section.changes {
+			fmt.Printf("%s %s %v\n", section.sign, change.Address.Hex(), change.Monitors)
+			for _, work := range change.Work {
+				fmt.Printf("    %s\n", work)
+			}
+		}
+	}
+
+	status := "dry run, nothing applied"
+	if applied {
+		status = "applied"
+	}
+	fmt.Printf("%d added, %d removed, %d changed, %d unchanged (%s)\n", len(plan.Added), len(plan.Removed), len(plan.Changed), plan.Unchanged, status)
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sparseth/config"
+	"sparseth/execution/ens"
+	"sparseth/execution/ethclient"
+	"sparseth/log"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// ensTimeout is the maximum duration
+// of resolving a single ENS name.
+const ensTimeout = 30 * time.Second
+
+// nameResolver resolves ENS names at the
+// checkpoint block. The endpoints are only
+// dialed once the first name is resolved.
+type nameResolver struct {
+	endpoints  []*ethclient.EndpointConfig
+	checkpoint *config.Checkpoint
+	log        log.Logger
+
+	mu       sync.Mutex
+	resolver *ens.Resolver
+	resolved map[string]common.Address
+}
+
+// newNameResolver creates a new nameResolver that
+// resolves names via the specified endpoints at
+// the specified checkpoint.
+func newNameResolver(endpoints []*ethclient.EndpointConfig, checkpoint *config.Checkpoint, log log.Logger) *nameResolver {
+	return &nameResolver{
+		endpoints:  endpoints,
+		checkpoint: checkpoint,
+		log:        log,
+		resolved:   make(map[string]common.Address),
+	}
+}
+
+// Resolve returns the verified address of the
+// specified name. Names are resolved once, so
+// that the config resolves to the same address
+// on every load.
+func (r *nameResolver) Resolve(name string) (common.Address, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if addr, ok := r.resolved[name]; ok {
+		return addr, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), ensTimeout)
+	defer cancel()
+
+	if r.resolver == nil {
+		resolver, err := r.dial(ctx)
+		if err != nil {
+			return common.Address{}, err
+		}
+		r.resolver = resolver
+	}
+
+	addr, err := r.resolver.Resolve(ctx, name)
+	if err != nil {
+		return common.Address{}, err
+	}
+	r.resolved[name] = addr
+	return addr, nil
+}
+
+// dial connects to the endpoints, and creates
+// a resolver at the verified checkpoint block.
+func (r *nameResolver) dial(ctx context.Context) (*ens.Resolver, error) {
+	pool, err := ethclient.DialPool(ctx, r.endpoints, nil, r.log)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to RPC providers: %w", err)
+	}
+
+	var head *types.Header
+	if err = pool.CallContext(ctx, &head, "eth_getBlockByHash", r.checkpoint.Hash, false); err != nil {
+		return nil, fmt.Errorf("failed to fetch checkpoint block: %w", err)
+	}
+	if head == nil {
+		return nil, fmt.Errorf("checkpoint block not found")
+	}
+	if err = r.checkpoint.Verify(head); err != nil {
+		return nil, fmt.Errorf("invalid checkpoint block: %w", err)
+	}
+
+	provider := ethclient.NewRpcProvider(ethclient.NewClient(pool))
+	return ens.NewResolver(provider, head, r.log), nil
+}
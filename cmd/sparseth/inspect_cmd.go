@@ -0,0 +1,147 @@
+package main
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"sparseth/ethstore"
+	"sparseth/storage"
+	"sparseth/storage/engine"
+	"strings"
+)
+
+// runInspectDBCommand runs the inspect-db subcommand
+// with the specified arguments, and returns the exit
+// code.
+//
+//	sparseth inspect-db [--db <path>] [--db-engine <name>] [--db-encryption-key <hex> | --db-encryption-key-file <path>] [--config <path>] [--headers <n>]
+func runInspectDBCommand(args []string) int {
+	fs := newFlagSet("inspect-db", "[--db <path>] [--db-engine <name>] [--db-encryption-key <hex> | --db-encryption-key-file <path>] [--config <path>] [--headers <n>]")
+	dbPath := fs.String("db", "/sparseth/.db", "Path to the database of the node, which is opened read-only")
+	dbEngine := fs.String("db-engine", "badger", "Storage engine of the database, 'badger' or 'pebble'")
+	dbKey := fs.String("db-encryption-key", "", "Hex-encoded encryption key of an encrypted database")
+	dbKeyFile := fs.String("db-encryption-key-file", "", "Path to the hex-encoded encryption key of an encrypted database")
+	fs.String("config", "config.yaml", "Path to the config file of the node, to read database options from")
+	numHeaders := fs.Int("headers", 10, "Number of most recent stored headers to list")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 0 || *numHeaders < 0 {
+		fs.Usage()
+		return 2
+	}
+	if err := resolveOptions(fs, os.Getenv); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to resolve options: %v\n", err)
+		return 2
+	}
+
+	e, err := engine.ParseEngine(*dbEngine)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to open database: %v\n", err)
+		return 1
+	}
+	// Do not create an empty database
+	if _, err = os.Stat(*dbPath); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to open database: %v\n", err)
+		return 1
+	}
+	key, err := loadEncryptionKey(*dbKey, *dbKeyFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid encryption key: %v\n", err)
+		return 2
+	}
+	// Safe while the node is running
+	db, err := engine.OpenReadOnlyWith(e, *dbPath, engine.Options{EncryptionKey: key})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to open database: %v\n", err)
+		return 1
+	}
+	defer db.Close()
+
+	headers, err := ethstore.NewHeaderStore(db).Last(*numHeaders)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to inspect headers: %v\n", err)
+		return 1
+	}
+	accounts, err := ethstore.InspectAccounts(db)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to inspect accounts: %v\n", err)
+		return 1
+	}
+	stats, err := ethstore.Inspect(db)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to inspect database: %v\n", err)
+		return 1
+	}
+
+	if *numHeaders > 0 {
+		fmt.Printf("%-12s %s\n", "NUMBER", "HASH")
+		for _, h := range headers {
+			fmt.Printf("%-12d %s\n", h.Number.Uint64(), h.Hash().Hex())
+		}
+		fmt.Println()
+	}
+
+	fmt.Printf("%-42s %12s %-66s %8s %8s\n", "ACCOUNT", "HEAD", "CHAIN HEAD", "BLOCKS", "EVENTS")
+	for _, a := range accounts {
+		fmt.Printf("%-42s %12d %-66s %8d %8d\n", a.Address.Hex(), a.Head.Number, a.Head.Head.Hex(), a.Heads, a.Events)
+	}
+	fmt.Println()
+
+	var keys, size uint64
+	fmt.Printf("%-20s %12s %14s\n", "PREFIX", "KEYS", "SIZE")
+	for _, s := range stats {
+		prefix := s.Prefix
+		if prefix == "" {
+			prefix = "(world state)"
+		}
+		fmt.Printf("%-20s %12d %14d\n", prefix, s.Keys, s.Size)
+		keys += s.Keys
+		size += s.Size
+	}
+	fmt.Printf("%-20s %12d %14d\n", "total", keys, size)
+	return 0
+}
+
+// openDatabase opens the database at the
+// specified path with the storage engine
+// of the specified name, encrypted with the
+// specified key, unless empty.
+func openDatabase(name, path string, key []byte) (storage.KeyValStore, error) {
+	e, err := engine.ParseEngine(name)
+	if err != nil {
+		return nil, err
+	}
+	return engine.OpenWith(e, path, engine.Options{EncryptionKey: key})
+}
+
+// loadEncryptionKey loads the database encryption
+// key, either hex-encoded or from the file at the
+// specified path, or returns nil if neither is set.
+func loadEncryptionKey(hexKey, path string) ([]byte, error) {
+	if hexKey != "" && path != "" {
+		return nil, errors.New("key and key file are mutually exclusive")
+	}
+	if path != "" {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read key file: %w", err)
+		}
+		hexKey = strings.TrimSpace(string(content))
+	}
+	if hexKey == "" {
+		return nil, nil
+	}
+
+	key, err := hex.DecodeString(strings.TrimPrefix(hexKey, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode key: %w", err)
+	}
+	switch len(key) {
+	case 16, 24, 32:
+		return key, nil
+	default:
+		return nil, fmt.Errorf("key must be 16, 24, or 32 bytes, got %d", len(key))
+	}
+}
@@ -1,20 +1,21 @@
 package main
 
 import (
-	"context"
-	"errors"
+	"crypto/rand"
 	"flag"
 	"fmt"
+	"io"
 	"os"
-	"os/signal"
+	"slices"
 	userconfig "sparseth/config"
+	"sparseth/execution"
+	"sparseth/execution/ethclient"
 	internalconfig "sparseth/internal/config"
-	"sparseth/internal/log"
-	"sparseth/node"
-	"syscall"
+	"strconv"
+	"strings"
 
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 )
 
 var (
@@ -23,111 +24,206 @@ var (
 	anvil   = "anvil"
 )
 
-func main() {
-	rpcURL := flag.String("rpc", "ws://localhost:8545", "RPC provider URL to connect to")
-	dbPath := flag.String("db", "/sparseth/.db", "Path to database")
-	configPath := flag.String("config", "config.yaml", "Path to config file")
-	networkFlag := flag.String("network", "mainnet", "Ethereum network to use")
-	eventModeFlag := flag.Bool("event-mode", false, "Enable event monitoring mode (default: false)")
-	checkPointFlag := flag.String("checkpoint", "", "Checkpoint hash to start from (default: genesis hash of the network)")
+// command is a subcommand of sparseth.
+type command struct {
+	name    string
+	summary string
+	// run runs the command with the specified
+	// arguments, and returns the exit code.
+	run func(args []string) int
+}
 
-	if v := os.Getenv("EXECUTION_RPC_URL"); v != "" {
-		flag.Set("rpc", v)
-	}
-	if v := os.Getenv("DB_PATH"); v != "" {
-		flag.Set("db", v)
-	}
-	if v := os.Getenv("CONFIG_PATH"); v != "" {
-		flag.Set("config", v)
-	}
-	if v := os.Getenv("ETHEREUM_NETWORK"); v != "" {
-		flag.Set("network", v)
+// commands are all subcommands, in
+// the order they are listed in.
+var commands = []*command{
+	{"run", "Run the node (default)", runNodeCommand},
+	{"validate-config", "Validate the config file and node options", runValidateConfigCommand},
+	{"verify-block", "Verify a range of past blocks and exit", runVerifyBlockCommand},
+	{"export-state", "Export the world state of a running node", runExportStateCommand},
+	{"import-state", "Import an exported world state into the database", runImportStateCommand},
+	{"inspect-db", "Summarize the contents of the database", runInspectDBCommand},
+	{"config", "Show or apply config changes of a running node", runConfigCommand},
+	{"tx", "Look up the verification outcome of a transaction", runTxCommand},
+	{"proof", "Fetch and verify a Merkle proof of an account", runProofCommand},
+	{"version", "Print the version", runVersionCommand},
+}
+
+func main() {
+	args := os.Args[1:]
+	// Without a subcommand, the node is run, so
+	// that existing deployments keep working
+	if len(args) == 0 || (strings.HasPrefix(args[0], "-") && args[0] != "-h" && args[0] != "--help") {
+		os.Exit(runNodeCommand(args))
 	}
-	if v := os.Getenv("CHECKPOINT_HASH"); v != "" {
-		flag.Set("checkpoint", v)
+
+	for _, cmd := range commands {
+		if cmd.name == args[0] {
+			os.Exit(cmd.run(args[1:]))
+		}
 	}
-	if v := os.Getenv("EVENT_MODE"); v == "1" || v == "true" {
-		flag.Set("event-mode", "true")
+	if args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		printUsage(os.Stdout)
+		os.Exit(0)
 	}
 
-	flag.Parse()
-
-	logger := log.New(log.NewTerminalHandler()).With("component", "main")
+	fmt.Fprintf(os.Stderr, "unknown command %q\n\n", args[0])
+	printUsage(os.Stderr)
+	os.Exit(2)
+}
 
-	supportedNetworks := map[string]*params.ChainConfig{
-		mainnet: userconfig.MainnetChainConfig,
-		sepolia: userconfig.SepoliaChainConfig,
-		anvil:   userconfig.AnvilChainConfig,
+// printUsage prints all subcommands
+// to the specified writer.
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "usage: sparseth <command> [options] [args]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-16s %s\n", cmd.name, cmd.summary)
 	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "run 'sparseth <command> -h' for the options of a command")
+}
 
-	chainConfig, exists := supportedNetworks[*networkFlag]
-	if !exists {
-		logger.Error("unsupported network", "network", *networkFlag)
-		logger.Info(fmt.Sprintf("supported networks: %s, %s, %s", mainnet, sepolia, anvil))
-		os.Exit(2)
+// newFlagSet creates a flag set of the specified
+// subcommand, whose help shows the specified
+// synopsis followed by all options.
+func newFlagSet(name, synopsis string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: sparseth %s %s\n", name, synopsis)
+		fs.PrintDefaults()
 	}
+	return fs
+}
 
-	checkpoint := common.HexToHash(*checkPointFlag)
-	if *checkPointFlag == "" {
-		if *networkFlag == anvil {
-			logger.Error(fmt.Sprintf("checkpoint option is required for %s network", anvil))
-			os.Exit(2)
+// parseAddresses parses the specified
+// comma-separated addresses. If checksum is
+// set, addresses must be EIP-55 checksummed.
+func parseAddresses(value string, checksum bool) ([]common.Address, error) {
+	var addrs []common.Address
+	for _, part := range strings.Split(value, ",") {
+		if part = strings.TrimSpace(part); part == "" {
+			continue
 		}
-
-		checkpoints := map[string]common.Hash{
-			mainnet: userconfig.MainnetGenesisHash,
-			sepolia: userconfig.SepoliaGenesisHash,
+		if err := userconfig.ValidateAddress(part, checksum); err != nil {
+			return nil, err
 		}
-		checkpoint = checkpoints[*networkFlag]
+		addrs = append(addrs, common.HexToAddress(part))
 	}
+	return addrs, nil
+}
 
-	logger.Info("using RPC provider", "url", *rpcURL)
-	logger.Info("using database", "path", *dbPath)
-	logger.Info("using network", "name", *networkFlag)
-	logger.Info("using checkpoint", "hash", checkpoint.Hex())
-	logger.Info("using config file", "path", *configPath)
-	logger.Info("event mode", "enabled", *eventModeFlag)
+// parseConfirmations parses the number of
+// confirmations, or 'finalized'.
+func parseConfirmations(value string) (execution.Confirmations, error) {
+	if value == "finalized" {
+		return execution.Confirmations{Finalized: true}, nil
+	}
 
-	loader := internalconfig.NewLoader(logger)
-	accsConfig, err := loader.Load(*configPath)
+	depth, err := strconv.ParseUint(value, 10, 64)
 	if err != nil {
-		logger.Error("failed to load config", "err", err)
-		os.Exit(1)
+		return execution.Confirmations{}, fmt.Errorf("expected number or 'finalized', got %q", value)
 	}
+	return execution.Confirmations{Depth: depth}, nil
+}
 
-	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
-	defer cancel()
+// parseCheckpoint parses the checkpoint, which is
+// either a block hash or the path of a checkpoint
+// file.
+func parseCheckpoint(value string) (*userconfig.Checkpoint, error) {
+	if raw, err := hexutil.Decode(value); err == nil && len(raw) == common.HashLength {
+		return &userconfig.Checkpoint{Hash: common.BytesToHash(raw)}, nil
+	}
+	return internalconfig.LoadCheckpoint(value)
+}
 
-	nodeConfig := &node.Config{
-		ChainConfig: chainConfig,
-		Checkpoint:  checkpoint,
-		AccsConfig:  accsConfig,
-		RpcURL:      *rpcURL,
-		DbPath:      *dbPath,
-		IsEventMode: *eventModeFlag,
+// parseAuditSeed parses the hex-encoded audit
+// seed, or returns a random seed if empty.
+func parseAuditSeed(value string) (common.Hash, error) {
+	if value == "" {
+		var seed common.Hash
+		if _, err := rand.Read(seed[:]); err != nil {
+			return common.Hash{}, fmt.Errorf("failed to generate seed: %w", err)
+		}
+		return seed, nil
 	}
 
-	n, err := node.NewNode(ctx, nodeConfig, logger)
+	raw, err := hexutil.Decode(value)
+	if err != nil || len(raw) != common.HashLength {
+		return common.Hash{}, fmt.Errorf("expected 32 hex-encoded bytes, got %s", value)
+	}
+	return common.BytesToHash(raw), nil
+}
+
+// parseEndpoints parses the comma-separated RPC provider
+// URLs, their rates, and their method policies. A single
+// rate or method list applies to all providers.
+func parseEndpoints(urls, rates, allow, deny string) ([]*ethclient.EndpointConfig, error) {
+	var endpoints []*ethclient.EndpointConfig
+	for _, url := range strings.Split(urls, ",") {
+		if url = strings.TrimSpace(url); url != "" {
+			endpoints = append(endpoints, &ethclient.EndpointConfig{URL: url})
+		}
+	}
+	if len(endpoints) == 0 {
+		return nil, fmt.Errorf("no RPC provider specified")
+	}
+
+	rateParts, err := splitPerEndpoint(rates, ",", len(endpoints))
+	if err != nil {
+		return nil, fmt.Errorf("invalid rates: %w", err)
+	}
+	allowParts, err := splitPerEndpoint(allow, ";", len(endpoints))
+	if err != nil {
+		return nil, fmt.Errorf("invalid allowed methods: %w", err)
+	}
+	denyParts, err := splitPerEndpoint(deny, ";", len(endpoints))
 	if err != nil {
-		logger.Error("failed to create node", "err", err)
-		os.Exit(1)
+		return nil, fmt.Errorf("invalid denied methods: %w", err)
 	}
-	defer n.Shutdown()
 
-	logger.Info("start node")
-	go func() {
-		if err = n.Start(ctx); err != nil {
-			logger.Error("node run failed", "err", err)
-			cancel()
+	for i, ep := range endpoints {
+		if rateParts[i] != "" {
+			rate, err := strconv.ParseFloat(rateParts[i], 64)
+			if err != nil || rate < 0 {
+				return nil, fmt.Errorf("invalid rate %q", rateParts[i])
+			}
+			ep.Rate = rate
+		}
+		if allowParts[i] != "" || denyParts[i] != "" {
+			ep.Policy = ethclient.NewMethodPolicy(splitMethods(allowParts[i]), splitMethods(denyParts[i]))
 		}
-	}()
+	}
+
+	return endpoints, nil
+}
 
-	<-ctx.Done()
+// splitPerEndpoint splits the specified value into
+// one part per endpoint. A single part applies to
+// all endpoints.
+func splitPerEndpoint(value, sep string, n int) ([]string, error) {
+	parts := strings.Split(value, sep)
+	if len(parts) == 1 {
+		parts = slices.Repeat(parts, n)
+	}
+	if len(parts) != n {
+		return nil, fmt.Errorf("expected 1 or %d values, got %d", n, len(parts))
+	}
 
-	if ctx.Err() != nil && !errors.Is(ctx.Err(), context.Canceled) {
-		logger.Error("shutdown due to error", "err", ctx.Err())
-		os.Exit(1)
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
 	}
+	return parts, nil
+}
 
-	logger.Info("graceful shutdown")
+// splitMethods splits the specified comma-
+// separated list of RPC method patterns.
+func splitMethods(value string) []string {
+	var methods []string
+	for _, method := range strings.Split(value, ",") {
+		if method = strings.TrimSpace(method); method != "" {
+			methods = append(methods, method)
+		}
+	}
+	return methods
 }
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	internalconfig "sparseth/internal/config"
+)
+
+// envVars maps each flag to the environment
+// variable that may set it instead.
+var envVars = map[string]string{
+	"rpc":                     "EXECUTION_RPC_URL",
+	"rpc-rate":                "EXECUTION_RPC_RATE",
+	"rpc-allow":               "EXECUTION_RPC_ALLOW",
+	"rpc-deny":                "EXECUTION_RPC_DENY",
+	"beacon":                  "BEACON_API_URL",
+	"blobs":                   "BLOBS_API_URL",
+	"db":                      "DB_PATH",
+	"db-encoding":             "DB_ENCODING",
+	"db-engine":               "DB_ENGINE",
+	"db-encryption-key":       "DB_ENCRYPTION_KEY",
+	"db-encryption-key-file":  "DB_ENCRYPTION_KEY_FILE",
+	"db-key-rotation":         "DB_KEY_ROTATION",
+	"config":                  "CONFIG_PATH",
+	"network":                 "ETHEREUM_NETWORK",
+	"chain-config":            "CHAIN_CONFIG_PATH",
+	"checkpoint":              "CHECKPOINT_HASH",
+	"event-mode":              "EVENT_MODE",
+	"allow-mode-fallback":     "ALLOW_MODE_FALLBACK",
+	"api-addr":                "API_ADDR",
+	"api-keys":                "API_KEYS",
+	"api-admin-key":           "API_ADMIN_KEY",
+	"api-origins":             "API_ORIGINS",
+	"serve-headers":           "SERVE_HEADERS",
+	"header-source":           "HEADER_SOURCE_URL",
+	"header-source-key":       "HEADER_SOURCE_API_KEY",
+	"monitor-concurrency":     "MONITOR_CONCURRENCY",
+	"monitor-queue-policy":    "MONITOR_QUEUE_POLICY",
+	"confirmations":           "CONFIRMATIONS",
+	"process-delay":           "PROCESS_DELAY",
+	"process-retries":         "PROCESS_RETRIES",
+	"call-budget":             "CALL_BUDGET",
+	"read-allowlist":          "READ_ALLOWLIST",
+	"read-allowlist-defaults": "READ_ALLOWLIST_DEFAULTS",
+	"checksum-addresses":      "CHECKSUM_ADDRESSES",
+	"fetch-parallelism":       "FETCH_PARALLELISM",
+	"audit-rate":              "AUDIT_RATE",
+	"audit-seed":              "AUDIT_SEED",
+	"snapshot-blocks":         "SNAPSHOT_BLOCKS",
+	"pressure-threshold":      "PRESSURE_THRESHOLD",
+	"pressure-webhook":        "PRESSURE_WEBHOOK",
+	"alert-webhook":           "ALERT_WEBHOOK",
+	"attest-key":              "ATTEST_KEY_PATH",
+	"attest-password":         "ATTEST_PASSWORD_PATH",
+	"attest-signer":           "ATTEST_SIGNER_URL",
+	"attest-account":          "ATTEST_ACCOUNT",
+	"peers":                   "PEERS",
+	"peer-key":                "PEER_API_KEY",
+	"sink-sqlite":             "SINK_SQLITE_PATH",
+	"sink-nats":               "SINK_NATS_URL",
+	"sink-nats-subject":       "SINK_NATS_SUBJECT",
+	"sink-kafka":              "SINK_KAFKA_URL",
+	"sink-kafka-topic":        "SINK_KAFKA_TOPIC",
+	"report-interval":         "REPORT_INTERVAL",
+	"gc-interval":             "GC_INTERVAL",
+	"gc-discard-ratio":        "GC_DISCARD_RATIO",
+	"hooks":                   "HOOKS",
+	"watch-config":            "WATCH_CONFIG",
+	"resolve-abis":            "RESOLVE_ABIS",
+	"abi-cache":               "ABI_CACHE_PATH",
+	"etherscan-key":           "ETHERSCAN_API_KEY",
+	"log-level":               "LOG_LEVEL",
+	"log-format":              "LOG_FORMAT",
+	"log-file":                "LOG_FILE",
+}
+
+// resolveOptions fills in all flags not set on the
+// command line, in order of precedence, from the
+// environment and the node section of the config
+// file. Flags set nowhere keep their defaults.
+//
+// Subcommands define a subset of the node flags,
+// options of other flags are ignored. The config
+// file is skipped if it does not exist at its
+// default path.
+//
+// Note that the config file path itself cannot be
+// set in the config file.
+func resolveOptions(fs *flag.FlagSet, getenv func(string) string) error {
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+
+	for name, env := range envVars {
+		if set[name] || fs.Lookup(name) == nil {
+			continue
+		}
+		if v := getenv(env); v != "" {
+			if err := fs.Set(name, v); err != nil {
+				return fmt.Errorf("invalid value %q for %s: %w", v, env, err)
+			}
+			set[name] = true
+		}
+	}
+
+	opts, err := internalconfig.LoadNodeOptions(fs.Lookup("config").Value.String())
+	if errors.Is(err, os.ErrNotExist) && !set["config"] {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for name, v := range opts {
+		if _, ok := envVars[name]; !ok || name == "config" {
+			return fmt.Errorf("unknown node option %s", name)
+		}
+		if set[name] || fs.Lookup(name) == nil {
+			continue
+		}
+		if err = fs.Set(name, v); err != nil {
+			return fmt.Errorf("invalid value %q for node option %s: %w", v, name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	userconfig "sparseth/config"
+	"sparseth/execution/ethclient"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pslowak/sparseth/execution/mpt"
+)
+
+// runProofCommand runs the proof subcommand with the
+// specified arguments, and returns the exit code. The
+// exit code is non-zero if a proof is invalid.
+//
+//	sparseth proof [--rpc <url>] --address <addr> [--slot <slot>]... [--block <number|hash>]
+func runProofCommand(args []string) int {
+	fs := newFlagSet("proof", "[--rpc <url>] --address <addr> [--slot <slot>]... [--block <number|hash>]")
+	rpcURL := fs.String("rpc", "ws://localhost:8545", "URL of the RPC provider to fetch the header and proof from")
+	address := fs.String("address", "", "EIP-55 checksummed address of the account to prove")
+	block := fs.String("block", "latest", "Number or hash of the block to prove the account at, or 'latest'")
+	var slots []common.Hash
+	fs.Func("slot", "Storage slot to prove, as hex with 0x prefix or decimal, may be repeated", func(value string) error {
+		slot, err := parseSlot(value)
+		if err != nil {
+			return err
+		}
+		slots = append(slots, slot)
+		return nil
+	})
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 0 || *address == "" {
+		fs.Usage()
+		return 2
+	}
+	if err := userconfig.ValidateAddress(*address, true); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid address: %v\n", err)
+		return 2
+	}
+	addr := common.HexToAddress(*address)
+
+	ctx, cancel := context.WithTimeout(context.Background(), configCmdTimeout)
+	defer cancel()
+
+	ec, err := ethclient.DialContext(ctx, *rpcURL)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to connect to RPC provider: %v\n", err)
+		return 1
+	}
+	defer ec.Close()
+
+	header, err := fetchHeader(ctx, ec, *block)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to fetch header: %v\n", err)
+		return 1
+	}
+	proof, err := ec.GetProof(ctx, addr, slots, header.Hash())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to fetch proof: %v\n", err)
+		return 1
+	}
+
+	fmt.Printf("block %d (%s), state root %s\n", header.Number.Uint64(), header.Hash().Hex(), header.Root.Hex())
+	if err = printProof(addr, slots, header, proof); err != nil {
+		fmt.Fprintf(os.Stderr, "! %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// fetchHeader fetches the header of the specified block,
+// i.e., a number, a hash, or 'latest'. The header must
+// hash to the printed hash, which is compared with a
+// trusted source, e.g., a checkpoint.
+func fetchHeader(ctx context.Context, ec *ethclient.Client, block string) (*types.Header, error) {
+	if raw, err := hexutil.Decode(block); err == nil && len(raw) == common.HashLength {
+		return ec.HeaderByHash(ctx, common.BytesToHash(raw))
+	}
+
+	var num *big.Int
+	if block != "latest" {
+		n, err := strconv.ParseUint(block, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("expected block number, hash, or 'latest', got %q", block)
+		}
+		num = new(big.Int).SetUint64(n)
+	}
+	header, err := ec.HeaderByNumber(ctx, num)
+	if err != nil {
+		return nil, err
+	}
+	// Fetch again by hash to check
+	// that the header matches it
+	return ec.HeaderByHash(ctx, header.Hash())
+}
+
+// printProof verifies the specified proof against the
+// state root of the specified header, and prints the
+// verified values of the account and its slots. An
+// error is returned if any proof is invalid.
+func printProof(addr common.Address, slots []common.Hash, header *types.Header, proof *ethclient.Proof) error {
+	acc, err := mpt.VerifyAccountProof(header.Root, addr, proof.AccountProof)
+	if err != nil {
+		return fmt.Errorf("invalid account proof: %w", err)
+	}
+	if acc == nil {
+		fmt.Printf("account %s does not exist\n", addr.Hex())
+		return nil
+	}
+	if err = checkClaims(proof, acc); err != nil {
+		return err
+	}
+	fmt.Printf("account %s: nonce %d, balance %s, code hash %s, storage root %s\n", addr.Hex(), acc.Nonce, acc.Balance, acc.CodeHash.Hex(), acc.StorageRoot.Hex())
+
+	if len(proof.StorageProof) != len(slots) {
+		return fmt.Errorf("expected %d storage proofs, got %d", len(slots), len(proof.StorageProof))
+	}
+	for i, slot := range slots {
+		value, err := mpt.VerifyStorageProof(acc.StorageRoot, mpt.StorageKey(slot), proof.StorageProof[i].Proof)
+		if err != nil {
+			return fmt.Errorf("invalid proof of slot %s: %w", slot.Hex(), err)
+		}
+		fmt.Printf("slot %s: %s\n", slot.Hex(), common.BytesToHash(value).Hex())
+	}
+	return nil
+}
+
+// checkClaims checks that the values claimed by
+// the provider match the verified account.
+func checkClaims(proof *ethclient.Proof, acc *mpt.Account) error {
+	if proof.Nonce == nil || !proof.Nonce.IsUint64() || proof.Nonce.Uint64() != acc.Nonce {
+		return fmt.Errorf("provider claims nonce %v, but proof proves %d", proof.Nonce, acc.Nonce)
+	}
+	if proof.Balance == nil || proof.Balance.Cmp(acc.Balance) != 0 {
+		return fmt.Errorf("provider claims balance %v, but proof proves %s", proof.Balance, acc.Balance)
+	}
+	if proof.CodeHash != acc.CodeHash {
+		return fmt.Errorf("provider claims code hash %s, but proof proves %s", proof.CodeHash.Hex(), acc.CodeHash.Hex())
+	}
+	if proof.StorageRoot != acc.StorageRoot {
+		return fmt.Errorf("provider claims storage root %s, but proof proves %s", proof.StorageRoot.Hex(), acc.StorageRoot.Hex())
+	}
+	return nil
+}
+
+// parseSlot parses the specified storage slot,
+// either hex-encoded with 0x prefix, or decimal.
+func parseSlot(value string) (common.Hash, error) {
+	slot, ok := new(big.Int), false
+	if hex, found := strings.CutPrefix(value, "0x"); found {
+		slot, ok = slot.SetString(hex, 16)
+	} else {
+		slot, ok = slot.SetString(value, 10)
+	}
+	if !ok || slot.Sign() < 0 || slot.BitLen() > 256 {
+		return common.Hash{}, errors.New("expected 32-byte hex or decimal slot")
+	}
+	return common.BigToHash(slot), nil
+}
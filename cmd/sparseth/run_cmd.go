@@ -0,0 +1,614 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sparseth/bus"
+	userconfig "sparseth/config"
+	"sparseth/ethstore"
+	"sparseth/hook"
+	internalconfig "sparseth/internal/config"
+	internallog "sparseth/internal/log"
+	"sparseth/log"
+	"sparseth/node"
+	"sparseth/notify"
+	"sparseth/sink"
+	"sparseth/storage/engine"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+const (
+	// logFileSize is the size in bytes
+	// at which the log file is rotated.
+	logFileSize = 100 << 20
+	// logFileBackups is the number of
+	// rotated log files that are kept.
+	logFileBackups = 5
+)
+
+// nodeFlags holds the options of a node,
+// shared by all subcommands running one.
+type nodeFlags struct {
+	rpcURL                *string
+	rpcRate               *string
+	rpcAllow              *string
+	rpcDeny               *string
+	beaconURL             *string
+	blobsURL              *string
+	dbPath                *string
+	dbEncoding            *string
+	dbEngine              *string
+	dbKey                 *string
+	dbKeyFile             *string
+	dbKeyRotation         *time.Duration
+	configPath            *string
+	network               *string
+	chainConfig           *string
+	eventMode             *bool
+	allowModeFallback     *bool
+	checkpoint            *string
+	apiAddr               *string
+	serveHeaders          *bool
+	headerSource          *string
+	headerSourceKey       *string
+	apiKeys               *string
+	apiAdminKey           *string
+	apiOrigins            *string
+	concurrency           *int
+	queuePolicy           *string
+	confirmations         *string
+	processDelay          *uint64
+	processRetries        *int
+	callBudget            *uint64
+	readAllowlist         *string
+	readAllowlistDefaults *bool
+	checksum              *bool
+	fetchParallelism      *int
+	auditRate             *uint64
+	auditSeed             *string
+	snapshotBlocks        *uint64
+	pressureThreshold     *float64
+	reportInterval        *time.Duration
+	gcInterval            *time.Duration
+	gcDiscardRatio        *float64
+	alertWebhook          *string
+	sinkNATS              *string
+	sinkNATSSubject       *string
+	sinkKafka             *string
+	sinkKafkaTopic        *string
+	sinkSQLite            *string
+	attestKey             *string
+	attestPassword        *string
+	attestSigner          *string
+	attestAccount         *string
+	peers                 *string
+	peerKey               *string
+	pressureWebhook       *string
+	hooks                 *string
+	resolveABIs           *bool
+	abiCache              *string
+	etherscanKey          *string
+	watchConfig           *bool
+	logLevel              *string
+	logFormat             *string
+	logFile               *string
+}
+
+// defineNodeFlags defines the node
+// options on the specified flag set.
+func defineNodeFlags(fs *flag.FlagSet) *nodeFlags {
+	return &nodeFlags{
+		rpcURL:                fs.String("rpc", "ws://localhost:8545", "Comma-separated RPC provider URLs to connect to, in order of priority"),
+		rpcRate:               fs.String("rpc-rate", "", "Comma-separated maximum calls per second for each RPC provider (default: unlimited)"),
+		rpcAllow:              fs.String("rpc-allow", "", "Semicolon-separated lists of RPC methods allowed for each RPC provider, e.g., eth_*,net_* (default: all)"),
+		rpcDeny:               fs.String("rpc-deny", "", "Semicolon-separated lists of RPC methods denied for each RPC provider, e.g., debug_* (default: none)"),
+		beaconURL:             fs.String("beacon", "", "Beacon API URL of a consensus client, only finalized blocks are processed if set (default: disabled)"),
+		blobsURL:              fs.String("blobs", "", "Beacon API URL to fetch the blobs of relevant blob transactions from, blobs are verified if set (default: disabled)"),
+		dbPath:                fs.String("db", "/sparseth/.db", "Path to database"),
+		dbEncoding:            fs.String("db-encoding", "rlp", "Encoding of newly stored logs and block digests, 'rlp' or 'protobuf'"),
+		dbEngine:              fs.String("db-engine", "badger", "Storage engine of the database, 'badger' or 'pebble', which cannot be changed once created"),
+		dbKey:                 fs.String("db-encryption-key", "", "Hex-encoded AES key of 16, 24, or 32 bytes to encrypt a badger database with (default: unencrypted)"),
+		dbKeyFile:             fs.String("db-encryption-key-file", "", "Path to a hex-encoded AES key to encrypt a badger database with, instead of --db-encryption-key"),
+		dbKeyRotation:         fs.Duration("db-key-rotation", 240*time.Hour, "Interval at which new data keys of an encrypted database are generated"),
+		configPath:            fs.String("config", "config.yaml", "Path to config file"),
+		network:               fs.String("network", "mainnet", "Ethereum network to use"),
+		chainConfig:           fs.String("chain-config", "", "Path to a JSON chain config or genesis file of a custom network, overrides --network (default: none)"),
+		eventMode:             fs.Bool("event-mode", false, "Enable event monitoring mode (default: false)"),
+		allowModeFallback:     fs.Bool("allow-mode-fallback", false, "Run in the other mode if the RPC providers do not allow the methods of the configured mode (default: false)"),
+		checkpoint:            fs.String("checkpoint", "", "Checkpoint hash, or path to a JSON checkpoint file, to start from (default: genesis hash of the network)"),
+		apiAddr:               fs.String("api-addr", "", "Address to serve the JSON-RPC API on (default: disabled)"),
+		serveHeaders:          fs.Bool("serve-headers", false, "Serve the confirmed block headers to other instances via the headers namespace of the API (default: false)"),
+		headerSource:          fs.String("header-source", "", "WebSocket API URL of another instance to fetch block headers from instead of the RPC provider (default: disabled)"),
+		headerSourceKey:       fs.String("header-source-key", "", "API key sent to the header source, if it requires API keys"),
+		apiKeys:               fs.String("api-keys", "", "Path to a file of API keys, each scoped to a subset of accounts (default: no authentication)"),
+		apiAdminKey:           fs.String("api-admin-key", "", "API key required for the admin namespace without --api-keys (default: local non-browser clients only)"),
+		apiOrigins:            fs.String("api-origins", "", "Comma-separated origins allowed to open WebSocket connections to the API, or * for any (default: localhost)"),
+		concurrency:           fs.Int("monitor-concurrency", 16, "Maximum number of blocks processed concurrently across all monitors"),
+		queuePolicy:           fs.String("monitor-queue-policy", string(bus.Block), "Policy once a monitor falls behind: block holds back all monitors, drop-oldest skips ahead and backfills the dropped blocks"),
+		confirmations:         fs.String("confirmations", "0", "Number of blocks built on top of a block before it is processed, or 'finalized'"),
+		processDelay:          fs.Uint64("process-delay", 0, "Number of blocks behind the head at which blocks are processed, regardless of confirmations"),
+		processRetries:        fs.Int("process-retries", 2, "Number of retries of a block a monitor failed to process, before it is recorded as a dead letter"),
+		callBudget:            fs.Uint64("call-budget", 0, "Maximum RPC calls per block per account before switching it to proof-only mode, 0 disables the budget"),
+		readAllowlist:         fs.String("read-allowlist", "", "Comma-separated addresses whose uninitialized reads are not verified, in addition to the defaults"),
+		readAllowlistDefaults: fs.Bool("read-allowlist-defaults", true, "Do not verify uninitialized reads of precompiles and system contracts of the network"),
+		checksum:              fs.Bool("checksum-addresses", true, "Require addresses in the config file, the read allowlist, and API calls to be EIP-55 checksummed"),
+		fetchParallelism:      fs.Int("fetch-parallelism", 8, "Maximum number of concurrent RPC calls to fetch the state required to re-execute a block"),
+		auditRate:             fs.Uint64("audit-rate", 0, "Verify only about one in n blocks in event mode, sampled pseudo-randomly, 0 or 1 verifies every block"),
+		auditSeed:             fs.String("audit-seed", "", "Hex-encoded secret seed of the audit sample, to disclose the sampled blocks later (default: random)"),
+		snapshotBlocks:        fs.Uint64("snapshot-blocks", 128, "Number of recent blocks to keep verified account snapshots for, 0 disables snapshots"),
+		pressureThreshold:     fs.Float64("pressure-threshold", 0.8, "Pressure between 0 and 1 at which the node is considered under pressure"),
+		reportInterval:        fs.Duration("report-interval", 0, "Length of the periods summarized by digest reports, e.g., 24h, 0 disables reports"),
+		gcInterval:            fs.Duration("gc-interval", 10*time.Minute, "Interval at which the value log of a badger database is garbage collected, 0 disables it"),
+		gcDiscardRatio:        fs.Float64("gc-discard-ratio", 0.5, "Ratio of discarded data between 0 and 1 at which a value log file is rewritten"),
+		alertWebhook:          fs.String("alert-webhook", "", "Comma-separated URLs to post alerts to as JSON, e.g., of failed verifications (default: none)"),
+		sinkNATS:              fs.String("sink-nats", "", "URL of a NATS server to publish verified events and state diffs to, e.g., nats://localhost:4222 (default: disabled)"),
+		sinkNATSSubject:       fs.String("sink-nats-subject", "sparseth", "Subject prefix of messages published to NATS"),
+		sinkKafka:             fs.String("sink-kafka", "", "URL of a Kafka REST Proxy to publish verified events and state diffs to (default: disabled)"),
+		sinkKafkaTopic:        fs.String("sink-kafka-topic", "sparseth", "Kafka topic of published messages"),
+		sinkSQLite:            fs.String("sink-sqlite", "", "Path to a SQLite database to write verified decoded events and account balances to (default: disabled)"),
+		attestKey:             fs.String("attest-key", "", "Path to a hex-encoded private key or keystore file to sign attestations of verified roots with (default: disabled)"),
+		attestPassword:        fs.String("attest-password", "", "Path to the password file of an encrypted --attest-key"),
+		attestSigner:          fs.String("attest-signer", "", "URL of an external signer, e.g., Clef, to sign attestations of verified roots with (default: disabled)"),
+		attestAccount:         fs.String("attest-account", "", "Address of the account of the external signer to sign attestations with"),
+		peers:                 fs.String("peers", "", "Comma-separated API URLs of other nodes monitoring the same accounts to compare attestations with, requires a signer (default: none)"),
+		peerKey:               fs.String("peer-key", "", "API key sent to all peers, if they require API keys"),
+		pressureWebhook:       fs.String("pressure-webhook", "", "URL to post pressure samples to when the pressure crosses the threshold (default: none)"),
+		hooks:                 fs.String("hooks", "", "Comma-separated paths of Go plugins with verification hooks to run on each block (default: none)"),
+		resolveABIs:           fs.Bool("resolve-abis", false, "Fetch the ABIs of accounts without an ABI path from Sourcify or Etherscan (default: false)"),
+		abiCache:              fs.String("abi-cache", "/sparseth/.abis", "Path to the directory where resolved ABIs are cached"),
+		etherscanKey:          fs.String("etherscan-key", "", "Etherscan API key, ABIs are also resolved via Etherscan if set (default: Sourcify only)"),
+		watchConfig:           fs.Bool("watch-config", false, "Reload the config file on change without restarting (default: false)"),
+		logLevel:              fs.String("log-level", "debug", "Minimum level of logged messages, 'debug', 'info', 'warn', or 'error', optionally followed by per-component levels, e.g., info,state-verifier=debug"),
+		logFormat:             fs.String("log-format", internallog.FormatText, "Format of logged messages, 'text' or 'json'"),
+		logFile:               fs.String("log-file", "", "Path to a file to write logs to instead of stdout, rotated at 100 MB (default: stdout)"),
+	}
+}
+
+// runNodeCommand runs the run subcommand with the
+// specified arguments, and returns the exit code.
+//
+//	sparseth run [options]
+func runNodeCommand(args []string) int {
+	fs := newFlagSet("run", "[options]")
+	f := defineNodeFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return 2
+	}
+
+	if err := resolveOptions(fs, os.Getenv); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to resolve options: %v\n", err)
+		return 2
+	}
+	logger, closeLog, err := newLogger(f, os.Stdout)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid log options: %v\n", err)
+		return 2
+	}
+	defer closeLog()
+
+	cfg, code := loadNodeConfig(f, logger)
+	if code != 0 {
+		return code
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	n, code := newNode(ctx, cfg, f, logger)
+	if code != 0 {
+		return code
+	}
+	defer n.Shutdown()
+
+	logger.Info("start node")
+	go func() {
+		if err := n.Start(ctx); err != nil {
+			logger.Error("node run failed", "err", err)
+			cancel()
+		}
+	}()
+
+	<-ctx.Done()
+
+	if ctx.Err() != nil && !errors.Is(ctx.Err(), context.Canceled) {
+		logger.Error("shutdown due to error", "err", ctx.Err())
+		return 1
+	}
+
+	logger.Info("graceful shutdown")
+	return 0
+}
+
+// newLogger creates the logger configured by the
+// log options, which writes to the specified writer
+// unless a log file is set. The returned function
+// closes the log file, if any.
+func newLogger(f *nodeFlags, w io.Writer) (log.Logger, func(), error) {
+	levels, err := internallog.ParseLevels(*f.logLevel)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	closeLog := func() {}
+	if *f.logFile != "" {
+		file, err := internallog.OpenRotatingFile(*f.logFile, logFileSize, logFileBackups)
+		if err != nil {
+			return nil, nil, err
+		}
+		w = file
+		closeLog = func() { file.Close() }
+	}
+
+	handler, err := internallog.NewHandler(w, *f.logFormat, levels)
+	if err != nil {
+		closeLog()
+		return nil, nil, err
+	}
+	return internallog.New(handler).With("component", "main"), closeLog, nil
+}
+
+// loadNodeConfig validates the specified options, and
+// loads the config of the node, without connecting to
+// any RPC provider, except for resolving ENS names.
+// It returns a non-zero exit code on failure.
+func loadNodeConfig(f *nodeFlags, logger log.Logger) (*node.Config, int) {
+	supportedNetworks := map[string]*params.ChainConfig{
+		mainnet: userconfig.MainnetChainConfig,
+		sepolia: userconfig.SepoliaChainConfig,
+		anvil:   userconfig.AnvilChainConfig,
+	}
+
+	network := *f.network
+	checkpoints := map[string]common.Hash{
+		mainnet: userconfig.MainnetGenesisHash,
+		sepolia: userconfig.SepoliaGenesisHash,
+	}
+
+	if *f.chainConfig != "" {
+		custom, genesis, err := internalconfig.LoadChainConfig(*f.chainConfig)
+		if err != nil {
+			logger.Error("failed to load chain config", "err", err)
+			return nil, 2
+		}
+		network = fmt.Sprintf("custom (chain id %s)", custom.ChainID)
+		supportedNetworks[network] = custom
+		checkpoints[network] = genesis
+	}
+
+	chainConfig, exists := supportedNetworks[network]
+	if !exists {
+		logger.Error("unsupported network", "network", network)
+		logger.Info(fmt.Sprintf("supported networks: %s, %s, %s, or a custom network via --chain-config", mainnet, sepolia, anvil))
+		return nil, 2
+	}
+
+	checkpoint := &userconfig.Checkpoint{Hash: checkpoints[network]}
+	if *f.checkpoint == "" {
+		if network == anvil {
+			logger.Error(fmt.Sprintf("checkpoint option is required for %s network", anvil))
+			return nil, 2
+		}
+	} else {
+		cp, err := parseCheckpoint(*f.checkpoint)
+		if err != nil {
+			logger.Error("invalid checkpoint", "err", err)
+			return nil, 2
+		}
+		checkpoint = cp
+	}
+
+	endpoints, err := parseEndpoints(*f.rpcURL, *f.rpcRate, *f.rpcAllow, *f.rpcDeny)
+	if err != nil {
+		logger.Error("invalid RPC providers", "err", err)
+		return nil, 2
+	}
+
+	for _, ep := range endpoints {
+		logger.Info("using RPC provider", "url", ep.URL, "rate", ep.Rate)
+	}
+	if *f.beaconURL != "" {
+		logger.Info("using beacon API, follow finalized blocks", "url", *f.beaconURL)
+	}
+	if *f.blobsURL != "" {
+		logger.Info("using beacon API, verify blobs", "url", *f.blobsURL)
+	}
+	dbEncoding, err := ethstore.ParseEncoding(*f.dbEncoding)
+	if err != nil {
+		logger.Error("invalid database encoding", "err", err)
+		return nil, 2
+	}
+	dbEngine, err := engine.ParseEngine(*f.dbEngine)
+	if err != nil {
+		logger.Error("invalid database engine", "err", err)
+		return nil, 2
+	}
+	dbKey, err := loadEncryptionKey(*f.dbKey, *f.dbKeyFile)
+	if err != nil {
+		logger.Error("invalid database encryption key", "err", err)
+		return nil, 2
+	}
+	if dbKey != nil && dbEngine != engine.Badger {
+		logger.Error("database encryption requires the badger engine", "engine", dbEngine)
+		return nil, 2
+	}
+	logger.Info("using database", "path", *f.dbPath, "engine", dbEngine, "encoding", dbEncoding, "encrypted", dbKey != nil)
+	logger.Info("using network", "name", network)
+	logger.Info("using checkpoint", "hash", checkpoint.Hash.Hex())
+	logger.Info("using config file", "path", *f.configPath)
+	logger.Info("event mode", "enabled", *f.eventMode, "fallback", *f.allowModeFallback)
+	logger.Info("watch config", "enabled", *f.watchConfig)
+	if *f.apiAddr != "" {
+		logger.Info("using API address", "addr", *f.apiAddr)
+	}
+
+	confirmations, err := parseConfirmations(*f.confirmations)
+	if err != nil {
+		logger.Error("invalid confirmations", "err", err)
+		return nil, 2
+	}
+	confirmations.Delay = *f.processDelay
+	logger.Info("using confirmations", "depth", confirmations.Depth, "finalized", confirmations.Finalized, "delay", confirmations.Delay)
+
+	if *f.pressureThreshold <= 0 || *f.pressureThreshold > 1 {
+		logger.Error("invalid pressure threshold", "threshold", *f.pressureThreshold)
+		return nil, 2
+	}
+	if *f.gcDiscardRatio <= 0 || *f.gcDiscardRatio >= 1 {
+		logger.Error("invalid GC discard ratio", "ratio", *f.gcDiscardRatio)
+		return nil, 2
+	}
+	if *f.pressureWebhook != "" {
+		logger.Info("using pressure webhook", "url", *f.pressureWebhook)
+	}
+
+	queuePolicy, err := bus.ParsePolicy(*f.queuePolicy)
+	if err == nil && queuePolicy == bus.DropNewest {
+		err = fmt.Errorf("monitors cannot backfill the newest blocks")
+	}
+	if err != nil {
+		logger.Error("invalid monitor queue policy", "err", err)
+		return nil, 2
+	}
+	logger.Info("using monitor queue policy", "policy", queuePolicy)
+
+	if *f.processRetries < 0 {
+		logger.Error("invalid process retries", "retries", *f.processRetries)
+		return nil, 2
+	}
+	logger.Info("using process retries", "retries", *f.processRetries)
+
+	if *f.reportInterval < 0 {
+		logger.Error("invalid report interval", "interval", *f.reportInterval)
+		return nil, 2
+	}
+
+	auditSeed, err := parseAuditSeed(*f.auditSeed)
+	if err != nil {
+		logger.Error("invalid audit seed", "err", err)
+		return nil, 2
+	}
+
+	if !*f.checksum {
+		logger.Warn("address checksums disabled, typos in addresses may go unnoticed")
+	}
+
+	readAllowlist, err := parseAddresses(*f.readAllowlist, *f.checksum)
+	if err != nil {
+		logger.Error("invalid read allowlist", "err", err)
+		return nil, 2
+	}
+	logger.Info("using read allowlist", "addresses", len(readAllowlist), "defaults", *f.readAllowlistDefaults)
+
+	if *f.serveHeaders && *f.apiAddr == "" {
+		logger.Error("--serve-headers requires --api-addr")
+		return nil, 2
+	}
+	if *f.headerSource != "" {
+		if *f.beaconURL != "" {
+			logger.Warn("--beacon is ignored, as block headers are fetched from the header source")
+		}
+		logger.Info("fetch block headers from header source", "url", *f.headerSource)
+	}
+
+	var peers []string
+	for _, url := range strings.Split(*f.peers, ",") {
+		if url = strings.TrimSpace(url); url != "" {
+			peers = append(peers, url)
+		}
+	}
+	if len(peers) > 0 && *f.attestKey == "" && *f.attestSigner == "" {
+		logger.Error("peers require --attest-key or --attest-signer")
+		return nil, 2
+	}
+
+	loader := internalconfig.NewLoader(*f.checksum, logger)
+	var abiResolver *internalconfig.ABIResolver
+	if *f.resolveABIs {
+		logger.Info("resolve ABIs", "cache", *f.abiCache, "etherscan", *f.etherscanKey != "")
+		abiResolver = internalconfig.NewABIResolver(chainConfig.ChainID.Uint64(), *f.abiCache, *f.etherscanKey, logger)
+		loader.SetABIResolver(abiResolver)
+	}
+	names := newNameResolver(endpoints, checkpoint, logger)
+	loader.SetNameResolver(names.Resolve)
+	accsConfig, err := loader.Load(*f.configPath)
+	if err != nil {
+		logger.Error("failed to load config", "err", err)
+		return nil, 1
+	}
+	invariants, err := loader.LoadInvariants(*f.configPath)
+	if err != nil {
+		logger.Error("failed to load invariants", "err", err)
+		return nil, 1
+	}
+	if len(invariants) > 0 && *f.eventMode {
+		logger.Error("invariants require state monitoring mode")
+		return nil, 2
+	}
+	if *f.eventMode {
+		for _, acc := range accsConfig.Accounts {
+			if acc.Alerts != nil {
+				logger.Error("alert rules require state monitoring mode", "account", acc.Addr.Hex())
+				return nil, 2
+			}
+		}
+	}
+
+	var tenants []*userconfig.Tenant
+	if *f.apiKeys != "" {
+		tenants, err = loader.LoadTenants(*f.apiKeys)
+		if err != nil {
+			logger.Error("failed to load API keys", "err", err)
+			return nil, 1
+		}
+		logger.Info("using API keys", "tenants", len(tenants))
+		if *f.apiAdminKey != "" {
+			logger.Warn("--api-admin-key is ignored, as the admin namespace is served to unscoped tenants")
+		}
+	}
+
+	var apiOrigins []string
+	for _, origin := range strings.Split(*f.apiOrigins, ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			apiOrigins = append(apiOrigins, origin)
+		}
+	}
+
+	nodeConfig := &node.Config{
+		ChainConfig:           chainConfig,
+		Checkpoint:            checkpoint,
+		AccsConfig:            accsConfig,
+		Endpoints:             endpoints,
+		BeaconURL:             *f.beaconURL,
+		BlobsURL:              *f.blobsURL,
+		DbPath:                *f.dbPath,
+		DbEncoding:            dbEncoding,
+		DbEngine:              dbEngine,
+		DbEncryptionKey:       dbKey,
+		DbKeyRotation:         *f.dbKeyRotation,
+		IsEventMode:           *f.eventMode,
+		AllowModeFallback:     *f.allowModeFallback,
+		ConfigPath:            *f.configPath,
+		WatchConfig:           *f.watchConfig,
+		ABIResolver:           abiResolver,
+		NameResolver:          names.Resolve,
+		Invariants:            invariants,
+		ApiAddr:               *f.apiAddr,
+		Tenants:               tenants,
+		AdminKey:              *f.apiAdminKey,
+		ApiOrigins:            apiOrigins,
+		MonitorConcurrency:    *f.concurrency,
+		MonitorQueuePolicy:    queuePolicy,
+		Confirmations:         confirmations,
+		ProcessRetries:        *f.processRetries,
+		CallBudget:            *f.callBudget,
+		ReadAllowlist:         readAllowlist,
+		ReadAllowlistDefaults: *f.readAllowlistDefaults,
+		ChecksumAddresses:     *f.checksum,
+		FetchParallelism:      *f.fetchParallelism,
+		AuditRate:             *f.auditRate,
+		AuditSeed:             auditSeed,
+		SnapshotBlocks:        *f.snapshotBlocks,
+		PressureThreshold:     *f.pressureThreshold,
+		PressureWebhook:       *f.pressureWebhook,
+		ReportInterval:        *f.reportInterval,
+		GCInterval:            *f.gcInterval,
+		GCDiscardRatio:        *f.gcDiscardRatio,
+		Peers:                 peers,
+		PeerKey:               *f.peerKey,
+		ServeHeaders:          *f.serveHeaders,
+		HeaderSource:          *f.headerSource,
+		HeaderSourceKey:       *f.headerSourceKey,
+	}
+	return nodeConfig, 0
+}
+
+// newNode creates a node with the specified config,
+// along with the hooks, notifiers, signer, and sinks
+// set by the specified options. It returns a non-zero
+// exit code on failure.
+func newNode(ctx context.Context, cfg *node.Config, f *nodeFlags, logger log.Logger) (*node.Node, int) {
+	n, err := node.NewNode(ctx, cfg, logger)
+	if err != nil {
+		logger.Error("failed to create node", "err", err)
+		return nil, 1
+	}
+
+	for _, path := range strings.Split(*f.hooks, ",") {
+		if path = strings.TrimSpace(path); path == "" {
+			continue
+		}
+		h, err := hook.LoadPlugin(path)
+		if err != nil {
+			logger.Error("failed to load hook", "path", path, "err", err)
+			n.Shutdown()
+			return nil, 1
+		}
+		logger.Info("using verification hook", "name", h.Name(), "path", path)
+		n.AddHook(h)
+	}
+
+	for _, url := range strings.Split(*f.alertWebhook, ",") {
+		if url = strings.TrimSpace(url); url == "" {
+			continue
+		}
+		logger.Info("using alert webhook", "url", url)
+		n.AddNotifier(notify.NewWebhook(url))
+	}
+
+	signer, err := newSigner(ctx, *f.attestKey, *f.attestPassword, *f.attestSigner, *f.attestAccount)
+	if err != nil {
+		logger.Error("failed to create attestation signer", "err", err)
+		n.Shutdown()
+		return nil, 1
+	}
+	if signer != nil {
+		logger.Info("signing attestations", "signer", signer.Address().Hex())
+		n.SetSigner(signer)
+	}
+
+	if *f.sinkSQLite != "" {
+		if err = n.AddSQLite(*f.sinkSQLite); err != nil {
+			logger.Error("failed to create SQLite sink", "path", *f.sinkSQLite, "err", err)
+			n.Shutdown()
+			return nil, 1
+		}
+		logger.Info("using SQLite sink", "path", *f.sinkSQLite)
+	}
+
+	if *f.sinkNATS != "" {
+		pub, err := sink.NewNATS(*f.sinkNATS, *f.sinkNATSSubject)
+		if err != nil {
+			logger.Error("failed to create NATS sink", "err", err)
+			n.Shutdown()
+			return nil, 1
+		}
+		logger.Info("using NATS sink", "name", pub.Name())
+		n.AddPublisher(pub)
+	}
+
+	if *f.sinkKafka != "" {
+		pub, err := sink.NewKafka(*f.sinkKafka, *f.sinkKafkaTopic)
+		if err != nil {
+			logger.Error("failed to create Kafka sink", "err", err)
+			n.Shutdown()
+			return nil, 1
+		}
+		logger.Info("using Kafka sink", "name", pub.Name())
+		n.AddPublisher(pub)
+	}
+	return n, 0
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"sparseth/execution/monitor/state"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// runExportStateCommand runs the export-state subcommand
+// with the specified arguments, and returns the exit code.
+//
+//	sparseth export-state [--api <url>] [--api-key <key>] <file>
+func runExportStateCommand(args []string) int {
+	fs := newFlagSet("export-state", "[--api <url>] [--api-key <key>] <file>")
+	apiURL := fs.String("api", "http://localhost:8550", "URL of the JSON-RPC API of the running node")
+	apiKey := fs.String("api-key", os.Getenv("API_KEY"), "API key of an unscoped tenant, if the node requires API keys")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: sparseth export-state [--api <url>] [--api-key <key>] <file>")
+		return 2
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), configCmdTimeout)
+	defer cancel()
+
+	client, err := dialAPI(ctx, *apiURL, *apiKey)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to connect to node: %v\n", err)
+		return 1
+	}
+	defer client.Close()
+
+	var encoded hexutil.Bytes
+	if err = client.CallContext(ctx, &encoded, "admin_exportState"); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to export state: %v\n", err)
+		return 1
+	}
+
+	var export state.StateExport
+	if err = rlp.DecodeBytes(encoded, &export); err != nil || export.Header == nil {
+		fmt.Fprintf(os.Stderr, "invalid state returned by node: %v\n", err)
+		return 1
+	}
+	if err = os.WriteFile(fs.Arg(0), encoded, 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write state: %v\n", err)
+		return 1
+	}
+
+	fmt.Printf("exported state after block %d (%s) to %s\n", export.Header.Number.Uint64(), export.Header.Hash().Hex(), fs.Arg(0))
+	printResumeHint(export.Header)
+	return 0
+}
+
+// runImportStateCommand runs the import-state subcommand
+// with the specified arguments, and returns the exit code.
+//
+//	sparseth import-state [--db <path>] [--db-engine <name>] [--db-encryption-key <hex> | --db-encryption-key-file <path>] [--config <path>] <file>
+func runImportStateCommand(args []string) int {
+	fs := newFlagSet("import-state", "[--db <path>] [--db-engine <name>] [--db-encryption-key <hex> | --db-encryption-key-file <path>] [--config <path>] <file>")
+	dbPath := fs.String("db", "/sparseth/.db", "Path to the database of the node, which must not be running")
+	dbEngine := fs.String("db-engine", "badger", "Storage engine of the database, 'badger' or 'pebble'")
+	dbKey := fs.String("db-encryption-key", "", "Hex-encoded encryption key of the database, which is encrypted if set")
+	dbKeyFile := fs.String("db-encryption-key-file", "", "Path to the hex-encoded encryption key of the database, which is encrypted if set")
+	fs.String("config", "config.yaml", "Path to the config file of the node, to read database options from")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+	if err := resolveOptions(fs, os.Getenv); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to resolve options: %v\n", err)
+		return 2
+	}
+
+	encoded, err := os.ReadFile(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read state: %v\n", err)
+		return 1
+	}
+
+	key, err := loadEncryptionKey(*dbKey, *dbKeyFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid encryption key: %v\n", err)
+		return 2
+	}
+	db, err := openDatabase(*dbEngine, *dbPath, key)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to open database: %v\n", err)
+		return 1
+	}
+	defer db.Close()
+
+	header, err := state.ImportState(db, bytes.NewReader(encoded))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to import state: %v\n", err)
+		return 1
+	}
+
+	fmt.Printf("imported state after block %d (%s) into %s\n", header.Number.Uint64(), header.Hash().Hex(), *dbPath)
+	printResumeHint(header)
+	return 0
+}
+
+// printResumeHint prints how to start a node
+// from the state after the specified block.
+func printResumeHint(header *types.Header) {
+	fmt.Printf("start the node with --checkpoint %s to resume from this state\n", header.Hash().Hex())
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sparseth/node"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// runTxCommand runs the tx subcommand with the
+// specified arguments, and returns the exit code.
+//
+//	sparseth tx [--api <url>] [--api-key <key>] <hash>
+func runTxCommand(args []string) int {
+	fs := newFlagSet("tx", "[--api <url>] [--api-key <key>] <hash>")
+	apiURL := fs.String("api", "http://localhost:8550", "URL of the JSON-RPC API of the running node")
+	apiKey := fs.String("api-key", os.Getenv("API_KEY"), "API key, if the node requires API keys")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: sparseth tx [--api <url>] [--api-key <key>] <hash>")
+		return 2
+	}
+
+	raw, err := hexutil.Decode(fs.Arg(0))
+	if err != nil || len(raw) != common.HashLength {
+		fmt.Fprintf(os.Stderr, "invalid tx hash %s\n", fs.Arg(0))
+		return 2
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), configCmdTimeout)
+	defer cancel()
+
+	client, err := dialAPI(ctx, *apiURL, *apiKey)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to connect to node: %v\n", err)
+		return 1
+	}
+	defer client.Close()
+
+	var outcome *node.TxOutcome
+	if err = client.CallContext(ctx, &outcome, "stats_txOutcome", common.BytesToHash(raw)); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to look up tx: %v\n", err)
+		return 1
+	}
+
+	status := "succeeded"
+	if outcome.Status == 0 {
+		status = "reverted"
+	}
+	verdict := "verified"
+	if !outcome.Verified {
+		verdict = "NOT verified, block failed verification"
+	}
+	fmt.Printf("tx %s %s in block %d (%s): %s\n", outcome.TxHash.Hex(), status, outcome.BlockNumber, outcome.BlockHash.Hex(), verdict)
+	for _, addr := range outcome.Accounts {
+		fmt.Printf("    %s\n", addr.Hex())
+	}
+	return 0
+}
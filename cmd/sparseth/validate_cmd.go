@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// runValidateConfigCommand runs the validate-config
+// subcommand with the specified arguments, and returns
+// the exit code. It accepts all options of the node.
+//
+//	sparseth validate-config [options] [<path>]
+func runValidateConfigCommand(args []string) int {
+	fs := newFlagSet("validate-config", "[options] [<path>]")
+	f := defineNodeFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() > 1 {
+		fs.Usage()
+		return 2
+	}
+	if fs.NArg() == 1 {
+		if err := fs.Set("config", fs.Arg(0)); err != nil {
+			return 2
+		}
+	}
+
+	// Invalid node options are
+	// part of the config file
+	if err := resolveOptions(fs, os.Getenv); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to resolve options: %v\n", err)
+		return 1
+	}
+	logger, closeLog, err := newLogger(f, os.Stdout)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid log options: %v\n", err)
+		return 1
+	}
+	defer closeLog()
+
+	cfg, code := loadNodeConfig(f, logger)
+	if code != 0 {
+		return code
+	}
+
+	fmt.Printf("%s is valid: %d accounts, %d invariants, %d API keys\n", cfg.ConfigPath, len(cfg.AccsConfig.Accounts), len(cfg.Invariants), len(cfg.Tenants))
+	return 0
+}
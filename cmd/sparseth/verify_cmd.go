@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sparseth/node"
+	"strconv"
+	"syscall"
+)
+
+// runVerifyBlockCommand runs the verify-block subcommand
+// with the specified arguments, and returns the exit code.
+// The exit code is non-zero if a mismatch was found.
+//
+//	sparseth verify-block [options] [--json] <from> [<to>]
+//	sparseth verify-block [options] [--json] --number <n>
+func runVerifyBlockCommand(args []string) int {
+	fs := newFlagSet("verify-block", "[options] [--json] <from> [<to>] | --number <n>")
+	f := defineNodeFlags(fs)
+	number := fs.Uint64("number", 0, "Number of a single block to verify, instead of a range")
+	asJSON := fs.Bool("json", false, "Print the report as JSON, and only warnings and errors to stderr")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	blockArgs := fs.Args()
+	if isFlagSet(fs, "number") {
+		if len(blockArgs) > 0 {
+			fmt.Fprintln(os.Stderr, "--number cannot be combined with a block range")
+			return 2
+		}
+		blockArgs = []string{strconv.FormatUint(*number, 10)}
+	}
+	blockRange, err := parseBlockRange(blockArgs)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid block range: %v\n", err)
+		fs.Usage()
+		return 2
+	}
+
+	if err = resolveOptions(fs, os.Getenv); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to resolve options: %v\n", err)
+		return 2
+	}
+	logOut := io.Writer(os.Stdout)
+	if *asJSON {
+		// Keep stdout for the report
+		logOut = os.Stderr
+		if !isFlagSet(fs, "log-level") {
+			*f.logLevel = "warn"
+		}
+	}
+	logger, closeLog, err := newLogger(f, logOut)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid log options: %v\n", err)
+		return 2
+	}
+	defer closeLog()
+
+	if *f.beaconURL != "" {
+		logger.Error("block range cannot be verified with beacon API")
+		return 2
+	}
+
+	cfg, code := loadNodeConfig(f, logger)
+	if code != 0 {
+		return code
+	}
+	for _, acc := range cfg.AccsConfig.Accounts {
+		if !acc.StartedAt(blockRange.To) {
+			logger.Error("start block of account is after the block range", "account", acc.Addr.Hex(), "startBlock", acc.StartBlock, "toBlock", blockRange.To)
+			return 2
+		}
+	}
+	cfg.Range = blockRange
+	logger.Info("verify block range", "from", blockRange.From, "to", blockRange.To)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	n, code := newNode(ctx, cfg, f, logger)
+	if code != 0 {
+		return code
+	}
+
+	report, err := n.VerifyRange(ctx)
+	n.Shutdown()
+	if err != nil {
+		logger.Error("failed to verify block range", "err", err)
+		return 1
+	}
+
+	if *asJSON {
+		if err = json.NewEncoder(os.Stdout).Encode(report); err != nil {
+			logger.Error("failed to encode report", "err", err)
+			return 1
+		}
+	} else {
+		printReport(report)
+	}
+	if len(report.Mismatches) > 0 || len(report.FailedAccounts) > 0 {
+		return 1
+	}
+	return 0
+}
+
+// parseBlockRange parses the block range to verify
+// from the specified arguments, i.e., the first and
+// the optional last block, which defaults to the
+// first block.
+func parseBlockRange(args []string) (*node.BlockRange, error) {
+	if len(args) == 0 || len(args) > 2 {
+		return nil, fmt.Errorf("expected 1 or 2 block numbers, got %d", len(args))
+	}
+
+	nums := make([]uint64, len(args))
+	for i, arg := range args {
+		num, err := strconv.ParseUint(arg, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid block number %q", arg)
+		}
+		nums[i] = num
+	}
+
+	from, to := nums[0], nums[len(nums)-1]
+	if from > to {
+		return nil, fmt.Errorf("first block %d is after last block %d", from, to)
+	}
+	return &node.BlockRange{From: from, To: to}, nil
+}
+
+// isFlagSet checks whether the flag with the specified
+// name is set on the command line of the specified
+// flag set.
+func isFlagSet(fs *flag.FlagSet, name string) bool {
+	set := false
+	fs.Visit(func(f *flag.Flag) {
+		set = set || f.Name == name
+	})
+	return set
+}
+
+// printReport prints the specified range report.
+func printReport(report *node.RangeReport) {
+	for _, m := range report.Mismatches {
+		fmt.Printf("! block %d (%s) %s: %s\n", m.Number, m.Hash.Hex(), m.Monitor, m.Error)
+	}
+	for _, v := range report.Failures {
+		fmt.Printf("! block %d %s %s %s: %s\n", v.Number, v.Account.Hex(), v.Source, v.Kind, v.Message)
+		if v.Expected != "" || v.Actual != "" {
+			fmt.Printf("    expected %s, got %s\n", v.Expected, v.Actual)
+		}
+	}
+	for _, addr := range report.FailedAccounts {
+		fmt.Printf("! account %s failed\n", addr.Hex())
+	}
+
+	fmt.Printf("blocks %d to %d: %d processed, %d verified\n", report.From, report.To, report.Blocks, report.Verified)
+	fmt.Printf("accounts: %d verified, %d failed, %d mismatches found\n", report.VerifiedAccounts(), len(report.FailedAccounts), len(report.Mismatches))
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+// version is the version of sparseth, which
+// is set at build time, see the Makefile.
+var version = "dev"
+
+// runVersionCommand runs the version subcommand with
+// the specified arguments, and returns the exit code.
+//
+//	sparseth version
+func runVersionCommand(args []string) int {
+	fs := newFlagSet("version", "")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	fmt.Printf("sparseth %s\n", version)
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return 0
+	}
+
+	fmt.Printf("go: %s\n", info.GoVersion)
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision", "vcs.time", "vcs.modified":
+			fmt.Printf("%s: %s\n", setting.Key, setting.Value)
+		}
+	}
+	return 0
+}
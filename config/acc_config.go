@@ -21,6 +21,29 @@ type AccountConfig struct {
 	// params for a contract account for both
 	// event and state monitoring.
 	ContractConfig *ContractConfig
+	// Proxy defines the monitoring params if the
+	// account is an upgradeable proxy, or is nil
+	// otherwise.
+	Proxy *ProxyConfig
+	// Alerts defines the alert rules evaluated
+	// against the verified state of the account,
+	// or is nil if no rules are defined.
+	Alerts *AlertRules
+	// CallBudget is the maximum number of RPC calls
+	// per block spent on re-executing transactions
+	// of the account, or zero to use the default.
+	CallBudget uint64
+	// StartBlock is the first block processed for
+	// the account, e.g., its deployment height.
+	// Earlier blocks are ignored for the account.
+	StartBlock uint64
+}
+
+// StartedAt checks whether the account is
+// monitored at the specified block number,
+// see StartBlock.
+func (a *AccountConfig) StartedAt(num uint64) bool {
+	return num >= a.StartBlock
 }
 
 // Contains checks whether the specified
@@ -50,6 +73,10 @@ type EventConfig struct {
 	// HeadSlot specifies the storage location
 	// of the event hash chain head.
 	HeadSlot common.Hash
+	// InitialHead is the hash chain head
+	// before the first processed block,
+	// unless a verified head is stored.
+	InitialHead common.Hash
 }
 
 // SparseConfig defines the monitoring params
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"strings"
+)
+
+var (
+	// ErrInvalidAddress is returned if an
+	// address is not a 0x-prefixed 20-byte
+	// hex string.
+	ErrInvalidAddress = errors.New("invalid address")
+
+	// ErrInvalidChecksum is returned if an
+	// address does not match its EIP-55
+	// checksummed form.
+	ErrInvalidChecksum = errors.New("invalid address checksum")
+)
+
+// ValidateAddress checks whether the specified string
+// is a 0x-prefixed 20-byte hex address. The format is
+// the same on all EVM chains.
+//
+// If checksum is set, the address must match its
+// EIP-55 checksummed form exactly, so that typos are
+// detected. Otherwise, any casing is accepted, but a
+// mixed-case address must still carry a valid
+// checksum, as it is likely a typo otherwise.
+func ValidateAddress(s string, checksum bool) error {
+	hex, ok := strings.CutPrefix(s, "0x")
+	if !ok || len(hex) != 2*common.AddressLength {
+		return fmt.Errorf("%w: %s", ErrInvalidAddress, s)
+	}
+	if _, err := hexutil.Decode("0x" + hex); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidAddress, s)
+	}
+
+	expected := common.HexToAddress(s).Hex()
+	if s == expected {
+		return nil
+	}
+
+	isMixedCase := strings.ToLower(hex) != hex && strings.ToUpper(hex) != hex
+	if checksum || isMixedCase {
+		return fmt.Errorf("%w: %s, expected %s", ErrInvalidChecksum, s, expected)
+	}
+	return nil
+}
+
+// ChecksumAddress is an address that is encoded in
+// its EIP-55 checksummed form, e.g., in API responses,
+// rather than in lowercase.
+type ChecksumAddress common.Address
+
+// Address returns the underlying address.
+func (a ChecksumAddress) Address() common.Address {
+	return common.Address(a)
+}
+
+// Hex returns the EIP-55 checksummed
+// form of the address.
+func (a ChecksumAddress) Hex() string {
+	return common.Address(a).Hex()
+}
+
+// MarshalText encodes the address in
+// its EIP-55 checksummed form.
+func (a ChecksumAddress) MarshalText() ([]byte, error) {
+	return []byte(a.Hex()), nil
+}
+
+// UnmarshalText decodes a hex address
+// in any casing.
+func (a *ChecksumAddress) UnmarshalText(input []byte) error {
+	return (*common.Address)(a).UnmarshalText(input)
+}
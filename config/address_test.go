@@ -0,0 +1,60 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/ethereum/go-ethereum/common"
+	"testing"
+)
+
+func TestValidateAddress(t *testing.T) {
+	checksummed := "0xDeaDbeefdEAdbeefdEadbEEFdeadbeEFdEaDbeeF"
+
+	t.Run("should accept checksummed address", func(t *testing.T) {
+		if err := ValidateAddress(checksummed, true); err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("should reject lowercase address if checksum is required", func(t *testing.T) {
+		err := ValidateAddress("0xdeadbeefdeadbeefdeadbeefdeadbeefdeadbeef", true)
+		if !errors.Is(err, ErrInvalidChecksum) {
+			t.Errorf("expected invalid checksum, got %v", err)
+		}
+	})
+
+	t.Run("should accept lowercase address if checksum is not required", func(t *testing.T) {
+		if err := ValidateAddress("0xdeadbeefdeadbeefdeadbeefdeadbeefdeadbeef", false); err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("should reject mixed-case address with invalid checksum", func(t *testing.T) {
+		err := ValidateAddress("0xDeaDbeefdEAdbeefdEadbEEFdeadbeEFdEaDbeef", false)
+		if !errors.Is(err, ErrInvalidChecksum) {
+			t.Errorf("expected invalid checksum, got %v", err)
+		}
+	})
+
+	t.Run("should reject malformed address", func(t *testing.T) {
+		for _, addr := range []string{"", "deadbeefdeadbeefdeadbeefdeadbeefdeadbeef", "0xdeadbeef", "0xzzadbeefdeadbeefdeadbeefdeadbeefdeadbeef"} {
+			if err := ValidateAddress(addr, false); !errors.Is(err, ErrInvalidAddress) {
+				t.Errorf("expected invalid address for %q, got %v", addr, err)
+			}
+		}
+	})
+}
+
+func TestChecksumAddress_MarshalText(t *testing.T) {
+	t.Run("should encode checksummed address", func(t *testing.T) {
+		addr := ChecksumAddress(common.HexToAddress("0xdeadbeefdeadbeefdeadbeefdeadbeefdeadbeef"))
+
+		data, err := json.Marshal(addr)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if string(data) != `"0xDeaDbeefdEAdbeefdEadbEEFdeadbeEFdEaDbeeF"` {
+			t.Errorf("expected checksummed address, got %s", data)
+		}
+	})
+}
@@ -0,0 +1,20 @@
+package config
+
+import (
+	"math/big"
+)
+
+// AlertRules defines conditions on the verified
+// state of an account that raise an alert.
+type AlertRules struct {
+	// MinBalance raises an alert once the balance
+	// drops below it, or is nil if disabled.
+	MinBalance *big.Int
+	// MaxOutgoing raises an alert once the balance
+	// decreases by more than it within a single
+	// block, or is nil if disabled.
+	MaxOutgoing *big.Int
+	// CodeChange raises an alert once
+	// the code of the account changes.
+	CodeChange bool
+}
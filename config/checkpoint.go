@@ -0,0 +1,41 @@
+package config
+
+import (
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Checkpoint identifies the block the
+// node starts syncing from.
+type Checkpoint struct {
+	// Hash is the hash of the block.
+	Hash common.Hash
+	// Number is optional, it is only
+	// checked against the block if set.
+	Number *uint64
+	// StateRoot is optional, it is only
+	// checked against the block if set.
+	StateRoot *common.Hash
+	// Time is optional, it is only
+	// checked against the block if set.
+	Time *uint64
+}
+
+// Verify checks whether the specified
+// header matches the checkpoint.
+func (c *Checkpoint) Verify(header *types.Header) error {
+	if hash := header.Hash(); hash != c.Hash {
+		return fmt.Errorf("hash mismatch: expected %s, got %s", c.Hash.Hex(), hash.Hex())
+	}
+	if c.Number != nil && header.Number.Uint64() != *c.Number {
+		return fmt.Errorf("number mismatch: expected %d, got %d", *c.Number, header.Number.Uint64())
+	}
+	if c.StateRoot != nil && header.Root != *c.StateRoot {
+		return fmt.Errorf("state root mismatch: expected %s, got %s", c.StateRoot.Hex(), header.Root.Hex())
+	}
+	if c.Time != nil && header.Time != *c.Time {
+		return fmt.Errorf("timestamp mismatch: expected %d, got %d", *c.Time, header.Time)
+	}
+	return nil
+}
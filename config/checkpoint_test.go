@@ -0,0 +1,46 @@
+package config
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"math/big"
+	"testing"
+)
+
+func TestCheckpoint_Verify(t *testing.T) {
+	header := &types.Header{
+		Number: big.NewInt(10),
+		Root:   common.HexToHash("0xaa"),
+		Time:   1000,
+	}
+	num, root, time := uint64(10), header.Root, uint64(1000)
+
+	t.Run("should accept matching header", func(t *testing.T) {
+		cp := &Checkpoint{Hash: header.Hash(), Number: &num, StateRoot: &root, Time: &time}
+		if err := cp.Verify(header); err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("should accept bare hash", func(t *testing.T) {
+		cp := &Checkpoint{Hash: header.Hash()}
+		if err := cp.Verify(header); err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("should reject mismatching state root", func(t *testing.T) {
+		other := common.HexToHash("0xbb")
+		cp := &Checkpoint{Hash: header.Hash(), StateRoot: &other}
+		if err := cp.Verify(header); err == nil {
+			t.Errorf("expected error, got nil")
+		}
+	})
+
+	t.Run("should reject mismatching hash", func(t *testing.T) {
+		cp := &Checkpoint{Hash: common.HexToHash("0x01")}
+		if err := cp.Verify(header); err == nil {
+			t.Errorf("expected error, got nil")
+		}
+	})
+}
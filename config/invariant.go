@@ -0,0 +1,87 @@
+package config
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"math/big"
+)
+
+// Invariant is a predicate over the verified
+// state of monitored accounts, which must hold
+// after every verified block, e.g., reserves
+// must cover the total supply.
+type Invariant struct {
+	// Name identifies the invariant in alerts.
+	Name string
+	// Expr is the predicate as declared.
+	Expr  string
+	Left  *Operand
+	Op    Comparison
+	Right *Operand
+}
+
+// Comparison is the comparison
+// operator of an invariant.
+type Comparison string
+
+// Supported comparison operators.
+const (
+	Equal          Comparison = "=="
+	NotEqual       Comparison = "!="
+	Less           Comparison = "<"
+	LessOrEqual    Comparison = "<="
+	Greater        Comparison = ">"
+	GreaterOrEqual Comparison = ">="
+)
+
+// Holds checks whether the comparison holds
+// for the specified result of a.Cmp(b).
+func (c Comparison) Holds(cmp int) bool {
+	switch c {
+	case Equal:
+		return cmp == 0
+	case NotEqual:
+		return cmp != 0
+	case Less:
+		return cmp < 0
+	case LessOrEqual:
+		return cmp <= 0
+	case Greater:
+		return cmp > 0
+	case GreaterOrEqual:
+		return cmp >= 0
+	default:
+		return false
+	}
+}
+
+// OperandKind is the kind of
+// value an operand refers to.
+type OperandKind int
+
+const (
+	// OperandConst is a constant.
+	OperandConst OperandKind = iota
+	// OperandBalance is the balance
+	// of an account in wei.
+	OperandBalance
+	// OperandNonce is the nonce
+	// of an account.
+	OperandNonce
+	// OperandSlot is a storage slot of an
+	// account, read as unsigned integer.
+	OperandSlot
+)
+
+// Operand is a side of an invariant.
+type Operand struct {
+	Kind OperandKind
+	// Account is the account the
+	// operand refers to, if any.
+	Account common.Address
+	// Slot is the storage slot, if
+	// the operand is a slot.
+	Slot common.Hash
+	// Value is the value, if the
+	// operand is a constant.
+	Value *big.Int
+}
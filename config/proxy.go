@@ -0,0 +1,23 @@
+package config
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// EIP1967ImplementationSlot is the storage location
+// of the implementation of an EIP-1967 proxy, i.e.,
+// keccak256("eip1967.proxy.implementation") - 1.
+var EIP1967ImplementationSlot = common.HexToHash("0x360894a13ba1a3210667c828492db98dca3e2076cc3735a920a3ca505d382bbc")
+
+// ProxyConfig defines the monitoring params
+// for an upgradeable proxy contract.
+type ProxyConfig struct {
+	// ImplementationSlot specifies the storage
+	// location of the implementation address.
+	ImplementationSlot common.Hash
+	// Implementation is the implementation whose
+	// ABI is used for the events of the proxy, or
+	// the zero address if the ABI is specified
+	// explicitly.
+	Implementation common.Address
+}
@@ -0,0 +1,18 @@
+package config
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"math/big"
+	"testing"
+)
+
+func TestEIP1967ImplementationSlot(t *testing.T) {
+	t.Run("should match derived slot", func(t *testing.T) {
+		hash := crypto.Keccak256Hash([]byte("eip1967.proxy.implementation")).Big()
+		want := common.BigToHash(hash.Sub(hash, big.NewInt(1)))
+		if EIP1967ImplementationSlot != want {
+			t.Errorf("expected %s, got %s", want.Hex(), EIP1967ImplementationSlot.Hex())
+		}
+	})
+}
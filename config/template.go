@@ -0,0 +1,95 @@
+package config
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Template is a built-in monitoring config for a
+// standard contract, so that neither an ABI file
+// nor storage slots need to be specified.
+//
+// Templates assume a contract that inherits the
+// hash chain head and the interaction counter
+// first, i.e., at slots 0 and 1, followed by the
+// OpenZeppelin (v5) implementation of the standard.
+type Template struct {
+	// Name selects the template in the
+	// config file, e.g., erc20.
+	Name string
+	// ABI is the JSON ABI with
+	// the standard events.
+	ABI string
+	// HeadSlot is the default storage location
+	// of the event hash chain head.
+	HeadSlot common.Hash
+	// CountSlot is the default storage location
+	// of the interaction counter.
+	CountSlot common.Hash
+	// BalancesSlot is the storage location of
+	// the mapping of balances by holder.
+	BalancesSlot common.Hash
+	// TotalSupplySlot is the storage location of
+	// the total supply, or the zero hash if the
+	// total supply is not stored.
+	TotalSupplySlot common.Hash
+}
+
+// BalanceSlot returns the storage location of
+// the balance of the specified holder, i.e.,
+// keccak256(holder . BalancesSlot).
+func (t *Template) BalanceSlot(holder common.Address) common.Hash {
+	return crypto.Keccak256Hash(common.LeftPadBytes(holder.Bytes(), common.HashLength), t.BalancesSlot.Bytes())
+}
+
+// erc20ABI holds the standard
+// events of ERC-20 tokens.
+const erc20ABI = `[
+{"anonymous":false,"inputs":[{"indexed":true,"name":"from","type":"address"},{"indexed":true,"name":"to","type":"address"},{"indexed":false,"name":"value","type":"uint256"}],"name":"Transfer","type":"event"},
+{"anonymous":false,"inputs":[{"indexed":true,"name":"owner","type":"address"},{"indexed":true,"name":"spender","type":"address"},{"indexed":false,"name":"value","type":"uint256"}],"name":"Approval","type":"event"}
+]`
+
+// erc721ABI holds the standard
+// events of ERC-721 tokens.
+const erc721ABI = `[
+{"anonymous":false,"inputs":[{"indexed":true,"name":"from","type":"address"},{"indexed":true,"name":"to","type":"address"},{"indexed":true,"name":"tokenId","type":"uint256"}],"name":"Transfer","type":"event"},
+{"anonymous":false,"inputs":[{"indexed":true,"name":"owner","type":"address"},{"indexed":true,"name":"approved","type":"address"},{"indexed":true,"name":"tokenId","type":"uint256"}],"name":"Approval","type":"event"},
+{"anonymous":false,"inputs":[{"indexed":true,"name":"owner","type":"address"},{"indexed":true,"name":"operator","type":"address"},{"indexed":false,"name":"approved","type":"bool"}],"name":"ApprovalForAll","type":"event"}
+]`
+
+// templates holds the built-in
+// templates by name.
+var templates = map[string]*Template{
+	"erc20": {
+		Name:      "erc20",
+		ABI:       erc20ABI,
+		HeadSlot:  slot(0),
+		CountSlot: slot(1),
+		// Followed by _balances, _allowances,
+		// and _totalSupply
+		BalancesSlot:    slot(2),
+		TotalSupplySlot: slot(4),
+	},
+	"erc721": {
+		Name:      "erc721",
+		ABI:       erc721ABI,
+		HeadSlot:  slot(0),
+		CountSlot: slot(1),
+		// Followed by _name, _symbol,
+		// _owners, and _balances
+		BalancesSlot: slot(5),
+	},
+}
+
+// LookupTemplate returns the built-in
+// template with the specified name.
+func LookupTemplate(name string) (*Template, bool) {
+	t, ok := templates[name]
+	return t, ok
+}
+
+// slot returns the specified
+// storage slot as a hash.
+func slot(n byte) common.Hash {
+	return common.Hash{common.HashLength - 1: n}
+}
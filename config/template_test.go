@@ -0,0 +1,38 @@
+package config
+
+import (
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"strings"
+	"testing"
+)
+
+func TestTemplates(t *testing.T) {
+	// keccak256("Transfer(address,address,uint256)")
+	transfer := common.HexToHash("0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef")
+
+	for _, name := range []string{"erc20", "erc721"} {
+		t.Run("should parse ABI of "+name, func(t *testing.T) {
+			tmpl, ok := LookupTemplate(name)
+			if !ok {
+				t.Fatalf("expected template %s", name)
+			}
+			parsed, err := abi.JSON(strings.NewReader(tmpl.ABI))
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if parsed.Events["Transfer"].ID != transfer {
+				t.Errorf("expected Transfer event with ID %s, got %s", transfer.Hex(), parsed.Events["Transfer"].ID.Hex())
+			}
+		})
+	}
+
+	t.Run("should derive distinct balance slots", func(t *testing.T) {
+		tmpl, _ := LookupTemplate("erc20")
+		a := tmpl.BalanceSlot(common.HexToAddress("0x1"))
+		b := tmpl.BalanceSlot(common.HexToAddress("0x2"))
+		if a == b || a == tmpl.BalancesSlot {
+			t.Errorf("expected distinct balance slots, got %s and %s", a.Hex(), b.Hex())
+		}
+	})
+}
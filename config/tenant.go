@@ -0,0 +1,32 @@
+package config
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"slices"
+)
+
+// Tenant is a consumer of the JSON-RPC API,
+// identified by its API key.
+type Tenant struct {
+	// Name identifies the tenant in logs.
+	Name string
+	// Key is the secret API key of the tenant.
+	Key string
+	// Accounts holds the monitored accounts visible
+	// to the tenant. If nil, all accounts are visible,
+	// and the tenant may manage them.
+	Accounts []common.Address
+}
+
+// Scoped checks whether the tenant is restricted
+// to a subset of the monitored accounts. A nil
+// tenant is not scoped.
+func (t *Tenant) Scoped() bool {
+	return t != nil && t.Accounts != nil
+}
+
+// Allows checks whether the specified
+// account is visible to the tenant.
+func (t *Tenant) Allows(addr common.Address) bool {
+	return !t.Scoped() || slices.Contains(t.Accounts, addr)
+}
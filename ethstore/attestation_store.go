@@ -0,0 +1,77 @@
+package ethstore
+
+import (
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+	"sparseth/storage"
+)
+
+// Attestation is a signed statement of the
+// operator of a node that it verified the
+// storage root or the event hash chain head
+// of an account at a block.
+type Attestation struct {
+	Number    uint64
+	BlockHash common.Hash
+	Account   common.Address
+	// Root is the verified storage root in
+	// sparse mode, or the verified hash chain
+	// head in event mode.
+	Root common.Hash
+	// Signer is the address of the key
+	// that produced the signature.
+	Signer    common.Address
+	Signature []byte
+}
+
+// AttestationStore provides thread-safe storage
+// of attestations by block number.
+type AttestationStore struct {
+	db storage.KeyValStore
+}
+
+// NewAttestationStore creates a new AttestationStore
+// using the specified key-val store.
+func NewAttestationStore(db storage.KeyValStore) *AttestationStore {
+	return &AttestationStore{
+		db: db,
+	}
+}
+
+// Put stores the specified attestation. An
+// attestation previously stored for the same
+// block and account is overwritten.
+func (s *AttestationStore) Put(a *Attestation) error {
+	encoded, err := rlp.EncodeToBytes(a)
+	if err != nil {
+		return fmt.Errorf("failed to encode attestation: %w", err)
+	}
+
+	if err = s.db.Put(attestationKey(a.Number, a.Account), encoded); err != nil {
+		return fmt.Errorf("failed to put attestation: %w", err)
+	}
+	return nil
+}
+
+// Get returns all stored attestations of
+// the block with the specified number, by
+// account.
+func (s *AttestationStore) Get(num uint64) ([]*Attestation, error) {
+	it := s.db.NewIterator(attestationBlockPrefix(num), nil)
+	defer it.Release()
+
+	var attestations []*Attestation
+	for it.Next() {
+		var a Attestation
+		if err := rlp.DecodeBytes(it.Value(), &a); err != nil {
+			return nil, fmt.Errorf("failed to decode attestation: %w", err)
+		}
+		attestations = append(attestations, &a)
+	}
+	if err := it.Error(); err != nil {
+		return nil, fmt.Errorf("failed to iterate attestations: %w", err)
+	}
+
+	return attestations, nil
+}
@@ -0,0 +1,54 @@
+package ethstore
+
+import (
+	"math/big"
+	"sparseth/storage/mem"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestAttestationStore_Get(t *testing.T) {
+	t.Run("should return attestations of block", func(t *testing.T) {
+		db := mem.New()
+		defer db.Close()
+
+		store := NewAttestationStore(db)
+		for i, num := range []uint64{1, 2, 2, 3} {
+			a := &Attestation{
+				Number:    num,
+				BlockHash: common.HexToHash("0x01"),
+				Account:   common.BigToAddress(big.NewInt(int64(i))),
+				Root:      common.HexToHash("0x03"),
+				Signature: []byte{0x04},
+			}
+			if err := store.Put(a); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+		}
+
+		attestations, err := store.Get(2)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(attestations) != 2 || attestations[0].Number != 2 || attestations[1].Number != 2 {
+			t.Fatalf("expected 2 attestations of block 2, got %+v", attestations)
+		}
+		if attestations[0].Root != common.HexToHash("0x03") || len(attestations[0].Signature) != 1 {
+			t.Errorf("expected stored attestation, got %+v", attestations[0])
+		}
+	})
+
+	t.Run("should return no attestations of empty store", func(t *testing.T) {
+		db := mem.New()
+		defer db.Close()
+
+		attestations, err := NewAttestationStore(db).Get(0)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(attestations) != 0 {
+			t.Errorf("expected no attestations, got %d", len(attestations))
+		}
+	})
+}
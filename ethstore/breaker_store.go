@@ -0,0 +1,66 @@
+package ethstore
+
+import (
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+	"sparseth/storage"
+)
+
+// TrippedBreaker is the circuit breaker of an
+// account that tripped at a block, as the
+// account exceeded its RPC budget.
+type TrippedBreaker struct {
+	Account common.Address
+	Number  uint64
+}
+
+// BreakerStore provides thread-safe storage
+// of tripped circuit breakers by account.
+type BreakerStore struct {
+	db storage.KeyValStore
+}
+
+// NewBreakerStore creates a new BreakerStore
+// using the specified key-val store.
+func NewBreakerStore(db storage.KeyValStore) *BreakerStore {
+	return &BreakerStore{
+		db: db,
+	}
+}
+
+// Put stores the specified tripped breaker. A
+// breaker previously stored for the same account
+// is overwritten.
+func (s *BreakerStore) Put(b *TrippedBreaker) error {
+	encoded, err := rlp.EncodeToBytes(b)
+	if err != nil {
+		return fmt.Errorf("failed to encode breaker: %w", err)
+	}
+
+	if err = s.db.Put(breakerKey(b.Account), encoded); err != nil {
+		return fmt.Errorf("failed to put breaker: %w", err)
+	}
+	return nil
+}
+
+// All returns all stored tripped
+// breakers, by account.
+func (s *BreakerStore) All() ([]*TrippedBreaker, error) {
+	it := s.db.NewIterator(breakerPrefix, nil)
+	defer it.Release()
+
+	var breakers []*TrippedBreaker
+	for it.Next() {
+		var b TrippedBreaker
+		if err := rlp.DecodeBytes(it.Value(), &b); err != nil {
+			return nil, fmt.Errorf("failed to decode breaker: %w", err)
+		}
+		breakers = append(breakers, &b)
+	}
+	if err := it.Error(); err != nil {
+		return nil, fmt.Errorf("failed to iterate breakers: %w", err)
+	}
+
+	return breakers, nil
+}
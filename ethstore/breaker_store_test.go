@@ -0,0 +1,48 @@
+package ethstore
+
+import (
+	"sparseth/storage/mem"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestBreakerStore_All(t *testing.T) {
+	t.Run("should return no breakers if none tripped", func(t *testing.T) {
+		db := mem.New()
+		defer db.Close()
+
+		breakers, err := NewBreakerStore(db).All()
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(breakers) != 0 {
+			t.Errorf("expected no breakers, got %d", len(breakers))
+		}
+	})
+
+	t.Run("should return last breaker of each account", func(t *testing.T) {
+		db := mem.New()
+		defer db.Close()
+
+		store := NewBreakerStore(db)
+		first := common.HexToAddress("0x1")
+		second := common.HexToAddress("0x2")
+		for _, b := range []*TrippedBreaker{{first, 1}, {second, 2}, {first, 3}} {
+			if err := store.Put(b); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+		}
+
+		breakers, err := store.All()
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(breakers) != 2 {
+			t.Fatalf("expected 2 breakers, got %d", len(breakers))
+		}
+		if breakers[0].Account != first || breakers[0].Number != 3 || breakers[1].Account != second {
+			t.Errorf("expected breakers of both accounts, got %+v and %+v", breakers[0], breakers[1])
+		}
+	})
+}
@@ -0,0 +1,179 @@
+package ethstore
+
+import (
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+	"slices"
+	"sparseth/storage"
+	"sync"
+)
+
+var (
+	// ErrChainHeadNotFound is returned when no
+	// hash chain head is stored for an account
+	// at or before a block.
+	ErrChainHeadNotFound = errors.New("chain head not found")
+)
+
+// ChainHead is the verified event hash chain
+// head of an account after a block.
+type ChainHead struct {
+	Number    uint64
+	BlockHash common.Hash
+	Head      common.Hash
+	// Logs identify the logs verified with
+	// the block, so that they can be removed
+	// if the block is reorged.
+	Logs []*LogID `rlp:"optional"`
+}
+
+// LogID identifies a stored log, along with
+// the block that emitted it.
+type LogID struct {
+	BlockNumber uint64
+	TxHash      common.Hash
+	Index       uint64
+}
+
+// ChainHeadStore provides thread-safe storage of
+// the verified event hash chain heads of accounts
+// by block number.
+type ChainHeadStore struct {
+	db storage.KeyValStore
+	mu sync.RWMutex
+}
+
+// NewChainHeadStore creates a new ChainHeadStore
+// using the specified key-val store.
+func NewChainHeadStore(db storage.KeyValStore) *ChainHeadStore {
+	return &ChainHeadStore{
+		db: db,
+	}
+}
+
+// At retrieves the most recent hash chain head
+// of the specified account stored at or before
+// the specified block number.
+func (s *ChainHeadStore) At(addr common.Address, num uint64) (*ChainHead, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	key := chainHeadKey(addr, num)
+	prefixLen := len(chainHeadPrefix) + common.AddressLength
+
+	it := s.db.NewReverseIterator(key[:prefixLen], key[prefixLen:])
+	defer it.Release()
+
+	if !it.Next() {
+		if err := it.Error(); err != nil {
+			return nil, fmt.Errorf("failed to iterate chain heads: %w", err)
+		}
+		return nil, ErrChainHeadNotFound
+	}
+
+	var head ChainHead
+	if err := rlp.DecodeBytes(it.Value(), &head); err != nil {
+		return nil, fmt.Errorf("failed to decode chain head: %w", err)
+	}
+	return &head, nil
+}
+
+// Range retrieves all hash chain heads of the
+// specified account stored between the specified
+// block numbers, both inclusive, in ascending
+// order.
+func (s *ChainHeadStore) Range(addr common.Address, from, to uint64) ([]*ChainHead, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	key := chainHeadKey(addr, to)
+	prefixLen := len(chainHeadPrefix) + common.AddressLength
+
+	it := s.db.NewReverseIterator(key[:prefixLen], key[prefixLen:])
+	defer it.Release()
+
+	var heads []*ChainHead
+	// Most recent heads come first
+	for it.Next() {
+		var head ChainHead
+		if err := rlp.DecodeBytes(it.Value(), &head); err != nil {
+			return nil, fmt.Errorf("failed to decode chain head: %w", err)
+		}
+		if head.Number < from {
+			break
+		}
+		heads = append(heads, &head)
+	}
+	if err := it.Error(); err != nil {
+		return nil, fmt.Errorf("failed to iterate chain heads: %w", err)
+	}
+
+	slices.Reverse(heads)
+	return heads, nil
+}
+
+// Put stores the specified hash chain head of the
+// specified account. A head previously stored at
+// the same block number, e.g., of a reorged block,
+// is overwritten.
+func (s *ChainHeadStore) Put(addr common.Address, head *ChainHead) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	encoded, err := rlp.EncodeToBytes(head)
+	if err != nil {
+		return fmt.Errorf("failed to encode chain head: %w", err)
+	}
+
+	return s.db.Put(chainHeadKey(addr, head.Number), encoded)
+}
+
+// Truncate removes all hash chain heads of the
+// specified account stored at or after the
+// specified block number, e.g., of reorged
+// blocks, and returns them in ascending order.
+func (s *ChainHeadStore) Truncate(addr common.Address, num uint64) ([]*ChainHead, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	prefixLen := len(chainHeadPrefix) + common.AddressLength
+	it := s.db.NewReverseIterator(chainHeadKey(addr, 0)[:prefixLen], nil)
+
+	var (
+		keys    [][]byte
+		removed []*ChainHead
+	)
+	// Most recent heads come first
+	for it.Next() {
+		var head ChainHead
+		if err := rlp.DecodeBytes(it.Value(), &head); err != nil {
+			it.Release()
+			return nil, fmt.Errorf("failed to decode chain head: %w", err)
+		}
+		if head.Number < num {
+			break
+		}
+		keys = append(keys, storage.CopyBytes(it.Key()))
+		removed = append(removed, &head)
+	}
+	err := it.Error()
+	it.Release()
+	if err != nil {
+		return nil, fmt.Errorf("failed to iterate chain heads: %w", err)
+	}
+
+	batch := s.db.NewBatch()
+	for _, key := range keys {
+		if err = batch.Delete(key); err != nil {
+			return nil, fmt.Errorf("failed to delete chain head: %w", err)
+		}
+	}
+	if err = batch.Write(); err != nil {
+		return nil, fmt.Errorf("failed to delete chain heads: %w", err)
+	}
+
+	slices.Reverse(removed)
+	return removed, nil
+}
@@ -0,0 +1,130 @@
+package ethstore
+
+import (
+	"errors"
+	"github.com/ethereum/go-ethereum/common"
+	"sparseth/storage/mem"
+	"testing"
+)
+
+func TestChainHeadStore_At(t *testing.T) {
+	addr := common.HexToAddress("0xaa")
+
+	t.Run("should return error when no head stored before block", func(t *testing.T) {
+		db := mem.New()
+		defer db.Close()
+
+		store := NewChainHeadStore(db)
+		if err := store.Put(addr, &ChainHead{Number: 10, Head: common.HexToHash("0x01")}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if _, err := store.At(addr, 9); !errors.Is(err, ErrChainHeadNotFound) {
+			t.Errorf("expected %v, got %v", ErrChainHeadNotFound, err)
+		}
+	})
+
+	t.Run("should return most recent head at or before block", func(t *testing.T) {
+		db := mem.New()
+		defer db.Close()
+
+		store := NewChainHeadStore(db)
+		for num, head := range map[uint64]string{10: "0x01", 20: "0x02", 30: "0x03"} {
+			if err := store.Put(addr, &ChainHead{Number: num, Head: common.HexToHash(head)}); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+		}
+		// Heads of other accounts are ignored
+		if err := store.Put(common.HexToAddress("0xbb"), &ChainHead{Number: 25, Head: common.HexToHash("0xff")}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		for num, expected := range map[uint64]string{10: "0x01", 25: "0x02", 30: "0x03", 100: "0x03"} {
+			head, err := store.At(addr, num)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if head.Head != common.HexToHash(expected) {
+				t.Errorf("expected head %s at block %d, got %s", expected, num, head.Head.Hex())
+			}
+		}
+	})
+}
+
+func TestChainHeadStore_Range(t *testing.T) {
+	addr := common.HexToAddress("0xaa")
+
+	t.Run("should return heads within range in order", func(t *testing.T) {
+		db := mem.New()
+		defer db.Close()
+
+		store := NewChainHeadStore(db)
+		for _, num := range []uint64{10, 20, 30, 40} {
+			if err := store.Put(addr, &ChainHead{Number: num}); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+		}
+		// Heads of other accounts are ignored
+		if err := store.Put(common.HexToAddress("0xbb"), &ChainHead{Number: 25}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		heads, err := store.Range(addr, 15, 30)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(heads) != 2 || heads[0].Number != 20 || heads[1].Number != 30 {
+			t.Errorf("expected heads 20 and 30 in order, got %d heads", len(heads))
+		}
+	})
+
+	t.Run("should return nothing if no head within range", func(t *testing.T) {
+		db := mem.New()
+		defer db.Close()
+
+		store := NewChainHeadStore(db)
+		if err := store.Put(addr, &ChainHead{Number: 10}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		heads, err := store.Range(addr, 11, 20)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(heads) != 0 {
+			t.Errorf("expected no heads, got %d", len(heads))
+		}
+	})
+}
+
+func TestChainHeadStore_Truncate(t *testing.T) {
+	addr := common.HexToAddress("0xaa")
+
+	t.Run("should remove heads at or after block", func(t *testing.T) {
+		db := mem.New()
+		defer db.Close()
+
+		store := NewChainHeadStore(db)
+		for _, num := range []uint64{10, 20, 30} {
+			if err := store.Put(addr, &ChainHead{Number: num}); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+		}
+
+		removed, err := store.Truncate(addr, 20)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(removed) != 2 || removed[0].Number != 20 || removed[1].Number != 30 {
+			t.Errorf("expected heads 20 and 30 to be removed in order, got %d heads", len(removed))
+		}
+
+		head, err := store.At(addr, 100)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if head.Number != 10 {
+			t.Errorf("expected head 10, got %d", head.Number)
+		}
+	})
+}
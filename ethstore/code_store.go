@@ -0,0 +1,56 @@
+package ethstore
+
+import (
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"sparseth/storage"
+)
+
+var (
+	// ErrCodeNotFound is returned when a
+	// requested code is not found in the
+	// store.
+	ErrCodeNotFound = errors.New("code not found")
+)
+
+// CodeStore provides thread-safe storage of
+// contract code keyed by code hash.
+//
+// Code is immutable for a given hash, so stored
+// code never needs to be invalidated and may be
+// shared by any number of accounts.
+type CodeStore struct {
+	db storage.KeyValStore
+}
+
+// NewCodeStore creates a new CodeStore
+// using the specified key-val store.
+func NewCodeStore(db storage.KeyValStore) *CodeStore {
+	return &CodeStore{
+		db: db,
+	}
+}
+
+// Get retrieves the code with the
+// specified code hash.
+func (s *CodeStore) Get(hash common.Hash) ([]byte, error) {
+	code, err := s.db.Get(codeKey(hash))
+	if err != nil {
+		if errors.Is(err, storage.ErrKeyNotFound) {
+			return nil, ErrCodeNotFound
+		}
+		return nil, fmt.Errorf("failed to get code: %w", err)
+	}
+	return code, nil
+}
+
+// Put stores the specified code, keyed
+// by its code hash.
+func (s *CodeStore) Put(code []byte) error {
+	if err := s.db.Put(codeKey(crypto.Keccak256Hash(code)), code); err != nil {
+		return fmt.Errorf("failed to put code: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package ethstore
+
+import (
+	"bytes"
+	"errors"
+	"github.com/ethereum/go-ethereum/crypto"
+	"sparseth/storage/mem"
+	"testing"
+)
+
+func TestCodeStore_Get(t *testing.T) {
+	code := []byte{0x60, 0x00, 0x60, 0x00, 0xf3}
+
+	t.Run("should return error when code not found", func(t *testing.T) {
+		db := mem.New()
+		defer db.Close()
+
+		store := NewCodeStore(db)
+		if _, err := store.Get(crypto.Keccak256Hash(code)); !errors.Is(err, ErrCodeNotFound) {
+			t.Errorf("expected %v, got %v", ErrCodeNotFound, err)
+		}
+	})
+
+	t.Run("should return previously stored code by hash", func(t *testing.T) {
+		db := mem.New()
+		defer db.Close()
+
+		store := NewCodeStore(db)
+		if err := store.Put(code); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		got, err := store.Get(crypto.Keccak256Hash(code))
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if !bytes.Equal(got, code) {
+			t.Errorf("expected code %x, got %x", code, got)
+		}
+	})
+}
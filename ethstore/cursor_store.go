@@ -0,0 +1,70 @@
+package ethstore
+
+import (
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+	"sparseth/storage"
+)
+
+var (
+	// ErrCursorNotFound is returned when no
+	// block is acknowledged by a monitor.
+	ErrCursorNotFound = errors.New("cursor not found")
+)
+
+// Cursor is the last block
+// acknowledged by a monitor.
+type Cursor struct {
+	Number uint64
+	Hash   common.Hash
+}
+
+// CursorStore provides thread-safe storage
+// of the last block acknowledged by each
+// monitor, by monitor name.
+type CursorStore struct {
+	db storage.KeyValStore
+}
+
+// NewCursorStore creates a new CursorStore
+// using the specified key-val store.
+func NewCursorStore(db storage.KeyValStore) *CursorStore {
+	return &CursorStore{
+		db: db,
+	}
+}
+
+// Get retrieves the last block acknowledged
+// by the monitor with the specified name.
+func (s *CursorStore) Get(monitor string) (*Cursor, error) {
+	encoded, err := s.db.Get(cursorKey(monitor))
+	if err != nil {
+		if errors.Is(err, storage.ErrKeyNotFound) {
+			return nil, ErrCursorNotFound
+		}
+		return nil, fmt.Errorf("failed to get cursor: %w", err)
+	}
+
+	var cursor Cursor
+	if err = rlp.DecodeBytes(encoded, &cursor); err != nil {
+		return nil, fmt.Errorf("failed to decode cursor: %w", err)
+	}
+	return &cursor, nil
+}
+
+// Put stores the specified block as the last
+// block acknowledged by the monitor with the
+// specified name.
+func (s *CursorStore) Put(monitor string, cursor *Cursor) error {
+	encoded, err := rlp.EncodeToBytes(cursor)
+	if err != nil {
+		return fmt.Errorf("failed to encode cursor: %w", err)
+	}
+
+	if err = s.db.Put(cursorKey(monitor), encoded); err != nil {
+		return fmt.Errorf("failed to put cursor: %w", err)
+	}
+	return nil
+}
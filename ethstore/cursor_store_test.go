@@ -0,0 +1,44 @@
+package ethstore
+
+import (
+	"errors"
+	"sparseth/storage/mem"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestCursorStore_Get(t *testing.T) {
+	t.Run("should return error when cursor not found", func(t *testing.T) {
+		db := mem.New()
+		defer db.Close()
+
+		store := NewCursorStore(db)
+		if _, err := store.Get("monitor"); !errors.Is(err, ErrCursorNotFound) {
+			t.Errorf("expected %v, got %v", ErrCursorNotFound, err)
+		}
+	})
+
+	t.Run("should return last stored cursor", func(t *testing.T) {
+		db := mem.New()
+		defer db.Close()
+
+		store := NewCursorStore(db)
+		for num := uint64(1); num <= 2; num++ {
+			if err := store.Put("monitor", &Cursor{Number: num, Hash: common.BigToHash(common.Big1)}); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+		}
+
+		got, err := store.Get("monitor")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if got.Number != 2 || got.Hash != common.BigToHash(common.Big1) {
+			t.Errorf("expected cursor at block 2, got %+v", got)
+		}
+		if _, err = store.Get("other"); !errors.Is(err, ErrCursorNotFound) {
+			t.Errorf("expected %v, got %v", ErrCursorNotFound, err)
+		}
+	})
+}
@@ -0,0 +1,72 @@
+package ethstore
+
+import (
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+	"sparseth/storage"
+)
+
+// DeadLetter is a block that a monitor
+// failed to process, even after retries.
+type DeadLetter struct {
+	Monitor string
+	Number  uint64
+	Hash    common.Hash
+	// Err is the error of the last attempt.
+	Err      string
+	Attempts uint64
+	// Time is the time of the last attempt,
+	// in seconds since the Unix epoch.
+	Time uint64
+}
+
+// DeadLetterStore provides thread-safe storage
+// of dead letters by block number.
+type DeadLetterStore struct {
+	db storage.KeyValStore
+}
+
+// NewDeadLetterStore creates a new DeadLetterStore
+// using the specified key-val store.
+func NewDeadLetterStore(db storage.KeyValStore) *DeadLetterStore {
+	return &DeadLetterStore{
+		db: db,
+	}
+}
+
+// Put stores the specified dead letter. A dead
+// letter previously stored for the same block
+// and monitor is overwritten.
+func (s *DeadLetterStore) Put(dl *DeadLetter) error {
+	encoded, err := rlp.EncodeToBytes(dl)
+	if err != nil {
+		return fmt.Errorf("failed to encode dead letter: %w", err)
+	}
+
+	if err = s.db.Put(deadLetterKey(dl.Number, dl.Hash, dl.Monitor), encoded); err != nil {
+		return fmt.Errorf("failed to put dead letter: %w", err)
+	}
+	return nil
+}
+
+// Since returns all stored dead letters of blocks
+// from the specified number on, by block number.
+func (s *DeadLetterStore) Since(num uint64) ([]*DeadLetter, error) {
+	it := s.db.NewIterator(deadLetterPrefix, encodeNumber(num))
+	defer it.Release()
+
+	var letters []*DeadLetter
+	for it.Next() {
+		var dl DeadLetter
+		if err := rlp.DecodeBytes(it.Value(), &dl); err != nil {
+			return nil, fmt.Errorf("failed to decode dead letter: %w", err)
+		}
+		letters = append(letters, &dl)
+	}
+	if err := it.Error(); err != nil {
+		return nil, fmt.Errorf("failed to iterate dead letters: %w", err)
+	}
+
+	return letters, nil
+}
@@ -0,0 +1,47 @@
+package ethstore
+
+import (
+	"sparseth/storage/mem"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestDeadLetterStore_Since(t *testing.T) {
+	t.Run("should return dead letters by block number", func(t *testing.T) {
+		db := mem.New()
+		defer db.Close()
+
+		store := NewDeadLetterStore(db)
+		for _, num := range []uint64{3, 1, 2} {
+			dl := &DeadLetter{Monitor: "monitor", Number: num, Hash: common.HexToHash("0x01"), Err: "failed", Attempts: 3}
+			if err := store.Put(dl); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+		}
+
+		letters, err := store.Since(2)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(letters) != 2 || letters[0].Number != 2 || letters[1].Number != 3 {
+			t.Fatalf("expected dead letters of blocks 2 and 3, got %+v", letters)
+		}
+		if letters[0].Monitor != "monitor" || letters[0].Err != "failed" || letters[0].Attempts != 3 {
+			t.Errorf("expected stored dead letter, got %+v", letters[0])
+		}
+	})
+
+	t.Run("should return no dead letters of empty store", func(t *testing.T) {
+		db := mem.New()
+		defer db.Close()
+
+		letters, err := NewDeadLetterStore(db).Since(0)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(letters) != 0 {
+			t.Errorf("expected no dead letters, got %d", len(letters))
+		}
+	})
+}
@@ -0,0 +1,181 @@
+package ethstore
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+	"sparseth/storage"
+	"sync"
+)
+
+// Delivery is a committed block recorded
+// in the delivery log for export to sinks.
+type Delivery struct {
+	// Seq is the sequence number of the delivery,
+	// which is strictly increasing without gaps.
+	Seq uint64
+	// Number is the block number.
+	Number uint64
+	// Hash is the block hash.
+	Hash common.Hash
+	// Failed holds the names of all monitors
+	// that failed to verify the block.
+	Failed []string
+}
+
+// Verified checks whether the block has
+// been verified by all monitors.
+func (d *Delivery) Verified() bool {
+	return len(d.Failed) == 0
+}
+
+// DeliveryLog is a thread-safe write-ahead log of
+// blocks to be delivered to sinks. It records the
+// latest sequence number acknowledged by each sink,
+// so that deliveries are resumed after a restart
+// without gaps.
+//
+// Each block is recorded at most once, so that
+// blocks committed again after a restart are not
+// delivered twice. Blocks replaced by a reorg are
+// recorded again.
+//
+// Entries acknowledged by all sinks are removed
+// via Prune.
+type DeliveryLog struct {
+	db storage.KeyValStore
+	mu sync.Mutex
+}
+
+// NewDeliveryLog creates a new DeliveryLog
+// using the specified key-val store.
+func NewDeliveryLog(db storage.KeyValStore) *DeliveryLog {
+	return &DeliveryLog{
+		db: db,
+	}
+}
+
+// Append assigns the next sequence number to
+// the specified delivery, and stores it. If the
+// block has been recorded already, nothing is
+// stored, and false is returned.
+func (l *DeliveryLog) Append(d *Delivery) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	recorded, err := l.db.Get(deliveredKey(d.Number))
+	if err != nil && !errors.Is(err, storage.ErrKeyNotFound) {
+		return false, fmt.Errorf("failed to get recorded block %d: %w", d.Number, err)
+	}
+	if err == nil && common.BytesToHash(recorded) == d.Hash {
+		return false, nil
+	}
+
+	head, err := l.readSeq(deliveryHeadKey)
+	if err != nil {
+		return false, fmt.Errorf("failed to get delivery head: %w", err)
+	}
+	d.Seq = head + 1
+
+	encoded, err := rlp.EncodeToBytes(d)
+	if err != nil {
+		return false, fmt.Errorf("failed to encode delivery: %w", err)
+	}
+
+	batch := l.db.NewBatch()
+	if err = batch.Put(deliveryKey(d.Seq), encoded); err != nil {
+		return false, fmt.Errorf("failed to put delivery: %w", err)
+	}
+	if err = batch.Put(deliveryHeadKey, encodeNumber(d.Seq)); err != nil {
+		return false, fmt.Errorf("failed to put delivery head: %w", err)
+	}
+	if err = batch.Put(deliveredKey(d.Number), d.Hash.Bytes()); err != nil {
+		return false, fmt.Errorf("failed to put recorded block %d: %w", d.Number, err)
+	}
+	if err = batch.Write(); err != nil {
+		return false, fmt.Errorf("failed to write delivery: %w", err)
+	}
+
+	// The log must survive a crash before
+	// the block is delivered to any sink
+	if err = l.db.SyncKeyValue(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Since returns all stored deliveries with a sequence
+// number greater than the specified one, in order.
+func (l *DeliveryLog) Since(seq uint64) ([]*Delivery, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	it := l.db.NewIterator(deliveryPrefix, encodeNumber(seq+1))
+	defer it.Release()
+
+	var deliveries []*Delivery
+	for it.Next() {
+		var d Delivery
+		if err := rlp.DecodeBytes(it.Value(), &d); err != nil {
+			return nil, fmt.Errorf("failed to decode delivery: %w", err)
+		}
+		deliveries = append(deliveries, &d)
+	}
+	if err := it.Error(); err != nil {
+		return nil, fmt.Errorf("failed to iterate deliveries: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+// Acked returns the latest sequence number
+// acknowledged by the specified sink, or zero
+// if the sink acknowledged no delivery yet.
+func (l *DeliveryLog) Acked(sink string) (uint64, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.readSeq(ackKey(sink))
+}
+
+// Ack records that the specified sink has
+// received all deliveries up to and including
+// the specified sequence number.
+func (l *DeliveryLog) Ack(sink string, seq uint64) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := l.db.Put(ackKey(sink), encodeNumber(seq)); err != nil {
+		return fmt.Errorf("failed to put ack: %w", err)
+	}
+	return nil
+}
+
+// Prune removes all deliveries up to and
+// including the specified sequence number.
+func (l *DeliveryLog) Prune(seq uint64) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := l.db.DeleteRange(deliveryKey(0), deliveryKey(seq+1)); err != nil {
+		return fmt.Errorf("failed to prune deliveries: %w", err)
+	}
+	return nil
+}
+
+// readSeq reads the sequence number stored
+// at the specified key, or zero if none.
+//
+// Note that the caller must hold the lock.
+func (l *DeliveryLog) readSeq(key []byte) (uint64, error) {
+	encoded, err := l.db.Get(key)
+	if err != nil {
+		if errors.Is(err, storage.ErrKeyNotFound) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(encoded), nil
+}
@@ -0,0 +1,165 @@
+package ethstore
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"sparseth/storage/mem"
+	"testing"
+)
+
+func TestDeliveryLog_Append(t *testing.T) {
+	t.Run("should assign increasing sequence numbers", func(t *testing.T) {
+		db := mem.New()
+		defer db.Close()
+
+		wal := NewDeliveryLog(db)
+		for i := range 3 {
+			d := &Delivery{Number: uint64(10 + i), Hash: common.BytesToHash([]byte{byte(i)})}
+			if _, err := wal.Append(d); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if d.Seq != uint64(i+1) {
+				t.Errorf("expected sequence number %d, got %d", i+1, d.Seq)
+			}
+		}
+	})
+
+	t.Run("should continue sequence after reopen", func(t *testing.T) {
+		db := mem.New()
+		defer db.Close()
+
+		if _, err := NewDeliveryLog(db).Append(&Delivery{Number: 1}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		d := &Delivery{Number: 2}
+		if _, err := NewDeliveryLog(db).Append(d); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if d.Seq != 2 {
+			t.Errorf("expected sequence number 2, got %d", d.Seq)
+		}
+	})
+	t.Run("should skip recorded block", func(t *testing.T) {
+		db := mem.New()
+		defer db.Close()
+
+		wal := NewDeliveryLog(db)
+		hash := common.HexToHash("0x01")
+		if _, err := wal.Append(&Delivery{Number: 1, Hash: hash}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		recorded, err := NewDeliveryLog(db).Append(&Delivery{Number: 1, Hash: hash})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if recorded {
+			t.Errorf("expected block to be skipped, got recorded")
+		}
+		if res, _ := wal.Since(0); len(res) != 1 {
+			t.Errorf("expected 1 delivery, got %d", len(res))
+		}
+	})
+
+	t.Run("should record block replaced by reorg", func(t *testing.T) {
+		db := mem.New()
+		defer db.Close()
+
+		wal := NewDeliveryLog(db)
+		if _, err := wal.Append(&Delivery{Number: 1, Hash: common.HexToHash("0x01")}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		d := &Delivery{Number: 1, Hash: common.HexToHash("0x02")}
+		recorded, err := wal.Append(d)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if !recorded || d.Seq != 2 {
+			t.Errorf("expected block recorded with sequence number 2, got %v and %d", recorded, d.Seq)
+		}
+	})
+}
+
+func TestDeliveryLog_Since(t *testing.T) {
+	t.Run("should return deliveries after sequence number in order", func(t *testing.T) {
+		db := mem.New()
+		defer db.Close()
+
+		wal := NewDeliveryLog(db)
+		for i := range 3 {
+			if _, err := wal.Append(&Delivery{Number: uint64(i), Failed: []string{"transaction"}}); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+		}
+
+		res, err := wal.Since(1)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(res) != 2 || res[0].Seq != 2 || res[1].Seq != 3 {
+			t.Fatalf("expected deliveries 2 and 3, got %d deliveries", len(res))
+		}
+		if res[0].Verified() {
+			t.Errorf("expected delivery not to be verified")
+		}
+	})
+
+	t.Run("should not return pruned deliveries", func(t *testing.T) {
+		db := mem.New()
+		defer db.Close()
+
+		wal := NewDeliveryLog(db)
+		for i := range 3 {
+			if _, err := wal.Append(&Delivery{Number: uint64(i)}); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+		}
+		if err := wal.Prune(2); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		res, err := wal.Since(0)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(res) != 1 || res[0].Seq != 3 {
+			t.Errorf("expected delivery 3 only, got %d deliveries", len(res))
+		}
+	})
+}
+
+func TestDeliveryLog_Ack(t *testing.T) {
+	t.Run("should return zero if not acknowledged", func(t *testing.T) {
+		db := mem.New()
+		defer db.Close()
+
+		seq, err := NewDeliveryLog(db).Acked("kafka")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if seq != 0 {
+			t.Errorf("expected 0, got %d", seq)
+		}
+	})
+
+	t.Run("should track acknowledgements per sink", func(t *testing.T) {
+		db := mem.New()
+		defer db.Close()
+
+		wal := NewDeliveryLog(db)
+		if err := wal.Ack("kafka", 5); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if err := wal.Ack("postgres", 3); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if seq, _ := wal.Acked("kafka"); seq != 5 {
+			t.Errorf("expected 5, got %d", seq)
+		}
+		if seq, _ := wal.Acked("postgres"); seq != 3 {
+			t.Errorf("expected 3, got %d", seq)
+		}
+	})
+}
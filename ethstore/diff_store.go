@@ -0,0 +1,102 @@
+package ethstore
+
+import (
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+	"math/big"
+	"sparseth/storage"
+	"sync"
+)
+
+var (
+	// ErrDiffNotFound is returned when a
+	// requested state diff is not found
+	// in the store.
+	ErrDiffNotFound = errors.New("diff not found")
+)
+
+// StateDiff holds the verified changes to
+// all monitored accounts within a block.
+type StateDiff struct {
+	Number    uint64
+	BlockHash common.Hash
+	Accounts  []*AccountDiff
+}
+
+// AccountDiff is the verified change
+// of a single account within a block.
+// Unchanged fields hold equal values.
+type AccountDiff struct {
+	Address        common.Address
+	NonceBefore    uint64
+	NonceAfter     uint64
+	BalanceBefore  *big.Int
+	BalanceAfter   *big.Int
+	CodeHashBefore common.Hash
+	CodeHashAfter  common.Hash
+	// Slots holds all changed storage
+	// slots, in order of first change.
+	Slots []*SlotDiff
+}
+
+// SlotDiff is the verified change
+// of a single storage slot.
+type SlotDiff struct {
+	Slot   common.Hash
+	Before common.Hash
+	After  common.Hash
+}
+
+// DiffStore provides thread-safe storage
+// of state diffs by block number.
+type DiffStore struct {
+	db storage.KeyValStore
+	mu sync.RWMutex
+}
+
+// NewDiffStore creates a new DiffStore
+// using the specified key-val store.
+func NewDiffStore(db storage.KeyValStore) *DiffStore {
+	return &DiffStore{
+		db: db,
+	}
+}
+
+// Get retrieves the state diff of the
+// block with the specified number.
+func (s *DiffStore) Get(num uint64) (*StateDiff, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	encoded, err := s.db.Get(diffKey(num))
+	if err != nil {
+		if errors.Is(err, storage.ErrKeyNotFound) {
+			return nil, ErrDiffNotFound
+		}
+		return nil, fmt.Errorf("failed to get diff: %w", err)
+	}
+
+	var diff StateDiff
+	if err = rlp.DecodeBytes(encoded, &diff); err != nil {
+		return nil, fmt.Errorf("failed to decode diff: %w", err)
+	}
+
+	return &diff, nil
+}
+
+// Put stores the specified state diff. A diff
+// previously stored at the same block number,
+// e.g., of a reorged block, is overwritten.
+func (s *DiffStore) Put(diff *StateDiff) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	encoded, err := rlp.EncodeToBytes(diff)
+	if err != nil {
+		return fmt.Errorf("failed to encode diff: %w", err)
+	}
+
+	return s.db.Put(diffKey(diff.Number), encoded)
+}
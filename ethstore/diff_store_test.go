@@ -0,0 +1,55 @@
+package ethstore
+
+import (
+	"errors"
+	"github.com/ethereum/go-ethereum/common"
+	"math/big"
+	"sparseth/storage/mem"
+	"testing"
+)
+
+func TestDiffStore_Get(t *testing.T) {
+	t.Run("should return error when diff not found", func(t *testing.T) {
+		db := mem.New()
+		defer db.Close()
+
+		store := NewDiffStore(db)
+		if _, err := store.Get(1); !errors.Is(err, ErrDiffNotFound) {
+			t.Errorf("expected %v, got %v", ErrDiffNotFound, err)
+		}
+	})
+
+	t.Run("should return stored diff", func(t *testing.T) {
+		db := mem.New()
+		defer db.Close()
+
+		store := NewDiffStore(db)
+		diff := &StateDiff{
+			Number:    1,
+			BlockHash: common.HexToHash("0x01"),
+			Accounts: []*AccountDiff{{
+				Address:       common.HexToAddress("0xaa"),
+				NonceBefore:   1,
+				NonceAfter:    2,
+				BalanceBefore: big.NewInt(10),
+				BalanceAfter:  big.NewInt(5),
+				Slots:         []*SlotDiff{{Slot: common.HexToHash("0x01"), Before: common.Hash{}, After: common.HexToHash("0x02")}},
+			}},
+		}
+		if err := store.Put(diff); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		res, err := store.Get(1)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(res.Accounts) != 1 || len(res.Accounts[0].Slots) != 1 {
+			t.Fatalf("expected 1 account with 1 slot, got %+v", res.Accounts)
+		}
+		acc := res.Accounts[0]
+		if acc.NonceAfter != 2 || acc.BalanceAfter.Cmp(big.NewInt(5)) != 0 || acc.Slots[0].After != common.HexToHash("0x02") {
+			t.Errorf("expected nonce 2, balance 5, and slot 0x02, got %d, %s, and %s", acc.NonceAfter, acc.BalanceAfter, acc.Slots[0].After.Hex())
+		}
+	})
+}
@@ -0,0 +1,83 @@
+package ethstore
+
+import (
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"sparseth/storage"
+	"sync"
+)
+
+var (
+	// ErrDigestNotFound is returned when a
+	// requested block digest is not found
+	// in the store.
+	ErrDigestNotFound = errors.New("digest not found")
+)
+
+// BlockDigest is the digest of the verified
+// outputs of a committed block.
+type BlockDigest struct {
+	Number    uint64
+	BlockHash common.Hash
+	Digest    common.Hash
+	Verified  bool
+}
+
+// DigestStore provides thread-safe storage
+// of block digests by block number.
+type DigestStore struct {
+	db  storage.KeyValStore
+	enc Encoding
+	mu  sync.RWMutex
+}
+
+// NewDigestStore creates a new DigestStore
+// using the specified key-val store. New
+// digests are stored with the specified
+// encoding, while digests of any encoding
+// are read.
+func NewDigestStore(db storage.KeyValStore, enc Encoding) *DigestStore {
+	return &DigestStore{
+		db:  db,
+		enc: enc,
+	}
+}
+
+// Get retrieves the block digest at
+// the specified block number.
+func (s *DigestStore) Get(num uint64) (*BlockDigest, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	encoded, err := s.db.Get(digestKey(num))
+	if err != nil {
+		if errors.Is(err, storage.ErrKeyNotFound) {
+			return nil, ErrDigestNotFound
+		}
+		return nil, fmt.Errorf("failed to get digest: %w", err)
+	}
+
+	digest, err := decodeDigest(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode digest: %w", err)
+	}
+
+	return digest, nil
+}
+
+// Put stores the specified block digest. A
+// digest previously stored at the same block
+// number, e.g., of a reorged block, is
+// overwritten.
+func (s *DigestStore) Put(digest *BlockDigest) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	encoded, err := encodeDigest(digest, s.enc)
+	if err != nil {
+		return fmt.Errorf("failed to encode digest: %w", err)
+	}
+
+	return s.db.Put(digestKey(digest.Number), encoded)
+}
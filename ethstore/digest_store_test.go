@@ -0,0 +1,42 @@
+package ethstore
+
+import (
+	"errors"
+	"github.com/ethereum/go-ethereum/common"
+	"sparseth/storage/mem"
+	"testing"
+)
+
+func TestDigestStore_Get(t *testing.T) {
+	t.Run("should return error when digest not found", func(t *testing.T) {
+		db := mem.New()
+		defer db.Close()
+
+		store := NewDigestStore(db, EncodingRLP)
+		if _, err := store.Get(1); !errors.Is(err, ErrDigestNotFound) {
+			t.Errorf("expected %v, got %v", ErrDigestNotFound, err)
+		}
+	})
+
+	t.Run("should return latest digest stored at block", func(t *testing.T) {
+		db := mem.New()
+		defer db.Close()
+
+		store := NewDigestStore(db, EncodingRLP)
+		reorged := &BlockDigest{Number: 1, BlockHash: common.HexToHash("0x01"), Digest: common.HexToHash("0xaa")}
+		canonical := &BlockDigest{Number: 1, BlockHash: common.HexToHash("0x02"), Digest: common.HexToHash("0xbb")}
+		for _, digest := range []*BlockDigest{reorged, canonical} {
+			if err := store.Put(digest); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+		}
+
+		res, err := store.Get(1)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if res.BlockHash != canonical.BlockHash || res.Digest != canonical.Digest {
+			t.Errorf("expected digest %s at block %s, got %s at %s", canonical.Digest.Hex(), canonical.BlockHash.Hex(), res.Digest.Hex(), res.BlockHash.Hex())
+		}
+	})
+}
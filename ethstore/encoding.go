@@ -0,0 +1,220 @@
+package ethstore
+
+import (
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+var (
+	// ErrUnknownEncoding is returned when an
+	// unsupported record encoding is requested.
+	ErrUnknownEncoding = errors.New("unknown encoding")
+)
+
+// Encoding is the on-disk encoding
+// of newly stored records.
+type Encoding string
+
+const (
+	// EncodingRLP encodes records with RLP,
+	// which is the default.
+	EncodingRLP Encoding = "rlp"
+	// EncodingProtobuf encodes records with
+	// the versioned protobuf schemas defined
+	// in schema/, so that they can be read
+	// by non-Go tooling.
+	EncodingProtobuf Encoding = "protobuf"
+)
+
+// protobufV1 is the version byte prefixed
+// to records encoded with the v1 protobuf
+// schema. RLP encoded records never start
+// with it, as they are lists.
+const protobufV1 byte = 0x01
+
+// ParseEncoding parses the specified
+// record encoding.
+func ParseEncoding(s string) (Encoding, error) {
+	switch enc := Encoding(s); enc {
+	case EncodingRLP, EncodingProtobuf:
+		return enc, nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnknownEncoding, s)
+	}
+}
+
+// isProtobuf checks whether the specified
+// stored record is protobuf encoded.
+func isProtobuf(encoded []byte) bool {
+	return len(encoded) > 0 && encoded[0] == protobufV1
+}
+
+// encodeLog encodes the specified log
+// with the specified encoding.
+func encodeLog(log *types.Log, enc Encoding) ([]byte, error) {
+	if enc != EncodingProtobuf {
+		return rlp.EncodeToBytes(log)
+	}
+
+	b := []byte{protobufV1}
+	b = appendBytes(b, 1, log.Address.Bytes())
+	for _, topic := range log.Topics {
+		b = protowire.AppendTag(b, 2, protowire.BytesType)
+		b = protowire.AppendBytes(b, topic.Bytes())
+	}
+	b = appendBytes(b, 3, log.Data)
+	b = appendVarint(b, 4, log.BlockNumber)
+	b = appendBytes(b, 5, log.TxHash.Bytes())
+	b = appendVarint(b, 6, uint64(log.TxIndex))
+	b = appendBytes(b, 7, log.BlockHash.Bytes())
+	b = appendVarint(b, 8, uint64(log.Index))
+	b = appendVarint(b, 9, protowire.EncodeBool(log.Removed))
+	return b, nil
+}
+
+// decodeLog decodes the specified stored log,
+// regardless of the encoding it was stored with.
+func decodeLog(encoded []byte) (*types.Log, error) {
+	var log types.Log
+	if !isProtobuf(encoded) {
+		if err := rlp.DecodeBytes(encoded, &log); err != nil {
+			return nil, err
+		}
+		return &log, nil
+	}
+
+	err := consumeFields(encoded[1:], func(num protowire.Number, val []byte, n uint64) {
+		switch num {
+		case 1:
+			log.Address = common.BytesToAddress(val)
+		case 2:
+			log.Topics = append(log.Topics, common.BytesToHash(val))
+		case 3:
+			log.Data = common.CopyBytes(val)
+		case 4:
+			log.BlockNumber = n
+		case 5:
+			log.TxHash = common.BytesToHash(val)
+		case 6:
+			log.TxIndex = uint(n)
+		case 7:
+			log.BlockHash = common.BytesToHash(val)
+		case 8:
+			log.Index = uint(n)
+		case 9:
+			log.Removed = protowire.DecodeBool(n)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &log, nil
+}
+
+// encodeDigest encodes the specified block
+// digest with the specified encoding.
+func encodeDigest(digest *BlockDigest, enc Encoding) ([]byte, error) {
+	if enc != EncodingProtobuf {
+		return rlp.EncodeToBytes(digest)
+	}
+
+	b := []byte{protobufV1}
+	b = appendVarint(b, 1, digest.Number)
+	b = appendBytes(b, 2, digest.BlockHash.Bytes())
+	b = appendBytes(b, 3, digest.Digest.Bytes())
+	b = appendVarint(b, 4, protowire.EncodeBool(digest.Verified))
+	return b, nil
+}
+
+// decodeDigest decodes the specified stored
+// block digest, regardless of the encoding
+// it was stored with.
+func decodeDigest(encoded []byte) (*BlockDigest, error) {
+	var digest BlockDigest
+	if !isProtobuf(encoded) {
+		if err := rlp.DecodeBytes(encoded, &digest); err != nil {
+			return nil, err
+		}
+		return &digest, nil
+	}
+
+	err := consumeFields(encoded[1:], func(num protowire.Number, val []byte, n uint64) {
+		switch num {
+		case 1:
+			digest.Number = n
+		case 2:
+			digest.BlockHash = common.BytesToHash(val)
+		case 3:
+			digest.Digest = common.BytesToHash(val)
+		case 4:
+			digest.Verified = protowire.DecodeBool(n)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &digest, nil
+}
+
+// appendBytes appends the specified
+// length-delimited field, unless empty.
+func appendBytes(b []byte, num protowire.Number, val []byte) []byte {
+	if len(val) == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, val)
+}
+
+// appendVarint appends the specified
+// varint field, unless zero.
+func appendVarint(b []byte, num protowire.Number, val uint64) []byte {
+	if val == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, val)
+}
+
+// consumeFields calls the specified function for
+// each length-delimited or varint field of the
+// specified message. Fields of other types are
+// skipped, so that newer schema versions remain
+// readable.
+func consumeFields(b []byte, field func(num protowire.Number, val []byte, n uint64)) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return fmt.Errorf("invalid tag: %w", protowire.ParseError(n))
+		}
+		b = b[n:]
+
+		switch typ {
+		case protowire.BytesType:
+			val, n := protowire.ConsumeBytes(b)
+			if n < 0 {
+				return fmt.Errorf("invalid field %d: %w", num, protowire.ParseError(n))
+			}
+			field(num, val, 0)
+			b = b[n:]
+		case protowire.VarintType:
+			val, n := protowire.ConsumeVarint(b)
+			if n < 0 {
+				return fmt.Errorf("invalid field %d: %w", num, protowire.ParseError(n))
+			}
+			field(num, nil, val)
+			b = b[n:]
+		default:
+			n := protowire.ConsumeFieldValue(num, typ, b)
+			if n < 0 {
+				return fmt.Errorf("invalid field %d: %w", num, protowire.ParseError(n))
+			}
+			b = b[n:]
+		}
+	}
+	return nil
+}
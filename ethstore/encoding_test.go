@@ -0,0 +1,118 @@
+package ethstore
+
+import (
+	"bytes"
+	"errors"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"sparseth/storage/mem"
+	"testing"
+)
+
+func TestParseEncoding(t *testing.T) {
+	t.Run("should parse supported encodings", func(t *testing.T) {
+		for _, enc := range []Encoding{EncodingRLP, EncodingProtobuf} {
+			parsed, err := ParseEncoding(string(enc))
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if parsed != enc {
+				t.Errorf("expected %s, got %s", enc, parsed)
+			}
+		}
+	})
+
+	t.Run("should return error on unknown encoding", func(t *testing.T) {
+		if _, err := ParseEncoding("json"); !errors.Is(err, ErrUnknownEncoding) {
+			t.Errorf("expected %v, got %v", ErrUnknownEncoding, err)
+		}
+	})
+}
+
+func TestEncoding_Log(t *testing.T) {
+	log := &types.Log{
+		Address:     common.HexToAddress("0xaa"),
+		Topics:      []common.Hash{common.HexToHash("0x01"), common.HexToHash("0x02")},
+		Data:        []byte("data"),
+		BlockNumber: 7,
+		TxHash:      common.HexToHash("0xbb"),
+		TxIndex:     3,
+		BlockHash:   common.HexToHash("0xcc"),
+		Index:       2,
+	}
+
+	t.Run("should round-trip protobuf log", func(t *testing.T) {
+		encoded, err := encodeLog(log, EncodingProtobuf)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if !isProtobuf(encoded) {
+			t.Fatalf("expected protobuf record")
+		}
+
+		decoded, err := decodeLog(encoded)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if decoded.Address != log.Address || len(decoded.Topics) != 2 || decoded.Topics[1] != log.Topics[1] {
+			t.Errorf("expected address and topics to match, got %v", decoded)
+		}
+		if !bytes.Equal(decoded.Data, log.Data) || decoded.TxHash != log.TxHash || decoded.Index != log.Index || decoded.BlockNumber != log.BlockNumber {
+			t.Errorf("expected log to match, got %v", decoded)
+		}
+	})
+
+	t.Run("should read logs of both encodings", func(t *testing.T) {
+		db := mem.New()
+		defer db.Close()
+
+		first := &types.Log{TxHash: common.HexToHash("0x01"), Data: []byte("rlp")}
+		if err := NewEventStore(db, EncodingRLP).PutAll([]*types.Log{first}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		second := &types.Log{TxHash: common.HexToHash("0x02"), Data: []byte("protobuf")}
+		store := NewEventStore(db, EncodingProtobuf)
+		if err := store.PutAll([]*types.Log{second}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		for _, expected := range []*types.Log{first, second} {
+			log, err := store.GetLog(expected.TxHash, expected.Index)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if !bytes.Equal(log.Data, expected.Data) {
+				t.Errorf("expected %s, got %s", expected.Data, log.Data)
+			}
+		}
+	})
+
+	t.Run("should return error on truncated record", func(t *testing.T) {
+		encoded, err := encodeLog(log, EncodingProtobuf)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if _, err = decodeLog(encoded[:len(encoded)-1]); err == nil {
+			t.Errorf("expected error, got nil")
+		}
+	})
+}
+
+func TestEncoding_Digest(t *testing.T) {
+	t.Run("should round-trip protobuf digest", func(t *testing.T) {
+		digest := &BlockDigest{Number: 1, BlockHash: common.HexToHash("0x01"), Digest: common.HexToHash("0xaa"), Verified: true}
+
+		encoded, err := encodeDigest(digest, EncodingProtobuf)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		decoded, err := decodeDigest(encoded)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if *decoded != *digest {
+			t.Errorf("expected %v, got %v", digest, decoded)
+		}
+	})
+}
@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/rlp"
 	"sparseth/storage"
 	"sync"
 )
@@ -20,15 +19,20 @@ var (
 // EventStore provides thread-safe
 // storage of Ethereum event logs.
 type EventStore struct {
-	db storage.KeyValStore
-	mu sync.RWMutex
+	db  storage.KeyValStore
+	enc Encoding
+	mu  sync.RWMutex
 }
 
 // NewEventStore creates a new EventStore
-// using the specified key-val store.
-func NewEventStore(db storage.KeyValStore) *EventStore {
+// using the specified key-val store. New
+// logs are stored with the specified
+// encoding, while logs of any encoding
+// are read.
+func NewEventStore(db storage.KeyValStore, enc Encoding) *EventStore {
 	return &EventStore{
-		db: db,
+		db:  db,
+		enc: enc,
 	}
 }
 
@@ -47,12 +51,12 @@ func (s *EventStore) GetLog(txHash common.Hash, logIndex uint) (*types.Log, erro
 		return nil, fmt.Errorf("failed to get log: %w", err)
 	}
 
-	var log types.Log
-	if err = rlp.DecodeBytes(encoded, &log); err != nil {
+	log, err := decodeLog(encoded)
+	if err != nil {
 		return nil, fmt.Errorf("failed to decode log: %w", err)
 	}
 
-	return &log, nil
+	return log, nil
 }
 
 // PutAll stores the specified logs
@@ -64,7 +68,7 @@ func (s *EventStore) PutAll(logs []*types.Log) error {
 	batch := s.db.NewBatchWithSize(len(logs))
 
 	for _, log := range logs {
-		encoded, err := rlp.EncodeToBytes(log)
+		encoded, err := encodeLog(log, s.enc)
 		if err != nil {
 			return fmt.Errorf("failed to encode log: %w", err)
 		}
@@ -75,3 +79,21 @@ func (s *EventStore) PutAll(logs []*types.Log) error {
 
 	return batch.Write()
 }
+
+// DeleteAll removes the logs with the specified
+// IDs from the EventStore, e.g., of reorged
+// blocks. Missing logs are ignored.
+func (s *EventStore) DeleteAll(ids []*LogID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	batch := s.db.NewBatchWithSize(len(ids))
+
+	for _, id := range ids {
+		if err := batch.Delete(logKey(id.TxHash, uint(id.Index))); err != nil {
+			return fmt.Errorf("failed to delete log in batch: %w", err)
+		}
+	}
+
+	return batch.Write()
+}
@@ -13,7 +13,7 @@ func TestEventStore_GetLog(t *testing.T) {
 		db := mem.New()
 		defer db.Close()
 
-		store := NewEventStore(db)
+		store := NewEventStore(db, EncodingRLP)
 		if _, err := store.GetLog(common.BytesToHash([]byte("tx-1")), 1); err == nil {
 			t.Errorf("should return error when log not found")
 		}
@@ -23,7 +23,7 @@ func TestEventStore_GetLog(t *testing.T) {
 		db := mem.New()
 		defer db.Close()
 
-		store := NewEventStore(db)
+		store := NewEventStore(db, EncodingRLP)
 		logs := []*types.Log{
 			{
 				TxHash: common.BytesToHash([]byte("tx-1")),
@@ -56,7 +56,7 @@ func TestEventStore_PutAll(t *testing.T) {
 		db := mem.New()
 		defer db.Close()
 
-		store := NewEventStore(db)
+		store := NewEventStore(db, EncodingRLP)
 		logs := []*types.Log{
 			{
 				TxHash: common.BytesToHash([]byte("tx-1")),
@@ -6,6 +6,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/rlp"
+	"slices"
 	"sparseth/storage"
 	"sync"
 )
@@ -80,22 +81,86 @@ func (s *HeaderStore) GetByNumber(num uint64) (*types.Header, error) {
 	return header, nil
 }
 
+// AtOrBefore retrieves the header with the largest
+// block number stored at or before the specified
+// block number.
+func (s *HeaderStore) AtOrBefore(num uint64) (*types.Header, error) {
+	headers, err := s.last(encodeNumber(num), 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(headers) == 0 {
+		return nil, ErrHeaderNotFound
+	}
+	return headers[0], nil
+}
+
+// Last retrieves the last n headers stored
+// by block number, in ascending order.
+func (s *HeaderStore) Last(n int) ([]*types.Header, error) {
+	headers, err := s.last(nil, n)
+	if err != nil {
+		return nil, err
+	}
+	slices.Reverse(headers)
+	return headers, nil
+}
+
+// last retrieves at most n headers stored by block
+// number, in descending order, starting at the
+// specified encoded block number, or at the last
+// block number if nil.
+func (s *HeaderStore) last(start []byte, n int) ([]*types.Header, error) {
+	s.mu.RLock()
+	prefix := headerNumberKey(0)[:len(headerPrefix)+1]
+	it := s.db.NewReverseIterator(prefix, start)
+
+	var hashes []common.Hash
+	for len(hashes) < n && it.Next() {
+		hashes = append(hashes, common.BytesToHash(it.Value()))
+	}
+	err := it.Error()
+	it.Release()
+	s.mu.RUnlock()
+	if err != nil {
+		return nil, fmt.Errorf("failed to iterate headers: %w", err)
+	}
+
+	headers := make([]*types.Header, 0, len(hashes))
+	for _, hash := range hashes {
+		header, err := s.GetByHash(hash)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get header by hash: %w", err)
+		}
+		headers = append(headers, header)
+	}
+	return headers, nil
+}
+
 // Put stores the specified header in the store.
 func (s *HeaderStore) Put(header *types.Header) error {
+	return s.PutAll([]*types.Header{header})
+}
+
+// PutAll stores all specified headers
+// in the store with a single write.
+func (s *HeaderStore) PutAll(headers []*types.Header) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	encoded, err := rlp.EncodeToBytes(header)
-	if err != nil {
-		return err
-	}
+	batch := s.db.NewBatchWithSize(2 * len(headers))
+	for _, header := range headers {
+		encoded, err := rlp.EncodeToBytes(header)
+		if err != nil {
+			return err
+		}
 
-	batch := s.db.NewBatchWithSize(2)
-	if err = batch.Put(headerHashKey(header.Hash()), encoded); err != nil {
-		return fmt.Errorf("failed to put header in batch: %w", err)
-	}
-	if err = batch.Put(headerNumberKey(header.Number.Uint64()), header.Hash().Bytes()); err != nil {
-		return fmt.Errorf("failed to put header in batch: %w", err)
+		if err = batch.Put(headerHashKey(header.Hash()), encoded); err != nil {
+			return fmt.Errorf("failed to put header in batch: %w", err)
+		}
+		if err = batch.Put(headerNumberKey(header.Number.Uint64()), header.Hash().Bytes()); err != nil {
+			return fmt.Errorf("failed to put header in batch: %w", err)
+		}
 	}
 	return batch.Write()
 }
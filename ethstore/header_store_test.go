@@ -2,6 +2,7 @@ package ethstore
 
 import (
 	"bytes"
+	"errors"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"math/big"
@@ -26,6 +27,33 @@ func TestHeaderStore_Put(t *testing.T) {
 	})
 }
 
+func TestHeaderStore_PutAll(t *testing.T) {
+	t.Run("should store all headers", func(t *testing.T) {
+		db := mem.New()
+		defer db.Close()
+
+		store := NewHeaderStore(db)
+		headers := make([]*types.Header, 3)
+		for i := range headers {
+			headers[i] = &types.Header{Number: big.NewInt(int64(i))}
+		}
+
+		if err := store.PutAll(headers); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		for _, header := range headers {
+			res, err := store.GetByNumber(header.Number.Uint64())
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if res.Hash() != header.Hash() {
+				t.Errorf("expected hash %s, got %s", header.Hash(), res.Hash())
+			}
+		}
+	})
+}
+
 func TestHeaderStore_GetByHash(t *testing.T) {
 	t.Run("should return error when header not found", func(t *testing.T) {
 		db := mem.New()
@@ -107,3 +135,68 @@ func TestHeaderStore_GetByNumber(t *testing.T) {
 		}
 	})
 }
+
+func TestHeaderStore_Last(t *testing.T) {
+	t.Run("should return last headers in ascending order", func(t *testing.T) {
+		db := mem.New()
+		defer db.Close()
+
+		store := NewHeaderStore(db)
+		headers := make([]*types.Header, 5)
+		for i := range headers {
+			headers[i] = &types.Header{Number: big.NewInt(int64(i))}
+		}
+		if err := store.PutAll(headers); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		last, err := store.Last(2)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(last) != 2 {
+			t.Fatalf("expected 2 headers, got %d", len(last))
+		}
+		if last[0].Number.Uint64() != 3 || last[1].Number.Uint64() != 4 {
+			t.Errorf("expected headers 3 and 4, got %d and %d", last[0].Number, last[1].Number)
+		}
+	})
+}
+
+func TestHeaderStore_AtOrBefore(t *testing.T) {
+	t.Run("should return latest header at or before number", func(t *testing.T) {
+		db := mem.New()
+		defer db.Close()
+
+		store := NewHeaderStore(db)
+		for _, num := range []int64{2, 5, 300} {
+			if err := store.Put(&types.Header{Number: big.NewInt(num)}); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+		}
+
+		for num, expected := range map[uint64]uint64{2: 2, 4: 2, 5: 5, 299: 5, 1000: 300} {
+			header, err := store.AtOrBefore(num)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if header.Number.Uint64() != expected {
+				t.Errorf("expected header %d at or before %d, got %d", expected, num, header.Number.Uint64())
+			}
+		}
+	})
+
+	t.Run("should return error when no header before number", func(t *testing.T) {
+		db := mem.New()
+		defer db.Close()
+
+		store := NewHeaderStore(db)
+		if err := store.Put(&types.Header{Number: big.NewInt(5)}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if _, err := store.AtOrBefore(4); !errors.Is(err, ErrHeaderNotFound) {
+			t.Errorf("expected header not found, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,122 @@
+package ethstore
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+	"slices"
+	"sort"
+	"sparseth/storage"
+)
+
+// KeyStats describes all keys stored
+// under a common schema prefix.
+type KeyStats struct {
+	// Prefix is the schema prefix, e.g.,
+	// "se:header:", or empty for keys stored
+	// by go-ethereum, i.e., the world state.
+	Prefix string
+	Keys   uint64
+	// Size is the total size of all
+	// keys and values in bytes.
+	Size uint64
+}
+
+// Inspect counts all keys stored in the specified
+// key-val store, and their size, by schema prefix,
+// sorted by prefix.
+func Inspect(db storage.KeyValStore) ([]*KeyStats, error) {
+	it := db.NewIterator(nil, nil)
+	defer it.Release()
+
+	indexed := make(map[string]*KeyStats)
+	for it.Next() {
+		prefix := schemaPrefix(it.Key())
+		stats, ok := indexed[prefix]
+		if !ok {
+			stats = &KeyStats{Prefix: prefix}
+			indexed[prefix] = stats
+		}
+		stats.Keys++
+		stats.Size += uint64(len(it.Key()) + len(it.Value()))
+	}
+	if err := it.Error(); err != nil {
+		return nil, fmt.Errorf("failed to iterate keys: %w", err)
+	}
+
+	stats := make([]*KeyStats, 0, len(indexed))
+	for _, s := range indexed {
+		stats = append(stats, s)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Prefix < stats[j].Prefix
+	})
+	return stats, nil
+}
+
+// AccountStats describes the verified event
+// hash chain heads stored for an account.
+type AccountStats struct {
+	Address common.Address
+	// Head is the most recent
+	// verified hash chain head.
+	Head *ChainHead
+	// Heads is the number of stored heads,
+	// i.e., of blocks with verified events.
+	Heads uint64
+	// Events is the number of events
+	// verified with the stored heads.
+	Events uint64
+}
+
+// InspectAccounts summarizes the verified hash
+// chain heads of all accounts stored in the
+// specified key-val store, sorted by address.
+func InspectAccounts(db storage.KeyValStore) ([]*AccountStats, error) {
+	it := db.NewReverseIterator(chainHeadPrefix, nil)
+	defer it.Release()
+
+	var stats []*AccountStats
+	for it.Next() {
+		key := it.Key()
+		if len(key) < len(chainHeadPrefix)+common.AddressLength {
+			continue
+		}
+		addr := common.BytesToAddress(key[len(chainHeadPrefix) : len(chainHeadPrefix)+common.AddressLength])
+
+		var head ChainHead
+		if err := rlp.DecodeBytes(it.Value(), &head); err != nil {
+			return nil, fmt.Errorf("failed to decode chain head of %s: %w", addr.Hex(), err)
+		}
+
+		// Most recent heads come first
+		if len(stats) == 0 || stats[len(stats)-1].Address != addr {
+			stats = append(stats, &AccountStats{Address: addr, Head: &head})
+		}
+		s := stats[len(stats)-1]
+		s.Heads++
+		s.Events += uint64(len(head.Logs))
+	}
+	if err := it.Error(); err != nil {
+		return nil, fmt.Errorf("failed to iterate chain heads: %w", err)
+	}
+
+	// Accounts are iterated in descending order
+	slices.Reverse(stats)
+	return stats, nil
+}
+
+// schemaPrefix returns the schema prefix of the
+// specified key, i.e., up to the first colon after
+// the sparseth prefix, or the whole key if it has
+// none, e.g., the delivery head.
+func schemaPrefix(key []byte) string {
+	if !bytes.HasPrefix(key, sparsethPrefix) {
+		return ""
+	}
+	if i := bytes.IndexByte(key[len(sparsethPrefix):], ':'); i >= 0 {
+		return string(key[:len(sparsethPrefix)+i+1])
+	}
+	return string(key)
+}
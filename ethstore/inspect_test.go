@@ -0,0 +1,82 @@
+package ethstore
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"math/big"
+	"sparseth/storage/mem"
+	"testing"
+)
+
+func TestInspect(t *testing.T) {
+	t.Run("should count keys by schema prefix", func(t *testing.T) {
+		db := mem.New()
+		defer db.Close()
+
+		headers := NewHeaderStore(db)
+		for i := range 2 {
+			if err := headers.Put(&types.Header{Number: big.NewInt(int64(i))}); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+		}
+		if err := NewRootStore(db).Put(&StateRoot{Number: 1, Root: common.HexToHash("0x01")}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if err := db.Put([]byte("trie-node"), []byte{0x01}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		stats, err := Inspect(db)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		counts := make(map[string]uint64)
+		for _, s := range stats {
+			counts[s.Prefix] = s.Keys
+		}
+		if counts[""] != 1 || counts["se:root:"] != 1 || counts["se:header:"] == 0 {
+			t.Errorf("expected keys of world state, roots, and headers, got %v", counts)
+		}
+		if stats[0].Prefix != "" {
+			t.Errorf("expected stats sorted by prefix, got %s first", stats[0].Prefix)
+		}
+	})
+}
+
+func TestInspectAccounts(t *testing.T) {
+	t.Run("should summarize chain heads by account", func(t *testing.T) {
+		db := mem.New()
+		defer db.Close()
+
+		acc1 := common.HexToAddress("0x1")
+		acc2 := common.HexToAddress("0x2")
+		heads := NewChainHeadStore(db)
+		for _, num := range []uint64{10, 12} {
+			err := heads.Put(acc1, &ChainHead{
+				Number: num,
+				Logs:   []*LogID{{BlockNumber: num}, {BlockNumber: num, Index: 1}},
+			})
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+		}
+		if err := heads.Put(acc2, &ChainHead{Number: 11, Logs: []*LogID{{BlockNumber: 11}}}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		stats, err := InspectAccounts(db)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(stats) != 2 {
+			t.Fatalf("expected 2 accounts, got %d", len(stats))
+		}
+		if stats[0].Address != acc1 || stats[0].Head.Number != 12 || stats[0].Heads != 2 || stats[0].Events != 4 {
+			t.Errorf("expected head 12 with 2 heads and 4 events, got %+v", stats[0])
+		}
+		if stats[1].Address != acc2 || stats[1].Head.Number != 11 || stats[1].Heads != 1 || stats[1].Events != 1 {
+			t.Errorf("expected head 11 with 1 head and 1 event, got %+v", stats[1])
+		}
+	})
+}
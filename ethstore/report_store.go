@@ -0,0 +1,108 @@
+package ethstore
+
+import (
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+	"sparseth/storage"
+	"sync"
+)
+
+var (
+	// ErrReportNotFound is returned when a
+	// requested report is not found in the
+	// store.
+	ErrReportNotFound = errors.New("report not found")
+)
+
+// Report summarizes the blocks committed
+// during a period, per monitored account.
+type Report struct {
+	// Start and End delimit the period, in
+	// seconds since the Unix epoch, where
+	// End is exclusive.
+	Start uint64
+	End   uint64
+	// FirstBlock and LastBlock are the numbers
+	// of the first and last block committed in
+	// the period, if any.
+	FirstBlock uint64
+	LastBlock  uint64
+	// Blocks is the number of blocks
+	// committed in the period.
+	Blocks uint64
+	// Accounts holds the summary of each
+	// account active in the period.
+	Accounts []*AccountReport
+}
+
+// AccountReport summarizes the blocks
+// committed during a period for a single
+// account.
+type AccountReport struct {
+	Address common.Address
+	// Verified is the number of blocks
+	// verified for the account.
+	Verified uint64
+	// Failed is the number of blocks that
+	// failed verification for the account.
+	Failed uint64
+	// Activity is the number of verified events
+	// (event mode) or re-executed transactions
+	// (sparse mode) of the account.
+	Activity uint64
+	// Alerts is the number of alerts
+	// raised for the account.
+	Alerts uint64
+}
+
+// ReportStore provides thread-safe storage
+// of reports by start of their period.
+type ReportStore struct {
+	db storage.KeyValStore
+	mu sync.RWMutex
+}
+
+// NewReportStore creates a new ReportStore
+// using the specified key-val store.
+func NewReportStore(db storage.KeyValStore) *ReportStore {
+	return &ReportStore{
+		db: db,
+	}
+}
+
+// Get retrieves the report of the period
+// with the specified start.
+func (s *ReportStore) Get(start uint64) (*Report, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	encoded, err := s.db.Get(reportKey(start))
+	if err != nil {
+		if errors.Is(err, storage.ErrKeyNotFound) {
+			return nil, ErrReportNotFound
+		}
+		return nil, fmt.Errorf("failed to get report: %w", err)
+	}
+
+	var report Report
+	if err = rlp.DecodeBytes(encoded, &report); err != nil {
+		return nil, fmt.Errorf("failed to decode report: %w", err)
+	}
+
+	return &report, nil
+}
+
+// Put stores the specified report.
+func (s *ReportStore) Put(report *Report) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	encoded, err := rlp.EncodeToBytes(report)
+	if err != nil {
+		return fmt.Errorf("failed to encode report: %w", err)
+	}
+
+	return s.db.Put(reportKey(report.Start), encoded)
+}
@@ -0,0 +1,48 @@
+package ethstore
+
+import (
+	"errors"
+	"github.com/ethereum/go-ethereum/common"
+	"sparseth/storage/mem"
+	"testing"
+)
+
+func TestReportStore_Get(t *testing.T) {
+	t.Run("should return error when report not found", func(t *testing.T) {
+		db := mem.New()
+		defer db.Close()
+
+		store := NewReportStore(db)
+		if _, err := store.Get(3600); !errors.Is(err, ErrReportNotFound) {
+			t.Errorf("expected %v, got %v", ErrReportNotFound, err)
+		}
+	})
+
+	t.Run("should return stored report", func(t *testing.T) {
+		db := mem.New()
+		defer db.Close()
+
+		store := NewReportStore(db)
+		report := &Report{
+			Start:      3600,
+			End:        7200,
+			FirstBlock: 10,
+			LastBlock:  20,
+			Blocks:     11,
+			Accounts: []*AccountReport{
+				{Address: common.HexToAddress("0xaa"), Verified: 10, Failed: 1, Activity: 5, Alerts: 1},
+			},
+		}
+		if err := store.Put(report); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		res, err := store.Get(3600)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if res.Blocks != 11 || len(res.Accounts) != 1 || *res.Accounts[0] != *report.Accounts[0] {
+			t.Errorf("expected %+v, got %+v", report, res)
+		}
+	})
+}
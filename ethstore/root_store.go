@@ -0,0 +1,81 @@
+package ethstore
+
+import (
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+	"sparseth/storage"
+	"sync"
+)
+
+var (
+	// ErrRootNotFound is returned when a
+	// requested state root is not found
+	// in the store.
+	ErrRootNotFound = errors.New("root not found")
+)
+
+// StateRoot is the root of the persistent
+// world state after a processed block.
+type StateRoot struct {
+	Number    uint64
+	BlockHash common.Hash
+	Root      common.Hash
+	// ReceiptsHash is the root of the receipts
+	// computed by re-execution, or zero if the
+	// root was not recorded by processing.
+	ReceiptsHash common.Hash `rlp:"optional"`
+}
+
+// RootStore provides thread-safe storage
+// of world state roots by block number.
+type RootStore struct {
+	db storage.KeyValStore
+	mu sync.RWMutex
+}
+
+// NewRootStore creates a new RootStore
+// using the specified key-val store.
+func NewRootStore(db storage.KeyValStore) *RootStore {
+	return &RootStore{
+		db: db,
+	}
+}
+
+// Get retrieves the state root at the
+// specified block number.
+func (s *RootStore) Get(num uint64) (*StateRoot, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	encoded, err := s.db.Get(rootKey(num))
+	if err != nil {
+		if errors.Is(err, storage.ErrKeyNotFound) {
+			return nil, ErrRootNotFound
+		}
+		return nil, fmt.Errorf("failed to get root: %w", err)
+	}
+
+	var root StateRoot
+	if err = rlp.DecodeBytes(encoded, &root); err != nil {
+		return nil, fmt.Errorf("failed to decode root: %w", err)
+	}
+
+	return &root, nil
+}
+
+// Put stores the specified state root. A root
+// previously stored at the same block number,
+// e.g., of a reorged block, is overwritten.
+func (s *RootStore) Put(root *StateRoot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	encoded, err := rlp.EncodeToBytes(root)
+	if err != nil {
+		return fmt.Errorf("failed to encode root: %w", err)
+	}
+
+	return s.db.Put(rootKey(root.Number), encoded)
+}
@@ -0,0 +1,42 @@
+package ethstore
+
+import (
+	"errors"
+	"github.com/ethereum/go-ethereum/common"
+	"sparseth/storage/mem"
+	"testing"
+)
+
+func TestRootStore_Get(t *testing.T) {
+	t.Run("should return error when root not found", func(t *testing.T) {
+		db := mem.New()
+		defer db.Close()
+
+		store := NewRootStore(db)
+		if _, err := store.Get(1); !errors.Is(err, ErrRootNotFound) {
+			t.Errorf("expected %v, got %v", ErrRootNotFound, err)
+		}
+	})
+
+	t.Run("should return latest root stored at block", func(t *testing.T) {
+		db := mem.New()
+		defer db.Close()
+
+		store := NewRootStore(db)
+		reorged := &StateRoot{Number: 1, BlockHash: common.HexToHash("0x01"), Root: common.HexToHash("0xaa")}
+		canonical := &StateRoot{Number: 1, BlockHash: common.HexToHash("0x02"), Root: common.HexToHash("0xbb")}
+		for _, root := range []*StateRoot{reorged, canonical} {
+			if err := store.Put(root); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+		}
+
+		res, err := store.Get(1)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if res.BlockHash != canonical.BlockHash || res.Root != canonical.Root {
+			t.Errorf("expected root %s at block %s, got %s at %s", canonical.Root.Hex(), canonical.BlockHash.Hex(), res.Root.Hex(), res.BlockHash.Hex())
+		}
+	})
+}
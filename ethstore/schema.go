@@ -19,6 +19,83 @@ var (
 	// headerPrefix is used to prefix all block headers
 	// in the key-val store.
 	headerPrefix = prefix("header:")
+
+	// snapshotPrefix is used to prefix all verified
+	// account snapshots in the key-val store.
+	snapshotPrefix = prefix("snapshot:")
+
+	// rootPrefix is used to prefix all world state
+	// roots by block number in the key-val store.
+	rootPrefix = prefix("root:")
+
+	// deliveryPrefix is used to prefix all entries
+	// of the delivery log in the key-val store.
+	deliveryPrefix = prefix("delivery:")
+
+	// deliveryHeadKey tracks the sequence number
+	// of the latest entry of the delivery log.
+	deliveryHeadKey = prefix("delivery-head")
+
+	// deliveredPrefix is used to prefix the hash of
+	// the last block recorded in the delivery log by
+	// block number.
+	deliveredPrefix = prefix("delivered:")
+
+	// ackPrefix is used to prefix the latest sequence
+	// number acknowledged by each sink.
+	ackPrefix = prefix("ack:")
+
+	// codePrefix is used to prefix all contract
+	// code by code hash in the key-val store.
+	codePrefix = prefix("code:")
+
+	// trustPrefix is used to prefix the trust
+	// of all RPC providers in the key-val store.
+	trustPrefix = prefix("trust:")
+
+	// digestPrefix is used to prefix all block
+	// digests by block number in the key-val store.
+	digestPrefix = prefix("digest:")
+
+	// txPrefix is used to prefix the outcomes of
+	// all re-executed transactions by hash in
+	// the key-val store.
+	txPrefix = prefix("tx:")
+
+	// reportPrefix is used to prefix all periodic
+	// digest reports by start of their period in
+	// the key-val store.
+	reportPrefix = prefix("report:")
+
+	// chainHeadPrefix is used to prefix the verified
+	// event hash chain heads of all accounts by block
+	// number in the key-val store.
+	chainHeadPrefix = prefix("chainhead:")
+
+	// diffPrefix is used to prefix the state diffs
+	// of all monitored accounts by block number in
+	// the key-val store.
+	diffPrefix = prefix("diff:")
+
+	// cursorPrefix is used to prefix the last block
+	// acknowledged by each monitor in the key-val
+	// store.
+	cursorPrefix = prefix("cursor:")
+
+	// deadLetterPrefix is used to prefix all blocks
+	// that monitors failed to process by block number
+	// in the key-val store.
+	deadLetterPrefix = prefix("deadletter:")
+
+	// attestationPrefix is used to prefix the signed
+	// attestations of all accounts by block number
+	// in the key-val store.
+	attestationPrefix = prefix("attestation:")
+
+	// breakerPrefix is used to prefix the tripped
+	// circuit breakers of all accounts in the
+	// key-val store.
+	breakerPrefix = prefix("breaker:")
 )
 
 // logKey generates a unique key for a log.
@@ -59,6 +136,221 @@ func headerNumberKey(num uint64) []byte {
 	return key
 }
 
+// snapshotBlockKey generates the common key prefix
+// for all account snapshots at the specified block.
+//
+// snapshotBlockKey = se:snapshot:<num>
+func snapshotBlockKey(num uint64) []byte {
+	// 8 for uint64
+	key := make([]byte, 0, len(snapshotPrefix)+8)
+	key = append(key, snapshotPrefix...)
+	key = append(key, encodeNumber(num)...)
+	return key
+}
+
+// snapshotKey generates a unique key for
+// an account snapshot at a block.
+//
+// snapshotKey = se:snapshot:<num><hash><addr>
+func snapshotKey(num uint64, hash common.Hash, addr common.Address) []byte {
+	key := make([]byte, 0, len(snapshotPrefix)+8+common.HashLength+common.AddressLength)
+	key = append(key, snapshotBlockKey(num)...)
+	key = append(key, hash.Bytes()...)
+	key = append(key, addr.Bytes()...)
+	return key
+}
+
+// rootKey generates a unique key for the
+// world state root at a block.
+//
+// rootKey = se:root:<num>
+func rootKey(num uint64) []byte {
+	// 8 for uint64
+	key := make([]byte, 0, len(rootPrefix)+8)
+	key = append(key, rootPrefix...)
+	key = append(key, encodeNumber(num)...)
+	return key
+}
+
+// deliveryKey generates a unique key for
+// an entry of the delivery log.
+//
+// deliveryKey = se:delivery:<seq>
+func deliveryKey(seq uint64) []byte {
+	// 8 for uint64
+	key := make([]byte, 0, len(deliveryPrefix)+8)
+	key = append(key, deliveryPrefix...)
+	key = append(key, encodeNumber(seq)...)
+	return key
+}
+
+// deliveredKey generates a unique key for the
+// hash of the last block recorded in the delivery
+// log with a block number.
+//
+// deliveredKey = se:delivered:<num>
+func deliveredKey(num uint64) []byte {
+	// 8 for uint64
+	key := make([]byte, 0, len(deliveredPrefix)+8)
+	key = append(key, deliveredPrefix...)
+	key = append(key, encodeNumber(num)...)
+	return key
+}
+
+// ackKey generates a unique key for the latest
+// sequence number acknowledged by a sink.
+//
+// ackKey = se:ack:<sink>
+func ackKey(sink string) []byte {
+	key := make([]byte, 0, len(ackPrefix)+len(sink))
+	key = append(key, ackPrefix...)
+	key = append(key, sink...)
+	return key
+}
+
+// codeKey generates a unique key for
+// the contract code with a code hash.
+//
+// codeKey = se:code:<hash>
+func codeKey(hash common.Hash) []byte {
+	key := make([]byte, 0, len(codePrefix)+common.HashLength)
+	key = append(key, codePrefix...)
+	key = append(key, hash.Bytes()...)
+	return key
+}
+
+// trustKey generates a unique key for
+// the trust of an RPC provider.
+//
+// trustKey = se:trust:<url>
+func trustKey(url string) []byte {
+	key := make([]byte, 0, len(trustPrefix)+len(url))
+	key = append(key, trustPrefix...)
+	key = append(key, url...)
+	return key
+}
+
+// digestKey generates a unique key for
+// the digest of a block.
+//
+// digestKey = se:digest:<num>
+func digestKey(num uint64) []byte {
+	// 8 for uint64
+	key := make([]byte, 0, len(digestPrefix)+8)
+	key = append(key, digestPrefix...)
+	key = append(key, encodeNumber(num)...)
+	return key
+}
+
+// txKey generates a unique key for the
+// outcome of a transaction.
+//
+// txKey = se:tx:<hash>
+func txKey(hash common.Hash) []byte {
+	key := make([]byte, 0, len(txPrefix)+common.HashLength)
+	key = append(key, txPrefix...)
+	key = append(key, hash.Bytes()...)
+	return key
+}
+
+// reportKey generates a unique key for the
+// report of the period with the specified
+// start, in seconds since the Unix epoch.
+//
+// reportKey = se:report:<start>
+func reportKey(start uint64) []byte {
+	// 8 for uint64
+	key := make([]byte, 0, len(reportPrefix)+8)
+	key = append(key, reportPrefix...)
+	key = append(key, encodeNumber(start)...)
+	return key
+}
+
+// chainHeadKey generates a unique key for the
+// hash chain head of an account after a block.
+//
+// chainHeadKey = se:chainhead:<addr><num>
+func chainHeadKey(addr common.Address, num uint64) []byte {
+	// 8 for uint64
+	key := make([]byte, 0, len(chainHeadPrefix)+common.AddressLength+8)
+	key = append(key, chainHeadPrefix...)
+	key = append(key, addr.Bytes()...)
+	key = append(key, encodeNumber(num)...)
+	return key
+}
+
+// diffKey generates a unique key for
+// the state diff of a block.
+//
+// diffKey = se:diff:<num>
+func diffKey(num uint64) []byte {
+	// 8 for uint64
+	key := make([]byte, 0, len(diffPrefix)+8)
+	key = append(key, diffPrefix...)
+	key = append(key, encodeNumber(num)...)
+	return key
+}
+
+// cursorKey generates a unique key for the
+// last block acknowledged by a monitor.
+//
+// cursorKey = se:cursor:<monitor>
+func cursorKey(monitor string) []byte {
+	key := make([]byte, 0, len(cursorPrefix)+len(monitor))
+	key = append(key, cursorPrefix...)
+	key = append(key, monitor...)
+	return key
+}
+
+// deadLetterKey generates a unique key for a
+// block that a monitor failed to process.
+//
+// deadLetterKey = se:deadletter:<num><hash><monitor>
+func deadLetterKey(num uint64, hash common.Hash, monitor string) []byte {
+	// 8 for uint64
+	key := make([]byte, 0, len(deadLetterPrefix)+8+common.HashLength+len(monitor))
+	key = append(key, deadLetterPrefix...)
+	key = append(key, encodeNumber(num)...)
+	key = append(key, hash.Bytes()...)
+	key = append(key, monitor...)
+	return key
+}
+
+// attestationKey generates a unique key for the
+// attestation of an account at a block.
+//
+// attestationKey = se:attestation:<num><account>
+func attestationKey(num uint64, account common.Address) []byte {
+	// 8 for uint64
+	key := make([]byte, 0, len(attestationPrefix)+8+common.AddressLength)
+	key = append(key, attestationBlockPrefix(num)...)
+	key = append(key, account.Bytes()...)
+	return key
+}
+
+// attestationBlockPrefix generates the common
+// prefix of all attestations of a block.
+//
+// attestationBlockPrefix = se:attestation:<num>
+func attestationBlockPrefix(num uint64) []byte {
+	// 8 for uint64
+	key := make([]byte, 0, len(attestationPrefix)+8)
+	key = append(key, attestationPrefix...)
+	key = append(key, encodeNumber(num)...)
+	return key
+}
+
+// breakerKey generates a unique key for the
+// tripped circuit breaker of an account.
+//
+// breakerKey = se:breaker:<addr>
+func breakerKey(addr common.Address) []byte {
+	key := make([]byte, 0, len(breakerPrefix)+common.AddressLength)
+	key = append(key, breakerPrefix...)
+	key = append(key, addr.Bytes()...)
+	return key
+}
+
 // prefix returns a byte slice that combines the
 // sparsethPrefix with the specified string.
 func prefix(s string) []byte {
@@ -0,0 +1,110 @@
+package ethstore
+
+import (
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+	"math/big"
+	"sparseth/storage"
+	"sync"
+)
+
+var (
+	// ErrSnapshotNotFound is returned when a
+	// requested account snapshot is not found
+	// in the store.
+	ErrSnapshotNotFound = errors.New("snapshot not found")
+)
+
+// AccountSnapshot is the verified state
+// of an account at a specific block.
+type AccountSnapshot struct {
+	Address     common.Address
+	Nonce       uint64
+	Balance     *big.Int
+	CodeHash    common.Hash
+	StorageRoot common.Hash
+}
+
+// SnapshotStore provides thread-safe storage of
+// verified account snapshots at recent blocks.
+//
+// Snapshots are keyed by block number and hash,
+// so that snapshots of reorged blocks are never
+// returned for the canonical block. Only the
+// snapshots of the most recent blocks are kept,
+// older snapshots are pruned on insertion.
+type SnapshotStore struct {
+	db     storage.KeyValStore
+	blocks uint64
+	mu     sync.RWMutex
+}
+
+// NewSnapshotStore creates a new SnapshotStore
+// using the specified key-val store, keeping
+// the snapshots of the specified number of
+// most recent blocks.
+func NewSnapshotStore(db storage.KeyValStore, blocks uint64) *SnapshotStore {
+	return &SnapshotStore{
+		db:     db,
+		blocks: blocks,
+	}
+}
+
+// Get retrieves the snapshot of the specified
+// account at the specified block.
+func (s *SnapshotStore) Get(header *types.Header, addr common.Address) (*AccountSnapshot, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	encoded, err := s.db.Get(snapshotKey(header.Number.Uint64(), header.Hash(), addr))
+	if err != nil {
+		if errors.Is(err, storage.ErrKeyNotFound) {
+			return nil, ErrSnapshotNotFound
+		}
+		return nil, fmt.Errorf("failed to get snapshot: %w", err)
+	}
+
+	var snapshot AccountSnapshot
+	if err = rlp.DecodeBytes(encoded, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to decode snapshot: %w", err)
+	}
+
+	return &snapshot, nil
+}
+
+// PutAll stores the specified snapshots at the
+// specified block, and prunes all snapshots
+// that are no longer among the most recent
+// blocks.
+func (s *SnapshotStore) PutAll(header *types.Header, snapshots []*AccountSnapshot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	num := header.Number.Uint64()
+	batch := s.db.NewBatchWithSize(len(snapshots))
+
+	for _, snapshot := range snapshots {
+		encoded, err := rlp.EncodeToBytes(snapshot)
+		if err != nil {
+			return fmt.Errorf("failed to encode snapshot: %w", err)
+		}
+		if err = batch.Put(snapshotKey(num, header.Hash(), snapshot.Address), encoded); err != nil {
+			return fmt.Errorf("failed to put snapshot in batch: %w", err)
+		}
+	}
+	if err := batch.Write(); err != nil {
+		return fmt.Errorf("failed to write snapshots: %w", err)
+	}
+
+	if num < s.blocks {
+		return nil
+	}
+	if err := s.db.DeleteRange(snapshotBlockKey(0), snapshotBlockKey(num-s.blocks+1)); err != nil {
+		return fmt.Errorf("failed to prune snapshots: %w", err)
+	}
+
+	return nil
+}
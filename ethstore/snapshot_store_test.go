@@ -0,0 +1,95 @@
+package ethstore
+
+import (
+	"errors"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"math/big"
+	"sparseth/storage/mem"
+	"testing"
+)
+
+func TestSnapshotStore_Get(t *testing.T) {
+	addr := common.HexToAddress("0xdeadbeefdeadbeefdeadbeefdeadbeefdeadbeef")
+
+	t.Run("should return error when snapshot not found", func(t *testing.T) {
+		db := mem.New()
+		defer db.Close()
+
+		store := NewSnapshotStore(db, 8)
+		header := &types.Header{Number: big.NewInt(1)}
+		if _, err := store.Get(header, addr); !errors.Is(err, ErrSnapshotNotFound) {
+			t.Errorf("expected %v, got %v", ErrSnapshotNotFound, err)
+		}
+	})
+
+	t.Run("should return previously stored snapshot", func(t *testing.T) {
+		db := mem.New()
+		defer db.Close()
+
+		store := NewSnapshotStore(db, 8)
+		header := &types.Header{Number: big.NewInt(1)}
+		snapshot := &AccountSnapshot{
+			Address:     addr,
+			Nonce:       7,
+			Balance:     big.NewInt(42),
+			CodeHash:    types.EmptyCodeHash,
+			StorageRoot: types.EmptyRootHash,
+		}
+		if err := store.PutAll(header, []*AccountSnapshot{snapshot}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		res, err := store.Get(header, addr)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if res.Nonce != snapshot.Nonce || res.Balance.Cmp(snapshot.Balance) != 0 {
+			t.Errorf("expected nonce %d and balance %s, got %d and %s", snapshot.Nonce, snapshot.Balance, res.Nonce, res.Balance)
+		}
+	})
+
+	t.Run("should not return snapshot of reorged block", func(t *testing.T) {
+		db := mem.New()
+		defer db.Close()
+
+		store := NewSnapshotStore(db, 8)
+		reorged := &types.Header{Number: big.NewInt(1), Extra: []byte("reorged")}
+		canonical := &types.Header{Number: big.NewInt(1), Extra: []byte("canonical")}
+		if err := store.PutAll(reorged, []*AccountSnapshot{{Address: addr, Balance: big.NewInt(1)}}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if _, err := store.Get(canonical, addr); !errors.Is(err, ErrSnapshotNotFound) {
+			t.Errorf("expected %v, got %v", ErrSnapshotNotFound, err)
+		}
+	})
+}
+
+func TestSnapshotStore_PutAll(t *testing.T) {
+	addr := common.HexToAddress("0xdeadbeefdeadbeefdeadbeefdeadbeefdeadbeef")
+
+	t.Run("should prune snapshots of old blocks", func(t *testing.T) {
+		db := mem.New()
+		defer db.Close()
+
+		store := NewSnapshotStore(db, 2)
+		headers := make([]*types.Header, 4)
+		for i := range headers {
+			headers[i] = &types.Header{Number: big.NewInt(int64(i))}
+			if err := store.PutAll(headers[i], []*AccountSnapshot{{Address: addr, Balance: big.NewInt(int64(i))}}); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+		}
+
+		for i, header := range headers {
+			_, err := store.Get(header, addr)
+			if i < 2 && !errors.Is(err, ErrSnapshotNotFound) {
+				t.Errorf("expected snapshot at block %d to be pruned, got %v", i, err)
+			}
+			if i >= 2 && err != nil {
+				t.Errorf("expected snapshot at block %d, got %v", i, err)
+			}
+		}
+	})
+}
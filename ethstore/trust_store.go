@@ -0,0 +1,92 @@
+package ethstore
+
+import (
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/rlp"
+	"math"
+	"sparseth/storage"
+)
+
+var (
+	// ErrTrustNotFound is returned when the
+	// trust of a provider is not found in
+	// the store.
+	ErrTrustNotFound = errors.New("trust not found")
+)
+
+// ProviderTrust is the persisted trust of
+// an RPC provider, i.e., how often the data
+// it claimed matched the proven state.
+type ProviderTrust struct {
+	URL         string
+	Score       float64
+	Agreements  uint64
+	Divergences uint64
+}
+
+// storedTrust is the encoding of a
+// ProviderTrust, as RLP does not
+// support floating-point numbers.
+type storedTrust struct {
+	URL         string
+	Score       uint64
+	Agreements  uint64
+	Divergences uint64
+}
+
+// TrustStore provides thread-safe storage
+// of provider trust by provider URL.
+type TrustStore struct {
+	db storage.KeyValStore
+}
+
+// NewTrustStore creates a new TrustStore
+// using the specified key-val store.
+func NewTrustStore(db storage.KeyValStore) *TrustStore {
+	return &TrustStore{
+		db: db,
+	}
+}
+
+// Get retrieves the trust of the provider
+// with the specified URL.
+func (s *TrustStore) Get(url string) (*ProviderTrust, error) {
+	encoded, err := s.db.Get(trustKey(url))
+	if err != nil {
+		if errors.Is(err, storage.ErrKeyNotFound) {
+			return nil, ErrTrustNotFound
+		}
+		return nil, fmt.Errorf("failed to get trust: %w", err)
+	}
+
+	var stored storedTrust
+	if err = rlp.DecodeBytes(encoded, &stored); err != nil {
+		return nil, fmt.Errorf("failed to decode trust: %w", err)
+	}
+
+	return &ProviderTrust{
+		URL:         stored.URL,
+		Score:       math.Float64frombits(stored.Score),
+		Agreements:  stored.Agreements,
+		Divergences: stored.Divergences,
+	}, nil
+}
+
+// Put stores the specified trust.
+func (s *TrustStore) Put(trust *ProviderTrust) error {
+	encoded, err := rlp.EncodeToBytes(&storedTrust{
+		URL:         trust.URL,
+		Score:       math.Float64bits(trust.Score),
+		Agreements:  trust.Agreements,
+		Divergences: trust.Divergences,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode trust: %w", err)
+	}
+
+	if err = s.db.Put(trustKey(trust.URL), encoded); err != nil {
+		return fmt.Errorf("failed to put trust: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package ethstore
+
+import (
+	"errors"
+	"sparseth/storage/mem"
+	"testing"
+)
+
+func TestTrustStore_Get(t *testing.T) {
+	url := "ws://localhost:8545"
+
+	t.Run("should return error when trust not found", func(t *testing.T) {
+		db := mem.New()
+		defer db.Close()
+
+		store := NewTrustStore(db)
+		if _, err := store.Get(url); !errors.Is(err, ErrTrustNotFound) {
+			t.Errorf("expected %v, got %v", ErrTrustNotFound, err)
+		}
+	})
+
+	t.Run("should return previously stored trust", func(t *testing.T) {
+		db := mem.New()
+		defer db.Close()
+
+		store := NewTrustStore(db)
+		trust := &ProviderTrust{
+			URL:         url,
+			Score:       0.875,
+			Agreements:  7,
+			Divergences: 1,
+		}
+		if err := store.Put(trust); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		got, err := store.Get(url)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if *got != *trust {
+			t.Errorf("expected %+v, got %+v", trust, got)
+		}
+	})
+}
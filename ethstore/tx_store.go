@@ -0,0 +1,88 @@
+package ethstore
+
+import (
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+	"sparseth/storage"
+)
+
+var (
+	// ErrTxNotFound is returned when the
+	// outcome of a transaction is not found
+	// in the store.
+	ErrTxNotFound = errors.New("tx not found")
+)
+
+// TxOutcome is the verification outcome
+// of a re-executed transaction.
+type TxOutcome struct {
+	TxHash    common.Hash
+	Number    uint64
+	BlockHash common.Hash
+	// Status is the receipt status
+	// computed by re-execution.
+	Status uint64
+	// Verified indicates whether the effects of
+	// the transaction are included in the
+	// verified state.
+	Verified bool
+	// Accounts holds the monitored
+	// accounts the transaction touched.
+	Accounts []common.Address
+}
+
+// TxStore provides storage of transaction
+// outcomes by transaction hash.
+type TxStore struct {
+	db storage.KeyValStore
+}
+
+// NewTxStore creates a new TxStore
+// using the specified key-val store.
+func NewTxStore(db storage.KeyValStore) *TxStore {
+	return &TxStore{
+		db: db,
+	}
+}
+
+// Get retrieves the outcome of the transaction
+// with the specified hash.
+func (s *TxStore) Get(hash common.Hash) (*TxOutcome, error) {
+	encoded, err := s.db.Get(txKey(hash))
+	if err != nil {
+		if errors.Is(err, storage.ErrKeyNotFound) {
+			return nil, ErrTxNotFound
+		}
+		return nil, fmt.Errorf("failed to get tx outcome: %w", err)
+	}
+
+	var outcome TxOutcome
+	if err = rlp.DecodeBytes(encoded, &outcome); err != nil {
+		return nil, fmt.Errorf("failed to decode tx outcome: %w", err)
+	}
+
+	return &outcome, nil
+}
+
+// PutAll stores the specified outcomes. The outcome
+// previously stored for the same transaction, e.g.,
+// of a reorged or retried block, is overwritten.
+func (s *TxStore) PutAll(outcomes []*TxOutcome) error {
+	batch := s.db.NewBatchWithSize(len(outcomes))
+	for _, outcome := range outcomes {
+		encoded, err := rlp.EncodeToBytes(outcome)
+		if err != nil {
+			return fmt.Errorf("failed to encode tx outcome: %w", err)
+		}
+		if err = batch.Put(txKey(outcome.TxHash), encoded); err != nil {
+			return fmt.Errorf("failed to put tx outcome in batch: %w", err)
+		}
+	}
+
+	if err := batch.Write(); err != nil {
+		return fmt.Errorf("failed to write tx outcomes: %w", err)
+	}
+	return nil
+}
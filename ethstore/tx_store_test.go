@@ -0,0 +1,43 @@
+package ethstore
+
+import (
+	"errors"
+	"github.com/ethereum/go-ethereum/common"
+	"sparseth/storage/mem"
+	"testing"
+)
+
+func TestTxStore_Get(t *testing.T) {
+	t.Run("should return error when tx not found", func(t *testing.T) {
+		db := mem.New()
+		defer db.Close()
+
+		store := NewTxStore(db)
+		if _, err := store.Get(common.HexToHash("0x01")); !errors.Is(err, ErrTxNotFound) {
+			t.Errorf("expected %v, got %v", ErrTxNotFound, err)
+		}
+	})
+
+	t.Run("should return latest outcome of tx", func(t *testing.T) {
+		db := mem.New()
+		defer db.Close()
+
+		store := NewTxStore(db)
+		hash := common.HexToHash("0x01")
+		failed := &TxOutcome{TxHash: hash, Number: 1, BlockHash: common.HexToHash("0xaa"), Status: 1}
+		verified := &TxOutcome{TxHash: hash, Number: 1, BlockHash: common.HexToHash("0xaa"), Status: 1, Verified: true, Accounts: []common.Address{{0x01}}}
+		for _, outcome := range []*TxOutcome{failed, verified} {
+			if err := store.PutAll([]*TxOutcome{outcome}); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+		}
+
+		res, err := store.Get(hash)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if !res.Verified || len(res.Accounts) != 1 {
+			t.Errorf("expected verified outcome with 1 account, got %+v", res)
+		}
+	})
+}
@@ -0,0 +1,190 @@
+package ens
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"math/big"
+	"sparseth/execution/ethclient"
+	"sparseth/log"
+	"strings"
+)
+
+// coinTypeETH is the SLIP-44 coin type
+// of Ethereum addresses, see ENSIP-9.
+const coinTypeETH = 60
+
+var (
+	// Registry is the address of the ENS registry,
+	// which is the same on mainnet and testnets.
+	Registry = common.HexToAddress("0x00000000000C2E074eC69A0dFb2997BA6C7d2e1e")
+
+	// ErrUnsupportedResolver is returned if the storage
+	// layout of the resolver of a name is unknown, so
+	// that its resolution cannot be verified.
+	ErrUnsupportedResolver = errors.New("unsupported resolver")
+
+	// ErrNotFound is returned if a name
+	// does not resolve to an address.
+	ErrNotFound = errors.New("name not found")
+)
+
+// resolverLayout is the storage
+// layout of a resolver contract.
+type resolverLayout struct {
+	// versionsSlot is the slot of the
+	// mapping of record versions by node.
+	versionsSlot int64
+	// addressesSlot is the slot of the mapping
+	// of addresses by version, node, and coin
+	// type.
+	addressesSlot int64
+}
+
+// publicResolverLayout is the storage layout
+// of the versioned PublicResolver, i.e.,
+// recordVersions, versionable_abis, and
+// versionable_addresses.
+var publicResolverLayout = &resolverLayout{
+	versionsSlot:  0,
+	addressesSlot: 2,
+}
+
+// resolvers holds the storage layouts
+// of known resolvers by address.
+var resolvers = map[common.Address]*resolverLayout{
+	// PublicResolver on mainnet
+	common.HexToAddress("0x231b0Ee14048e9dCcD1d247744d114a4EB5E8E63"): publicResolverLayout,
+	common.HexToAddress("0xF29100983E058B709F3D539b0c765937B804AC15"): publicResolverLayout,
+	// PublicResolver on Sepolia
+	common.HexToAddress("0x8FADE66B79cC9f707aB26799354482EB93a5B7dD"): publicResolverLayout,
+}
+
+// Resolver resolves ENS names to addresses
+// at a trusted block, where the records of
+// both the registry and the resolver are
+// verified with storage proofs.
+type Resolver struct {
+	provider ethclient.Provider
+	head     *types.Header
+	log      log.Logger
+}
+
+// NewResolver creates a new Resolver that resolves
+// names at the specified trusted block.
+func NewResolver(provider ethclient.Provider, head *types.Header, log log.Logger) *Resolver {
+	return &Resolver{
+		provider: provider,
+		head:     head,
+		log:      log.With("component", "ens-resolver"),
+	}
+}
+
+// Resolve returns the verified address the specified
+// name, e.g., vitalik.eth, resolves to. Only names
+// whose resolver has a known storage layout can be
+// resolved.
+func (r *Resolver) Resolve(ctx context.Context, name string) (common.Address, error) {
+	node, err := Namehash(name)
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	// The resolver is the second field of
+	// the record of the node in the registry
+	record := mappingSlot(node, big.NewInt(0))
+	resolver, err := r.read(ctx, Registry, offsetSlot(record, 1))
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to read resolver of %s: %w", name, err)
+	}
+	resolverAddr := common.BytesToAddress(resolver[common.HashLength-common.AddressLength:])
+	if resolverAddr == (common.Address{}) {
+		return common.Address{}, fmt.Errorf("%w: %s has no resolver", ErrNotFound, name)
+	}
+
+	layout, ok := resolvers[resolverAddr]
+	if !ok {
+		return common.Address{}, fmt.Errorf("%w: %s of %s", ErrUnsupportedResolver, resolverAddr.Hex(), name)
+	}
+
+	version, err := r.read(ctx, resolverAddr, mappingSlot(node, big.NewInt(layout.versionsSlot)))
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to read record version of %s: %w", name, err)
+	}
+	// versionable_addresses[version][node][coinType]
+	slot := mappingSlot(common.BytesToHash(version[common.HashLength-8:]), big.NewInt(layout.addressesSlot))
+	slot = mappingSlot(node, slot.Big())
+	slot = mappingSlot(common.BigToHash(big.NewInt(coinTypeETH)), slot.Big())
+
+	val, err := r.read(ctx, resolverAddr, slot)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to read address of %s: %w", name, err)
+	}
+	// Short byte arrays are stored left-aligned,
+	// with twice their length in the last byte
+	if val[common.HashLength-1] != 2*common.AddressLength {
+		return common.Address{}, fmt.Errorf("%w: %s has no address", ErrNotFound, name)
+	}
+	addr := common.BytesToAddress(val[:common.AddressLength])
+
+	r.log.Info("resolved ENS name", "name", name, "address", addr.Hex(), "resolver", resolverAddr.Hex(), "num", r.head.Number)
+	return addr, nil
+}
+
+// read returns the verified value of the specified
+// storage slot at the trusted block, padded to 32
+// bytes.
+func (r *Resolver) read(ctx context.Context, addr common.Address, slot common.Hash) (common.Hash, error) {
+	val, err := r.provider.GetStorageAtBlock(ctx, addr, slot, r.head)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return common.BytesToHash(val), nil
+}
+
+// Namehash returns the node of the specified name,
+// see EIP-137. Names must be normalized, which is
+// only supported for lowercase ASCII names.
+func Namehash(name string) (common.Hash, error) {
+	var node common.Hash
+	if name == "" {
+		return node, nil
+	}
+
+	labels := strings.Split(name, ".")
+	for i := len(labels) - 1; i >= 0; i-- {
+		label := labels[i]
+		if label == "" || !isNormalized(label) {
+			return common.Hash{}, fmt.Errorf("invalid or unnormalized name: %s", name)
+		}
+		node = crypto.Keccak256Hash(node.Bytes(), crypto.Keccak256([]byte(label)))
+	}
+	return node, nil
+}
+
+// isNormalized checks whether the specified
+// label is a normalized ASCII label.
+func isNormalized(label string) bool {
+	for _, c := range label {
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') && c != '-' && c != '_' {
+			return false
+		}
+	}
+	return true
+}
+
+// mappingSlot returns the slot of the value
+// for the specified key of the mapping at
+// the specified slot.
+func mappingSlot(key common.Hash, slot *big.Int) common.Hash {
+	return crypto.Keccak256Hash(key.Bytes(), common.BigToHash(slot).Bytes())
+}
+
+// offsetSlot returns the slot at the specified
+// offset from the specified slot.
+func offsetSlot(slot common.Hash, offset int64) common.Hash {
+	return common.BigToHash(new(big.Int).Add(slot.Big(), big.NewInt(offset)))
+}
@@ -0,0 +1,118 @@
+package ens
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math/big"
+	"sparseth/execution/ethclient"
+	"sparseth/internal/log"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+type testStorageProvider struct {
+	ethclient.Provider
+	storage map[common.Address]map[common.Hash]common.Hash
+}
+
+func (p *testStorageProvider) GetStorageAtBlock(_ context.Context, acc common.Address, slot common.Hash, _ *types.Header) ([]byte, error) {
+	return p.storage[acc][slot].Bytes(), nil
+}
+
+func (p *testStorageProvider) set(acc common.Address, slot, val common.Hash) {
+	if p.storage[acc] == nil {
+		p.storage[acc] = make(map[common.Hash]common.Hash)
+	}
+	p.storage[acc][slot] = val
+}
+
+func TestNamehash(t *testing.T) {
+	t.Run("should hash names", func(t *testing.T) {
+		tests := map[string]string{
+			"":            "0x0000000000000000000000000000000000000000000000000000000000000000",
+			"eth":         "0x93cdeb708b7545dc668eb9280176169d1c33cfd8ed6f04690a0bcc88a93fc4ae",
+			"foo.eth":     "0xde9b09fd7c5f901e23a3f19fecc54828e9c848539801e86591bd9801b019f84f",
+			"vitalik.eth": "0xee6c4522aab0003e8d14cd40a6af439055fd2577951148c14b6cea9a53475835",
+		}
+		for name, expected := range tests {
+			node, err := Namehash(name)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if node.Hex() != expected {
+				t.Errorf("expected %s for %q, got %s", expected, name, node.Hex())
+			}
+		}
+	})
+
+	t.Run("should reject unnormalized names", func(t *testing.T) {
+		for _, name := range []string{"Vitalik.eth", "foo..eth", "café.eth"} {
+			if _, err := Namehash(name); err == nil {
+				t.Errorf("expected error for %q, got nil", name)
+			}
+		}
+	})
+}
+
+func TestResolver_Resolve(t *testing.T) {
+	testLogger := log.New(slog.DiscardHandler)
+	head := &types.Header{Number: big.NewInt(1)}
+	resolver := common.HexToAddress("0x231b0Ee14048e9dCcD1d247744d114a4EB5E8E63")
+	addr := common.HexToAddress("0xd8dA6BF26964aF9D7eEd9e10e5d6b5e4eF06E5A8")
+
+	// setup stores the records of vitalik.eth at
+	// the specified resolver and record version
+	setup := func(resolverAddr common.Address, version int64) *testStorageProvider {
+		node, _ := Namehash("vitalik.eth")
+		p := &testStorageProvider{storage: make(map[common.Address]map[common.Hash]common.Hash)}
+		p.set(Registry, offsetSlot(mappingSlot(node, big.NewInt(0)), 1), common.BytesToHash(resolverAddr.Bytes()))
+		p.set(resolverAddr, mappingSlot(node, big.NewInt(0)), common.BigToHash(big.NewInt(version)))
+
+		slot := mappingSlot(common.BigToHash(big.NewInt(version)), big.NewInt(2))
+		slot = mappingSlot(node, slot.Big())
+		slot = mappingSlot(common.BigToHash(big.NewInt(coinTypeETH)), slot.Big())
+		var val common.Hash
+		copy(val[:], addr.Bytes())
+		val[common.HashLength-1] = 2 * common.AddressLength
+		p.set(resolverAddr, slot, val)
+		return p
+	}
+
+	t.Run("should resolve name", func(t *testing.T) {
+		got, err := NewResolver(setup(resolver, 3), head, testLogger).Resolve(t.Context(), "vitalik.eth")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if got != addr {
+			t.Errorf("expected %s, got %s", addr.Hex(), got.Hex())
+		}
+	})
+
+	t.Run("should fail on record of other version", func(t *testing.T) {
+		p := setup(resolver, 3)
+		node, _ := Namehash("vitalik.eth")
+		p.set(resolver, mappingSlot(node, big.NewInt(0)), common.BigToHash(big.NewInt(4)))
+
+		_, err := NewResolver(p, head, testLogger).Resolve(t.Context(), "vitalik.eth")
+		if !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected %v, got %v", ErrNotFound, err)
+		}
+	})
+
+	t.Run("should fail on unknown name", func(t *testing.T) {
+		_, err := NewResolver(setup(resolver, 0), head, testLogger).Resolve(t.Context(), "nick.eth")
+		if !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected %v, got %v", ErrNotFound, err)
+		}
+	})
+
+	t.Run("should fail on unsupported resolver", func(t *testing.T) {
+		_, err := NewResolver(setup(common.HexToAddress("0x01"), 0), head, testLogger).Resolve(t.Context(), "vitalik.eth")
+		if !errors.Is(err, ErrUnsupportedResolver) {
+			t.Errorf("expected %v, got %v", ErrUnsupportedResolver, err)
+		}
+	})
+}
@@ -6,21 +6,33 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
-	"sparseth/execution/mpt"
+	"github.com/pslowak/sparseth/execution/mpt"
+	"slices"
 )
 
+// proofBatchSize is the maximum number of
+// accounts whose proofs are requested in a
+// single JSON-RPC batch.
+const proofBatchSize = 100
+
 // accountProvider provides verified
 // account-related data via the
 // Ethereum RPC API.
+//
+// Verified accounts and storage slots are
+// cached, so that each is requested at most
+// once per block.
 type accountProvider struct {
-	c *Client
+	c     *Client
+	cache *proofCache
 }
 
 // newAccountProvider creates a new accountProvider
 // using the specified client.
 func newAccountProvider(client *Client) *accountProvider {
 	return &accountProvider{
-		c: client,
+		c:     client,
+		cache: newProofCache(),
 	}
 }
 
@@ -28,6 +40,22 @@ func newAccountProvider(client *Client) *accountProvider {
 // account at the specified block, or nil
 // if no such account exists.
 func (p *accountProvider) getAccountAtBlock(ctx context.Context, account common.Address, header *types.Header) (*Account, error) {
+	if acc, ok := p.cache.account(header.Hash(), account); ok {
+		return acc, nil
+	}
+
+	acc, err := p.fetchAccountAtBlock(ctx, account, header)
+	if err != nil {
+		return nil, err
+	}
+	p.cache.addAccount(header.Hash(), account, acc)
+	return acc, nil
+}
+
+// fetchAccountAtBlock requests and verifies
+// the proof of the specified account at the
+// specified block, see getAccountAtBlock.
+func (p *accountProvider) fetchAccountAtBlock(ctx context.Context, account common.Address, header *types.Header) (*Account, error) {
 	proof, err := p.c.GetProof(ctx, account, nil, header.Hash())
 	if err != nil {
 		return nil, fmt.Errorf("failed to get proof: %w", err)
@@ -51,6 +79,93 @@ func (p *accountProvider) getAccountAtBlock(ctx context.Context, account common.
 	}, nil
 }
 
+// getAccountsAtBlock provides the verified accounts
+// and storage slots of all specified requests at the
+// specified block, in order of the requests. Proofs
+// are requested in batches of at most proofBatchSize
+// accounts.
+//
+// Storage slots of accounts that do not exist at
+// the specified block are omitted. Requests whose
+// account and slots are all cached are served
+// without requesting proofs.
+func (p *accountProvider) getAccountsAtBlock(ctx context.Context, reqs []*ProofRequest, header *types.Header) ([]*AccountState, error) {
+	states := make([]*AccountState, len(reqs))
+
+	// Indices of all requests not
+	// served from the cache
+	missing := make([]int, 0, len(reqs))
+	for i, req := range reqs {
+		if state, ok := p.cache.state(header.Hash(), req); ok {
+			states[i] = state
+		} else {
+			missing = append(missing, i)
+		}
+	}
+
+	for indices := range slices.Chunk(missing, proofBatchSize) {
+		batch := make([]*ProofRequest, len(indices))
+		for i, idx := range indices {
+			batch[i] = reqs[idx]
+		}
+
+		proofs, err := p.c.GetProofs(ctx, batch, header.Hash())
+		if err != nil {
+			return nil, fmt.Errorf("failed to get proofs: %w", err)
+		}
+
+		for i, proof := range proofs {
+			state, err := verifyAccountState(header, batch[i], proof)
+			if err != nil {
+				return nil, fmt.Errorf("failed to verify account %s: %w", batch[i].Address.Hex(), err)
+			}
+			p.cache.addState(header.Hash(), batch[i].Address, state)
+			states[indices[i]] = state
+		}
+	}
+
+	return states, nil
+}
+
+// verifyAccountState verifies the specified proof
+// for the specified request against the state root
+// of the specified block.
+func verifyAccountState(header *types.Header, req *ProofRequest, proof *Proof) (*AccountState, error) {
+	acc, err := mpt.VerifyAccountProof(header.Root, req.Address, proof.AccountProof)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify account: %w", err)
+	}
+
+	state := &AccountState{
+		Storage: make(map[common.Hash][]byte, len(req.Slots)),
+	}
+	if acc == nil {
+		// Account does not exist
+		return state, nil
+	}
+
+	state.Account = &Account{
+		Address:     req.Address,
+		Nonce:       acc.Nonce,
+		Balance:     acc.Balance,
+		CodeHash:    acc.CodeHash,
+		StorageRoot: acc.StorageRoot,
+	}
+
+	if len(proof.StorageProof) != len(req.Slots) {
+		return nil, fmt.Errorf("expected %d storage proofs, got %d", len(req.Slots), len(proof.StorageProof))
+	}
+	for i, slot := range req.Slots {
+		val, err := mpt.VerifyStorageProof(acc.StorageRoot, mpt.StorageKey(slot), proof.StorageProof[i].Proof)
+		if err != nil {
+			return nil, fmt.Errorf("failed to verify storage slot %s: %w", slot.Hex(), err)
+		}
+		state.Storage[slot] = val
+	}
+
+	return state, nil
+}
+
 // getSlotAtBlock provides the verified value stored
 // at the specified storage slot for the specified
 // Ethereum account at the specified block.
@@ -58,6 +173,22 @@ func (p *accountProvider) getAccountAtBlock(ctx context.Context, account common.
 // Note that the specified account must exist at the
 // specified block, otherwise an error will be returned.
 func (p *accountProvider) getSlotAtBlock(ctx context.Context, addr common.Address, slot common.Hash, header *types.Header) ([]byte, error) {
+	if val, ok := p.cache.slot(header.Hash(), addr, slot); ok {
+		return val, nil
+	}
+
+	val, err := p.fetchSlotAtBlock(ctx, addr, slot, header)
+	if err != nil {
+		return nil, err
+	}
+	p.cache.addSlot(header.Hash(), addr, slot, val)
+	return val, nil
+}
+
+// fetchSlotAtBlock requests and verifies the
+// proof of the specified storage slot at the
+// specified block, see getSlotAtBlock.
+func (p *accountProvider) fetchSlotAtBlock(ctx context.Context, addr common.Address, slot common.Hash, header *types.Header) ([]byte, error) {
 	proof, err := p.c.GetProof(ctx, addr, []common.Hash{slot}, header.Hash())
 	if err != nil {
 		return nil, fmt.Errorf("failed to get proof: %w", err)
@@ -75,7 +206,7 @@ func (p *accountProvider) getSlotAtBlock(ctx context.Context, addr common.Addres
 		return nil, fmt.Errorf("missing storage proof for slot")
 	}
 
-	slotHash := crypto.Keccak256Hash(slot.Bytes())
+	slotHash := mpt.StorageKey(slot)
 	val, err := mpt.VerifyStorageProof(acc.StorageRoot, slotHash, proof.StorageProof[0].Proof)
 	if err != nil {
 		return nil, fmt.Errorf("failed to verify storage: %w", err)
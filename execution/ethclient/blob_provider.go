@@ -0,0 +1,162 @@
+package ethclient
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// secondsPerSlot is the duration
+// of a beacon chain slot.
+const secondsPerSlot = 12
+
+// BlobSidecar is a blob of a blob transaction
+// along with its KZG commitment and proof, as
+// served by the Beacon API.
+type BlobSidecar struct {
+	Blob       *kzg4844.Blob
+	Commitment kzg4844.Commitment
+	Proof      kzg4844.Proof
+}
+
+// VersionedHash returns the versioned hash
+// of the blob, as referenced by transactions.
+func (s *BlobSidecar) VersionedHash() common.Hash {
+	return kzg4844.CalcBlobHashV1(sha256.New(), &s.Commitment)
+}
+
+// BlobProvider provides verified blob sidecars
+// via the Beacon API of a consensus client.
+//
+// Note that consensus clients prune blobs after
+// about 18 days, so older blobs are unavailable.
+type BlobProvider struct {
+	url string
+	c   *http.Client
+
+	// genesis is the genesis time of the
+	// beacon chain, fetched once on demand.
+	genesis uint64
+	mu      sync.Mutex
+}
+
+// NewBlobProvider creates a new BlobProvider
+// using the Beacon API at the specified URL.
+func NewBlobProvider(url string) *BlobProvider {
+	return &BlobProvider{
+		url: strings.TrimSuffix(url, "/"),
+		c:   &http.Client{},
+	}
+}
+
+// GetBlobsAtBlock retrieves the blobs with the specified
+// versioned hashes included in the specified block. Each
+// blob is verified against its KZG commitment, and the
+// commitment against the versioned hash.
+func (p *BlobProvider) GetBlobsAtBlock(ctx context.Context, header *types.Header, hashes []common.Hash) ([]*BlobSidecar, error) {
+	if len(hashes) == 0 {
+		return nil, nil
+	}
+
+	slot, err := p.slot(ctx, header)
+	if err != nil {
+		return nil, err
+	}
+
+	var res struct {
+		Data []struct {
+			Blob          *kzg4844.Blob      `json:"blob"`
+			KZGCommitment kzg4844.Commitment `json:"kzg_commitment"`
+			KZGProof      kzg4844.Proof      `json:"kzg_proof"`
+		} `json:"data"`
+	}
+	if err = p.get(ctx, fmt.Sprintf("/eth/v1/beacon/blob_sidecars/%d", slot), &res); err != nil {
+		return nil, fmt.Errorf("failed to get blob sidecars at slot %d: %w", slot, err)
+	}
+
+	sidecars := make(map[common.Hash]*BlobSidecar, len(res.Data))
+	for _, data := range res.Data {
+		if data.Blob == nil {
+			return nil, fmt.Errorf("blob sidecar at slot %d has no blob", slot)
+		}
+		sidecar := &BlobSidecar{
+			Blob:       data.Blob,
+			Commitment: data.KZGCommitment,
+			Proof:      data.KZGProof,
+		}
+		sidecars[sidecar.VersionedHash()] = sidecar
+	}
+
+	blobs := make([]*BlobSidecar, len(hashes))
+	for i, hash := range hashes {
+		sidecar, ok := sidecars[hash]
+		if !ok {
+			return nil, fmt.Errorf("blob %s not found at slot %d", hash.Hex(), slot)
+		}
+		if err = kzg4844.VerifyBlobProof(sidecar.Blob, sidecar.Commitment, sidecar.Proof); err != nil {
+			return nil, fmt.Errorf("invalid KZG proof for blob %s: %w", hash.Hex(), err)
+		}
+		blobs[i] = sidecar
+	}
+	return blobs, nil
+}
+
+// slot returns the beacon chain slot
+// of the specified execution block.
+func (p *BlobProvider) slot(ctx context.Context, header *types.Header) (uint64, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.genesis == 0 {
+		var res struct {
+			Data struct {
+				GenesisTime string `json:"genesis_time"`
+			} `json:"data"`
+		}
+		if err := p.get(ctx, "/eth/v1/beacon/genesis", &res); err != nil {
+			return 0, fmt.Errorf("failed to get genesis: %w", err)
+		}
+		genesis, err := strconv.ParseUint(res.Data.GenesisTime, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid genesis time %q: %w", res.Data.GenesisTime, err)
+		}
+		p.genesis = genesis
+	}
+
+	if header.Time < p.genesis {
+		return 0, fmt.Errorf("block %d precedes beacon chain genesis", header.Number.Uint64())
+	}
+	return (header.Time - p.genesis) / secondsPerSlot, nil
+}
+
+// get fetches the specified path of the Beacon
+// API and decodes the JSON response into res.
+func (p *BlobProvider) get(ctx context.Context, path string, res any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url+path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.c.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+	if err = json.NewDecoder(resp.Body).Decode(res); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package ethclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newBlobEndpoint(t *testing.T, blob *kzg4844.Blob, commitment kzg4844.Commitment, proof kzg4844.Proof) string {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/eth/v1/beacon/genesis", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"data":{"genesis_time":"1000"}}`)
+	})
+	mux.HandleFunc("/eth/v1/beacon/blob_sidecars/10", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{
+			"data": []map[string]any{{
+				"index":          "0",
+				"blob":           blob,
+				"kzg_commitment": commitment,
+				"kzg_proof":      proof,
+			}},
+		})
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+func TestBlobProvider_GetBlobsAtBlock(t *testing.T) {
+	blob := new(kzg4844.Blob)
+	commitment, err := kzg4844.BlobToCommitment(blob)
+	if err != nil {
+		t.Fatalf("failed to compute commitment: %v", err)
+	}
+	proof, err := kzg4844.ComputeBlobProof(blob, commitment)
+	if err != nil {
+		t.Fatalf("failed to compute proof: %v", err)
+	}
+	hash := (&BlobSidecar{Commitment: commitment}).VersionedHash()
+
+	// Slot 10 after genesis
+	header := &types.Header{Number: big.NewInt(1), Time: 1000 + 10*secondsPerSlot}
+
+	t.Run("should return verified blobs", func(t *testing.T) {
+		p := NewBlobProvider(newBlobEndpoint(t, blob, commitment, proof))
+
+		blobs, err := p.GetBlobsAtBlock(t.Context(), header, []common.Hash{hash})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(blobs) != 1 || blobs[0].Commitment != commitment {
+			t.Errorf("expected blob with commitment, got %v", blobs)
+		}
+	})
+
+	t.Run("should fail if blob is missing", func(t *testing.T) {
+		p := NewBlobProvider(newBlobEndpoint(t, blob, commitment, proof))
+
+		if _, err := p.GetBlobsAtBlock(t.Context(), header, []common.Hash{{0x01}}); err == nil {
+			t.Errorf("expected error, got nil")
+		}
+	})
+
+	t.Run("should fail if proof is invalid", func(t *testing.T) {
+		p := NewBlobProvider(newBlobEndpoint(t, blob, commitment, kzg4844.Proof{}))
+
+		if _, err := p.GetBlobsAtBlock(t.Context(), header, []common.Hash{hash}); err == nil {
+			t.Errorf("expected error, got nil")
+		}
+	})
+}
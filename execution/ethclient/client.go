@@ -26,7 +26,7 @@ var (
 // Client is a wrapper for the
 // Ethereum RPC API.
 type Client struct {
-	c *rpc.Client
+	c Caller
 }
 
 // DialContext connects to an Ethereum
@@ -40,8 +40,9 @@ func DialContext(ctx context.Context, url string) (*Client, error) {
 }
 
 // NewClient creates a new Client instance
-// using an existing RPC client connection.
-func NewClient(c *rpc.Client) *Client {
+// using an existing RPC connection, e.g., an
+// *rpc.Client or a Pool.
+func NewClient(c Caller) *Client {
 	return &Client{c: c}
 }
 
@@ -52,18 +53,31 @@ func (ec *Client) Close() error {
 }
 
 // GetLogsAtBlock fetches the logs for the specified
-// Ethereum account at the specified block.
-func (ec *Client) GetLogsAtBlock(ctx context.Context, addr common.Address, blockNum *big.Int) ([]*types.Log, error) {
+// Ethereum account at the specified block. If topics
+// are specified, only logs whose first topic is any
+// of them are fetched.
+func (ec *Client) GetLogsAtBlock(ctx context.Context, addr common.Address, blockNum *big.Int, topics []common.Hash) ([]*types.Log, error) {
+	return ec.GetLogsInRange(ctx, addr, blockNum, blockNum, topics)
+}
+
+// GetLogsInRange fetches the logs for the specified
+// Ethereum account in the specified inclusive range
+// of blocks, filtered by topics as GetLogsAtBlock.
+func (ec *Client) GetLogsInRange(ctx context.Context, addr common.Address, from, to *big.Int, topics []common.Hash) ([]*types.Log, error) {
 	type query struct {
-		FromBlock string `json:"fromBlock"`
-		ToBlock   string `json:"toBlock"`
-		Address   string `json:"address"`
+		FromBlock string          `json:"fromBlock"`
+		ToBlock   string          `json:"toBlock"`
+		Address   string          `json:"address"`
+		Topics    [][]common.Hash `json:"topics,omitempty"`
 	}
 	arg := &query{
-		FromBlock: toBlockNumArg(blockNum),
-		ToBlock:   toBlockNumArg(blockNum),
+		FromBlock: toBlockNumArg(from),
+		ToBlock:   toBlockNumArg(to),
 		Address:   addr.Hex(),
 	}
+	if len(topics) > 0 {
+		arg.Topics = [][]common.Hash{topics}
+	}
 	var result []*types.Log
 	err := ec.c.CallContext(ctx, &result, "eth_getLogs", arg)
 	if err != nil {
@@ -197,6 +211,46 @@ func (ec *Client) GetProof(ctx context.Context, account common.Address, slots []
 	return resp, nil
 }
 
+// ProofRequest specifies the account and storage
+// slots to request a Merkle proof for.
+type ProofRequest struct {
+	Address common.Address
+	Slots   []common.Hash
+}
+
+// GetProofs returns Merkle proofs for all specified
+// requests at the specified block, in order of the
+// requests. All proofs are requested in a single
+// JSON-RPC batch.
+func (ec *Client) GetProofs(ctx context.Context, reqs []*ProofRequest, blockHash common.Hash) ([]*Proof, error) {
+	proofs := make([]*Proof, len(reqs))
+	batch := make([]rpc.BatchElem, len(reqs))
+	for i, req := range reqs {
+		stringSlots := make([]string, len(req.Slots))
+		for j, s := range req.Slots {
+			stringSlots[j] = s.Hex()
+		}
+		batch[i] = rpc.BatchElem{
+			Method: "eth_getProof",
+			Args:   []any{req.Address.Hex(), stringSlots, blockHash.Hex()},
+			Result: &proofs[i],
+		}
+	}
+
+	if err := ec.c.BatchCallContext(ctx, batch); err != nil {
+		return nil, fmt.Errorf("failed to get proofs: %w", err)
+	}
+	for i, elem := range batch {
+		if elem.Error != nil {
+			return nil, fmt.Errorf("failed to get proof for account %s: %w", reqs[i].Address.Hex(), elem.Error)
+		}
+		if proofs[i] == nil {
+			return nil, fmt.Errorf("missing proof for account %s", reqs[i].Address.Hex())
+		}
+	}
+	return proofs, nil
+}
+
 // GetCodeAtBlock retrieves the code for the specified
 // Ethereum account at the specified block number.
 func (ec *Client) GetCodeAtBlock(ctx context.Context, addr common.Address, blockNum *big.Int) ([]byte, error) {
@@ -226,6 +280,57 @@ func (ec *Client) GetTransactionsAtBlock(ctx context.Context, blockNum *big.Int)
 	return block.Txs, err
 }
 
+// GetWithdrawalsAtBlock retrieves all withdrawals
+// from the block with the specified number.
+func (ec *Client) GetWithdrawalsAtBlock(ctx context.Context, blockNum *big.Int) (types.Withdrawals, error) {
+	type rpcBlock struct {
+		Withdrawals types.Withdrawals `json:"withdrawals"`
+	}
+
+	var block *rpcBlock
+	err := ec.c.CallContext(ctx, &block, "eth_getBlockByNumber", toBlockNumArg(blockNum), false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get withdrawals at block %s: %w", blockNum, err)
+	}
+	if block == nil {
+		return nil, fmt.Errorf("block %s not found", blockNum)
+	}
+	return block.Withdrawals, nil
+}
+
+// HeaderByNumber retrieves the block header
+// with the specified number.
+func (ec *Client) HeaderByNumber(ctx context.Context, blockNum *big.Int) (*types.Header, error) {
+	var head *types.Header
+	err := ec.c.CallContext(ctx, &head, "eth_getBlockByNumber", toBlockNumArg(blockNum), false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get header at block %s: %w", blockNum, err)
+	}
+	if head == nil {
+		return nil, fmt.Errorf("block %s not found", blockNum)
+	}
+	return head, nil
+}
+
+// HeaderByHash retrieves the block header with
+// the specified hash, and checks that the header
+// hashes to it, so that its fields, e.g., the state
+// root, are as trustworthy as the hash.
+func (ec *Client) HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error) {
+	var head *types.Header
+	err := ec.c.CallContext(ctx, &head, "eth_getBlockByHash", hash, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get header of block %s: %w", hash.Hex(), err)
+	}
+	if head == nil {
+		return nil, fmt.Errorf("block %s not found", hash.Hex())
+	}
+	if head.Hash() != hash {
+		return nil, fmt.Errorf("header of block %s hashes to %s", hash.Hex(), head.Hash().Hex())
+	}
+	return head, nil
+}
+
 // GetTransactionTrace retrieves the transaction trace
 // with a pre-state tracer for the specified transaction
 // hash.
@@ -241,8 +346,45 @@ func (ec *Client) GetTransactionTrace(ctx context.Context, txHash common.Hash) (
 	return result, nil
 }
 
+// GetBlockTraces retrieves the traces of all transactions
+// in the block with the specified number with a pre-state
+// tracer, in order of their index in the block.
+//
+// The prestate tracer returns the accounts necessary to
+// execute each transaction.
+func (ec *Client) GetBlockTraces(ctx context.Context, blockNum *big.Int) ([]*TransactionTrace, error) {
+	type txTrace struct {
+		TxHash common.Hash       `json:"txHash"`
+		Result *TransactionTrace `json:"result"`
+		Error  string            `json:"error"`
+	}
+
+	var result []*txTrace
+	err := ec.c.CallContext(ctx, &result, "debug_traceBlockByNumber", toBlockNumArg(blockNum), prestateTracer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to trace block %s: %w", blockNum, err)
+	}
+
+	traces := make([]*TransactionTrace, len(result))
+	for i, tr := range result {
+		if tr.Error != "" {
+			return nil, fmt.Errorf("failed to trace transaction %d at block %s: %s", i, blockNum, tr.Error)
+		}
+		if tr.Result == nil {
+			return nil, fmt.Errorf("missing trace of transaction %d at block %s", i, blockNum)
+		}
+		tr.Result.TxHash = tr.TxHash
+		traces[i] = tr.Result
+	}
+	return traces, nil
+}
+
 // toBlockNumArg converts a *big.Int block number
 // to a hex-encoded string suitable for RPC calls.
 func toBlockNumArg(blockNum *big.Int) string {
+	if blockNum.Sign() < 0 {
+		// Special block tags, e.g., finalized
+		return rpc.BlockNumber(blockNum.Int64()).String()
+	}
 	return fmt.Sprintf("0x%x", blockNum)
 }
@@ -0,0 +1,112 @@
+package ethclient
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+type testProofService struct {
+	// missing is the account for
+	// which no proof is available
+	missing common.Address
+}
+
+type testProof struct {
+	Address      common.Address `json:"address"`
+	Balance      string         `json:"balance"`
+	Nonce        string         `json:"nonce"`
+	AccountProof []string       `json:"accountProof"`
+	StorageProof []struct{}     `json:"storageProof"`
+}
+
+func (s *testProofService) GetProof(_ context.Context, addr common.Address, _ []string, _ common.Hash) (*testProof, error) {
+	if addr == s.missing {
+		return nil, errors.New("proof unavailable")
+	}
+	return &testProof{Address: addr, Balance: "0x0", Nonce: "0x0", AccountProof: []string{}}, nil
+}
+
+func newTestProofClient(t *testing.T, svc *testProofService) *Client {
+	server := rpc.NewServer()
+	if err := server.RegisterName("eth", svc); err != nil {
+		t.Fatalf("failed to register service: %v", err)
+	}
+	t.Cleanup(server.Stop)
+
+	return NewClient(rpc.DialInProc(server))
+}
+
+func TestClient_GetProofs(t *testing.T) {
+	first := common.HexToAddress("0x01")
+	second := common.HexToAddress("0x02")
+
+	t.Run("should return proofs in order of requests", func(t *testing.T) {
+		ec := newTestProofClient(t, &testProofService{})
+
+		proofs, err := ec.GetProofs(t.Context(), []*ProofRequest{{Address: first}, {Address: second}}, common.Hash{})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(proofs) != 2 || proofs[0].Address != first || proofs[1].Address != second {
+			t.Errorf("expected proofs of first and second account")
+		}
+	})
+
+	t.Run("should return error if any proof fails", func(t *testing.T) {
+		ec := newTestProofClient(t, &testProofService{missing: second})
+
+		if _, err := ec.GetProofs(t.Context(), []*ProofRequest{{Address: first}, {Address: second}}, common.Hash{}); err == nil {
+			t.Errorf("expected error, got nil")
+		}
+	})
+}
+
+type testHeaderService struct {
+	header *types.Header
+}
+
+func (s *testHeaderService) GetBlockByHash(_ context.Context, _ common.Hash, _ bool) (*types.Header, error) {
+	return s.header, nil
+}
+
+func TestClient_HeaderByHash(t *testing.T) {
+	header := &types.Header{Number: big.NewInt(10), Root: common.HexToHash("0x01"), Difficulty: big.NewInt(0)}
+
+	newClient := func(t *testing.T, header *types.Header) *Client {
+		server := rpc.NewServer()
+		if err := server.RegisterName("eth", &testHeaderService{header: header}); err != nil {
+			t.Fatalf("failed to register service: %v", err)
+		}
+		t.Cleanup(server.Stop)
+
+		return NewClient(rpc.DialInProc(server))
+	}
+
+	t.Run("should return header with matching hash", func(t *testing.T) {
+		ec := newClient(t, header)
+
+		got, err := ec.HeaderByHash(t.Context(), header.Hash())
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if got.Root != header.Root {
+			t.Errorf("expected root %s, got %s", header.Root.Hex(), got.Root.Hex())
+		}
+	})
+
+	t.Run("should return error if header does not match hash", func(t *testing.T) {
+		tampered := types.CopyHeader(header)
+		tampered.Root = common.HexToHash("0x02")
+		ec := newClient(t, tampered)
+
+		if _, err := ec.HeaderByHash(t.Context(), header.Hash()); err == nil {
+			t.Errorf("expected error, got nil")
+		}
+	})
+}
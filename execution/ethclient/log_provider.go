@@ -22,7 +22,16 @@ func newLogProvider(client *Client) *logProvider {
 }
 
 // getLogsAtBlock retrieves logs for the specified
-// Ethereum account at the specified block.
-func (r *logProvider) getLogsAtBlock(ctx context.Context, account common.Address, blockNum *big.Int) ([]*types.Log, error) {
-	return r.c.GetLogsAtBlock(ctx, account, blockNum)
+// Ethereum account at the specified block, with
+// any of the specified topics, if set.
+func (r *logProvider) getLogsAtBlock(ctx context.Context, account common.Address, blockNum *big.Int, topics []common.Hash) ([]*types.Log, error) {
+	return r.c.GetLogsAtBlock(ctx, account, blockNum, topics)
+}
+
+// getLogsInRange retrieves logs for the specified
+// Ethereum account in the specified inclusive
+// range of blocks, with any of the specified
+// topics, if set.
+func (r *logProvider) getLogsInRange(ctx context.Context, account common.Address, from, to *big.Int, topics []common.Hash) ([]*types.Log, error) {
+	return r.c.GetLogsInRange(ctx, account, from, to, topics)
 }
@@ -0,0 +1,67 @@
+package ethclient
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	// ErrMethodNotAllowed is returned if a method
+	// is not allowed on any endpoint of a Pool.
+	ErrMethodNotAllowed = errors.New("method not allowed")
+)
+
+// MethodPolicy restricts the RPC methods that
+// may be sent to an endpoint.
+//
+// Patterns are either exact method names, e.g.,
+// debug_traceTransaction, or namespace wildcards,
+// e.g., debug_*. A method is allowed if it matches
+// no deny pattern and, if allow patterns are set,
+// at least one allow pattern.
+type MethodPolicy struct {
+	allow []string
+	deny  []string
+}
+
+// NewMethodPolicy creates a new MethodPolicy
+// with the specified allow and deny patterns.
+func NewMethodPolicy(allow, deny []string) *MethodPolicy {
+	return &MethodPolicy{
+		allow: allow,
+		deny:  deny,
+	}
+}
+
+// Allows checks whether the specified
+// method is allowed by the policy. A nil
+// policy allows all methods.
+func (p *MethodPolicy) Allows(method string) bool {
+	if p == nil {
+		return true
+	}
+
+	for _, pattern := range p.deny {
+		if matchMethod(pattern, method) {
+			return false
+		}
+	}
+	if len(p.allow) == 0 {
+		return true
+	}
+	for _, pattern := range p.allow {
+		if matchMethod(pattern, method) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchMethod checks whether the specified
+// method matches the specified pattern.
+func matchMethod(pattern, method string) bool {
+	if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+		return strings.HasPrefix(method, prefix)
+	}
+	return pattern == method
+}
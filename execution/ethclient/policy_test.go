@@ -0,0 +1,85 @@
+package ethclient
+
+import (
+	"errors"
+	"log/slog"
+	"sparseth/internal/log"
+	"testing"
+)
+
+func TestMethodPolicy_Allows(t *testing.T) {
+	t.Run("should allow all methods without policy", func(t *testing.T) {
+		var policy *MethodPolicy
+		if !policy.Allows("debug_traceTransaction") {
+			t.Errorf("expected method to be allowed")
+		}
+	})
+
+	t.Run("should deny methods matching wildcard", func(t *testing.T) {
+		policy := NewMethodPolicy(nil, []string{"debug_*"})
+		if policy.Allows("debug_traceTransaction") {
+			t.Errorf("expected debug_traceTransaction to be denied")
+		}
+		if !policy.Allows("eth_getProof") {
+			t.Errorf("expected eth_getProof to be allowed")
+		}
+	})
+
+	t.Run("should only allow listed methods", func(t *testing.T) {
+		policy := NewMethodPolicy([]string{"eth_getProof"}, nil)
+		if !policy.Allows("eth_getProof") {
+			t.Errorf("expected eth_getProof to be allowed")
+		}
+		if policy.Allows("eth_getLogs") {
+			t.Errorf("expected eth_getLogs to be denied")
+		}
+	})
+
+	t.Run("should prefer deny over allow", func(t *testing.T) {
+		policy := NewMethodPolicy([]string{"eth_*"}, []string{"eth_getLogs"})
+		if policy.Allows("eth_getLogs") {
+			t.Errorf("expected eth_getLogs to be denied")
+		}
+	})
+}
+
+func TestPool_MethodPolicy(t *testing.T) {
+	testLogger := log.New(slog.DiscardHandler)
+
+	t.Run("should skip endpoint denying method", func(t *testing.T) {
+		pool, err := DialPool(t.Context(), []*EndpointConfig{
+			{URL: newTestEndpoint(t, "first"), Policy: NewMethodPolicy(nil, []string{"test_*"})},
+			{URL: newTestEndpoint(t, "second")},
+		}, nil, testLogger)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		defer pool.Close()
+
+		var name string
+		if err = pool.CallContext(t.Context(), &name, "test_name"); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if name != "second" {
+			t.Errorf("expected second, got %s", name)
+		}
+	})
+
+	t.Run("should fail if no endpoint allows method", func(t *testing.T) {
+		pool, err := DialPool(t.Context(), []*EndpointConfig{
+			{URL: newTestEndpoint(t, "first"), Policy: NewMethodPolicy(nil, []string{"test_name"})},
+		}, nil, testLogger)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		defer pool.Close()
+
+		if pool.Supports("test_name") {
+			t.Errorf("expected test_name to be unsupported")
+		}
+		err = pool.CallContext(t.Context(), nil, "test_name")
+		if !errors.Is(err, ErrMethodNotAllowed) {
+			t.Errorf("expected %v, got %v", ErrMethodNotAllowed, err)
+		}
+	})
+}
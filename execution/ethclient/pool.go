@@ -0,0 +1,557 @@
+package ethclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sparseth/log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/mclock"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	// maxFailures is the number of consecutive failed
+	// calls after which an endpoint is considered
+	// unhealthy.
+	maxFailures = 3
+	// healthCheckInterval is the interval at which
+	// the health of all endpoints is checked.
+	healthCheckInterval = 15 * time.Second
+	// healthCheckTimeout is the maximum duration
+	// of a single health check.
+	healthCheckTimeout = 5 * time.Second
+)
+
+var (
+	// ErrNoEndpoint is returned if no endpoint
+	// of a Pool could serve a call.
+	ErrNoEndpoint = errors.New("no endpoint available")
+)
+
+// Caller is the interface of an Ethereum
+// RPC API connection.
+type Caller interface {
+	// CallContext performs a JSON-RPC call with
+	// the specified method and arguments.
+	CallContext(ctx context.Context, result any, method string, args ...any) error
+
+	// BatchCallContext sends all specified
+	// requests as a single batch.
+	BatchCallContext(ctx context.Context, b []rpc.BatchElem) error
+
+	// Close shuts down the connection.
+	Close()
+}
+
+// EndpointConfig configures a single
+// endpoint of a Pool.
+type EndpointConfig struct {
+	// URL is the URL of the RPC provider.
+	URL string
+	// Rate is the maximum number of calls per
+	// second sent to the provider, or zero if
+	// unlimited.
+	Rate float64
+	// Policy restricts the methods sent to the
+	// provider, or nil if all are allowed.
+	Policy *MethodPolicy
+}
+
+// endpoint is a single RPC provider of a Pool.
+type endpoint struct {
+	url      string
+	c        *rpc.Client
+	budget   *budget
+	policy   *MethodPolicy
+	healthy  bool
+	failures int
+	trust    *Trust
+}
+
+// EndpointStatus describes the
+// state of an endpoint of a Pool.
+type EndpointStatus struct {
+	URL     string
+	Healthy bool
+	Trust   Trust
+	// Usage is the share of the rate budget
+	// currently used, or zero if unlimited.
+	Usage float64
+}
+
+// Pool is a Caller that distributes calls over
+// multiple RPC providers. Endpoints are tried in
+// order of configuration: if an endpoint fails,
+// is unhealthy, or has exhausted its rate budget,
+// the call is sent to the next endpoint.
+//
+// Unhealthy endpoints are restored once they pass
+// a periodic health check, see RunContext.
+//
+// Endpoints whose claimed data repeatedly diverged
+// from the proven state are tried after all other
+// endpoints, see Observe.
+type Pool struct {
+	endpoints []*endpoint
+	clock     mclock.Clock
+	log       log.Logger
+	mu        sync.Mutex
+}
+
+// DialPool connects to all specified endpoints.
+// Endpoints that cannot be dialed are marked as
+// unhealthy. At least one endpoint must be dialed
+// successfully. Rate budgets are measured with
+// the specified clock, or the system clock if nil.
+func DialPool(ctx context.Context, configs []*EndpointConfig, clock mclock.Clock, log log.Logger) (*Pool, error) {
+	if len(configs) == 0 {
+		return nil, fmt.Errorf("no endpoints specified")
+	}
+	if clock == nil {
+		clock = mclock.System{}
+	}
+
+	p := &Pool{
+		endpoints: make([]*endpoint, 0, len(configs)),
+		clock:     clock,
+		log:       log.With("component", "rpc-pool"),
+	}
+
+	dialed := 0
+	for _, cfg := range configs {
+		ep := &endpoint{
+			url:    cfg.URL,
+			budget: newBudget(cfg.Rate, clock),
+			policy: cfg.Policy,
+			trust:  newTrust(),
+		}
+
+		c, err := rpc.DialContext(ctx, cfg.URL)
+		if err != nil {
+			p.log.Warn("failed to dial endpoint", "url", cfg.URL, "err", err)
+		} else {
+			ep.c = c
+			ep.healthy = true
+			dialed++
+		}
+		p.endpoints = append(p.endpoints, ep)
+	}
+
+	if dialed == 0 {
+		return nil, fmt.Errorf("failed to dial any endpoint: %w", ErrNoEndpoint)
+	}
+
+	return p, nil
+}
+
+// Primary returns the connection of the first
+// healthy endpoint, e.g., for subscriptions,
+// which cannot fail over transparently.
+func (p *Pool) Primary() *rpc.Client {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, ep := range p.endpoints {
+		if ep.c != nil && ep.healthy {
+			return ep.c
+		}
+	}
+	for _, ep := range p.endpoints {
+		if ep.c != nil {
+			return ep.c
+		}
+	}
+	return nil
+}
+
+// Supports checks whether the specified method
+// is allowed on at least one endpoint.
+func (p *Pool) Supports(method string) bool {
+	for _, ep := range p.endpoints {
+		if ep.policy.Allows(method) {
+			return true
+		}
+	}
+	return false
+}
+
+// Endpoints returns the status of all
+// endpoints, in order of configuration.
+func (p *Pool) Endpoints() []*EndpointStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	statuses := make([]*EndpointStatus, len(p.endpoints))
+	for i, ep := range p.endpoints {
+		statuses[i] = &EndpointStatus{
+			URL:     ep.url,
+			Healthy: ep.c != nil && ep.healthy,
+			Trust:   *ep.trust,
+			Usage:   ep.budget.usage(),
+		}
+	}
+	return statuses
+}
+
+// Saturation returns the average share of the
+// capacity of all endpoints currently used, where
+// unhealthy endpoints count as fully used, and
+// endpoints without a rate budget as unused.
+func (p *Pool) Saturation() float64 {
+	statuses := p.Endpoints()
+
+	var total float64
+	for _, status := range statuses {
+		if status.Healthy {
+			total += status.Usage
+		} else {
+			total++
+		}
+	}
+	return total / float64(len(statuses))
+}
+
+// SetTrust restores the trust of the endpoint
+// with the specified URL, e.g., as persisted
+// before a restart.
+func (p *Pool) SetTrust(url string, trust Trust) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, ep := range p.endpoints {
+		if ep.url == url {
+			*ep.trust = trust
+		}
+	}
+}
+
+// Observe records whether the data claimed by the
+// endpoints with the specified URLs for a block
+// matched the proven state, see ServedBy.
+func (p *Pool) Observe(urls []string, diverged bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, ep := range p.endpoints {
+		if !slices.Contains(urls, ep.url) {
+			continue
+		}
+
+		wasTrusted := ep.trust.isTrusted()
+		ep.trust.observe(diverged)
+		if wasTrusted && !ep.trust.isTrusted() {
+			p.log.Warn("endpoint data diverges from proven state, deprioritize", "url", ep.url, "score", ep.trust.Score)
+		}
+		if !wasTrusted && ep.trust.isTrusted() {
+			p.log.Info("endpoint trusted again", "url", ep.url, "score", ep.trust.Score)
+		}
+	}
+}
+
+// CallContext performs a JSON-RPC call with the
+// specified method and arguments, failing over
+// to the next endpoint on transport errors.
+func (p *Pool) CallContext(ctx context.Context, result any, method string, args ...any) error {
+	return p.do(ctx, []string{method}, func(c *rpc.Client) error {
+		return c.CallContext(ctx, result, method, args...)
+	})
+}
+
+// BatchCallContext sends all specified requests as a
+// single batch, failing over to the next endpoint on
+// transport errors.
+func (p *Pool) BatchCallContext(ctx context.Context, b []rpc.BatchElem) error {
+	methods := make([]string, len(b))
+	for i, elem := range b {
+		methods[i] = elem.Method
+	}
+	return p.do(ctx, methods, func(c *rpc.Client) error {
+		return c.BatchCallContext(ctx, b)
+	})
+}
+
+// Close shuts down all endpoint connections.
+func (p *Pool) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, ep := range p.endpoints {
+		if ep.c != nil {
+			ep.c.Close()
+		}
+	}
+}
+
+// RunContext periodically checks the health of
+// all endpoints until the context is canceled.
+func (p *Pool) RunContext(ctx context.Context) error {
+	for {
+		select {
+		case <-p.clock.After(healthCheckInterval):
+			p.checkHealth(ctx)
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// do sends the specified call to the first
+// available endpoint that allows all specified
+// methods, failing over to the next endpoint on
+// transport errors.
+//
+// If all healthy endpoints exhausted their rate
+// budget, do waits for budget to become available.
+// If no endpoint is healthy, the call is sent to
+// all endpoints in order as a last resort.
+func (p *Pool) do(ctx context.Context, methods []string, call func(*rpc.Client) error) error {
+	for {
+		candidates := allowing(p.healthyEndpoints(), methods)
+		if len(candidates) == 0 {
+			candidates = allowing(p.dialedEndpoints(), methods)
+		}
+		if len(candidates) == 0 {
+			return fmt.Errorf("%w: %s", ErrMethodNotAllowed, strings.Join(methods, ", "))
+		}
+		candidates = p.byTrust(candidates)
+
+		err := ErrNoEndpoint
+		var wait time.Duration
+		for _, ep := range candidates {
+			if d := p.take(ep); d > 0 {
+				if wait == 0 || d < wait {
+					wait = d
+				}
+				continue
+			}
+
+			err = call(ep.c)
+			if err == nil || !isTransportError(err) || ctx.Err() != nil {
+				p.onSuccess(ep)
+				if err == nil {
+					RecordServedBy(ctx, ep.url)
+				}
+				return err
+			}
+			p.onFailure(ep, err)
+		}
+		if wait == 0 {
+			return err
+		}
+
+		// Endpoints with exhausted rate budget
+		// remain, retry once budget is available
+		select {
+		case <-p.clock.After(wait):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// healthyEndpoints returns all healthy endpoints
+// with an established connection.
+func (p *Pool) healthyEndpoints() []*endpoint {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	healthy := make([]*endpoint, 0, len(p.endpoints))
+	for _, ep := range p.endpoints {
+		if ep.c != nil && ep.healthy {
+			healthy = append(healthy, ep)
+		}
+	}
+	return healthy
+}
+
+// take consumes one call from the rate budget
+// of the specified endpoint, see budget.take.
+func (p *Pool) take(ep *endpoint) time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return ep.budget.take()
+}
+
+// dialedEndpoints returns all endpoints with an
+// established connection, regardless of health.
+func (p *Pool) dialedEndpoints() []*endpoint {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	dialed := make([]*endpoint, 0, len(p.endpoints))
+	for _, ep := range p.endpoints {
+		if ep.c != nil {
+			dialed = append(dialed, ep)
+		}
+	}
+	return dialed
+}
+
+// byTrust orders the specified endpoints such
+// that trusted endpoints come first, retaining
+// the order of configuration otherwise.
+func (p *Pool) byTrust(endpoints []*endpoint) []*endpoint {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	slices.SortStableFunc(endpoints, func(a, b *endpoint) int {
+		switch {
+		case a.trust.isTrusted() == b.trust.isTrusted():
+			return 0
+		case a.trust.isTrusted():
+			return -1
+		default:
+			return 1
+		}
+	})
+	return endpoints
+}
+
+// allowing filters the specified endpoints by
+// whether they allow all specified methods.
+func allowing(endpoints []*endpoint, methods []string) []*endpoint {
+	allowed := endpoints[:0]
+	for _, ep := range endpoints {
+		if slices.ContainsFunc(methods, func(method string) bool { return !ep.policy.Allows(method) }) {
+			continue
+		}
+		allowed = append(allowed, ep)
+	}
+	return allowed
+}
+
+// onSuccess resets the failure count
+// of the specified endpoint.
+func (p *Pool) onSuccess(ep *endpoint) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	ep.failures = 0
+}
+
+// onFailure records a failed call of the
+// specified endpoint, marking it unhealthy
+// after too many consecutive failures.
+func (p *Pool) onFailure(ep *endpoint, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	ep.failures++
+	p.log.Debug("call to endpoint failed", "url", ep.url, "failures", ep.failures, "err", err)
+
+	if ep.healthy && ep.failures >= maxFailures {
+		ep.healthy = false
+		p.log.Warn("endpoint unhealthy, fail over", "url", ep.url, "err", err)
+	}
+}
+
+// checkHealth checks the health of all
+// endpoints by requesting the chain ID.
+func (p *Pool) checkHealth(ctx context.Context) {
+	for _, ep := range p.dialedEndpoints() {
+		checkCtx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+		var id string
+		err := ep.c.CallContext(checkCtx, &id, "eth_chainId")
+		cancel()
+
+		p.mu.Lock()
+		switch {
+		case err == nil && !ep.healthy:
+			p.log.Info("endpoint healthy again", "url", ep.url)
+			ep.healthy = true
+			ep.failures = 0
+		case err != nil && ep.healthy:
+			p.log.Warn("endpoint failed health check", "url", ep.url, "err", err)
+			ep.healthy = false
+		}
+		p.mu.Unlock()
+	}
+}
+
+// isTransportError checks whether the specified
+// error indicates a failure of the provider, as
+// opposed to an error response to a valid call.
+func isTransportError(err error) bool {
+	var httpErr rpc.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode == http.StatusTooManyRequests || httpErr.StatusCode >= http.StatusInternalServerError
+	}
+
+	var rpcErr rpc.Error
+	return !errors.As(err, &rpcErr)
+}
+
+// budget is a token bucket limiting the
+// rate of calls sent to an endpoint.
+type budget struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	clock  mclock.Clock
+	last   mclock.AbsTime
+}
+
+// newBudget creates a new budget allowing the
+// specified number of calls per second, with
+// bursts of up to one second, but at least one
+// call, so that rates below one call per second
+// are reachable. A non-positive rate yields an
+// unlimited budget.
+func newBudget(rate float64, clock mclock.Clock) *budget {
+	if rate <= 0 {
+		return nil
+	}
+
+	burst := max(rate, 1)
+	return &budget{
+		rate:   rate,
+		burst:  burst,
+		tokens: burst,
+		clock:  clock,
+		last:   clock.Now(),
+	}
+}
+
+// take consumes one call from the budget. If
+// the budget is exhausted, nothing is consumed,
+// and the duration until the next call becomes
+// available is returned.
+func (b *budget) take() time.Duration {
+	if b == nil {
+		return 0
+	}
+
+	b.refill()
+	if b.tokens < 1 {
+		return time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+	}
+
+	b.tokens--
+	return 0
+}
+
+// usage returns the share of the budget
+// currently used, or zero if unlimited.
+func (b *budget) usage() float64 {
+	if b == nil {
+		return 0
+	}
+
+	b.refill()
+	return 1 - b.tokens/b.burst
+}
+
+// refill adds the calls that became
+// available since the last refill.
+func (b *budget) refill() {
+	now := b.clock.Now()
+	b.tokens = min(b.burst, b.tokens+time.Duration(now-b.last).Seconds()*b.rate)
+	b.last = now
+}
@@ -0,0 +1,300 @@
+package ethclient
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sparseth/internal/log"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/mclock"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+type testService struct {
+	name string
+}
+
+func (s *testService) Name() string {
+	return s.name
+}
+
+func (s *testService) Fail() error {
+	return errors.New("execution reverted")
+}
+
+func (s *testService) ChainId() string {
+	return "0x1"
+}
+
+func newTestEndpoint(t *testing.T, name string) string {
+	server := rpc.NewServer()
+	if err := server.RegisterName("test", &testService{name: name}); err != nil {
+		t.Fatalf("failed to register service: %v", err)
+	}
+	// Serves eth_chainId for health checks
+	if err := server.RegisterName("eth", &testService{name: name}); err != nil {
+		t.Fatalf("failed to register service: %v", err)
+	}
+
+	srv := httptest.NewServer(server)
+	t.Cleanup(func() {
+		srv.Close()
+		server.Stop()
+	})
+	return srv.URL
+}
+
+func newFailingEndpoint(t *testing.T, status int) string {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+func TestPool_CallContext(t *testing.T) {
+	testLogger := log.New(slog.DiscardHandler)
+
+	t.Run("should use first endpoint", func(t *testing.T) {
+		pool, err := DialPool(t.Context(), []*EndpointConfig{
+			{URL: newTestEndpoint(t, "first")},
+			{URL: newTestEndpoint(t, "second")},
+		}, nil, testLogger)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		defer pool.Close()
+
+		var name string
+		if err = pool.CallContext(t.Context(), &name, "test_name"); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if name != "first" {
+			t.Errorf("expected first, got %s", name)
+		}
+	})
+
+	t.Run("should fail over on server error", func(t *testing.T) {
+		pool, err := DialPool(t.Context(), []*EndpointConfig{
+			{URL: newFailingEndpoint(t, http.StatusServiceUnavailable)},
+			{URL: newTestEndpoint(t, "second")},
+		}, nil, testLogger)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		defer pool.Close()
+
+		var name string
+		if err = pool.CallContext(t.Context(), &name, "test_name"); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if name != "second" {
+			t.Errorf("expected second, got %s", name)
+		}
+	})
+
+	t.Run("should fail over on rate limit", func(t *testing.T) {
+		pool, err := DialPool(t.Context(), []*EndpointConfig{
+			{URL: newFailingEndpoint(t, http.StatusTooManyRequests)},
+			{URL: newTestEndpoint(t, "second")},
+		}, nil, testLogger)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		defer pool.Close()
+
+		var name string
+		if err = pool.CallContext(t.Context(), &name, "test_name"); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if name != "second" {
+			t.Errorf("expected second, got %s", name)
+		}
+	})
+
+	t.Run("should not fail over on error response", func(t *testing.T) {
+		pool, err := DialPool(t.Context(), []*EndpointConfig{
+			{URL: newTestEndpoint(t, "first")},
+			{URL: newTestEndpoint(t, "second")},
+		}, nil, testLogger)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		defer pool.Close()
+
+		err = pool.CallContext(t.Context(), nil, "test_fail")
+		var rpcErr rpc.Error
+		if !errors.As(err, &rpcErr) {
+			t.Fatalf("expected rpc error, got %v", err)
+		}
+	})
+
+	t.Run("should mark endpoint unhealthy after repeated failures", func(t *testing.T) {
+		pool, err := DialPool(t.Context(), []*EndpointConfig{
+			{URL: newFailingEndpoint(t, http.StatusInternalServerError)},
+			{URL: newTestEndpoint(t, "second")},
+		}, nil, testLogger)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		defer pool.Close()
+
+		for range maxFailures {
+			if err = pool.CallContext(t.Context(), nil, "test_name"); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+		}
+
+		if pool.endpoints[0].healthy {
+			t.Errorf("expected first endpoint to be unhealthy")
+		}
+	})
+
+	t.Run("should use next endpoint if budget is exhausted", func(t *testing.T) {
+		pool, err := DialPool(t.Context(), []*EndpointConfig{
+			{URL: newTestEndpoint(t, "first"), Rate: 1},
+			{URL: newTestEndpoint(t, "second")},
+		}, nil, testLogger)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		defer pool.Close()
+
+		var first, second string
+		if err = pool.CallContext(t.Context(), &first, "test_name"); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if err = pool.CallContext(t.Context(), &second, "test_name"); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if first != "first" || second != "second" {
+			t.Errorf("expected first and second, got %s and %s", first, second)
+		}
+	})
+
+	t.Run("should wait for budget below one call per second", func(t *testing.T) {
+		clock := &mclock.Simulated{}
+		pool, err := DialPool(t.Context(), []*EndpointConfig{
+			{URL: newTestEndpoint(t, "first"), Rate: 0.5},
+		}, clock, testLogger)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		defer pool.Close()
+
+		var name string
+		if err = pool.CallContext(t.Context(), &name, "test_name"); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		done := make(chan error, 1)
+		go func() {
+			done <- pool.CallContext(t.Context(), &name, "test_name")
+		}()
+		clock.WaitForTimers(1)
+		clock.Run(2 * time.Second)
+
+		select {
+		case err = <-done:
+			if err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Errorf("expected call to complete once budget is available")
+		}
+	})
+}
+
+func TestPool_RunContext(t *testing.T) {
+	testLogger := log.New(slog.DiscardHandler)
+
+	t.Run("should restore endpoint once health check passes", func(t *testing.T) {
+		clock := &mclock.Simulated{}
+		pool, err := DialPool(t.Context(), []*EndpointConfig{
+			{URL: newTestEndpoint(t, "first")},
+		}, clock, testLogger)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		defer pool.Close()
+
+		pool.mu.Lock()
+		pool.endpoints[0].healthy = false
+		pool.mu.Unlock()
+
+		go pool.RunContext(t.Context())
+		clock.WaitForTimers(1)
+		clock.Run(healthCheckInterval)
+		// Re-armed once the check completed
+		clock.WaitForTimers(1)
+
+		pool.mu.Lock()
+		defer pool.mu.Unlock()
+		if !pool.endpoints[0].healthy {
+			t.Errorf("expected endpoint to be healthy")
+		}
+	})
+}
+
+func TestBudget_Take(t *testing.T) {
+	t.Run("should allow unlimited calls without rate", func(t *testing.T) {
+		b := newBudget(0, mclock.System{})
+		for range 100 {
+			if d := b.take(); d != 0 {
+				t.Fatalf("expected no wait, got %v", d)
+			}
+		}
+	})
+
+	t.Run("should return wait if exhausted", func(t *testing.T) {
+		b := newBudget(2, mclock.System{})
+		b.take()
+		b.take()
+
+		d := b.take()
+		if d <= 0 || d > 500*time.Millisecond {
+			t.Errorf("expected wait in (0, 500ms], got %v", d)
+		}
+	})
+
+	t.Run("should allow calls below one per second", func(t *testing.T) {
+		clock := &mclock.Simulated{}
+		b := newBudget(0.5, clock)
+		if d := b.take(); d != 0 {
+			t.Fatalf("expected no wait, got %v", d)
+		}
+
+		d := b.take()
+		if d != 2*time.Second {
+			t.Fatalf("expected wait of 2s, got %v", d)
+		}
+		clock.Run(d)
+		if d = b.take(); d != 0 {
+			t.Errorf("expected no wait, got %v", d)
+		}
+	})
+}
+
+func TestBudget_Usage(t *testing.T) {
+	t.Run("should report no usage without rate", func(t *testing.T) {
+		b := newBudget(0, mclock.System{})
+		if u := b.usage(); u != 0 {
+			t.Errorf("expected 0, got %v", u)
+		}
+	})
+
+	t.Run("should report share of used calls", func(t *testing.T) {
+		b := newBudget(1000, mclock.System{})
+		for range 500 {
+			b.take()
+		}
+
+		u := b.usage()
+		if u < 0.4 || u > 0.5 {
+			t.Errorf("expected usage in [0.4, 0.5], got %v", u)
+		}
+	})
+}
@@ -0,0 +1,111 @@
+package ethclient
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/lru"
+)
+
+// proofCacheSize is the maximum number of
+// verified accounts and storage slots kept
+// in the proof cache, each.
+const proofCacheSize = 16384
+
+// accountKey identifies an account
+// at a specific block.
+type accountKey struct {
+	block common.Hash
+	addr  common.Address
+}
+
+// slotKey identifies a storage slot of
+// an account at a specific block.
+type slotKey struct {
+	block common.Hash
+	addr  common.Address
+	slot  common.Hash
+}
+
+// proofCache caches verified accounts and storage
+// slots by block hash, so that repeated lookups,
+// e.g., while preparing and verifying the same
+// block, do not issue duplicate proof requests.
+//
+// Verified values never change for a given block
+// hash, so entries are only evicted once the
+// cache is full.
+type proofCache struct {
+	accounts *lru.Cache[accountKey, *Account]
+	slots    *lru.Cache[slotKey, []byte]
+}
+
+// newProofCache creates a new, empty proof cache.
+func newProofCache() *proofCache {
+	return &proofCache{
+		accounts: lru.NewCache[accountKey, *Account](proofCacheSize),
+		slots:    lru.NewCache[slotKey, []byte](proofCacheSize),
+	}
+}
+
+// account returns the cached account at the
+// specified block, which is nil if the account
+// does not exist, or false if not cached.
+func (c *proofCache) account(block common.Hash, addr common.Address) (*Account, bool) {
+	return c.accounts.Get(accountKey{block, addr})
+}
+
+// addAccount caches the specified verified
+// account at the specified block, where nil
+// denotes a non-existent account.
+func (c *proofCache) addAccount(block common.Hash, addr common.Address, acc *Account) {
+	c.accounts.Add(accountKey{block, addr}, acc)
+}
+
+// slot returns the cached value of the specified
+// storage slot at the specified block, or false
+// if not cached.
+func (c *proofCache) slot(block common.Hash, addr common.Address, slot common.Hash) ([]byte, bool) {
+	return c.slots.Get(slotKey{block, addr, slot})
+}
+
+// addSlot caches the specified verified value of
+// the specified storage slot at the specified block.
+func (c *proofCache) addSlot(block common.Hash, addr common.Address, slot common.Hash, val []byte) {
+	c.slots.Add(slotKey{block, addr, slot}, val)
+}
+
+// state returns the cached account and storage
+// slots of the specified request at the specified
+// block, or false if any of them is not cached.
+func (c *proofCache) state(block common.Hash, req *ProofRequest) (*AccountState, bool) {
+	acc, ok := c.account(block, req.Address)
+	if !ok {
+		return nil, false
+	}
+
+	state := &AccountState{
+		Account: acc,
+		Storage: make(map[common.Hash][]byte, len(req.Slots)),
+	}
+	if acc == nil {
+		// Slots of non-existent
+		// accounts are omitted
+		return state, true
+	}
+	for _, slot := range req.Slots {
+		val, ok := c.slot(block, req.Address, slot)
+		if !ok {
+			return nil, false
+		}
+		state.Storage[slot] = val
+	}
+	return state, true
+}
+
+// addState caches the specified verified account
+// state at the specified block.
+func (c *proofCache) addState(block common.Hash, addr common.Address, state *AccountState) {
+	c.addAccount(block, addr, state.Account)
+	for slot, val := range state.Storage {
+		c.addSlot(block, addr, slot, val)
+	}
+}
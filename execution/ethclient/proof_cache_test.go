@@ -0,0 +1,72 @@
+package ethclient
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestProofCache_State(t *testing.T) {
+	block := common.HexToHash("0x01")
+	addr := common.HexToAddress("0xaa")
+	slot := common.HexToHash("0x02")
+
+	t.Run("should miss if account is not cached", func(t *testing.T) {
+		cache := newProofCache()
+
+		if _, ok := cache.state(block, &ProofRequest{Address: addr}); ok {
+			t.Errorf("expected cache miss")
+		}
+	})
+
+	t.Run("should miss if slot is not cached", func(t *testing.T) {
+		cache := newProofCache()
+		cache.addAccount(block, addr, &Account{Nonce: 1})
+
+		if _, ok := cache.state(block, &ProofRequest{Address: addr, Slots: []common.Hash{slot}}); ok {
+			t.Errorf("expected cache miss")
+		}
+	})
+
+	t.Run("should miss for other block", func(t *testing.T) {
+		cache := newProofCache()
+		cache.addState(block, addr, &AccountState{Account: &Account{Nonce: 1}})
+
+		if _, ok := cache.state(common.HexToHash("0x03"), &ProofRequest{Address: addr}); ok {
+			t.Errorf("expected cache miss")
+		}
+	})
+
+	t.Run("should return cached state", func(t *testing.T) {
+		cache := newProofCache()
+		cache.addState(block, addr, &AccountState{
+			Account: &Account{Nonce: 1},
+			Storage: map[common.Hash][]byte{slot: {0x2a}},
+		})
+
+		state, ok := cache.state(block, &ProofRequest{Address: addr, Slots: []common.Hash{slot}})
+		if !ok {
+			t.Fatalf("expected cache hit")
+		}
+		if state.Account.Nonce != 1 {
+			t.Errorf("expected nonce 1, got %d", state.Account.Nonce)
+		}
+		if !bytes.Equal(state.Storage[slot], []byte{0x2a}) {
+			t.Errorf("expected slot value 0x2a, got %x", state.Storage[slot])
+		}
+	})
+
+	t.Run("should return cached non-existent account", func(t *testing.T) {
+		cache := newProofCache()
+		cache.addAccount(block, addr, nil)
+
+		state, ok := cache.state(block, &ProofRequest{Address: addr, Slots: []common.Hash{slot}})
+		if !ok {
+			t.Fatalf("expected cache hit")
+		}
+		if state.Account != nil {
+			t.Errorf("expected no account, got %v", state.Account)
+		}
+	})
+}
@@ -16,15 +16,33 @@ type Provider interface {
 	// are indexed by their position in the block.
 	GetTxsAtBlock(ctx context.Context, header *types.Header) ([]*TransactionWithIndex, error)
 
+	// GetWithdrawalsAtBlock retrieves all withdrawals at
+	// the specified block, in order of their index. This
+	// list is guaranteed to be complete and valid.
+	GetWithdrawalsAtBlock(ctx context.Context, header *types.Header) ([]*types.Withdrawal, error)
+
 	// GetLogsAtBlock retrieves the logs for the specified
-	// Ethereum account at the specified block.
-	GetLogsAtBlock(ctx context.Context, acc common.Address, blockNum *big.Int) ([]*types.Log, error)
+	// Ethereum account at the specified block. If topics
+	// are specified, only logs whose first topic, i.e.,
+	// event ID, is any of them are retrieved.
+	GetLogsAtBlock(ctx context.Context, acc common.Address, blockNum *big.Int, topics []common.Hash) ([]*types.Log, error)
+
+	// GetLogsInRange retrieves the logs for the specified
+	// Ethereum account in the specified inclusive range
+	// of blocks, filtered by topics as GetLogsAtBlock.
+	GetLogsInRange(ctx context.Context, acc common.Address, from, to *big.Int, topics []common.Hash) ([]*types.Log, error)
 
 	// GetAccountAtBlock provides the verified account
 	// at the specified block, or nil if no such account
 	// exists.
 	GetAccountAtBlock(ctx context.Context, acc common.Address, head *types.Header) (*Account, error)
 
+	// GetAccountsAtBlock provides the verified accounts and
+	// storage slots of all specified requests at the
+	// specified block, in order of the requests. Proofs
+	// are requested in as few round trips as possible.
+	GetAccountsAtBlock(ctx context.Context, reqs []*ProofRequest, head *types.Header) ([]*AccountState, error)
+
 	// GetStorageAtBlock provides the verified value stored at
 	// the specified storage slot for the specified Ethereum
 	// account at the specified block.
@@ -50,4 +68,12 @@ type Provider interface {
 	// Note that the returned trace is not verified, and hence
 	// may not be complete or valid.
 	GetTransactionTrace(ctx context.Context, txHash common.Hash) (*TransactionTrace, error)
+
+	// GetBlockTraces retrieves the transaction traces with
+	// a pre-state tracer for all transactions in the
+	// specified block, in order of their index.
+	//
+	// Note that the returned traces are not verified, and
+	// hence may not be complete or valid.
+	GetBlockTraces(ctx context.Context, header *types.Header) ([]*TransactionTrace, error)
 }
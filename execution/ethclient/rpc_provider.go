@@ -34,10 +34,25 @@ func (p *RpcProvider) GetTxsAtBlock(ctx context.Context, header *types.Header) (
 	return p.tx.getTxsAtBlock(ctx, header)
 }
 
+// GetWithdrawalsAtBlock retrieves all withdrawals at
+// the specified block, in order of their index. This
+// list is guaranteed to be complete and valid.
+func (p *RpcProvider) GetWithdrawalsAtBlock(ctx context.Context, header *types.Header) ([]*types.Withdrawal, error) {
+	return p.tx.getWithdrawalsAtBlock(ctx, header)
+}
+
 // GetLogsAtBlock retrieves the logs for the specified
-// Ethereum account at the specified block.
-func (p *RpcProvider) GetLogsAtBlock(ctx context.Context, acc common.Address, blockNum *big.Int) ([]*types.Log, error) {
-	return p.log.getLogsAtBlock(ctx, acc, blockNum)
+// Ethereum account at the specified block, with any
+// of the specified topics, if set.
+func (p *RpcProvider) GetLogsAtBlock(ctx context.Context, acc common.Address, blockNum *big.Int, topics []common.Hash) ([]*types.Log, error) {
+	return p.log.getLogsAtBlock(ctx, acc, blockNum, topics)
+}
+
+// GetLogsInRange retrieves the logs for the specified
+// Ethereum account in the specified inclusive range
+// of blocks, with any of the specified topics, if set.
+func (p *RpcProvider) GetLogsInRange(ctx context.Context, acc common.Address, from, to *big.Int, topics []common.Hash) ([]*types.Log, error) {
+	return p.log.getLogsInRange(ctx, acc, from, to, topics)
 }
 
 // GetAccountAtBlock provides the verified account
@@ -47,6 +62,13 @@ func (p *RpcProvider) GetAccountAtBlock(ctx context.Context, acc common.Address,
 	return p.acc.getAccountAtBlock(ctx, acc, head)
 }
 
+// GetAccountsAtBlock provides the verified accounts and
+// storage slots of all specified requests at the
+// specified block, in order of the requests.
+func (p *RpcProvider) GetAccountsAtBlock(ctx context.Context, reqs []*ProofRequest, head *types.Header) ([]*AccountState, error) {
+	return p.acc.getAccountsAtBlock(ctx, reqs, head)
+}
+
 // GetStorageAtBlock provides the verified value stored at
 // the specified storage slot for the specified Ethereum
 // account at the specified block.
@@ -75,3 +97,10 @@ func (p *RpcProvider) GetCodeAtBlock(ctx context.Context, acc common.Address, he
 func (p *RpcProvider) GetTransactionTrace(ctx context.Context, txHash common.Hash) (*TransactionTrace, error) {
 	return p.tx.getTransactionTrace(ctx, txHash)
 }
+
+// GetBlockTraces retrieves the transaction traces with
+// a pre-state tracer for all transactions in the
+// specified block, in order of their index.
+func (p *RpcProvider) GetBlockTraces(ctx context.Context, header *types.Header) ([]*TransactionTrace, error) {
+	return p.tx.getBlockTraces(ctx, header)
+}
@@ -0,0 +1,134 @@
+package ethclient
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+)
+
+const (
+	// trustDecay is the weight of a single
+	// observation in the trust score of an
+	// endpoint, i.e., the score is a moving
+	// average over roughly the most recent
+	// 1/trustDecay observations.
+	trustDecay = 0.02
+	// minTrustScore is the trust score below
+	// which an endpoint is only used after all
+	// trusted endpoints.
+	minTrustScore = 0.9
+)
+
+var (
+	// ErrDivergence is returned if data claimed
+	// by a provider, e.g., traces or logs, does
+	// not match the proven state.
+	ErrDivergence = errors.New("provider data diverges from proven state")
+)
+
+// Trust describes how often the data claimed by
+// an endpoint matched the proven state.
+type Trust struct {
+	// Score is a moving average of the share of
+	// blocks for which the data claimed by the
+	// endpoint matched the proven state, from
+	// zero (never) to one (always).
+	Score float64
+	// Agreements is the number of blocks for
+	// which the claimed data matched.
+	Agreements uint64
+	// Divergences is the number of blocks for
+	// which the claimed data did not match.
+	Divergences uint64
+}
+
+// newTrust creates a new Trust of an
+// endpoint without any observations.
+func newTrust() *Trust {
+	return &Trust{Score: 1}
+}
+
+// observe records whether the data claimed by
+// the endpoint for a block matched the proven
+// state.
+func (t *Trust) observe(diverged bool) {
+	outcome := 1.0
+	if diverged {
+		outcome = 0
+		t.Divergences++
+	} else {
+		t.Agreements++
+	}
+	t.Score += trustDecay * (outcome - t.Score)
+}
+
+// isTrusted checks whether the
+// trust score is sufficient.
+func (t *Trust) isTrusted() bool {
+	return t.Score >= minTrustScore
+}
+
+// servedByKey is the context key
+// of the ServedBy recorders.
+type servedByKey struct{}
+
+// ServedBy records the URLs of all endpoints
+// that served calls made with a context, see
+// WithServedBy, e.g., to attribute the data
+// of a block to its providers.
+type ServedBy struct {
+	urls []string
+	mu   sync.Mutex
+}
+
+// WithServedBy returns a copy of the specified
+// context that records the URLs of all endpoints
+// serving calls in the specified recorder, in
+// addition to all recorders of the parent context.
+func WithServedBy(ctx context.Context, s *ServedBy) context.Context {
+	parents, _ := ctx.Value(servedByKey{}).([]*ServedBy)
+	return context.WithValue(ctx, servedByKey{}, append(slices.Clone(parents), s))
+}
+
+// RecordServedBy records the specified URLs in
+// all recorders of the specified context, e.g.,
+// for data that was downloaded ahead.
+func RecordServedBy(ctx context.Context, urls ...string) {
+	recorders, _ := ctx.Value(servedByKey{}).([]*ServedBy)
+	for _, s := range recorders {
+		s.Record(urls...)
+	}
+}
+
+// Record records the specified URLs.
+// A nil recorder records nothing.
+func (s *ServedBy) Record(urls ...string) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, url := range urls {
+		if !slices.Contains(s.urls, url) {
+			s.urls = append(s.urls, url)
+		}
+	}
+}
+
+// URLs returns all recorded URLs in
+// sorted order.
+func (s *ServedBy) URLs() []string {
+	if s == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	urls := slices.Clone(s.urls)
+	slices.Sort(urls)
+	return urls
+}
@@ -0,0 +1,77 @@
+package ethclient
+
+import (
+	"log/slog"
+	"slices"
+	"sparseth/internal/log"
+	"testing"
+)
+
+func TestTrust_Observe(t *testing.T) {
+	t.Run("should lose trust on repeated divergences", func(t *testing.T) {
+		trust := newTrust()
+		for range 10 {
+			trust.observe(true)
+		}
+
+		if trust.isTrusted() || trust.Divergences != 10 {
+			t.Errorf("expected untrusted after 10 divergences, got %+v", trust)
+		}
+	})
+
+	t.Run("should regain trust on agreements", func(t *testing.T) {
+		trust := &Trust{Score: 0.5}
+		for range 100 {
+			trust.observe(false)
+		}
+
+		if !trust.isTrusted() || trust.Agreements != 100 {
+			t.Errorf("expected trusted after 100 agreements, got %+v", trust)
+		}
+	})
+}
+
+func TestPool_Observe(t *testing.T) {
+	testLogger := log.New(slog.DiscardHandler)
+
+	t.Run("should record serving endpoint", func(t *testing.T) {
+		first := newTestEndpoint(t, "first")
+		pool, err := DialPool(t.Context(), []*EndpointConfig{{URL: first}}, nil, testLogger)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		defer pool.Close()
+
+		served := new(ServedBy)
+		if err = pool.CallContext(WithServedBy(t.Context(), served), nil, "test_name"); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if !slices.Equal(served.URLs(), []string{first}) {
+			t.Errorf("expected %s, got %v", first, served.URLs())
+		}
+	})
+
+	t.Run("should prefer trusted endpoint", func(t *testing.T) {
+		first := newTestEndpoint(t, "first")
+		pool, err := DialPool(t.Context(), []*EndpointConfig{
+			{URL: first},
+			{URL: newTestEndpoint(t, "second")},
+		}, nil, testLogger)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		defer pool.Close()
+
+		for range 10 {
+			pool.Observe([]string{first}, true)
+		}
+
+		var name string
+		if err = pool.CallContext(t.Context(), &name, "test_name"); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if name != "second" {
+			t.Errorf("expected second, got %s", name)
+		}
+	})
+}
@@ -48,6 +48,30 @@ func (p *txProvider) getTxsAtBlock(ctx context.Context, header *types.Header) ([
 	return indexedTxs, err
 }
 
+// getWithdrawalsAtBlock retrieves and verifies
+// all withdrawals at the specified block.
+//
+// Blocks without withdrawals are not requested,
+// as the header commits to the empty list.
+func (p *txProvider) getWithdrawalsAtBlock(ctx context.Context, header *types.Header) ([]*types.Withdrawal, error) {
+	if header.WithdrawalsHash == nil || *header.WithdrawalsHash == types.EmptyWithdrawalsHash {
+		return nil, nil
+	}
+
+	withdrawals, err := p.c.GetWithdrawalsAtBlock(ctx, header.Number)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get withdrawals: %w", err)
+	}
+
+	// Verify completeness and integrity of the withdrawals
+	root := types.DeriveSha(types.Withdrawals(withdrawals), trie.NewStackTrie(nil))
+	if root != *header.WithdrawalsHash {
+		return nil, fmt.Errorf("withdrawals hash does not match block hash")
+	}
+
+	return withdrawals, nil
+}
+
 // getTransactionTrace retrieves the transaction trace
 // with a pre-state tracer for the specified transaction
 // hash.
@@ -57,3 +81,10 @@ func (p *txProvider) getTxsAtBlock(ctx context.Context, header *types.Header) ([
 func (p *txProvider) getTransactionTrace(ctx context.Context, txHash common.Hash) (*TransactionTrace, error) {
 	return p.c.GetTransactionTrace(ctx, txHash)
 }
+
+// getBlockTraces retrieves the transaction traces
+// with a pre-state tracer for all transactions in
+// the specified block.
+func (p *txProvider) getBlockTraces(ctx context.Context, header *types.Header) ([]*TransactionTrace, error) {
+	return p.c.GetBlockTraces(ctx, header.Number)
+}
@@ -0,0 +1,76 @@
+package ethclient
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+type testBlockService struct {
+	withdrawals types.Withdrawals
+	calls       int
+}
+
+func (s *testBlockService) GetBlockByNumber(_ string, _ bool) (map[string]any, error) {
+	s.calls++
+	return map[string]any{"withdrawals": s.withdrawals}, nil
+}
+
+func newTestBlockProvider(t *testing.T, svc *testBlockService) *txProvider {
+	server := rpc.NewServer()
+	if err := server.RegisterName("eth", svc); err != nil {
+		t.Fatalf("failed to register service: %v", err)
+	}
+	t.Cleanup(server.Stop)
+
+	return newTxProvider(NewClient(rpc.DialInProc(server)))
+}
+
+func TestTxProvider_GetWithdrawalsAtBlock(t *testing.T) {
+	withdrawals := types.Withdrawals{
+		{Index: 1, Validator: 7, Address: common.HexToAddress("0x01"), Amount: 100},
+		{Index: 2, Validator: 8, Address: common.HexToAddress("0x02"), Amount: 200},
+	}
+	root := types.DeriveSha(withdrawals, trie.NewStackTrie(nil))
+
+	t.Run("should return verified withdrawals", func(t *testing.T) {
+		p := newTestBlockProvider(t, &testBlockService{withdrawals: withdrawals})
+		header := &types.Header{Number: big.NewInt(1), WithdrawalsHash: &root}
+
+		got, err := p.getWithdrawalsAtBlock(t.Context(), header)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(got) != 2 || got[0].Address != withdrawals[0].Address || got[1].Amount != 200 {
+			t.Errorf("expected withdrawals of block, got %v", got)
+		}
+	})
+
+	t.Run("should return error if withdrawals do not match header", func(t *testing.T) {
+		p := newTestBlockProvider(t, &testBlockService{withdrawals: withdrawals[:1]})
+		header := &types.Header{Number: big.NewInt(1), WithdrawalsHash: &root}
+
+		if _, err := p.getWithdrawalsAtBlock(t.Context(), header); err == nil {
+			t.Errorf("expected error, got nil")
+		}
+	})
+
+	t.Run("should not request empty withdrawals", func(t *testing.T) {
+		svc := &testBlockService{}
+		p := newTestBlockProvider(t, svc)
+		empty := types.EmptyWithdrawalsHash
+		header := &types.Header{Number: big.NewInt(1), WithdrawalsHash: &empty}
+
+		got, err := p.getWithdrawalsAtBlock(t.Context(), header)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(got) != 0 || svc.calls != 0 {
+			t.Errorf("expected no withdrawals and no calls, got %d withdrawals and %d calls", len(got), svc.calls)
+		}
+	})
+}
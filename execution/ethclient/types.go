@@ -19,10 +19,26 @@ type Account struct {
 	StorageRoot common.Hash
 }
 
+// AccountState represents an Ethereum account
+// along with the values of requested storage
+// slots, all verified against the state root.
+type AccountState struct {
+	// Account is the verified account, or
+	// nil if no such account exists.
+	Account *Account
+	// Storage holds the verified values
+	// of the requested storage slots.
+	Storage map[common.Hash][]byte
+}
+
 // TransactionTrace represents a transaction trace
 // that contains all accounts touched during the
 // transaction execution.
 type TransactionTrace struct {
+	// TxHash is the hash of the traced transaction,
+	// or the zero hash if not reported by the
+	// provider.
+	TxHash   common.Hash
 	Accounts []*AccountTrace
 }
 
@@ -2,26 +2,104 @@ package execution
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"math/big"
+	"slices"
+	"sparseth/bus"
+	"sparseth/ethstore"
 	"sparseth/log"
+	"sparseth/storage"
+	"sync/atomic"
 )
 
+// HeaderSource provides block headers
+// by number and by hash.
+type HeaderSource interface {
+	// HeaderByNumber retrieves the block
+	// header with the specified number.
+	HeaderByNumber(ctx context.Context, blockNum *big.Int) (*types.Header, error)
+	// HeaderByHash retrieves the block
+	// header with the specified hash.
+	HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error)
+}
+
+// Confirmations specifies when a block
+// is considered safe to be processed.
+type Confirmations struct {
+	// Depth is the number of blocks that must be
+	// built on top of a block before it is safe.
+	Depth uint64
+	// Finalized indicates that a block is only
+	// safe once finalized, Depth is ignored.
+	Finalized bool
+	// Delay is the number of blocks behind the
+	// head at which blocks are processed, even
+	// if they are safe already, e.g., as the
+	// provider serves inconsistent state for
+	// the newest blocks.
+	Delay uint64
+}
+
 // Listener subscribes to new block headers
 // and dispatches them as they arrive.
+//
+// Block headers are dispatched contiguously: if
+// a block header does not link to the previous
+// one, e.g., as block numbers are skipped, its
+// missing ancestors are backfilled from the
+// header source before it is dispatched.
+//
+// Block headers are held back until they are
+// confirmed. Held back headers replaced by a
+// reorg are dropped without being dispatched.
 type Listener struct {
-	sub        <-chan *types.Header
-	dispatcher *Dispatcher
-	log        log.Logger
+	sub   <-chan *types.Header
+	src   HeaderSource
+	store *ethstore.HeaderStore
+	heads *bus.Topic[*types.Header]
+	// upcoming receives the held back block
+	// header that is dispatched next.
+	upcoming *bus.Topic[*types.Header]
+	conf     Confirmations
+	log      log.Logger
+	// last is the last received
+	// block header, if any.
+	last *types.Header
+	// pending holds all received but not yet
+	// confirmed block headers in order.
+	pending []*types.Header
+	// announced is the last block header
+	// published to the upcoming topic.
+	announced common.Hash
+	// published is the number of the last block
+	// header published to the heads topic.
+	published atomic.Uint64
+	// head is the last received block
+	// header, see Head.
+	head atomic.Pointer[types.Header]
 }
 
 // NewListener creates a new block Listener that
 // listens for block headers from the specified
-// channel.
-func NewListener(ch <-chan *types.Header, dispatcher *Dispatcher, log log.Logger) *Listener {
+// channel. Missing block headers are fetched
+// from the specified source, and stored in the
+// specified key-val store. Block headers are
+// published to the specified heads topic once
+// confirmed, and to the specified upcoming topic
+// shortly before, e.g., to prefetch block data.
+func NewListener(ch <-chan *types.Header, src HeaderSource, db storage.KeyValStore, heads, upcoming *bus.Topic[*types.Header], conf Confirmations, log log.Logger) *Listener {
 	return &Listener{
-		sub:        ch,
-		dispatcher: dispatcher,
-		log:        log.With("component", "block-listener"),
+		sub:      ch,
+		src:      src,
+		store:    ethstore.NewHeaderStore(db),
+		heads:    heads,
+		upcoming: upcoming,
+		conf:     conf,
+		log:      log.With("component", "block-listener"),
 	}
 }
 
@@ -32,12 +110,178 @@ func (l *Listener) RunContext(ctx context.Context) error {
 
 	for {
 		select {
-		case head := <-l.sub:
+		case head, ok := <-l.sub:
+			if !ok {
+				l.log.Info("block header channel closed")
+				return nil
+			}
 			l.log.Info("received new block head", "hash", head.Hash())
-			l.dispatcher.Broadcast(head)
+			if err := l.handle(ctx, head); err != nil {
+				l.log.Error("failed to handle block head", "num", head.Number, "hash", head.Hash().Hex(), "err", err)
+				return err
+			}
 		case <-ctx.Done():
 			l.log.Info("stop listening for block headers")
 			return nil
 		}
 	}
 }
+
+// Published returns the number of the last block
+// header published to the heads topic, or zero if
+// none was published yet.
+//
+// Published may be called concurrently.
+func (l *Listener) Published() uint64 {
+	return l.published.Load()
+}
+
+// Head returns the last received block header,
+// which may not be confirmed yet, or nil if none
+// was received yet.
+//
+// Head may be called concurrently.
+func (l *Listener) Head() *types.Header {
+	return l.head.Load()
+}
+
+// handle stores and dispatches the specified block
+// header, after backfilling all missing ancestors.
+// Duplicates of the last dispatched block header
+// are dropped.
+func (l *Listener) handle(ctx context.Context, head *types.Header) error {
+	if l.last != nil && head.Hash() == l.last.Hash() {
+		l.log.Debug("drop duplicate block head", "hash", head.Hash())
+		return nil
+	}
+
+	if l.last != nil && head.ParentHash != l.last.Hash() {
+		if err := l.backfill(ctx, head); err != nil {
+			return fmt.Errorf("failed to backfill: %w", err)
+		}
+	}
+
+	if err := l.store.Put(head); err != nil {
+		return fmt.Errorf("failed to store header at block %d: %w", head.Number.Uint64(), err)
+	}
+	l.receive(head)
+	l.dispatchConfirmed(ctx, head)
+	return nil
+}
+
+// backfill fetches, stores and dispatches all
+// ancestors of the specified block header after
+// its latest stored ancestor, in order.
+//
+// Ancestors are fetched by walking back the
+// parent hashes, each ancestor must link to
+// the next one.
+func (l *Listener) backfill(ctx context.Context, head *types.Header) error {
+	var missed []*types.Header
+	for child := head; ; {
+		_, err := l.store.GetByHash(child.ParentHash)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, ethstore.ErrHeaderNotFound) {
+			return fmt.Errorf("failed to get parent of block %d: %w", child.Number.Uint64(), err)
+		}
+		if child.Number.Sign() == 0 {
+			return fmt.Errorf("header at block %d has no stored ancestor", head.Number.Uint64())
+		}
+
+		parent, err := l.src.HeaderByHash(ctx, child.ParentHash)
+		if err != nil {
+			return fmt.Errorf("failed to fetch parent of block %d: %w", child.Number.Uint64(), err)
+		}
+		if parent.Hash() != child.ParentHash || parent.Number.Uint64()+1 != child.Number.Uint64() {
+			return fmt.Errorf("header at block %d does not link to its parent", child.Number.Uint64())
+		}
+		missed = append(missed, parent)
+		child = parent
+	}
+	if len(missed) == 0 {
+		return nil
+	}
+
+	slices.Reverse(missed)
+	l.log.Warn("missing ancestors of block header detected, backfill", "from", missed[0].Number, "to", missed[len(missed)-1].Number)
+	for _, ancestor := range missed {
+		if err := l.store.Put(ancestor); err != nil {
+			return fmt.Errorf("failed to store header at block %d: %w", ancestor.Number.Uint64(), err)
+		}
+		l.receive(ancestor)
+	}
+	return nil
+}
+
+// receive holds back the specified block header
+// until it is confirmed. Held back headers that
+// are replaced by the specified header, as they
+// have the same or a higher number, are dropped.
+func (l *Listener) receive(head *types.Header) {
+	for len(l.pending) > 0 && l.pending[len(l.pending)-1].Number.Cmp(head.Number) >= 0 {
+		dropped := l.pending[len(l.pending)-1]
+		l.log.Info("drop unconfirmed block head replaced by reorg", "num", dropped.Number, "hash", dropped.Hash())
+		l.pending = l.pending[:len(l.pending)-1]
+	}
+
+	l.pending = append(l.pending, head)
+	l.last = head
+	l.head.Store(head)
+}
+
+// dispatchConfirmed publishes all held back block
+// headers that are confirmed by the specified head.
+func (l *Listener) dispatchConfirmed(ctx context.Context, head *types.Header) {
+	safe, ok := l.safeNumber(ctx, head)
+	if !ok {
+		return
+	}
+
+	for len(l.pending) > 0 && l.pending[0].Number.Uint64() <= safe {
+		l.published.Store(l.pending[0].Number.Uint64())
+		l.heads.Publish(l.pending[0])
+		l.pending = l.pending[1:]
+	}
+
+	if len(l.pending) > 0 && l.pending[0].Hash() != l.announced {
+		l.announced = l.pending[0].Hash()
+		l.upcoming.Publish(l.pending[0])
+	}
+}
+
+// safeNumber returns the number of the latest block
+// that is confirmed by the specified head, and not
+// within the processing delay, or false if there
+// is no such block.
+func (l *Listener) safeNumber(ctx context.Context, head *types.Header) (uint64, bool) {
+	safe, ok := l.confirmedNumber(ctx, head)
+	if !ok || l.conf.Delay == 0 {
+		return safe, ok
+	}
+
+	if head.Number.Uint64() < l.conf.Delay {
+		return 0, false
+	}
+	return min(safe, head.Number.Uint64()-l.conf.Delay), true
+}
+
+// confirmedNumber returns the number of the latest
+// block that is confirmed by the specified head, or
+// false if no block is confirmed.
+func (l *Listener) confirmedNumber(ctx context.Context, head *types.Header) (uint64, bool) {
+	if l.conf.Finalized {
+		finalized, err := l.src.HeaderByNumber(ctx, big.NewInt(int64(rpc.FinalizedBlockNumber)))
+		if err != nil {
+			l.log.Warn("failed to fetch finalized block, hold back block heads", "err", err)
+			return 0, false
+		}
+		return finalized.Number.Uint64(), true
+	}
+
+	if head.Number.Uint64() < l.conf.Depth {
+		return 0, false
+	}
+	return head.Number.Uint64() - l.conf.Depth, true
+}
@@ -0,0 +1,289 @@
+package execution
+
+import (
+	"context"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"log/slog"
+	"math/big"
+	"sparseth/bus"
+	"sparseth/internal/log"
+	"sparseth/storage/mem"
+	"testing"
+	"time"
+)
+
+type testHeaderSource struct {
+	headers map[uint64]*types.Header
+	// forged are served instead of
+	// the headers with their hash.
+	forged map[common.Hash]*types.Header
+}
+
+func (s *testHeaderSource) HeaderByNumber(_ context.Context, blockNum *big.Int) (*types.Header, error) {
+	head, exists := s.headers[blockNum.Uint64()]
+	if !exists {
+		return nil, fmt.Errorf("block %s not found", blockNum)
+	}
+	return head, nil
+}
+
+func (s *testHeaderSource) HeaderByHash(_ context.Context, hash common.Hash) (*types.Header, error) {
+	if head, exists := s.forged[hash]; exists {
+		return head, nil
+	}
+	for _, head := range s.headers {
+		if head.Hash() == hash {
+			return head, nil
+		}
+	}
+	return nil, fmt.Errorf("block %s not found", hash.Hex())
+}
+
+// newTestChain creates a chain of linked
+// headers with the specified length.
+func newTestChain(length int) []*types.Header {
+	chain := make([]*types.Header, length)
+	for i := range chain {
+		chain[i] = &types.Header{Number: big.NewInt(int64(i))}
+		if i > 0 {
+			chain[i].ParentHash = chain[i-1].Hash()
+		}
+	}
+	return chain
+}
+
+func TestListener_RunContext(t *testing.T) {
+	testLogger := log.New(slog.DiscardHandler)
+
+	t.Run("should backfill skipped headers", func(t *testing.T) {
+		chain := newTestChain(5)
+		src := &testHeaderSource{headers: map[uint64]*types.Header{2: chain[2], 3: chain[3]}}
+
+		db := mem.New()
+		defer db.Close()
+
+		heads := bus.NewTopic[*types.Header]("headers", testLogger)
+		sub := heads.Subscribe("test")
+
+		ch := make(chan *types.Header, 2)
+		ch <- chain[1]
+		ch <- chain[4]
+
+		l := NewListener(ch, src, db, heads, nil, Confirmations{}, testLogger)
+		go l.RunContext(t.Context())
+
+		for _, expected := range chain[1:] {
+			select {
+			case head := <-sub:
+				if head.Hash() != expected.Hash() {
+					t.Fatalf("expected header %d, got %d", expected.Number, head.Number)
+				}
+			case <-time.After(time.Second):
+				t.Fatalf("timeout: did not receive header %d", expected.Number)
+			}
+		}
+	})
+
+	t.Run("should drop duplicate headers", func(t *testing.T) {
+		chain := newTestChain(3)
+
+		db := mem.New()
+		defer db.Close()
+
+		heads := bus.NewTopic[*types.Header]("headers", testLogger)
+		sub := heads.Subscribe("test")
+
+		ch := make(chan *types.Header, 3)
+		ch <- chain[1]
+		ch <- chain[1]
+		ch <- chain[2]
+
+		l := NewListener(ch, &testHeaderSource{}, db, heads, nil, Confirmations{}, testLogger)
+		go l.RunContext(t.Context())
+
+		for _, expected := range chain[1:] {
+			select {
+			case head := <-sub:
+				if head.Hash() != expected.Hash() {
+					t.Fatalf("expected header %d, got %d", expected.Number, head.Number)
+				}
+			case <-time.After(time.Second):
+				t.Fatalf("timeout: did not receive header %d", expected.Number)
+			}
+		}
+	})
+
+	t.Run("should fail if skipped header cannot be fetched", func(t *testing.T) {
+		chain := newTestChain(4)
+
+		db := mem.New()
+		defer db.Close()
+
+		ch := make(chan *types.Header, 2)
+		ch <- chain[1]
+		ch <- chain[3]
+
+		l := NewListener(ch, &testHeaderSource{}, db, bus.NewTopic[*types.Header]("headers", testLogger), nil, Confirmations{}, testLogger)
+		if err := l.RunContext(t.Context()); err == nil {
+			t.Errorf("expected error, got nil")
+		}
+	})
+
+	t.Run("should fail if skipped headers do not link", func(t *testing.T) {
+		chain := newTestChain(4)
+		forged := &types.Header{Number: big.NewInt(2), ParentHash: chain[1].Hash(), Extra: []byte("forged")}
+		src := &testHeaderSource{forged: map[common.Hash]*types.Header{chain[2].Hash(): forged}}
+
+		db := mem.New()
+		defer db.Close()
+
+		heads := bus.NewTopic[*types.Header]("headers", testLogger)
+		sub := heads.Subscribe("test")
+
+		ch := make(chan *types.Header, 2)
+		ch <- chain[1]
+		ch <- chain[3]
+
+		l := NewListener(ch, src, db, heads, nil, Confirmations{}, testLogger)
+		if err := l.RunContext(t.Context()); err == nil {
+			t.Errorf("expected error, got nil")
+		}
+
+		<-sub
+		select {
+		case head := <-sub:
+			t.Errorf("expected no header after %d, got %d", chain[1].Number, head.Number)
+		default:
+		}
+	})
+
+	t.Run("should backfill missing ancestors of reorged header", func(t *testing.T) {
+		chain := newTestChain(3)
+		forked := &types.Header{Number: big.NewInt(2), ParentHash: chain[1].Hash(), Extra: []byte("forked")}
+		next := &types.Header{Number: big.NewInt(3), ParentHash: forked.Hash()}
+		src := &testHeaderSource{headers: map[uint64]*types.Header{2: forked}}
+
+		db := mem.New()
+		defer db.Close()
+
+		heads := bus.NewTopic[*types.Header]("headers", testLogger)
+		sub := heads.Subscribe("test")
+
+		ch := make(chan *types.Header, 3)
+		ch <- chain[1]
+		ch <- chain[2]
+		ch <- next
+
+		l := NewListener(ch, src, db, heads, nil, Confirmations{}, testLogger)
+		go l.RunContext(t.Context())
+
+		for _, expected := range []*types.Header{chain[1], chain[2], forked, next} {
+			select {
+			case head := <-sub:
+				if head.Hash() != expected.Hash() {
+					t.Fatalf("expected header %s, got %s", expected.Hash(), head.Hash())
+				}
+			case <-time.After(time.Second):
+				t.Fatalf("timeout: did not receive header %d", expected.Number)
+			}
+		}
+	})
+
+	t.Run("should hold back unconfirmed headers", func(t *testing.T) {
+		chain := newTestChain(5)
+
+		db := mem.New()
+		defer db.Close()
+
+		heads := bus.NewTopic[*types.Header]("headers", testLogger)
+		sub := heads.Subscribe("test")
+
+		ch := make(chan *types.Header, 4)
+		for _, head := range chain[1:] {
+			ch <- head
+		}
+
+		l := NewListener(ch, &testHeaderSource{}, db, heads, nil, Confirmations{Depth: 2}, testLogger)
+		go l.RunContext(t.Context())
+
+		for _, expected := range chain[1:3] {
+			select {
+			case head := <-sub:
+				if head.Hash() != expected.Hash() {
+					t.Fatalf("expected header %d, got %d", expected.Number, head.Number)
+				}
+			case <-time.After(time.Second):
+				t.Fatalf("timeout: did not receive header %d", expected.Number)
+			}
+		}
+
+		select {
+		case head := <-sub:
+			t.Errorf("expected no header, got %d", head.Number)
+		case <-time.After(50 * time.Millisecond):
+		}
+	})
+
+	t.Run("should drop unconfirmed headers replaced by reorg", func(t *testing.T) {
+		chain := newTestChain(4)
+		reorged := &types.Header{Number: big.NewInt(2), ParentHash: chain[1].Hash(), Extra: []byte("reorged")}
+
+		db := mem.New()
+		defer db.Close()
+
+		heads := bus.NewTopic[*types.Header]("headers", testLogger)
+		sub := heads.Subscribe("test")
+
+		ch := make(chan *types.Header, 4)
+		ch <- chain[1]
+		ch <- reorged
+		ch <- chain[2]
+		ch <- chain[3]
+
+		l := NewListener(ch, &testHeaderSource{}, db, heads, nil, Confirmations{Depth: 1}, testLogger)
+		go l.RunContext(t.Context())
+
+		for _, expected := range chain[1:3] {
+			select {
+			case head := <-sub:
+				if head.Hash() != expected.Hash() {
+					t.Fatalf("expected header %s, got %s", expected.Hash(), head.Hash())
+				}
+			case <-time.After(time.Second):
+				t.Fatalf("timeout: did not receive header %d", expected.Number)
+			}
+		}
+	})
+
+	t.Run("should announce next header within delay", func(t *testing.T) {
+		chain := newTestChain(5)
+
+		db := mem.New()
+		defer db.Close()
+
+		heads := bus.NewTopic[*types.Header]("headers", testLogger)
+		upcoming := bus.NewTopic[*types.Header]("upcoming", testLogger)
+		sub := upcoming.Subscribe("test")
+
+		ch := make(chan *types.Header, 4)
+		for _, head := range chain[1:] {
+			ch <- head
+		}
+
+		l := NewListener(ch, &testHeaderSource{}, db, heads, upcoming, Confirmations{Delay: 2}, testLogger)
+		go l.RunContext(t.Context())
+
+		for _, expected := range chain[1:4] {
+			select {
+			case head := <-sub:
+				if head.Hash() != expected.Hash() {
+					t.Fatalf("expected header %d, got %d", expected.Number, head.Number)
+				}
+			case <-time.After(time.Second):
+				t.Fatalf("timeout: did not receive header %d", expected.Number)
+			}
+		}
+	})
+}
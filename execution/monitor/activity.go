@@ -0,0 +1,55 @@
+package monitor
+
+import (
+	"context"
+	"github.com/ethereum/go-ethereum/common"
+	"maps"
+	"sync"
+)
+
+// activityKey is the context key
+// of the Activity recorder.
+type activityKey struct{}
+
+// Activity records the verified events or
+// re-executed transactions per account of a
+// block processed with a context, see
+// WithActivity.
+type Activity struct {
+	counts map[common.Address]uint64
+	mu     sync.Mutex
+}
+
+// WithActivity returns a copy of the specified
+// context that records the activity of accounts
+// in the specified recorder.
+func WithActivity(ctx context.Context, a *Activity) context.Context {
+	return context.WithValue(ctx, activityKey{}, a)
+}
+
+// RecordActivity adds the specified count to the
+// activity of the specified account, if the
+// specified context has a recorder.
+func RecordActivity(ctx context.Context, addr common.Address, count uint64) {
+	a, _ := ctx.Value(activityKey{}).(*Activity)
+	if a == nil || count == 0 {
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.counts == nil {
+		a.counts = make(map[common.Address]uint64)
+	}
+	a.counts[addr] += count
+}
+
+// Counts returns the recorded activity
+// per account, or nil if none.
+func (a *Activity) Counts() map[common.Address]uint64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return maps.Clone(a.counts)
+}
@@ -0,0 +1,76 @@
+package monitor
+
+import (
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"sparseth/bus"
+)
+
+// MismatchError is returned if a value derived
+// by a monitor differs from the value proven
+// on-chain, e.g., the balance of an account.
+type MismatchError struct {
+	// Kind names the mismatched
+	// value, e.g., balance.
+	Kind string
+	// Expected is the proven value.
+	Expected string
+	// Actual is the derived value.
+	Actual string
+}
+
+// Error implements the error interface.
+func (e *MismatchError) Error() string {
+	return fmt.Sprintf("%s mismatch: expected: %s, got: %s", e.Kind, e.Expected, e.Actual)
+}
+
+// NewAlert returns an alert raised by the specified
+// source for the specified account at the specified
+// block. If err is a MismatchError, the alert holds
+// the mismatched values.
+func NewAlert(source string, addr common.Address, head *types.Header, err error) *bus.Alert {
+	alert := &bus.Alert{
+		Source:  source,
+		Account: addr,
+		Number:  head.Number.Uint64(),
+		Hash:    head.Hash(),
+		Message: err.Error(),
+	}
+
+	var mismatch *MismatchError
+	if errors.As(err, &mismatch) {
+		alert.Kind = mismatch.Kind
+		alert.Expected = mismatch.Expected
+		alert.Actual = mismatch.Actual
+	}
+	return alert
+}
+
+// NewVerification returns the outcome of the specified
+// kind of verifying the specified account at the
+// specified block by the specified source, where err
+// is nil for VerificationOK. If err is a MismatchError,
+// the outcome holds the mismatched values.
+func NewVerification(kind bus.VerificationKind, source string, addr common.Address, head *types.Header, err error) *bus.Verification {
+	v := &bus.Verification{
+		Kind:    kind,
+		Source:  source,
+		Account: addr,
+		Number:  head.Number.Uint64(),
+		Hash:    head.Hash(),
+	}
+	if err == nil {
+		return v
+	}
+
+	v.Message = err.Error()
+	var mismatch *MismatchError
+	if errors.As(err, &mismatch) {
+		v.Value = mismatch.Kind
+		v.Expected = mismatch.Expected
+		v.Actual = mismatch.Actual
+	}
+	return v
+}
@@ -0,0 +1,63 @@
+package monitor
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sparseth/bus"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestNewAlert(t *testing.T) {
+	addr := common.HexToAddress("0xaa")
+	head := &types.Header{Number: big.NewInt(10)}
+
+	t.Run("should hold values of wrapped mismatch", func(t *testing.T) {
+		err := fmt.Errorf("state verification failed: %w", &MismatchError{Kind: "nonce", Expected: "1", Actual: "2"})
+
+		alert := NewAlert("test", addr, head, err)
+		if alert.Kind != "nonce" || alert.Expected != "1" || alert.Actual != "2" {
+			t.Errorf("expected nonce mismatch, got %+v", alert)
+		}
+		if alert.Number != 10 || alert.Hash != head.Hash() {
+			t.Errorf("expected block 10 with hash %s, got %d with %s", head.Hash().Hex(), alert.Number, alert.Hash.Hex())
+		}
+		if alert.Message != err.Error() {
+			t.Errorf("expected message %q, got %q", err.Error(), alert.Message)
+		}
+	})
+
+	t.Run("should leave values empty for other errors", func(t *testing.T) {
+		alert := NewAlert("test", addr, head, errors.New("budget exceeded"))
+		if alert.Kind != "" || alert.Expected != "" || alert.Actual != "" {
+			t.Errorf("expected no mismatch, got %+v", alert)
+		}
+	})
+}
+
+func TestNewVerification(t *testing.T) {
+	addr := common.HexToAddress("0xaa")
+	head := &types.Header{Number: big.NewInt(10)}
+
+	t.Run("should hold values of wrapped mismatch", func(t *testing.T) {
+		err := fmt.Errorf("state verification failed: %w", &MismatchError{Kind: "balance", Expected: "1", Actual: "2"})
+
+		v := NewVerification(bus.StateMismatch, "test", addr, head, err)
+		if !v.Failed() || v.Value != "balance" || v.Expected != "1" || v.Actual != "2" {
+			t.Errorf("expected balance mismatch, got %+v", v)
+		}
+		if v.Message != err.Error() || v.Account != addr || v.Number != 10 {
+			t.Errorf("expected mismatch of %s at block 10, got %+v", addr.Hex(), v)
+		}
+	})
+
+	t.Run("should have no message if verified", func(t *testing.T) {
+		v := NewVerification(bus.VerificationOK, "test", addr, head, nil)
+		if v.Failed() || v.Message != "" {
+			t.Errorf("expected verified account, got %+v", v)
+		}
+	})
+}
@@ -0,0 +1,217 @@
+package monitor
+
+import (
+	"context"
+	"slices"
+	"sparseth/bus"
+	"sparseth/log"
+	"sync"
+)
+
+// Barrier collects the results of all registered
+// monitors per block, and commits a block only once
+// all monitors have processed it. Committed blocks
+// are published in order, so consumers get a
+// consistent view across all accounts per block.
+//
+// A monitor that has not processed any block yet
+// holds back all blocks until it processes its
+// first block. Blocks before that are not expected
+// from the monitor.
+type Barrier struct {
+	results <-chan *bus.Result
+	commits *bus.Topic[*bus.BlockCommit]
+	log     log.Logger
+	// progress holds the number of the last block
+	// processed by each registered monitor, or nil
+	// if the monitor has not processed any block.
+	progress map[string]*uint64
+	// first holds the number of the first block
+	// processed by each registered monitor, so
+	// that earlier blocks do not expect it.
+	first map[string]uint64
+	// pending holds all blocks not yet
+	// committed, in order of arrival.
+	pending []*bus.BlockCommit
+	// latest is the last committed
+	// block, if any.
+	latest *bus.BlockCommit
+	// check is called on each complete block
+	// before it is committed, see SetCheck.
+	check func(*bus.BlockCommit) []*bus.Result
+	mu    sync.Mutex
+}
+
+// NewBarrier creates a new Barrier that collects
+// results from the specified channel and publishes
+// committed blocks to the specified topic.
+func NewBarrier(results <-chan *bus.Result, commits *bus.Topic[*bus.BlockCommit], log log.Logger) *Barrier {
+	return &Barrier{
+		results:  results,
+		commits:  commits,
+		log:      log.With("component", "barrier"),
+		progress: make(map[string]*uint64),
+		first:    make(map[string]uint64),
+	}
+}
+
+// Register adds the monitor with the specified
+// name to the set of monitors that must process
+// a block before it is committed.
+func (b *Barrier) Register(name string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, exists := b.progress[name]; !exists {
+		b.progress[name] = nil
+	}
+}
+
+// Deregister removes the monitor with the
+// specified name, blocks are no longer held
+// back for the monitor.
+func (b *Barrier) Deregister(name string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.progress, name)
+	delete(b.first, name)
+	b.flush()
+}
+
+// SetCheck sets the function that is called on
+// each complete block before it is committed. The
+// results it returns are added to the block, e.g.,
+// to veto the block. The check must be set before
+// the barrier is started.
+func (b *Barrier) SetCheck(check func(*bus.BlockCommit) []*bus.Result) {
+	b.check = check
+}
+
+// Latest returns the last
+// committed block, if any.
+func (b *Barrier) Latest() *bus.BlockCommit {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.latest
+}
+
+// Progress returns the number of the last block
+// processed by each registered monitor, by name.
+// Monitors that have not processed any block yet
+// are omitted.
+func (b *Barrier) Progress() map[string]uint64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	progress := make(map[string]uint64, len(b.progress))
+	for name, num := range b.progress {
+		if num != nil {
+			progress[name] = *num
+		}
+	}
+	return progress
+}
+
+// RunContext collects results until the
+// context is canceled.
+func (b *Barrier) RunContext(ctx context.Context) error {
+	for {
+		select {
+		case r, ok := <-b.results:
+			if !ok {
+				return nil
+			}
+			b.record(r)
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// record adds the specified result to its
+// block, and commits all complete blocks.
+func (b *Barrier) record(r *bus.Result) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, registered := b.progress[r.Monitor]; !registered {
+		b.log.Debug("drop result of unregistered monitor", "monitor", r.Monitor, "num", r.Number)
+		return
+	}
+
+	num := r.Number
+	if b.progress[r.Monitor] == nil {
+		b.first[r.Monitor] = num
+	}
+	b.progress[r.Monitor] = &num
+
+	var commit *bus.BlockCommit
+	for _, c := range b.pending {
+		if c.Hash == r.Hash {
+			commit = c
+			break
+		}
+	}
+	if commit == nil {
+		commit = &bus.BlockCommit{
+			Number: r.Number,
+			Hash:   r.Hash,
+		}
+		b.pending = append(b.pending, commit)
+	}
+	commit.Results = append(commit.Results, r)
+
+	b.flush()
+}
+
+// flush commits all pending blocks in order,
+// until the first incomplete block.
+//
+// Note that the caller must hold the lock.
+func (b *Barrier) flush() {
+	for len(b.pending) > 0 && b.isComplete(b.pending[0]) {
+		commit := b.pending[0]
+		b.pending = b.pending[1:]
+		commit.Monitors = b.expected(commit.Number)
+		if b.check != nil {
+			commit.Results = append(commit.Results, b.check(commit)...)
+		}
+		commit.Digest = CommitDigest(commit)
+		b.latest = commit
+
+		b.log.Debug("commit block", "num", commit.Number, "hash", commit.Hash.Hex(), "verified", commit.Verified(), "digest", commit.Digest.Hex())
+		b.commits.Publish(commit)
+	}
+}
+
+// expected returns the names of all registered
+// monitors that are expected to process the block
+// with the specified number, in order.
+//
+// Note that the caller must hold the lock.
+func (b *Barrier) expected(num uint64) []string {
+	names := make([]string, 0, len(b.first))
+	for name, first := range b.first {
+		if first <= num {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// isComplete checks whether all registered
+// monitors have processed the specified block,
+// or a later one.
+//
+// Note that the caller must hold the lock.
+func (b *Barrier) isComplete(commit *bus.BlockCommit) bool {
+	for _, num := range b.progress {
+		if num == nil || *num < commit.Number {
+			return false
+		}
+	}
+	return true
+}
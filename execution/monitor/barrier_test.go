@@ -0,0 +1,176 @@
+package monitor
+
+import (
+	"errors"
+	"log/slog"
+	"sparseth/bus"
+	"sparseth/internal/log"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func newTestBarrier(monitors ...string) (*Barrier, <-chan *bus.BlockCommit) {
+	testLogger := log.New(slog.DiscardHandler)
+
+	commits := bus.NewTopic[*bus.BlockCommit]("commits", testLogger)
+	sub := commits.Subscribe("test")
+
+	b := NewBarrier(nil, commits, testLogger)
+	for _, name := range monitors {
+		b.Register(name)
+	}
+	return b, sub
+}
+
+func testResult(monitor string, num uint64, err error) *bus.Result {
+	return &bus.Result{
+		Monitor: monitor,
+		Number:  num,
+		Hash:    common.BytesToHash([]byte{byte(num)}),
+		Err:     err,
+	}
+}
+
+func TestBarrier_Record(t *testing.T) {
+	t.Run("should commit once all monitors processed block", func(t *testing.T) {
+		b, sub := newTestBarrier("first", "second")
+
+		b.record(testResult("first", 1, nil))
+		if len(sub) != 0 {
+			t.Fatalf("expected no commit, got %d", len(sub))
+		}
+
+		b.record(testResult("second", 1, nil))
+		if len(sub) != 1 {
+			t.Fatalf("expected 1 commit, got %d", len(sub))
+		}
+
+		commit := <-sub
+		if commit.Number != 1 || len(commit.Results) != 2 || !commit.Verified() {
+			t.Errorf("expected verified block 1 with 2 results, got %+v", commit)
+		}
+	})
+
+	t.Run("should commit blocks in order", func(t *testing.T) {
+		b, sub := newTestBarrier("first", "second")
+
+		b.record(testResult("first", 1, nil))
+		b.record(testResult("first", 2, nil))
+		b.record(testResult("second", 1, nil))
+		b.record(testResult("second", 2, nil))
+
+		for _, num := range []uint64{1, 2} {
+			if commit := <-sub; commit.Number != num {
+				t.Errorf("expected block %d, got %d", num, commit.Number)
+			}
+		}
+	})
+
+	t.Run("should not be verified if any monitor failed", func(t *testing.T) {
+		b, sub := newTestBarrier("first", "second")
+
+		b.record(testResult("first", 1, nil))
+		b.record(testResult("second", 1, errors.New("mismatch")))
+
+		if commit := <-sub; commit.Verified() {
+			t.Errorf("expected unverified block")
+		}
+	})
+
+	t.Run("should not be verified if any monitor skipped block", func(t *testing.T) {
+		b, sub := newTestBarrier("first", "second")
+
+		b.record(testResult("first", 1, nil))
+		b.record(testResult("second", 1, nil))
+		<-sub
+
+		b.record(testResult("first", 2, nil))
+		b.record(testResult("second", 3, nil))
+		if commit := <-sub; commit.Number != 2 || commit.Verified() {
+			t.Errorf("expected unverified block 2, got %+v", commit)
+		}
+	})
+
+	t.Run("should not hold back blocks for late monitor", func(t *testing.T) {
+		b, sub := newTestBarrier("first")
+
+		b.record(testResult("first", 1, nil))
+		<-sub
+
+		b.record(testResult("first", 2, nil))
+		<-sub
+
+		b.Register("second")
+		b.record(testResult("first", 3, nil))
+		if len(sub) != 0 {
+			t.Fatalf("expected no commit before first result of new monitor, got %d", len(sub))
+		}
+
+		b.record(testResult("second", 3, nil))
+		if commit := <-sub; commit.Number != 3 || len(commit.Results) != 2 {
+			t.Errorf("expected block 3 with 2 results, got %+v", commit)
+		}
+	})
+
+	t.Run("should not expect late monitor for earlier blocks", func(t *testing.T) {
+		b, sub := newTestBarrier("first")
+
+		b.record(testResult("first", 1, nil))
+		<-sub
+
+		b.Register("second")
+		b.record(testResult("first", 2, nil))
+		b.record(testResult("second", 3, nil))
+		if commit := <-sub; commit.Number != 2 || !commit.Verified() {
+			t.Errorf("expected verified block 2, got %+v", commit)
+		}
+	})
+}
+
+func TestBarrier_SetCheck(t *testing.T) {
+	t.Run("should add check results before commit", func(t *testing.T) {
+		b, sub := newTestBarrier("first")
+		b.SetCheck(func(commit *bus.BlockCommit) []*bus.Result {
+			return []*bus.Result{testResult("check", commit.Number, errors.New("vetoed"))}
+		})
+
+		b.record(testResult("first", 1, nil))
+
+		commit := <-sub
+		if len(commit.Results) != 2 || commit.Verified() {
+			t.Errorf("expected unverified block with 2 results, got %+v", commit)
+		}
+	})
+}
+
+func TestBarrier_Deregister(t *testing.T) {
+	t.Run("should commit held back blocks", func(t *testing.T) {
+		b, sub := newTestBarrier("first", "second")
+
+		b.record(testResult("first", 1, nil))
+		b.Deregister("second")
+
+		if commit := <-sub; commit.Number != 1 {
+			t.Errorf("expected block 1, got %d", commit.Number)
+		}
+		if latest := b.Latest(); latest == nil || latest.Number != 1 {
+			t.Errorf("expected latest block 1, got %+v", latest)
+		}
+	})
+}
+
+func TestBarrier_Progress(t *testing.T) {
+	t.Run("should return last block of each monitor", func(t *testing.T) {
+		b, _ := newTestBarrier("first", "second", "third")
+
+		b.record(testResult("first", 1, nil))
+		b.record(testResult("first", 2, nil))
+		b.record(testResult("second", 1, nil))
+
+		progress := b.Progress()
+		if len(progress) != 2 || progress["first"] != 2 || progress["second"] != 1 {
+			t.Errorf("expected first at 2 and second at 1, got %v", progress)
+		}
+	})
+}
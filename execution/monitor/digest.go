@@ -0,0 +1,41 @@
+package monitor
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"sort"
+	"sparseth/bus"
+)
+
+// Digest derives a deterministic digest of the
+// verified outputs of a block, e.g., the state
+// root, so that two nodes with the same config
+// can cheaply confirm they agree on the block.
+func Digest(head *types.Header, outputs ...common.Hash) common.Hash {
+	data := make([][]byte, 0, len(outputs)+1)
+	data = append(data, head.Hash().Bytes())
+	for _, out := range outputs {
+		data = append(data, out.Bytes())
+	}
+	return crypto.Keccak256Hash(data...)
+}
+
+// CommitDigest derives a deterministic digest of
+// the specified block from the digests of all
+// monitors, in order of monitor name. Failed
+// monitors contribute a zero digest.
+func CommitDigest(commit *bus.BlockCommit) common.Hash {
+	results := make([]*bus.Result, len(commit.Results))
+	copy(results, commit.Results)
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Monitor < results[j].Monitor
+	})
+
+	data := make([][]byte, 0, 2*len(results)+1)
+	data = append(data, commit.Hash.Bytes())
+	for _, r := range results {
+		data = append(data, crypto.Keccak256([]byte(r.Monitor)), r.Digest.Bytes())
+	}
+	return crypto.Keccak256Hash(data...)
+}
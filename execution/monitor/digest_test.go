@@ -0,0 +1,31 @@
+package monitor
+
+import (
+	"sparseth/bus"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestCommitDigest(t *testing.T) {
+	first := &bus.Result{Monitor: "first", Digest: common.HexToHash("0x01")}
+	second := &bus.Result{Monitor: "second", Digest: common.HexToHash("0x02")}
+
+	t.Run("should not depend on order of results", func(t *testing.T) {
+		a := CommitDigest(&bus.BlockCommit{Results: []*bus.Result{first, second}})
+		b := CommitDigest(&bus.BlockCommit{Results: []*bus.Result{second, first}})
+		if a != b {
+			t.Errorf("expected equal digests, got %s and %s", a.Hex(), b.Hex())
+		}
+	})
+
+	t.Run("should differ if a monitor digest differs", func(t *testing.T) {
+		other := &bus.Result{Monitor: "second", Digest: common.HexToHash("0x03")}
+
+		a := CommitDigest(&bus.BlockCommit{Results: []*bus.Result{first, second}})
+		b := CommitDigest(&bus.BlockCommit{Results: []*bus.Result{first, other}})
+		if a == b {
+			t.Errorf("expected different digests, got %s", a.Hex())
+		}
+	})
+}
@@ -0,0 +1,107 @@
+package event
+
+import (
+	"fmt"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/core/types"
+	"reflect"
+)
+
+// Event is a verified log, decoded with
+// the ABI of the emitting contract.
+type Event struct {
+	// Name is the name of the event
+	// in the ABI, e.g., Transfer.
+	Name string
+	// Sig is the signature of the event, e.g.,
+	// Transfer(address,address,uint256).
+	Sig string
+	// Log is the raw log. If Removed is set,
+	// the log was part of a reorged block,
+	// and is retracted.
+	Log *types.Log
+	// Args holds the values of all event
+	// inputs by name. Indexed inputs of
+	// dynamic type hold their hash.
+	Args map[string]any
+
+	event *abi.Event
+}
+
+// Decode sets the fields of the specified struct
+// pointer to the event args, matching each input
+// by its name in camel case, e.g., the input
+// value sets the field Value. Inputs without a
+// matching field are skipped.
+func (e *Event) Decode(v any) error {
+	dst := reflect.ValueOf(v)
+	if dst.Kind() != reflect.Pointer || dst.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("cannot decode into %T, expected struct pointer", v)
+	}
+	dst = dst.Elem()
+
+	for _, input := range e.event.Inputs {
+		name := abi.ToCamelCase(input.Name)
+		field := dst.FieldByName(name)
+		if !field.IsValid() || !field.CanSet() {
+			continue
+		}
+		val := reflect.ValueOf(e.Args[input.Name])
+		if !val.IsValid() || !val.Type().AssignableTo(field.Type()) {
+			return fmt.Errorf("cannot assign input %s to field %s of type %s", input.Name, name, field.Type())
+		}
+		field.Set(val)
+	}
+	return nil
+}
+
+// Decode decodes the specified log with the
+// event of the ABI that emitted it.
+func (v *Verifier) Decode(log *types.Log) (*Event, error) {
+	event, err := v.eventOf(log)
+	if err != nil {
+		return nil, err
+	}
+
+	args := make(map[string]any, len(event.Inputs))
+	if err = event.Inputs.NonIndexed().UnpackIntoMap(args, log.Data); err != nil {
+		return nil, fmt.Errorf("failed to unpack log data: %w", err)
+	}
+	first := topicOffset(event)
+	if len(log.Topics) < first {
+		return nil, fmt.Errorf("log does not contain ID")
+	}
+	if err = abi.ParseTopicsIntoMap(args, indexedInputs(event), log.Topics[first:]); err != nil {
+		return nil, fmt.Errorf("failed to parse topics: %w", err)
+	}
+
+	return &Event{
+		Name:  event.Name,
+		Sig:   event.Sig,
+		Log:   log,
+		Args:  args,
+		event: event,
+	}, nil
+}
+
+// indexedInputs returns the indexed
+// inputs of the specified event.
+func indexedInputs(event *abi.Event) abi.Arguments {
+	var indexed abi.Arguments
+	for _, arg := range event.Inputs {
+		if arg.Indexed {
+			indexed = append(indexed, arg)
+		}
+	}
+	return indexed
+}
+
+// topicOffset returns the index of the first
+// indexed input in the topics of a log of the
+// specified event, i.e., 0 if it is anonymous.
+func topicOffset(event *abi.Event) int {
+	if event.Anonymous {
+		return 0
+	}
+	return 1
+}
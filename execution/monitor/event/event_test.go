@@ -0,0 +1,71 @@
+package event
+
+import (
+	"bytes"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"math/big"
+	"testing"
+)
+
+func TestVerifier_Decode(t *testing.T) {
+	transferABI, err := abi.JSON(bytes.NewReader([]byte(`[{"anonymous":false,"inputs":[{"indexed":true,"name":"from","type":"address"},{"indexed":true,"name":"to","type":"address"},{"indexed":false,"name":"value","type":"uint256"}],"name":"Transfer","type":"event"}]`)))
+	if err != nil {
+		t.Fatalf("failed to parse ABI: %v", err)
+	}
+	transfer := transferABI.Events["Transfer"]
+	from := common.HexToAddress("0xf39fd6e51aad88f6f4ce6ab8827279cfffb92266")
+	to := common.HexToAddress("0xa513e6e4b8f2a923d98304ec87f64353c4d5c853")
+
+	data, err := transfer.Inputs.NonIndexed().Pack(big.NewInt(42))
+	if err != nil {
+		t.Fatalf("failed to pack event: %v", err)
+	}
+	log := &types.Log{
+		Topics: []common.Hash{transfer.ID, common.BytesToHash(from.Bytes()), common.BytesToHash(to.Bytes())},
+		Data:   data,
+	}
+
+	t.Run("should decode args by name", func(t *testing.T) {
+		ev, err := NewLogVerifier(transferABI, common.Hash{}).Decode(log)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if ev.Name != "Transfer" {
+			t.Errorf("expected Transfer, got %s", ev.Name)
+		}
+		if ev.Args["from"] != from || ev.Args["to"] != to {
+			t.Errorf("expected from %s and to %s, got %v and %v", from.Hex(), to.Hex(), ev.Args["from"], ev.Args["to"])
+		}
+		if value, ok := ev.Args["value"].(*big.Int); !ok || value.Int64() != 42 {
+			t.Errorf("expected value 42, got %v", ev.Args["value"])
+		}
+	})
+
+	t.Run("should decode into struct", func(t *testing.T) {
+		ev, err := NewLogVerifier(transferABI, common.Hash{}).Decode(log)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		var decoded struct {
+			From  common.Address
+			To    common.Address
+			Value *big.Int
+		}
+		if err = ev.Decode(&decoded); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if decoded.From != from || decoded.To != to || decoded.Value.Int64() != 42 {
+			t.Errorf("unexpected decoded event %+v", decoded)
+		}
+	})
+
+	t.Run("should return error for unknown event", func(t *testing.T) {
+		unknown := &types.Log{Topics: []common.Hash{common.HexToHash("0x01")}}
+		if _, err := NewLogVerifier(transferABI, common.Hash{}).Decode(unknown); err == nil {
+			t.Errorf("expected error, got nil")
+		}
+	})
+}
@@ -2,14 +2,18 @@ package event
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"math"
+	"sparseth/bus"
 	"sparseth/ethstore"
 	"sparseth/execution/ethclient"
 	"sparseth/execution/monitor"
 	"sparseth/log"
 	"sparseth/storage"
+	"sync"
 )
 
 // LogProcessor downloads, verifies and
@@ -19,48 +23,377 @@ type LogProcessor struct {
 	acc      *monitor.AccountInfo
 	verifier *Verifier
 	store    *ethstore.EventStore
+	heads    *ethstore.ChainHeadStore
+	// restored indicates whether the verifier
+	// head was restored from the store.
+	restored bool
+	// last is the number of the
+	// last processed block.
+	last     uint64
 	provider ethclient.Provider
+	// topics are the IDs of all events of the
+	// contract ABI, or nil if it has anonymous
+	// events, which carry no ID.
+	topics []common.Hash
+	// window fetches logs over multiple blocks,
+	// or is nil if logs are fetched per block.
+	window *logWindow
+	// sample checks whether a block is verified,
+	// or is nil if all blocks are verified.
+	sample func(*types.Header) bool
+	// pending holds the logs of blocks that were
+	// not sampled, verified with the next sample.
+	pending []*types.Log
+	// alerts receives failed verifications.
+	alerts *bus.Topic[*bus.Alert]
+	// verifications receives the outcome
+	// of each verified block.
+	verifications *bus.Topic[*bus.Verification]
+	// events receives the decoded verified logs,
+	// or is nil if no one subscribed yet.
+	events   *bus.Topic[*Event]
+	eventsMu sync.Mutex
 }
 
 // NewLogProcessor creates a new LogProcessor
-// for the specified account.
-func NewLogProcessor(acc *monitor.AccountInfo, rpc *ethclient.Client, db storage.KeyValStore, log log.Logger) *LogProcessor {
-	store := ethstore.NewEventStore(db)
+// for the specified account, storing verified
+// logs with the specified encoding.
+//
+// The hash chain head verified after each block is
+// stored, and restored at the first processed block,
+// so that processing can resume after a restart. The
+// initial head of the account is only used if no head
+// is stored before that block. If a block is processed
+// again, e.g., after a reorg, the hash chain is rewound
+// to the block before it, and the logs of all later
+// blocks are removed from the store.
+//
+// If bound is not nil, logs of blocks queued for
+// processing are fetched over adaptive windows of
+// blocks, up to the block returned by bound.
+//
+// The outcome of each verification is published to the
+// specified verifications topic, and failed ones to the
+// specified alerts topic, either of which may be nil.
+//
+// If sample is not nil, the hash chain head is only
+// verified at blocks for which sample returns true.
+// The logs of other blocks are kept and verified,
+// and stored, along with the next sampled block.
+func NewLogProcessor(acc *monitor.AccountInfo, rpc *ethclient.Client, db storage.KeyValStore, enc ethstore.Encoding, bound func() uint64, sample func(*types.Header) bool, alerts *bus.Topic[*bus.Alert], verifications *bus.Topic[*bus.Verification], log log.Logger) *LogProcessor {
+	store := ethstore.NewEventStore(db, enc)
 	provider := ethclient.NewRpcProvider(rpc)
 	verifier := NewLogVerifier(acc.ABI, acc.InitialHead)
 
+	var topics []common.Hash
+	if len(AnonymousEvents(acc.ABI)) == 0 {
+		topics = make([]common.Hash, 0, len(acc.ABI.Events))
+		for _, event := range acc.ABI.Events {
+			topics = append(topics, event.ID)
+		}
+	}
+
+	var window *logWindow
+	if bound != nil {
+		window = newLogWindow(provider, acc.Addr, topics, bound)
+	}
+
 	return &LogProcessor{
-		log:      log.With("component", acc.Addr.Hex()+"-log-processor"),
-		acc:      acc,
-		store:    store,
-		provider: provider,
-		verifier: verifier,
+		log:           log.With("component", acc.Addr.Hex()+"-log-processor"),
+		acc:           acc,
+		store:         store,
+		heads:         ethstore.NewChainHeadStore(db),
+		provider:      provider,
+		verifier:      verifier,
+		topics:        topics,
+		window:        window,
+		sample:        sample,
+		alerts:        alerts,
+		verifications: verifications,
+	}
+}
+
+// Subscribe returns a channel receiving the decoded
+// logs of the account once verified, in order. If the
+// specified id is already subscribed, the existing
+// channel is returned.
+//
+// If a block is reorged, its logs are delivered again
+// with Log.Removed set. Logs are dropped for slow
+// subscribers, as for any bus.Topic.
+func (p *LogProcessor) Subscribe(id string) <-chan *Event {
+	p.eventsMu.Lock()
+	defer p.eventsMu.Unlock()
+
+	if p.events == nil {
+		p.events = bus.NewTopic[*Event](p.acc.Addr.Hex()+"-events", p.log)
+	}
+	return p.events.Subscribe(id)
+}
+
+// Unsubscribe removes the subscriber with the
+// specified id and closes its channel.
+func (p *LogProcessor) Unsubscribe(id string) {
+	p.eventsMu.Lock()
+	defer p.eventsMu.Unlock()
+
+	if p.events != nil {
+		p.events.Unsubscribe(id)
 	}
 }
 
 // ProcessBlock processes the specified block header.
-func (p *LogProcessor) ProcessBlock(ctx context.Context, head *types.Header) error {
+// Blocks whose logs bloom excludes the contract are
+// skipped without any RPC calls, unless logs of
+// unsampled blocks are pending verification, as
+// are blocks before the start block. The returned digest
+// covers the verified head of the hash chain, see
+// monitor.Digest.
+func (p *LogProcessor) ProcessBlock(ctx context.Context, head *types.Header) (common.Hash, error) {
+	if !p.restored || head.Number.Uint64() <= p.last {
+		if err := p.rewind(head.Number.Uint64()); err != nil {
+			return common.Hash{}, err
+		}
+	}
+	p.last = head.Number.Uint64()
+
+	if head.Number.Uint64() < p.acc.StartBlock {
+		p.log.Debug("block precedes start block, skip block", "num", head.Number, "hash", head.Hash().Hex())
+		return monitor.Digest(head, p.verifier.Head()), nil
+	}
+	sampled := p.sample == nil || p.sample(head)
+
+	// Pending logs are verified at the next sampled
+	// block, even if its bloom excludes the contract
+	if !bloomMatches(head.Bloom, p.acc.Addr, p.topics) && (!sampled || len(p.pending) == 0) {
+		p.log.Debug("logs bloom excludes contract, skip block", "num", head.Number, "hash", head.Hash().Hex())
+		return monitor.Digest(head, p.verifier.Head()), nil
+	}
+
 	p.log.Debug("download logs for block", "num", head.Number, "hash", head.Hash().Hex())
-	logs, err := p.provider.GetLogsAtBlock(ctx, p.acc.Addr, head.Number)
+	logs, err := p.logsAtBlock(ctx, head)
 	if err != nil {
-		return err
+		return common.Hash{}, err
+	}
+	for _, log := range logs {
+		// Logs of another branch, e.g., as the
+		// provider has not yet seen the block
+		if log.Removed || log.BlockHash != head.Hash() {
+			return common.Hash{}, fmt.Errorf("log %d of tx %s is not part of block %s", log.Index, log.TxHash.Hex(), head.Hash().Hex())
+		}
+	}
+
+	if !sampled {
+		p.log.Debug("block not sampled, defer verification", "num", head.Number, "hash", head.Hash().Hex(), "pending", len(p.pending)+len(logs))
+		p.pending = append(p.pending, logs...)
+		return monitor.Digest(head, p.verifier.Head()), nil
 	}
+	logs = append(p.pending, logs...)
+	// Logs of a failed sample are dropped, as
+	// are the logs of a failed block otherwise
+	p.pending = nil
 
 	expected, err := p.provider.GetStorageAtBlock(ctx, p.acc.Addr, p.acc.Slot, head)
 	if err != nil {
-		return fmt.Errorf("failed to read header value: %w", err)
+		return common.Hash{}, fmt.Errorf("failed to read header value: %w", err)
 	}
 
 	p.log.Debug("verify logs for block", "num", head.Number, "hash", head.Hash().Hex())
 	if err = p.verifier.VerifyLogs(logs, common.BytesToHash(expected)); err != nil {
-		return fmt.Errorf("failed to process logs: %w", err)
+		failed := fmt.Errorf("event verification failed: %w", err)
+		p.verifications.Publish(monitor.NewVerification(bus.EventChainMismatch, "log-processor", p.acc.Addr, head, failed))
+		p.alerts.Publish(monitor.NewAlert("log-processor", p.acc.Addr, head, failed))
+		return common.Hash{}, fmt.Errorf("failed to process logs: %w: %w", ethclient.ErrDivergence, err)
 	}
+	ok := monitor.NewVerification(bus.VerificationOK, "log-processor", p.acc.Addr, head, nil)
+	ok.Root = common.BytesToHash(expected)
+	p.verifications.Publish(ok)
 
 	p.log.Debug("store logs for block", "num", head.Number, "hash", head.Hash().Hex())
 	if err = p.store.PutAll(logs); err != nil {
-		return fmt.Errorf("failed to store logs: %w", err)
+		return common.Hash{}, fmt.Errorf("failed to store logs: %w", err)
 	}
+	ids := make([]*ethstore.LogID, len(logs))
+	for i, log := range logs {
+		ids[i] = &ethstore.LogID{
+			BlockNumber: log.BlockNumber,
+			TxHash:      log.TxHash,
+			Index:       uint64(log.Index),
+		}
+	}
+	err = p.heads.Put(p.acc.Addr, &ethstore.ChainHead{
+		Number:    head.Number.Uint64(),
+		BlockHash: head.Hash(),
+		Head:      p.verifier.Head(),
+		Logs:      ids,
+	})
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to store chain head: %w", err)
+	}
+	monitor.RecordActivity(ctx, p.acc.Addr, uint64(len(logs)))
+	p.publish(logs)
 
 	p.log.Debug("block processed", "num", head.Number, "hash", head.Hash().Hex())
+	return monitor.Digest(head, p.verifier.Head()), nil
+}
+
+// Replay returns the digest of the specified block,
+// which was processed before, from the stored hash
+// chain heads, without any RPC calls. It returns
+// false if the block must be processed again, i.e.,
+// if the stored head of the block belongs to another
+// block, or if logs of the block may have been
+// pending verification, as it was not sampled.
+func (p *LogProcessor) Replay(head *types.Header) (common.Hash, bool, error) {
+	num := head.Number.Uint64()
+	if p.sample != nil {
+		latest, err := p.heads.At(p.acc.Addr, math.MaxUint64)
+		if err != nil && !errors.Is(err, ethstore.ErrChainHeadNotFound) {
+			return common.Hash{}, false, fmt.Errorf("failed to get chain head: %w", err)
+		}
+		// Pending logs are lost on restart
+		if err != nil || num > latest.Number {
+			return common.Hash{}, false, nil
+		}
+	}
+
+	chainHead := p.acc.InitialHead
+	stored, err := p.heads.At(p.acc.Addr, num)
+	if err != nil && !errors.Is(err, ethstore.ErrChainHeadNotFound) {
+		return common.Hash{}, false, fmt.Errorf("failed to get chain head: %w", err)
+	}
+	if err == nil {
+		if stored.Number == num && stored.BlockHash != head.Hash() {
+			return common.Hash{}, false, nil
+		}
+		chainHead = stored.Head
+	}
+	return monitor.Digest(head, chainHead), true, nil
+}
+
+// publish decodes the specified logs and delivers
+// them to all subscribers, if any.
+func (p *LogProcessor) publish(logs []*types.Log) {
+	p.eventsMu.Lock()
+	events := p.events
+	p.eventsMu.Unlock()
+
+	if events == nil {
+		return
+	}
+	for _, log := range logs {
+		ev, err := p.verifier.Decode(log)
+		if err != nil {
+			// Verified logs are always decodable
+			p.log.Warn("failed to decode log", "tx", log.TxHash.Hex(), "index", log.Index, "err", err)
+			continue
+		}
+		events.Publish(ev)
+	}
+}
+
+// rewind resets the verifier to the hash chain head
+// stored before the specified block, such that the
+// block continues the verified hash chain. Stored
+// heads at or after the block are removed, as are
+// the logs verified with them, e.g., after a reorg.
+//
+// Logs of earlier blocks verified with a removed head,
+// i.e., logs of unsampled blocks, are pending again.
+func (p *LogProcessor) rewind(num uint64) error {
+	removed, err := p.heads.Truncate(p.acc.Addr, num)
+	if err != nil {
+		return fmt.Errorf("failed to rewind chain head: %w", err)
+	}
+
+	// Logs delivered before a reorg are retracted,
+	// but not those of a previous run
+	p.eventsMu.Lock()
+	retract := p.restored && p.events != nil
+	p.eventsMu.Unlock()
+
+	var pending, retracted []*types.Log
+	for _, batch := range removed {
+		for _, id := range batch.Logs {
+			if id.BlockNumber >= num && !retract {
+				continue
+			}
+			log, err := p.store.GetLog(id.TxHash, uint(id.Index))
+			if err != nil {
+				return fmt.Errorf("failed to restore log: %w", err)
+			}
+			// Not all encodings keep log metadata
+			log.BlockNumber, log.TxHash, log.Index = id.BlockNumber, id.TxHash, uint(id.Index)
+			if id.BlockNumber < num {
+				pending = append(pending, log)
+			}
+			if retract {
+				retraction := *log
+				retraction.Removed = true
+				retracted = append(retracted, &retraction)
+			}
+		}
+		if err = p.store.DeleteAll(batch.Logs); err != nil {
+			return fmt.Errorf("failed to remove logs: %w", err)
+		}
+	}
+	for _, log := range p.pending {
+		if log.BlockNumber < num {
+			pending = append(pending, log)
+		}
+	}
+	p.pending = pending
+	p.publish(retracted)
+
+	head := p.acc.InitialHead
+	if num > 0 {
+		stored, err := p.heads.At(p.acc.Addr, num-1)
+		if err != nil && !errors.Is(err, ethstore.ErrChainHeadNotFound) {
+			return fmt.Errorf("failed to restore chain head: %w", err)
+		}
+		if err == nil {
+			head = stored.Head
+		}
+	}
+
+	if p.restored && len(removed) > 0 {
+		p.log.Warn("block processed again, possible reorg, rewind chain head", "num", num, "removed", len(removed), "head", head.Hex())
+	} else {
+		p.log.Info("restore chain head", "num", num, "removed", len(removed), "head", head.Hex())
+	}
+	p.verifier = NewLogVerifier(p.acc.ABI, head)
+	p.restored = true
 	return nil
 }
+
+// logsAtBlock returns the logs of the
+// account at the specified block.
+func (p *LogProcessor) logsAtBlock(ctx context.Context, head *types.Header) ([]*types.Log, error) {
+	if p.window == nil {
+		return p.provider.GetLogsAtBlock(ctx, p.acc.Addr, head.Number, p.topics)
+	}
+	return p.window.logsAtBlock(ctx, head)
+}
+
+// bloomMatches checks whether the specified bloom
+// may contain a log of the specified address with
+// any of the specified event IDs. If no IDs are
+// specified, only the address is matched.
+//
+// Blooms have no false negatives, i.e., if no
+// match is found, no such log exists.
+func bloomMatches(bloom types.Bloom, addr common.Address, topics []common.Hash) bool {
+	if !types.BloomLookup(bloom, addr) {
+		return false
+	}
+	if len(topics) == 0 {
+		return true
+	}
+
+	for _, topic := range topics {
+		if types.BloomLookup(bloom, topic) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,417 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"log/slog"
+	"math/big"
+	"sparseth/ethstore"
+	"sparseth/execution/ethclient"
+	"sparseth/execution/monitor"
+	"sparseth/internal/log"
+	"sparseth/storage"
+	"sparseth/storage/mem"
+	"strings"
+	"testing"
+)
+
+func TestBloomMatches(t *testing.T) {
+	addr := common.HexToAddress("0xaa")
+	topic := common.HexToHash("0x01")
+
+	bloom := types.CreateBloom(&types.Receipt{
+		Logs: []*types.Log{{Address: addr, Topics: []common.Hash{topic}}},
+	})
+
+	t.Run("should match address and topic", func(t *testing.T) {
+		if !bloomMatches(bloom, addr, []common.Hash{common.HexToHash("0x02"), topic}) {
+			t.Errorf("expected match")
+		}
+	})
+
+	t.Run("should not match other address", func(t *testing.T) {
+		if bloomMatches(bloom, common.HexToAddress("0xbb"), []common.Hash{topic}) {
+			t.Errorf("expected no match")
+		}
+	})
+
+	t.Run("should not match other topic", func(t *testing.T) {
+		if bloomMatches(bloom, addr, []common.Hash{common.HexToHash("0x02")}) {
+			t.Errorf("expected no match")
+		}
+	})
+
+	t.Run("should match address without topics", func(t *testing.T) {
+		if !bloomMatches(bloom, addr, nil) {
+			t.Errorf("expected match")
+		}
+	})
+
+	t.Run("should not match empty bloom", func(t *testing.T) {
+		if bloomMatches(types.Bloom{}, addr, []common.Hash{topic}) {
+			t.Errorf("expected no match")
+		}
+	})
+}
+
+type sampleTestProvider struct {
+	ethclient.Provider
+	// logs holds the logs of each block
+	logs map[uint64][]*types.Log
+	// heads holds the hash chain
+	// head after each block
+	heads map[uint64]common.Hash
+	// proofs counts storage reads
+	proofs int
+	// topics holds the last topic filter
+	topics []common.Hash
+}
+
+func (p *sampleTestProvider) GetLogsAtBlock(_ context.Context, _ common.Address, blockNum *big.Int, topics []common.Hash) ([]*types.Log, error) {
+	p.topics = topics
+	return p.logs[blockNum.Uint64()], nil
+}
+
+func (p *sampleTestProvider) GetStorageAtBlock(_ context.Context, _ common.Address, _ common.Hash, head *types.Header) ([]byte, error) {
+	p.proofs++
+	return p.heads[head.Number.Uint64()].Bytes(), nil
+}
+
+func TestLogProcessor_ProcessBlock(t *testing.T) {
+	pingABI, err := abi.JSON(strings.NewReader(`[{"anonymous":false,"inputs":[{"indexed":false,"name":"value","type":"uint256"}],"name":"Ping","type":"event"}]`))
+	if err != nil {
+		t.Fatalf("failed to parse ABI: %v", err)
+	}
+	addr := common.HexToAddress("0xaa")
+	ping := pingABI.Events["Ping"]
+
+	provider := &sampleTestProvider{
+		logs:  make(map[uint64][]*types.Log),
+		heads: make(map[uint64]common.Hash),
+	}
+	// block creates a block emitting a single
+	// log with the specified value, and returns
+	// the hash chain head after the block
+	block := func(num, value uint64, prev common.Hash) (*types.Header, *types.Log, common.Hash) {
+		data, err := ping.Inputs.NonIndexed().Pack(new(big.Int).SetUint64(value))
+		if err != nil {
+			t.Fatalf("failed to pack event: %v", err)
+		}
+		l := &types.Log{Address: addr, Topics: []common.Hash{ping.ID}, Data: data, BlockNumber: num, TxHash: common.BigToHash(new(big.Int).SetUint64(value))}
+
+		head, err := NewLogVerifier(pingABI, prev).computeNewHead(prev, l)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		header := &types.Header{
+			Number: new(big.Int).SetUint64(num),
+			Extra:  data,
+			Bloom:  types.CreateBloom(&types.Receipt{Logs: []*types.Log{l}}),
+		}
+		l.BlockHash = header.Hash()
+		return header, l, head
+	}
+
+	chain := NewLogVerifier(pingABI, common.Hash{})
+	headers := make([]*types.Header, 0, 3)
+	for num := uint64(1); num <= 3; num++ {
+		header, l, head := block(num, num, chain.Head())
+		chain.head = head
+
+		provider.logs[num] = []*types.Log{l}
+		provider.heads[num] = head
+		headers = append(headers, header)
+	}
+
+	newProcessorWithDB := func(db storage.KeyValStore, sample func(*types.Header) bool) *LogProcessor {
+		return &LogProcessor{
+			log:      log.New(slog.DiscardHandler),
+			acc:      &monitor.AccountInfo{Addr: addr, ABI: pingABI},
+			verifier: NewLogVerifier(pingABI, common.Hash{}),
+			store:    ethstore.NewEventStore(db, ethstore.EncodingRLP),
+			heads:    ethstore.NewChainHeadStore(db),
+			provider: provider,
+			topics:   []common.Hash{ping.ID},
+			sample:   sample,
+		}
+	}
+	newProcessor := func(sample func(*types.Header) bool) *LogProcessor {
+		return newProcessorWithDB(mem.New(), sample)
+	}
+
+	t.Run("should verify logs of unsampled blocks at next sample", func(t *testing.T) {
+		provider.proofs = 0
+		p := newProcessor(func(h *types.Header) bool {
+			return h.Number.Uint64() == 3
+		})
+
+		for _, h := range headers {
+			if _, err := p.ProcessBlock(t.Context(), h); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+		}
+
+		if provider.proofs != 1 {
+			t.Errorf("expected 1 storage read, got %d", provider.proofs)
+		}
+		if p.verifier.Head() != chain.Head() {
+			t.Errorf("expected head %s, got %s", chain.Head().Hex(), p.verifier.Head().Hex())
+		}
+		if len(p.pending) != 0 {
+			t.Errorf("expected no pending logs, got %d", len(p.pending))
+		}
+	})
+
+	t.Run("should detect missing log of unsampled block", func(t *testing.T) {
+		p := newProcessor(func(h *types.Header) bool {
+			return h.Number.Uint64() == 3
+		})
+
+		withheld := provider.logs[2]
+		provider.logs[2] = nil
+		defer func() { provider.logs[2] = withheld }()
+
+		for _, h := range headers[:2] {
+			if _, err := p.ProcessBlock(t.Context(), h); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+		}
+		if _, err := p.ProcessBlock(t.Context(), headers[2]); !errors.Is(err, ethclient.ErrDivergence) {
+			t.Errorf("expected divergence, got %v", err)
+		}
+	})
+
+	t.Run("should verify every block without sampling", func(t *testing.T) {
+		provider.proofs = 0
+		p := newProcessor(nil)
+
+		for _, h := range headers {
+			if _, err := p.ProcessBlock(t.Context(), h); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+		}
+		if provider.proofs != 3 {
+			t.Errorf("expected 3 storage reads, got %d", provider.proofs)
+		}
+		if len(provider.topics) != 1 || provider.topics[0] != ping.ID {
+			t.Errorf("expected logs filtered by event ID, got %v", provider.topics)
+		}
+	})
+
+	t.Run("should resume hash chain from stored head", func(t *testing.T) {
+		db := mem.New()
+		defer db.Close()
+
+		p := newProcessorWithDB(db, nil)
+		if _, err := p.ProcessBlock(t.Context(), headers[0]); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		// Restart mid-chain
+		p = newProcessorWithDB(db, nil)
+		for _, h := range headers[1:] {
+			if _, err := p.ProcessBlock(t.Context(), h); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+		}
+		if p.verifier.Head() != chain.Head() {
+			t.Errorf("expected head %s, got %s", chain.Head().Hex(), p.verifier.Head().Hex())
+		}
+	})
+
+	t.Run("should replay blocks after restart from earlier block", func(t *testing.T) {
+		db := mem.New()
+		defer db.Close()
+
+		p := newProcessorWithDB(db, nil)
+		for _, h := range headers[:2] {
+			if _, err := p.ProcessBlock(t.Context(), h); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+		}
+
+		// Restart from the first block
+		p = newProcessorWithDB(db, nil)
+		for _, h := range headers {
+			if _, err := p.ProcessBlock(t.Context(), h); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+		}
+		if p.verifier.Head() != chain.Head() {
+			t.Errorf("expected head %s, got %s", chain.Head().Hex(), p.verifier.Head().Hex())
+		}
+	})
+
+	t.Run("should replay acknowledged blocks from stored heads", func(t *testing.T) {
+		db := mem.New()
+		defer db.Close()
+
+		p := newProcessorWithDB(db, nil)
+		digests := make([]common.Hash, 0, 2)
+		for _, h := range headers[:2] {
+			digest, err := p.ProcessBlock(t.Context(), h)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			digests = append(digests, digest)
+		}
+
+		// Restart, replaying the processed blocks
+		provider.proofs = 0
+		p = newProcessorWithDB(db, nil)
+		for i, h := range headers[:2] {
+			digest, ok, err := p.Replay(h)
+			if err != nil || !ok {
+				t.Fatalf("expected replay, got %v, %v", ok, err)
+			}
+			if digest != digests[i] {
+				t.Errorf("expected digest %s, got %s", digests[i].Hex(), digest.Hex())
+			}
+		}
+		if _, err := p.ProcessBlock(t.Context(), headers[2]); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if provider.proofs != 1 {
+			t.Errorf("expected 1 storage read, got %d", provider.proofs)
+		}
+		if p.verifier.Head() != chain.Head() {
+			t.Errorf("expected head %s, got %s", chain.Head().Hex(), p.verifier.Head().Hex())
+		}
+	})
+
+	t.Run("should not replay reorged block", func(t *testing.T) {
+		db := mem.New()
+		defer db.Close()
+
+		p := newProcessorWithDB(db, nil)
+		if _, err := p.ProcessBlock(t.Context(), headers[0]); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		reorged := types.CopyHeader(headers[0])
+		reorged.Extra = []byte("reorged")
+		if _, ok, err := newProcessorWithDB(db, nil).Replay(reorged); err != nil || ok {
+			t.Errorf("expected no replay, got %v, %v", ok, err)
+		}
+	})
+
+	t.Run("should not replay unsampled block", func(t *testing.T) {
+		db := mem.New()
+		defer db.Close()
+
+		sample := func(h *types.Header) bool {
+			return h.Number.Uint64() == 1
+		}
+		p := newProcessorWithDB(db, sample)
+		for _, h := range headers[:2] {
+			if _, err := p.ProcessBlock(t.Context(), h); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+		}
+
+		p = newProcessorWithDB(db, sample)
+		if _, ok, err := p.Replay(headers[0]); err != nil || !ok {
+			t.Errorf("expected replay of sampled block, got %v, %v", ok, err)
+		}
+		if _, ok, err := p.Replay(headers[1]); err != nil || ok {
+			t.Errorf("expected no replay of unsampled block, got %v, %v", ok, err)
+		}
+	})
+
+	t.Run("should rewind hash chain on reorg", func(t *testing.T) {
+		db := mem.New()
+		defer db.Close()
+
+		p := newProcessorWithDB(db, nil)
+		for _, h := range headers {
+			if _, err := p.ProcessBlock(t.Context(), h); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+		}
+
+		reorged, l, head := block(3, 30, provider.heads[2])
+		withheld, withheldHead := provider.logs[3], provider.heads[3]
+		provider.logs[3], provider.heads[3] = []*types.Log{l}, head
+		defer func() { provider.logs[3], provider.heads[3] = withheld, withheldHead }()
+
+		if _, err := p.ProcessBlock(t.Context(), reorged); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if p.verifier.Head() != head {
+			t.Errorf("expected head %s, got %s", head.Hex(), p.verifier.Head().Hex())
+		}
+		if _, err := p.store.GetLog(withheld[0].TxHash, withheld[0].Index); !errors.Is(err, ethstore.ErrLogNotFound) {
+			t.Errorf("expected reorged log to be removed, got %v", err)
+		}
+	})
+
+	t.Run("should verify pending logs again after reorg of sample", func(t *testing.T) {
+		db := mem.New()
+		defer db.Close()
+
+		p := newProcessorWithDB(db, func(h *types.Header) bool {
+			return h.Number.Uint64() == 3
+		})
+		for _, h := range headers {
+			if _, err := p.ProcessBlock(t.Context(), h); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+		}
+
+		reorged, l, head := block(3, 30, provider.heads[2])
+		withheld, withheldHead := provider.logs[3], provider.heads[3]
+		provider.logs[3], provider.heads[3] = []*types.Log{l}, head
+		defer func() { provider.logs[3], provider.heads[3] = withheld, withheldHead }()
+
+		if _, err := p.ProcessBlock(t.Context(), reorged); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if p.verifier.Head() != head {
+			t.Errorf("expected head %s, got %s", head.Hex(), p.verifier.Head().Hex())
+		}
+	})
+
+	t.Run("should reject log of other block", func(t *testing.T) {
+		p := newProcessor(nil)
+
+		other, _, _ := block(1, 10, common.Hash{})
+		if _, err := p.ProcessBlock(t.Context(), other); err == nil {
+			t.Errorf("expected error, got nil")
+		}
+	})
+
+	t.Run("should deliver verified events and retract reorged ones", func(t *testing.T) {
+		p := newProcessor(nil)
+		events := p.Subscribe("test")
+
+		for _, h := range headers {
+			if _, err := p.ProcessBlock(t.Context(), h); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+		}
+		for num := uint64(1); num <= 3; num++ {
+			ev := <-events
+			if value := ev.Args["value"].(*big.Int); ev.Log.Removed || value.Uint64() != num {
+				t.Errorf("expected event with value %d, got %v", num, value)
+			}
+		}
+
+		reorged, l, head := block(3, 30, provider.heads[2])
+		withheld, withheldHead := provider.logs[3], provider.heads[3]
+		provider.logs[3], provider.heads[3] = []*types.Log{l}, head
+		defer func() { provider.logs[3], provider.heads[3] = withheld, withheldHead }()
+
+		if _, err := p.ProcessBlock(t.Context(), reorged); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if ev := <-events; !ev.Log.Removed || ev.Args["value"].(*big.Int).Uint64() != 3 {
+			t.Errorf("expected retracted event with value 3")
+		}
+		if ev := <-events; ev.Log.Removed || ev.Args["value"].(*big.Int).Uint64() != 30 {
+			t.Errorf("expected event with value 30")
+		}
+	})
+}
@@ -3,20 +3,27 @@ package event
 import (
 	"bytes"
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
+	"sparseth/execution/monitor"
 )
 
 // Verifier verifies the completeness and integrity
 // of Ethereum event logs using a hash chain mechanism.
 //
-// All events must be non-anonymous.
+// Anonymous events carry no event ID, and are matched
+// by their topic count and data layout instead.
 type Verifier struct {
 	// abi is the ABI of the contract.
 	abi abi.ABI
+	// anonymous holds all anonymous
+	// events of the ABI.
+	anonymous []abi.Event
 	// head is the current head of the hash chain.
 	head common.Hash
 }
@@ -29,11 +36,33 @@ type Verifier struct {
 // all events that will be verified.
 func NewLogVerifier(abi abi.ABI, head common.Hash) *Verifier {
 	return &Verifier{
-		abi:  abi,
-		head: head,
+		abi:       abi,
+		anonymous: AnonymousEvents(abi),
+		head:      head,
 	}
 }
 
+// AnonymousEvents returns all anonymous events
+// of the specified ABI, sorted by signature.
+func AnonymousEvents(contractAbi abi.ABI) []abi.Event {
+	var anonymous []abi.Event
+	for _, event := range contractAbi.Events {
+		if event.Anonymous {
+			anonymous = append(anonymous, event)
+		}
+	}
+	slices.SortFunc(anonymous, func(a, b abi.Event) int {
+		return strings.Compare(a.Sig, b.Sig)
+	})
+	return anonymous
+}
+
+// Head returns the current head
+// of the hash chain.
+func (v *Verifier) Head() common.Hash {
+	return v.head
+}
+
 // VerifyLogs validates the specified ordered slice
 // of logs against the expected hash chain head.
 func (v *Verifier) VerifyLogs(logs []*types.Log, expected common.Hash) error {
@@ -47,7 +76,7 @@ func (v *Verifier) VerifyLogs(logs []*types.Log, expected common.Hash) error {
 	}
 
 	if !bytes.Equal(curr.Bytes(), expected.Bytes()) {
-		return fmt.Errorf("head mismatch")
+		return &monitor.MismatchError{Kind: "hash chain head", Expected: expected.Hex(), Actual: curr.Hex()}
 	}
 
 	v.head = curr
@@ -57,14 +86,9 @@ func (v *Verifier) VerifyLogs(logs []*types.Log, expected common.Hash) error {
 // computeNewHead calculates the new hash chain
 // head after processing a single log.
 func (v *Verifier) computeNewHead(prev common.Hash, log *types.Log) (common.Hash, error) {
-	if len(log.Topics) < 1 {
-		return common.Hash{}, fmt.Errorf("log does not contain ID")
-	}
-
-	id := log.Topics[0]
-	event, err := v.abi.EventByID(id)
+	event, err := v.eventOf(log)
 	if err != nil {
-		return common.Hash{}, fmt.Errorf("unknown event ID: %w", err)
+		return common.Hash{}, err
 	}
 
 	data, err := event.Inputs.NonIndexed().UnpackValues(log.Data)
@@ -88,12 +112,15 @@ func (v *Verifier) computeNewHead(prev common.Hash, log *types.Log) (common.Hash
 	}
 	vals := []interface{}{prev}
 
-	indexed, nonIndexed := 1, 0
+	// Anonymous events have no ID topic
+	first := topicOffset(event)
+
+	indexed, nonIndexed := first, 0
 	for _, arg := range event.Inputs {
 		args = append(args, arg)
 		if arg.Indexed {
 			if len(log.Topics) <= indexed {
-				return common.Hash{}, fmt.Errorf("topic count mismatch: want %d, got %d", indexed, len(log.Topics)-1)
+				return common.Hash{}, fmt.Errorf("topic count mismatch: want %d, got %d", indexed+1-first, len(log.Topics)-first)
 			}
 			vals = append(vals, log.Topics[indexed])
 			indexed++
@@ -105,7 +132,7 @@ func (v *Verifier) computeNewHead(prev common.Hash, log *types.Log) (common.Hash
 
 	if indexed != len(log.Topics) {
 		topics := len(event.Inputs) - len(event.Inputs.NonIndexed())
-		return common.Hash{}, fmt.Errorf("topic count mismatch: want %d, got %d", topics, len(log.Topics)-1)
+		return common.Hash{}, fmt.Errorf("topic count mismatch: want %d, got %d", topics, len(log.Topics)-first)
 	}
 
 	packed, err := args.Pack(vals...)
@@ -115,3 +142,42 @@ func (v *Verifier) computeNewHead(prev common.Hash, log *types.Log) (common.Hash
 
 	return crypto.Keccak256Hash(packed), nil
 }
+
+// eventOf returns the event of the ABI that emitted
+// the specified log. Logs are matched by their event
+// ID first, then against the anonymous events, which
+// must match exactly one by topic count and data.
+func (v *Verifier) eventOf(log *types.Log) (*abi.Event, error) {
+	if len(log.Topics) > 0 {
+		event, err := v.abi.EventByID(log.Topics[0])
+		if err == nil && !event.Anonymous {
+			return event, nil
+		}
+	}
+	if len(v.anonymous) == 0 {
+		if len(log.Topics) < 1 {
+			return nil, fmt.Errorf("log does not contain ID")
+		}
+		return nil, fmt.Errorf("unknown event ID: %s", log.Topics[0].Hex())
+	}
+
+	var match *abi.Event
+	for i := range v.anonymous {
+		event := &v.anonymous[i]
+		topics := len(event.Inputs) - len(event.Inputs.NonIndexed())
+		if topics != len(log.Topics) {
+			continue
+		}
+		if _, err := event.Inputs.NonIndexed().UnpackValues(log.Data); err != nil {
+			continue
+		}
+		if match != nil {
+			return nil, fmt.Errorf("ambiguous anonymous event: %s or %s", match.Sig, event.Sig)
+		}
+		match = event
+	}
+	if match == nil {
+		return nil, fmt.Errorf("unknown event: no event matches log")
+	}
+	return match, nil
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	"math/big"
 	"testing"
 )
@@ -241,3 +242,46 @@ func TestVerifier_VerifyLogs(t *testing.T) {
 		}
 	})
 }
+
+func TestVerifier_VerifyAnonymousLogs(t *testing.T) {
+	anonABI, err := abi.JSON(bytes.NewReader([]byte(`[{"anonymous":true,"inputs":[{"indexed":true,"name":"key","type":"bytes32"},{"indexed":false,"name":"value","type":"uint256"}],"name":"Log","type":"event"},{"anonymous":false,"inputs":[{"indexed":false,"name":"value","type":"uint256"}],"name":"Ping","type":"event"}]`)))
+	if err != nil {
+		t.Fatalf("failed to parse ABI: %v", err)
+	}
+	key := common.HexToHash("0x01")
+	value := common.BigToHash(big.NewInt(2))
+
+	t.Run("should verify anonymous event", func(t *testing.T) {
+		logs := []*types.Log{{Topics: []common.Hash{key}, Data: value.Bytes()}}
+
+		expected := crypto.Keccak256Hash(common.Hash{}.Bytes(), key.Bytes(), value.Bytes())
+		verifier := NewLogVerifier(anonABI, common.Hash{})
+		if err := verifier.VerifyLogs(logs, expected); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("should prefer event matching ID", func(t *testing.T) {
+		ping := anonABI.Events["Ping"]
+		logs := []*types.Log{{Topics: []common.Hash{ping.ID}, Data: value.Bytes()}}
+
+		expected := crypto.Keccak256Hash(common.Hash{}.Bytes(), value.Bytes())
+		verifier := NewLogVerifier(anonABI, common.Hash{})
+		if err := verifier.VerifyLogs(logs, expected); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("should return error when anonymous event is ambiguous", func(t *testing.T) {
+		ambiguousABI, err := abi.JSON(bytes.NewReader([]byte(`[{"anonymous":true,"inputs":[{"indexed":true,"name":"key","type":"bytes32"},{"indexed":false,"name":"value","type":"uint256"}],"name":"Log","type":"event"},{"anonymous":true,"inputs":[{"indexed":true,"name":"key","type":"bytes32"},{"indexed":false,"name":"value","type":"int256"}],"name":"Signed","type":"event"}]`)))
+		if err != nil {
+			t.Fatalf("failed to parse ABI: %v", err)
+		}
+		logs := []*types.Log{{Topics: []common.Hash{key}, Data: value.Bytes()}}
+
+		verifier := NewLogVerifier(ambiguousABI, common.Hash{})
+		if err = verifier.VerifyLogs(logs, common.Hash{}); err == nil {
+			t.Errorf("expected error, got nil")
+		}
+	})
+}
@@ -0,0 +1,145 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"math/big"
+	"sparseth/execution/ethclient"
+	"strings"
+)
+
+const (
+	// maxLogWindow is the maximum number of
+	// blocks covered by a single log query.
+	maxLogWindow = 2048
+	// cheapLogWindow is the number of logs below
+	// which a log query is considered cheap, so
+	// that the window grows.
+	cheapLogWindow = 1000
+	// limitExceededCode is the JSON-RPC error code
+	// used by many providers if a log query exceeds
+	// their limits.
+	limitExceededCode = -32005
+)
+
+// logWindow fetches the logs of an account over
+// adaptive windows of blocks, so that blocks
+// queued for processing, e.g., during backfill,
+// are covered by as few log queries as possible.
+//
+// The window shrinks if a provider rejects a query
+// as too large, and grows while queries are cheap.
+type logWindow struct {
+	provider ethclient.Provider
+	addr     common.Address
+	// topics filter the fetched logs
+	// by event ID, if set.
+	topics []common.Hash
+	// bound returns the number of the last block
+	// that may be queried, i.e., that is queued
+	// for processing.
+	bound func() uint64
+	size  uint64
+	// logs holds the fetched logs by block
+	// number, from next up to last.
+	logs       map[uint64][]*types.Log
+	next, last uint64
+}
+
+// newLogWindow creates a new logWindow for the
+// specified account and topics. Windows never
+// extend past the block returned by bound.
+func newLogWindow(provider ethclient.Provider, addr common.Address, topics []common.Hash, bound func() uint64) *logWindow {
+	return &logWindow{
+		provider: provider,
+		addr:     addr,
+		topics:   topics,
+		bound:    bound,
+		size:     1,
+	}
+}
+
+// logsAtBlock returns the logs of the account at
+// the specified block. If the block is not covered
+// by the current window, a new window starting at
+// the block is fetched.
+//
+// Logs are attributed to the block by number, and
+// discarded if the block hash does not match, e.g.,
+// after a reorg, in which case the logs of the
+// block are fetched individually.
+func (w *logWindow) logsAtBlock(ctx context.Context, head *types.Header) ([]*types.Log, error) {
+	num := head.Number.Uint64()
+	if w.logs == nil || num < w.next || num > w.last {
+		if err := w.fetch(ctx, num); err != nil {
+			return nil, err
+		}
+	}
+
+	logs := w.logs[num]
+	delete(w.logs, num)
+	w.next = num + 1
+
+	for _, log := range logs {
+		if log.BlockHash != head.Hash() {
+			w.logs = nil
+			return w.provider.GetLogsAtBlock(ctx, w.addr, head.Number, w.topics)
+		}
+	}
+	return logs, nil
+}
+
+// fetch fetches a new window starting at the
+// specified block, shrinking the window until
+// the provider accepts the query.
+func (w *logWindow) fetch(ctx context.Context, from uint64) error {
+	for {
+		to := from + w.size - 1
+		if bound := w.bound(); to > bound {
+			to = max(from, bound)
+		}
+
+		logs, err := w.provider.GetLogsInRange(ctx, w.addr, new(big.Int).SetUint64(from), new(big.Int).SetUint64(to), w.topics)
+		if err != nil {
+			if isTooLarge(err) && to > from {
+				w.size = max(1, (to-from+1)/2)
+				continue
+			}
+			return fmt.Errorf("failed to get logs in blocks %d to %d: %w", from, to, err)
+		}
+
+		if len(logs) < cheapLogWindow && to-from+1 == w.size {
+			w.size = min(maxLogWindow, 2*w.size)
+		}
+
+		w.logs = make(map[uint64][]*types.Log)
+		for _, log := range logs {
+			w.logs[log.BlockNumber] = append(w.logs[log.BlockNumber], log)
+		}
+		w.next, w.last = from, to
+		return nil
+	}
+}
+
+// isTooLarge checks whether the specified error
+// indicates that a log query exceeded the limits
+// of the provider, e.g., as its response would be
+// too large.
+func isTooLarge(err error) bool {
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) && rpcErr.ErrorCode() == limitExceededCode {
+		return true
+	}
+
+	msg := strings.ToLower(err.Error())
+	for _, hint := range []string{"too large", "too many", "more than", "limit exceeded", "block range"} {
+		if strings.Contains(msg, hint) {
+			return true
+		}
+	}
+	return false
+}
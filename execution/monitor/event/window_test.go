@@ -0,0 +1,124 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"math/big"
+	"sparseth/execution/ethclient"
+	"testing"
+)
+
+type windowTestProvider struct {
+	ethclient.Provider
+	// logs holds the logs of each block
+	logs map[uint64][]*types.Log
+	// limit is the maximum number of
+	// blocks queried at once, if set
+	limit uint64
+	// ranges holds the queried ranges
+	ranges [][2]uint64
+	// single counts per-block queries
+	single int
+}
+
+func (p *windowTestProvider) GetLogsInRange(_ context.Context, _ common.Address, from, to *big.Int, _ []common.Hash) ([]*types.Log, error) {
+	if p.limit > 0 && to.Uint64()-from.Uint64()+1 > p.limit {
+		return nil, errors.New("query returned more than 10000 results")
+	}
+
+	p.ranges = append(p.ranges, [2]uint64{from.Uint64(), to.Uint64()})
+	var logs []*types.Log
+	for num := from.Uint64(); num <= to.Uint64(); num++ {
+		logs = append(logs, p.logs[num]...)
+	}
+	return logs, nil
+}
+
+func (p *windowTestProvider) GetLogsAtBlock(_ context.Context, _ common.Address, blockNum *big.Int, _ []common.Hash) ([]*types.Log, error) {
+	p.single++
+	return p.logs[blockNum.Uint64()], nil
+}
+
+func newWindowTestHeader(num uint64) *types.Header {
+	return &types.Header{Number: new(big.Int).SetUint64(num)}
+}
+
+func TestLogWindow_LogsAtBlock(t *testing.T) {
+	addr := common.HexToAddress("0x01")
+
+	t.Run("should cover queued blocks with few queries", func(t *testing.T) {
+		head := newWindowTestHeader(5)
+		provider := &windowTestProvider{logs: map[uint64][]*types.Log{
+			5: {{BlockNumber: 5, BlockHash: head.Hash()}},
+		}}
+		w := newLogWindow(provider, addr, nil, func() uint64 { return 100 })
+
+		for num := uint64(1); num <= 100; num++ {
+			h := newWindowTestHeader(num)
+			logs, err := w.logsAtBlock(t.Context(), h)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if num == 5 && len(logs) != 1 {
+				t.Errorf("expected 1 log at block 5, got %d", len(logs))
+			}
+		}
+
+		if len(provider.ranges) > 8 {
+			t.Errorf("expected at most 8 queries, got %d", len(provider.ranges))
+		}
+	})
+
+	t.Run("should not query past bound", func(t *testing.T) {
+		provider := &windowTestProvider{}
+		w := newLogWindow(provider, addr, nil, func() uint64 { return 3 })
+		w.size = 16
+
+		if _, err := w.logsAtBlock(t.Context(), newWindowTestHeader(1)); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if provider.ranges[0] != [2]uint64{1, 3} {
+			t.Errorf("expected blocks 1 to 3, got %v", provider.ranges[0])
+		}
+	})
+
+	t.Run("should shrink window if response is too large", func(t *testing.T) {
+		provider := &windowTestProvider{limit: 4}
+		w := newLogWindow(provider, addr, nil, func() uint64 { return 100 })
+		w.size = 64
+
+		if _, err := w.logsAtBlock(t.Context(), newWindowTestHeader(1)); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if r := provider.ranges[0]; r[1]-r[0]+1 > 4 {
+			t.Errorf("expected at most 4 blocks, got %v", r)
+		}
+	})
+
+	t.Run("should refetch block if hash does not match", func(t *testing.T) {
+		provider := &windowTestProvider{logs: map[uint64][]*types.Log{
+			1: {{BlockNumber: 1, BlockHash: common.HexToHash("0xdead")}},
+		}}
+		w := newLogWindow(provider, addr, nil, func() uint64 { return 10 })
+
+		if _, err := w.logsAtBlock(t.Context(), newWindowTestHeader(1)); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if provider.single != 1 {
+			t.Errorf("expected block to be refetched, got %d per-block queries", provider.single)
+		}
+	})
+}
+
+func TestIsTooLarge(t *testing.T) {
+	t.Run("should detect limit errors", func(t *testing.T) {
+		if !isTooLarge(errors.New("Log response size exceeded. You can make eth_getLogs requests with up to a 2K block range")) {
+			t.Errorf("expected limit error")
+		}
+		if isTooLarge(errors.New("connection refused")) {
+			t.Errorf("expected no limit error")
+		}
+	})
+}
@@ -17,7 +17,11 @@ type AccountInfo struct {
 	// Slot contains the head of the hash
 	// chain.
 	Slot common.Hash
-	// InitialHead is the initial head
-	// value of the event chain.
+	// InitialHead is the initial head value
+	// of the event chain, used if no verified
+	// head is stored.
 	InitialHead common.Hash
+	// StartBlock is the first block
+	// processed for the account.
+	StartBlock uint64
 }
@@ -0,0 +1,111 @@
+package monitor
+
+import (
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/holiman/uint256"
+	"math/big"
+	"sparseth/bus"
+	"sparseth/config"
+	"sparseth/log"
+	"sync"
+)
+
+// StateReader reads the verified
+// state of monitored accounts.
+type StateReader interface {
+	GetBalance(addr common.Address) *uint256.Int
+	GetNonce(addr common.Address) uint64
+	GetState(addr common.Address, slot common.Hash) common.Hash
+}
+
+// InvariantChecker evaluates the configured
+// invariants against the verified state after
+// each block, and raises an alert once an
+// invariant is violated.
+//
+// A violated invariant is only alerted again
+// after it held for at least one block, so that
+// a persistent violation raises a single alert.
+type InvariantChecker struct {
+	invariants []*config.Invariant
+	alerts     *bus.Topic[*bus.Alert]
+	log        log.Logger
+
+	// violated holds the names of
+	// the violated invariants.
+	violated map[string]bool
+	mu       sync.Mutex
+}
+
+// NewInvariantChecker creates a new InvariantChecker
+// that publishes violations of the specified
+// invariants to the specified alerts topic.
+func NewInvariantChecker(invariants []*config.Invariant, alerts *bus.Topic[*bus.Alert], log log.Logger) *InvariantChecker {
+	return &InvariantChecker{
+		invariants: invariants,
+		alerts:     alerts,
+		log:        log.With("component", "invariant-checker"),
+		violated:   make(map[string]bool),
+	}
+}
+
+// Check evaluates all invariants against the
+// specified state after the specified block,
+// and returns the names of the violated ones.
+func (c *InvariantChecker) Check(head *types.Header, state StateReader) []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	violated := make([]string, 0)
+	for _, inv := range c.invariants {
+		left := evalOperand(inv.Left, state)
+		right := evalOperand(inv.Right, state)
+		if inv.Op.Holds(left.Cmp(right)) {
+			if c.violated[inv.Name] {
+				c.log.Info("invariant holds again", "invariant", inv.Name, "num", head.Number, "hash", head.Hash().Hex())
+				delete(c.violated, inv.Name)
+			}
+			continue
+		}
+
+		violated = append(violated, inv.Name)
+		if c.violated[inv.Name] {
+			continue
+		}
+		c.violated[inv.Name] = true
+
+		c.log.Warn("invariant violated", "invariant", inv.Name, "check", inv.Expr, "left", left, "right", right, "num", head.Number, "hash", head.Hash().Hex())
+		alert := NewAlert("invariant-checker", invariantAccount(inv), head, fmt.Errorf("invariant %s violated: %s", inv.Name, inv.Expr))
+		alert.Kind = "invariant"
+		alert.Expected = inv.Expr
+		alert.Actual = fmt.Sprintf("%s %s %s", left, inv.Op, right)
+		c.alerts.Publish(alert)
+	}
+	return violated
+}
+
+// evalOperand returns the value of the
+// specified operand in the specified state.
+func evalOperand(op *config.Operand, state StateReader) *big.Int {
+	switch op.Kind {
+	case config.OperandBalance:
+		return state.GetBalance(op.Account).ToBig()
+	case config.OperandNonce:
+		return new(big.Int).SetUint64(state.GetNonce(op.Account))
+	case config.OperandSlot:
+		return state.GetState(op.Account, op.Slot).Big()
+	default:
+		return op.Value
+	}
+}
+
+// invariantAccount returns the first
+// account the invariant refers to.
+func invariantAccount(inv *config.Invariant) common.Address {
+	if inv.Left.Kind != config.OperandConst {
+		return inv.Left.Account
+	}
+	return inv.Right.Account
+}
@@ -0,0 +1,104 @@
+package monitor
+
+import (
+	"log/slog"
+	"math/big"
+	"sparseth/bus"
+	"sparseth/config"
+	"sparseth/internal/log"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/holiman/uint256"
+)
+
+type testState struct {
+	balance *uint256.Int
+	slots   map[common.Hash]common.Hash
+}
+
+func (s *testState) GetBalance(common.Address) *uint256.Int {
+	return s.balance
+}
+
+func (s *testState) GetNonce(common.Address) uint64 {
+	return 0
+}
+
+func (s *testState) GetState(_ common.Address, slot common.Hash) common.Hash {
+	return s.slots[slot]
+}
+
+func TestInvariantChecker_Check(t *testing.T) {
+	testLogger := log.New(slog.DiscardHandler)
+	addr := common.HexToAddress("0xaa")
+
+	// reserves: slot 0x1 >= slot 0x2
+	reserves := &config.Invariant{
+		Name:  "reserves",
+		Expr:  "slot(0xaa, 0x1) >= slot(0xaa, 0x2)",
+		Left:  &config.Operand{Kind: config.OperandSlot, Account: addr, Slot: common.HexToHash("0x1")},
+		Op:    config.GreaterOrEqual,
+		Right: &config.Operand{Kind: config.OperandSlot, Account: addr, Slot: common.HexToHash("0x2")},
+	}
+	// funded: balance > 100
+	funded := &config.Invariant{
+		Name:  "funded",
+		Expr:  "balance(0xaa) > 100",
+		Left:  &config.Operand{Kind: config.OperandBalance, Account: addr},
+		Op:    config.Greater,
+		Right: &config.Operand{Kind: config.OperandConst, Value: big.NewInt(100)},
+	}
+
+	t.Run("should not alert if invariants hold", func(t *testing.T) {
+		alerts := bus.NewTopic[*bus.Alert]("alerts", testLogger)
+		sub := alerts.Subscribe("test")
+		state := &testState{
+			balance: uint256.NewInt(101),
+			slots:   map[common.Hash]common.Hash{common.HexToHash("0x1"): common.HexToHash("0x05"), common.HexToHash("0x2"): common.HexToHash("0x05")},
+		}
+
+		violated := NewInvariantChecker([]*config.Invariant{reserves, funded}, alerts, testLogger).Check(&types.Header{Number: big.NewInt(1)}, state)
+		if len(violated) != 0 {
+			t.Errorf("expected no violations, got %v", violated)
+		}
+		if len(sub) != 0 {
+			t.Errorf("expected no alerts, got %d", len(sub))
+		}
+	})
+
+	t.Run("should alert once per violation", func(t *testing.T) {
+		alerts := bus.NewTopic[*bus.Alert]("alerts", testLogger)
+		sub := alerts.Subscribe("test")
+		state := &testState{
+			balance: uint256.NewInt(100),
+			slots:   map[common.Hash]common.Hash{},
+		}
+		checker := NewInvariantChecker([]*config.Invariant{reserves, funded}, alerts, testLogger)
+
+		for i := range 2 {
+			violated := checker.Check(&types.Header{Number: big.NewInt(int64(i))}, state)
+			if len(violated) != 1 || violated[0] != "funded" {
+				t.Fatalf("expected funded to be violated, got %v", violated)
+			}
+		}
+		if len(sub) != 1 {
+			t.Fatalf("expected 1 alert, got %d", len(sub))
+		}
+		alert := <-sub
+		if alert.Kind != "invariant" || alert.Account != addr || alert.Actual != "100 > 100" {
+			t.Errorf("expected invariant alert for %s, got %+v", addr.Hex(), alert)
+		}
+
+		// Once the invariant holds again,
+		// a new violation is alerted
+		state.balance = uint256.NewInt(101)
+		checker.Check(&types.Header{Number: big.NewInt(2)}, state)
+		state.balance = uint256.NewInt(0)
+		checker.Check(&types.Header{Number: big.NewInt(3)}, state)
+		if len(sub) != 1 {
+			t.Errorf("expected another alert, got %d", len(sub))
+		}
+	})
+}
@@ -2,11 +2,33 @@ package monitor
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/mclock"
 	"github.com/ethereum/go-ethereum/core/types"
+	"sparseth/bus"
+	"sparseth/ethstore"
+	"sparseth/execution/ethclient"
 	"sparseth/log"
+	"time"
 )
 
+const (
+	// retryDelay is the delay before the first
+	// retry of a failed block, doubling with
+	// each further retry.
+	retryDelay = time.Second
+	// maxRetryDelay is the maximum delay
+	// between retries of a failed block.
+	maxRetryDelay = 30 * time.Second
+)
+
+// ErrHalted is returned for the blocks after a
+// block that failed permanently, until the failed
+// block is processed successfully.
+var ErrHalted = errors.New("monitor halted at failed block")
+
 type Monitor struct {
 	log log.Logger
 	// sub is the channel for receiving
@@ -15,16 +37,115 @@ type Monitor struct {
 	// processor handles business logic
 	// to process blocks
 	processor Processor
+	// name identifies the monitor
+	// at the scheduler
+	name string
+	// sched bounds concurrent block
+	// processing across monitors
+	sched *Scheduler
+	// results receives the result
+	// of each processed block
+	results *bus.Topic[*bus.Result]
+	// cursors stores the last acknowledged
+	// block, or is nil if not persisted
+	cursors *ethstore.CursorStore
+	// cursor is the last block acknowledged
+	// before the start, until the first block
+	// after it is processed.
+	cursor *ethstore.Cursor
+	// retries is the number of retries
+	// of a failed block.
+	retries    int
+	retryDelay time.Duration
+	// headers is the store dropped blocks are
+	// backfilled from, or nil if not backfilled.
+	headers *ethstore.HeaderStore
+	// last is the number of the last block
+	// received, if any.
+	last     uint64
+	received bool
+	// deadLetters stores blocks that failed
+	// permanently, or is nil if not recorded.
+	deadLetters *ethstore.DeadLetterStore
+	// halted is the block that failed permanently,
+	// which is processed again before each further
+	// block, or nil if not halted.
+	halted *types.Header
+	// clock is used for retry delays and the
+	// time of dead letters, which is relative
+	// to the wall time at epoch.
+	clock mclock.Clock
+	epoch time.Time
+	start mclock.AbsTime
 }
 
 // NewMonitor creates a new Monitor for the
-// specified Ethereum smart contract.
-func NewMonitor(name string, ch <-chan *types.Header, processor Processor, log log.Logger) *Monitor {
-	return &Monitor{
-		log:       log.With("component", name+"-monitor"),
-		sub:       ch,
-		processor: processor,
+// specified Ethereum smart contract. Blocks
+// are only processed once the specified
+// scheduler grants a slot, if not nil. The
+// result of each processed block is published
+// to the specified topic.
+func NewMonitor(name string, ch <-chan *types.Header, processor Processor, sched *Scheduler, results *bus.Topic[*bus.Result], log log.Logger) *Monitor {
+	m := &Monitor{
+		log:        log.With("component", name+"-monitor"),
+		sub:        ch,
+		processor:  processor,
+		name:       name,
+		sched:      sched,
+		results:    results,
+		retryDelay: retryDelay,
 	}
+	m.SetClock(mclock.System{})
+	return m
+}
+
+// SetClock sets the clock used for retry delays
+// and the time of dead letters. The clock must be
+// set before the monitor is started.
+func (m *Monitor) SetClock(clock mclock.Clock) {
+	m.clock = clock
+	m.epoch = time.Now()
+	m.start = clock.Now()
+}
+
+// SetCursors sets the store of the last block
+// acknowledged by the monitor, i.e., processed
+// successfully. If the processor is a Replayer,
+// blocks up to the acknowledged block are replayed
+// from the store after a restart, instead of being
+// processed again. Cursors must be set before the
+// monitor is started.
+func (m *Monitor) SetCursors(cursors *ethstore.CursorStore) {
+	m.cursors = cursors
+}
+
+// SetBackfill sets the store that blocks missing
+// from the subscription, e.g., as they were dropped
+// from a full queue, are backfilled from, so that
+// no block is skipped. Backfill must be set before
+// the monitor is started.
+func (m *Monitor) SetBackfill(headers *ethstore.HeaderStore) {
+	m.headers = headers
+}
+
+// SetRetries sets the number of retries of a block
+// that failed to process, with exponential backoff.
+// Divergences are not retried, as they are not
+// transient. Retries must be set before the
+// monitor is started.
+func (m *Monitor) SetRetries(retries int) {
+	m.retries = retries
+}
+
+// SetDeadLetters sets the store of blocks that
+// failed permanently, i.e., after all retries.
+// Either way, the monitor halts at such a block:
+// it is processed again before each further block,
+// which fails with ErrHalted until it succeeds.
+// Dead letters must be set before the monitor
+// is started.
+func (m *Monitor) SetDeadLetters(deadLetters *ethstore.DeadLetterStore) {
+	m.deadLetters = deadLetters
 }
 
 // RunContext starts the monitoring loop
@@ -32,12 +153,21 @@ func NewMonitor(name string, ch <-chan *types.Header, processor Processor, log l
 func (m *Monitor) RunContext(ctx context.Context) error {
 	m.log.Info("start monitor")
 
+	if err := m.loadCursor(); err != nil {
+		return err
+	}
+
 	for {
 		select {
-		case head := <-m.sub:
-			if err := m.processBlock(ctx, head); err != nil {
-				m.log.Warn("failed to process block", "num", head.Number, "hash", head.Hash().Hex(), "err", err)
+		case head, ok := <-m.sub:
+			if !ok {
+				m.log.Info("subscription closed, stop monitor")
+				return nil
 			}
+			for _, h := range m.backfill(head) {
+				m.handle(ctx, h)
+			}
+			m.handle(ctx, head)
 		case <-ctx.Done():
 			m.log.Info("stop monitor")
 			return nil
@@ -45,14 +175,232 @@ func (m *Monitor) RunContext(ctx context.Context) error {
 	}
 }
 
-// processBlock handles a single block.
-func (m *Monitor) processBlock(ctx context.Context, header *types.Header) error {
+// handle processes the specified block, or replays
+// it if acknowledged before, and publishes the result.
+func (m *Monitor) handle(ctx context.Context, head *types.Header) {
+	m.last, m.received = head.Number.Uint64(), true
+
+	if digest, ok := m.replayBlock(head); ok {
+		m.results.Publish(&bus.Result{
+			Monitor: m.name,
+			Number:  head.Number.Uint64(),
+			Hash:    head.Hash(),
+			Digest:  digest,
+		})
+		return
+	}
+
+	if err := m.resume(ctx, head); err != nil {
+		if ctx.Err() != nil {
+			return
+		}
+		m.results.Publish(&bus.Result{
+			Monitor: m.name,
+			Number:  head.Number.Uint64(),
+			Hash:    head.Hash(),
+			Err:     err,
+		})
+		return
+	}
+
+	digest, served, activity, err := m.processWithRetries(ctx, head)
+	if ctx.Err() != nil {
+		return
+	}
+	res := &bus.Result{
+		Monitor:   m.name,
+		Number:    head.Number.Uint64(),
+		Hash:      head.Hash(),
+		Err:       err,
+		Providers: served.URLs(),
+		Digest:    digest,
+	}
+	if err == nil {
+		res.Activity = activity.Counts()
+		m.acknowledge(head)
+	}
+	m.results.Publish(res)
+}
+
+// resume processes the block the monitor halted
+// at, if any, before the specified block, so that
+// no block is skipped. A block that replaces the
+// halted block, e.g., after a reorg, lifts the
+// halt instead.
+func (m *Monitor) resume(ctx context.Context, head *types.Header) error {
+	if m.halted == nil {
+		return nil
+	}
+	if head.Number.Uint64() <= m.halted.Number.Uint64() {
+		m.halted = nil
+		return nil
+	}
+
+	if _, err := m.processBlock(ctx, m.halted); err != nil {
+		m.log.Warn("failed to process halted block", "num", m.halted.Number, "hash", m.halted.Hash().Hex(), "err", err)
+		return fmt.Errorf("%w %d: %w", ErrHalted, m.halted.Number.Uint64(), err)
+	}
+
+	m.log.Info("halted block processed, resume monitor", "num", m.halted.Number, "hash", m.halted.Hash().Hex())
+	m.acknowledge(m.halted)
+	m.halted = nil
+	return nil
+}
+
+// backfill returns the blocks between the last
+// received block and the specified block, in
+// order, if any are missing and backfill is set.
+// The blocks are read from the store, which holds
+// the canonical chain the specified block extends.
+func (m *Monitor) backfill(head *types.Header) []*types.Header {
+	if m.headers == nil || !m.received || head.Number.Uint64() <= m.last+1 {
+		return nil
+	}
+
+	missing := make([]*types.Header, 0, head.Number.Uint64()-m.last-1)
+	for num := m.last + 1; num < head.Number.Uint64(); num++ {
+		h, err := m.headers.GetByNumber(num)
+		if err != nil {
+			m.log.Error("failed to backfill dropped block", "num", num, "err", err)
+			return missing
+		}
+		missing = append(missing, h)
+	}
+	m.log.Warn("backfill dropped blocks", "from", m.last+1, "to", head.Number.Uint64()-1)
+	return missing
+}
+
+// processWithRetries handles a single block, retrying
+// failed attempts, and records the block as a dead
+// letter if all attempts fail. The providers and
+// activity of the last attempt are returned.
+func (m *Monitor) processWithRetries(ctx context.Context, head *types.Header) (common.Hash, *ethclient.ServedBy, *Activity, error) {
+	delay := m.retryDelay
+	for attempt := 1; ; attempt++ {
+		served := new(ethclient.ServedBy)
+		activity := new(Activity)
+		digest, err := m.processBlock(WithActivity(ethclient.WithServedBy(ctx, served), activity), head)
+		if err == nil || ctx.Err() != nil {
+			return digest, served, activity, err
+		}
+		if attempt > m.retries || errors.Is(err, ethclient.ErrDivergence) {
+			m.log.Warn("failed to process block", "num", head.Number, "hash", head.Hash().Hex(), "attempts", attempt, "err", err)
+			m.deadLetter(head, attempt, err)
+			m.halted = head
+			return digest, served, activity, err
+		}
+
+		m.log.Warn("failed to process block, retry", "num", head.Number, "hash", head.Hash().Hex(), "attempt", attempt, "delay", delay, "err", err)
+		select {
+		case <-m.clock.After(delay):
+		case <-ctx.Done():
+			return digest, served, activity, err
+		}
+		delay = min(2*delay, maxRetryDelay)
+	}
+}
+
+// deadLetter records the specified block
+// as failed after the specified attempts.
+func (m *Monitor) deadLetter(head *types.Header, attempts int, err error) {
+	if m.deadLetters == nil {
+		return
+	}
+
+	err = m.deadLetters.Put(&ethstore.DeadLetter{
+		Monitor:  m.name,
+		Number:   head.Number.Uint64(),
+		Hash:     head.Hash(),
+		Err:      err.Error(),
+		Attempts: uint64(attempts),
+		Time:     uint64(m.epoch.Add(time.Duration(m.clock.Now() - m.start)).Unix()),
+	})
+	if err != nil {
+		m.log.Warn("failed to record dead letter", "num", head.Number, "hash", head.Hash().Hex(), "err", err)
+	}
+}
+
+// processBlock handles a single block, and
+// returns the digest of its verified outputs.
+func (m *Monitor) processBlock(ctx context.Context, header *types.Header) (common.Hash, error) {
+	if m.sched != nil {
+		release, err := m.sched.Acquire(ctx, m.name)
+		if err != nil {
+			return common.Hash{}, fmt.Errorf("failed to acquire processing slot: %w", err)
+		}
+		defer release()
+	}
+
 	m.log.Debug("process block", "num", header.Number, "hash", header.Hash().Hex())
 
-	if err := m.processor.ProcessBlock(ctx, header); err != nil {
-		return fmt.Errorf("failed to process block: %w", err)
+	digest, err := m.processor.ProcessBlock(ctx, header)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to process block: %w", err)
 	}
 
-	m.log.Info("block verified", "num", header.Number, "hash", header.Hash().Hex())
+	m.log.Info("block verified", "num", header.Number, "hash", header.Hash().Hex(), "digest", digest.Hex())
+	return digest, nil
+}
+
+// loadCursor loads the last block acknowledged
+// before the start, if it may be replayed.
+func (m *Monitor) loadCursor() error {
+	if _, ok := m.processor.(Replayer); !ok || m.cursors == nil {
+		return nil
+	}
+
+	cursor, err := m.cursors.Get(m.name)
+	if errors.Is(err, ethstore.ErrCursorNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load cursor: %w", err)
+	}
+
+	m.log.Info("resume after acknowledged block", "num", cursor.Number, "hash", cursor.Hash.Hex())
+	m.cursor = cursor
 	return nil
 }
+
+// replayBlock returns the digest of the specified
+// block, if it was acknowledged before the start,
+// and can be replayed from the store.
+func (m *Monitor) replayBlock(head *types.Header) (common.Hash, bool) {
+	if m.cursor == nil || head.Number.Uint64() > m.cursor.Number {
+		m.cursor = nil
+		return common.Hash{}, false
+	}
+	if head.Number.Uint64() == m.cursor.Number && head.Hash() != m.cursor.Hash {
+		m.log.Warn("acknowledged block reorged, process again", "num", head.Number, "hash", head.Hash().Hex(), "acknowledged", m.cursor.Hash.Hex())
+		m.cursor = nil
+		return common.Hash{}, false
+	}
+
+	digest, ok, err := m.processor.(Replayer).Replay(head)
+	if err != nil || !ok {
+		if err != nil {
+			m.log.Warn("failed to replay block, process again", "num", head.Number, "hash", head.Hash().Hex(), "err", err)
+		}
+		m.cursor = nil
+		return common.Hash{}, false
+	}
+
+	m.log.Debug("replay acknowledged block", "num", head.Number, "hash", head.Hash().Hex())
+	return digest, true
+}
+
+// acknowledge records the specified block
+// as the last block processed successfully.
+func (m *Monitor) acknowledge(head *types.Header) {
+	if m.cursors == nil {
+		return
+	}
+
+	err := m.cursors.Put(m.name, &ethstore.Cursor{
+		Number: head.Number.Uint64(),
+		Hash:   head.Hash(),
+	})
+	if err != nil {
+		m.log.Warn("failed to acknowledge block", "num", head.Number, "hash", head.Hash().Hex(), "err", err)
+	}
+}
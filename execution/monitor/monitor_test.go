@@ -0,0 +1,204 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/mclock"
+	"github.com/ethereum/go-ethereum/core/types"
+	"log/slog"
+	"math/big"
+	"sparseth/bus"
+	"sparseth/ethstore"
+	"sparseth/execution/ethclient"
+	"sparseth/internal/log"
+	"sparseth/storage/mem"
+	"testing"
+	"time"
+)
+
+type testProcessor struct {
+	// errs are returned by the
+	// first attempts, in order.
+	errs     []error
+	attempts int
+}
+
+func (p *testProcessor) ProcessBlock(_ context.Context, head *types.Header) (common.Hash, error) {
+	p.attempts++
+	if p.attempts <= len(p.errs) {
+		return common.Hash{}, p.errs[p.attempts-1]
+	}
+	return head.Hash(), nil
+}
+
+func TestMonitor_RunContext(t *testing.T) {
+	testLogger := log.New(slog.DiscardHandler)
+	head := &types.Header{Number: big.NewInt(1)}
+
+	// run processes a single block with the specified
+	// retries, and returns its result along with the
+	// dead letters recorded
+	run := func(t *testing.T, proc Processor, retries int) (*bus.Result, []*ethstore.DeadLetter) {
+		sub := make(chan *types.Header, 1)
+		sub <- head
+		close(sub)
+
+		results := bus.NewTopic[*bus.Result]("results", testLogger)
+		ch := results.Subscribe("test")
+		deadLetters := ethstore.NewDeadLetterStore(mem.New())
+
+		m := NewMonitor("test", sub, proc, nil, results, testLogger)
+		m.SetRetries(retries)
+		m.SetDeadLetters(deadLetters)
+		m.retryDelay = time.Millisecond
+		if err := m.RunContext(t.Context()); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		letters, err := deadLetters.Since(0)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		return <-ch, letters
+	}
+
+	t.Run("should retry failed block", func(t *testing.T) {
+		proc := &testProcessor{errs: []error{errors.New("unavailable")}}
+
+		res, letters := run(t, proc, 1)
+		if res.Err != nil || res.Digest != head.Hash() {
+			t.Errorf("expected verified block, got %v", res.Err)
+		}
+		if proc.attempts != 2 || len(letters) != 0 {
+			t.Errorf("expected 2 attempts and no dead letters, got %d and %d", proc.attempts, len(letters))
+		}
+	})
+
+	t.Run("should record dead letter after all retries", func(t *testing.T) {
+		err := errors.New("unavailable")
+		proc := &testProcessor{errs: []error{err, err, err}}
+
+		res, letters := run(t, proc, 2)
+		if res.Err == nil {
+			t.Errorf("expected failed block")
+		}
+		if len(letters) != 1 || letters[0].Attempts != 3 || letters[0].Monitor != "test" || letters[0].Hash != head.Hash() {
+			t.Fatalf("expected dead letter after 3 attempts, got %+v", letters)
+		}
+	})
+
+	t.Run("should not retry divergence", func(t *testing.T) {
+		proc := &testProcessor{errs: []error{ethclient.ErrDivergence}}
+
+		_, letters := run(t, proc, 2)
+		if proc.attempts != 1 || len(letters) != 1 {
+			t.Errorf("expected 1 attempt and 1 dead letter, got %d and %d", proc.attempts, len(letters))
+		}
+	})
+}
+
+func TestMonitor_SetBackfill(t *testing.T) {
+	testLogger := log.New(slog.DiscardHandler)
+
+	t.Run("should backfill dropped blocks from store", func(t *testing.T) {
+		headers := ethstore.NewHeaderStore(mem.New())
+		chain := make([]*types.Header, 4)
+		for i := range chain {
+			chain[i] = &types.Header{Number: big.NewInt(int64(i + 1))}
+			if err := headers.Put(chain[i]); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+		}
+
+		// Blocks 2 and 3 are dropped
+		sub := make(chan *types.Header, 2)
+		sub <- chain[0]
+		sub <- chain[3]
+		close(sub)
+
+		results := bus.NewTopic[*bus.Result]("results", testLogger)
+		ch := results.Subscribe("test")
+
+		m := NewMonitor("test", sub, &testProcessor{}, nil, results, testLogger)
+		m.SetBackfill(headers)
+		if err := m.RunContext(t.Context()); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		for _, h := range chain {
+			res := <-ch
+			if res.Number != h.Number.Uint64() || res.Hash != h.Hash() || !res.Verified() {
+				t.Errorf("expected verified block %d, got block %d", h.Number, res.Number)
+			}
+		}
+	})
+}
+
+func TestMonitor_Halt(t *testing.T) {
+	testLogger := log.New(slog.DiscardHandler)
+
+	t.Run("should halt at failed block until processed", func(t *testing.T) {
+		chain := make([]*types.Header, 3)
+		sub := make(chan *types.Header, len(chain))
+		for i := range chain {
+			chain[i] = &types.Header{Number: big.NewInt(int64(i + 1))}
+			sub <- chain[i]
+		}
+		close(sub)
+
+		results := bus.NewTopic[*bus.Result]("results", testLogger)
+		ch := results.Subscribe("test")
+
+		// Block 1 fails, and again before block 2
+		err := errors.New("unavailable")
+		proc := &testProcessor{errs: []error{err, err}}
+		m := NewMonitor("test", sub, proc, nil, results, testLogger)
+		if err := m.RunContext(t.Context()); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if res := <-ch; res.Number != 1 || res.Err == nil {
+			t.Errorf("expected failed block 1, got block %d", res.Number)
+		}
+		if res := <-ch; res.Number != 2 || !errors.Is(res.Err, ErrHalted) {
+			t.Errorf("expected halted block 2, got block %d with %v", res.Number, res.Err)
+		}
+		if res := <-ch; res.Number != 3 || !res.Verified() {
+			t.Errorf("expected verified block 3, got block %d with %v", res.Number, res.Err)
+		}
+		if proc.attempts != 4 {
+			t.Errorf("expected 4 attempts, got %d", proc.attempts)
+		}
+	})
+}
+
+func TestMonitor_SetClock(t *testing.T) {
+	testLogger := log.New(slog.DiscardHandler)
+
+	t.Run("should delay retries on clock", func(t *testing.T) {
+		sub := make(chan *types.Header, 1)
+		sub <- &types.Header{Number: big.NewInt(1)}
+		close(sub)
+
+		results := bus.NewTopic[*bus.Result]("results", testLogger)
+		ch := results.Subscribe("test")
+
+		clock := new(mclock.Simulated)
+		proc := &testProcessor{errs: []error{errors.New("unavailable")}}
+		m := NewMonitor("test", sub, proc, nil, results, testLogger)
+		m.SetRetries(1)
+		m.SetClock(clock)
+		go m.RunContext(t.Context())
+
+		clock.WaitForTimers(1)
+		clock.Run(retryDelay)
+
+		if res := <-ch; !res.Verified() {
+			t.Errorf("expected verified block, got %v", res.Err)
+		}
+		if proc.attempts != 2 {
+			t.Errorf("expected 2 attempts, got %d", proc.attempts)
+		}
+	})
+}
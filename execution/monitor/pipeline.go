@@ -0,0 +1,80 @@
+package monitor
+
+import (
+	"context"
+	"github.com/ethereum/go-ethereum/core/types"
+	"sparseth/log"
+)
+
+// Preparer defines the interface for preparing
+// blocks ahead of processing, e.g., downloading
+// their transactions and proofs.
+type Preparer interface {
+	// Prepare prepares a single block header.
+	Prepare(ctx context.Context, head *types.Header) error
+}
+
+// Pipeline prepares blocks ahead of a monitor. Each
+// block is forwarded to the monitor in order once it
+// is prepared, so the next block is prepared while the
+// monitor processes the current one.
+type Pipeline struct {
+	log log.Logger
+	// in is the channel for receiving
+	// new block headers.
+	in <-chan *types.Header
+	// out forwards prepared block
+	// headers to the monitor.
+	out chan *types.Header
+	// preparer prepares blocks
+	// ahead of processing
+	preparer Preparer
+}
+
+// NewPipeline creates a new Pipeline preparing
+// all headers received on the specified channel
+// with the specified preparer.
+func NewPipeline(ch <-chan *types.Header, preparer Preparer, log log.Logger) *Pipeline {
+	return &Pipeline{
+		log:      log.With("component", "pipeline"),
+		in:       ch,
+		out:      make(chan *types.Header),
+		preparer: preparer,
+	}
+}
+
+// Out returns the channel of prepared block
+// headers, which is closed once the pipeline
+// stops.
+func (p *Pipeline) Out() <-chan *types.Header {
+	return p.out
+}
+
+// RunContext prepares and forwards blocks
+// until the context is canceled, or the
+// input channel is closed.
+func (p *Pipeline) RunContext(ctx context.Context) error {
+	defer close(p.out)
+
+	for {
+		select {
+		case head, ok := <-p.in:
+			if !ok {
+				return nil
+			}
+			if err := p.preparer.Prepare(ctx, head); err != nil {
+				// Preparing is an optimization only,
+				// the monitor processes the block anyway
+				p.log.Debug("failed to prepare block", "num", head.Number, "hash", head.Hash().Hex(), "err", err)
+			}
+
+			select {
+			case p.out <- head:
+			case <-ctx.Done():
+				return nil
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"github.com/ethereum/go-ethereum/core/types"
+	"log/slog"
+	"math/big"
+	"sparseth/internal/log"
+	"sync"
+	"testing"
+	"time"
+)
+
+type testPreparer struct {
+	prepared []uint64
+	err      error
+	mu       sync.Mutex
+}
+
+func (p *testPreparer) Prepare(_ context.Context, head *types.Header) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.prepared = append(p.prepared, head.Number.Uint64())
+	return p.err
+}
+
+func (p *testPreparer) count() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return len(p.prepared)
+}
+
+func TestPipeline_RunContext(t *testing.T) {
+	testLogger := log.New(slog.DiscardHandler)
+
+	t.Run("should forward prepared blocks in order", func(t *testing.T) {
+		in := make(chan *types.Header, 3)
+		for num := range 3 {
+			in <- &types.Header{Number: big.NewInt(int64(num))}
+		}
+		close(in)
+
+		preparer := &testPreparer{}
+		p := NewPipeline(in, preparer, testLogger)
+		go p.RunContext(t.Context())
+
+		var got []uint64
+		for head := range p.Out() {
+			got = append(got, head.Number.Uint64())
+		}
+
+		if len(got) != 3 || got[0] != 0 || got[1] != 1 || got[2] != 2 {
+			t.Errorf("expected blocks 0, 1, 2, got %v", got)
+		}
+		if preparer.count() != 3 {
+			t.Errorf("expected 3 prepared blocks, got %d", preparer.count())
+		}
+	})
+
+	t.Run("should forward block if preparing fails", func(t *testing.T) {
+		in := make(chan *types.Header, 1)
+		in <- &types.Header{Number: big.NewInt(1)}
+		close(in)
+
+		p := NewPipeline(in, &testPreparer{err: errors.New("unavailable")}, testLogger)
+		go p.RunContext(t.Context())
+
+		head, ok := <-p.Out()
+		if !ok || head.Number.Uint64() != 1 {
+			t.Errorf("expected block 1")
+		}
+	})
+
+	t.Run("should prepare next block while current is processed", func(t *testing.T) {
+		in := make(chan *types.Header, 2)
+		in <- &types.Header{Number: big.NewInt(1)}
+		in <- &types.Header{Number: big.NewInt(2)}
+
+		preparer := &testPreparer{}
+		p := NewPipeline(in, preparer, testLogger)
+		go p.RunContext(t.Context())
+
+		// Take the first block, but do not
+		// take the second one yet
+		<-p.Out()
+
+		deadline := time.After(time.Second)
+		for preparer.count() < 2 {
+			select {
+			case <-deadline:
+				t.Fatalf("expected next block to be prepared")
+			case <-time.After(10 * time.Millisecond):
+			}
+		}
+	})
+}
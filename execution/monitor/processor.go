@@ -2,12 +2,27 @@ package monitor
 
 import (
 	"context"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 )
 
 // Processor defines the core interface
 // for processing Ethereum block headers.
 type Processor interface {
-	// ProcessBlock handles a single block header.
-	ProcessBlock(ctx context.Context, head *types.Header) error
+	// ProcessBlock handles a single block header,
+	// and returns a digest of the verified outputs,
+	// see Digest.
+	ProcessBlock(ctx context.Context, head *types.Header) (common.Hash, error)
+}
+
+// Replayer is implemented by processors that can
+// restore the digest of an already processed block
+// from the store, e.g., as the consensus client
+// replays blocks after a restart.
+type Replayer interface {
+	// Replay returns the digest of the specified
+	// block, which was processed before, or false
+	// if the block must be processed again, e.g.,
+	// after a reorg.
+	Replay(head *types.Header) (common.Hash, bool, error)
 }
@@ -0,0 +1,96 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"sparseth/bus"
+	"sparseth/config"
+	"sparseth/execution/ethclient"
+	"sparseth/log"
+	"sync"
+)
+
+// Upgrade is a verified change of the
+// implementation of a proxy.
+type Upgrade struct {
+	// Proxy is the upgraded proxy.
+	Proxy common.Address
+	// From is the previous implementation.
+	From common.Address
+	// To is the new implementation.
+	To common.Address
+}
+
+// ProxyChecker reads the verified implementation of
+// each monitored proxy after each block, and raises
+// an alert once the implementation changes.
+//
+// The implementation a proxy was configured with is
+// taken as its initial implementation, otherwise the
+// first verified implementation is.
+type ProxyChecker struct {
+	provider ethclient.Provider
+	alerts   *bus.Topic[*bus.Alert]
+	log      log.Logger
+
+	// impls holds the last verified
+	// implementation of each proxy.
+	impls map[common.Address]common.Address
+	mu    sync.Mutex
+}
+
+// NewProxyChecker creates a new ProxyChecker that
+// reads implementations via the specified provider,
+// and publishes upgrades to the specified alerts
+// topic.
+func NewProxyChecker(provider ethclient.Provider, alerts *bus.Topic[*bus.Alert], log log.Logger) *ProxyChecker {
+	return &ProxyChecker{
+		provider: provider,
+		alerts:   alerts,
+		log:      log.With("component", "proxy-checker"),
+		impls:    make(map[common.Address]common.Address),
+	}
+}
+
+// Check reads the verified implementation of each
+// of the specified accounts that is a proxy at the
+// specified block, and returns the upgrades since
+// the last check.
+func (c *ProxyChecker) Check(ctx context.Context, head *types.Header, accs []*config.AccountConfig) ([]*Upgrade, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	upgrades := make([]*Upgrade, 0)
+	for _, acc := range accs {
+		if acc.Proxy == nil || !acc.StartedAt(head.Number.Uint64()) {
+			continue
+		}
+
+		val, err := c.provider.GetStorageAtBlock(ctx, acc.Addr, acc.Proxy.ImplementationSlot, head)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read implementation of %s at block %d: %w", acc.Addr.Hex(), head.Number.Uint64(), err)
+		}
+		impl := common.BytesToAddress(val)
+
+		prev, known := c.impls[acc.Addr]
+		if !known && acc.Proxy.Implementation != (common.Address{}) {
+			prev, known = acc.Proxy.Implementation, true
+		}
+		c.impls[acc.Addr] = impl
+		if !known || prev == impl {
+			continue
+		}
+
+		c.log.Warn("proxy implementation changed", "proxy", acc.Addr.Hex(), "from", prev.Hex(), "to", impl.Hex(), "num", head.Number, "hash", head.Hash().Hex())
+		alert := NewAlert("proxy-checker", acc.Addr, head, fmt.Errorf("implementation of proxy %s changed from %s to %s", acc.Addr.Hex(), prev.Hex(), impl.Hex()))
+		alert.Kind = "implementation"
+		alert.Expected = prev.Hex()
+		alert.Actual = impl.Hex()
+		c.alerts.Publish(alert)
+
+		upgrades = append(upgrades, &Upgrade{Proxy: acc.Addr, From: prev, To: impl})
+	}
+	return upgrades, nil
+}
@@ -0,0 +1,76 @@
+package monitor
+
+import (
+	"context"
+	"log/slog"
+	"math/big"
+	"sparseth/bus"
+	"sparseth/config"
+	"sparseth/execution/ethclient"
+	"sparseth/internal/log"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+type testSlotProvider struct {
+	ethclient.Provider
+	slots map[common.Address]common.Hash
+}
+
+func (p *testSlotProvider) GetStorageAtBlock(_ context.Context, acc common.Address, _ common.Hash, _ *types.Header) ([]byte, error) {
+	return p.slots[acc].Bytes(), nil
+}
+
+func TestProxyChecker_Check(t *testing.T) {
+	testLogger := log.New(slog.DiscardHandler)
+	proxy := common.HexToAddress("0xaa")
+	implA := common.HexToAddress("0x0a")
+	implB := common.HexToAddress("0x0b")
+	head := &types.Header{Number: big.NewInt(1)}
+
+	t.Run("should not alert on first implementation", func(t *testing.T) {
+		alerts := bus.NewTopic[*bus.Alert]("alerts", testLogger)
+		sub := alerts.Subscribe("test")
+		provider := &testSlotProvider{slots: map[common.Address]common.Hash{proxy: common.BytesToHash(implA.Bytes())}}
+		accs := []*config.AccountConfig{{Addr: proxy, Proxy: &config.ProxyConfig{ImplementationSlot: config.EIP1967ImplementationSlot}}}
+
+		upgrades, err := NewProxyChecker(provider, alerts, testLogger).Check(t.Context(), head, accs)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(upgrades) != 0 || len(sub) != 0 {
+			t.Errorf("expected no upgrades, got %d", len(upgrades))
+		}
+	})
+
+	t.Run("should alert on changed implementation", func(t *testing.T) {
+		alerts := bus.NewTopic[*bus.Alert]("alerts", testLogger)
+		sub := alerts.Subscribe("test")
+		provider := &testSlotProvider{slots: map[common.Address]common.Hash{proxy: common.BytesToHash(implB.Bytes())}}
+		accs := []*config.AccountConfig{{Addr: proxy, Proxy: &config.ProxyConfig{ImplementationSlot: config.EIP1967ImplementationSlot, Implementation: implA}}}
+		checker := NewProxyChecker(provider, alerts, testLogger)
+
+		upgrades, err := checker.Check(t.Context(), head, accs)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(upgrades) != 1 || upgrades[0].From != implA || upgrades[0].To != implB {
+			t.Fatalf("expected upgrade from %s to %s, got %+v", implA.Hex(), implB.Hex(), upgrades)
+		}
+		if alert := <-sub; alert.Kind != "implementation" || alert.Actual != implB.Hex() {
+			t.Errorf("expected implementation alert, got %+v", alert)
+		}
+
+		// The new implementation is
+		// not alerted again
+		upgrades, err = checker.Check(t.Context(), head, accs)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(upgrades) != 0 {
+			t.Errorf("expected no upgrades, got %d", len(upgrades))
+		}
+	})
+}
@@ -0,0 +1,107 @@
+package monitor
+
+import (
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"math/big"
+	"sparseth/bus"
+	"sparseth/config"
+	"sparseth/ethstore"
+	"sparseth/log"
+	"sync"
+)
+
+// RuleChecker evaluates the alert rules of the
+// monitored accounts against the verified state
+// after each block, see config.AlertRules.
+//
+// A low balance is only alerted again after the
+// balance recovered for at least one block, so
+// that a persistently low balance raises a single
+// alert.
+type RuleChecker struct {
+	alerts *bus.Topic[*bus.Alert]
+	log    log.Logger
+
+	// low holds the accounts whose balance
+	// is below their minimum balance.
+	low map[common.Address]bool
+	mu  sync.Mutex
+}
+
+// NewRuleChecker creates a new RuleChecker that
+// publishes violated rules to the specified
+// alerts topic.
+func NewRuleChecker(alerts *bus.Topic[*bus.Alert], log log.Logger) *RuleChecker {
+	return &RuleChecker{
+		alerts: alerts,
+		log:    log.With("component", "rule-checker"),
+		low:    make(map[common.Address]bool),
+	}
+}
+
+// Check evaluates the alert rules of the specified
+// accounts against the specified state and changes
+// within the specified block, and returns the
+// raised alerts.
+func (c *RuleChecker) Check(head *types.Header, state StateReader, diffs []*ethstore.AccountDiff, accs []*config.AccountConfig) []*bus.Alert {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	changed := make(map[common.Address]*ethstore.AccountDiff, len(diffs))
+	for _, diff := range diffs {
+		changed[diff.Address] = diff
+	}
+
+	raised := make([]*bus.Alert, 0)
+	for _, acc := range accs {
+		rules := acc.Alerts
+		if rules == nil || !acc.StartedAt(head.Number.Uint64()) {
+			continue
+		}
+
+		if rules.MinBalance != nil {
+			balance := state.GetBalance(acc.Addr).ToBig()
+			low := balance.Cmp(rules.MinBalance) < 0
+			if low && !c.low[acc.Addr] {
+				raised = append(raised, newRuleAlert(acc.Addr, head, "min-balance", rules.MinBalance.String(), balance.String(),
+					fmt.Errorf("balance of %s dropped below %s wei: %s wei", acc.Addr.Hex(), rules.MinBalance, balance)))
+			}
+			c.low[acc.Addr] = low
+		}
+
+		diff, ok := changed[acc.Addr]
+		if !ok {
+			continue
+		}
+		if rules.MaxOutgoing != nil && diff.BalanceBefore != nil && diff.BalanceAfter != nil {
+			outgoing := new(big.Int).Sub(diff.BalanceBefore, diff.BalanceAfter)
+			if outgoing.Cmp(rules.MaxOutgoing) > 0 {
+				raised = append(raised, newRuleAlert(acc.Addr, head, "max-outgoing", rules.MaxOutgoing.String(), outgoing.String(),
+					fmt.Errorf("outgoing value of %s exceeded %s wei: %s wei", acc.Addr.Hex(), rules.MaxOutgoing, outgoing)))
+			}
+		}
+		if rules.CodeChange && diff.CodeHashBefore != diff.CodeHashAfter {
+			raised = append(raised, newRuleAlert(acc.Addr, head, "code-change", diff.CodeHashBefore.Hex(), diff.CodeHashAfter.Hex(),
+				fmt.Errorf("code of %s changed", acc.Addr.Hex())))
+		}
+	}
+
+	for _, alert := range raised {
+		c.log.Warn("alert rule violated", "account", alert.Account.Hex(), "rule", alert.Kind, "expected", alert.Expected, "actual", alert.Actual, "num", head.Number, "hash", head.Hash().Hex())
+		c.alerts.Publish(alert)
+	}
+	return raised
+}
+
+// newRuleAlert returns an alert of the specified
+// kind, e.g., min-balance, for the specified
+// account at the specified block.
+func newRuleAlert(addr common.Address, head *types.Header, kind, expected, actual string, err error) *bus.Alert {
+	alert := NewAlert("rule-checker", addr, head, err)
+	alert.Kind = kind
+	alert.Expected = expected
+	alert.Actual = actual
+	return alert
+}
@@ -0,0 +1,75 @@
+package monitor
+
+import (
+	"log/slog"
+	"math/big"
+	"sparseth/bus"
+	"sparseth/config"
+	"sparseth/ethstore"
+	"sparseth/internal/log"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/holiman/uint256"
+)
+
+func TestRuleChecker_Check(t *testing.T) {
+	testLogger := log.New(slog.DiscardHandler)
+	addr := common.HexToAddress("0xaa")
+	head := &types.Header{Number: big.NewInt(1)}
+
+	t.Run("should alert once on low balance", func(t *testing.T) {
+		alerts := bus.NewTopic[*bus.Alert]("alerts", testLogger)
+		accs := []*config.AccountConfig{{Addr: addr, Alerts: &config.AlertRules{MinBalance: big.NewInt(100)}}}
+		state := &testState{balance: uint256.NewInt(99)}
+		checker := NewRuleChecker(alerts, testLogger)
+
+		raised := checker.Check(head, state, nil, accs)
+		if len(raised) != 1 || raised[0].Kind != "min-balance" || raised[0].Actual != "99" {
+			t.Fatalf("expected min-balance alert, got %+v", raised)
+		}
+		if raised = checker.Check(head, state, nil, accs); len(raised) != 0 {
+			t.Errorf("expected no repeated alert, got %d", len(raised))
+		}
+
+		// Once recovered, a low
+		// balance is alerted again
+		state.balance = uint256.NewInt(100)
+		checker.Check(head, state, nil, accs)
+		state.balance = uint256.NewInt(1)
+		if raised = checker.Check(head, state, nil, accs); len(raised) != 1 {
+			t.Errorf("expected another alert, got %d", len(raised))
+		}
+	})
+
+	t.Run("should alert on outgoing value and code change", func(t *testing.T) {
+		alerts := bus.NewTopic[*bus.Alert]("alerts", testLogger)
+		sub := alerts.Subscribe("test")
+		accs := []*config.AccountConfig{{Addr: addr, Alerts: &config.AlertRules{MaxOutgoing: big.NewInt(10), CodeChange: true}}}
+		diffs := []*ethstore.AccountDiff{{
+			Address:        addr,
+			BalanceBefore:  big.NewInt(100),
+			BalanceAfter:   big.NewInt(89),
+			CodeHashBefore: types.EmptyCodeHash,
+			CodeHashAfter:  common.HexToHash("0xcc"),
+		}}
+
+		raised := NewRuleChecker(alerts, testLogger).Check(head, &testState{}, diffs, accs)
+		if len(raised) != 2 || raised[0].Kind != "max-outgoing" || raised[0].Actual != "11" || raised[1].Kind != "code-change" {
+			t.Fatalf("expected max-outgoing and code-change alerts, got %+v", raised)
+		}
+		if len(sub) != 2 {
+			t.Errorf("expected 2 published alerts, got %d", len(sub))
+		}
+	})
+
+	t.Run("should ignore accounts without rules", func(t *testing.T) {
+		alerts := bus.NewTopic[*bus.Alert]("alerts", testLogger)
+		accs := []*config.AccountConfig{{Addr: addr}}
+
+		if raised := NewRuleChecker(alerts, testLogger).Check(head, &testState{balance: uint256.NewInt(0)}, nil, accs); len(raised) != 0 {
+			t.Errorf("expected no alerts, got %d", len(raised))
+		}
+	})
+}
@@ -0,0 +1,50 @@
+package monitor
+
+import (
+	"encoding/binary"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Sampler selects a pseudo-random sample of
+// blocks to verify, with about one in rate
+// blocks being sampled.
+//
+// A block is sampled based on its hash and a
+// secret seed, so that a provider cannot tell
+// which blocks are verified. Once the seed is
+// disclosed, anyone can check which blocks
+// were sampled against the commitment.
+type Sampler struct {
+	rate uint64
+	seed common.Hash
+}
+
+// NewSampler creates a new Sampler that samples
+// about one in rate blocks using the specified
+// seed. A rate of at most one samples all blocks.
+func NewSampler(rate uint64, seed common.Hash) *Sampler {
+	return &Sampler{
+		rate: rate,
+		seed: seed,
+	}
+}
+
+// Sampled checks whether the
+// specified block is sampled.
+func (s *Sampler) Sampled(head *types.Header) bool {
+	if s.rate <= 1 {
+		return true
+	}
+
+	hash := crypto.Keccak256(s.seed.Bytes(), head.Hash().Bytes())
+	return binary.BigEndian.Uint64(hash[:8])%s.rate == 0
+}
+
+// Commitment returns the hash of the seed,
+// which can be published without revealing
+// the sampled blocks in advance.
+func (s *Sampler) Commitment() common.Hash {
+	return crypto.Keccak256Hash(s.seed.Bytes())
+}
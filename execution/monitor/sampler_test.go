@@ -0,0 +1,51 @@
+package monitor
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestSampler_Sampled(t *testing.T) {
+	headers := make([]*types.Header, 1000)
+	for i := range headers {
+		headers[i] = &types.Header{Number: big.NewInt(int64(i))}
+	}
+
+	t.Run("should sample all blocks without rate", func(t *testing.T) {
+		s := NewSampler(1, common.HexToHash("0x01"))
+		for _, h := range headers {
+			if !s.Sampled(h) {
+				t.Fatalf("expected block %d to be sampled", h.Number)
+			}
+		}
+	})
+
+	t.Run("should sample about one in rate blocks", func(t *testing.T) {
+		s := NewSampler(10, common.HexToHash("0x01"))
+
+		sampled := 0
+		for _, h := range headers {
+			if s.Sampled(h) {
+				sampled++
+			}
+		}
+		if sampled < 50 || sampled > 150 {
+			t.Errorf("expected about 100 sampled blocks, got %d", sampled)
+		}
+	})
+
+	t.Run("should depend on seed", func(t *testing.T) {
+		a := NewSampler(10, common.HexToHash("0x01"))
+		b := NewSampler(10, common.HexToHash("0x02"))
+
+		for _, h := range headers {
+			if a.Sampled(h) != b.Sampled(h) {
+				return
+			}
+		}
+		t.Errorf("expected different samples for different seeds")
+	})
+}
@@ -0,0 +1,155 @@
+package monitor
+
+import (
+	"context"
+	"sync"
+)
+
+// Scheduler bounds the number of blocks that are
+// processed concurrently across all monitors it
+// is shared with.
+//
+// If more monitors are waiting than slots are
+// available, a free slot is granted to the
+// monitor that was served least recently, so
+// that a busy monitor cannot starve the others.
+type Scheduler struct {
+	limit  int
+	active int
+	// waiting holds all pending slot requests
+	// in arrival order.
+	waiting []*ticket
+	// served holds the sequence number of the
+	// last slot granted to each monitor.
+	served map[string]uint64
+	seq    uint64
+	mu     sync.Mutex
+}
+
+// ticket is a pending request for a slot.
+type ticket struct {
+	name  string
+	ready chan struct{}
+}
+
+// NewScheduler creates a new Scheduler that
+// allows at most limit blocks to be processed
+// concurrently. A non-positive limit is treated
+// as a limit of one.
+func NewScheduler(limit int) *Scheduler {
+	if limit < 1 {
+		limit = 1
+	}
+
+	return &Scheduler{
+		limit:  limit,
+		served: make(map[string]uint64),
+	}
+}
+
+// Acquire blocks until the monitor with the specified
+// name is granted a slot, or the context is canceled.
+// On success, the returned function must be called to
+// release the slot once the block is processed.
+func (s *Scheduler) Acquire(ctx context.Context, name string) (func(), error) {
+	s.mu.Lock()
+	if s.active < s.limit && len(s.waiting) == 0 {
+		s.grant(name)
+		s.mu.Unlock()
+		return s.release, nil
+	}
+
+	t := &ticket{
+		name:  name,
+		ready: make(chan struct{}),
+	}
+	s.waiting = append(s.waiting, t)
+	s.mu.Unlock()
+
+	select {
+	case <-t.ready:
+		return s.release, nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		select {
+		case <-t.ready:
+			// Slot was granted concurrently,
+			// hand it over to the next monitor
+			s.active--
+			s.dispatch()
+		default:
+			s.remove(t)
+		}
+		return nil, ctx.Err()
+	}
+}
+
+// Queued returns the number of monitors
+// currently waiting for a slot.
+func (s *Scheduler) Queued() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.waiting)
+}
+
+// Limit returns the maximum number of
+// blocks processed concurrently.
+func (s *Scheduler) Limit() int {
+	return s.limit
+}
+
+// release frees a slot and grants
+// it to the next waiting monitor.
+func (s *Scheduler) release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.active--
+	s.dispatch()
+}
+
+// dispatch grants free slots to waiting monitors,
+// preferring the least recently served ones.
+//
+// Note that the caller must hold the lock.
+func (s *Scheduler) dispatch() {
+	for s.active < s.limit && len(s.waiting) > 0 {
+		next := 0
+		for i, t := range s.waiting {
+			if s.served[t.name] < s.served[s.waiting[next].name] {
+				next = i
+			}
+		}
+
+		t := s.waiting[next]
+		s.waiting = append(s.waiting[:next], s.waiting[next+1:]...)
+		s.grant(t.name)
+		close(t.ready)
+	}
+}
+
+// grant marks a slot as taken by the
+// monitor with the specified name.
+//
+// Note that the caller must hold the lock.
+func (s *Scheduler) grant(name string) {
+	s.active++
+	s.seq++
+	s.served[name] = s.seq
+}
+
+// remove drops the specified ticket
+// from the waiting list.
+//
+// Note that the caller must hold the lock.
+func (s *Scheduler) remove(t *ticket) {
+	for i, w := range s.waiting {
+		if w == t {
+			s.waiting = append(s.waiting[:i], s.waiting[i+1:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package monitor
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestScheduler_Acquire(t *testing.T) {
+	t.Run("should grant slots up to limit", func(t *testing.T) {
+		s := NewScheduler(2)
+
+		if _, err := s.Acquire(t.Context(), "first"); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if _, err := s.Acquire(t.Context(), "second"); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+		defer cancel()
+
+		if _, err := s.Acquire(ctx, "third"); err == nil {
+			t.Errorf("expected error, got nil")
+		}
+	})
+
+	t.Run("should grant slot after release", func(t *testing.T) {
+		s := NewScheduler(1)
+
+		release, err := s.Acquire(t.Context(), "first")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		done := make(chan struct{})
+		go func() {
+			if _, err := s.Acquire(t.Context(), "second"); err == nil {
+				close(done)
+			}
+		}()
+
+		release()
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Errorf("timeout: slot was not granted")
+		}
+	})
+
+	t.Run("should prefer least recently served monitor", func(t *testing.T) {
+		s := NewScheduler(1)
+
+		// 'busy' is served first, 'quiet' has never been served
+		release, err := s.Acquire(t.Context(), "busy")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		order := make(chan string, 2)
+		waitFor := func(name string) {
+			r, err := s.Acquire(t.Context(), name)
+			if err != nil {
+				return
+			}
+			order <- name
+			r()
+		}
+
+		go waitFor("busy")
+		waitForWaiting(t, s, 1)
+		go waitFor("quiet")
+		waitForWaiting(t, s, 2)
+
+		release()
+
+		for _, expected := range []string{"quiet", "busy"} {
+			select {
+			case got := <-order:
+				if got != expected {
+					t.Errorf("expected %s, got %s", expected, got)
+				}
+			case <-time.After(time.Second):
+				t.Fatalf("timeout: slot was not granted")
+			}
+		}
+	})
+
+	t.Run("should not leak slot on cancellation", func(t *testing.T) {
+		s := NewScheduler(1)
+
+		release, err := s.Acquire(t.Context(), "first")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		ctx, cancel := context.WithCancel(t.Context())
+		cancel()
+		if _, err = s.Acquire(ctx, "second"); err == nil {
+			t.Fatalf("expected error, got nil")
+		}
+
+		release()
+		if _, err = s.Acquire(t.Context(), "third"); err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+	})
+}
+
+// waitForWaiting blocks until the scheduler
+// has the specified number of waiting tickets.
+func waitForWaiting(t *testing.T, s *Scheduler, n int) {
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		s.mu.Lock()
+		waiting := len(s.waiting)
+		s.mu.Unlock()
+		if waiting >= n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("timeout: expected %d waiting monitors", n)
+}
+
+func TestScheduler_Queued(t *testing.T) {
+	t.Run("should count waiting monitors", func(t *testing.T) {
+		s := NewScheduler(1)
+
+		release, err := s.Acquire(t.Context(), "first")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		granted := make(chan struct{})
+		go func() {
+			if _, err := s.Acquire(t.Context(), "second"); err == nil {
+				close(granted)
+			}
+		}()
+
+		deadline := time.Now().Add(time.Second)
+		for s.Queued() != 1 {
+			if time.Now().After(deadline) {
+				t.Fatalf("expected 1 queued, got %d", s.Queued())
+			}
+			time.Sleep(time.Millisecond)
+		}
+
+		release()
+		<-granted
+
+		if q := s.Queued(); q != 0 {
+			t.Errorf("expected 0 queued, got %d", q)
+		}
+	})
+}
@@ -0,0 +1,83 @@
+package state
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// ReadAllowlist holds the addresses whose uninitialized
+// reads are not verified, as they are known to be read
+// by re-execution without being part of the partial
+// state, e.g., precompiles and system contracts.
+//
+// Note that skipping verification trades soundness for
+// fewer RPC calls: a read of an allowlisted address is
+// assumed to be valid.
+type ReadAllowlist struct {
+	cc *params.ChainConfig
+	// defaults indicates whether the precompiles
+	// and system contracts active at a block are
+	// allowlisted.
+	defaults bool
+	// addrs holds additional
+	// allowlisted addresses.
+	addrs map[common.Address]bool
+}
+
+// NewReadAllowlist creates a new ReadAllowlist with the
+// specified additional addresses. If defaults is set,
+// all precompiles and system contracts active at a block
+// according to the specified chain configuration are
+// allowlisted as well.
+func NewReadAllowlist(cc *params.ChainConfig, defaults bool, addrs []common.Address) *ReadAllowlist {
+	set := make(map[common.Address]bool, len(addrs))
+	for _, addr := range addrs {
+		set[addr] = true
+	}
+
+	return &ReadAllowlist{
+		cc:       cc,
+		defaults: defaults,
+		addrs:    set,
+	}
+}
+
+// Contains checks whether the specified address is
+// allowlisted at the specified block. A nil allowlist
+// contains no addresses.
+func (a *ReadAllowlist) Contains(addr common.Address, head *types.Header) bool {
+	if a == nil {
+		return false
+	}
+	if a.addrs[addr] {
+		return true
+	}
+	if !a.defaults {
+		return false
+	}
+
+	isMerge := head.Difficulty != nil && head.Difficulty.Sign() == 0
+	rules := a.cc.Rules(head.Number, isMerge, head.Time)
+	for _, precompile := range vm.ActivePrecompiles(rules) {
+		if precompile == addr {
+			return true
+		}
+	}
+
+	return isSystemContract(addr, rules)
+}
+
+// isSystemContract checks whether the specified address
+// is a system contract active under the specified rules.
+func isSystemContract(addr common.Address, rules params.Rules) bool {
+	switch addr {
+	case params.BeaconRootsAddress:
+		return rules.IsCancun
+	case params.HistoryStorageAddress, params.WithdrawalQueueAddress, params.ConsolidationQueueAddress:
+		return rules.IsPrague
+	default:
+		return false
+	}
+}
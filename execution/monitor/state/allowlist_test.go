@@ -0,0 +1,72 @@
+package state
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"math/big"
+	"testing"
+)
+
+func TestReadAllowlist_Contains(t *testing.T) {
+	// Prague is active on mainnet
+	// after this timestamp
+	prague := &types.Header{
+		Number:     big.NewInt(22_500_000),
+		Time:       1_750_000_000,
+		Difficulty: big.NewInt(0),
+	}
+	// Cancun is not yet active on
+	// mainnet at this block
+	shanghai := &types.Header{
+		Number:     big.NewInt(18_000_000),
+		Time:       1_693_000_000,
+		Difficulty: big.NewInt(0),
+	}
+
+	t.Run("should contain precompiles by default", func(t *testing.T) {
+		a := NewReadAllowlist(params.MainnetChainConfig, true, nil)
+		if !a.Contains(common.BytesToAddress([]byte{0x01}), prague) {
+			t.Errorf("expected ecrecover precompile to be allowlisted")
+		}
+	})
+
+	t.Run("should contain system contracts once active", func(t *testing.T) {
+		a := NewReadAllowlist(params.MainnetChainConfig, true, nil)
+		if !a.Contains(params.HistoryStorageAddress, prague) {
+			t.Errorf("expected history storage contract to be allowlisted")
+		}
+		if a.Contains(params.BeaconRootsAddress, shanghai) {
+			t.Errorf("expected beacon roots contract not to be allowlisted before cancun")
+		}
+	})
+
+	t.Run("should not contain defaults if disabled", func(t *testing.T) {
+		a := NewReadAllowlist(params.MainnetChainConfig, false, nil)
+		if a.Contains(common.BytesToAddress([]byte{0x01}), prague) {
+			t.Errorf("expected precompile not to be allowlisted")
+		}
+	})
+
+	t.Run("should contain additional addresses", func(t *testing.T) {
+		addr := common.HexToAddress("0xdeadbeefdeadbeefdeadbeefdeadbeefdeadbeef")
+		a := NewReadAllowlist(params.MainnetChainConfig, false, []common.Address{addr})
+		if !a.Contains(addr, prague) {
+			t.Errorf("expected address to be allowlisted")
+		}
+	})
+
+	t.Run("should not contain other addresses", func(t *testing.T) {
+		a := NewReadAllowlist(params.MainnetChainConfig, true, nil)
+		if a.Contains(common.HexToAddress("0xdeadbeefdeadbeefdeadbeefdeadbeefdeadbeef"), prague) {
+			t.Errorf("expected address not to be allowlisted")
+		}
+	})
+
+	t.Run("should contain nothing if nil", func(t *testing.T) {
+		var a *ReadAllowlist
+		if a.Contains(common.BytesToAddress([]byte{0x01}), prague) {
+			t.Errorf("expected nil allowlist to be empty")
+		}
+	})
+}
@@ -0,0 +1,83 @@
+package state
+
+import (
+	"context"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/misc/eip4844"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// validateBlobGas checks the blob gas accounting of
+// the specified transactions against the specified
+// block header.
+//
+// As only relevant transactions are re-executed,
+// their blob gas must not exceed, rather than
+// equal, the blob gas used by the block.
+func validateBlobGas(cc *params.ChainConfig, header *types.Header, txs []*TransactionWithContext) error {
+	var used uint64
+	for _, tx := range txs {
+		if tx.Tx.Type() != types.BlobTxType {
+			continue
+		}
+
+		hashes := tx.Tx.BlobHashes()
+		if len(hashes) == 0 {
+			return fmt.Errorf("blob tx %s has no blobs", tx.Tx.Hash().Hex())
+		}
+		for _, hash := range hashes {
+			if !kzg4844.IsValidVersionedHash(hash[:]) {
+				return fmt.Errorf("blob tx %s has invalid versioned hash %s", tx.Tx.Hash().Hex(), hash.Hex())
+			}
+		}
+		used += tx.Tx.BlobGas()
+	}
+	if used == 0 {
+		return nil
+	}
+
+	if header.BlobGasUsed == nil || header.ExcessBlobGas == nil {
+		return fmt.Errorf("block %d has blob txs but no blob gas fields", header.Number.Uint64())
+	}
+	if limit := eip4844.MaxBlobGasPerBlock(cc, header.Time); used > limit {
+		return fmt.Errorf("blob gas used %d exceeds maximum %d", used, limit)
+	}
+	if used > *header.BlobGasUsed {
+		return fmt.Errorf("blob gas used %d exceeds blob gas used by block %d", used, *header.BlobGasUsed)
+	}
+	return nil
+}
+
+// blobHashes returns the versioned hashes of
+// all blobs of the specified transactions.
+func blobHashes(txs []*TransactionWithContext) []common.Hash {
+	hashes := make([]common.Hash, 0)
+	for _, tx := range txs {
+		hashes = append(hashes, tx.Tx.BlobHashes()...)
+	}
+	return hashes
+}
+
+// verifyBlobs fetches the blobs of the specified
+// transactions of the specified block, verifying
+// them against their KZG commitments. Blobs are
+// only verified if a blob provider is set.
+func (p *TxProcessor) verifyBlobs(ctx context.Context, head *types.Header, txs []*TransactionWithContext) error {
+	if p.blobs == nil {
+		return nil
+	}
+
+	hashes := blobHashes(txs)
+	if len(hashes) == 0 {
+		return nil
+	}
+
+	p.logWithContext(fmt.Sprintf("verify %d blobs for block", len(hashes)), head)
+	if _, err := p.blobs.GetBlobsAtBlock(ctx, head, hashes); err != nil {
+		return fmt.Errorf("failed to verify blobs at block %d: %w", head.Number.Uint64(), err)
+	}
+	return nil
+}
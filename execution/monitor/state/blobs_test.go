@@ -0,0 +1,65 @@
+package state
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"math/big"
+	"testing"
+)
+
+func newBlobTx(hashes ...common.Hash) *TransactionWithContext {
+	return &TransactionWithContext{
+		Tx: types.NewTx(&types.BlobTx{BlobHashes: hashes}),
+	}
+}
+
+func newBlobHeader(blobGasUsed uint64) *types.Header {
+	excess := uint64(0)
+	return &types.Header{
+		Number:        big.NewInt(1),
+		Time:          1800000000,
+		BlobGasUsed:   &blobGasUsed,
+		ExcessBlobGas: &excess,
+	}
+}
+
+func TestValidateBlobGas(t *testing.T) {
+	cc := params.MainnetChainConfig
+	hash := common.Hash{0x01}
+
+	t.Run("should accept blob gas within block blob gas", func(t *testing.T) {
+		txs := []*TransactionWithContext{newBlobTx(hash, hash)}
+		if err := validateBlobGas(cc, newBlobHeader(3*params.BlobTxBlobGasPerBlob), txs); err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("should reject blob gas exceeding block blob gas", func(t *testing.T) {
+		txs := []*TransactionWithContext{newBlobTx(hash, hash)}
+		if err := validateBlobGas(cc, newBlobHeader(params.BlobTxBlobGasPerBlob), txs); err == nil {
+			t.Errorf("expected error, got nil")
+		}
+	})
+
+	t.Run("should reject blob tx without blobs", func(t *testing.T) {
+		txs := []*TransactionWithContext{newBlobTx()}
+		if err := validateBlobGas(cc, newBlobHeader(params.BlobTxBlobGasPerBlob), txs); err == nil {
+			t.Errorf("expected error, got nil")
+		}
+	})
+
+	t.Run("should reject invalid versioned hash", func(t *testing.T) {
+		txs := []*TransactionWithContext{newBlobTx(common.Hash{0x02})}
+		if err := validateBlobGas(cc, newBlobHeader(params.BlobTxBlobGasPerBlob), txs); err == nil {
+			t.Errorf("expected error, got nil")
+		}
+	})
+
+	t.Run("should reject blob tx in block without blob gas", func(t *testing.T) {
+		txs := []*TransactionWithContext{newBlobTx(hash)}
+		if err := validateBlobGas(cc, &types.Header{Number: big.NewInt(1)}, txs); err == nil {
+			t.Errorf("expected error, got nil")
+		}
+	})
+}
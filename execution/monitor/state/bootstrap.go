@@ -0,0 +1,113 @@
+package state
+
+import (
+	"context"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/holiman/uint256"
+	"sparseth/config"
+	"sparseth/execution/ethclient"
+)
+
+// Bootstrap seeds the persistent world state with
+// the proven state of all monitored accounts at the
+// specified checkpoint block, so that completeness
+// checks succeed from the first processed block.
+//
+// The nonce, balance, and code of each account are
+// seeded, along with the configured storage slots.
+// As proofs cannot enumerate storage, an account
+// whose storage holds further slots stays
+// incomplete, and its storage root mismatch is
+// reported until its state is reconstructed. The
+// addresses of incomplete accounts are returned.
+// Accounts whose start block is after the
+// checkpoint are skipped, as they are started
+// with empty state.
+func (p *TxProcessor) Bootstrap(ctx context.Context, head *types.Header) ([]common.Address, error) {
+	p.applyPendingAccounts(head.Number.Uint64())
+	active := p.activeAccounts(head.Number.Uint64())
+
+	reqs := make([]*ethclient.ProofRequest, len(active.Accounts))
+	for i, acc := range active.Accounts {
+		reqs[i] = &ethclient.ProofRequest{
+			Address: acc.Addr,
+			Slots:   knownSlots(acc),
+		}
+	}
+
+	p.logWithContext(fmt.Sprintf("bootstrap %d accounts from proofs", len(reqs)), head)
+	states, err := p.provider.GetAccountsAtBlock(ctx, reqs, head)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch proofs at block %d: %w", head.Number.Uint64(), err)
+	}
+
+	for _, state := range states {
+		if state.Account == nil {
+			continue
+		}
+		if err = p.seed(ctx, state, head); err != nil {
+			p.world.Revert()
+			return nil, err
+		}
+	}
+	p.world.IntermediateRoot(false)
+
+	incomplete := make([]common.Address, 0)
+	for _, state := range states {
+		if state.Account != nil && p.world.GetStorageRoot(state.Account.Address) != state.Account.StorageRoot {
+			incomplete = append(incomplete, state.Account.Address)
+		}
+	}
+
+	root, err := p.world.Commit(head.Number.Uint64(), false, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to commit bootstrapped state: %w", err)
+	}
+	p.world, err = p.world.WithRoot(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create bootstrapped state: %w", err)
+	}
+	if err = p.recordRoot(head, root, types.EmptyReceiptsHash); err != nil {
+		return nil, err
+	}
+	return incomplete, nil
+}
+
+// seed writes the specified proven account
+// state to the persistent world state.
+func (p *TxProcessor) seed(ctx context.Context, state *ethclient.AccountState, head *types.Header) error {
+	acc := state.Account
+	p.world.SetNonce(acc.Address, acc.Nonce, tracing.NonceChangeUnspecified)
+	p.world.SetBalance(acc.Address, uint256.MustFromBig(acc.Balance), tracing.BalanceChangeUnspecified)
+
+	if acc.CodeHash != types.EmptyCodeHash {
+		code, err := p.provider.GetCodeAtBlock(ctx, acc.Address, head)
+		if err != nil {
+			return fmt.Errorf("failed to fetch code of account %s: %w", acc.Address.Hex(), err)
+		}
+		p.world.SetCode(acc.Address, code)
+	}
+
+	for slot, val := range state.Storage {
+		if value := common.BytesToHash(val); value != (common.Hash{}) {
+			p.world.SetState(acc.Address, slot, value)
+		}
+	}
+	return nil
+}
+
+// knownSlots returns the storage slots
+// configured for the specified account.
+func knownSlots(acc *config.AccountConfig) []common.Hash {
+	slots := make([]common.Hash, 0, 2)
+	if acc.ContractConfig.HasSparseConfig() {
+		slots = append(slots, acc.ContractConfig.State.CountSlot)
+	}
+	if acc.ContractConfig.HasEventConfig() {
+		slots = append(slots, acc.ContractConfig.Event.HeadSlot)
+	}
+	return slots
+}
@@ -0,0 +1,139 @@
+package state
+
+import (
+	"context"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/triedb"
+	"log/slog"
+	"math/big"
+	"sparseth/config"
+	"sparseth/ethstore"
+	"sparseth/execution/ethclient"
+	"sparseth/internal/log"
+	"sparseth/storage/mem"
+	"testing"
+)
+
+type bootstrapTestProvider struct {
+	preparerTestProvider
+	// states to be returned by
+	// GetAccountsAtBlock per account
+	states map[common.Address]*ethclient.AccountState
+	// code to be returned by
+	// GetCodeAtBlock
+	code []byte
+}
+
+func (p *bootstrapTestProvider) GetAccountsAtBlock(_ context.Context, reqs []*ethclient.ProofRequest, _ *types.Header) ([]*ethclient.AccountState, error) {
+	states := make([]*ethclient.AccountState, len(reqs))
+	for i, req := range reqs {
+		states[i] = p.states[req.Address]
+		if states[i] == nil {
+			states[i] = &ethclient.AccountState{}
+		}
+	}
+	return states, nil
+}
+
+func (p *bootstrapTestProvider) GetCodeAtBlock(context.Context, common.Address, *types.Header) ([]byte, error) {
+	return p.code, nil
+}
+
+func TestTxProcessor_Bootstrap(t *testing.T) {
+	eoa := common.HexToAddress("0xaa")
+	contract := common.HexToAddress("0xbb")
+	countSlot := common.HexToHash("0x01")
+	code := []byte{0x60, 0x00}
+
+	accs := &config.AccountsConfig{Accounts: []*config.AccountConfig{
+		{Addr: eoa, ContractConfig: &config.ContractConfig{}},
+		{Addr: contract, ContractConfig: &config.ContractConfig{State: &config.SparseConfig{CountSlot: countSlot}}},
+	}}
+	provider := &bootstrapTestProvider{
+		states: map[common.Address]*ethclient.AccountState{
+			eoa: {Account: &ethclient.Account{
+				Address:     eoa,
+				Nonce:       3,
+				Balance:     big.NewInt(100),
+				CodeHash:    types.EmptyCodeHash,
+				StorageRoot: types.EmptyRootHash,
+			}},
+			contract: {
+				Account: &ethclient.Account{
+					Address:  contract,
+					Nonce:    1,
+					Balance:  big.NewInt(0),
+					CodeHash: crypto.Keccak256Hash(code),
+					// Storage holds more slots
+					// than the count slot
+					StorageRoot: common.HexToHash("0x01"),
+				},
+				Storage: map[common.Hash][]byte{countSlot: {0x05}},
+			},
+		},
+		code: code,
+	}
+
+	newProcessor := func(t *testing.T) *TxProcessor {
+		db := mem.New()
+		trieDB := triedb.NewDatabase(rawdb.NewDatabase(db), nil)
+		world, err := NewRevertingStateDB(types.EmptyRootHash, state.NewDatabase(trieDB, nil))
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		return &TxProcessor{
+			provider: provider,
+			world:    world,
+			accounts: accs,
+			roots:    ethstore.NewRootStore(db),
+			trieDB:   trieDB,
+			breaker:  newCircuitBreaker(0),
+			log:      log.New(slog.DiscardHandler),
+		}
+	}
+
+	t.Run("should seed proven state of accounts", func(t *testing.T) {
+		p := newProcessor(t)
+		head := &types.Header{Number: big.NewInt(10)}
+
+		incomplete, err := p.Bootstrap(t.Context(), head)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if p.world.GetNonce(eoa) != 3 || p.world.GetBalance(eoa).Uint64() != 100 {
+			t.Errorf("expected nonce 3 and balance 100, got %d and %s", p.world.GetNonce(eoa), p.world.GetBalance(eoa))
+		}
+		if p.world.GetCodeHash(contract) != crypto.Keccak256Hash(code) {
+			t.Errorf("expected code to be seeded")
+		}
+		if got := p.world.GetState(contract, countSlot); got != common.BytesToHash([]byte{0x05}) {
+			t.Errorf("expected count slot 0x05, got %s", got.Hex())
+		}
+		if len(incomplete) != 1 || incomplete[0] != contract {
+			t.Errorf("expected %s to be incomplete, got %v", contract.Hex(), incomplete)
+		}
+		if latest := p.LatestRoot(); latest == nil || latest.Number != 10 {
+			t.Errorf("expected root recorded at block 10, got %v", latest)
+		}
+	})
+
+	t.Run("should skip accounts starting after the block", func(t *testing.T) {
+		p := newProcessor(t)
+		p.accounts = &config.AccountsConfig{Accounts: []*config.AccountConfig{
+			{Addr: eoa, ContractConfig: &config.ContractConfig{}, StartBlock: 11},
+		}}
+		head := &types.Header{Number: big.NewInt(10)}
+
+		if _, err := p.Bootstrap(t.Context(), head); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if p.world.Exist(eoa) {
+			t.Errorf("expected %s not to be seeded", eoa.Hex())
+		}
+	})
+}
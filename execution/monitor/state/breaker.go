@@ -0,0 +1,199 @@
+package state
+
+import (
+	"errors"
+	"github.com/ethereum/go-ethereum/common"
+	"sort"
+	"sparseth/config"
+	"sparseth/ethstore"
+	"sparseth/execution/ethclient"
+	"sync"
+)
+
+// errBudgetExceeded is returned if a block is not
+// prepared ahead, as it exceeds the RPC budget of
+// a monitored account.
+var errBudgetExceeded = errors.New("RPC budget exceeded")
+
+// circuitBreaker limits the RPC calls spent on
+// re-executing transactions of a single account.
+//
+// If the estimated number of calls for an account
+// exceeds the budget in a single block, e.g., as a
+// contract is suddenly touched by thousands of txs,
+// the breaker trips, and the account is switched to
+// proof-only mode: its transactions are no longer
+// re-executed, instead, only its proven on-chain
+// state is fetched per block.
+//
+// Tripped breakers stay tripped, also across
+// restarts, as the reconstructed world state of
+// the account is outdated once tripped.
+type circuitBreaker struct {
+	// budget is the default maximum number of calls
+	// per block and account, or zero if unlimited.
+	budget  uint64
+	tripped map[common.Address]uint64
+	mu      sync.Mutex
+}
+
+// newCircuitBreaker creates a new circuitBreaker
+// with the specified default per-block call budget,
+// which accounts may override.
+func newCircuitBreaker(budget uint64) *circuitBreaker {
+	return &circuitBreaker{
+		budget:  budget,
+		tripped: make(map[common.Address]uint64),
+	}
+}
+
+// check estimates the number of calls needed to
+// re-execute the specified transactions for each
+// of the specified accounts, and trips the breaker
+// of all accounts exceeding the budget at the
+// specified block. The newly tripped accounts are
+// returned with their estimated number of calls.
+func (b *circuitBreaker) check(num uint64, txs []*TransactionWithContext, accs *config.AccountsConfig) map[common.Address]uint64 {
+	return b.trip(num, estimateCalls(txs, accs), accs)
+}
+
+// checkDirect is like check, but estimates the
+// calls of the specified transactions before they
+// are traced, given their senders, so that traces
+// are not downloaded for accounts that exceed their
+// budget anyway. See estimateDirectCalls.
+func (b *circuitBreaker) checkDirect(num uint64, txs []*ethclient.TransactionWithIndex, senders []common.Address, accs *config.AccountsConfig) map[common.Address]uint64 {
+	return b.trip(num, estimateDirectCalls(txs, senders, accs), accs)
+}
+
+// trip trips the breaker of all of the specified
+// accounts whose estimated calls exceed the budget
+// at the specified block, and returns them with
+// their estimated number of calls.
+func (b *circuitBreaker) trip(num uint64, calls map[common.Address]uint64, accs *config.AccountsConfig) map[common.Address]uint64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	exceeded := make(map[common.Address]uint64)
+	for _, acc := range accs.Accounts {
+		budget := b.budgetOf(acc)
+		if budget > 0 && calls[acc.Addr] > budget {
+			b.tripped[acc.Addr] = num
+			exceeded[acc.Addr] = calls[acc.Addr]
+		}
+	}
+	return exceeded
+}
+
+// exceedsDirect checks whether the estimated calls
+// of the specified transactions, before they are
+// traced, exceed the budget of any of the specified
+// accounts, without tripping their breakers.
+func (b *circuitBreaker) exceedsDirect(txs []*ethclient.TransactionWithIndex, senders []common.Address, accs *config.AccountsConfig) bool {
+	calls := estimateDirectCalls(txs, senders, accs)
+	for _, acc := range accs.Accounts {
+		budget := b.budgetOf(acc)
+		if budget > 0 && calls[acc.Addr] > budget {
+			return true
+		}
+	}
+	return false
+}
+
+// restore trips the breakers of all specified
+// accounts, e.g., as they tripped before a
+// restart.
+func (b *circuitBreaker) restore(tripped []*ethstore.TrippedBreaker) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, t := range tripped {
+		b.tripped[t.Account] = t.Number
+	}
+}
+
+// budgetOf returns the call budget of the
+// specified account, or zero if unlimited.
+func (b *circuitBreaker) budgetOf(acc *config.AccountConfig) uint64 {
+	if acc.CallBudget > 0 {
+		return acc.CallBudget
+	}
+	return b.budget
+}
+
+// isTripped checks whether the breaker of
+// the specified account is tripped.
+func (b *circuitBreaker) isTripped(addr common.Address) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	_, tripped := b.tripped[addr]
+	return tripped
+}
+
+// trippedAccounts returns all accounts with a
+// tripped breaker, in ascending order.
+func (b *circuitBreaker) trippedAccounts() []common.Address {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	addrs := make([]common.Address, 0, len(b.tripped))
+	for addr := range b.tripped {
+		addrs = append(addrs, addr)
+	}
+	sort.Slice(addrs, func(i, j int) bool {
+		return addrs[i].Cmp(addrs[j]) < 0
+	})
+	return addrs
+}
+
+// estimateCalls estimates the number of calls needed
+// to re-execute the specified transactions for each
+// of the specified accounts.
+//
+// A transaction is attributed to all accounts it
+// touches directly. Its cost is one account proof
+// for the sender, the recipient, and each traced
+// account, plus one storage proof per traced slot.
+func estimateCalls(txs []*TransactionWithContext, accs *config.AccountsConfig) map[common.Address]uint64 {
+	calls := make(map[common.Address]uint64)
+	for _, tx := range txs {
+		cost := uint64(2)
+		touched := map[common.Address]bool{tx.Sender: true}
+		if tx.Tx.To() != nil {
+			touched[*tx.Tx.To()] = true
+		}
+		for _, acc := range tx.Trace.Accounts {
+			cost += 1 + uint64(len(acc.Storage.Slots))
+			touched[acc.Address] = true
+		}
+
+		for _, acc := range accs.Accounts {
+			if touched[acc.Addr] {
+				calls[acc.Addr] += cost
+			}
+		}
+	}
+	return calls
+}
+
+// estimateDirectCalls estimates the number of calls
+// needed to re-execute the specified transactions,
+// sent by the specified senders, for each of the
+// specified accounts, before they are traced.
+//
+// Only transactions sent or received by an account
+// are attributed to it, at the cost of one account
+// proof each for the sender and the recipient, so
+// the estimate is a lower bound of estimateCalls.
+func estimateDirectCalls(txs []*ethclient.TransactionWithIndex, senders []common.Address, accs *config.AccountsConfig) map[common.Address]uint64 {
+	calls := make(map[common.Address]uint64)
+	for i, tx := range txs {
+		for _, acc := range accs.Accounts {
+			if senders[i] == acc.Addr || (tx.Tx.To() != nil && *tx.Tx.To() == acc.Addr) {
+				calls[acc.Addr] += 2
+			}
+		}
+	}
+	return calls
+}
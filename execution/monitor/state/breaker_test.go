@@ -0,0 +1,140 @@
+package state
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"sparseth/config"
+	"sparseth/ethstore"
+	"sparseth/execution/ethclient"
+	"testing"
+)
+
+func newBreakerTestTx(sender, to common.Address, slots int) *TransactionWithContext {
+	trace := &ethclient.TransactionTrace{
+		Accounts: []*ethclient.AccountTrace{
+			{
+				Address: to,
+				Storage: &ethclient.StorageTrace{Slots: make([]common.Hash, slots)},
+			},
+		},
+	}
+
+	return &TransactionWithContext{
+		Tx:     types.NewTx(&types.LegacyTx{To: &to}),
+		Sender: sender,
+		Trace:  trace,
+	}
+}
+
+func TestCircuitBreaker_Check(t *testing.T) {
+	sender := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	busy := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	quiet := common.HexToAddress("0x3333333333333333333333333333333333333333")
+
+	accs := &config.AccountsConfig{
+		Accounts: []*config.AccountConfig{
+			{Addr: busy, ContractConfig: &config.ContractConfig{}},
+			{Addr: quiet, ContractConfig: &config.ContractConfig{}},
+		},
+	}
+
+	t.Run("should not trip without budget", func(t *testing.T) {
+		b := newCircuitBreaker(0)
+		txs := []*TransactionWithContext{newBreakerTestTx(sender, busy, 100)}
+
+		if exceeded := b.check(1, txs, accs); len(exceeded) != 0 {
+			t.Errorf("expected no tripped account, got %d", len(exceeded))
+		}
+	})
+
+	t.Run("should trip account exceeding budget", func(t *testing.T) {
+		b := newCircuitBreaker(10)
+		txs := []*TransactionWithContext{newBreakerTestTx(sender, busy, 20)}
+
+		exceeded := b.check(1, txs, accs)
+		if len(exceeded) != 1 {
+			t.Fatalf("expected 1 tripped account, got %d", len(exceeded))
+		}
+		if !b.isTripped(busy) {
+			t.Errorf("expected %s to be tripped", busy.Hex())
+		}
+		if b.isTripped(quiet) {
+			t.Errorf("expected %s not to be tripped", quiet.Hex())
+		}
+	})
+
+	t.Run("should prefer account budget over default", func(t *testing.T) {
+		b := newCircuitBreaker(10)
+		generous := &config.AccountsConfig{
+			Accounts: []*config.AccountConfig{
+				{Addr: busy, ContractConfig: &config.ContractConfig{}, CallBudget: 100},
+			},
+		}
+		txs := []*TransactionWithContext{newBreakerTestTx(sender, busy, 20)}
+
+		if exceeded := b.check(1, txs, generous); len(exceeded) != 0 {
+			t.Errorf("expected no tripped account, got %d", len(exceeded))
+		}
+	})
+}
+
+func TestCircuitBreaker_CheckDirect(t *testing.T) {
+	sender := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	busy := common.HexToAddress("0x2222222222222222222222222222222222222222")
+
+	accs := &config.AccountsConfig{
+		Accounts: []*config.AccountConfig{
+			{Addr: busy, ContractConfig: &config.ContractConfig{}},
+		},
+	}
+
+	txs := make([]*ethclient.TransactionWithIndex, 6)
+	senders := make([]common.Address, len(txs))
+	for i := range txs {
+		txs[i] = &ethclient.TransactionWithIndex{Tx: types.NewTx(&types.LegacyTx{To: &busy}), Index: i}
+		senders[i] = sender
+	}
+
+	t.Run("should trip account exceeding budget before tracing", func(t *testing.T) {
+		b := newCircuitBreaker(10)
+
+		if !b.exceedsDirect(txs, senders, accs) {
+			t.Errorf("expected budget to be exceeded")
+		}
+		if b.isTripped(busy) {
+			t.Fatalf("expected %s not to be tripped by exceedsDirect", busy.Hex())
+		}
+
+		exceeded := b.checkDirect(1, txs, senders, accs)
+		if exceeded[busy] != 12 {
+			t.Errorf("expected 12 calls, got %d", exceeded[busy])
+		}
+		if !b.isTripped(busy) {
+			t.Errorf("expected %s to be tripped", busy.Hex())
+		}
+	})
+
+	t.Run("should not trip account within budget", func(t *testing.T) {
+		b := newCircuitBreaker(20)
+
+		if exceeded := b.checkDirect(1, txs, senders, accs); len(exceeded) != 0 {
+			t.Errorf("expected no tripped account, got %d", len(exceeded))
+		}
+	})
+}
+
+func TestCircuitBreaker_Restore(t *testing.T) {
+	busy := common.HexToAddress("0x2222222222222222222222222222222222222222")
+
+	t.Run("should keep restored breakers tripped", func(t *testing.T) {
+		b := newCircuitBreaker(10)
+		b.restore([]*ethstore.TrippedBreaker{{Account: busy, Number: 7}})
+
+		if !b.isTripped(busy) {
+			t.Errorf("expected %s to be tripped", busy.Hex())
+		}
+		if addrs := b.trippedAccounts(); len(addrs) != 1 || addrs[0] != busy {
+			t.Errorf("expected [%s], got %v", busy.Hex(), addrs)
+		}
+	})
+}
@@ -0,0 +1,244 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/holiman/uint256"
+	"math/big"
+)
+
+// callGasCap is the gas limit of calls
+// that do not specify a gas limit.
+const callGasCap = 50_000_000
+
+var (
+	// ErrUnmonitoredState is returned if a call
+	// reads the state of an account that is not
+	// verified, i.e., not monitored.
+	ErrUnmonitoredState = errors.New("call touches unmonitored state")
+	// ErrNoVerifiedState is returned if a call is
+	// made before any block has been processed.
+	ErrNoVerifiedState = errors.New("no verified state yet")
+)
+
+// CallMsg is a read-only call.
+type CallMsg struct {
+	From common.Address
+	To   common.Address
+	Data []byte
+	// Value is the transferred value, which
+	// requires the sender to be monitored,
+	// or nil.
+	Value *big.Int
+	// Gas is the gas limit, or zero for
+	// the default limit.
+	Gas uint64
+}
+
+// CallResult is the result of a read-only
+// call against the verified world state.
+type CallResult struct {
+	// Number and BlockHash identify the
+	// block the call is executed at.
+	Number    uint64
+	BlockHash common.Hash
+	Return    []byte
+	GasUsed   uint64
+	// Err is the execution error, e.g., a
+	// revert, or nil if the call succeeded.
+	Err error
+}
+
+// Call executes the specified read-only call
+// against the verified world state after the
+// last processed block. All changes are
+// discarded.
+//
+// Only the state of monitored accounts whose
+// transactions are re-executed is verified, so
+// if the call reads the state of any other
+// account, except for precompiles and accounts
+// created by the call, ErrUnmonitoredState is
+// returned.
+func (p *TxProcessor) Call(msg *CallMsg) (*CallResult, error) {
+	latest := p.LatestRoot()
+	if latest == nil {
+		return nil, ErrNoVerifiedState
+	}
+	header, err := p.headers.GetByHash(latest.BlockHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load header of block %d: %w", latest.Number, err)
+	}
+
+	inner, err := state.New(latest.Root, state.NewDatabase(p.trieDB, nil))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open state at block %d: %w", latest.Number, err)
+	}
+
+	p.mu.Lock()
+	active := p.activeAccounts(latest.Number)
+	p.mu.Unlock()
+
+	rules := p.executor.cc.Rules(header.Number, true, header.Time)
+	world := newGuardedStateDB(inner)
+	for _, acc := range active.Accounts {
+		world.allow(acc.Addr)
+	}
+	for _, addr := range vm.ActivePrecompiles(rules) {
+		world.allow(addr)
+	}
+
+	chain := &HeaderContext{
+		Params: p.executor.cc,
+		Store:  p.headers,
+	}
+	context := core.NewEVMBlockContext(header, chain, &header.Coinbase)
+	evm := vm.NewEVM(context, world, p.executor.cc, vm.Config{NoBaseFee: true})
+	evm.SetTxContext(vm.TxContext{Origin: msg.From, GasPrice: new(big.Int)})
+
+	gas := msg.Gas
+	if gas == 0 || gas > callGasCap {
+		gas = callGasCap
+	}
+	value := new(uint256.Int)
+	if msg.Value != nil {
+		value.SetFromBig(msg.Value)
+	}
+
+	ret, left, callErr := evm.Call(msg.From, msg.To, msg.Data, gas, value)
+	if err = world.Err(); err != nil {
+		return nil, err
+	}
+	if err = chain.Err(); err != nil {
+		return nil, err
+	}
+
+	return &CallResult{
+		Number:    latest.Number,
+		BlockHash: latest.BlockHash,
+		Return:    ret,
+		GasUsed:   gas - left,
+		Err:       callErr,
+	}, nil
+}
+
+// guardedStateDB is a state database that only
+// permits reads of allowed accounts, i.e., of
+// verified state, and records the first read
+// of any other account.
+type guardedStateDB struct {
+	*state.StateDB
+	allowed map[common.Address]bool
+	err     error
+}
+
+// newGuardedStateDB creates a new guarded
+// state database wrapping the specified one.
+func newGuardedStateDB(inner *state.StateDB) *guardedStateDB {
+	return &guardedStateDB{
+		StateDB: inner,
+		allowed: make(map[common.Address]bool),
+	}
+}
+
+// allow permits reads of the specified account.
+func (db *guardedStateDB) allow(addr common.Address) {
+	db.allowed[addr] = true
+}
+
+// Err returns the first read of an
+// account that is not allowed, if any.
+func (db *guardedStateDB) Err() error {
+	return db.err
+}
+
+// check records a read of the specified
+// account, if it is not allowed.
+func (db *guardedStateDB) check(addr common.Address) {
+	if db.err == nil && !db.allowed[addr] {
+		db.err = fmt.Errorf("%w: %s", ErrUnmonitoredState, addr.Hex())
+	}
+}
+
+func (db *guardedStateDB) CreateAccount(addr common.Address) {
+	db.allow(addr)
+	db.StateDB.CreateAccount(addr)
+}
+
+func (db *guardedStateDB) CreateContract(addr common.Address) {
+	db.allow(addr)
+	db.StateDB.CreateContract(addr)
+}
+
+func (db *guardedStateDB) SubBalance(addr common.Address, amount *uint256.Int, reason tracing.BalanceChangeReason) uint256.Int {
+	// Zero-value transfers do not depend
+	// on the balance, e.g., of the sender
+	if amount.IsZero() {
+		return uint256.Int{}
+	}
+	db.check(addr)
+	return db.StateDB.SubBalance(addr, amount, reason)
+}
+
+func (db *guardedStateDB) AddBalance(addr common.Address, amount *uint256.Int, reason tracing.BalanceChangeReason) uint256.Int {
+	if amount.IsZero() {
+		return uint256.Int{}
+	}
+	db.check(addr)
+	return db.StateDB.AddBalance(addr, amount, reason)
+}
+
+func (db *guardedStateDB) GetBalance(addr common.Address) *uint256.Int {
+	db.check(addr)
+	return db.StateDB.GetBalance(addr)
+}
+
+func (db *guardedStateDB) GetNonce(addr common.Address) uint64 {
+	db.check(addr)
+	return db.StateDB.GetNonce(addr)
+}
+
+func (db *guardedStateDB) GetCodeHash(addr common.Address) common.Hash {
+	db.check(addr)
+	return db.StateDB.GetCodeHash(addr)
+}
+
+func (db *guardedStateDB) GetCode(addr common.Address) []byte {
+	db.check(addr)
+	return db.StateDB.GetCode(addr)
+}
+
+func (db *guardedStateDB) GetCodeSize(addr common.Address) int {
+	db.check(addr)
+	return db.StateDB.GetCodeSize(addr)
+}
+
+func (db *guardedStateDB) GetCommittedState(addr common.Address, key common.Hash) common.Hash {
+	db.check(addr)
+	return db.StateDB.GetCommittedState(addr, key)
+}
+
+func (db *guardedStateDB) GetState(addr common.Address, key common.Hash) common.Hash {
+	db.check(addr)
+	return db.StateDB.GetState(addr, key)
+}
+
+func (db *guardedStateDB) GetStorageRoot(addr common.Address) common.Hash {
+	db.check(addr)
+	return db.StateDB.GetStorageRoot(addr)
+}
+
+func (db *guardedStateDB) Exist(addr common.Address) bool {
+	db.check(addr)
+	return db.StateDB.Exist(addr)
+}
+
+func (db *guardedStateDB) Empty(addr common.Address) bool {
+	db.check(addr)
+	return db.StateDB.Empty(addr)
+}
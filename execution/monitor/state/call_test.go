@@ -0,0 +1,81 @@
+package state
+
+import (
+	"errors"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/triedb"
+	"github.com/holiman/uint256"
+	"sparseth/config"
+	"sparseth/storage/mem"
+	"testing"
+)
+
+func TestGuardedStateDB(t *testing.T) {
+	monitored := common.HexToAddress("0xaa")
+	other := common.HexToAddress("0xbb")
+
+	newGuarded := func(t *testing.T) *guardedStateDB {
+		trieDB := triedb.NewDatabase(rawdb.NewDatabase(mem.New()), nil)
+		inner, err := state.New(types.EmptyRootHash, state.NewDatabase(trieDB, nil))
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		db := newGuardedStateDB(inner)
+		db.allow(monitored)
+		return db
+	}
+
+	t.Run("should permit reads of allowed account", func(t *testing.T) {
+		db := newGuarded(t)
+		db.GetBalance(monitored)
+		db.GetState(monitored, common.Hash{})
+
+		if err := db.Err(); err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("should fail on read of other account", func(t *testing.T) {
+		db := newGuarded(t)
+		db.GetCode(other)
+
+		if err := db.Err(); !errors.Is(err, ErrUnmonitoredState) {
+			t.Errorf("expected %v, got %v", ErrUnmonitoredState, err)
+		}
+	})
+
+	t.Run("should permit reads of created account", func(t *testing.T) {
+		db := newGuarded(t)
+		db.CreateAccount(other)
+		db.CreateContract(other)
+		db.GetCode(other)
+
+		if err := db.Err(); err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("should ignore zero-value transfer", func(t *testing.T) {
+		db := newGuarded(t)
+		db.SubBalance(other, new(uint256.Int), tracing.BalanceChangeTransfer)
+		db.AddBalance(other, new(uint256.Int), tracing.BalanceChangeTransfer)
+
+		if err := db.Err(); err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+	})
+}
+
+func TestTxProcessor_Call(t *testing.T) {
+	t.Run("should fail if no block processed", func(t *testing.T) {
+		p := newExportTestProcessor(t, mem.New(), &bootstrapTestProvider{}, &config.AccountsConfig{})
+
+		if _, err := p.Call(&CallMsg{To: common.HexToAddress("0xaa")}); !errors.Is(err, ErrNoVerifiedState) {
+			t.Errorf("expected %v, got %v", ErrNoVerifiedState, err)
+		}
+	})
+}
@@ -0,0 +1,56 @@
+package state
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"sparseth/execution/ethclient"
+)
+
+// authorities returns the accounts authorizing a
+// code delegation (EIP-7702) in the specified
+// transaction, if any. Authorizations with an
+// invalid signature are skipped, as they are
+// not applied on-chain either.
+func authorities(tx *types.Transaction) []common.Address {
+	auths := tx.SetCodeAuthorizations()
+	if len(auths) == 0 {
+		return nil
+	}
+
+	addrs := make([]common.Address, 0, len(auths))
+	for _, auth := range auths {
+		authority, err := auth.Authority()
+		if err != nil {
+			continue
+		}
+		addrs = append(addrs, authority)
+	}
+	return addrs
+}
+
+// delegateRequests returns the proof requests for
+// the delegates of all specified accounts with a
+// delegation designator that are not among the
+// specified accounts themselves.
+//
+// Calls to a delegated account execute the code of
+// its delegate, which must hence be loaded as well.
+func delegateRequests(accs []*fetchedAccount) []*ethclient.ProofRequest {
+	fetched := make(map[common.Address]bool, len(accs))
+	for _, acc := range accs {
+		if acc.Account != nil {
+			fetched[acc.Account.Address] = true
+		}
+	}
+
+	var reqs []*ethclient.ProofRequest
+	for _, acc := range accs {
+		target, ok := types.ParseDelegation(acc.code)
+		if !ok || fetched[target] {
+			continue
+		}
+		fetched[target] = true
+		reqs = append(reqs, &ethclient.ProofRequest{Address: target})
+	}
+	return reqs
+}
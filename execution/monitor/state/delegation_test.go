@@ -0,0 +1,62 @@
+package state
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"sparseth/execution/ethclient"
+	"testing"
+)
+
+func TestAuthorities(t *testing.T) {
+	t.Run("should return signers of authorizations", func(t *testing.T) {
+		key, err := crypto.GenerateKey()
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		auth, err := types.SignSetCode(key, types.SetCodeAuthorization{Address: common.HexToAddress("0x01")})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		tx := types.NewTx(&types.SetCodeTx{AuthList: []types.SetCodeAuthorization{auth, {}}})
+
+		addrs := authorities(tx)
+		if len(addrs) != 1 || addrs[0] != crypto.PubkeyToAddress(key.PublicKey) {
+			t.Errorf("expected signer of valid authorization only, got %v", addrs)
+		}
+	})
+
+	t.Run("should return nothing for other transactions", func(t *testing.T) {
+		if addrs := authorities(types.NewTx(&types.LegacyTx{})); len(addrs) != 0 {
+			t.Errorf("expected no authorities, got %v", addrs)
+		}
+	})
+}
+
+func TestDelegateRequests(t *testing.T) {
+	eoa := common.HexToAddress("0x01")
+	delegate := common.HexToAddress("0x02")
+	contract := common.HexToAddress("0x03")
+
+	t.Run("should request delegates not yet fetched", func(t *testing.T) {
+		reqs := delegateRequests([]*fetchedAccount{
+			{AccountState: &ethclient.AccountState{Account: &ethclient.Account{Address: eoa}}, code: types.AddressToDelegation(delegate)},
+			{AccountState: &ethclient.AccountState{Account: &ethclient.Account{Address: contract}}, code: []byte{0x60, 0x00}},
+		})
+
+		if len(reqs) != 1 || reqs[0].Address != delegate {
+			t.Errorf("expected request for delegate, got %v", reqs)
+		}
+	})
+
+	t.Run("should not request fetched delegates", func(t *testing.T) {
+		reqs := delegateRequests([]*fetchedAccount{
+			{AccountState: &ethclient.AccountState{Account: &ethclient.Account{Address: eoa}}, code: types.AddressToDelegation(delegate)},
+			{AccountState: &ethclient.AccountState{Account: &ethclient.Account{Address: delegate}}, code: []byte{0x60, 0x00}},
+		})
+
+		if len(reqs) != 0 {
+			t.Errorf("expected no requests, got %v", reqs)
+		}
+	})
+}
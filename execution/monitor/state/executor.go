@@ -9,6 +9,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/params"
+	"sparseth/ethstore"
 )
 
 // ExecutionResult contains the receipts
@@ -20,32 +21,59 @@ type ExecutionResult struct {
 // TxExecutor is responsible for executing
 // transactions in the context of a block.
 type TxExecutor struct {
-	chain core.ChainContext
+	cc      *params.ChainConfig
+	headers *ethstore.HeaderStore
+	senders *SenderCache
 }
 
 // NewTxExecutor creates a new TxExecutor
-// using the supplied chain configuration.
-// Note that TxExecutor is not safe for
-// concurrent use.
-func NewTxExecutor(chain *params.ChainConfig) *TxExecutor {
+// using the supplied chain configuration,
+// resolving block hashes of ancestors via
+// the specified header store, and looking
+// up transaction senders in the specified
+// cache. Note that TxExecutor is not safe
+// for concurrent use.
+func NewTxExecutor(chain *params.ChainConfig, headers *ethstore.HeaderStore, senders *SenderCache) *TxExecutor {
 	return &TxExecutor{
-		chain: &HeaderContext{
-			Params: chain,
-		},
+		cc:      chain,
+		headers: headers,
+		senders: senders,
 	}
 }
 
 // ExecuteTxs executes the specified transactions
 // using the supplied state. Not that it is assumed
 // that all transactions belong to the supplied block.
+//
+// If a transaction reads the hash of an ancestor
+// whose header is not stored, ErrMissingAncestor
+// is returned, as the result would be wrong.
+//
+// The system calls that precede the transactions
+// of a block are applied first, see
+// applySystemCalls. The blob gas of blob
+// transactions is checked against the block
+// header beforehand, see validateBlobGas.
 func (e *TxExecutor) ExecuteTxs(header *types.Header, txs []*TransactionWithContext, world *TracingStateDB) (*ExecutionResult, error) {
+	if err := validateBlobGas(e.cc, header, txs); err != nil {
+		return nil, fmt.Errorf("invalid blob gas: %w", err)
+	}
+
 	usedGas := new(uint64)
 	gasPool := new(core.GasPool).AddGas(header.GasLimit)
 
-	signer := types.MakeSigner(e.chain.Config(), header.Number, header.Time)
+	signer := &cachedSigner{
+		Signer: types.MakeSigner(e.cc, header.Number, header.Time),
+		cache:  e.senders,
+	}
 
-	context := core.NewEVMBlockContext(header, e.chain, &header.Coinbase)
-	evm := vm.NewEVM(context, world, e.chain.Config(), vm.Config{})
+	chain := &HeaderContext{
+		Params: e.cc,
+		Store:  e.headers,
+	}
+	context := core.NewEVMBlockContext(header, chain, &header.Coinbase)
+	evm := vm.NewEVM(context, world, e.cc, vm.Config{})
+	applySystemCalls(e.cc, header, evm)
 
 	receipts := make([]*types.Receipt, len(txs))
 	for index, tx := range txs {
@@ -54,6 +82,9 @@ func (e *TxExecutor) ExecuteTxs(header *types.Header, txs []*TransactionWithCont
 			return nil, fmt.Errorf("failed to convert tx at index %d to message: %w", index, err)
 		}
 		world.SetTxContext(tx.Tx.Hash(), tx.Index)
+		// Replace the context left behind by the
+		// system calls or the previous transaction
+		evm.SetTxContext(core.NewEVMTxContext(msg))
 
 		onTxStart(evm, tx.Tx, msg)
 		result, err := core.ApplyMessage(evm, msg, gasPool)
@@ -72,6 +103,10 @@ func (e *TxExecutor) ExecuteTxs(header *types.Header, txs []*TransactionWithCont
 		receipt := createReceipt(evm, result, world, header, tx, *usedGas, root)
 		receipts[index] = receipt
 		onTxEnd(evm, receipt, nil)
+
+		if err = chain.Err(); err != nil {
+			return nil, fmt.Errorf("failed to execute tx at index %d: %w", index, err)
+		}
 	}
 
 	return &ExecutionResult{
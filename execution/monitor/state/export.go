@@ -0,0 +1,266 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/ethereum/go-ethereum/triedb"
+	"io"
+	"sparseth/ethstore"
+	"sparseth/execution/monitor"
+	"sparseth/storage"
+)
+
+// StateExport is a portable snapshot of the
+// verified world state after a block.
+type StateExport struct {
+	// Header is the last verified block,
+	// which the state is taken after.
+	Header *types.Header
+	// Root is the world state root.
+	Root common.Hash
+	// Nodes are the trie nodes of the account
+	// trie and all storage tries.
+	Nodes [][]byte
+	// Codes are the contract codes
+	// of all accounts.
+	Codes [][]byte
+}
+
+// ExportState writes the world state after the last
+// processed block, along with the header of that block,
+// to the specified writer.
+func (p *TxProcessor) ExportState(w io.Writer) (*types.Header, error) {
+	latest := p.LatestRoot()
+	if latest == nil {
+		return nil, fmt.Errorf("no block processed yet")
+	}
+
+	header, err := p.headers.GetByHash(latest.BlockHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get header of block %d: %w", latest.Number, err)
+	}
+
+	export := &StateExport{
+		Header: header,
+		Root:   latest.Root,
+	}
+	codes := make(map[common.Hash]struct{})
+	err = walkState(p.trieDB, latest.Root, func(blob []byte) {
+		export.Nodes = append(export.Nodes, blob)
+	}, func(acc *types.StateAccount) error {
+		hash := common.BytesToHash(acc.CodeHash)
+		if hash == types.EmptyCodeHash {
+			return nil
+		}
+		if _, ok := codes[hash]; ok {
+			return nil
+		}
+		code := rawdb.ReadCode(p.trieDB.Disk(), hash)
+		if len(code) == 0 {
+			return fmt.Errorf("code %s not found", hash.Hex())
+		}
+		codes[hash] = struct{}{}
+		export.Codes = append(export.Codes, code)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err = rlp.Encode(w, export); err != nil {
+		return nil, fmt.Errorf("failed to encode state: %w", err)
+	}
+	return header, nil
+}
+
+// ImportState reads a world state exported by ExportState
+// from the specified reader, and writes it to the specified
+// store. The state is checked to be complete, i.e., to hold
+// all trie nodes and codes referenced by its root. The
+// header of the block the state is taken after is returned.
+//
+// Note that the exported state must be trusted: it is only
+// checked for completeness, not against the chain.
+func ImportState(db storage.KeyValStore, r io.Reader) (*types.Header, error) {
+	var export StateExport
+	if err := rlp.Decode(r, &export); err != nil {
+		return nil, fmt.Errorf("failed to decode state: %w", err)
+	}
+	if export.Header == nil {
+		return nil, fmt.Errorf("state has no header")
+	}
+
+	batch := db.NewBatch()
+	for _, node := range export.Nodes {
+		rawdb.WriteLegacyTrieNode(batch, crypto.Keccak256Hash(node), node)
+	}
+	for _, code := range export.Codes {
+		rawdb.WriteCode(batch, crypto.Keccak256Hash(code), code)
+	}
+	if err := batch.Write(); err != nil {
+		return nil, fmt.Errorf("failed to write state: %w", err)
+	}
+
+	trieDB := triedb.NewDatabase(rawdb.NewDatabase(db), nil)
+	err := walkState(trieDB, export.Root, func([]byte) {}, func(acc *types.StateAccount) error {
+		hash := common.BytesToHash(acc.CodeHash)
+		if hash != types.EmptyCodeHash && !rawdb.HasCode(trieDB.Disk(), hash) {
+			return fmt.Errorf("code %s not found", hash.Hex())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("incomplete state: %w", err)
+	}
+
+	if err = ethstore.NewHeaderStore(db).Put(export.Header); err != nil {
+		return nil, fmt.Errorf("failed to store header: %w", err)
+	}
+	err = ethstore.NewRootStore(db).Put(&ethstore.StateRoot{
+		Number:    export.Header.Number.Uint64(),
+		BlockHash: export.Header.Hash(),
+		Root:      export.Root,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to store state root: %w", err)
+	}
+	return export.Header, nil
+}
+
+// Resume restores the world state after the specified
+// block from the store, e.g., after it was imported, or
+// processed before a restart. It returns false if no
+// complete state is stored for the block, in which case
+// the state is unchanged.
+func (p *TxProcessor) Resume(head *types.Header) (bool, error) {
+	stored, err := p.roots.Get(head.Number.Uint64())
+	if errors.Is(err, ethstore.ErrRootNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	// Roots are recorded before their trie nodes are
+	// flushed, so the nodes may be missing after a crash
+	if stored.BlockHash != head.Hash() {
+		return false, nil
+	}
+	if stored.Root != types.EmptyRootHash && !rawdb.HasLegacyTrieNode(p.trieDB.Disk(), stored.Root) {
+		return false, nil
+	}
+
+	world, err := p.world.WithRoot(stored.Root)
+	if err != nil {
+		return false, fmt.Errorf("failed to open stored state: %w", err)
+	}
+	p.world = world
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.latest = stored
+	return true, nil
+}
+
+// Replay returns the digest of the specified block,
+// which was processed before, from the stored roots,
+// without any RPC calls. It returns false if the block
+// must be processed again, i.e., if it is after the
+// current world state, or if the stored root belongs
+// to another block.
+func (p *TxProcessor) Replay(head *types.Header) (common.Hash, bool, error) {
+	stored, ok, err := p.processed(head)
+	if err != nil || !ok {
+		return common.Hash{}, false, err
+	}
+	return monitor.Digest(head, stored.Root, stored.ReceiptsHash), true, nil
+}
+
+// processed returns the stored root of the specified
+// block, if the block is already reflected in the
+// current world state.
+func (p *TxProcessor) processed(head *types.Header) (*ethstore.StateRoot, bool, error) {
+	latest := p.LatestRoot()
+	if latest == nil || head.Number.Uint64() > latest.Number {
+		return nil, false, nil
+	}
+
+	stored, err := p.roots.Get(head.Number.Uint64())
+	if errors.Is(err, ethstore.ErrRootNotFound) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	if stored.BlockHash != head.Hash() || stored.ReceiptsHash == (common.Hash{}) {
+		return nil, false, nil
+	}
+	return stored, true, nil
+}
+
+// walkState iterates all trie nodes of the world state
+// with the specified root, and calls onNode with each
+// node and onAccount with each account. An error is
+// returned if a node is missing.
+func walkState(db *triedb.Database, root common.Hash, onNode func([]byte), onAccount func(*types.StateAccount) error) error {
+	if root == types.EmptyRootHash {
+		return nil
+	}
+
+	accTrie, err := trie.NewStateTrie(trie.StateTrieID(root), db)
+	if err != nil {
+		return fmt.Errorf("failed to open account trie: %w", err)
+	}
+	it, err := accTrie.NodeIterator(nil)
+	if err != nil {
+		return fmt.Errorf("failed to iterate account trie: %w", err)
+	}
+
+	for it.Next(true) {
+		if it.Hash() != (common.Hash{}) {
+			onNode(it.NodeBlob())
+		}
+		if !it.Leaf() {
+			continue
+		}
+
+		var acc types.StateAccount
+		if err = rlp.DecodeBytes(it.LeafBlob(), &acc); err != nil {
+			return fmt.Errorf("failed to decode account: %w", err)
+		}
+		if err = onAccount(&acc); err != nil {
+			return err
+		}
+		if acc.Root == types.EmptyRootHash {
+			continue
+		}
+
+		id := trie.StorageTrieID(root, common.BytesToHash(it.LeafKey()), acc.Root)
+		storageTrie, err := trie.NewStateTrie(id, db)
+		if err != nil {
+			return fmt.Errorf("failed to open storage trie: %w", err)
+		}
+		storageIt, err := storageTrie.NodeIterator(nil)
+		if err != nil {
+			return fmt.Errorf("failed to iterate storage trie: %w", err)
+		}
+		for storageIt.Next(true) {
+			if storageIt.Hash() != (common.Hash{}) {
+				onNode(storageIt.NodeBlob())
+			}
+		}
+		if storageIt.Error() != nil {
+			return fmt.Errorf("failed to iterate storage trie: %w", storageIt.Error())
+		}
+	}
+	if it.Error() != nil {
+		return fmt.Errorf("failed to iterate account trie: %w", it.Error())
+	}
+	return nil
+}
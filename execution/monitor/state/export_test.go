@@ -0,0 +1,221 @@
+package state
+
+import (
+	"bytes"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/triedb"
+	"github.com/holiman/uint256"
+	"log/slog"
+	"math/big"
+	"sparseth/config"
+	"sparseth/ethstore"
+	"sparseth/execution/ethclient"
+	"sparseth/execution/monitor"
+	"sparseth/internal/log"
+	"sparseth/storage"
+	"sparseth/storage/mem"
+	"testing"
+)
+
+func newExportTestProcessor(t *testing.T, db storage.KeyValStore, provider ethclient.Provider, accs *config.AccountsConfig) *TxProcessor {
+	trieDB := triedb.NewDatabase(rawdb.NewDatabase(db), nil)
+	world, err := NewRevertingStateDB(types.EmptyRootHash, state.NewDatabase(trieDB, nil))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	return &TxProcessor{
+		provider: provider,
+		world:    world,
+		accounts: accs,
+		roots:    ethstore.NewRootStore(db),
+		headers:  ethstore.NewHeaderStore(db),
+		trieDB:   trieDB,
+		breaker:  newCircuitBreaker(0),
+		log:      log.New(slog.DiscardHandler),
+	}
+}
+
+func TestTxProcessor_ExportState(t *testing.T) {
+	contract := common.HexToAddress("0xbb")
+	countSlot := common.HexToHash("0x01")
+	code := []byte{0x60, 0x00}
+
+	accs := &config.AccountsConfig{Accounts: []*config.AccountConfig{
+		{Addr: contract, ContractConfig: &config.ContractConfig{State: &config.SparseConfig{CountSlot: countSlot}}},
+	}}
+	provider := &bootstrapTestProvider{
+		states: map[common.Address]*ethclient.AccountState{
+			contract: {
+				Account: &ethclient.Account{
+					Address:     contract,
+					Nonce:       1,
+					Balance:     big.NewInt(7),
+					CodeHash:    crypto.Keccak256Hash(code),
+					StorageRoot: types.EmptyRootHash,
+				},
+				Storage: map[common.Hash][]byte{countSlot: {0x05}},
+			},
+		},
+		code: code,
+	}
+	head := &types.Header{Number: big.NewInt(10)}
+
+	exportState := func(t *testing.T) *bytes.Buffer {
+		db := mem.New()
+		p := newExportTestProcessor(t, db, provider, accs)
+		if _, err := p.Bootstrap(t.Context(), head); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if err := p.headers.Put(head); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		var buf bytes.Buffer
+		if _, err := p.ExportState(&buf); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		return &buf
+	}
+
+	t.Run("should fail if no block processed", func(t *testing.T) {
+		p := newExportTestProcessor(t, mem.New(), provider, accs)
+
+		if _, err := p.ExportState(&bytes.Buffer{}); err == nil {
+			t.Errorf("expected error, got nil")
+		}
+	})
+
+	t.Run("should resume imported state", func(t *testing.T) {
+		buf := exportState(t)
+
+		db := mem.New()
+		imported, err := ImportState(db, buf)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if imported.Hash() != head.Hash() {
+			t.Errorf("expected header %s, got %s", head.Hash().Hex(), imported.Hash().Hex())
+		}
+
+		p := newExportTestProcessor(t, db, provider, accs)
+		ok, err := p.Resume(head)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if !ok {
+			t.Fatalf("expected state to be resumed")
+		}
+		if p.world.GetBalance(contract).Uint64() != 7 {
+			t.Errorf("expected balance 7, got %s", p.world.GetBalance(contract))
+		}
+		if !bytes.Equal(p.world.GetCode(contract), code) {
+			t.Errorf("expected code to be imported")
+		}
+		if got := p.world.GetState(contract, countSlot); got != common.BytesToHash([]byte{0x05}) {
+			t.Errorf("expected count slot 0x05, got %s", got.Hex())
+		}
+	})
+
+	t.Run("should reject incomplete state", func(t *testing.T) {
+		var export StateExport
+		if err := rlp.Decode(exportState(t), &export); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		export.Codes = nil
+
+		var buf bytes.Buffer
+		if err := rlp.Encode(&buf, &export); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if _, err := ImportState(mem.New(), &buf); err == nil {
+			t.Errorf("expected error, got nil")
+		}
+	})
+
+	t.Run("should not resume state of other block", func(t *testing.T) {
+		db := mem.New()
+		if _, err := ImportState(db, exportState(t)); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		p := newExportTestProcessor(t, db, provider, accs)
+		ok, err := p.Resume(&types.Header{Number: big.NewInt(10), Time: 1})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if ok {
+			t.Errorf("expected state not to be resumed")
+		}
+	})
+}
+
+func TestTxProcessor_Replay(t *testing.T) {
+	addr := common.HexToAddress("0xbb")
+	accs := &config.AccountsConfig{
+		Accounts: []*config.AccountConfig{{Addr: addr, ContractConfig: &config.ContractConfig{}}},
+	}
+	head := &types.Header{Number: big.NewInt(1)}
+	receipts := common.HexToHash("0x01")
+
+	// process records the state of a single
+	// block, and returns a processor that is
+	// restarted on the same database
+	process := func(t *testing.T) *TxProcessor {
+		db := mem.New()
+		p := newExportTestProcessor(t, db, nil, accs)
+		p.world.SetBalance(addr, uint256.NewInt(7), tracing.BalanceChangeUnspecified)
+		root, err := p.world.Commit(1, false, false)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if err = p.recordRoot(head, root, receipts); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		return newExportTestProcessor(t, db, nil, accs)
+	}
+
+	t.Run("should replay processed block after restart", func(t *testing.T) {
+		p := process(t)
+		ok, err := p.Resume(head)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if !ok {
+			t.Fatalf("expected state to be resumed")
+		}
+		if p.world.GetBalance(addr).Uint64() != 7 {
+			t.Errorf("expected balance 7, got %s", p.world.GetBalance(addr))
+		}
+
+		digest, ok, err := p.Replay(head)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if !ok || digest != monitor.Digest(head, p.LatestRoot().Root, receipts) {
+			t.Errorf("expected digest of processed block, got %s", digest.Hex())
+		}
+	})
+
+	t.Run("should not replay block after state", func(t *testing.T) {
+		p := process(t)
+		if _, ok, _ := p.Replay(head); ok {
+			t.Errorf("expected block not to be replayed before resume")
+		}
+	})
+
+	t.Run("should not replay other block", func(t *testing.T) {
+		p := process(t)
+		if _, err := p.Resume(head); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if _, ok, _ := p.Replay(&types.Header{Number: big.NewInt(1), Time: 1}); ok {
+			t.Errorf("expected reorged block not to be replayed")
+		}
+	})
+}
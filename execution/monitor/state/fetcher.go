@@ -0,0 +1,170 @@
+package state
+
+import (
+	"context"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"golang.org/x/sync/errgroup"
+	"slices"
+	"sparseth/ethstore"
+	"sparseth/execution/ethclient"
+	"sync"
+)
+
+// fetchChunkSize is the maximum number of
+// accounts fetched by a single provider call.
+const fetchChunkSize = 32
+
+// fetchedAccount is a verified account
+// along with its code and fetched storage.
+type fetchedAccount struct {
+	*ethclient.AccountState
+	// code is the code of the account,
+	// or nil if it has no code.
+	code []byte
+}
+
+// stateFetcher concurrently fetches the accounts,
+// code, and storage slots required to build the
+// transient state of a block.
+type stateFetcher struct {
+	provider ethclient.Provider
+	// codes caches the code of accounts by
+	// code hash, or nil if code is not cached.
+	codes *ethstore.CodeStore
+	// parallelism is the maximum number
+	// of concurrent provider calls.
+	parallelism int
+}
+
+// newStateFetcher creates a new stateFetcher that
+// issues at most the specified number of provider
+// calls concurrently, and looks up code in the
+// specified store before fetching it. A non-positive
+// parallelism is treated as a parallelism of one.
+func newStateFetcher(provider ethclient.Provider, codes *ethstore.CodeStore, parallelism int) *stateFetcher {
+	if parallelism < 1 {
+		parallelism = 1
+	}
+
+	return &stateFetcher{
+		provider:    provider,
+		codes:       codes,
+		parallelism: parallelism,
+	}
+}
+
+// fetch fetches the accounts and storage slots of all
+// specified requests at the specified block, along
+// with the code of all existing contract accounts, in
+// order of the requests. Code shared by multiple
+// accounts is only fetched once.
+func (f *stateFetcher) fetch(ctx context.Context, head *types.Header, reqs []*ethclient.ProofRequest) ([]*fetchedAccount, error) {
+	chunks := slices.Collect(slices.Chunk(reqs, fetchChunkSize))
+	states := make([][]*ethclient.AccountState, len(chunks))
+
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(f.parallelism)
+	for i, chunk := range chunks {
+		g.Go(func() error {
+			st, err := f.provider.GetAccountsAtBlock(gctx, chunk, head)
+			if err != nil {
+				return fmt.Errorf("failed to get accounts at block %d: %w", head.Number.Uint64(), err)
+			}
+			if len(st) != len(chunk) {
+				return fmt.Errorf("expected %d accounts at block %d, got %d", len(chunk), head.Number.Uint64(), len(st))
+			}
+			states[i] = st
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	accs := make([]*fetchedAccount, 0, len(reqs))
+	for _, st := range slices.Concat(states...) {
+		accs = append(accs, &fetchedAccount{AccountState: st})
+	}
+
+	if err := f.fetchCode(ctx, head, accs); err != nil {
+		return nil, err
+	}
+	return accs, nil
+}
+
+// fetchCode fetches the code of all specified
+// existing contract accounts at the specified
+// block, fetching each distinct code once. Code
+// found in the code store is not fetched.
+func (f *stateFetcher) fetchCode(ctx context.Context, head *types.Header, accs []*fetchedAccount) error {
+	codes := make(map[common.Hash][]byte)
+	var mu sync.Mutex
+
+	// Fetch each uncached code via
+	// the first account that uses it
+	byHash := make(map[common.Hash]common.Address)
+	for _, acc := range accs {
+		if acc.Account == nil || acc.Account.CodeHash == types.EmptyCodeHash {
+			continue
+		}
+		if _, ok := codes[acc.Account.CodeHash]; ok {
+			continue
+		}
+		if _, ok := byHash[acc.Account.CodeHash]; ok {
+			continue
+		}
+		if code, ok := f.cachedCode(acc.Account.CodeHash); ok {
+			codes[acc.Account.CodeHash] = code
+			continue
+		}
+		byHash[acc.Account.CodeHash] = acc.Account.Address
+	}
+
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(f.parallelism)
+	for hash, addr := range byHash {
+		g.Go(func() error {
+			code, err := f.provider.GetCodeAtBlock(gctx, addr, head)
+			if err != nil {
+				return fmt.Errorf("failed to get code for account %s at block %d: %w", addr.Hex(), head.Number.Uint64(), err)
+			}
+			if f.codes != nil {
+				if err = f.codes.Put(code); err != nil {
+					return fmt.Errorf("failed to cache code with hash %s: %w", hash.Hex(), err)
+				}
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			codes[hash] = code
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return err
+	}
+
+	for _, acc := range accs {
+		if acc.Account != nil {
+			acc.code = codes[acc.Account.CodeHash]
+		}
+	}
+	return nil
+}
+
+// cachedCode returns the code with the
+// specified hash from the code store, if
+// present.
+func (f *stateFetcher) cachedCode(hash common.Hash) ([]byte, bool) {
+	if f.codes == nil {
+		return nil, false
+	}
+
+	code, err := f.codes.Get(hash)
+	if err != nil {
+		return nil, false
+	}
+	return code, true
+}
@@ -0,0 +1,189 @@
+package state
+
+import (
+	"context"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"math/big"
+	"sparseth/ethstore"
+	"sparseth/execution/ethclient"
+	"sparseth/storage/mem"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fetcherTestProvider struct {
+	// code to be returned for all
+	// contract accounts
+	code []byte
+	// error to be returned by provider methods
+	err error
+
+	codeCalls int
+	active    int
+	maxActive int
+	mu        sync.Mutex
+}
+
+func (p *fetcherTestProvider) enter() {
+	p.mu.Lock()
+	p.active++
+	p.maxActive = max(p.maxActive, p.active)
+	p.mu.Unlock()
+
+	// Give concurrent calls time to overlap
+	time.Sleep(5 * time.Millisecond)
+
+	p.mu.Lock()
+	p.active--
+	p.mu.Unlock()
+}
+
+func (p *fetcherTestProvider) GetTxsAtBlock(context.Context, *types.Header) ([]*ethclient.TransactionWithIndex, error) {
+	return nil, nil
+}
+
+func (p *fetcherTestProvider) GetWithdrawalsAtBlock(context.Context, *types.Header) ([]*types.Withdrawal, error) {
+	return nil, nil
+}
+
+func (p *fetcherTestProvider) GetLogsAtBlock(context.Context, common.Address, *big.Int, []common.Hash) ([]*types.Log, error) {
+	return nil, nil
+}
+
+func (p *fetcherTestProvider) GetLogsInRange(context.Context, common.Address, *big.Int, *big.Int, []common.Hash) ([]*types.Log, error) {
+	return nil, nil
+}
+
+func (p *fetcherTestProvider) GetAccountAtBlock(context.Context, common.Address, *types.Header) (*ethclient.Account, error) {
+	return nil, nil
+}
+
+func (p *fetcherTestProvider) GetAccountsAtBlock(_ context.Context, reqs []*ethclient.ProofRequest, _ *types.Header) ([]*ethclient.AccountState, error) {
+	p.enter()
+
+	states := make([]*ethclient.AccountState, len(reqs))
+	for i, req := range reqs {
+		states[i] = &ethclient.AccountState{
+			Account: &ethclient.Account{
+				Address:  req.Address,
+				Balance:  big.NewInt(0),
+				CodeHash: crypto.Keccak256Hash(p.code),
+			},
+		}
+	}
+	return states, p.err
+}
+
+func (p *fetcherTestProvider) GetStorageAtBlock(context.Context, common.Address, common.Hash, *types.Header) ([]byte, error) {
+	return nil, nil
+}
+
+func (p *fetcherTestProvider) GetCodeAtBlock(context.Context, common.Address, *types.Header) ([]byte, error) {
+	p.mu.Lock()
+	p.codeCalls++
+	p.mu.Unlock()
+
+	return p.code, p.err
+}
+
+func (p *fetcherTestProvider) GetTransactionTrace(context.Context, common.Hash) (*ethclient.TransactionTrace, error) {
+	return nil, nil
+}
+
+func (p *fetcherTestProvider) GetBlockTraces(context.Context, *types.Header) ([]*ethclient.TransactionTrace, error) {
+	return nil, nil
+}
+
+func testProofRequests(n int) []*ethclient.ProofRequest {
+	reqs := make([]*ethclient.ProofRequest, n)
+	for i := range reqs {
+		reqs[i] = &ethclient.ProofRequest{Address: common.BigToAddress(big.NewInt(int64(i + 1)))}
+	}
+	return reqs
+}
+
+func TestStateFetcher_Fetch(t *testing.T) {
+	head := &types.Header{Number: big.NewInt(1)}
+
+	t.Run("should return accounts in order of requests", func(t *testing.T) {
+		f := newStateFetcher(&fetcherTestProvider{code: []byte{0x60}}, nil, 4)
+
+		reqs := testProofRequests(3 * fetchChunkSize)
+		accs, err := f.fetch(t.Context(), head, reqs)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(accs) != len(reqs) {
+			t.Fatalf("expected %d accounts, got %d", len(reqs), len(accs))
+		}
+		for i, acc := range accs {
+			if acc.Account.Address != reqs[i].Address {
+				t.Fatalf("expected account %s at index %d, got %s", reqs[i].Address.Hex(), i, acc.Account.Address.Hex())
+			}
+		}
+	})
+
+	t.Run("should not exceed parallelism", func(t *testing.T) {
+		provider := &fetcherTestProvider{}
+		f := newStateFetcher(provider, nil, 2)
+
+		if _, err := f.fetch(t.Context(), head, testProofRequests(8*fetchChunkSize)); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if provider.maxActive != 2 {
+			t.Errorf("expected 2 concurrent calls, got %d", provider.maxActive)
+		}
+	})
+
+	t.Run("should fetch shared code once", func(t *testing.T) {
+		provider := &fetcherTestProvider{code: []byte{0x60}}
+		f := newStateFetcher(provider, nil, 4)
+
+		accs, err := f.fetch(t.Context(), head, testProofRequests(3))
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if provider.codeCalls != 1 {
+			t.Errorf("expected 1 code call, got %d", provider.codeCalls)
+		}
+		for _, acc := range accs {
+			if len(acc.code) != 1 {
+				t.Errorf("expected code of account %s", acc.Account.Address.Hex())
+			}
+		}
+	})
+
+	t.Run("should not fetch cached code", func(t *testing.T) {
+		db := mem.New()
+		defer db.Close()
+
+		codes := ethstore.NewCodeStore(db)
+		provider := &fetcherTestProvider{code: []byte{0x60}}
+		f := newStateFetcher(provider, codes, 4)
+
+		for range 2 {
+			accs, err := f.fetch(t.Context(), head, testProofRequests(3))
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if len(accs[0].code) != 1 {
+				t.Errorf("expected code of account %s", accs[0].Account.Address.Hex())
+			}
+		}
+		if provider.codeCalls != 1 {
+			t.Errorf("expected 1 code call, got %d", provider.codeCalls)
+		}
+	})
+
+	t.Run("should return error if accounts cannot be fetched", func(t *testing.T) {
+		f := newStateFetcher(&fetcherTestProvider{err: fmt.Errorf("unavailable")}, nil, 4)
+
+		if _, err := f.fetch(t.Context(), head, testProofRequests(3)); err == nil {
+			t.Errorf("expected error, got nil")
+		}
+	})
+}
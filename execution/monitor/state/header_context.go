@@ -1,20 +1,39 @@
 package state
 
 import (
+	"errors"
+	"fmt"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/consensus"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/params"
+	"sparseth/ethstore"
+)
+
+var (
+	// ErrMissingAncestor is returned if a re-executed
+	// transaction reads the hash of an ancestor block
+	// whose header is not in the header store.
+	ErrMissingAncestor = errors.New("missing ancestor header")
 )
 
 // HeaderContext implements a minimal ChainContext,
-// providing only the chain configuration
+// providing the chain configuration and the headers
+// of ancestor blocks, which back the BLOCKHASH
+// opcode.
 //
 // This is used when executing transactions in
 // isolation, without the need for a full consensus
 // engine, see TxExecutor.ExecuteTxs.
 type HeaderContext struct {
 	Params *params.ChainConfig
+	// Store holds the ancestor headers,
+	// or is nil if lookup is disabled.
+	Store *ethstore.HeaderStore
+
+	// err records the first
+	// failed header lookup.
+	err error
 }
 
 // Engine returns the chain's consensus engine.
@@ -26,16 +45,45 @@ func (hc *HeaderContext) Engine() consensus.Engine {
 }
 
 // GetHeader retrieves a block header by its
-// hash and number.
+// hash and number from the header store.
 //
-// Note that we return nil for any hash-number
-// combination, as header lookup is not supported
-// in this context.
-func (hc *HeaderContext) GetHeader(_ common.Hash, _ uint64) *types.Header {
-	return nil
+// If no such header is stored, nil is returned,
+// and the failed lookup is reported by Err, as
+// the EVM silently treats the hash as zero.
+func (hc *HeaderContext) GetHeader(hash common.Hash, num uint64) *types.Header {
+	if hc.Store == nil {
+		hc.fail(fmt.Errorf("%w: block %d (%s), header lookup disabled", ErrMissingAncestor, num, hash.Hex()))
+		return nil
+	}
+
+	header, err := hc.Store.GetByHash(hash)
+	if err != nil {
+		hc.fail(fmt.Errorf("%w: block %d (%s): %w", ErrMissingAncestor, num, hash.Hex(), err))
+		return nil
+	}
+	if header.Number.Uint64() != num {
+		hc.fail(fmt.Errorf("%w: block %s has number %d, expected %d", ErrMissingAncestor, hash.Hex(), header.Number.Uint64(), num))
+		return nil
+	}
+	return header
 }
 
 // Config returns the chain's configuration.
 func (hc *HeaderContext) Config() *params.ChainConfig {
 	return hc.Params
 }
+
+// Err returns the first failed
+// header lookup, if any.
+func (hc *HeaderContext) Err() error {
+	return hc.err
+}
+
+// fail records the specified failed
+// header lookup, unless one has already
+// been recorded.
+func (hc *HeaderContext) fail(err error) {
+	if hc.err == nil {
+		hc.err = err
+	}
+}
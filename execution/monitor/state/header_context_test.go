@@ -0,0 +1,54 @@
+package state
+
+import (
+	"errors"
+	"github.com/ethereum/go-ethereum/core/types"
+	"math/big"
+	"sparseth/ethstore"
+	"sparseth/storage/mem"
+	"testing"
+)
+
+func TestHeaderContext_GetHeader(t *testing.T) {
+	ancestor := &types.Header{Number: big.NewInt(1)}
+
+	store := ethstore.NewHeaderStore(mem.New())
+	if err := store.Put(ancestor); err != nil {
+		t.Fatalf("failed to store header: %v", err)
+	}
+
+	t.Run("should return stored ancestor", func(t *testing.T) {
+		hc := &HeaderContext{Store: store}
+
+		header := hc.GetHeader(ancestor.Hash(), 1)
+		if header == nil || header.Hash() != ancestor.Hash() {
+			t.Fatalf("expected ancestor header, got %v", header)
+		}
+		if err := hc.Err(); err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("should record missing ancestor", func(t *testing.T) {
+		hc := &HeaderContext{Store: store}
+
+		missing := &types.Header{Number: big.NewInt(2)}
+		if header := hc.GetHeader(missing.Hash(), 2); header != nil {
+			t.Fatalf("expected no header, got %v", header)
+		}
+		if err := hc.Err(); !errors.Is(err, ErrMissingAncestor) {
+			t.Errorf("expected missing ancestor error, got %v", err)
+		}
+	})
+
+	t.Run("should record number mismatch", func(t *testing.T) {
+		hc := &HeaderContext{Store: store}
+
+		if header := hc.GetHeader(ancestor.Hash(), 2); header != nil {
+			t.Fatalf("expected no header, got %v", header)
+		}
+		if err := hc.Err(); !errors.Is(err, ErrMissingAncestor) {
+			t.Errorf("expected missing ancestor error, got %v", err)
+		}
+	})
+}
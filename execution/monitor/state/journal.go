@@ -4,7 +4,10 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/holiman/uint256"
+	"sparseth/ethstore"
 )
 
 // journalEntry records a change to the state that
@@ -122,3 +125,113 @@ func (j *journal) StorageChange(addr common.Address, slot, prev common.Hash) {
 		prev: prev,
 	})
 }
+
+// Diff returns the changes made since the last reset
+// per account, in order of first change, comparing
+// the value before the first change of each field
+// with its current value in the specified state.
+// Accounts without any effective change, e.g., as
+// a value was set to itself, are omitted.
+func (j *journal) Diff(db *state.StateDB) []*ethstore.AccountDiff {
+	type before struct {
+		nonce    *uint64
+		balance  *uint256.Int
+		codeHash *common.Hash
+		slots    []common.Hash
+		values   map[common.Hash]common.Hash
+	}
+
+	var order []common.Address
+	touched := make(map[common.Address]*before)
+	get := func(addr common.Address) *before {
+		b, ok := touched[addr]
+		if !ok {
+			b = &before{values: make(map[common.Hash]common.Hash)}
+			touched[addr] = b
+			order = append(order, addr)
+		}
+		return b
+	}
+
+	// The first entry of each field
+	// holds its original value
+	for _, entry := range j.entries {
+		switch e := entry.(type) {
+		case *nonceChange:
+			if b := get(e.addr); b.nonce == nil {
+				b.nonce = &e.prev
+			}
+		case *balanceChange:
+			if b := get(e.addr); b.balance == nil {
+				b.balance = e.prev
+			}
+		case *codeChange:
+			if b := get(e.addr); b.codeHash == nil {
+				hash := crypto.Keccak256Hash(e.prev)
+				if len(e.prev) == 0 {
+					hash = types.EmptyCodeHash
+				}
+				b.codeHash = &hash
+			}
+		case *storageChange:
+			b := get(e.addr)
+			if _, ok := b.values[e.slot]; !ok {
+				b.values[e.slot] = e.prev
+				b.slots = append(b.slots, e.slot)
+			}
+		}
+	}
+
+	diffs := make([]*ethstore.AccountDiff, 0, len(order))
+	for _, addr := range order {
+		b := touched[addr]
+		diff := &ethstore.AccountDiff{
+			Address:        addr,
+			NonceBefore:    db.GetNonce(addr),
+			NonceAfter:     db.GetNonce(addr),
+			BalanceBefore:  db.GetBalance(addr).ToBig(),
+			BalanceAfter:   db.GetBalance(addr).ToBig(),
+			CodeHashBefore: codeHashOf(db, addr),
+			CodeHashAfter:  codeHashOf(db, addr),
+		}
+		if b.nonce != nil {
+			diff.NonceBefore = *b.nonce
+		}
+		if b.balance != nil {
+			diff.BalanceBefore = b.balance.ToBig()
+		}
+		if b.codeHash != nil {
+			diff.CodeHashBefore = *b.codeHash
+		}
+		for _, slot := range b.slots {
+			after := db.GetState(addr, slot)
+			if prev := b.values[slot]; prev != after {
+				diff.Slots = append(diff.Slots, &ethstore.SlotDiff{
+					Slot:   slot,
+					Before: prev,
+					After:  after,
+				})
+			}
+		}
+
+		if diff.NonceBefore == diff.NonceAfter &&
+			diff.BalanceBefore.Cmp(diff.BalanceAfter) == 0 &&
+			diff.CodeHashBefore == diff.CodeHashAfter &&
+			len(diff.Slots) == 0 {
+			continue
+		}
+		diffs = append(diffs, diff)
+	}
+	return diffs
+}
+
+// codeHashOf returns the code hash of the
+// specified account, where accounts without
+// code have the empty code hash.
+func codeHashOf(db *state.StateDB, addr common.Address) common.Hash {
+	hash := db.GetCodeHash(addr)
+	if hash == (common.Hash{}) {
+		return types.EmptyCodeHash
+	}
+	return hash
+}
@@ -0,0 +1,82 @@
+package state
+
+import (
+	"context"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"sparseth/config"
+	"sparseth/ethstore"
+	"sparseth/execution/monitor"
+)
+
+// recordOutcomes stores the verification outcome of
+// the specified transactions of the specified block,
+// along with the monitored accounts each touched.
+// Receipts are matched by index, transactions
+// without a receipt are not recorded.
+//
+// Outcomes only serve lookups, so failures are
+// logged rather than failing the block.
+func (p *TxProcessor) recordOutcomes(head *types.Header, txs []*TransactionWithContext, receipts []*types.Receipt, accs *config.AccountsConfig, verified bool) {
+	if len(receipts) == 0 {
+		return
+	}
+
+	outcomes := make([]*ethstore.TxOutcome, 0, len(receipts))
+	for i, receipt := range receipts {
+		if i >= len(txs) {
+			break
+		}
+		outcomes = append(outcomes, &ethstore.TxOutcome{
+			TxHash:    receipt.TxHash,
+			Number:    head.Number.Uint64(),
+			BlockHash: head.Hash(),
+			Status:    receipt.Status,
+			Verified:  verified,
+			Accounts:  touchedAccounts(txs[i], accs),
+		})
+	}
+
+	if err := p.outcomes.PutAll(outcomes); err != nil {
+		p.log.Warn("failed to store tx outcomes", "num", head.Number, "hash", head.Hash().Hex(), "error", err)
+	}
+}
+
+// recordActivity records the re-executed transactions
+// of a verified block per touched account, see
+// monitor.RecordActivity.
+func recordActivity(ctx context.Context, txs []*TransactionWithContext, receipts []*types.Receipt, accs *config.AccountsConfig) {
+	for i := range receipts {
+		if i >= len(txs) {
+			break
+		}
+		for _, addr := range touchedAccounts(txs[i], accs) {
+			monitor.RecordActivity(ctx, addr, 1)
+		}
+	}
+}
+
+// touchedAccounts returns the specified accounts
+// that the specified transaction was sent from,
+// sent to, or touched during execution.
+func touchedAccounts(tx *TransactionWithContext, accs *config.AccountsConfig) []common.Address {
+	seen := make(map[common.Address]bool)
+	touched := make([]common.Address, 0)
+	add := func(addr common.Address) {
+		if !seen[addr] && accs.Contains(addr) {
+			seen[addr] = true
+			touched = append(touched, addr)
+		}
+	}
+
+	add(tx.Sender)
+	if tx.Tx.To() != nil {
+		add(*tx.Tx.To())
+	}
+	if tx.Trace != nil {
+		for _, acc := range tx.Trace.Accounts {
+			add(acc.Address)
+		}
+	}
+	return touched
+}
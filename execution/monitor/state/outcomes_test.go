@@ -0,0 +1,54 @@
+package state
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"log/slog"
+	"math/big"
+	"sparseth/config"
+	"sparseth/ethstore"
+	"sparseth/execution/ethclient"
+	"sparseth/internal/log"
+	"sparseth/storage/mem"
+	"testing"
+)
+
+func TestTxProcessor_RecordOutcomes(t *testing.T) {
+	monitored := common.HexToAddress("0x01")
+	other := common.HexToAddress("0x02")
+	accs := &config.AccountsConfig{Accounts: []*config.AccountConfig{{Addr: monitored}}}
+
+	newTx := func(nonce uint64) *TransactionWithContext {
+		return &TransactionWithContext{
+			Tx:     types.NewTx(&types.LegacyTx{Nonce: nonce, To: &other}),
+			Sender: other,
+			Trace: &ethclient.TransactionTrace{
+				Accounts: []*ethclient.AccountTrace{{Address: monitored}, {Address: other}},
+			},
+		}
+	}
+
+	t.Run("should record outcomes with touched accounts", func(t *testing.T) {
+		store := ethstore.NewTxStore(mem.New())
+		p := &TxProcessor{outcomes: store, log: log.New(slog.DiscardHandler)}
+
+		head := &types.Header{Number: big.NewInt(1)}
+		txs := []*TransactionWithContext{newTx(0), newTx(1)}
+		receipts := []*types.Receipt{
+			{TxHash: txs[0].Tx.Hash(), Status: types.ReceiptStatusSuccessful},
+			{TxHash: txs[1].Tx.Hash(), Status: types.ReceiptStatusFailed},
+		}
+		p.recordOutcomes(head, txs, receipts, accs, true)
+
+		outcome, err := store.Get(txs[1].Tx.Hash())
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if !outcome.Verified || outcome.Status != types.ReceiptStatusFailed || outcome.BlockHash != head.Hash() {
+			t.Errorf("expected verified failed tx at block %s, got %+v", head.Hash().Hex(), outcome)
+		}
+		if len(outcome.Accounts) != 1 || outcome.Accounts[0] != monitored {
+			t.Errorf("expected touched account %s, got %v", monitored.Hex(), outcome.Accounts)
+		}
+	})
+}
@@ -0,0 +1,222 @@
+package state
+
+import (
+	"context"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/lru"
+	"github.com/ethereum/go-ethereum/core/types"
+	"slices"
+	"sparseth/config"
+	"sparseth/execution/ethclient"
+)
+
+// prefetchBlocks is the maximum number of
+// blocks kept in the prefetch cache.
+const prefetchBlocks = 16
+
+// preparedBlock holds the data of a block
+// that was downloaded ahead of processing.
+type preparedBlock struct {
+	// txs holds all transactions of the
+	// block along with their context.
+	txs []*TransactionWithContext
+	// withdrawals holds all withdrawals
+	// of the block.
+	withdrawals []*types.Withdrawal
+	// relevant holds the transactions the
+	// transient state was loaded for, if any.
+	relevant []*TransactionWithContext
+	// world is the partial state before
+	// the block, or nil if not loaded.
+	world *TracingStateDB
+	// served records the providers
+	// that served the block data.
+	served *ethclient.ServedBy
+	// untouched holds the accounts the block
+	// leaves unchanged, if its traces were
+	// skipped, see Preparer.untouched.
+	untouched *config.AccountsConfig
+}
+
+// prefetchCache holds the blocks
+// prepared ahead of processing.
+type prefetchCache struct {
+	blocks *lru.Cache[common.Hash, *preparedBlock]
+}
+
+// newPrefetchCache creates a new,
+// empty prefetch cache.
+func newPrefetchCache() *prefetchCache {
+	return &prefetchCache{
+		blocks: lru.NewCache[common.Hash, *preparedBlock](prefetchBlocks),
+	}
+}
+
+// peek returns the prepared data of the
+// specified block without removing it, or
+// false if the block was not prefetched.
+func (c *prefetchCache) peek(head *types.Header) (*preparedBlock, bool) {
+	return c.blocks.Get(head.Hash())
+}
+
+// take returns and removes the prepared
+// data of the specified block, or false
+// if the block was not prefetched.
+func (c *prefetchCache) take(head *types.Header) (*preparedBlock, bool) {
+	block, ok := c.blocks.Get(head.Hash())
+	if ok {
+		c.blocks.Remove(head.Hash())
+	}
+	return block, ok
+}
+
+// Prefetch downloads all transactions of the
+// specified block along with their traces, so
+// the block is processed without waiting for
+// them once it is dispatched.
+//
+// Prefetch may be called concurrently with
+// ProcessBlock.
+func (p *TxProcessor) Prefetch(ctx context.Context, head *types.Header) error {
+	if _, ok := p.prefetched.peek(head); ok {
+		return nil
+	}
+
+	served := new(ethclient.ServedBy)
+	block, err := p.download(ethclient.WithServedBy(ctx, served), head)
+	if err != nil {
+		return err
+	}
+	block.served = served
+
+	p.prefetched.blocks.Add(head.Hash(), block)
+	return nil
+}
+
+// Prepare downloads all transactions of the specified
+// block, and loads the partial state required to
+// re-execute the relevant ones. Prepare is the first
+// stage of the processing pipeline: a block is
+// prepared while its predecessor is executed and
+// verified by ProcessBlock.
+//
+// The transient state is loaded for the accounts
+// monitored at the time of preparation. If the set
+// of monitored accounts changes before the block is
+// processed, ProcessBlock loads the state again.
+//
+// Prepare may be called concurrently with
+// ProcessBlock.
+func (p *TxProcessor) Prepare(ctx context.Context, head *types.Header) error {
+	if _, ok, _ := p.processed(head); ok {
+		// Replayed blocks are not processed again
+		return nil
+	}
+
+	block, ok := p.prefetched.peek(head)
+	if !ok {
+		served := new(ethclient.ServedBy)
+		downloaded, err := p.download(ethclient.WithServedBy(ctx, served), head)
+		if err != nil {
+			return err
+		}
+		block = downloaded
+		block.served = served
+	}
+	if block.world != nil {
+		return nil
+	}
+	ctx = ethclient.WithServedBy(ctx, block.served)
+
+	relevant := p.preparer.filterRelevant(head, block.txs)
+	if len(relevant) == 0 {
+		p.prefetched.blocks.Add(head.Hash(), block)
+		return nil
+	}
+
+	world, err := p.preparer.LoadState(ctx, head, relevant)
+	if err != nil {
+		// Keep the downloaded transactions,
+		// the state is loaded when processed
+		p.prefetched.blocks.Add(head.Hash(), block)
+		return fmt.Errorf("failed to load partial transient state for block %d: %w", head.Number.Uint64(), err)
+	}
+
+	p.prefetched.blocks.Add(head.Hash(), &preparedBlock{
+		txs:         block.txs,
+		withdrawals: block.withdrawals,
+		relevant:    relevant,
+		world:       world,
+		served:      block.served,
+		untouched:   block.untouched,
+	})
+	return nil
+}
+
+// download downloads all transactions of the
+// specified block along with their context, and
+// all withdrawals of the block.
+//
+// If the block leaves all monitored accounts
+// unchanged, no transactions are returned, as
+// their traces are not downloaded.
+func (p *TxProcessor) download(ctx context.Context, head *types.Header) (*preparedBlock, error) {
+	txs, err := p.provider.GetTxsAtBlock(ctx, head)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get txs at block %d: %w", head.Number.Uint64(), err)
+	}
+
+	accs := p.preparer.accounts()
+	if p.preparer.untouched(ctx, head, txs, accs) {
+		p.logWithContext("block leaves monitored accounts unchanged, skip traces", head)
+		return &preparedBlock{untouched: accs}, nil
+	}
+
+	// Breakers are tripped in order when the
+	// block is processed, before tracing
+	senders, err := p.preparer.sendersOf(head, txs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to recover senders at block %d: %w", head.Number.Uint64(), err)
+	}
+	if p.breaker.exceedsDirect(txs, senders, accs) {
+		return nil, fmt.Errorf("failed to download block %d: %w", head.Number.Uint64(), errBudgetExceeded)
+	}
+
+	withContext, err := p.preparer.getTxsWithContext(ctx, head, txs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transactions with context at block %d: %w", head.Number.Uint64(), err)
+	}
+
+	withdrawals, err := p.provider.GetWithdrawalsAtBlock(ctx, head)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get withdrawals at block %d: %w", head.Number.Uint64(), err)
+	}
+	return &preparedBlock{txs: withContext, withdrawals: withdrawals}, nil
+}
+
+// transientState returns the partial state before the
+// specified block for the specified transactions. The
+// prepared state is used if it was loaded for exactly
+// these transactions.
+func (p *TxProcessor) transientState(ctx context.Context, head *types.Header, block *preparedBlock, txs []*TransactionWithContext) (*TracingStateDB, error) {
+	if block != nil && block.world != nil && sameTxs(block.relevant, txs) {
+		p.logWithContext("use prepared state for block", head)
+		return block.world, nil
+	}
+
+	p.logWithContext("prepare state for block", head)
+	world, err := p.preparer.LoadState(ctx, head, txs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load partial transient state for block %d: %w", head.Number.Uint64(), err)
+	}
+	return world, nil
+}
+
+// sameTxs checks whether both lists
+// contain the same transactions.
+func sameTxs(a, b []*TransactionWithContext) bool {
+	return slices.EqualFunc(a, b, func(x, y *TransactionWithContext) bool {
+		return x.Index == y.Index && x.Tx.Hash() == y.Tx.Hash()
+	})
+}
@@ -0,0 +1,69 @@
+package state
+
+import (
+	"github.com/ethereum/go-ethereum/core/types"
+	"math/big"
+	"testing"
+)
+
+func TestPrefetchCache_Take(t *testing.T) {
+	t.Run("should return prefetched txs once", func(t *testing.T) {
+		c := newPrefetchCache()
+		head := &types.Header{Number: big.NewInt(1)}
+		c.blocks.Add(head.Hash(), &preparedBlock{txs: []*TransactionWithContext{{Index: 0}}})
+
+		block, ok := c.take(head)
+		if !ok || len(block.txs) != 1 {
+			t.Fatalf("expected 1 prefetched tx")
+		}
+
+		if _, ok = c.take(head); ok {
+			t.Errorf("expected no prefetched txs")
+		}
+	})
+
+	t.Run("should not return txs of other block", func(t *testing.T) {
+		c := newPrefetchCache()
+		c.blocks.Add((&types.Header{Number: big.NewInt(1)}).Hash(), &preparedBlock{})
+
+		if _, ok := c.take(&types.Header{Number: big.NewInt(2)}); ok {
+			t.Errorf("expected no prefetched txs")
+		}
+	})
+
+	t.Run("should keep block on peek", func(t *testing.T) {
+		c := newPrefetchCache()
+		head := &types.Header{Number: big.NewInt(1)}
+		c.blocks.Add(head.Hash(), &preparedBlock{})
+
+		if _, ok := c.peek(head); !ok {
+			t.Fatalf("expected prefetched block")
+		}
+		if _, ok := c.take(head); !ok {
+			t.Errorf("expected prefetched block after peek")
+		}
+	})
+}
+
+func TestSameTxs(t *testing.T) {
+	first := &TransactionWithContext{Tx: types.NewTx(&types.LegacyTx{Nonce: 1}), Index: 0}
+	second := &TransactionWithContext{Tx: types.NewTx(&types.LegacyTx{Nonce: 2}), Index: 1}
+
+	t.Run("should match same txs", func(t *testing.T) {
+		if !sameTxs([]*TransactionWithContext{first, second}, []*TransactionWithContext{first, second}) {
+			t.Errorf("expected same txs")
+		}
+	})
+
+	t.Run("should not match subset", func(t *testing.T) {
+		if sameTxs([]*TransactionWithContext{first, second}, []*TransactionWithContext{first}) {
+			t.Errorf("expected different txs")
+		}
+	})
+
+	t.Run("should not match other txs", func(t *testing.T) {
+		if sameTxs([]*TransactionWithContext{first}, []*TransactionWithContext{second}) {
+			t.Errorf("expected different txs")
+		}
+	})
+}
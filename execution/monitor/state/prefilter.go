@@ -0,0 +1,90 @@
+package state
+
+import (
+	"context"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"sparseth/config"
+	"sparseth/execution/ethclient"
+)
+
+// untouched checks whether the specified block
+// leaves all specified accounts unchanged, so that
+// the traces of its transactions are not required.
+//
+// The cheap checks come first: the block must not
+// log any event of an account, and no transaction
+// may be sent from or to an account. As accounts
+// may still be changed by internal calls, their
+// proven state before and after the block must be
+// equal as well.
+//
+// The check is an optimization only, i.e., any
+// error is treated as if the block touched an
+// account.
+func (p *Preparer) untouched(ctx context.Context, header *types.Header, txs []*ethclient.TransactionWithIndex, accs *config.AccountsConfig) bool {
+	tracked := make(map[common.Address]bool, len(accs.Accounts))
+	for _, acc := range accs.Accounts {
+		if types.BloomLookup(header.Bloom, acc.Addr) {
+			return false
+		}
+		tracked[acc.Addr] = true
+	}
+
+	plain := make([]*types.Transaction, len(txs))
+	for i, tx := range txs {
+		if tx.Tx.To() != nil && tracked[*tx.Tx.To()] {
+			return false
+		}
+		plain[i] = tx.Tx
+	}
+	senders, err := p.senders.Senders(types.MakeSigner(p.cc, header.Number, header.Time), plain)
+	if err != nil {
+		return false
+	}
+	for _, sender := range senders {
+		if tracked[sender] {
+			return false
+		}
+	}
+
+	parent, err := p.store.GetByHash(header.ParentHash)
+	if err != nil {
+		p.log.Debug("failed to get parent header, skip pre-filter", "num", header.Number, "hash", header.Hash().Hex(), "err", err)
+		return false
+	}
+
+	reqs := make([]*ethclient.ProofRequest, len(accs.Accounts))
+	for i, acc := range accs.Accounts {
+		reqs[i] = &ethclient.ProofRequest{Address: acc.Addr}
+	}
+	before, err := p.provider.GetAccountsAtBlock(ctx, reqs, parent)
+	if err != nil {
+		p.log.Debug("failed to get accounts before block, skip pre-filter", "num", header.Number, "hash", header.Hash().Hex(), "err", err)
+		return false
+	}
+	after, err := p.provider.GetAccountsAtBlock(ctx, reqs, header)
+	if err != nil {
+		p.log.Debug("failed to get accounts after block, skip pre-filter", "num", header.Number, "hash", header.Hash().Hex(), "err", err)
+		return false
+	}
+
+	for i := range reqs {
+		if !sameAccount(before[i].Account, after[i].Account) {
+			return false
+		}
+	}
+	return true
+}
+
+// sameAccount checks whether both accounts are
+// equal, where nil denotes a missing account.
+func sameAccount(a, b *ethclient.Account) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Nonce == b.Nonce &&
+		a.Balance.Cmp(b.Balance) == 0 &&
+		a.CodeHash == b.CodeHash &&
+		a.StorageRoot == b.StorageRoot
+}
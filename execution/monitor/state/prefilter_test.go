@@ -0,0 +1,130 @@
+package state
+
+import (
+	"context"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"log/slog"
+	"math/big"
+	"sparseth/config"
+	"sparseth/ethstore"
+	"sparseth/execution/ethclient"
+	"sparseth/internal/log"
+	"sparseth/storage/mem"
+	"testing"
+)
+
+type prefilterTestProvider struct {
+	preparerTestProvider
+	// accounts to be returned by
+	// GetAccountsAtBlock per block
+	accounts map[common.Hash]*ethclient.Account
+}
+
+func (p *prefilterTestProvider) GetAccountsAtBlock(_ context.Context, reqs []*ethclient.ProofRequest, head *types.Header) ([]*ethclient.AccountState, error) {
+	states := make([]*ethclient.AccountState, len(reqs))
+	for i := range reqs {
+		states[i] = &ethclient.AccountState{Account: p.accounts[head.Hash()]}
+	}
+	return states, nil
+}
+
+func TestPreparer_Untouched(t *testing.T) {
+	testLogger := log.New(slog.DiscardHandler)
+	cc := params.TestChainConfig
+
+	monitored := common.HexToAddress("0xaa")
+	accs := &config.AccountsConfig{
+		Accounts: []*config.AccountConfig{{Addr: monitored}},
+	}
+
+	parent := &types.Header{Number: big.NewInt(1)}
+	header := &types.Header{Number: big.NewInt(2), ParentHash: parent.Hash()}
+
+	store := ethstore.NewHeaderStore(mem.New())
+	if err := store.Put(parent); err != nil {
+		t.Fatalf("failed to store parent: %v", err)
+	}
+
+	sk, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("failed to generate secret key: %v", err)
+	}
+	signTx := func(to common.Address) *ethclient.TransactionWithIndex {
+		tx, err := types.SignNewTx(sk, types.LatestSigner(cc), &types.LegacyTx{To: &to, Gas: 21000, GasPrice: big.NewInt(1)})
+		if err != nil {
+			t.Fatalf("failed to sign transaction: %v", err)
+		}
+		return &ethclient.TransactionWithIndex{Tx: tx}
+	}
+
+	unchanged := func() *prefilterTestProvider {
+		acc := &ethclient.Account{Address: monitored, Nonce: 1, Balance: big.NewInt(1)}
+		return &prefilterTestProvider{accounts: map[common.Hash]*ethclient.Account{
+			parent.Hash(): acc,
+			header.Hash(): acc,
+		}}
+	}
+
+	t.Run("should skip block leaving accounts unchanged", func(t *testing.T) {
+		preparer := NewPreparer(unchanged(), store, nil, accs, cc, 1, nil, testLogger)
+
+		txs := []*ethclient.TransactionWithIndex{signTx(common.HexToAddress("0xbb"))}
+		if !preparer.untouched(t.Context(), header, txs, accs) {
+			t.Errorf("expected block to be untouched")
+		}
+	})
+
+	t.Run("should not skip block logging account", func(t *testing.T) {
+		preparer := NewPreparer(unchanged(), store, nil, accs, cc, 1, nil, testLogger)
+
+		logged := types.CopyHeader(header)
+		logged.Bloom = types.CreateBloom(&types.Receipt{Logs: []*types.Log{{Address: monitored}}})
+		if preparer.untouched(t.Context(), logged, nil, accs) {
+			t.Errorf("expected block to be touched")
+		}
+	})
+
+	t.Run("should not skip block with tx to account", func(t *testing.T) {
+		preparer := NewPreparer(unchanged(), store, nil, accs, cc, 1, nil, testLogger)
+
+		txs := []*ethclient.TransactionWithIndex{signTx(monitored)}
+		if preparer.untouched(t.Context(), header, txs, accs) {
+			t.Errorf("expected block to be touched")
+		}
+	})
+
+	t.Run("should not skip block with tx from account", func(t *testing.T) {
+		sender := &config.AccountsConfig{
+			Accounts: []*config.AccountConfig{{Addr: crypto.PubkeyToAddress(sk.PublicKey)}},
+		}
+		preparer := NewPreparer(unchanged(), store, nil, sender, cc, 1, nil, testLogger)
+
+		txs := []*ethclient.TransactionWithIndex{signTx(common.HexToAddress("0xbb"))}
+		if preparer.untouched(t.Context(), header, txs, sender) {
+			t.Errorf("expected block to be touched")
+		}
+	})
+
+	t.Run("should not skip block changing account", func(t *testing.T) {
+		provider := &prefilterTestProvider{accounts: map[common.Hash]*ethclient.Account{
+			parent.Hash(): {Address: monitored, Nonce: 1, Balance: big.NewInt(1)},
+			header.Hash(): {Address: monitored, Nonce: 1, Balance: big.NewInt(2)},
+		}}
+		preparer := NewPreparer(provider, store, nil, accs, cc, 1, nil, testLogger)
+
+		if preparer.untouched(t.Context(), header, nil, accs) {
+			t.Errorf("expected block to be touched")
+		}
+	})
+
+	t.Run("should not skip block with unknown parent", func(t *testing.T) {
+		preparer := NewPreparer(unchanged(), ethstore.NewHeaderStore(mem.New()), nil, accs, cc, 1, nil, testLogger)
+
+		if preparer.untouched(t.Context(), header, nil, accs) {
+			t.Errorf("expected block to be touched")
+		}
+	})
+}
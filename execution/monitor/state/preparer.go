@@ -2,6 +2,7 @@ package state
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/rawdb"
@@ -9,6 +10,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/tracing"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/ethereum/go-ethereum/triedb"
 	"github.com/holiman/uint256"
 	"slices"
@@ -17,8 +19,14 @@ import (
 	"sparseth/execution/ethclient"
 	"sparseth/log"
 	"sparseth/storage/mem"
+	"sync"
+	"sync/atomic"
 )
 
+// methodNotFoundCode is the JSON-RPC error
+// code of calls to unknown methods.
+const methodNotFoundCode = -32601
+
 // TransactionWithContext wraps a transaction
 // with its context, i.e., the index, sender,
 // and transaction trace
@@ -34,26 +42,56 @@ type TransactionWithContext struct {
 //   - Loading the partial state just before a specified block.
 type Preparer struct {
 	provider ethclient.Provider
+	fetcher  *stateFetcher
 	store    *ethstore.HeaderStore
 	accs     *config.AccountsConfig
 	cc       *params.ChainConfig
+	senders  *SenderCache
+
+	// noBlockTraces indicates that the provider
+	// does not support block-level traces.
+	noBlockTraces atomic.Bool
 
 	log log.Logger
+	mu  sync.RWMutex
 }
 
 // NewPreparer creates a new Preparer with the
 // specified provider and chain configuration,
 // reading headers from the specified store.
-func NewPreparer(provider ethclient.Provider, store *ethstore.HeaderStore, accs *config.AccountsConfig, cc *params.ChainConfig, log log.Logger) *Preparer {
+// The state of a block is fetched with at most
+// the specified number of concurrent provider
+// calls, looking up code in the specified code
+// store first. Transaction senders are looked up
+// in the specified cache.
+func NewPreparer(provider ethclient.Provider, store *ethstore.HeaderStore, codes *ethstore.CodeStore, accs *config.AccountsConfig, cc *params.ChainConfig, parallelism int, senders *SenderCache, log log.Logger) *Preparer {
 	return &Preparer{
 		provider: provider,
+		fetcher:  newStateFetcher(provider, codes, parallelism),
 		store:    store,
 		accs:     accs,
 		cc:       cc,
+		senders:  senders,
 		log:      log.With("component", "state-preparer"),
 	}
 }
 
+// setAccounts replaces the set of monitored accounts.
+func (p *Preparer) setAccounts(accs *config.AccountsConfig) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.accs = accs
+}
+
+// accounts returns the set of monitored accounts.
+func (p *Preparer) accounts() *config.AccountsConfig {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.accs
+}
+
 // FilterTxs filters a list of transactions to include only those
 // that are relevant to the monitored accounts.
 //
@@ -77,8 +115,15 @@ func (p *Preparer) FilterTxs(ctx context.Context, header *types.Header, txs []*e
 		return nil, fmt.Errorf("failed to get transactions with context: %w", err)
 	}
 
+	return p.filterRelevant(header, txsWithContext), nil
+}
+
+// filterRelevant filters the specified transactions
+// to include only those that are relevant to the
+// monitored accounts, see FilterTxs.
+func (p *Preparer) filterRelevant(header *types.Header, txsWithContext []*TransactionWithContext) []*TransactionWithContext {
 	trackedAccs := make(map[common.Address]bool)
-	for _, acc := range p.accs.Accounts {
+	for _, acc := range p.accounts().Accounts {
 		trackedAccs[acc.Addr] = true
 	}
 
@@ -104,7 +149,7 @@ func (p *Preparer) FilterTxs(ctx context.Context, header *types.Header, txs []*e
 	}
 
 	slices.Reverse(relevantTxs)
-	return relevantTxs, nil
+	return relevantTxs
 }
 
 // LoadState reconstructs the partial state immediately before
@@ -134,16 +179,21 @@ func (p *Preparer) LoadState(ctx context.Context, header *types.Header, txs []*T
 		return nil, fmt.Errorf("failed to get previous header: %w", err)
 	}
 
-	if err = p.createAccount(ctx, prev, header.Coinbase, world); err != nil {
-		return nil, fmt.Errorf("failed to create coinbase account %s at block %d: %w", header.Coinbase.Hex(), prev.Number.Uint64(), err)
+	// Reconstruct the partial state before the
+	// current block, fetching all state at once
+	accs, err := p.fetcher.fetch(ctx, prev, proofRequests(header, txs, systemCallSlots(p.cc, header)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch state at block %d: %w", prev.Number.Uint64(), err)
 	}
-
-	// Reconstruct the partial state
-	// before the current block
-	for _, t := range txs {
-		if err = p.createStateForTx(ctx, prev, t, world); err != nil {
-			return nil, fmt.Errorf("failed to create state for transaction at block %d: %w", prev.Number.Uint64(), err)
+	if reqs := delegateRequests(accs); len(reqs) > 0 {
+		delegates, err := p.fetcher.fetch(ctx, prev, reqs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch delegates at block %d: %w", prev.Number.Uint64(), err)
 		}
+		accs = append(accs, delegates...)
+	}
+	for _, acc := range accs {
+		createAccount(acc, world)
 	}
 
 	root, err := world.Commit(prev.Number.Uint64(), false, false)
@@ -156,32 +206,102 @@ func (p *Preparer) LoadState(ctx context.Context, header *types.Header, txs []*T
 
 // getTxsWithContext retrieves the context for the
 // specified transactions at the given block.
+//
+// The traces of all transactions are retrieved with a
+// single block-level trace, if supported by the provider,
+// and with one trace per transaction otherwise.
 func (p *Preparer) getTxsWithContext(ctx context.Context, header *types.Header, txs []*ethclient.TransactionWithIndex) ([]*TransactionWithContext, error) {
-	result := make([]*TransactionWithContext, len(txs))
+	traces := p.blockTraces(ctx, header, txs)
 
-	for i, tx := range txs {
-		signer := types.MakeSigner(p.cc, header.Number, header.Time)
-		from, err := signer.Sender(tx.Tx)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get sender from tx at index %d: %w", i, err)
-		}
+	senders, err := p.sendersOf(header, txs)
+	if err != nil {
+		return nil, err
+	}
 
-		trace, err := p.provider.GetTransactionTrace(ctx, tx.Tx.Hash())
-		if err != nil {
-			return nil, fmt.Errorf("failed to create access list for transaction %d: %w", i, err)
+	result := make([]*TransactionWithContext, len(txs))
+	for i, tx := range txs {
+		var trace *ethclient.TransactionTrace
+		if traces != nil {
+			trace = traces[i]
+		} else {
+			trace, err = p.provider.GetTransactionTrace(ctx, tx.Tx.Hash())
+			if err != nil {
+				return nil, fmt.Errorf("failed to create access list for transaction %d: %w", i, err)
+			}
 		}
 
 		result[i] = &TransactionWithContext{
 			Tx:     tx.Tx,
 			Index:  tx.Index,
 			Trace:  trace,
-			Sender: from,
+			Sender: senders[i],
 		}
 	}
 
 	return result, nil
 }
 
+// sendersOf recovers the senders of the specified
+// transactions of the specified block.
+func (p *Preparer) sendersOf(header *types.Header, txs []*ethclient.TransactionWithIndex) ([]common.Address, error) {
+	plain := make([]*types.Transaction, len(txs))
+	for i, tx := range txs {
+		plain[i] = tx.Tx
+	}
+	return p.senders.Senders(types.MakeSigner(p.cc, header.Number, header.Time), plain)
+}
+
+// blockTraces retrieves the traces of the specified
+// transactions with a single block-level trace, or
+// returns nil if the traces must be retrieved per
+// transaction instead.
+//
+// If the provider does not support block-level traces,
+// they are disabled for all subsequent blocks.
+func (p *Preparer) blockTraces(ctx context.Context, header *types.Header, txs []*ethclient.TransactionWithIndex) []*ethclient.TransactionTrace {
+	if len(txs) == 0 || p.noBlockTraces.Load() {
+		return nil
+	}
+
+	traces, err := p.provider.GetBlockTraces(ctx, header)
+	if err != nil {
+		if isUnsupported(err) {
+			p.log.Info("block traces not supported, fall back to transaction traces", "err", err)
+			p.noBlockTraces.Store(true)
+		} else {
+			p.log.Debug("failed to get block traces, fall back to transaction traces", "num", header.Number, "hash", header.Hash().Hex(), "err", err)
+		}
+		return nil
+	}
+
+	// Traces are not verified, so they are only used
+	// if they match the verified transactions
+	if len(traces) != len(txs) {
+		p.log.Warn("block traces do not match transactions, fall back to transaction traces", "num", header.Number, "hash", header.Hash().Hex(), "traces", len(traces), "txs", len(txs))
+		return nil
+	}
+	for i, tx := range txs {
+		if traces[i].TxHash != (common.Hash{}) && traces[i].TxHash != tx.Tx.Hash() {
+			p.log.Warn("block traces do not match transactions, fall back to transaction traces", "num", header.Number, "hash", header.Hash().Hex(), "index", i)
+			return nil
+		}
+	}
+
+	return traces
+}
+
+// isUnsupported checks whether the specified error
+// indicates that a method is not supported by the
+// provider.
+func isUnsupported(err error) bool {
+	if errors.Is(err, ethclient.ErrMethodNotAllowed) {
+		return true
+	}
+
+	var rpcErr rpc.Error
+	return errors.As(err, &rpcErr) && rpcErr.ErrorCode() == methodNotFoundCode
+}
+
 // isRelevant checks whether the transaction is
 // relevant to the tracked accounts.
 func isRelevant(tx *TransactionWithContext, trackedAccs map[common.Address]bool) bool {
@@ -195,6 +315,14 @@ func isRelevant(tx *TransactionWithContext, trackedAccs map[common.Address]bool)
 		return true
 	}
 
+	// Authorities may not be part of the trace,
+	// yet their code and nonce are changed
+	for _, authority := range authorities(tx.Tx) {
+		if trackedAccs[authority] {
+			return true
+		}
+	}
+
 	for _, acc := range tx.Trace.Accounts {
 		if trackedAccs[acc.Address] {
 			return true
@@ -204,73 +332,83 @@ func isRelevant(tx *TransactionWithContext, trackedAccs map[common.Address]bool)
 	return false
 }
 
-// createStateForTx creates the relevant accounts
-// for the specified transaction in the specified
-// world state.
-func (p *Preparer) createStateForTx(ctx context.Context, head *types.Header, tx *TransactionWithContext, world *TracingStateDB) error {
-	// Create sender
-	if err := p.createAccount(ctx, head, tx.Sender, world); err != nil {
-		return fmt.Errorf("failed to create sender account %s at block %d: %w", tx.Sender.Hex(), head.Number.Uint64(), err)
+// proofRequests collects the accounts and storage slots
+// accessed by the specified transactions, i.e., their
+// senders, recipients, authorities and their delegates,
+// and traced accounts, as well as the coinbase of the
+// specified block and the specified slots of the system
+// contracts called before the transactions.
+func proofRequests(header *types.Header, txs []*TransactionWithContext, system map[common.Address][]common.Hash) []*ethclient.ProofRequest {
+	var reqs []*ethclient.ProofRequest
+	byAddr := make(map[common.Address]*ethclient.ProofRequest)
+	slots := make(map[common.Address]map[common.Hash]bool)
+
+	add := func(addr common.Address, accessed []common.Hash) {
+		req, ok := byAddr[addr]
+		if !ok {
+			req = &ethclient.ProofRequest{Address: addr}
+			byAddr[addr] = req
+			slots[addr] = make(map[common.Hash]bool)
+			reqs = append(reqs, req)
+		}
+		for _, slot := range accessed {
+			if !slots[addr][slot] {
+				slots[addr][slot] = true
+				req.Slots = append(req.Slots, slot)
+			}
+		}
 	}
 
-	// A nil receiver indicates a contract
-	// creation transaction
-	if tx.Tx.To() != nil {
-		if err := p.createAccount(ctx, head, *tx.Tx.To(), world); err != nil {
-			return fmt.Errorf("failed to create receiver account %s at block %d: %w", tx.Tx.To().Hex(), head.Number.Uint64(), err)
-		}
+	add(header.Coinbase, nil)
+	for addr, accessed := range system {
+		add(addr, accessed)
 	}
+	for _, tx := range txs {
+		add(tx.Sender, nil)
 
-	for _, acc := range tx.Trace.Accounts {
-		if err := p.createAccount(ctx, head, acc.Address, world); err != nil {
-			return fmt.Errorf("failed to create account %s at block %d: %w", acc.Address.Hex(), head.Number.Uint64(), err)
+		// A nil receiver indicates a contract
+		// creation transaction
+		if tx.Tx.To() != nil {
+			add(*tx.Tx.To(), nil)
 		}
 
-		for _, slot := range acc.Storage.Slots {
-			if world.Exist(acc.Address) {
-				val, err := p.provider.GetStorageAtBlock(ctx, acc.Address, slot, head)
-				if err != nil {
-					return fmt.Errorf("failed to get storage slot %s for account %s at block %d: %w", slot.Hex(), acc.Address.Hex(), head.Number.Uint64(), err)
-				}
-				world.SetState(acc.Address, slot, common.BytesToHash(val))
-			}
+		// Authorities and their new delegates
+		// are accessed before execution
+		for _, authority := range authorities(tx.Tx) {
+			add(authority, nil)
+		}
+		for _, auth := range tx.Tx.SetCodeAuthorizations() {
+			add(auth.Address, nil)
+		}
+
+		for _, acc := range tx.Trace.Accounts {
+			add(acc.Address, acc.Storage.Slots)
 		}
 	}
 
-	return nil
+	return reqs
 }
 
-// createAccount creates an account in the
-// world state for the specified address.
-// Note that storage is not initialized.
-func (p *Preparer) createAccount(ctx context.Context, head *types.Header, addr common.Address, world *TracingStateDB) error {
-	if world.Exist(addr) {
-		// Account already exists,
-		// nothing to create
-		return nil
-	}
-
-	acc, err := p.provider.GetAccountAtBlock(ctx, addr, head)
-	if err != nil {
-		return fmt.Errorf("failed to get account at block %d: %w", head.Number.Uint64(), err)
-	}
+// createAccount creates the specified verified
+// account, including its code and the values of
+// its fetched storage slots, in the world state.
+func createAccount(fetched *fetchedAccount, world *TracingStateDB) {
+	acc := fetched.Account
 	if acc == nil {
 		// Account does not exist,
 		// nothing to create
-		return nil
+		return
 	}
 
 	world.CreateAccount(acc.Address)
 	world.SetNonce(acc.Address, acc.Nonce, tracing.NonceChangeUnspecified)
 	world.SetBalance(acc.Address, uint256.MustFromBig(acc.Balance), tracing.BalanceChangeUnspecified)
 
-	if acc.CodeHash != types.EmptyCodeHash {
-		code, err := p.provider.GetCodeAtBlock(ctx, acc.Address, head)
-		if err != nil {
-			return fmt.Errorf("failed to get code for account %s at block %d: %w", acc.Address.Hex(), head.Number.Uint64(), err)
-		}
-		world.SetCode(acc.Address, code)
+	if fetched.code != nil {
+		world.SetCode(acc.Address, fetched.code)
 	}
 
-	return nil
+	for slot, val := range fetched.Storage {
+		world.SetState(acc.Address, slot, common.BytesToHash(val))
+	}
 }
@@ -10,23 +10,43 @@ import (
 	"log/slog"
 	"math/big"
 	"sparseth/config"
+	"sparseth/ethstore"
 	"sparseth/execution/ethclient"
 	"sparseth/internal/log"
+	"sparseth/storage/mem"
 	"testing"
 )
 
 type preparerTestProvider struct {
 	// trace to be returned by GetTransactionTrace
 	tr *ethclient.TransactionTrace
+	// traces to be returned by GetBlockTraces
+	blockTrs []*ethclient.TransactionTrace
+	// error to be returned by GetBlockTraces
+	blockErr error
 	// error to be returned by provider methods
 	err error
+	// number of GetTransactionTrace calls
+	txTraces int
+	// states to be returned by GetAccountsAtBlock
+	states map[common.Address]*ethclient.AccountState
+	// requests passed to GetAccountsAtBlock
+	reqs []*ethclient.ProofRequest
 }
 
 func (p *preparerTestProvider) GetTxsAtBlock(ctx context.Context, header *types.Header) ([]*ethclient.TransactionWithIndex, error) {
 	return nil, nil
 }
 
-func (p *preparerTestProvider) GetLogsAtBlock(ctx context.Context, acc common.Address, blockNum *big.Int) ([]*types.Log, error) {
+func (p *preparerTestProvider) GetWithdrawalsAtBlock(ctx context.Context, header *types.Header) ([]*types.Withdrawal, error) {
+	return nil, nil
+}
+
+func (p *preparerTestProvider) GetLogsAtBlock(ctx context.Context, acc common.Address, blockNum *big.Int, topics []common.Hash) ([]*types.Log, error) {
+	return nil, nil
+}
+
+func (p *preparerTestProvider) GetLogsInRange(context.Context, common.Address, *big.Int, *big.Int, []common.Hash) ([]*types.Log, error) {
 	return nil, nil
 }
 
@@ -34,6 +54,18 @@ func (p *preparerTestProvider) GetAccountAtBlock(ctx context.Context, acc common
 	return nil, nil
 }
 
+func (p *preparerTestProvider) GetAccountsAtBlock(ctx context.Context, reqs []*ethclient.ProofRequest, head *types.Header) ([]*ethclient.AccountState, error) {
+	p.reqs = reqs
+	states := make([]*ethclient.AccountState, len(reqs))
+	for i, req := range reqs {
+		states[i] = &ethclient.AccountState{}
+		if st, ok := p.states[req.Address]; ok {
+			states[i] = st
+		}
+	}
+	return states, p.err
+}
+
 func (p *preparerTestProvider) GetStorageAtBlock(ctx context.Context, acc common.Address, slot common.Hash, head *types.Header) ([]byte, error) {
 	return nil, nil
 }
@@ -43,9 +75,17 @@ func (p *preparerTestProvider) GetCodeAtBlock(ctx context.Context, acc common.Ad
 }
 
 func (p *preparerTestProvider) GetTransactionTrace(ctx context.Context, txHash common.Hash) (*ethclient.TransactionTrace, error) {
+	p.txTraces++
 	return p.tr, p.err
 }
 
+func (p *preparerTestProvider) GetBlockTraces(ctx context.Context, header *types.Header) ([]*ethclient.TransactionTrace, error) {
+	if p.blockTrs == nil && p.blockErr == nil {
+		return nil, ethclient.ErrMethodNotAllowed
+	}
+	return p.blockTrs, p.blockErr
+}
+
 func TestPreparer_FilterTxs(t *testing.T) {
 	testLogger := log.New(slog.DiscardHandler)
 
@@ -92,7 +132,7 @@ func TestPreparer_FilterTxs(t *testing.T) {
 			},
 		}
 
-		preparer := NewPreparer(provider, nil, accs, cc, testLogger)
+		preparer := NewPreparer(provider, nil, nil, accs, cc, 1, nil, testLogger)
 		filtered, err := preparer.FilterTxs(t.Context(), header, txs)
 		if err == nil {
 			t.Errorf("expected error, got nil")
@@ -140,7 +180,7 @@ func TestPreparer_FilterTxs(t *testing.T) {
 			},
 		}
 
-		preparer := NewPreparer(provider, nil, accs, cc, testLogger)
+		preparer := NewPreparer(provider, nil, nil, accs, cc, 1, nil, testLogger)
 		filtered, err := preparer.FilterTxs(t.Context(), header, txs)
 		if err != nil {
 			t.Errorf("expected no error, got: %v", err)
@@ -193,7 +233,7 @@ func TestPreparer_FilterTxs(t *testing.T) {
 			},
 		}
 
-		preparer := NewPreparer(provider, nil, accs, cc, testLogger)
+		preparer := NewPreparer(provider, nil, nil, accs, cc, 1, nil, testLogger)
 		filtered, err := preparer.FilterTxs(t.Context(), header, txs)
 		if err != nil {
 			t.Errorf("expected no error, got: %v", err)
@@ -247,7 +287,7 @@ func TestPreparer_FilterTxs(t *testing.T) {
 			},
 		}
 
-		preparer := NewPreparer(provider, nil, accs, cc, testLogger)
+		preparer := NewPreparer(provider, nil, nil, accs, cc, 1, nil, testLogger)
 		filtered, err := preparer.FilterTxs(t.Context(), header, txs)
 		if err != nil {
 			t.Errorf("expected no error, got: %v", err)
@@ -313,7 +353,7 @@ func TestPreparer_FilterTxs(t *testing.T) {
 			},
 		}
 
-		preparer := NewPreparer(provider, nil, accs, cc, testLogger)
+		preparer := NewPreparer(provider, nil, nil, accs, cc, 1, nil, testLogger)
 		filtered, err := preparer.FilterTxs(t.Context(), header, txs)
 		if err != nil {
 			t.Errorf("expected no error, got: %v", err)
@@ -381,7 +421,7 @@ func TestPreparer_FilterTxs(t *testing.T) {
 			},
 		}
 
-		preparer := NewPreparer(provider, nil, accs, cc, testLogger)
+		preparer := NewPreparer(provider, nil, nil, accs, cc, 1, nil, testLogger)
 		filtered, err := preparer.FilterTxs(t.Context(), header, txs)
 		if err != nil {
 			t.Errorf("expected no error, got: %v", err)
@@ -449,7 +489,7 @@ func TestPreparer_FilterTxs(t *testing.T) {
 			},
 		}
 
-		preparer := NewPreparer(provider, nil, accs, cc, testLogger)
+		preparer := NewPreparer(provider, nil, nil, accs, cc, 1, nil, testLogger)
 		filtered, err := preparer.FilterTxs(t.Context(), header, txs)
 		if err != nil {
 			t.Errorf("expected no error, got: %v", err)
@@ -459,3 +499,168 @@ func TestPreparer_FilterTxs(t *testing.T) {
 		}
 	})
 }
+
+func TestPreparer_getTxsWithContext(t *testing.T) {
+	testLogger := log.New(slog.DiscardHandler)
+
+	sk, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("failed to generate secret key: %v", err)
+	}
+
+	cc := params.TestChainConfig
+	header := &types.Header{Number: big.NewInt(1), Time: 1}
+	signedTx, err := types.SignNewTx(sk, types.LatestSigner(cc), &types.DynamicFeeTx{
+		To:        &common.Address{},
+		Gas:       21000,
+		GasFeeCap: big.NewInt(1),
+	})
+	if err != nil {
+		t.Fatalf("failed to sign transaction: %v", err)
+	}
+	txs := []*ethclient.TransactionWithIndex{{Tx: signedTx, Index: 0}}
+	accs := &config.AccountsConfig{}
+
+	t.Run("should use block traces", func(t *testing.T) {
+		blockTr := &ethclient.TransactionTrace{TxHash: signedTx.Hash()}
+		provider := &preparerTestProvider{
+			blockTrs: []*ethclient.TransactionTrace{blockTr},
+		}
+
+		preparer := NewPreparer(provider, nil, nil, accs, cc, 1, nil, testLogger)
+		result, err := preparer.getTxsWithContext(t.Context(), header, txs)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if result[0].Trace != blockTr {
+			t.Errorf("expected block trace")
+		}
+		if provider.txTraces != 0 {
+			t.Errorf("expected no transaction traces, got %d", provider.txTraces)
+		}
+	})
+
+	t.Run("should fall back to transaction traces if unsupported", func(t *testing.T) {
+		provider := &preparerTestProvider{
+			tr: &ethclient.TransactionTrace{},
+		}
+
+		preparer := NewPreparer(provider, nil, nil, accs, cc, 1, nil, testLogger)
+		for range 2 {
+			if _, err := preparer.getTxsWithContext(t.Context(), header, txs); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+		}
+		if provider.txTraces != 2 {
+			t.Errorf("expected 2 transaction traces, got %d", provider.txTraces)
+		}
+		if !preparer.noBlockTraces.Load() {
+			t.Errorf("expected block traces to be disabled")
+		}
+	})
+
+	t.Run("should fall back to transaction traces if block traces do not match", func(t *testing.T) {
+		provider := &preparerTestProvider{
+			tr:       &ethclient.TransactionTrace{},
+			blockTrs: []*ethclient.TransactionTrace{{TxHash: common.HexToHash("0x01")}},
+		}
+
+		preparer := NewPreparer(provider, nil, nil, accs, cc, 1, nil, testLogger)
+		result, err := preparer.getTxsWithContext(t.Context(), header, txs)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if result[0].Trace != provider.tr {
+			t.Errorf("expected transaction trace")
+		}
+		if preparer.noBlockTraces.Load() {
+			t.Errorf("expected block traces to remain enabled")
+		}
+	})
+}
+
+func TestPreparer_LoadState(t *testing.T) {
+	testLogger := log.New(slog.DiscardHandler)
+
+	sender := common.HexToAddress("0x01")
+	contract := common.HexToAddress("0x02")
+	coinbase := common.HexToAddress("0x03")
+	slot := common.HexToHash("0x04")
+
+	tx := &TransactionWithContext{
+		Tx:     types.NewTx(&types.LegacyTx{To: &contract}),
+		Sender: sender,
+		Trace: &ethclient.TransactionTrace{
+			Accounts: []*ethclient.AccountTrace{
+				{Address: sender, Storage: &ethclient.StorageTrace{}},
+				{Address: contract, Storage: &ethclient.StorageTrace{Slots: []common.Hash{slot, slot}}},
+			},
+		},
+	}
+
+	prev := &types.Header{Number: big.NewInt(1)}
+	header := &types.Header{Number: big.NewInt(2), Coinbase: coinbase}
+
+	store := ethstore.NewHeaderStore(mem.New())
+	if err := store.Put(prev); err != nil {
+		t.Fatalf("failed to store previous header: %v", err)
+	}
+
+	t.Run("should request each account once", func(t *testing.T) {
+		provider := &preparerTestProvider{}
+		preparer := NewPreparer(provider, store, nil, &config.AccountsConfig{}, params.TestChainConfig, 1, nil, testLogger)
+
+		if _, err := preparer.LoadState(t.Context(), header, []*TransactionWithContext{tx, tx}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if len(provider.reqs) != 3 {
+			t.Fatalf("expected 3 requests, got %d", len(provider.reqs))
+		}
+		if provider.reqs[0].Address != coinbase {
+			t.Errorf("expected coinbase to be requested first, got %s", provider.reqs[0].Address.Hex())
+		}
+		if len(provider.reqs[2].Slots) != 1 || provider.reqs[2].Slots[0] != slot {
+			t.Errorf("expected single slot of contract to be requested, got %v", provider.reqs[2].Slots)
+		}
+	})
+
+	t.Run("should create fetched accounts and storage", func(t *testing.T) {
+		provider := &preparerTestProvider{
+			states: map[common.Address]*ethclient.AccountState{
+				sender: {
+					Account: &ethclient.Account{Address: sender, Nonce: 1, Balance: big.NewInt(10), CodeHash: types.EmptyCodeHash},
+				},
+				contract: {
+					Account: &ethclient.Account{Address: contract, Balance: big.NewInt(0), CodeHash: types.EmptyCodeHash},
+					Storage: map[common.Hash][]byte{slot: {0x2a}},
+				},
+			},
+		}
+		preparer := NewPreparer(provider, store, nil, &config.AccountsConfig{}, params.TestChainConfig, 1, nil, testLogger)
+
+		world, err := preparer.LoadState(t.Context(), header, []*TransactionWithContext{tx})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if world.GetNonce(sender) != 1 {
+			t.Errorf("expected nonce 1, got %d", world.GetNonce(sender))
+		}
+		if got := world.GetState(contract, slot); got != common.BytesToHash([]byte{0x2a}) {
+			t.Errorf("expected slot value 0x2a, got %s", got.Hex())
+		}
+		if world.Exist(coinbase) {
+			t.Errorf("expected coinbase not to exist")
+		}
+	})
+
+	t.Run("should return error if accounts cannot be fetched", func(t *testing.T) {
+		provider := &preparerTestProvider{err: fmt.Errorf("unavailable")}
+		preparer := NewPreparer(provider, store, nil, &config.AccountsConfig{}, params.TestChainConfig, 1, nil, testLogger)
+
+		if _, err := preparer.LoadState(t.Context(), header, []*TransactionWithContext{tx}); err == nil {
+			t.Errorf("expected error, got nil")
+		}
+	})
+}
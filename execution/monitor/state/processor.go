@@ -3,17 +3,22 @@ package state
 import (
 	"context"
 	"fmt"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/tracing"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/trie"
 	"github.com/ethereum/go-ethereum/triedb"
+	"sparseth/bus"
 	"sparseth/config"
 	"sparseth/ethstore"
 	"sparseth/execution/ethclient"
+	"sparseth/execution/monitor"
 	"sparseth/log"
 	"sparseth/storage"
+	"sync"
 )
 
 // TxProcessor downloads and re-executes
@@ -31,17 +36,87 @@ type TxProcessor struct {
 	world    *RevertingStateDB
 	accounts *config.AccountsConfig
 	log      log.Logger
+
+	// snapshots holds the verified state of all
+	// monitored accounts at recent blocks, or is
+	// nil if snapshots are disabled.
+	snapshots *ethstore.SnapshotStore
+	// roots holds the world state root
+	// after each processed block.
+	roots *ethstore.RootStore
+	// outcomes holds the verification outcome
+	// of each re-executed transaction.
+	outcomes *ethstore.TxStore
+	// diffs holds the changes to the monitored
+	// accounts within each processed block.
+	diffs *ethstore.DiffStore
+	// headers holds the synced block headers.
+	headers *ethstore.HeaderStore
+	trieDB  *triedb.Database
+	// breaker switches accounts exceeding their
+	// RPC budget to proof-only mode.
+	breaker *circuitBreaker
+	// breakers holds the tripped circuit
+	// breakers across restarts.
+	breakers *ethstore.BreakerStore
+	// alerts receives tripped circuit
+	// breakers and state mismatches.
+	alerts *bus.Topic[*bus.Alert]
+	// verifications receives the outcome of
+	// verifying each account at each block.
+	verifications *bus.Topic[*bus.Verification]
+	// invariants evaluates the configured
+	// invariants after each block, or is nil
+	// if no invariants are configured.
+	invariants *monitor.InvariantChecker
+	// rules evaluates the alert rules of the
+	// monitored accounts after each block.
+	rules *monitor.RuleChecker
+	// prefetched holds the transactions of
+	// blocks downloaded ahead of processing.
+	prefetched *prefetchCache
+	// blobs provides the blobs of relevant blob
+	// transactions, or is nil if blobs are not
+	// verified.
+	blobs *ethclient.BlobProvider
+
+	// pending holds an updated set of monitored
+	// accounts, applied at the next block boundary.
+	pending *config.AccountsConfig
+	// latest is the world state root after
+	// the last processed block.
+	latest *ethstore.StateRoot
+	mu     sync.Mutex
 }
 
-// NewTxProcessor creates a new TxProcessor.
-func NewTxProcessor(accs *config.AccountsConfig, cc *params.ChainConfig, db storage.KeyValStore, rpc *ethclient.Client, log log.Logger) (*TxProcessor, error) {
+// NewTxProcessor creates a new TxProcessor. The
+// verified state of all monitored accounts is kept
+// for the specified number of most recent blocks,
+// where zero disables snapshots. Accounts that
+// exceed the specified number of RPC calls per
+// block are switched to proof-only mode, where
+// zero disables the budget. Uninitialized reads of
+// addresses in the specified allowlist are not
+// verified. The state of a block is fetched with
+// at most the specified number of concurrent
+// RPC calls. Both tripped circuit breakers and failed
+// verifications are published to the specified alerts
+// topic, as are violated alert rules of the monitored
+// accounts. The outcome of each verification is also
+// published to the specified verifications topic. If
+// a blob provider is specified, the blobs
+// of relevant blob transactions are verified.
+func NewTxProcessor(accs *config.AccountsConfig, cc *params.ChainConfig, db storage.KeyValStore, snapshotBlocks uint64, callBudget uint64, allowlist *ReadAllowlist, parallelism int, rpc *ethclient.Client, blobs *ethclient.BlobProvider, alerts *bus.Topic[*bus.Alert], verifications *bus.Topic[*bus.Verification], log log.Logger) (*TxProcessor, error) {
 	provider := ethclient.NewRpcProvider(rpc)
 
 	store := ethstore.NewHeaderStore(db)
-	preparer := NewPreparer(provider, store, accs, cc, log)
+	// Senders recovered while filtering are
+	// reused during execution
+	senders := NewSenderCache(senderCacheSize)
+	preparer := NewPreparer(provider, store, ethstore.NewCodeStore(db), accs, cc, parallelism, senders, log)
 
-	executor := NewTxExecutor(cc)
-	verifier := NewVerifier(store, provider, log)
+	executor := NewTxExecutor(cc, store, senders)
+	verifier := NewVerifier(store, provider, allowlist, log)
 
 	rawDB := rawdb.NewDatabase(db)
 	trieDB := triedb.NewDatabase(rawDB, nil)
@@ -54,93 +129,483 @@ func NewTxProcessor(accs *config.AccountsConfig, cc *params.ChainConfig, db stor
 		return nil, fmt.Errorf("failed to initialize state: %w", err)
 	}
 
+	// Tripped breakers stay tripped
+	breakers := ethstore.NewBreakerStore(db)
+	tripped, err := breakers.All()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load circuit breakers: %w", err)
+	}
+	breaker := newCircuitBreaker(callBudget)
+	breaker.restore(tripped)
+
+	var snapshots *ethstore.SnapshotStore
+	if snapshotBlocks > 0 {
+		snapshots = ethstore.NewSnapshotStore(db, snapshotBlocks)
+	}
+
 	return &TxProcessor{
-		provider: provider,
-		executor: executor,
-		preparer: preparer,
-		verifier: verifier,
-		world:    world,
-		accounts: accs,
-		log:      log.With("component", "transaction-processor"),
+		provider:      provider,
+		executor:      executor,
+		preparer:      preparer,
+		verifier:      verifier,
+		world:         world,
+		accounts:      accs,
+		log:           log.With("component", "transaction-processor"),
+		snapshots:     snapshots,
+		roots:         ethstore.NewRootStore(db),
+		outcomes:      ethstore.NewTxStore(db),
+		diffs:         ethstore.NewDiffStore(db),
+		headers:       store,
+		trieDB:        trieDB,
+		breaker:       breaker,
+		breakers:      breakers,
+		alerts:        alerts,
+		verifications: verifications,
+		rules:         monitor.NewRuleChecker(alerts, log),
+		prefetched:    newPrefetchCache(),
+		blobs:         blobs,
 	}, nil
 }
 
-// ProcessBlock processes the specified block header.
-func (p *TxProcessor) ProcessBlock(ctx context.Context, head *types.Header) error {
+// SetAccounts replaces the set of monitored accounts.
+// The new set takes effect at the next block boundary,
+// i.e., a block that is currently being processed is
+// finished with the previous set.
+//
+// Note that the world state of newly added accounts is
+// empty, so their completeness checks only succeed once
+// their state has been reconstructed.
+func (p *TxProcessor) SetAccounts(accs *config.AccountsConfig) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.pending = accs
+}
+
+// applyPendingAccounts applies an updated set of
+// monitored accounts, if any, at the specified
+// block number.
+func (p *TxProcessor) applyPendingAccounts(num uint64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.pending == nil {
+		return
+	}
+
+	p.log.Info("update monitored accounts", "accounts", len(p.pending.Accounts))
+	p.accounts = p.pending
+	p.preparer.setAccounts(p.activeAccounts(num))
+	p.pending = nil
+}
+
+// startAccounts starts re-executing the transactions
+// of all accounts whose start block is reached at the
+// specified block number.
+func (p *TxProcessor) startAccounts(num uint64) {
+	active := p.activeAccounts(num)
+	if len(active.Accounts) == len(p.preparer.accounts().Accounts) {
+		return
+	}
+
+	p.log.Info("start block of accounts reached", "num", num, "accounts", len(active.Accounts))
+	p.preparer.setAccounts(active)
+}
+
+// ProofOnlyAccounts returns all monitored accounts
+// in proof-only mode, as their circuit breaker
+// tripped.
+func (p *TxProcessor) ProofOnlyAccounts() []common.Address {
+	return p.breaker.trippedAccounts()
+}
+
+// activeAccounts returns all monitored accounts whose
+// transactions are re-executed at the specified block
+// number, i.e., all started accounts not in proof-only
+// mode.
+func (p *TxProcessor) activeAccounts(num uint64) *config.AccountsConfig {
+	active := make([]*config.AccountConfig, 0, len(p.accounts.Accounts))
+	for _, acc := range p.accounts.Accounts {
+		if acc.StartedAt(num) && !p.breaker.isTripped(acc.Addr) {
+			active = append(active, acc)
+		}
+	}
+	return &config.AccountsConfig{Accounts: active}
+}
+
+// proofOnlyAccounts returns all started monitored
+// accounts in proof-only mode at the specified
+// block number.
+func (p *TxProcessor) proofOnlyAccounts(num uint64) []*config.AccountConfig {
+	var proofOnly []*config.AccountConfig
+	for _, acc := range p.accounts.Accounts {
+		if acc.StartedAt(num) && p.breaker.isTripped(acc.Addr) {
+			proofOnly = append(proofOnly, acc)
+		}
+	}
+	return proofOnly
+}
+
+// enforceBudget trips the circuit breaker of all
+// accounts exceeding their RPC budget to re-execute
+// the specified transactions, and returns the
+// transactions relevant to the remaining accounts.
+func (p *TxProcessor) enforceBudget(head *types.Header, txs []*TransactionWithContext) []*TransactionWithContext {
+	exceeded := p.breaker.check(head.Number.Uint64(), txs, p.activeAccounts(head.Number.Uint64()))
+	if len(exceeded) == 0 {
+		return txs
+	}
+	p.tripped(head, exceeded)
+
+	// Transactions relevant to fewer accounts are
+	// a subset of the already relevant ones
+	return p.preparer.filterRelevant(head, txs)
+}
+
+// tripped reports and persists the specified newly
+// tripped accounts, with their estimated calls, and
+// stops re-executing their transactions.
+func (p *TxProcessor) tripped(head *types.Header, exceeded map[common.Address]uint64) {
+	for addr, calls := range exceeded {
+		p.log.Error("RPC budget exceeded, circuit breaker tripped, switch account to proof-only mode", "account", addr.Hex(), "calls", calls, "num", head.Number, "hash", head.Hash().Hex())
+		p.alert(addr, head, fmt.Errorf("RPC budget exceeded with %d calls, switched to proof-only mode", calls))
+		if err := p.breakers.Put(&ethstore.TrippedBreaker{Account: addr, Number: head.Number.Uint64()}); err != nil {
+			p.log.Warn("failed to persist circuit breaker", "account", addr.Hex(), "num", head.Number, "hash", head.Hash().Hex(), "error", err)
+		}
+	}
+	p.preparer.setAccounts(p.activeAccounts(head.Number.Uint64()))
+}
+
+// relevantTxs returns the total number of transactions
+// of the specified block, the transactions relevant to
+// the monitored accounts, and the prepared data of the
+// block, if any. Prefetched transactions are used, if
+// available.
+func (p *TxProcessor) relevantTxs(ctx context.Context, head *types.Header) (int, []*TransactionWithContext, *preparedBlock, error) {
+	block, ok := p.prefetched.take(head)
+	if ok && block.untouched != nil && block.untouched != p.preparer.accounts() {
+		// The monitored accounts changed since the
+		// block was found to leave them unchanged
+		p.logWithContext("monitored accounts changed, discard prefetched block", head)
+		ok = false
+	}
+	if ok {
+		// Attribute the prefetched data
+		// to the block being processed
+		ethclient.RecordServedBy(ctx, block.served.URLs()...)
+
+		p.logWithContext("filter prefetched txs for block", head)
+		return len(block.txs), p.preparer.filterRelevant(head, block.txs), block, nil
+	}
+
 	p.logWithContext("download txs for block", head)
 	txs, err := p.provider.GetTxsAtBlock(ctx, head)
 	if err != nil {
-		return fmt.Errorf("failed to get txs at block %d: %w", head.Number.Uint64(), err)
+		return 0, nil, nil, fmt.Errorf("failed to get txs at block %d: %w", head.Number.Uint64(), err)
+	}
+
+	if p.preparer.untouched(ctx, head, txs, p.preparer.accounts()) {
+		p.logWithContext("block leaves monitored accounts unchanged, skip traces", head)
+		return len(txs), nil, nil, nil
+	}
+
+	// The budget is checked before tracing, as
+	// traces are the most expensive calls
+	senders, err := p.preparer.sendersOf(head, txs)
+	if err != nil {
+		return 0, nil, nil, fmt.Errorf("failed to recover senders at block %d: %w", head.Number.Uint64(), err)
+	}
+	if exceeded := p.breaker.checkDirect(head.Number.Uint64(), txs, senders, p.activeAccounts(head.Number.Uint64())); len(exceeded) > 0 {
+		p.tripped(head, exceeded)
+		if len(p.preparer.accounts().Accounts) == 0 {
+			p.logWithContext("all monitored accounts in proof-only mode, skip traces", head)
+			return len(txs), nil, nil, nil
+		}
 	}
 
 	p.logWithContext("filter txs for block", head)
 	relevantTxs, err := p.preparer.FilterTxs(ctx, head, txs)
 	if err != nil {
-		return fmt.Errorf("failed to filter txs for block %d: %w", head.Number.Uint64(), err)
+		return 0, nil, nil, fmt.Errorf("failed to filter txs for block %d: %w", head.Number.Uint64(), err)
 	}
-	p.logWithContext(fmt.Sprintf("got: %d txs, filtered: %d txs, remaining: %d txs", len(txs), len(txs)-len(relevantTxs), len(relevantTxs)), head)
+	return len(txs), relevantTxs, nil, nil
+}
 
-	if len(relevantTxs) == 0 {
-		p.logWithContext("no txs to process, skip re-execution", head)
-		return nil
+// ProcessBlock processes the specified block header.
+//
+// Blocks prepared ahead via Prepare are executed
+// without waiting for downloads. Execution,
+// verification, and merging into the persistent
+// state are strictly sequential, so blocks must
+// be processed in order. A block that replaces
+// an already processed block, e.g., after a reorg,
+// is processed on the rolled back state of its
+// parent, see rollback.
+//
+// The returned digest covers the verified state
+// root and the root of the receipts computed by
+// re-execution, see monitor.Digest.
+func (p *TxProcessor) ProcessBlock(ctx context.Context, head *types.Header) (common.Hash, error) {
+	p.applyPendingAccounts(head.Number.Uint64())
+	p.startAccounts(head.Number.Uint64())
+
+	if err := p.rollback(head); err != nil {
+		return common.Hash{}, err
 	}
 
-	p.logWithContext("prepare state for block", head)
-	transientWorld, err := p.preparer.LoadState(ctx, head, relevantTxs)
+	total, relevantTxs, prepared, err := p.relevantTxs(ctx, head)
 	if err != nil {
-		return fmt.Errorf("failed to load partial transient state for block %d: %w", head.Number.Uint64(), err)
+		return common.Hash{}, err
 	}
+	relevantTxs = p.enforceBudget(head, relevantTxs)
+	p.logWithContext(fmt.Sprintf("got: %d txs, filtered: %d txs, remaining: %d txs", total, total-len(relevantTxs), len(relevantTxs)), head)
 
-	p.logWithContext("process transactions for block", head)
-	_, err = p.executor.ExecuteTxs(head, relevantTxs, transientWorld)
+	withdrawals, err := p.withdrawals(ctx, head, prepared)
 	if err != nil {
-		return fmt.Errorf("failed to execute txs for block %d: %w", head.Number.Uint64(), err)
+		return common.Hash{}, err
 	}
+	active := p.activeAccounts(head.Number.Uint64())
+	withdrawals = relevantWithdrawals(withdrawals, active)
 
-	transientRoot, err := transientWorld.Commit(head.Number.Uint64(), false, false)
+	// Accounts in proof-only mode are not re-executed,
+	// only their proven on-chain state is fetched
+	proven, err := p.proveAccounts(ctx, head)
 	if err != nil {
-		return fmt.Errorf("failed to commit state for block %d: %w", head.Number.Uint64(), err)
+		return common.Hash{}, err
 	}
 
-	newTransientWorld, err := New(transientRoot, transientWorld)
-	if err != nil {
-		return fmt.Errorf("failed to create new transient state for block %d: %w", head.Number.Uint64(), err)
+	if len(relevantTxs) == 0 && len(withdrawals) == 0 {
+		p.logWithContext("no txs to process, skip re-execution", head)
+		root := p.currentRoot()
+		if err = p.recordRoot(head, root, types.EmptyReceiptsHash); err != nil {
+			return common.Hash{}, err
+		}
+		if err = p.storeSnapshots(head, active, proven); err != nil {
+			p.log.Warn("failed to store account snapshots", "num", head.Number, "hash", head.Hash().Hex(), "error", err)
+		}
+		p.checkInvariants(head)
+		p.rules.Check(head, p.world, nil, active.Accounts)
+		return monitor.Digest(head, root, types.EmptyReceiptsHash), nil
 	}
 
-	p.logWithContext("verify uninitialized reads for block", head)
-	if err = p.verifier.VerifyUninitializedReads(ctx, head, newTransientWorld); err != nil {
-		p.log.Warn("invalid uninitialized reads detected", "num", head.Number, "hash", head.Hash().Hex(), "error", err)
-		return fmt.Errorf("invalid uninitialized reads for block %d: %w", head.Number.Uint64(), err)
+	var receipts []*types.Receipt
+	if len(relevantTxs) > 0 {
+		receipts, err = p.reexecute(ctx, head, prepared, relevantTxs, active)
+		if err != nil {
+			return common.Hash{}, err
+		}
 	}
 
-	p.logWithContext("merge transient state into persistent state", head)
-	p.merge(newTransientWorld)
+	// Withdrawals are credited after all
+	// transactions of the block
+	p.logWithContext(fmt.Sprintf("apply %d withdrawals for block", len(withdrawals)), head)
+	p.applyWithdrawals(withdrawals)
 
 	p.world.IntermediateRoot(false)
 
 	p.logWithContext("verify state for block", head)
-	for _, acc := range p.accounts.Accounts {
+	for _, acc := range active.Accounts {
 		if err = p.verifier.VerifyCompleteness(ctx, acc, head, p.world); err != nil {
 			p.log.Warn("failed to verify state for account, reverting state changes", "account", acc.Addr.Hex(), "num", head.Number, "hash", head.Hash().Hex(), "error", err)
+			p.reportFailure(bus.StateMismatch, acc.Addr, head, fmt.Errorf("state verification failed: %w", err))
 			p.world.Revert()
-			return fmt.Errorf("failed to verify state for account %s at block %d: %w", acc.Addr.Hex(), head.Number.Uint64(), err)
+			p.recordOutcomes(head, relevantTxs, receipts, active, false)
+			return common.Hash{}, fmt.Errorf("failed to verify state for account %s at block %d: %w: %w", acc.Addr.Hex(), head.Number.Uint64(), ethclient.ErrDivergence, err)
 		}
 	}
 
+	// Changes are journaled until the commit
+	diff := p.world.Diff()
+
 	p.logWithContext("verification succeeded, commit persistent state for block", head)
 	root, err := p.world.Commit(head.Number.Uint64(), false, false)
 	if err != nil {
 		p.log.Warn("failed to commit persistent state for block", "num", head.Number, "hash", head.Hash().Hex(), "error", err)
-		return fmt.Errorf("failed to commit persistent state for block %d: %w", head.Number.Uint64(), err)
+		return common.Hash{}, fmt.Errorf("failed to commit persistent state for block %d: %w", head.Number.Uint64(), err)
 	}
 
 	p.world, err = p.world.WithRoot(root)
 	if err != nil {
 		p.log.Warn("failed to create new persistent state for block", "num", head.Number, "hash", head.Hash().Hex(), "error", err)
-		return fmt.Errorf("failed to create new persistent state for block %d: %w", head.Number.Uint64(), err)
+		return common.Hash{}, fmt.Errorf("failed to create new persistent state for block %d: %w", head.Number.Uint64(), err)
+	}
+
+	receiptsHash := types.DeriveSha(types.Receipts(receipts), trie.NewStackTrie(nil))
+	if err = p.recordRoot(head, root, receiptsHash); err != nil {
+		return common.Hash{}, err
+	}
+	for _, acc := range active.Accounts {
+		ok := monitor.NewVerification(bus.VerificationOK, "transaction-processor", acc.Addr, head, nil)
+		ok.Root = p.world.GetStorageRoot(acc.Addr)
+		p.verifications.Publish(ok)
+	}
+
+	if err = p.storeDiff(head, diff); err != nil {
+		// Diffs are informational only, the
+		// verified state is already committed
+		p.log.Warn("failed to store state diff", "num", head.Number, "hash", head.Hash().Hex(), "error", err)
+	}
+	if err = p.storeSnapshots(head, active, proven); err != nil {
+		// Snapshots are an optimization only,
+		// the verified state is already committed
+		p.log.Warn("failed to store account snapshots", "num", head.Number, "hash", head.Hash().Hex(), "error", err)
+	}
+	p.recordOutcomes(head, relevantTxs, receipts, active, true)
+	p.checkInvariants(head)
+	p.rules.Check(head, p.world, diff, active.Accounts)
+	recordActivity(ctx, relevantTxs, receipts, active)
+
+	return monitor.Digest(head, root, receiptsHash), nil
+}
+
+// proveAccounts fetches the proven on-chain state
+// of all accounts in proof-only mode at the
+// specified block. Accounts that do not exist
+// are omitted.
+func (p *TxProcessor) proveAccounts(ctx context.Context, head *types.Header) ([]*ethclient.Account, error) {
+	proven := make([]*ethclient.Account, 0)
+	for _, acc := range p.proofOnlyAccounts(head.Number.Uint64()) {
+		onchain, err := p.provider.GetAccountAtBlock(ctx, acc.Addr, head)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch proof for account %s at block %d: %w", acc.Addr.Hex(), head.Number.Uint64(), err)
+		}
+		if onchain != nil {
+			proven = append(proven, onchain)
+		}
+	}
+	return proven, nil
+}
+
+// reexecute re-executes the specified transactions
+// of the specified block on their partial transient
+// state, and merges the changes to the specified
+// accounts into the persistent world state. The
+// computed receipts are returned.
+func (p *TxProcessor) reexecute(ctx context.Context, head *types.Header, prepared *preparedBlock, txs []*TransactionWithContext, active *config.AccountsConfig) ([]*types.Receipt, error) {
+	transientWorld, err := p.transientState(ctx, head, prepared, txs)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = p.verifyBlobs(ctx, head, txs); err != nil {
+		return nil, err
+	}
+
+	p.logWithContext("process transactions for block", head)
+	result, err := p.executor.ExecuteTxs(head, txs, transientWorld)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute txs for block %d: %w", head.Number.Uint64(), err)
+	}
+
+	transientRoot, err := transientWorld.Commit(head.Number.Uint64(), false, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to commit state for block %d: %w", head.Number.Uint64(), err)
+	}
+
+	newTransientWorld, err := New(transientRoot, transientWorld)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create new transient state for block %d: %w", head.Number.Uint64(), err)
+	}
+
+	p.logWithContext("verify uninitialized reads for block", head)
+	if err = p.verifier.VerifyUninitializedReads(ctx, head, newTransientWorld); err != nil {
+		p.log.Warn("invalid uninitialized reads detected", "num", head.Number, "hash", head.Hash().Hex(), "error", err)
+		p.reportFailure(bus.UninitializedReadViolation, common.Address{}, head, fmt.Errorf("uninitialized read verification failed: %w", err))
+		return nil, fmt.Errorf("invalid uninitialized reads for block %d: %w: %w", head.Number.Uint64(), ethclient.ErrDivergence, err)
+	}
+
+	p.logWithContext("merge transient state into persistent state", head)
+	p.merge(newTransientWorld, active)
+
+	return result.Receipts, nil
+}
+
+// alert publishes an alert concerning the
+// specified account at the specified block.
+func (p *TxProcessor) alert(addr common.Address, head *types.Header, err error) {
+	p.alerts.Publish(monitor.NewAlert("transaction-processor", addr, head, err))
+}
+
+// reportFailure publishes the failed verification
+// of the specified kind of the specified account
+// at the specified block, along with an alert.
+func (p *TxProcessor) reportFailure(kind bus.VerificationKind, addr common.Address, head *types.Header, err error) {
+	p.verifications.Publish(monitor.NewVerification(kind, "transaction-processor", addr, head, err))
+	p.alert(addr, head, err)
+}
+
+// SetInvariants sets the checker that evaluates
+// the configured invariants against the verified
+// world state after each processed block.
+func (p *TxProcessor) SetInvariants(checker *monitor.InvariantChecker) {
+	p.invariants = checker
+}
+
+// checkInvariants evaluates the configured
+// invariants after the specified block.
+func (p *TxProcessor) checkInvariants(head *types.Header) {
+	if p.invariants == nil {
+		return
 	}
+	p.invariants.Check(head, p.world)
+}
 
-	return nil
+// Diff returns the verified changes to the monitored
+// accounts within the processed block with the specified
+// number. Blocks without changes have no diff.
+func (p *TxProcessor) Diff(num uint64) (*ethstore.StateDiff, error) {
+	return p.diffs.Get(num)
+}
+
+// storeDiff stores the specified changes to the
+// monitored accounts within the specified block.
+func (p *TxProcessor) storeDiff(head *types.Header, accounts []*ethstore.AccountDiff) error {
+	if len(accounts) == 0 {
+		return nil
+	}
+
+	p.log.Debug("state changed", "num", head.Number, "hash", head.Hash().Hex(), "accounts", len(accounts))
+	return p.diffs.Put(&ethstore.StateDiff{
+		Number:    head.Number.Uint64(),
+		BlockHash: head.Hash(),
+		Accounts:  accounts,
+	})
+}
+
+// storeSnapshots stores the verified state of the
+// specified re-executed accounts and the specified
+// proven accounts at the specified block. Snapshots
+// are stored for every processed block, so that the
+// state is rolled back to any recent block.
+func (p *TxProcessor) storeSnapshots(head *types.Header, active *config.AccountsConfig, proven []*ethclient.Account) error {
+	if p.snapshots == nil {
+		return nil
+	}
+
+	snapshots := make([]*ethstore.AccountSnapshot, 0, len(active.Accounts)+len(proven))
+	for _, acc := range proven {
+		snapshots = append(snapshots, &ethstore.AccountSnapshot{
+			Address:     acc.Address,
+			Nonce:       acc.Nonce,
+			Balance:     acc.Balance,
+			CodeHash:    acc.CodeHash,
+			StorageRoot: acc.StorageRoot,
+		})
+	}
+	for _, acc := range active.Accounts {
+		if !p.world.Exist(acc.Addr) {
+			continue
+		}
+		snapshots = append(snapshots, &ethstore.AccountSnapshot{
+			Address:     acc.Addr,
+			Nonce:       p.world.GetNonce(acc.Addr),
+			Balance:     p.world.GetBalance(acc.Addr).ToBig(),
+			CodeHash:    p.world.GetCodeHash(acc.Addr),
+			StorageRoot: p.world.GetStorageRoot(acc.Addr),
+		})
+	}
+
+	return p.snapshots.PutAll(head, snapshots)
 }
 
 // logWithContext logs a message with
@@ -151,12 +616,12 @@ func (p *TxProcessor) logWithContext(msg string, header *types.Header) {
 
 // merge merges the relevant changes from the transient
 // world state ('from') into the persistent world state.
-// A change is considered relevant if it affects a
-// monitored account or its storage slots.
-func (p *TxProcessor) merge(from *TracingStateDB) {
+// A change is considered relevant if it affects one of
+// the specified accounts or its storage slots.
+func (p *TxProcessor) merge(from *TracingStateDB, accs *config.AccountsConfig) {
 	// Merge accounts
 	for _, acc := range from.WrittenAccounts() {
-		if p.accounts.Contains(acc) {
+		if accs.Contains(acc) {
 			p.world.SetNonce(acc, from.GetNonce(acc), tracing.NonceChangeUnspecified)
 			p.world.SetBalance(acc, from.GetBalance(acc), tracing.BalanceChangeUnspecified)
 			p.world.SetCode(acc, from.GetCode(acc))
@@ -164,7 +629,7 @@ func (p *TxProcessor) merge(from *TracingStateDB) {
 	}
 
 	// Merge storage slots
-	for _, acc := range p.accounts.Accounts {
+	for _, acc := range accs.Accounts {
 		for _, slot := range from.WrittenStorageSlots(acc.Addr) {
 			val := from.GetState(acc.Addr, slot)
 			p.world.SetState(acc.Addr, slot, val)
@@ -0,0 +1,147 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/trie"
+	"math/big"
+	"slices"
+	"sparseth/config"
+)
+
+// ErrNotMonitored is returned if a proof is
+// requested for an account whose state is not
+// verified at the requested block.
+var ErrNotMonitored = errors.New("account not monitored")
+
+// AccountProof is a Merkle proof of an account,
+// and of some of its storage slots, against the
+// root of the world state after a block.
+type AccountProof struct {
+	// Number and BlockHash identify the block,
+	// and Root the world state after it.
+	Number    uint64
+	BlockHash common.Hash
+	Root      common.Hash
+
+	Address     common.Address
+	Balance     *big.Int
+	Nonce       uint64
+	CodeHash    common.Hash
+	StorageRoot common.Hash
+	// Proof holds the RLP-encoded trie nodes
+	// from the root to the account.
+	Proof   [][]byte
+	Storage []*StorageProof
+}
+
+// StorageProof is a Merkle proof of a
+// storage slot against the storage root
+// of its account.
+type StorageProof struct {
+	Key   common.Hash
+	Value common.Hash
+	// Proof holds the RLP-encoded trie nodes
+	// from the storage root to the slot.
+	Proof [][]byte
+}
+
+// proofList collects the
+// nodes of a Merkle proof.
+type proofList [][]byte
+
+func (l *proofList) Put(_ []byte, value []byte) error {
+	*l = append(*l, value)
+	return nil
+}
+
+func (l *proofList) Delete([]byte) error {
+	panic("not supported")
+}
+
+// Proof generates a Merkle proof of the specified
+// account and storage slots against the world state
+// after the block with the specified number.
+//
+// Note that the world state only includes monitored
+// accounts, so that the root differs from the state
+// root of the block header. The proof is verified
+// against a root obtained otherwise, e.g., an
+// attested root.
+func (p *TxProcessor) Proof(num uint64, addr common.Address, slots []common.Hash) (*AccountProof, error) {
+	p.mu.Lock()
+	active := p.activeAccounts(num)
+	p.mu.Unlock()
+
+	if !slices.ContainsFunc(active.Accounts, func(acc *config.AccountConfig) bool { return acc.Addr == addr }) {
+		return nil, fmt.Errorf("%w: %s", ErrNotMonitored, addr.Hex())
+	}
+
+	root, err := p.RootAt(num)
+	if err != nil {
+		return nil, err
+	}
+
+	accTrie, err := trie.NewStateTrie(trie.StateTrieID(root.Root), p.trieDB)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open account trie: %w", err)
+	}
+
+	proof := &AccountProof{
+		Number:      root.Number,
+		BlockHash:   root.BlockHash,
+		Root:        root.Root,
+		Address:     addr,
+		Balance:     new(big.Int),
+		CodeHash:    types.EmptyCodeHash,
+		StorageRoot: types.EmptyRootHash,
+		Storage:     make([]*StorageProof, 0, len(slots)),
+	}
+
+	var accProof proofList
+	if err = accTrie.Prove(crypto.Keccak256(addr.Bytes()), &accProof); err != nil {
+		return nil, fmt.Errorf("failed to prove account %s: %w", addr.Hex(), err)
+	}
+	proof.Proof = accProof
+
+	acc, err := accTrie.GetAccount(addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account %s: %w", addr.Hex(), err)
+	}
+	if acc != nil {
+		proof.Balance = acc.Balance.ToBig()
+		proof.Nonce = acc.Nonce
+		proof.CodeHash = common.BytesToHash(acc.CodeHash)
+		proof.StorageRoot = acc.Root
+	}
+
+	var storageTrie *trie.StateTrie
+	if proof.StorageRoot != types.EmptyRootHash {
+		id := trie.StorageTrieID(root.Root, crypto.Keccak256Hash(addr.Bytes()), proof.StorageRoot)
+		if storageTrie, err = trie.NewStateTrie(id, p.trieDB); err != nil {
+			return nil, fmt.Errorf("failed to open storage trie of %s: %w", addr.Hex(), err)
+		}
+	}
+
+	for _, slot := range slots {
+		storage := &StorageProof{Key: slot, Proof: make([][]byte, 0)}
+		if storageTrie != nil {
+			var slotProof proofList
+			if err = storageTrie.Prove(crypto.Keccak256(slot.Bytes()), &slotProof); err != nil {
+				return nil, fmt.Errorf("failed to prove slot %s of %s: %w", slot.Hex(), addr.Hex(), err)
+			}
+			storage.Proof = slotProof
+
+			value, err := storageTrie.GetStorage(addr, slot.Bytes())
+			if err != nil {
+				return nil, fmt.Errorf("failed to get slot %s of %s: %w", slot.Hex(), addr.Hex(), err)
+			}
+			storage.Value = common.BytesToHash(value)
+		}
+		proof.Storage = append(proof.Storage, storage)
+	}
+	return proof, nil
+}
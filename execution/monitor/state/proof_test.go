@@ -0,0 +1,106 @@
+package state
+
+import (
+	"errors"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+	"math/big"
+	"sparseth/config"
+	"sparseth/execution/ethclient"
+	"sparseth/storage/mem"
+	"testing"
+)
+
+func TestTxProcessor_Proof(t *testing.T) {
+	contract := common.HexToAddress("0xbb")
+	countSlot := common.HexToHash("0x01")
+	code := []byte{0x60, 0x00}
+
+	accs := &config.AccountsConfig{Accounts: []*config.AccountConfig{
+		{Addr: contract, ContractConfig: &config.ContractConfig{State: &config.SparseConfig{CountSlot: countSlot}}},
+	}}
+	provider := &bootstrapTestProvider{
+		states: map[common.Address]*ethclient.AccountState{
+			contract: {
+				Account: &ethclient.Account{
+					Address:     contract,
+					Nonce:       1,
+					Balance:     big.NewInt(7),
+					CodeHash:    crypto.Keccak256Hash(code),
+					StorageRoot: types.EmptyRootHash,
+				},
+				Storage: map[common.Hash][]byte{countSlot: {0x05}},
+			},
+		},
+		code: code,
+	}
+	head := &types.Header{Number: big.NewInt(10)}
+
+	newProofTestProcessor := func(t *testing.T) *TxProcessor {
+		p := newExportTestProcessor(t, mem.New(), provider, accs)
+		if _, err := p.Bootstrap(t.Context(), head); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		return p
+	}
+
+	verify := func(t *testing.T, root common.Hash, key []byte, proof [][]byte) []byte {
+		db := memorydb.New()
+		for _, node := range proof {
+			if err := db.Put(crypto.Keccak256(node), node); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+		}
+		value, err := trie.VerifyProof(root, crypto.Keccak256(key), db)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		return value
+	}
+
+	t.Run("should prove account and slot against world state root", func(t *testing.T) {
+		p := newProofTestProcessor(t)
+
+		proof, err := p.Proof(10, contract, []common.Hash{countSlot})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if proof.Root != p.LatestRoot().Root {
+			t.Errorf("expected root %s, got %s", p.LatestRoot().Root.Hex(), proof.Root.Hex())
+		}
+		if proof.Nonce != 1 || proof.Balance.Uint64() != 7 {
+			t.Errorf("expected nonce 1 and balance 7, got %d and %s", proof.Nonce, proof.Balance)
+		}
+
+		var acc types.StateAccount
+		if err = rlp.DecodeBytes(verify(t, proof.Root, contract.Bytes(), proof.Proof), &acc); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if acc.Root != proof.StorageRoot {
+			t.Errorf("expected storage root %s, got %s", proof.StorageRoot.Hex(), acc.Root.Hex())
+		}
+
+		if len(proof.Storage) != 1 || proof.Storage[0].Value != common.BytesToHash([]byte{0x05}) {
+			t.Fatalf("expected count slot 0x05, got %+v", proof.Storage)
+		}
+		var value []byte
+		if err = rlp.DecodeBytes(verify(t, proof.StorageRoot, countSlot.Bytes(), proof.Storage[0].Proof), &value); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if common.BytesToHash(value) != proof.Storage[0].Value {
+			t.Errorf("expected proven value %s, got %x", proof.Storage[0].Value.Hex(), value)
+		}
+	})
+
+	t.Run("should fail for unmonitored account", func(t *testing.T) {
+		p := newProofTestProcessor(t)
+
+		if _, err := p.Proof(10, common.HexToAddress("0xcc"), nil); !errors.Is(err, ErrNotMonitored) {
+			t.Errorf("expected %v, got %v", ErrNotMonitored, err)
+		}
+	})
+}
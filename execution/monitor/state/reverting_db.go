@@ -9,6 +9,7 @@ import (
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/trie/utils"
 	"github.com/holiman/uint256"
+	"sparseth/ethstore"
 )
 
 // RevertingStateDB wraps a state.StateDB with
@@ -58,6 +59,13 @@ func (db *RevertingStateDB) Revert() {
 	db.journal.Revert(db.inner)
 }
 
+// Diff returns the changes made to the
+// state since the last commit per account,
+// see journal.Diff.
+func (db *RevertingStateDB) Diff() []*ethstore.AccountDiff {
+	return db.journal.Diff(db.inner)
+}
+
 //
 // state.StateDB functions
 //
@@ -4,8 +4,10 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/tracing"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/triedb"
+	"github.com/holiman/uint256"
 	"math/big"
 	"sparseth/storage/mem"
 	"testing"
@@ -57,3 +59,59 @@ func TestRevertingStateDB_Revert(t *testing.T) {
 		}
 	})
 }
+
+func TestRevertingStateDB_Diff(t *testing.T) {
+	newWorld := func(t *testing.T) *RevertingStateDB {
+		stateDB := state.NewDatabase(triedb.NewDatabase(rawdb.NewDatabase(mem.New()), nil), nil)
+		world, err := NewRevertingStateDB(types.EmptyRootHash, stateDB)
+		if err != nil {
+			t.Fatalf("error creating revering state database: %v", err)
+		}
+		return world
+	}
+	addr := common.HexToAddress("0xdeadbeefdeadbeefdeadbeefdeadbeefdeadbeef")
+	slot := common.BigToHash(big.NewInt(1))
+
+	t.Run("should return changes since last commit", func(t *testing.T) {
+		world := newWorld(t)
+		world.SetBalance(addr, uint256.NewInt(10), tracing.BalanceChangeUnspecified)
+		world.SetState(addr, slot, common.BigToHash(big.NewInt(1)))
+		root, err := world.Commit(1, false, false)
+		if err != nil {
+			t.Fatalf("failed to commit state: %v", err)
+		}
+		if world, err = world.WithRoot(root); err != nil {
+			t.Fatalf("failed to open state: %v", err)
+		}
+
+		world.SetNonce(addr, 1, tracing.NonceChangeUnspecified)
+		world.SetBalance(addr, uint256.NewInt(5), tracing.BalanceChangeUnspecified)
+		world.SetBalance(addr, uint256.NewInt(7), tracing.BalanceChangeUnspecified)
+		world.SetState(addr, slot, common.BigToHash(big.NewInt(2)))
+
+		diffs := world.Diff()
+		if len(diffs) != 1 {
+			t.Fatalf("expected 1 diff, got %d", len(diffs))
+		}
+		diff := diffs[0]
+		if diff.NonceBefore != 0 || diff.NonceAfter != 1 {
+			t.Errorf("expected nonce 0 -> 1, got %d -> %d", diff.NonceBefore, diff.NonceAfter)
+		}
+		if diff.BalanceBefore.Uint64() != 10 || diff.BalanceAfter.Uint64() != 7 {
+			t.Errorf("expected balance 10 -> 7, got %s -> %s", diff.BalanceBefore, diff.BalanceAfter)
+		}
+		if len(diff.Slots) != 1 || diff.Slots[0].Before != common.BigToHash(big.NewInt(1)) || diff.Slots[0].After != common.BigToHash(big.NewInt(2)) {
+			t.Errorf("expected slot 1 -> 2, got %+v", diff.Slots)
+		}
+	})
+
+	t.Run("should omit accounts set to same values", func(t *testing.T) {
+		world := newWorld(t)
+		world.SetState(addr, slot, common.Hash{})
+		world.SetBalance(addr, uint256.NewInt(0), tracing.BalanceChangeUnspecified)
+
+		if diffs := world.Diff(); len(diffs) != 0 {
+			t.Errorf("expected no diffs, got %d", len(diffs))
+		}
+	})
+}
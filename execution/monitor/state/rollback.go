@@ -0,0 +1,89 @@
+package state
+
+import (
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"sparseth/ethstore"
+)
+
+// rollback resets the world state to the verified
+// state after the parent of the specified block, if
+// the block replaces an already processed block,
+// e.g., after a reorg.
+//
+// The restored state of the monitored accounts is
+// checked against their snapshots at the parent,
+// so reorgs deeper than the kept snapshots are not
+// rolled back.
+func (p *TxProcessor) rollback(head *types.Header) error {
+	latest := p.LatestRoot()
+	num := head.Number.Uint64()
+	if latest == nil || num == 0 || num > latest.Number {
+		return nil
+	}
+
+	parent, err := p.roots.Get(num - 1)
+	if err != nil {
+		return fmt.Errorf("failed to get state root of block %d: %w", num-1, err)
+	}
+	if parent.BlockHash != head.ParentHash {
+		return fmt.Errorf("failed to roll back block %d: parent %s not processed", num, head.ParentHash.Hex())
+	}
+	header, err := p.headers.GetByHash(parent.BlockHash)
+	if err != nil {
+		return fmt.Errorf("failed to get header of block %d: %w", num-1, err)
+	}
+
+	world, err := p.world.WithRoot(parent.Root)
+	if err != nil {
+		return fmt.Errorf("failed to restore state of block %d: %w", num-1, err)
+	}
+	if err = p.checkSnapshots(header, world); err != nil {
+		return fmt.Errorf("failed to roll back to block %d: %w", num-1, err)
+	}
+
+	p.log.Warn("block replaces processed block, roll back state", "num", head.Number, "hash", head.Hash().Hex(), "depth", latest.Number-num+1)
+	p.world = world
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.latest = parent
+	return nil
+}
+
+// checkSnapshots checks that the state of all
+// re-executed accounts in the specified world
+// state matches their snapshots at the specified
+// block. Without snapshots, the state is not
+// checked.
+func (p *TxProcessor) checkSnapshots(header *types.Header, world *RevertingStateDB) error {
+	if p.snapshots == nil {
+		return nil
+	}
+
+	for _, acc := range p.activeAccounts(header.Number.Uint64()).Accounts {
+		if !world.Exist(acc.Addr) {
+			continue
+		}
+		snapshot, err := p.snapshots.Get(header, acc.Addr)
+		if err != nil {
+			return fmt.Errorf("failed to get snapshot of account %s: %w", acc.Addr.Hex(), err)
+		}
+		if !sameSnapshot(snapshot, world, acc.Addr) {
+			return fmt.Errorf("state of account %s does not match its snapshot", acc.Addr.Hex())
+		}
+	}
+	return nil
+}
+
+// sameSnapshot checks whether the specified snapshot
+// matches the state of its account in the specified
+// world state.
+func sameSnapshot(snapshot *ethstore.AccountSnapshot, world *RevertingStateDB, addr common.Address) bool {
+	return snapshot.Nonce == world.GetNonce(addr) &&
+		snapshot.Balance.Cmp(world.GetBalance(addr).ToBig()) == 0 &&
+		snapshot.CodeHash == world.GetCodeHash(addr) &&
+		snapshot.StorageRoot == world.GetStorageRoot(addr)
+}
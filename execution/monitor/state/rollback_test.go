@@ -0,0 +1,109 @@
+package state
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/holiman/uint256"
+	"math/big"
+	"sparseth/config"
+	"sparseth/ethstore"
+	"sparseth/storage/mem"
+	"testing"
+)
+
+// processRollbackTestBlock sets the balance of the
+// specified account, and records the resulting
+// world state as processed at the specified block.
+func processRollbackTestBlock(t *testing.T, p *TxProcessor, head *types.Header, addr common.Address, balance uint64) common.Hash {
+	p.world.SetBalance(addr, uint256.NewInt(balance), tracing.BalanceChangeUnspecified)
+	root, err := p.world.Commit(head.Number.Uint64(), false, false)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if p.world, err = p.world.WithRoot(root); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err = p.headers.Put(head); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err = p.recordRoot(head, root, types.EmptyReceiptsHash); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err = p.storeSnapshots(head, p.accounts, nil); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	return root
+}
+
+func TestTxProcessor_Rollback(t *testing.T) {
+	addr := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	accs := &config.AccountsConfig{
+		Accounts: []*config.AccountConfig{{Addr: addr, ContractConfig: &config.ContractConfig{}}},
+	}
+
+	setup := func(t *testing.T) (*TxProcessor, *types.Header, common.Hash) {
+		db := mem.New()
+		t.Cleanup(func() {
+			db.Close()
+		})
+
+		p := newExportTestProcessor(t, db, nil, accs)
+		p.snapshots = ethstore.NewSnapshotStore(db, 8)
+
+		first := &types.Header{Number: big.NewInt(1)}
+		root := processRollbackTestBlock(t, p, first, addr, 1)
+		processRollbackTestBlock(t, p, &types.Header{Number: big.NewInt(2), ParentHash: first.Hash()}, addr, 2)
+		return p, first, root
+	}
+
+	t.Run("should roll back state of replaced block", func(t *testing.T) {
+		p, first, root := setup(t)
+		reorged := &types.Header{Number: big.NewInt(2), ParentHash: first.Hash(), Extra: []byte("reorged")}
+
+		if err := p.rollback(reorged); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if latest := p.LatestRoot(); latest.Number != 1 || latest.Root != root {
+			t.Errorf("expected root %s at block 1, got %s at block %d", root.Hex(), latest.Root.Hex(), latest.Number)
+		}
+		if balance := p.world.GetBalance(addr).Uint64(); balance != 1 {
+			t.Errorf("expected balance 1, got %d", balance)
+		}
+	})
+
+	t.Run("should not roll back when extending processed block", func(t *testing.T) {
+		p, _, _ := setup(t)
+		latest := p.LatestRoot()
+		next := &types.Header{Number: big.NewInt(3), ParentHash: latest.BlockHash}
+
+		if err := p.rollback(next); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if p.LatestRoot() != latest {
+			t.Errorf("expected latest root to be unchanged")
+		}
+	})
+
+	t.Run("should fail if state does not match snapshot", func(t *testing.T) {
+		p, first, _ := setup(t)
+		tampered := &ethstore.AccountSnapshot{Address: addr, Balance: big.NewInt(7)}
+		if err := p.snapshots.PutAll(first, []*ethstore.AccountSnapshot{tampered}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		reorged := &types.Header{Number: big.NewInt(2), ParentHash: first.Hash(), Extra: []byte("reorged")}
+
+		if err := p.rollback(reorged); err == nil {
+			t.Errorf("expected error, got nil")
+		}
+	})
+
+	t.Run("should fail if parent was not processed", func(t *testing.T) {
+		p, _, _ := setup(t)
+		orphan := &types.Header{Number: big.NewInt(2), ParentHash: common.HexToHash("0xff")}
+
+		if err := p.rollback(orphan); err == nil {
+			t.Errorf("expected error, got nil")
+		}
+	})
+}
@@ -0,0 +1,93 @@
+package state
+
+import (
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/lru"
+	"github.com/ethereum/go-ethereum/core/types"
+	"golang.org/x/sync/errgroup"
+	"runtime"
+)
+
+// senderCacheSize is the number of transaction
+// senders kept in the cache, which covers the
+// transactions of several recent blocks.
+const senderCacheSize = 32768
+
+// SenderCache caches the recovered senders of
+// transactions by transaction hash, so that the
+// costly ECDSA recovery is done only once per
+// transaction, e.g., when a block is prefetched
+// and processed, or filtered and executed.
+//
+// A nil cache recovers all senders.
+type SenderCache struct {
+	senders *lru.Cache[common.Hash, common.Address]
+}
+
+// NewSenderCache creates a new SenderCache that
+// keeps the specified number of senders.
+func NewSenderCache(size int) *SenderCache {
+	return &SenderCache{
+		senders: lru.NewCache[common.Hash, common.Address](size),
+	}
+}
+
+// Sender returns the sender of the specified
+// transaction, recovering it if not cached.
+func (c *SenderCache) Sender(signer types.Signer, tx *types.Transaction) (common.Address, error) {
+	if c == nil {
+		return types.Sender(signer, tx)
+	}
+
+	if from, ok := c.senders.Get(tx.Hash()); ok {
+		return from, nil
+	}
+
+	from, err := types.Sender(signer, tx)
+	if err != nil {
+		return common.Address{}, err
+	}
+	c.senders.Add(tx.Hash(), from)
+	return from, nil
+}
+
+// Senders returns the senders of all specified
+// transactions, recovering uncached senders in
+// parallel.
+func (c *SenderCache) Senders(signer types.Signer, txs []*types.Transaction) ([]common.Address, error) {
+	senders := make([]common.Address, len(txs))
+
+	var g errgroup.Group
+	g.SetLimit(runtime.NumCPU())
+	for i, tx := range txs {
+		g.Go(func() error {
+			from, err := c.Sender(signer, tx)
+			if err != nil {
+				return fmt.Errorf("failed to get sender from tx at index %d: %w", i, err)
+			}
+			senders[i] = from
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	return senders, nil
+}
+
+// cachedSigner is a signer that looks up
+// senders in a SenderCache before recovering
+// them, e.g., to convert transactions to
+// messages for execution.
+type cachedSigner struct {
+	types.Signer
+	cache *SenderCache
+}
+
+// Sender returns the sender of the
+// specified transaction.
+func (s *cachedSigner) Sender(tx *types.Transaction) (common.Address, error) {
+	return s.cache.Sender(s.Signer, tx)
+}
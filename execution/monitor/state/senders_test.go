@@ -0,0 +1,75 @@
+package state
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"math/big"
+	"testing"
+)
+
+func TestSenderCache_Senders(t *testing.T) {
+	signer := types.LatestSignerForChainID(big.NewInt(1))
+
+	sk, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	from := crypto.PubkeyToAddress(sk.PublicKey)
+
+	txs := make([]*types.Transaction, 4)
+	for i := range txs {
+		txs[i], err = types.SignNewTx(sk, signer, &types.LegacyTx{Nonce: uint64(i), To: &common.Address{}})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+
+	t.Run("should recover all senders", func(t *testing.T) {
+		cache := NewSenderCache(senderCacheSize)
+
+		senders, err := cache.Senders(signer, txs)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		for i, sender := range senders {
+			if sender != from {
+				t.Errorf("expected sender %s at index %d, got %s", from.Hex(), i, sender.Hex())
+			}
+		}
+	})
+
+	t.Run("should return cached sender", func(t *testing.T) {
+		cache := NewSenderCache(senderCacheSize)
+		cached := common.HexToAddress("0xdeadbeefdeadbeefdeadbeefdeadbeefdeadbeef")
+		cache.senders.Add(txs[0].Hash(), cached)
+
+		sender, err := cache.Sender(signer, txs[0])
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if sender != cached {
+			t.Errorf("expected cached sender %s, got %s", cached.Hex(), sender.Hex())
+		}
+	})
+
+	t.Run("should fail on invalid signature", func(t *testing.T) {
+		unsigned := types.NewTx(&types.LegacyTx{To: &common.Address{}, V: big.NewInt(37), R: big.NewInt(0), S: big.NewInt(0)})
+
+		if _, err := NewSenderCache(senderCacheSize).Senders(signer, []*types.Transaction{txs[0], unsigned}); err == nil {
+			t.Errorf("expected error, got nil")
+		}
+	})
+
+	t.Run("should recover senders without cache", func(t *testing.T) {
+		var cache *SenderCache
+
+		senders, err := cache.Senders(signer, txs)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(senders) != len(txs) || senders[0] != from {
+			t.Errorf("expected %d senders, got %v", len(txs), senders)
+		}
+	})
+}
@@ -0,0 +1,155 @@
+package state
+
+import (
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+	"sparseth/ethstore"
+)
+
+// TrieStats describes the size of the
+// persistent world state trie.
+type TrieStats struct {
+	// Root is the root of the world state.
+	Root common.Hash
+	// AccountNodes is the number of nodes
+	// in the account trie.
+	AccountNodes uint64
+	// StorageNodes is the number of nodes
+	// in all storage tries.
+	StorageNodes uint64
+	// Accounts is the number of accounts
+	// in the account trie.
+	Accounts uint64
+}
+
+// LatestRoot returns the world state root after
+// the last processed block, or nil if no block
+// has been processed yet.
+func (p *TxProcessor) LatestRoot() *ethstore.StateRoot {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.latest
+}
+
+// RootAt returns the world state root after
+// the block with the specified number.
+func (p *TxProcessor) RootAt(num uint64) (*ethstore.StateRoot, error) {
+	return p.roots.Get(num)
+}
+
+// TrieStats counts the nodes of the world state
+// trie after the last processed block.
+func (p *TxProcessor) TrieStats() (*TrieStats, error) {
+	root := types.EmptyRootHash
+	if latest := p.LatestRoot(); latest != nil {
+		root = latest.Root
+	}
+
+	stats := &TrieStats{Root: root}
+	if root == types.EmptyRootHash {
+		return stats, nil
+	}
+
+	accTrie, err := trie.NewStateTrie(trie.StateTrieID(root), p.trieDB)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open account trie: %w", err)
+	}
+	it, err := accTrie.NodeIterator(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to iterate account trie: %w", err)
+	}
+
+	for it.Next(true) {
+		if !it.Leaf() {
+			if it.Hash() != (common.Hash{}) {
+				stats.AccountNodes++
+			}
+			continue
+		}
+		stats.Accounts++
+
+		var acc types.StateAccount
+		if err = rlp.DecodeBytes(it.LeafBlob(), &acc); err != nil {
+			return nil, fmt.Errorf("failed to decode account: %w", err)
+		}
+		if acc.Root == types.EmptyRootHash {
+			continue
+		}
+
+		count, err := p.countStorageNodes(root, common.BytesToHash(it.LeafKey()), acc.Root)
+		if err != nil {
+			return nil, err
+		}
+		stats.StorageNodes += count
+	}
+	if it.Error() != nil {
+		return nil, fmt.Errorf("failed to iterate account trie: %w", it.Error())
+	}
+
+	return stats, nil
+}
+
+// countStorageNodes counts the nodes of
+// the specified storage trie.
+func (p *TxProcessor) countStorageNodes(stateRoot, owner, root common.Hash) (uint64, error) {
+	storageTrie, err := trie.NewStateTrie(trie.StorageTrieID(stateRoot, owner, root), p.trieDB)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open storage trie: %w", err)
+	}
+	it, err := storageTrie.NodeIterator(nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to iterate storage trie: %w", err)
+	}
+
+	var count uint64
+	for it.Next(true) {
+		if it.Hash() != (common.Hash{}) {
+			count++
+		}
+	}
+	if it.Error() != nil {
+		return 0, fmt.Errorf("failed to iterate storage trie: %w", it.Error())
+	}
+
+	return count, nil
+}
+
+// currentRoot returns the world state
+// root after the last processed block.
+func (p *TxProcessor) currentRoot() common.Hash {
+	if latest := p.LatestRoot(); latest != nil {
+		return latest.Root
+	}
+	return types.EmptyRootHash
+}
+
+// recordRoot records the world state root and
+// the root of the computed receipts after the
+// specified block. The trie nodes of the world
+// state are flushed to disk, so that the state
+// is resumed after a restart.
+func (p *TxProcessor) recordRoot(head *types.Header, root common.Hash, receipts common.Hash) error {
+	if err := p.trieDB.Commit(root, false); err != nil {
+		return fmt.Errorf("failed to flush state of block %d: %w", head.Number.Uint64(), err)
+	}
+
+	latest := &ethstore.StateRoot{
+		Number:       head.Number.Uint64(),
+		BlockHash:    head.Hash(),
+		Root:         root,
+		ReceiptsHash: receipts,
+	}
+	if err := p.roots.Put(latest); err != nil {
+		return fmt.Errorf("failed to store state root for block %d: %w", head.Number.Uint64(), err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.latest = latest
+	return nil
+}
@@ -0,0 +1,56 @@
+package state
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+	"math/big"
+)
+
+// historyBufferLength is the length of the
+// ring buffers of both the beacon roots
+// (EIP-4788) and the history storage
+// (EIP-2935) system contracts.
+const historyBufferLength = 8191
+
+// applySystemCalls applies the system calls that
+// precede the transactions of the specified block,
+// i.e., storing the parent beacon block root since
+// Cancun and the parent block hash since Prague,
+// as geth does.
+func applySystemCalls(cc *params.ChainConfig, header *types.Header, evm *vm.EVM) {
+	if header.ParentBeaconRoot != nil {
+		core.ProcessBeaconBlockRoot(*header.ParentBeaconRoot, evm)
+	}
+	if cc.IsPrague(header.Number, header.Time) {
+		core.ProcessParentBlockHash(header.ParentHash, evm)
+	}
+}
+
+// systemCallSlots returns the system contracts called
+// before the transactions of the specified block, along
+// with the storage slots written by the calls, so that
+// they are part of the partial state.
+func systemCallSlots(cc *params.ChainConfig, header *types.Header) map[common.Address][]common.Hash {
+	slots := make(map[common.Address][]common.Hash)
+	if header.ParentBeaconRoot != nil {
+		idx := header.Time % historyBufferLength
+		slots[params.BeaconRootsAddress] = []common.Hash{
+			slotHash(idx),
+			slotHash(idx + historyBufferLength),
+		}
+	}
+	if cc.IsPrague(header.Number, header.Time) && header.Number.Sign() > 0 {
+		idx := (header.Number.Uint64() - 1) % historyBufferLength
+		slots[params.HistoryStorageAddress] = []common.Hash{slotHash(idx)}
+	}
+	return slots
+}
+
+// slotHash returns the storage slot
+// with the specified index.
+func slotHash(idx uint64) common.Hash {
+	return common.BigToHash(new(big.Int).SetUint64(idx))
+}
@@ -0,0 +1,74 @@
+package state
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+	"math/big"
+	"testing"
+)
+
+func TestSystemCallSlots(t *testing.T) {
+	root := common.HexToHash("0xaa")
+
+	t.Run("should return no slots before Cancun", func(t *testing.T) {
+		header := &types.Header{Number: big.NewInt(1)}
+
+		if slots := systemCallSlots(params.TestChainConfig, header); len(slots) != 0 {
+			t.Errorf("expected no slots, got %v", slots)
+		}
+	})
+
+	t.Run("should return slots of beacon roots and history contracts", func(t *testing.T) {
+		header := &types.Header{Number: big.NewInt(8193), Time: 8192, ParentBeaconRoot: &root}
+
+		slots := systemCallSlots(params.MergedTestChainConfig, header)
+		beacon := slots[params.BeaconRootsAddress]
+		if len(beacon) != 2 || beacon[0] != slotHash(1) || beacon[1] != slotHash(8192) {
+			t.Errorf("expected beacon root slots 1 and 8192, got %v", beacon)
+		}
+		history := slots[params.HistoryStorageAddress]
+		if len(history) != 1 || history[0] != slotHash(1) {
+			t.Errorf("expected history slot 1, got %v", history)
+		}
+	})
+}
+
+func TestApplySystemCalls(t *testing.T) {
+	t.Run("should store beacon root and parent hash", func(t *testing.T) {
+		world, err := state.New(types.EmptyRootHash, state.NewDatabaseForTesting())
+		if err != nil {
+			t.Fatalf("failed to create state: %v", err)
+		}
+		world.SetCode(params.BeaconRootsAddress, params.BeaconRootsCode)
+		world.SetCode(params.HistoryStorageAddress, params.HistoryStorageCode)
+		world.SetNonce(params.BeaconRootsAddress, 1, tracing.NonceChangeUnspecified)
+		world.SetNonce(params.HistoryStorageAddress, 1, tracing.NonceChangeUnspecified)
+
+		root := common.HexToHash("0xaa")
+		header := &types.Header{
+			Number:           big.NewInt(2),
+			Time:             12,
+			ParentHash:       common.HexToHash("0xbb"),
+			ParentBeaconRoot: &root,
+			Difficulty:       common.Big0,
+			BaseFee:          common.Big0,
+			GasLimit:         30_000_000,
+		}
+
+		cc := params.MergedTestChainConfig
+		context := core.NewEVMBlockContext(header, &HeaderContext{Params: cc}, &header.Coinbase)
+		applySystemCalls(cc, header, vm.NewEVM(context, world, cc, vm.Config{}))
+
+		if got := world.GetState(params.BeaconRootsAddress, slotHash(12+historyBufferLength)); got != root {
+			t.Errorf("expected beacon root %s, got %s", root.Hex(), got.Hex())
+		}
+		if got := world.GetState(params.HistoryStorageAddress, slotHash(1)); got != header.ParentHash {
+			t.Errorf("expected parent hash %s, got %s", header.ParentHash.Hex(), got.Hex())
+		}
+	})
+}
@@ -9,6 +9,7 @@ import (
 	"sparseth/config"
 	"sparseth/ethstore"
 	"sparseth/execution/ethclient"
+	"sparseth/execution/monitor"
 	"sparseth/log"
 )
 
@@ -17,20 +18,28 @@ import (
 type Verifier struct {
 	store    *ethstore.HeaderStore
 	provider ethclient.Provider
-	log      log.Logger
+	// allowlist holds the addresses whose
+	// uninitialized reads are not verified.
+	allowlist *ReadAllowlist
+	log       log.Logger
 }
 
 // NewVerifier creates a new Verifier instance.
-func NewVerifier(store *ethstore.HeaderStore, provider ethclient.Provider, log log.Logger) *Verifier {
+// Uninitialized reads of addresses in the
+// specified allowlist, if not nil, are not
+// verified.
+func NewVerifier(store *ethstore.HeaderStore, provider ethclient.Provider, allowlist *ReadAllowlist, log log.Logger) *Verifier {
 	return &Verifier{
-		store:    store,
-		provider: provider,
-		log:      log.With("component", "state-verifier"),
+		store:     store,
+		provider:  provider,
+		allowlist: allowlist,
+		log:       log.With("component", "state-verifier"),
 	}
 }
 
 // VerifyUninitializedReads checks whether the uninitialized
-// reads from the world state are valid.
+// reads from the world state are valid. Reads of allowlisted
+// addresses are skipped.
 func (v *Verifier) VerifyUninitializedReads(ctx context.Context, header *types.Header, world *TracingStateDB) error {
 	prev, err := v.store.GetByNumber(header.Number.Uint64() - 1)
 	if err != nil {
@@ -38,12 +47,18 @@ func (v *Verifier) VerifyUninitializedReads(ctx context.Context, header *types.H
 	}
 
 	for _, acc := range world.UninitializedAccountReads() {
+		if v.allowlist.Contains(acc, header) {
+			continue
+		}
 		if err = v.verifyAccountRead(ctx, acc, prev); err != nil {
 			return fmt.Errorf("uninitialized account read for %s: %w", acc.Hex(), err)
 		}
 	}
 
 	for _, tuple := range world.UninitializedStorageReads() {
+		if v.allowlist.Contains(tuple.Address, header) {
+			continue
+		}
 		if err = v.verifyStorageRead(ctx, tuple, prev); err != nil {
 			return fmt.Errorf("uninitialized storage read for account %s: %w", tuple.Address.Hex(), err)
 		}
@@ -129,7 +144,7 @@ func (v *Verifier) VerifyCompleteness(ctx context.Context, acc *config.AccountCo
 		actual := world.GetState(acc.Addr, acc.ContractConfig.State.CountSlot)
 		if common.BytesToHash(counter) != actual {
 			v.logWithContext("interaction counter mismatch", expected, header)
-			return fmt.Errorf("interaction counter mismatch: expected: %s, got: %s", common.Bytes2Hex(counter), actual.Hex())
+			return &monitor.MismatchError{Kind: "interaction counter", Expected: common.BytesToHash(counter).Hex(), Actual: actual.Hex()}
 		}
 	}
 
@@ -138,34 +153,42 @@ func (v *Verifier) VerifyCompleteness(ctx context.Context, acc *config.AccountCo
 
 // verifyExternallyOwnedAccount verifies the state of an
 // externally owned account (EOA) against the world state.
+// Delegated EOAs are verified like any other EOA.
 func (v *Verifier) verifyExternallyOwnedAccount(expected *ethclient.Account, header *types.Header, world vm.StateDB) error {
 	if !world.Exist(expected.Address) {
 		v.logWithContext("account exists on-chain but not in world state", expected, header)
-		return fmt.Errorf("account does not exist in world state, but on-chain")
+		return &monitor.MismatchError{Kind: "existence", Expected: "exists", Actual: "missing"}
 	}
 
 	nonce := world.GetNonce(expected.Address)
 	if expected.Nonce != nonce {
 		v.logWithContext("nonce mismatch", expected, header)
-		return fmt.Errorf("nonce mismatch: expected: %d, got; %d", expected.Nonce, nonce)
+		return &monitor.MismatchError{Kind: "nonce", Expected: fmt.Sprint(expected.Nonce), Actual: fmt.Sprint(nonce)}
 	}
 
 	balance := world.GetBalance(expected.Address).ToBig()
 	if expected.Balance.Cmp(balance) != 0 {
 		v.logWithContext("balance mismatch", expected, header)
-		return fmt.Errorf("balance mismatch: expected: %d, got: %d", expected.Balance, balance)
+		return &monitor.MismatchError{Kind: "balance", Expected: expected.Balance.String(), Actual: balance.String()}
 	}
 
+	// The code of an EOA with a delegation designator
+	// (EIP-7702) is the designator itself, so its code
+	// hash commits to the delegate
 	codeHash := world.GetCodeHash(expected.Address)
 	if expected.CodeHash != codeHash {
+		if target, ok := types.ParseDelegation(world.GetCode(expected.Address)); ok {
+			v.logWithContext("delegation mismatch", expected, header)
+			return &monitor.MismatchError{Kind: "delegation", Expected: expected.CodeHash.Hex(), Actual: "delegation to " + target.Hex()}
+		}
 		v.logWithContext("code hash mismatch", expected, header)
-		return fmt.Errorf("code hash mismatch: expected: %s, got: %s", expected.CodeHash.Hex(), codeHash.Hex())
+		return &monitor.MismatchError{Kind: "code hash", Expected: expected.CodeHash.Hex(), Actual: codeHash.Hex()}
 	}
 
 	storageRoot := world.GetStorageRoot(expected.Address)
 	if expected.StorageRoot != storageRoot {
 		v.logWithContext("storage root mismatch", expected, header)
-		return fmt.Errorf("storage root mismatch: expected: %s, got: %s", expected.StorageRoot.Hex(), storageRoot.Hex())
+		return &monitor.MismatchError{Kind: "storage root", Expected: expected.StorageRoot.Hex(), Actual: storageRoot.Hex()}
 	}
 
 	return nil
@@ -35,7 +35,15 @@ func (t *verifierTestProvider) GetTxsAtBlock(context.Context, *types.Header) ([]
 	return nil, nil
 }
 
-func (t *verifierTestProvider) GetLogsAtBlock(context.Context, common.Address, *big.Int) ([]*types.Log, error) {
+func (t *verifierTestProvider) GetWithdrawalsAtBlock(context.Context, *types.Header) ([]*types.Withdrawal, error) {
+	return nil, nil
+}
+
+func (t *verifierTestProvider) GetLogsAtBlock(context.Context, common.Address, *big.Int, []common.Hash) ([]*types.Log, error) {
+	return nil, nil
+}
+
+func (t *verifierTestProvider) GetLogsInRange(context.Context, common.Address, *big.Int, *big.Int, []common.Hash) ([]*types.Log, error) {
 	return nil, nil
 }
 
@@ -43,6 +51,10 @@ func (t *verifierTestProvider) GetAccountAtBlock(context.Context, common.Address
 	return t.acc, t.err
 }
 
+func (t *verifierTestProvider) GetAccountsAtBlock(context.Context, []*ethclient.ProofRequest, *types.Header) ([]*ethclient.AccountState, error) {
+	return nil, t.err
+}
+
 func (t *verifierTestProvider) GetStorageAtBlock(context.Context, common.Address, common.Hash, *types.Header) ([]byte, error) {
 	return t.storage, t.err
 }
@@ -55,10 +67,14 @@ func (t *verifierTestProvider) GetTransactionTrace(context.Context, common.Hash)
 	return nil, nil
 }
 
+func (t *verifierTestProvider) GetBlockTraces(context.Context, *types.Header) ([]*ethclient.TransactionTrace, error) {
+	return nil, nil
+}
+
 func TestVerifier_VerifyUninitializedReads(t *testing.T) {
 	t.Run("should return error when previous header cannot be retrieved", func(t *testing.T) {
 		store := ethstore.NewHeaderStore(mem.New())
-		v := NewVerifier(store, nil, log.New(slog.DiscardHandler))
+		v := NewVerifier(store, nil, nil, log.New(slog.DiscardHandler))
 
 		header := &types.Header{
 			Number: big.NewInt(1),
@@ -91,7 +107,7 @@ func TestVerifier_VerifyUninitializedReads(t *testing.T) {
 			t.Fatalf("failed to create world state: %v", err)
 		}
 
-		v := NewVerifier(store, nil, logger)
+		v := NewVerifier(store, nil, nil, logger)
 		if err = v.VerifyUninitializedReads(t.Context(), header, world); err != nil {
 			t.Errorf("expected no error, got %v", err)
 		}
@@ -129,7 +145,7 @@ func TestVerifier_VerifyUninitializedReads(t *testing.T) {
 			err: fmt.Errorf("failed to retrieve account"),
 		}
 
-		v := NewVerifier(store, testProvider, logger)
+		v := NewVerifier(store, testProvider, nil, logger)
 		if err = v.VerifyUninitializedReads(t.Context(), header, world); err == nil {
 			t.Errorf("expected error when account could not be retrieved, got nil")
 		}
@@ -172,12 +188,56 @@ func TestVerifier_VerifyUninitializedReads(t *testing.T) {
 			},
 		}
 
-		v := NewVerifier(store, testProvider, logger)
+		v := NewVerifier(store, testProvider, nil, logger)
 		if err = v.VerifyUninitializedReads(t.Context(), header, world); err == nil {
 			t.Errorf("expected error when account exists but was flagged as uninitialized read, got nil")
 		}
 	})
 
+	t.Run("should skip uninitialized read of allowlisted account", func(t *testing.T) {
+		prev := &types.Header{
+			Number: big.NewInt(1),
+		}
+
+		store := ethstore.NewHeaderStore(mem.New())
+		if err := store.Put(prev); err != nil {
+			t.Fatalf("failed to store previous header: %v", err)
+		}
+
+		header := &types.Header{
+			Number: big.NewInt(2),
+		}
+
+		logger := log.New(slog.DiscardHandler)
+		db := rawdb.NewDatabase(mem.New())
+		trieDB := triedb.NewDatabase(db, nil)
+		stateDB := state.NewDatabase(trieDB, nil)
+		world, err := NewWithEmptyTraces(types.EmptyRootHash, stateDB, logger)
+		if err != nil {
+			t.Fatalf("failed to create world state: %v", err)
+		}
+
+		// Create uninitialized read
+		addr := common.HexToAddress("0xdeadbeefdeadbeefdeadbeefdeadbeefdeadbeef")
+		world.GetNonce(addr)
+
+		testProvider := &verifierTestProvider{
+			acc: &ethclient.Account{
+				Address:     addr,
+				Nonce:       1,
+				Balance:     big.NewInt(1),
+				CodeHash:    types.EmptyCodeHash,
+				StorageRoot: types.EmptyRootHash,
+			},
+		}
+
+		allowlist := NewReadAllowlist(params.MainnetChainConfig, false, []common.Address{addr})
+		v := NewVerifier(store, testProvider, allowlist, logger)
+		if err = v.VerifyUninitializedReads(t.Context(), header, world); err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+	})
+
 	t.Run("should return no error if account creation", func(t *testing.T) {
 		prev := &types.Header{
 			Number: big.NewInt(1),
@@ -205,7 +265,7 @@ func TestVerifier_VerifyUninitializedReads(t *testing.T) {
 		addr := common.HexToAddress("0xdeadbeefdeadbeefdeadbeefdeadbeefdeadbeef")
 		world.GetNonce(addr)
 
-		v := NewVerifier(store, &verifierTestProvider{}, logger)
+		v := NewVerifier(store, &verifierTestProvider{}, nil, logger)
 		if err = v.VerifyUninitializedReads(t.Context(), header, world); err != nil {
 			t.Errorf("expected no error, got: %v", err)
 		}
@@ -249,7 +309,7 @@ func TestVerifier_VerifyUninitializedReads(t *testing.T) {
 			},
 		}
 
-		v := NewVerifier(store, testProvider, logger)
+		v := NewVerifier(store, testProvider, nil, logger)
 		if err = v.VerifyUninitializedReads(t.Context(), header, world); err != nil {
 			t.Errorf("expected no error, got: %v", err)
 		}
@@ -262,7 +322,7 @@ func TestVerifier_VerifyCompleteness(t *testing.T) {
 			acc: nil,
 			err: fmt.Errorf("failed to retrieve account"),
 		}
-		v := NewVerifier(nil, testProvider, log.New(slog.DiscardHandler))
+		v := NewVerifier(nil, testProvider, nil, log.New(slog.DiscardHandler))
 
 		acc := &config.AccountConfig{
 			Addr: common.HexToAddress("0xdeadbeefdeadbeefdeadbeefdeadbeefdeadbeef"),
@@ -285,7 +345,7 @@ func TestVerifier_VerifyCompleteness(t *testing.T) {
 	})
 
 	t.Run("should succeed when account does not exist", func(t *testing.T) {
-		v := NewVerifier(nil, &verifierTestProvider{}, log.New(slog.DiscardHandler))
+		v := NewVerifier(nil, &verifierTestProvider{}, nil, log.New(slog.DiscardHandler))
 
 		acc := &config.AccountConfig{
 			Addr: common.HexToAddress("0xdeadbeefdeadbeefdeadbeefdeadbeefdeadbeef"),
@@ -313,7 +373,7 @@ func TestVerifier_VerifyCompleteness(t *testing.T) {
 				Address: common.HexToAddress("0xdeadbeefdeadbeefdeadbeefdeadbeefdeadbeef"),
 			},
 		}
-		v := NewVerifier(nil, testProvider, log.New(slog.DiscardHandler))
+		v := NewVerifier(nil, testProvider, nil, log.New(slog.DiscardHandler))
 
 		acc := &config.AccountConfig{
 			Addr: testProvider.acc.Address,
@@ -342,7 +402,7 @@ func TestVerifier_VerifyCompleteness(t *testing.T) {
 				Nonce:   2,
 			},
 		}
-		v := NewVerifier(nil, testProvider, log.New(slog.DiscardHandler))
+		v := NewVerifier(nil, testProvider, nil, log.New(slog.DiscardHandler))
 
 		acc := &config.AccountConfig{
 			Addr: testProvider.acc.Address,
@@ -383,7 +443,7 @@ func TestVerifier_VerifyCompleteness(t *testing.T) {
 				Balance: big.NewInt(1000),
 			},
 		}
-		v := NewVerifier(nil, testProvider, log.New(slog.DiscardHandler))
+		v := NewVerifier(nil, testProvider, nil, log.New(slog.DiscardHandler))
 
 		acc := &config.AccountConfig{
 			Addr: testProvider.acc.Address,
@@ -426,7 +486,7 @@ func TestVerifier_VerifyCompleteness(t *testing.T) {
 				CodeHash: common.HexToHash("0xdeadbeef"),
 			},
 		}
-		v := NewVerifier(nil, testProvider, log.New(slog.DiscardHandler))
+		v := NewVerifier(nil, testProvider, nil, log.New(slog.DiscardHandler))
 
 		acc := &config.AccountConfig{
 			Addr: testProvider.acc.Address,
@@ -472,7 +532,7 @@ func TestVerifier_VerifyCompleteness(t *testing.T) {
 				StorageRoot: common.HexToHash("0xdeadbeef"),
 			},
 		}
-		v := NewVerifier(nil, testProvider, log.New(slog.DiscardHandler))
+		v := NewVerifier(nil, testProvider, nil, log.New(slog.DiscardHandler))
 
 		acc := &config.AccountConfig{
 			Addr: testProvider.acc.Address,
@@ -519,7 +579,7 @@ func TestVerifier_VerifyCompleteness(t *testing.T) {
 				StorageRoot: types.EmptyRootHash,
 			},
 		}
-		v := NewVerifier(nil, testProvider, log.New(slog.DiscardHandler))
+		v := NewVerifier(nil, testProvider, nil, log.New(slog.DiscardHandler))
 
 		acc := &config.AccountConfig{
 			Addr:           testProvider.acc.Address,
@@ -567,7 +627,7 @@ func TestVerifier_VerifyCompleteness(t *testing.T) {
 			},
 			storage: common.BigToHash(big.NewInt(2)).Bytes(),
 		}
-		v := NewVerifier(nil, testProvider, log.New(slog.DiscardHandler))
+		v := NewVerifier(nil, testProvider, nil, log.New(slog.DiscardHandler))
 
 		acc := &config.AccountConfig{
 			Addr: testProvider.acc.Address,
@@ -620,7 +680,7 @@ func TestVerifier_VerifyCompleteness(t *testing.T) {
 			},
 			storage: common.BigToHash(big.NewInt(1)).Bytes(),
 		}
-		v := NewVerifier(nil, testProvider, log.New(slog.DiscardHandler))
+		v := NewVerifier(nil, testProvider, nil, log.New(slog.DiscardHandler))
 
 		acc := &config.AccountConfig{
 			Addr: testProvider.acc.Address,
@@ -673,7 +733,7 @@ func TestVerifier_VerifyCompleteness(t *testing.T) {
 			},
 			storage: nil,
 		}
-		v := NewVerifier(nil, testProvider, log.New(slog.DiscardHandler))
+		v := NewVerifier(nil, testProvider, nil, log.New(slog.DiscardHandler))
 
 		acc := &config.AccountConfig{
 			Addr: testProvider.acc.Address,
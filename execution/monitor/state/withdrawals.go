@@ -0,0 +1,50 @@
+package state
+
+import (
+	"context"
+	"fmt"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/holiman/uint256"
+	"sparseth/config"
+)
+
+// withdrawals returns the verified withdrawals of
+// the specified block. Prefetched withdrawals are
+// used, if available.
+func (p *TxProcessor) withdrawals(ctx context.Context, head *types.Header, prepared *preparedBlock) ([]*types.Withdrawal, error) {
+	if prepared != nil {
+		return prepared.withdrawals, nil
+	}
+
+	withdrawals, err := p.provider.GetWithdrawalsAtBlock(ctx, head)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get withdrawals at block %d: %w", head.Number.Uint64(), err)
+	}
+	return withdrawals, nil
+}
+
+// applyWithdrawals credits the specified withdrawals
+// to the persistent world state. The changes can be
+// reverted like any other merged change.
+func (p *TxProcessor) applyWithdrawals(withdrawals []*types.Withdrawal) {
+	for _, w := range withdrawals {
+		// Withdrawal amounts are denominated in Gwei
+		amount := new(uint256.Int).Mul(uint256.NewInt(w.Amount), uint256.NewInt(params.GWei))
+		balance := new(uint256.Int).Add(p.world.GetBalance(w.Address), amount)
+		p.world.SetBalance(w.Address, balance, tracing.BalanceIncreaseWithdrawal)
+	}
+}
+
+// relevantWithdrawals returns all withdrawals
+// crediting one of the specified accounts.
+func relevantWithdrawals(withdrawals []*types.Withdrawal, accs *config.AccountsConfig) []*types.Withdrawal {
+	relevant := make([]*types.Withdrawal, 0)
+	for _, w := range withdrawals {
+		if accs.Contains(w.Address) {
+			relevant = append(relevant, w)
+		}
+	}
+	return relevant
+}
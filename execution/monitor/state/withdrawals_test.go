@@ -0,0 +1,59 @@
+package state
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/triedb"
+	"github.com/holiman/uint256"
+	"sparseth/config"
+	"sparseth/storage/mem"
+	"testing"
+)
+
+func TestTxProcessor_ApplyWithdrawals(t *testing.T) {
+	t.Run("should credit withdrawals in wei and revert them", func(t *testing.T) {
+		db := rawdb.NewDatabase(mem.New())
+		stateDB := state.NewDatabase(triedb.NewDatabase(db, nil), nil)
+
+		world, err := NewRevertingStateDB(types.EmptyRootHash, stateDB)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		p := &TxProcessor{world: world}
+
+		addr := common.HexToAddress("0x01")
+		p.applyWithdrawals([]*types.Withdrawal{
+			{Address: addr, Amount: 2},
+			{Address: addr, Amount: 3},
+		})
+
+		expected := uint256.NewInt(5 * params.GWei)
+		if got := world.GetBalance(addr); !got.Eq(expected) {
+			t.Errorf("expected balance %s, got %s", expected, got)
+		}
+
+		world.Revert()
+		if got := world.GetBalance(addr); !got.IsZero() {
+			t.Errorf("expected zero balance after revert, got %s", got)
+		}
+	})
+}
+
+func TestRelevantWithdrawals(t *testing.T) {
+	t.Run("should keep withdrawals to monitored accounts", func(t *testing.T) {
+		monitored := common.HexToAddress("0x01")
+		accs := &config.AccountsConfig{Accounts: []*config.AccountConfig{{Addr: monitored}}}
+
+		relevant := relevantWithdrawals([]*types.Withdrawal{
+			{Index: 1, Address: monitored},
+			{Index: 2, Address: common.HexToAddress("0x02")},
+		}, accs)
+
+		if len(relevant) != 1 || relevant[0].Index != 1 {
+			t.Errorf("expected only withdrawal to monitored account, got %v", relevant)
+		}
+	})
+}
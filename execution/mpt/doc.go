@@ -0,0 +1,16 @@
+// Package mpt verifies Merkle-Patricia trie proofs
+// of Ethereum accounts and storage slots, e.g., as
+// returned by eth_getProof.
+//
+// The package only depends on go-ethereum, so it
+// can be used independently of the node. Proofs
+// are verified against a trusted root: the state
+// root of a block header for account proofs, and
+// the storage root of a verified account for
+// storage proofs.
+//
+// All exported functions and types are considered
+// stable. Invalid proofs are reported by errors
+// wrapping ErrInvalidProof, while valid proofs of
+// absence yield nil values.
+package mpt
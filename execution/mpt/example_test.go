@@ -0,0 +1,38 @@
+package mpt_test
+
+import (
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/ethereum/go-ethereum/trie/trienode"
+	"github.com/pslowak/sparseth/execution/mpt"
+)
+
+func ExampleVerifyStorageProof() {
+	// Build a storage trie with a single slot
+	slot := common.HexToHash("0x01")
+	value, _ := rlp.EncodeToBytes([]byte{0x2a})
+
+	storage := trie.NewEmpty(nil)
+	storage.MustUpdate(mpt.StorageKey(slot).Bytes(), value)
+
+	// Prove the slot, as eth_getProof does
+	var proof trienode.ProofList
+	if err := storage.Prove(mpt.StorageKey(slot).Bytes(), &proof); err != nil {
+		panic(err)
+	}
+
+	nodes := make([][]byte, len(proof))
+	for i, node := range proof {
+		nodes[i] = node
+	}
+
+	verified, err := mpt.VerifyStorageProof(storage.Hash(), mpt.StorageKey(slot), nodes)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Printf("%#x\n", verified)
+	// Output: 0x2a
+}
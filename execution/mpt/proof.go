@@ -1,14 +1,22 @@
 package mpt
 
 import (
+	"errors"
 	"fmt"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/trie"
+	"github.com/ethereum/go-ethereum/trie/trienode"
 	"math/big"
-	"sparseth/storage/mem"
+)
+
+var (
+	// ErrInvalidProof is returned if a proof does
+	// not prove the presence or absence of a key
+	// against the specified root.
+	ErrInvalidProof = errors.New("invalid proof")
 )
 
 // Account represents an Ethereum account.
@@ -26,7 +34,7 @@ type Account struct {
 // is returned.
 func VerifyAccountProof(stateRoot common.Hash, address common.Address, proofNodes [][]byte) (*Account, error) {
 	key := crypto.Keccak256(address[:])
-	data, err := verifyProof(stateRoot, key[:], proofNodes)
+	data, err := VerifyProof(stateRoot, key, proofNodes)
 	if err != nil {
 		return nil, err
 	}
@@ -35,12 +43,7 @@ func VerifyAccountProof(stateRoot common.Hash, address common.Address, proofNode
 		return nil, nil
 	}
 
-	var account Account
-	if err := rlp.DecodeBytes(data, &account); err != nil {
-		return nil, fmt.Errorf("failed to decode account: %w", err)
-	}
-
-	return &account, err
+	return DecodeAccount(data)
 }
 
 // VerifyStorageProof verifies a Merkle proof for a given slot key
@@ -48,14 +51,14 @@ func VerifyAccountProof(stateRoot common.Hash, address common.Address, proofNode
 // slot key, nil is returned.
 //
 // Note that it is assumed that the slot key is a Keccak256 hash
-// of the byte key.
+// of the byte key, see StorageKey.
 func VerifyStorageProof(storageRoot common.Hash, slotKey common.Hash, proofNodes [][]byte) ([]byte, error) {
 	if storageRoot == types.EmptyRootHash {
 		// No storage for any key
 		return nil, nil
 	}
 
-	data, err := verifyProof(storageRoot, slotKey[:], proofNodes)
+	data, err := VerifyProof(storageRoot, slotKey[:], proofNodes)
 	if err != nil {
 		return nil, err
 	}
@@ -64,28 +67,49 @@ func VerifyStorageProof(storageRoot common.Hash, slotKey common.Hash, proofNodes
 		return nil, nil
 	}
 
-	var val []byte
-	if err := rlp.DecodeBytes(data, &val); err != nil {
-		return nil, fmt.Errorf("failed to decode value: %w", err)
-	}
-
-	return val, nil
+	return DecodeStorageValue(data)
 }
 
-// verifyProof verifies a Merkle proof for a given key against
+// VerifyProof verifies a Merkle proof for a given key against
 // a root hash.
 //
 // Note that the returned value is RLP encoded, or nil if no
 // such value exists.
-func verifyProof(rootHash common.Hash, key []byte, proofNodes [][]byte) ([]byte, error) {
-	proof := mem.New()
-	defer proof.Close()
+func VerifyProof(rootHash common.Hash, key []byte, proofNodes [][]byte) ([]byte, error) {
+	proof := make(trienode.ProofList, len(proofNodes))
+	for i, node := range proofNodes {
+		proof[i] = node
+	}
+
+	val, err := trie.VerifyProof(rootHash, key, proof.Set())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidProof, err)
+	}
+	return val, nil
+}
 
-	for _, node := range proofNodes {
-		if err := proof.Put(crypto.Keccak256(node), node); err != nil {
-			return nil, fmt.Errorf("failed to put proof node: %w", err)
-		}
+// StorageKey returns the key of the specified
+// storage slot in the storage trie.
+func StorageKey(slot common.Hash) common.Hash {
+	return crypto.Keccak256Hash(slot.Bytes())
+}
+
+// DecodeAccount decodes an RLP encoded account,
+// i.e., a value of the state trie.
+func DecodeAccount(data []byte) (*Account, error) {
+	var account Account
+	if err := rlp.DecodeBytes(data, &account); err != nil {
+		return nil, fmt.Errorf("failed to decode account: %w", err)
 	}
+	return &account, nil
+}
 
-	return trie.VerifyProof(rootHash, key, proof)
+// DecodeStorageValue decodes an RLP encoded
+// value of a storage trie.
+func DecodeStorageValue(data []byte) ([]byte, error) {
+	var val []byte
+	if err := rlp.DecodeBytes(data, &val); err != nil {
+		return nil, fmt.Errorf("failed to decode value: %w", err)
+	}
+	return val, nil
 }
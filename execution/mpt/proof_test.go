@@ -3,6 +3,7 @@ package mpt
 import (
 	"bytes"
 	"encoding/hex"
+	"errors"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -119,8 +120,8 @@ func TestVerifyAccountProof(t *testing.T) {
 		proofNodes[len(proofNodes)-1][len(proofNodes[len(proofNodes)-1])-1] ^= 0x01
 
 		_, err := VerifyAccountProof(stateRoot, address, proofNodes)
-		if err == nil {
-			t.Errorf("expected invalid proof error")
+		if !errors.Is(err, ErrInvalidProof) {
+			t.Errorf("expected invalid proof error, got %v", err)
 		}
 	})
 }
@@ -264,8 +265,18 @@ func TestVerifyStorageProof(t *testing.T) {
 		proofNodes[len(proofNodes)-1][len(proofNodes[len(proofNodes)-1])-1] ^= 0x01
 
 		_, err := VerifyStorageProof(storageRoot, slotKey, proofNodes)
-		if err == nil {
-			t.Errorf("expected invalid proof error")
+		if !errors.Is(err, ErrInvalidProof) {
+			t.Errorf("expected invalid proof error, got %v", err)
+		}
+	})
+}
+
+func TestStorageKey(t *testing.T) {
+	t.Run("should hash slot", func(t *testing.T) {
+		expected := crypto.Keccak256Hash(make([]byte, 32))
+
+		if key := StorageKey(common.Hash{}); key != expected {
+			t.Errorf("expected %s, got %s", expected, key)
 		}
 	})
 }
@@ -0,0 +1,50 @@
+package hook
+
+import (
+	"context"
+	"sparseth/bus"
+	"sparseth/ethstore"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Hook is the interface of an operator-defined
+// verification check, e.g., an invariant such as
+// "the total supply never decreases".
+//
+// Hooks run after all monitors processed a block,
+// but before the block is committed. A hook vetoes
+// the commit by returning an error, in which case
+// the block is committed as not verified.
+type Hook interface {
+	// Name uniquely identifies the hook.
+	Name() string
+
+	// Check inspects the specified report, and
+	// returns the alerts to raise, if any.
+	Check(ctx context.Context, report *Report) ([]*bus.Alert, error)
+}
+
+// Report is the verification report of a
+// block that is about to be committed.
+type Report struct {
+	// Commit holds the results of
+	// all monitors for the block.
+	Commit *bus.BlockCommit
+	// Diffs holds the verified state changes of
+	// all monitored accounts changed by the block.
+	// Only available in sparse mode, and if the
+	// state of the parent block is still known.
+	Diffs []*AccountDiff
+}
+
+// AccountDiff is the verified state change
+// of a single account within a block.
+type AccountDiff struct {
+	Address common.Address
+	// Before is the state at the parent
+	// block, or nil if unknown.
+	Before *ethstore.AccountSnapshot
+	// After is the state at the block.
+	After *ethstore.AccountSnapshot
+}
@@ -0,0 +1,41 @@
+package hook
+
+import (
+	"fmt"
+	"plugin"
+)
+
+// pluginSymbol is the name of the constructor
+// a hook plugin must export.
+const pluginSymbol = "NewHook"
+
+// LoadPlugin loads the hook of the Go plugin
+// at the specified path. The plugin must export
+// a constructor
+//
+//	func NewHook() (hook.Hook, error)
+//
+// and be built against the same version of
+// sparseth, see plugin.Open.
+func LoadPlugin(path string) (Hook, error) {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open plugin %s: %w", path, err)
+	}
+
+	sym, err := p.Lookup(pluginSymbol)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up %s in plugin %s: %w", pluginSymbol, path, err)
+	}
+
+	newHook, ok := sym.(func() (Hook, error))
+	if !ok {
+		return nil, fmt.Errorf("unexpected type %T of %s in plugin %s", sym, pluginSymbol, path)
+	}
+
+	h, err := newHook()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create hook of plugin %s: %w", path, err)
+	}
+	return h, nil
+}
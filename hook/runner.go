@@ -0,0 +1,141 @@
+package hook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sparseth/bus"
+	"sparseth/ethstore"
+	"sparseth/log"
+	"sparseth/storage"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// checkTimeout is the maximum duration
+// of a single hook check.
+const checkTimeout = 10 * time.Second
+
+// Runner runs all hooks on each
+// block before it is committed.
+type Runner struct {
+	hooks     []Hook
+	headers   *ethstore.HeaderStore
+	snapshots *ethstore.SnapshotStore
+	accounts  func() []common.Address
+	alerts    *bus.Topic[*bus.Alert]
+	log       log.Logger
+}
+
+// NewRunner creates a new Runner for the specified
+// hooks. The state diffs of the specified accounts
+// are read from the specified key-val store, and
+// alerts are published to the specified topic.
+func NewRunner(hooks []Hook, db storage.KeyValStore, accounts func() []common.Address, alerts *bus.Topic[*bus.Alert], log log.Logger) *Runner {
+	return &Runner{
+		hooks:     hooks,
+		headers:   ethstore.NewHeaderStore(db),
+		snapshots: ethstore.NewSnapshotStore(db, 0),
+		accounts:  accounts,
+		alerts:    alerts,
+		log:       log.With("component", "hook-runner"),
+	}
+}
+
+// Check runs all hooks on the specified block,
+// and returns a result for each hook that vetoed
+// the block.
+func (r *Runner) Check(commit *bus.BlockCommit) []*bus.Result {
+	report := &Report{
+		Commit: commit,
+		Diffs:  r.diffs(commit),
+	}
+
+	var vetoes []*bus.Result
+	for _, h := range r.hooks {
+		name := "hook/" + h.Name()
+
+		ctx, cancel := context.WithTimeout(context.Background(), checkTimeout)
+		alerts, err := h.Check(ctx, report)
+		cancel()
+
+		for _, alert := range alerts {
+			alert.Source = name
+			alert.Number = commit.Number
+			r.alerts.Publish(alert)
+		}
+
+		if err != nil {
+			r.log.Warn("hook vetoed block", "hook", h.Name(), "num", commit.Number, "hash", commit.Hash.Hex(), "err", err)
+			vetoes = append(vetoes, &bus.Result{
+				Monitor: name,
+				Number:  commit.Number,
+				Hash:    commit.Hash,
+				Err:     fmt.Errorf("vetoed by hook %s: %w", h.Name(), err),
+			})
+		}
+	}
+	return vetoes
+}
+
+// diffs returns the verified state changes of
+// all monitored accounts within the specified
+// block, if snapshots of the block are known.
+func (r *Runner) diffs(commit *bus.BlockCommit) []*AccountDiff {
+	header, err := r.headers.GetByHash(commit.Hash)
+	if err != nil {
+		r.log.Debug("no header for block, skip diffs", "num", commit.Number, "err", err)
+		return nil
+	}
+	// The parent is unknown, e.g., at the
+	// checkpoint, diffs have no prior state
+	parent, _ := r.headers.GetByHash(header.ParentHash)
+
+	var diffs []*AccountDiff
+	for _, addr := range r.accounts() {
+		after, err := r.snapshots.Get(header, addr)
+		if err != nil {
+			if !errors.Is(err, ethstore.ErrSnapshotNotFound) {
+				r.log.Warn("failed to get snapshot", "account", addr.Hex(), "num", commit.Number, "err", err)
+			}
+			continue
+		}
+
+		before := r.snapshot(parent, addr)
+		if before != nil && isUnchanged(before, after) {
+			continue
+		}
+		diffs = append(diffs, &AccountDiff{
+			Address: addr,
+			Before:  before,
+			After:   after,
+		})
+	}
+	return diffs
+}
+
+// snapshot returns the snapshot of the specified
+// account at the specified block, or nil if the
+// snapshot is unknown.
+func (r *Runner) snapshot(header *types.Header, addr common.Address) *ethstore.AccountSnapshot {
+	if header == nil {
+		return nil
+	}
+
+	snapshot, err := r.snapshots.Get(header, addr)
+	if err != nil {
+		return nil
+	}
+	return snapshot
+}
+
+// isUnchanged checks whether the
+// specified snapshots are equal.
+func isUnchanged(a, b *ethstore.AccountSnapshot) bool {
+	return a.Nonce == b.Nonce &&
+		a.Balance.Cmp(b.Balance) == 0 &&
+		a.CodeHash == b.CodeHash &&
+		a.StorageRoot == b.StorageRoot
+}
@@ -0,0 +1,114 @@
+package hook
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math/big"
+	"sparseth/bus"
+	"sparseth/ethstore"
+	"sparseth/internal/log"
+	"sparseth/storage/mem"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+type testHook struct {
+	err    error
+	alerts []*bus.Alert
+	report *Report
+}
+
+func (h *testHook) Name() string {
+	return "test"
+}
+
+func (h *testHook) Check(_ context.Context, report *Report) ([]*bus.Alert, error) {
+	h.report = report
+	return h.alerts, h.err
+}
+
+func TestRunner_Check(t *testing.T) {
+	testLogger := log.New(slog.DiscardHandler)
+	addr := common.HexToAddress("0xdeadbeefdeadbeefdeadbeefdeadbeefdeadbeef")
+	other := common.HexToAddress("0xf39fd6e51aad88f6f4ce6ab8827279cfffb92266")
+
+	parent := &types.Header{Number: big.NewInt(1)}
+	header := &types.Header{Number: big.NewInt(2), ParentHash: parent.Hash()}
+	commit := &bus.BlockCommit{Number: 2, Hash: header.Hash()}
+
+	snapshot := func(addr common.Address, balance int64) *ethstore.AccountSnapshot {
+		return &ethstore.AccountSnapshot{
+			Address:     addr,
+			Balance:     big.NewInt(balance),
+			CodeHash:    types.EmptyCodeHash,
+			StorageRoot: types.EmptyRootHash,
+		}
+	}
+
+	t.Run("should report changed accounts only", func(t *testing.T) {
+		db := mem.New()
+		defer db.Close()
+
+		if err := ethstore.NewHeaderStore(db).PutAll([]*types.Header{parent, header}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		snapshots := ethstore.NewSnapshotStore(db, 8)
+		if err := snapshots.PutAll(parent, []*ethstore.AccountSnapshot{snapshot(addr, 1), snapshot(other, 1)}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if err := snapshots.PutAll(header, []*ethstore.AccountSnapshot{snapshot(addr, 2), snapshot(other, 1)}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		h := &testHook{}
+		accounts := func() []common.Address { return []common.Address{addr, other} }
+		runner := NewRunner([]Hook{h}, db, accounts, nil, testLogger)
+
+		if vetoes := runner.Check(commit); len(vetoes) != 0 {
+			t.Fatalf("expected no vetoes, got %d", len(vetoes))
+		}
+		if len(h.report.Diffs) != 1 {
+			t.Fatalf("expected 1 diff, got %d", len(h.report.Diffs))
+		}
+		diff := h.report.Diffs[0]
+		if diff.Address != addr || diff.Before.Balance.Int64() != 1 || diff.After.Balance.Int64() != 2 {
+			t.Errorf("expected balance change of %s, got %+v", addr.Hex(), diff)
+		}
+	})
+
+	t.Run("should veto block on error", func(t *testing.T) {
+		db := mem.New()
+		defer db.Close()
+
+		h := &testHook{err: errors.New("supply decreased")}
+		runner := NewRunner([]Hook{h}, db, func() []common.Address { return nil }, nil, testLogger)
+
+		vetoes := runner.Check(commit)
+		if len(vetoes) != 1 || vetoes[0].Verified() || vetoes[0].Hash != commit.Hash {
+			t.Errorf("expected 1 veto of block, got %+v", vetoes)
+		}
+	})
+
+	t.Run("should publish alerts", func(t *testing.T) {
+		db := mem.New()
+		defer db.Close()
+
+		alerts := bus.NewTopic[*bus.Alert]("alerts", testLogger)
+		sub := alerts.Subscribe("test")
+
+		h := &testHook{alerts: []*bus.Alert{{Account: addr, Message: "large transfer"}}}
+		runner := NewRunner([]Hook{h}, db, func() []common.Address { return nil }, alerts, testLogger)
+
+		runner.Check(commit)
+		if len(sub) != 1 {
+			t.Fatalf("expected 1 alert, got %d", len(sub))
+		}
+		alert := <-sub
+		if alert.Source != "hook/test" || alert.Number != commit.Number {
+			t.Errorf("expected alert of hook/test at block %d, got %+v", commit.Number, alert)
+		}
+	})
+}
@@ -0,0 +1,249 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sparseth/log"
+	"strconv"
+	"time"
+)
+
+const (
+	// sourcifyURL is the API of Sourcify.
+	sourcifyURL = "https://sourcify.dev/server"
+	// etherscanURL is the API of Etherscan.
+	etherscanURL = "https://api.etherscan.io/v2/api"
+	// resolveTimeout is the maximum duration
+	// of resolving a single ABI.
+	resolveTimeout = 30 * time.Second
+)
+
+var (
+	// errNotVerified is returned if a contract
+	// is not verified by an ABI source.
+	errNotVerified = errors.New("contract not verified")
+)
+
+// ABIResolver fetches the ABIs of verified contracts
+// from Sourcify, or from Etherscan if Sourcify has no
+// match and an Etherscan API key is set.
+//
+// Resolved ABIs are cached in a local directory, so
+// that each ABI is only fetched once.
+type ABIResolver struct {
+	chainID uint64
+	dir     string
+	// key is the Etherscan API key, Etherscan
+	// is not queried if empty.
+	key       string
+	sourcify  string
+	etherscan string
+	c         *http.Client
+	log       log.Logger
+}
+
+// NewABIResolver creates a new ABIResolver for the
+// network with the specified chain ID, caching ABIs
+// in the specified directory.
+func NewABIResolver(chainID uint64, dir string, etherscanKey string, log log.Logger) *ABIResolver {
+	return &ABIResolver{
+		chainID:   chainID,
+		dir:       dir,
+		key:       etherscanKey,
+		sourcify:  sourcifyURL,
+		etherscan: etherscanURL,
+		c:         &http.Client{Timeout: resolveTimeout},
+		log:       log.With("component", "abi-resolver"),
+	}
+}
+
+// Resolve returns the JSON ABI of the
+// contract at the specified address.
+func (r *ABIResolver) Resolve(addr common.Address) ([]byte, error) {
+	path := filepath.Join(r.dir, strconv.FormatUint(r.chainID, 10), addr.Hex()+".json")
+	if data, err := os.ReadFile(path); err == nil {
+		r.log.Debug("use cached ABI", "address", addr.Hex(), "path", path)
+		return data, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), resolveTimeout)
+	defer cancel()
+
+	data, err := r.fromSourcify(ctx, addr)
+	if errors.Is(err, errNotVerified) && r.key != "" {
+		r.log.Debug("no match on Sourcify, try Etherscan", "address", addr.Hex())
+		data, err = r.fromEtherscan(ctx, addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve ABI of %s: %w", addr.Hex(), err)
+	}
+	r.log.Info("resolved ABI", "address", addr.Hex())
+
+	if err = os.MkdirAll(filepath.Dir(path), 0o755); err == nil {
+		err = os.WriteFile(path, data, 0o644)
+	}
+	if err != nil {
+		// The ABI is only fetched again
+		// on the next start
+		r.log.Warn("failed to cache ABI", "address", addr.Hex(), "path", path, "err", err)
+	}
+	return data, nil
+}
+
+// ResolveImplementation returns the implementation
+// of the upgradeable proxy at the specified address,
+// as detected by Sourcify, or by Etherscan if Sourcify
+// has no match and an Etherscan API key is set.
+//
+// Note that the implementation is not verified, and
+// not cached, as it changes with every upgrade.
+func (r *ABIResolver) ResolveImplementation(proxy common.Address) (common.Address, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), resolveTimeout)
+	defer cancel()
+
+	impl, err := r.implFromSourcify(ctx, proxy)
+	if errors.Is(err, errNotVerified) && r.key != "" {
+		r.log.Debug("no proxy match on Sourcify, try Etherscan", "address", proxy.Hex())
+		impl, err = r.implFromEtherscan(ctx, proxy)
+	}
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to resolve implementation of %s: %w", proxy.Hex(), err)
+	}
+	r.log.Info("resolved proxy implementation", "address", proxy.Hex(), "implementation", impl.Hex())
+	return impl, nil
+}
+
+// implFromSourcify fetches the implementation of the
+// proxy at the specified address from Sourcify.
+func (r *ABIResolver) implFromSourcify(ctx context.Context, proxy common.Address) (common.Address, error) {
+	var res struct {
+		ProxyResolution *struct {
+			IsProxy         bool `json:"isProxy"`
+			Implementations []struct {
+				Address common.Address `json:"address"`
+			} `json:"implementations"`
+		} `json:"proxyResolution"`
+	}
+	status, err := r.get(ctx, fmt.Sprintf("%s/v2/contract/%d/%s?fields=proxyResolution", r.sourcify, r.chainID, proxy.Hex()), &res)
+	if status == http.StatusNotFound {
+		return common.Address{}, errNotVerified
+	}
+	if err != nil {
+		return common.Address{}, fmt.Errorf("sourcify: %w", err)
+	}
+	if res.ProxyResolution == nil || !res.ProxyResolution.IsProxy || len(res.ProxyResolution.Implementations) == 0 {
+		return common.Address{}, errNotVerified
+	}
+	return res.ProxyResolution.Implementations[0].Address, nil
+}
+
+// implFromEtherscan fetches the implementation of the
+// proxy at the specified address from Etherscan.
+func (r *ABIResolver) implFromEtherscan(ctx context.Context, proxy common.Address) (common.Address, error) {
+	query := url.Values{
+		"chainid": {strconv.FormatUint(r.chainID, 10)},
+		"module":  {"contract"},
+		"action":  {"getsourcecode"},
+		"address": {proxy.Hex()},
+		"apikey":  {r.key},
+	}
+
+	var res struct {
+		Status string          `json:"status"`
+		Result json.RawMessage `json:"result"`
+	}
+	if _, err := r.get(ctx, r.etherscan+"?"+query.Encode(), &res); err != nil {
+		return common.Address{}, fmt.Errorf("etherscan: %w", err)
+	}
+	var sources []struct {
+		Proxy          string `json:"Proxy"`
+		Implementation string `json:"Implementation"`
+	}
+	if res.Status != "1" || json.Unmarshal(res.Result, &sources) != nil {
+		return common.Address{}, fmt.Errorf("etherscan: %s", res.Result)
+	}
+	if len(sources) == 0 || sources[0].Proxy != "1" || !common.IsHexAddress(sources[0].Implementation) {
+		return common.Address{}, fmt.Errorf("etherscan: %w", errNotVerified)
+	}
+	return common.HexToAddress(sources[0].Implementation), nil
+}
+
+// fromSourcify fetches the ABI of the contract
+// at the specified address from Sourcify.
+func (r *ABIResolver) fromSourcify(ctx context.Context, addr common.Address) ([]byte, error) {
+	var res struct {
+		ABI json.RawMessage `json:"abi"`
+	}
+	status, err := r.get(ctx, fmt.Sprintf("%s/v2/contract/%d/%s?fields=abi", r.sourcify, r.chainID, addr.Hex()), &res)
+	if status == http.StatusNotFound {
+		return nil, errNotVerified
+	}
+	if err != nil {
+		return nil, fmt.Errorf("sourcify: %w", err)
+	}
+	if len(res.ABI) == 0 {
+		return nil, errNotVerified
+	}
+	return res.ABI, nil
+}
+
+// fromEtherscan fetches the ABI of the contract
+// at the specified address from Etherscan.
+func (r *ABIResolver) fromEtherscan(ctx context.Context, addr common.Address) ([]byte, error) {
+	query := url.Values{
+		"chainid": {strconv.FormatUint(r.chainID, 10)},
+		"module":  {"contract"},
+		"action":  {"getabi"},
+		"address": {addr.Hex()},
+		"apikey":  {r.key},
+	}
+
+	var res struct {
+		Status string `json:"status"`
+		Result string `json:"result"`
+	}
+	if _, err := r.get(ctx, r.etherscan+"?"+query.Encode(), &res); err != nil {
+		return nil, fmt.Errorf("etherscan: %w", err)
+	}
+	if res.Status != "1" {
+		return nil, fmt.Errorf("etherscan: %s", res.Result)
+	}
+	return []byte(res.Result), nil
+}
+
+// get fetches the specified URL and decodes the
+// JSON response into res. The status code is
+// returned if a response was received.
+func (r *ABIResolver) get(ctx context.Context, url string, res any) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := r.c.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return resp.StatusCode, fmt.Errorf("status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, fmt.Errorf("failed to read response: %w", err)
+	}
+	if err = json.Unmarshal(data, res); err != nil {
+		return resp.StatusCode, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return resp.StatusCode, nil
+}
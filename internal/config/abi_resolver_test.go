@@ -0,0 +1,129 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sparseth/internal/log"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const testABI = `[{"type":"event","name":"Transfer","inputs":[{"name":"value","type":"uint256","indexed":false}],"anonymous":false}]`
+
+func newTestResolver(t *testing.T, sourcify, etherscan http.HandlerFunc, key string) *ABIResolver {
+	r := NewABIResolver(1, t.TempDir(), key, log.New(slog.DiscardHandler))
+
+	srv := httptest.NewServer(sourcify)
+	t.Cleanup(srv.Close)
+	r.sourcify = srv.URL
+
+	if etherscan != nil {
+		srv := httptest.NewServer(etherscan)
+		t.Cleanup(srv.Close)
+		r.etherscan = srv.URL
+	}
+	return r
+}
+
+func TestABIResolver_Resolve(t *testing.T) {
+	addr := common.HexToAddress("0xdeadbeefdeadbeefdeadbeefdeadbeefdeadbeef")
+	notFound := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}
+
+	t.Run("should resolve and cache ABI from Sourcify", func(t *testing.T) {
+		calls := 0
+		r := newTestResolver(t, func(w http.ResponseWriter, req *http.Request) {
+			calls++
+			fmt.Fprintf(w, `{"abi":%s}`, testABI)
+		}, nil, "")
+
+		for range 2 {
+			data, err := r.Resolve(addr)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if _, err = parseABI(data); err != nil {
+				t.Fatalf("expected valid ABI, got %v", err)
+			}
+		}
+		if calls != 1 {
+			t.Errorf("expected 1 call, got %d", calls)
+		}
+		if _, err := os.Stat(filepath.Join(r.dir, "1", addr.Hex()+".json")); err != nil {
+			t.Errorf("expected cached ABI, got %v", err)
+		}
+	})
+
+	t.Run("should fall back to Etherscan", func(t *testing.T) {
+		r := newTestResolver(t, notFound, func(w http.ResponseWriter, req *http.Request) {
+			if req.URL.Query().Get("apikey") != "key" {
+				t.Errorf("expected API key, got %s", req.URL.Query().Get("apikey"))
+			}
+			fmt.Fprintf(w, `{"status":"1","message":"OK","result":%q}`, testABI)
+		}, "key")
+
+		if _, err := r.Resolve(addr); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("should return error if contract is not verified", func(t *testing.T) {
+		r := newTestResolver(t, notFound, nil, "")
+
+		if _, err := r.Resolve(addr); err == nil {
+			t.Errorf("expected error, got nil")
+		}
+	})
+}
+
+func TestABIResolver_ResolveImplementation(t *testing.T) {
+	proxy := common.HexToAddress("0xdeadbeefdeadbeefdeadbeefdeadbeefdeadbeef")
+	impl := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	notFound := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}
+
+	t.Run("should resolve implementation from Sourcify", func(t *testing.T) {
+		r := newTestResolver(t, func(w http.ResponseWriter, req *http.Request) {
+			fmt.Fprintf(w, `{"proxyResolution":{"isProxy":true,"proxyType":"EIP1967Proxy","implementations":[{"address":%q}]}}`, impl.Hex())
+		}, nil, "")
+
+		got, err := r.ResolveImplementation(proxy)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if got != impl {
+			t.Errorf("expected %s, got %s", impl.Hex(), got.Hex())
+		}
+	})
+
+	t.Run("should fall back to Etherscan", func(t *testing.T) {
+		r := newTestResolver(t, notFound, func(w http.ResponseWriter, req *http.Request) {
+			fmt.Fprintf(w, `{"status":"1","message":"OK","result":[{"Proxy":"1","Implementation":%q}]}`, impl.Hex())
+		}, "key")
+
+		got, err := r.ResolveImplementation(proxy)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if got != impl {
+			t.Errorf("expected %s, got %s", impl.Hex(), got.Hex())
+		}
+	})
+
+	t.Run("should return error if contract is no proxy", func(t *testing.T) {
+		r := newTestResolver(t, func(w http.ResponseWriter, req *http.Request) {
+			fmt.Fprint(w, `{"proxyResolution":{"isProxy":false,"implementations":[]}}`)
+		}, nil, "")
+
+		if _, err := r.ResolveImplementation(proxy); err == nil {
+			t.Errorf("expected error, got nil")
+		}
+	})
+}
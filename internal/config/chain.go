@@ -0,0 +1,54 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/params"
+	"os"
+)
+
+// rawChainConfig represents the raw JSON
+// structure of the chain config file.
+type rawChainConfig struct {
+	Config *params.ChainConfig `json:"config"`
+	// GenesisHash is optional, it is computed
+	// from the genesis block if omitted.
+	GenesisHash *common.Hash `json:"genesisHash"`
+}
+
+// LoadChainConfig reads the chain config file at
+// the specified path, and returns the chain config
+// and the genesis hash of the network.
+//
+// The file is either a geth genesis file, whose
+// genesis hash is computed, or contains a config
+// and a genesisHash field only.
+func LoadChainConfig(path string) (*params.ChainConfig, common.Hash, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, common.Hash{}, fmt.Errorf("failed to read chain config file: %w", err)
+	}
+
+	var raw rawChainConfig
+	if err = json.Unmarshal(data, &raw); err != nil {
+		return nil, common.Hash{}, fmt.Errorf("failed to parse chain config: %w", err)
+	}
+	if raw.Config == nil || raw.Config.ChainID == nil {
+		return nil, common.Hash{}, fmt.Errorf("chain config has no chain id")
+	}
+	if err = raw.Config.CheckConfigForkOrder(); err != nil {
+		return nil, common.Hash{}, fmt.Errorf("invalid chain config: %w", err)
+	}
+
+	if raw.GenesisHash != nil {
+		return raw.Config, *raw.GenesisHash, nil
+	}
+
+	var genesis core.Genesis
+	if err = json.Unmarshal(data, &genesis); err != nil {
+		return nil, common.Hash{}, fmt.Errorf("failed to parse genesis: %w", err)
+	}
+	return raw.Config, genesis.ToBlock().Hash(), nil
+}
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func writeChainConfig(t *testing.T, data string) string {
+	path := filepath.Join(t.TempDir(), "chain.json")
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatalf("failed to write chain config: %v", err)
+	}
+	return path
+}
+
+func TestLoadChainConfig(t *testing.T) {
+	t.Run("should load config and genesis hash", func(t *testing.T) {
+		path := writeChainConfig(t, `{"config":{"chainId":1337,"homesteadBlock":0},"genesisHash":"0x0000000000000000000000000000000000000000000000000000000000000001"}`)
+
+		cc, hash, err := LoadChainConfig(path)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if cc.ChainID.Uint64() != 1337 {
+			t.Errorf("expected chain id 1337, got %v", cc.ChainID)
+		}
+		if hash != common.HexToHash("0x01") {
+			t.Errorf("expected genesis hash 0x01, got %s", hash.Hex())
+		}
+	})
+
+	t.Run("should compute genesis hash of genesis file", func(t *testing.T) {
+		path := writeChainConfig(t, `{"config":{"chainId":1337},"gasLimit":"0x1c9c380","difficulty":"0x1","alloc":{}}`)
+
+		_, hash, err := LoadChainConfig(path)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		genesis := &core.Genesis{
+			Config:     &params.ChainConfig{ChainID: big.NewInt(1337)},
+			GasLimit:   0x1c9c380,
+			Difficulty: common.Big1,
+			Alloc:      types.GenesisAlloc{},
+		}
+		if expected := genesis.ToBlock().Hash(); hash != expected {
+			t.Errorf("expected genesis hash %s, got %s", expected.Hex(), hash.Hex())
+		}
+	})
+
+	t.Run("should return error if chain id is missing", func(t *testing.T) {
+		path := writeChainConfig(t, `{"config":{"homesteadBlock":0},"genesisHash":"0x01"}`)
+
+		if _, _, err := LoadChainConfig(path); err == nil {
+			t.Errorf("expected error, got nil")
+		}
+	})
+}
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"os"
+	"sparseth/config"
+)
+
+// rawCheckpoint represents the raw JSON
+// structure of the checkpoint file.
+type rawCheckpoint struct {
+	Hash      *common.Hash `json:"hash"`
+	Number    *uint64      `json:"number"`
+	StateRoot *common.Hash `json:"stateRoot"`
+	Timestamp *uint64      `json:"timestamp"`
+}
+
+// LoadCheckpoint reads the checkpoint file at the
+// specified path. Only the block hash is required,
+// all other fields are checked against the block
+// if present.
+func LoadCheckpoint(path string) (*config.Checkpoint, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint file: %w", err)
+	}
+
+	var raw rawCheckpoint
+	if err = json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse checkpoint: %w", err)
+	}
+	if raw.Hash == nil {
+		return nil, fmt.Errorf("checkpoint has no hash")
+	}
+
+	return &config.Checkpoint{
+		Hash:      *raw.Hash,
+		Number:    raw.Number,
+		StateRoot: raw.StateRoot,
+		Time:      raw.Timestamp,
+	}, nil
+}
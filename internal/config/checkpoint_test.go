@@ -0,0 +1,41 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestLoadCheckpoint(t *testing.T) {
+	t.Run("should load checkpoint", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "checkpoint.json")
+		data := `{"hash":"0x0000000000000000000000000000000000000000000000000000000000000001","number":10,"stateRoot":"0x00000000000000000000000000000000000000000000000000000000000000aa","timestamp":1000}`
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatalf("failed to write checkpoint: %v", err)
+		}
+
+		cp, err := LoadCheckpoint(path)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if cp.Hash != common.HexToHash("0x01") {
+			t.Errorf("expected hash 0x01, got %s", cp.Hash.Hex())
+		}
+		if cp.Number == nil || *cp.Number != 10 || cp.StateRoot == nil || *cp.StateRoot != common.HexToHash("0xaa") || cp.Time == nil || *cp.Time != 1000 {
+			t.Errorf("expected number 10, state root 0xaa, and timestamp 1000, got %+v", cp)
+		}
+	})
+
+	t.Run("should return error if hash is missing", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "checkpoint.json")
+		if err := os.WriteFile(path, []byte(`{"number":10}`), 0o644); err != nil {
+			t.Fatalf("failed to write checkpoint: %v", err)
+		}
+
+		if _, err := LoadCheckpoint(path); err == nil {
+			t.Errorf("expected error, got nil")
+		}
+	})
+}
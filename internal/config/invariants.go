@@ -0,0 +1,225 @@
+package config
+
+import (
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"gopkg.in/yaml.v3"
+	"math/big"
+	"os"
+	"regexp"
+	"sparseth/config"
+	"strings"
+)
+
+var (
+	// invariantPattern splits an invariant
+	// into its operands and comparison.
+	invariantPattern = regexp.MustCompile(`^(.+?)\s*(==|!=|<=|>=|<|>)\s*(.+)$`)
+	// accountOperandPattern matches the
+	// balance and nonce of an account.
+	accountOperandPattern = regexp.MustCompile(`^(balance|nonce)\(\s*(\S+?)\s*\)$`)
+	// slotOperandPattern matches a
+	// storage slot of an account.
+	slotOperandPattern = regexp.MustCompile(`^slot\(\s*(\S+?)\s*,\s*(\S+?)\s*\)$`)
+	// tokenBalancePattern matches the token
+	// balance of a holder of a template token.
+	tokenBalancePattern = regexp.MustCompile(`^token_balance\(\s*(\S+?)\s*,\s*(\S+?)\s*\)$`)
+	// totalSupplyPattern matches the total
+	// supply of a template token.
+	totalSupplyPattern = regexp.MustCompile(`^total_supply\(\s*(\S+?)\s*\)$`)
+)
+
+// rawInvariantsConfig represents the invariants
+// section of the config file, along with the
+// accounts the invariants may refer to.
+type rawInvariantsConfig struct {
+	Accounts   []*AccountEntry   `yaml:"accounts"`
+	Invariants []*InvariantEntry `yaml:"invariants"`
+}
+
+// InvariantEntry represents a raw invariant,
+// as found in the config file.
+type InvariantEntry struct {
+	Name string `yaml:"name"`
+	// Check is the predicate, e.g.,
+	// slot(0x..., 0x1) >= balance(0x...).
+	Check string `yaml:"check"`
+}
+
+// LoadInvariants reads the invariants section of
+// the config file at the specified path. Each
+// invariant must only refer to accounts listed
+// in the config file.
+func (l *Loader) LoadInvariants(path string) ([]*config.Invariant, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var raw rawInvariantsConfig
+	if err = yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+	if err = l.resolveNames(raw.Accounts); err != nil {
+		return nil, fmt.Errorf("failed to resolve config: %w", err)
+	}
+
+	monitored := make(map[common.Address]bool, len(raw.Accounts))
+	templates := make(map[common.Address]*config.Template)
+	for _, acc := range raw.Accounts {
+		addr := common.HexToAddress(acc.Address)
+		monitored[addr] = true
+		if t, ok := config.LookupTemplate(acc.Template); ok {
+			templates[addr] = t
+		}
+	}
+
+	names := make(map[string]bool, len(raw.Invariants))
+	invariants := make([]*config.Invariant, 0, len(raw.Invariants))
+	for idx, entry := range raw.Invariants {
+		if entry.Name == "" || entry.Check == "" {
+			return nil, fmt.Errorf("invariant at index %d: name and check are required", idx)
+		}
+		if names[entry.Name] {
+			return nil, fmt.Errorf("invariant %s: duplicate name", entry.Name)
+		}
+		names[entry.Name] = true
+
+		inv, err := l.parseInvariant(entry, templates)
+		if err != nil {
+			return nil, fmt.Errorf("invariant %s: %w", entry.Name, err)
+		}
+		for _, op := range []*config.Operand{inv.Left, inv.Right} {
+			if op.Kind != config.OperandConst && !monitored[op.Account] {
+				return nil, fmt.Errorf("invariant %s: account %s is not monitored", entry.Name, op.Account.Hex())
+			}
+		}
+		invariants = append(invariants, inv)
+	}
+
+	if len(invariants) > 0 {
+		l.log.Info("loaded invariants", "count", len(invariants))
+	}
+	return invariants, nil
+}
+
+// parseInvariant parses the specified invariant
+// entry, where token operands refer to accounts
+// with the specified templates.
+func (l *Loader) parseInvariant(entry *InvariantEntry, templates map[common.Address]*config.Template) (*config.Invariant, error) {
+	expr := strings.TrimSpace(entry.Check)
+	match := invariantPattern.FindStringSubmatch(expr)
+	if match == nil {
+		return nil, fmt.Errorf("invalid check %q, expected <operand> <comparison> <operand>", expr)
+	}
+
+	left, err := l.parseOperand(match[1], templates)
+	if err != nil {
+		return nil, err
+	}
+	right, err := l.parseOperand(match[3], templates)
+	if err != nil {
+		return nil, err
+	}
+	if left.Kind == config.OperandConst && right.Kind == config.OperandConst {
+		return nil, fmt.Errorf("invalid check %q, at least one operand must refer to an account", expr)
+	}
+
+	return &config.Invariant{
+		Name:  entry.Name,
+		Expr:  expr,
+		Left:  left,
+		Op:    config.Comparison(match[2]),
+		Right: right,
+	}, nil
+}
+
+// parseOperand parses a single operand, i.e., a
+// decimal or hex constant, balance(<addr>),
+// nonce(<addr>), or slot(<addr>, <slot>). The
+// token_balance(<token>, <holder>) and
+// total_supply(<token>) of tokens with the
+// specified templates are resolved to slots.
+func (l *Loader) parseOperand(s string, templates map[common.Address]*config.Template) (*config.Operand, error) {
+	s = strings.TrimSpace(s)
+
+	if match := tokenBalancePattern.FindStringSubmatch(s); match != nil {
+		t, err := l.tokenTemplate(match[1], templates)
+		if err != nil {
+			return nil, err
+		}
+		if err = config.ValidateAddress(match[2], l.validator.checksum); err != nil {
+			return nil, err
+		}
+		return &config.Operand{
+			Kind:    config.OperandSlot,
+			Account: common.HexToAddress(match[1]),
+			Slot:    t.BalanceSlot(common.HexToAddress(match[2])),
+		}, nil
+	}
+
+	if match := totalSupplyPattern.FindStringSubmatch(s); match != nil {
+		t, err := l.tokenTemplate(match[1], templates)
+		if err != nil {
+			return nil, err
+		}
+		if t.TotalSupplySlot == (common.Hash{}) {
+			return nil, fmt.Errorf("template %s does not store the total supply", t.Name)
+		}
+		return &config.Operand{
+			Kind:    config.OperandSlot,
+			Account: common.HexToAddress(match[1]),
+			Slot:    t.TotalSupplySlot,
+		}, nil
+	}
+
+	if match := accountOperandPattern.FindStringSubmatch(s); match != nil {
+		if err := config.ValidateAddress(match[2], l.validator.checksum); err != nil {
+			return nil, err
+		}
+		kind := config.OperandBalance
+		if match[1] == "nonce" {
+			kind = config.OperandNonce
+		}
+		return &config.Operand{
+			Kind:    kind,
+			Account: common.HexToAddress(match[2]),
+		}, nil
+	}
+
+	if match := slotOperandPattern.FindStringSubmatch(s); match != nil {
+		if err := config.ValidateAddress(match[1], l.validator.checksum); err != nil {
+			return nil, err
+		}
+		if err := isValidHexHash(match[2]); err != nil {
+			return nil, fmt.Errorf("invalid slot %q: %w", match[2], err)
+		}
+		return &config.Operand{
+			Kind:    config.OperandSlot,
+			Account: common.HexToAddress(match[1]),
+			Slot:    common.HexToHash(match[2]),
+		}, nil
+	}
+
+	value, ok := new(big.Int).SetString(s, 0)
+	if !ok || value.Sign() < 0 {
+		return nil, fmt.Errorf("invalid operand %q", s)
+	}
+	return &config.Operand{
+		Kind:  config.OperandConst,
+		Value: value,
+	}, nil
+}
+
+// tokenTemplate returns the template of
+// the specified token account.
+func (l *Loader) tokenTemplate(token string, templates map[common.Address]*config.Template) (*config.Template, error) {
+	if err := config.ValidateAddress(token, l.validator.checksum); err != nil {
+		return nil, err
+	}
+	t, ok := templates[common.HexToAddress(token)]
+	if !ok {
+		return nil, fmt.Errorf("account %s has no template", token)
+	}
+	return t, nil
+}
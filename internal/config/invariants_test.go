@@ -0,0 +1,114 @@
+package config
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sparseth/config"
+	"sparseth/internal/log"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestLoader_LoadInvariants(t *testing.T) {
+	testLogger := log.New(slog.DiscardHandler)
+
+	write := func(t *testing.T, content string) string {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write config: %v", err)
+		}
+		return path
+	}
+
+	const accounts = "accounts:\n  - address: \"0xdeadbeefdeadbeefdeadbeefdeadbeefdeadbeef\"\n"
+
+	t.Run("should parse slot and constant operands", func(t *testing.T) {
+		path := write(t, accounts+"invariants:\n  - name: solvent\n    check: \"slot(0xdeadbeefdeadbeefdeadbeefdeadbeefdeadbeef, 0x2) >= 1000\"\n")
+
+		invs, err := NewLoader(false, testLogger).LoadInvariants(path)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(invs) != 1 {
+			t.Fatalf("expected 1 invariant, got %d", len(invs))
+		}
+		inv := invs[0]
+		if inv.Name != "solvent" || inv.Op != config.GreaterOrEqual {
+			t.Errorf("expected solvent with >=, got %s with %s", inv.Name, inv.Op)
+		}
+		if inv.Left.Kind != config.OperandSlot || inv.Left.Slot != common.HexToHash("0x2") {
+			t.Errorf("expected slot 0x2, got %+v", inv.Left)
+		}
+		if inv.Right.Kind != config.OperandConst || inv.Right.Value.Int64() != 1000 {
+			t.Errorf("expected constant 1000, got %+v", inv.Right)
+		}
+	})
+
+	t.Run("should parse balance and nonce operands", func(t *testing.T) {
+		path := write(t, accounts+"invariants:\n  - name: funded\n    check: \"balance(0xdeadbeefdeadbeefdeadbeefdeadbeefdeadbeef) > nonce(0xdeadbeefdeadbeefdeadbeefdeadbeefdeadbeef)\"\n")
+
+		invs, err := NewLoader(false, testLogger).LoadInvariants(path)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if invs[0].Left.Kind != config.OperandBalance || invs[0].Right.Kind != config.OperandNonce {
+			t.Errorf("expected balance > nonce, got %+v", invs[0])
+		}
+	})
+
+	t.Run("should return no invariants if section is missing", func(t *testing.T) {
+		path := write(t, accounts)
+
+		invs, err := NewLoader(false, testLogger).LoadInvariants(path)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(invs) != 0 {
+			t.Errorf("expected no invariants, got %d", len(invs))
+		}
+	})
+
+	t.Run("should reject unmonitored account", func(t *testing.T) {
+		path := write(t, accounts+"invariants:\n  - name: funded\n    check: \"balance(0x0000000000000000000000000000000000000001) > 0\"\n")
+
+		if _, err := NewLoader(false, testLogger).LoadInvariants(path); err == nil {
+			t.Errorf("expected error, got nil")
+		}
+	})
+
+	t.Run("should reject invalid check", func(t *testing.T) {
+		for _, check := range []string{"1 < 2", "balance(0xdeadbeefdeadbeefdeadbeefdeadbeefdeadbeef)", "supply(0xdeadbeefdeadbeefdeadbeefdeadbeefdeadbeef) > 0"} {
+			path := write(t, accounts+"invariants:\n  - name: broken\n    check: \""+check+"\"\n")
+
+			if _, err := NewLoader(false, testLogger).LoadInvariants(path); err == nil {
+				t.Errorf("expected error for %q, got nil", check)
+			}
+		}
+	})
+
+	t.Run("should resolve token operands of template", func(t *testing.T) {
+		path := write(t, "accounts:\n  - address: \"0xdeadbeefdeadbeefdeadbeefdeadbeefdeadbeef\"\n    template: erc20\ninvariants:\n  - name: capped\n    check: \"token_balance(0xdeadbeefdeadbeefdeadbeefdeadbeefdeadbeef, 0x0000000000000000000000000000000000000001) <= total_supply(0xdeadbeefdeadbeefdeadbeefdeadbeefdeadbeef)\"\n")
+
+		invs, err := NewLoader(false, testLogger).LoadInvariants(path)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		erc20, _ := config.LookupTemplate("erc20")
+		if want := erc20.BalanceSlot(common.HexToAddress("0x1")); invs[0].Left.Slot != want {
+			t.Errorf("expected balance slot %s, got %s", want.Hex(), invs[0].Left.Slot.Hex())
+		}
+		if invs[0].Right.Slot != erc20.TotalSupplySlot {
+			t.Errorf("expected total supply slot %s, got %s", erc20.TotalSupplySlot.Hex(), invs[0].Right.Slot.Hex())
+		}
+	})
+
+	t.Run("should reject token operand without template", func(t *testing.T) {
+		path := write(t, accounts+"invariants:\n  - name: capped\n    check: \"total_supply(0xdeadbeefdeadbeefdeadbeefdeadbeefdeadbeef) > 0\"\n")
+
+		if _, err := NewLoader(false, testLogger).LoadInvariants(path); err == nil {
+			t.Errorf("expected error, got nil")
+		}
+	})
+}
@@ -0,0 +1,252 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"math/big"
+	"os"
+	"regexp"
+	"sparseth/config"
+	"strconv"
+	"strings"
+)
+
+var (
+	// variablePattern matches the name of a
+	// state variable at the start of a slot
+	// reference, e.g., balances.
+	variablePattern = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*`)
+	// accessorPattern matches a single mapping
+	// key or struct member of a slot reference,
+	// e.g., [0x...] or .total.
+	accessorPattern = regexp.MustCompile(`^(?:\[\s*([^\]]*?)\s*\]|\.([A-Za-z_$][A-Za-z0-9_$]*))`)
+)
+
+// storageLayout is the storage layout of a
+// contract, as emitted by solc with the
+// storageLayout output selection.
+type storageLayout struct {
+	Storage []*storageVariable      `json:"storage"`
+	Types   map[string]*storageType `json:"types"`
+}
+
+// storageVariable is a state variable
+// or a member of a struct.
+type storageVariable struct {
+	Label  string `json:"label"`
+	Offset int    `json:"offset"`
+	Slot   string `json:"slot"`
+	Type   string `json:"type"`
+}
+
+// storageType describes the encoding
+// of a type in storage.
+type storageType struct {
+	Encoding      string             `json:"encoding"`
+	Label         string             `json:"label"`
+	NumberOfBytes string             `json:"numberOfBytes"`
+	Key           string             `json:"key"`
+	Value         string             `json:"value"`
+	Members       []*storageVariable `json:"members"`
+}
+
+// loadStorageLayout reads the storage layout
+// JSON at the specified path. Both the layout
+// itself and a solc contract output holding it
+// in its storageLayout field are accepted.
+func loadStorageLayout(path string) (*storageLayout, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %s: %w", path, err)
+	}
+
+	var wrapped struct {
+		StorageLayout *storageLayout `json:"storageLayout"`
+	}
+	if err = json.Unmarshal(data, &wrapped); err != nil {
+		return nil, fmt.Errorf("failed to parse storage layout %s: %w", path, err)
+	}
+	if wrapped.StorageLayout != nil {
+		return wrapped.StorageLayout, nil
+	}
+
+	var layout storageLayout
+	if err = json.Unmarshal(data, &layout); err != nil {
+		return nil, fmt.Errorf("failed to parse storage layout %s: %w", path, err)
+	}
+	return &layout, nil
+}
+
+// resolve returns the slot of the variable referenced
+// by the specified expression, i.e., the name of a
+// state variable followed by any mapping keys and
+// struct members, e.g., allowances[0x...][0x...].
+// The referenced variable must occupy a full slot.
+func (l *storageLayout) resolve(expr string) (common.Hash, error) {
+	rest := strings.TrimSpace(expr)
+	name := variablePattern.FindString(rest)
+	if name == "" {
+		return common.Hash{}, fmt.Errorf("invalid slot reference %q", expr)
+	}
+	rest = rest[len(name):]
+
+	v := findVariable(l.Storage, name)
+	if v == nil {
+		return common.Hash{}, fmt.Errorf("variable %s not found in storage layout", name)
+	}
+	slot, ok := new(big.Int).SetString(v.Slot, 10)
+	if !ok {
+		return common.Hash{}, fmt.Errorf("invalid slot %q of variable %s", v.Slot, name)
+	}
+	offset, typ := v.Offset, v.Type
+
+	for rest != "" {
+		match := accessorPattern.FindStringSubmatch(rest)
+		if match == nil {
+			return common.Hash{}, fmt.Errorf("invalid slot reference %q at %q", expr, rest)
+		}
+		rest = strings.TrimSpace(rest[len(match[0]):])
+
+		t, ok := l.Types[typ]
+		if !ok {
+			return common.Hash{}, fmt.Errorf("type %s not found in storage layout", typ)
+		}
+
+		if match[2] != "" {
+			member := findVariable(t.Members, match[2])
+			if t.Encoding != "inplace" || member == nil {
+				return common.Hash{}, fmt.Errorf("member %s not found in type %s", match[2], t.Label)
+			}
+			memberSlot, ok := new(big.Int).SetString(member.Slot, 10)
+			if !ok {
+				return common.Hash{}, fmt.Errorf("invalid slot %q of member %s", member.Slot, match[2])
+			}
+			slot.Add(slot, memberSlot)
+			offset, typ = member.Offset, member.Type
+			continue
+		}
+
+		if t.Encoding != "mapping" {
+			return common.Hash{}, fmt.Errorf("cannot index type %s with key %s", t.Label, match[1])
+		}
+		keyType, ok := l.Types[t.Key]
+		if !ok {
+			return common.Hash{}, fmt.Errorf("type %s not found in storage layout", t.Key)
+		}
+		key, err := encodeMappingKey(keyType.Label, match[1])
+		if err != nil {
+			return common.Hash{}, fmt.Errorf("invalid key %s of %s: %w", match[1], t.Label, err)
+		}
+		slot = crypto.Keccak256Hash(key, common.BigToHash(slot).Bytes()).Big()
+		offset, typ = 0, t.Value
+	}
+
+	if t, ok := l.Types[typ]; !ok || offset != 0 || t.NumberOfBytes != "32" {
+		return common.Hash{}, fmt.Errorf("slot reference %q must refer to a variable occupying a full slot", expr)
+	}
+	if slot.BitLen() > 256 {
+		return common.Hash{}, fmt.Errorf("slot reference %q exceeds the storage", expr)
+	}
+	return common.BigToHash(slot), nil
+}
+
+// findVariable returns the variable with
+// the specified label, or nil if missing.
+func findVariable(vars []*storageVariable, label string) *storageVariable {
+	for _, v := range vars {
+		if v.Label == label {
+			return v
+		}
+	}
+	return nil
+}
+
+// encodeMappingKey encodes the specified key of
+// the specified Solidity type as hashed to locate
+// a mapping value. Value types are padded to 32
+// bytes, whereas strings and bytes are unpadded.
+func encodeMappingKey(typ string, key string) ([]byte, error) {
+	switch {
+	case typ == "address" || strings.HasPrefix(typ, "contract "):
+		if err := config.ValidateAddress(key, false); err != nil {
+			return nil, err
+		}
+		return common.LeftPadBytes(common.HexToAddress(key).Bytes(), common.HashLength), nil
+	case typ == "bool":
+		b, err := strconv.ParseBool(key)
+		if err != nil {
+			return nil, err
+		}
+		if b {
+			return common.LeftPadBytes([]byte{1}, common.HashLength), nil
+		}
+		return make([]byte, common.HashLength), nil
+	case typ == "string":
+		unquoted, err := strconv.Unquote(key)
+		if err != nil {
+			return nil, fmt.Errorf("string keys must be quoted")
+		}
+		return []byte(unquoted), nil
+	case typ == "bytes":
+		return hexutil.Decode(key)
+	case strings.HasPrefix(typ, "bytes"):
+		size, err := strconv.Atoi(strings.TrimPrefix(typ, "bytes"))
+		if err != nil {
+			return nil, fmt.Errorf("unsupported key type %s", typ)
+		}
+		b, err := hexutil.Decode(key)
+		if err != nil || len(b) > size {
+			return nil, fmt.Errorf("expected at most %d hex bytes", size)
+		}
+		return common.RightPadBytes(b, common.HashLength), nil
+	case strings.HasPrefix(typ, "uint") || strings.HasPrefix(typ, "int") || strings.HasPrefix(typ, "enum "):
+		n, ok := new(big.Int).SetString(key, 0)
+		if !ok {
+			return nil, fmt.Errorf("expected an integer")
+		}
+		if n.Sign() < 0 {
+			if strings.HasPrefix(typ, "uint") || strings.HasPrefix(typ, "enum ") {
+				return nil, fmt.Errorf("expected an unsigned integer")
+			}
+			// Two's complement
+			n.Add(n, new(big.Int).Lsh(common.Big1, 256))
+		}
+		if n.BitLen() > 256 {
+			return nil, fmt.Errorf("integer exceeds 256 bits")
+		}
+		return common.BigToHash(n).Bytes(), nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %s", typ)
+	}
+}
+
+// resolveLayoutSlots returns a copy of the specified
+// entry with the head and count slots referenced by
+// name resolved via its storage layout. Hex slots
+// and entries without storage layout are returned
+// unchanged.
+func resolveLayoutSlots(acc *AccountEntry) (*AccountEntry, error) {
+	if acc.StorageLayout == empty {
+		return acc, nil
+	}
+	layout, err := loadStorageLayout(acc.StorageLayout)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved := *acc
+	for _, slot := range []*string{&resolved.HeadSlot, &resolved.CountSlot} {
+		if *slot == empty || strings.HasPrefix(*slot, "0x") {
+			continue
+		}
+		hash, err := layout.resolve(*slot)
+		if err != nil {
+			return nil, err
+		}
+		*slot = hash.Hex()
+	}
+	return &resolved, nil
+}
@@ -0,0 +1,112 @@
+package config
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sparseth/internal/log"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const testStorageLayout = `{"storageLayout":{
+"storage":[
+{"label":"head","offset":0,"slot":"0","type":"t_bytes32"},
+{"label":"counter","offset":0,"slot":"1","type":"t_uint256"},
+{"label":"paused","offset":0,"slot":"2","type":"t_bool"},
+{"label":"allowances","offset":0,"slot":"3","type":"t_mapping(t_address,t_mapping(t_address,t_uint256))"},
+{"label":"state","offset":0,"slot":"4","type":"t_struct(State)"}
+],
+"types":{
+"t_address":{"encoding":"inplace","label":"address","numberOfBytes":"20"},
+"t_bool":{"encoding":"inplace","label":"bool","numberOfBytes":"1"},
+"t_bytes32":{"encoding":"inplace","label":"bytes32","numberOfBytes":"32"},
+"t_uint256":{"encoding":"inplace","label":"uint256","numberOfBytes":"32"},
+"t_mapping(t_address,t_uint256)":{"encoding":"mapping","key":"t_address","label":"mapping(address => uint256)","numberOfBytes":"32","value":"t_uint256"},
+"t_mapping(t_address,t_mapping(t_address,t_uint256))":{"encoding":"mapping","key":"t_address","label":"mapping(address => mapping(address => uint256))","numberOfBytes":"32","value":"t_mapping(t_address,t_uint256)"},
+"t_struct(State)":{"encoding":"inplace","label":"struct State","numberOfBytes":"64","members":[
+{"label":"flag","offset":0,"slot":"0","type":"t_bool"},
+{"label":"head","offset":0,"slot":"1","type":"t_bytes32"}
+]}
+}}}`
+
+func TestStorageLayout_Resolve(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "layout.json")
+	if err := os.WriteFile(path, []byte(testStorageLayout), 0o644); err != nil {
+		t.Fatalf("failed to write layout: %v", err)
+	}
+	layout, err := loadStorageLayout(path)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	t.Run("should resolve state variable", func(t *testing.T) {
+		slot, err := layout.resolve("counter")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if slot != common.HexToHash("0x1") {
+			t.Errorf("expected slot 0x1, got %s", slot.Hex())
+		}
+	})
+
+	t.Run("should resolve nested mapping", func(t *testing.T) {
+		owner, spender := common.HexToAddress("0xaa"), common.HexToAddress("0xbb")
+		outer := crypto.Keccak256Hash(common.LeftPadBytes(owner.Bytes(), 32), common.HexToHash("0x3").Bytes())
+		want := crypto.Keccak256Hash(common.LeftPadBytes(spender.Bytes(), 32), outer.Bytes())
+
+		slot, err := layout.resolve("allowances[" + owner.Hex() + "][" + spender.Hex() + "]")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if slot != want {
+			t.Errorf("expected slot %s, got %s", want.Hex(), slot.Hex())
+		}
+	})
+
+	t.Run("should resolve struct member", func(t *testing.T) {
+		slot, err := layout.resolve("state.head")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if slot != common.HexToHash("0x5") {
+			t.Errorf("expected slot 0x5, got %s", slot.Hex())
+		}
+	})
+
+	t.Run("should reject invalid references", func(t *testing.T) {
+		for _, expr := range []string{"missing", "paused", "allowances[0xaa]", "allowances[0xzz][0xbb]", "counter[1]", "state.missing"} {
+			if _, err := layout.resolve(expr); err == nil {
+				t.Errorf("expected error for %q, got nil", expr)
+			}
+		}
+	})
+}
+
+func TestLoader_Load_StorageLayout(t *testing.T) {
+	dir := t.TempDir()
+	layoutPath := filepath.Join(dir, "layout.json")
+	if err := os.WriteFile(layoutPath, []byte(testStorageLayout), 0o644); err != nil {
+		t.Fatalf("failed to write layout: %v", err)
+	}
+	abiPath := filepath.Join(dir, "abi.json")
+	if err := os.WriteFile(abiPath, []byte("[]"), 0o644); err != nil {
+		t.Fatalf("failed to write ABI: %v", err)
+	}
+	path := filepath.Join(dir, "config.yaml")
+	content := "accounts:\n  - address: \"0xdeadbeefdeadbeefdeadbeefdeadbeefdeadbeef\"\n    abi_path: \"" + abiPath + "\"\n    storage_layout: \"" + layoutPath + "\"\n    head_slot: \"state.head\"\n    count_slot: \"counter\"\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	accs, err := NewLoader(false, log.New(slog.DiscardHandler)).Load(path)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	cc := accs.Accounts[0].ContractConfig
+	if cc.Event.HeadSlot != common.HexToHash("0x5") || cc.State.CountSlot != common.HexToHash("0x1") {
+		t.Errorf("expected head slot 0x5 and count slot 0x1, got %s and %s", cc.Event.HeadSlot.Hex(), cc.State.CountSlot.Hex())
+	}
+}
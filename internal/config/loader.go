@@ -11,15 +11,57 @@ import (
 // rawConfig represents the raw YAML structure
 // of the config file.
 type rawConfig struct {
-	Accounts []*account `yaml:"accounts"`
+	Accounts []*AccountEntry `yaml:"accounts"`
 }
 
-// account represents a raw YAML account entry.
-type account struct {
-	Address   string `yaml:"address"`
-	ABI       string `yaml:"abi_path"`
-	HeadSlot  string `yaml:"head_slot"`
-	CountSlot string `yaml:"count_slot"`
+// AccountEntry represents a raw account entry,
+// as found in the config file.
+type AccountEntry struct {
+	Address   string `yaml:"address" json:"address"`
+	ABI       string `yaml:"abi_path" json:"abi_path"`
+	HeadSlot  string `yaml:"head_slot" json:"head_slot"`
+	CountSlot string `yaml:"count_slot" json:"count_slot"`
+	// Template is optional, and selects a built-in
+	// template that provides the ABI and default
+	// slots of a standard contract, e.g., erc20.
+	Template string `yaml:"template" json:"template"`
+	// Proxy is optional, and marks the account as
+	// an upgradeable proxy of the specified kind,
+	// i.e., eip1967.
+	Proxy string `yaml:"proxy" json:"proxy"`
+	// StorageLayout is optional, and is the path
+	// to the solc storage layout of the contract,
+	// so that slots can be referenced by name,
+	// e.g., head or balances[0x...].
+	StorageLayout string `yaml:"storage_layout" json:"storage_layout"`
+	// InitialHead is optional, the hash
+	// chain starts at zero if empty.
+	InitialHead string `yaml:"initial_head" json:"initial_head"`
+	// ABIFragments are optional ABIs merged
+	// into the ABI, e.g., of libraries.
+	ABIFragments []string `yaml:"abi_fragments" json:"abi_fragments"`
+	// Events is optional, all events of the
+	// merged ABI are part of the hash chain
+	// if empty.
+	Events []string `yaml:"events" json:"events"`
+	// Alerts is optional, no alerts are
+	// raised for the account if nil.
+	Alerts *AlertRulesEntry `yaml:"alerts" json:"alerts"`
+	// CallBudget is optional, the node-wide
+	// default budget applies if zero.
+	CallBudget uint64 `yaml:"call_budget" json:"call_budget"`
+	// StartBlock is optional, all blocks
+	// are processed if zero.
+	StartBlock uint64 `yaml:"start_block" json:"start_block"`
+}
+
+// AlertRulesEntry represents raw alert rules of
+// an account, as found in the config file. Values
+// are in wei, as decimal or hex strings.
+type AlertRulesEntry struct {
+	MinBalance  string `yaml:"min_balance" json:"min_balance"`
+	MaxOutgoing string `yaml:"max_outgoing" json:"max_outgoing"`
+	CodeChange  bool   `yaml:"code_change" json:"code_change"`
 }
 
 // Loader reads the main config file.
@@ -27,18 +69,30 @@ type Loader struct {
 	log       log.Logger
 	validator *validator
 	parser    *parser
+	// resolveName is optional, ENS
+	// names are rejected if nil.
+	resolveName NameResolver
 }
 
 // NewLoader creates a new config Loader with
-// the specified logging context attached.
-func NewLoader(log log.Logger) *Loader {
+// the specified logging context attached. If
+// checksum is set, addresses must be in EIP-55
+// checksummed form.
+func NewLoader(checksum bool, log log.Logger) *Loader {
 	return &Loader{
 		log:       log.With("component", "config-loader"),
-		validator: newValidator(log),
+		validator: newValidator(checksum, log),
 		parser:    newParser(log),
 	}
 }
 
+// SetABIResolver sets the resolver of the ABIs of
+// accounts with a head slot but no ABI path.
+func (l *Loader) SetABIResolver(r *ABIResolver) {
+	l.validator.resolveABIs = r != nil
+	l.parser.resolver = r
+}
+
 // Load reads the config file at the specified path.
 func (l *Loader) Load(path string) (*config.AccountsConfig, error) {
 	l.log.Info("load config from file", "path", path)
@@ -52,6 +106,9 @@ func (l *Loader) Load(path string) (*config.AccountsConfig, error) {
 	if err = yaml.Unmarshal(data, &raw); err != nil {
 		return nil, fmt.Errorf("failed to parse config: %w", err)
 	}
+	if err = l.resolveNames(raw.Accounts); err != nil {
+		return nil, fmt.Errorf("failed to resolve config: %w", err)
+	}
 
 	if err = l.validator.validate(raw); err != nil {
 		return nil, fmt.Errorf("failed to validate config: %w", err)
@@ -59,3 +116,16 @@ func (l *Loader) Load(path string) (*config.AccountsConfig, error) {
 
 	return l.parser.parse(raw)
 }
+
+// LoadAccount validates and parses a single
+// account entry.
+func (l *Loader) LoadAccount(entry *AccountEntry) (*config.AccountConfig, error) {
+	if err := l.resolveNames([]*AccountEntry{entry}); err != nil {
+		return nil, fmt.Errorf("failed to resolve account: %w", err)
+	}
+	if err := l.validator.validateAccount(entry); err != nil {
+		return nil, fmt.Errorf("failed to validate account: %w", err)
+	}
+
+	return l.parser.parseAccount(entry)
+}
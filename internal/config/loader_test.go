@@ -0,0 +1,210 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sparseth/config"
+	"sparseth/internal/log"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestLoader_Load(t *testing.T) {
+	testLogger := log.New(slog.DiscardHandler)
+
+	write := func(t *testing.T, content string) string {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write config: %v", err)
+		}
+		return path
+	}
+
+	t.Run("should parse start block", func(t *testing.T) {
+		path := write(t, "accounts:\n  - address: \"0xdeadbeefdeadbeefdeadbeefdeadbeefdeadbeef\"\n    start_block: 100\n")
+
+		accs, err := NewLoader(false, testLogger).Load(path)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		acc := accs.Accounts[0]
+		if acc.StartBlock != 100 {
+			t.Errorf("expected start block 100, got %d", acc.StartBlock)
+		}
+		if acc.StartedAt(99) || !acc.StartedAt(100) {
+			t.Errorf("expected account to start at block 100")
+		}
+	})
+
+	t.Run("should parse initial head", func(t *testing.T) {
+		abiPath := filepath.Join(t.TempDir(), "abi.json")
+		if err := os.WriteFile(abiPath, []byte("[]"), 0o644); err != nil {
+			t.Fatalf("failed to write ABI: %v", err)
+		}
+		path := write(t, "accounts:\n  - address: \"0xdeadbeefdeadbeefdeadbeefdeadbeefdeadbeef\"\n    abi_path: \""+abiPath+"\"\n    head_slot: \"0x0\"\n    initial_head: \"0x01\"\n")
+
+		accs, err := NewLoader(false, testLogger).Load(path)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if head := accs.Accounts[0].ContractConfig.Event.InitialHead; head != common.HexToHash("0x01") {
+			t.Errorf("expected initial head 0x01, got %s", head.Hex())
+		}
+	})
+
+	t.Run("should reject invalid initial head", func(t *testing.T) {
+		path := write(t, "accounts:\n  - address: \"0xdeadbeefdeadbeefdeadbeefdeadbeefdeadbeef\"\n    initial_head: \"0xzz\"\n")
+
+		if _, err := NewLoader(false, testLogger).Load(path); err == nil {
+			t.Errorf("expected error, got nil")
+		}
+	})
+
+	t.Run("should merge ABI fragments and select events", func(t *testing.T) {
+		dir := t.TempDir()
+		abiPath := filepath.Join(dir, "abi.json")
+		if err := os.WriteFile(abiPath, []byte(`[{"anonymous":false,"inputs":[{"indexed":false,"name":"value","type":"uint256"}],"name":"Ping","type":"event"}]`), 0o644); err != nil {
+			t.Fatalf("failed to write ABI: %v", err)
+		}
+		fragmentPath := filepath.Join(dir, "fragment.json")
+		if err := os.WriteFile(fragmentPath, []byte(`[{"anonymous":false,"inputs":[{"indexed":false,"name":"value","type":"bytes32"}],"name":"Ping","type":"event"},{"anonymous":true,"inputs":[{"indexed":true,"name":"key","type":"bytes32"}],"name":"Log","type":"event"},{"anonymous":false,"inputs":[],"name":"Unused","type":"event"}]`), 0o644); err != nil {
+			t.Fatalf("failed to write ABI: %v", err)
+		}
+		path := write(t, "accounts:\n  - address: \"0xdeadbeefdeadbeefdeadbeefdeadbeefdeadbeef\"\n    abi_path: \""+abiPath+"\"\n    abi_fragments: [\""+fragmentPath+"\"]\n    head_slot: \"0x0\"\n    events: [\"Ping\", \"Ping(bytes32)\", \"Log\"]\n")
+
+		accs, err := NewLoader(false, testLogger).Load(path)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		events := accs.Accounts[0].ContractConfig.Event.ABI.Events
+		if len(events) != 3 {
+			t.Fatalf("expected 3 events, got %d", len(events))
+		}
+		if events["Ping0"].Sig != "Ping(bytes32)" {
+			t.Errorf("expected renamed Ping(bytes32), got %s", events["Ping0"].Sig)
+		}
+		if !events["Log"].Anonymous {
+			t.Errorf("expected anonymous Log event")
+		}
+	})
+
+	t.Run("should reject unknown event", func(t *testing.T) {
+		abiPath := filepath.Join(t.TempDir(), "abi.json")
+		if err := os.WriteFile(abiPath, []byte("[]"), 0o644); err != nil {
+			t.Fatalf("failed to write ABI: %v", err)
+		}
+		path := write(t, "accounts:\n  - address: \"0xdeadbeefdeadbeefdeadbeefdeadbeefdeadbeef\"\n    abi_path: \""+abiPath+"\"\n    head_slot: \"0x0\"\n    events: [\"Missing\"]\n")
+
+		if _, err := NewLoader(false, testLogger).Load(path); err == nil {
+			t.Errorf("expected error, got nil")
+		}
+	})
+
+	t.Run("should reject conflicting start blocks", func(t *testing.T) {
+		path := write(t, "accounts:\n  - address: \"0xdeadbeefdeadbeefdeadbeefdeadbeefdeadbeef\"\n    start_block: 100\n  - address: \"0xDEADBEEFDEADBEEFDEADBEEFDEADBEEFDEADBEEF\"\n    start_block: 200\n")
+
+		if _, err := NewLoader(false, testLogger).Load(path); err == nil {
+			t.Errorf("expected error, got nil")
+		}
+	})
+
+	t.Run("should apply template", func(t *testing.T) {
+		path := write(t, "accounts:\n  - address: \"0xdeadbeefdeadbeefdeadbeefdeadbeefdeadbeef\"\n    template: erc20\n    count_slot: \"0x7\"\n")
+
+		accs, err := NewLoader(false, testLogger).Load(path)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		cc := accs.Accounts[0].ContractConfig
+		if cc.Event == nil || cc.Event.HeadSlot != common.HexToHash("0x0") {
+			t.Fatalf("expected event config with head slot 0x0, got %+v", cc.Event)
+		}
+		if _, ok := cc.Event.ABI.Events["Approval"]; !ok {
+			t.Errorf("expected Approval event in template ABI")
+		}
+		if cc.State == nil || cc.State.CountSlot != common.HexToHash("0x7") {
+			t.Errorf("expected overridden count slot 0x7, got %+v", cc.State)
+		}
+	})
+
+	t.Run("should reject unknown template", func(t *testing.T) {
+		path := write(t, "accounts:\n  - address: \"0xdeadbeefdeadbeefdeadbeefdeadbeefdeadbeef\"\n    template: erc1155\n")
+
+		if _, err := NewLoader(false, testLogger).Load(path); err == nil {
+			t.Errorf("expected error, got nil")
+		}
+	})
+
+	t.Run("should parse proxy", func(t *testing.T) {
+		path := write(t, "accounts:\n  - address: \"0xdeadbeefdeadbeefdeadbeefdeadbeefdeadbeef\"\n    proxy: eip1967\n")
+
+		accs, err := NewLoader(false, testLogger).Load(path)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		proxy := accs.Accounts[0].Proxy
+		if proxy == nil || proxy.ImplementationSlot != config.EIP1967ImplementationSlot {
+			t.Errorf("expected EIP-1967 proxy, got %+v", proxy)
+		}
+	})
+
+	t.Run("should reject unknown proxy kind", func(t *testing.T) {
+		path := write(t, "accounts:\n  - address: \"0xdeadbeefdeadbeefdeadbeefdeadbeefdeadbeef\"\n    proxy: eip1822\n")
+
+		if _, err := NewLoader(false, testLogger).Load(path); err == nil {
+			t.Errorf("expected error, got nil")
+		}
+	})
+
+	t.Run("should parse alert rules", func(t *testing.T) {
+		path := write(t, "accounts:\n  - address: \"0xdeadbeefdeadbeefdeadbeefdeadbeefdeadbeef\"\n    alerts:\n      min_balance: \"1000\"\n      max_outgoing: \"0x10\"\n      code_change: true\n")
+
+		accs, err := NewLoader(false, testLogger).Load(path)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		rules := accs.Accounts[0].Alerts
+		if rules == nil || rules.MinBalance.Int64() != 1000 || rules.MaxOutgoing.Int64() != 16 || !rules.CodeChange {
+			t.Errorf("expected parsed alert rules, got %+v", rules)
+		}
+	})
+
+	t.Run("should reject invalid alert threshold", func(t *testing.T) {
+		path := write(t, "accounts:\n  - address: \"0xdeadbeefdeadbeefdeadbeefdeadbeefdeadbeef\"\n    alerts:\n      min_balance: \"-1\"\n")
+
+		if _, err := NewLoader(false, testLogger).Load(path); err == nil {
+			t.Errorf("expected error, got nil")
+		}
+	})
+
+	t.Run("should resolve ENS name", func(t *testing.T) {
+		path := write(t, "accounts:\n  - address: vitalik.eth\n")
+		addr := common.HexToAddress("0xd8dA6BF26964aF9D7eEd9e10e5d6b5e4eF06E5A8")
+
+		loader := NewLoader(true, testLogger)
+		loader.SetNameResolver(func(name string) (common.Address, error) {
+			if name != "vitalik.eth" {
+				return common.Address{}, fmt.Errorf("unknown name %s", name)
+			}
+			return addr, nil
+		})
+		accs, err := loader.Load(path)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if accs.Accounts[0].Addr != addr {
+			t.Errorf("expected %s, got %s", addr.Hex(), accs.Accounts[0].Addr.Hex())
+		}
+	})
+
+	t.Run("should reject ENS name without resolver", func(t *testing.T) {
+		path := write(t, "accounts:\n  - address: vitalik.eth\n")
+
+		if _, err := NewLoader(false, testLogger).Load(path); err == nil {
+			t.Errorf("expected error, got nil")
+		}
+	})
+}
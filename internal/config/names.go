@@ -0,0 +1,46 @@
+package config
+
+import (
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"strings"
+)
+
+// NameResolver resolves an ENS
+// name, e.g., vitalik.eth, to
+// a verified address.
+type NameResolver func(name string) (common.Address, error)
+
+// SetNameResolver sets the resolver of accounts
+// whose address is an ENS name.
+func (l *Loader) SetNameResolver(r NameResolver) {
+	l.resolveName = r
+}
+
+// isName checks whether the specified
+// address is an ENS name.
+func isName(addr string) bool {
+	return !strings.HasPrefix(addr, "0x") && strings.Contains(addr, ".")
+}
+
+// resolveNames replaces the ENS names among the
+// addresses of the specified entries with the
+// resolved checksummed addresses.
+func (l *Loader) resolveNames(entries []*AccountEntry) error {
+	for idx, entry := range entries {
+		if entry == nil || !isName(entry.Address) {
+			continue
+		}
+		if l.resolveName == nil {
+			return fmt.Errorf("account at index %d: ENS name %s cannot be resolved", idx, entry.Address)
+		}
+
+		addr, err := l.resolveName(entry.Address)
+		if err != nil {
+			return fmt.Errorf("account at index %d: failed to resolve %s: %w", idx, entry.Address, err)
+		}
+		l.log.Info("resolved ENS name", "name", entry.Address, "address", addr.Hex())
+		entry.Address = addr.Hex()
+	}
+	return nil
+}
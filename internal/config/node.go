@@ -0,0 +1,63 @@
+package config
+
+import (
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"os"
+	"strings"
+)
+
+// rawNodeConfig represents the node
+// section of the config file.
+type rawNodeConfig struct {
+	Node map[string]yaml.Node `yaml:"node"`
+}
+
+// LoadNodeOptions reads the node section of the
+// config file at the specified path, and returns
+// its options keyed by command-line flag name,
+// e.g., event_mode is returned as event-mode.
+//
+// Lists of scalars are joined with commas, so
+// that they match the format of the flags.
+func LoadNodeOptions(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var raw rawNodeConfig
+	if err = yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+
+	opts := make(map[string]string, len(raw.Node))
+	for key, node := range raw.Node {
+		val, err := nodeOptionValue(&node)
+		if err != nil {
+			return nil, fmt.Errorf("invalid node option %s: %w", key, err)
+		}
+		opts[strings.ReplaceAll(key, "_", "-")] = val
+	}
+	return opts, nil
+}
+
+// nodeOptionValue converts the specified
+// YAML node to a flag value.
+func nodeOptionValue(node *yaml.Node) (string, error) {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		return node.Value, nil
+	case yaml.SequenceNode:
+		vals := make([]string, 0, len(node.Content))
+		for _, item := range node.Content {
+			if item.Kind != yaml.ScalarNode {
+				return "", fmt.Errorf("expected list of scalars")
+			}
+			vals = append(vals, item.Value)
+		}
+		return strings.Join(vals, ","), nil
+	default:
+		return "", fmt.Errorf("expected scalar or list of scalars")
+	}
+}
@@ -0,0 +1,57 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadNodeOptions(t *testing.T) {
+	t.Run("should load node options", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		data := "node:\n  rpc:\n    - ws://a\n    - ws://b\n  network: sepolia\n  event_mode: true\naccounts: []\n"
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatalf("failed to write config: %v", err)
+		}
+
+		opts, err := LoadNodeOptions(path)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if opts["rpc"] != "ws://a,ws://b" {
+			t.Errorf("expected ws://a,ws://b, got %s", opts["rpc"])
+		}
+		if opts["network"] != "sepolia" {
+			t.Errorf("expected sepolia, got %s", opts["network"])
+		}
+		if opts["event-mode"] != "true" {
+			t.Errorf("expected true, got %s", opts["event-mode"])
+		}
+	})
+
+	t.Run("should return no options without node section", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		if err := os.WriteFile(path, []byte("accounts: []\n"), 0o644); err != nil {
+			t.Fatalf("failed to write config: %v", err)
+		}
+
+		opts, err := LoadNodeOptions(path)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(opts) != 0 {
+			t.Errorf("expected no options, got %v", opts)
+		}
+	})
+
+	t.Run("should return error on nested option", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		if err := os.WriteFile(path, []byte("node:\n  rpc:\n    url: ws://a\n"), 0o644); err != nil {
+			t.Fatalf("failed to write config: %v", err)
+		}
+
+		if _, err := LoadNodeOptions(path); err == nil {
+			t.Errorf("expected error, got nil")
+		}
+	})
+}
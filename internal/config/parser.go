@@ -4,7 +4,10 @@ import (
 	"fmt"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
+	"maps"
+	"math/big"
 	"os"
+	"slices"
 	"sparseth/config"
 	"sparseth/log"
 	"strings"
@@ -14,10 +17,16 @@ import (
 // represent the empty string.
 var empty = ""
 
+// proxyEIP1967 selects EIP-1967 proxies.
+const proxyEIP1967 = "eip1967"
+
 // parser handles the conversion of raw config
 // data into structured AccountsConfig data.
 type parser struct {
-	log log.Logger
+	// resolver resolves the ABIs of accounts
+	// without an ABI path, if set.
+	resolver *ABIResolver
+	log      log.Logger
 }
 
 // newParser creates a new parser
@@ -46,13 +55,24 @@ func (p *parser) parse(raw *rawConfig) (*config.AccountsConfig, error) {
 }
 
 // parseAccount parses a single account.
-func (p *parser) parseAccount(acc *account) (*config.AccountConfig, error) {
+func (p *parser) parseAccount(acc *AccountEntry) (*config.AccountConfig, error) {
 	p.log.Debug("parse account", "address", acc.Address)
+	resolved, err := resolveLayoutSlots(applyTemplate(acc))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve slots of account %s: %w", acc.Address, err)
+	}
+	acc = resolved
 
 	addr := common.HexToAddress(acc.Address)
 
+	p.log.Debug("parse proxy config", "address", addr.Hex())
+	proxyConfig, err := p.parseProxyConfig(acc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse proxy config: %w", err)
+	}
+
 	p.log.Debug("parse event config", "address", addr.Hex())
-	eventConfig, err := p.parseEventConfig(acc)
+	eventConfig, err := p.parseEventConfig(acc, proxyConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse event config: %w", err)
 	}
@@ -69,37 +89,114 @@ func (p *parser) parseAccount(acc *account) (*config.AccountConfig, error) {
 			Event: eventConfig,
 			State: sparseConfig,
 		},
+		Proxy:      proxyConfig,
+		Alerts:     parseAlertRules(acc.Alerts),
+		CallBudget: acc.CallBudget,
+		StartBlock: acc.StartBlock,
 	}, nil
 }
 
 // parseEventConfig parses the event configuration
-// for the specified account. Note that if no ABI
-// is specified, and no head slot is found, this
-// is no error and the returned EventConfig is nil.
-func (p *parser) parseEventConfig(acc *account) (*config.EventConfig, error) {
+// for the specified account, which may be a proxy.
+// Note that if no ABI is specified, and no head
+// slot is found, this is no error and the returned
+// EventConfig is nil.
+func (p *parser) parseEventConfig(acc *AccountEntry, proxy *config.ProxyConfig) (*config.EventConfig, error) {
 	if acc.ABI == empty && acc.HeadSlot == empty {
 		p.log.Debug("no event config found for account", "address", acc.Address)
 		return nil, nil
 	}
 
 	head := common.HexToHash(acc.HeadSlot)
-	contractAbi, err := p.parseABI(acc.ABI)
+	contractAbi, err := p.loadABI(acc, proxy)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse ABI for account %s: %w", acc.Address, err)
 	}
 
+	for _, path := range acc.ABIFragments {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read file %s: %w", path, err)
+		}
+		fragment, err := parseABI(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse ABI fragment %s for account %s: %w", path, acc.Address, err)
+		}
+		mergeEvents(&contractAbi, fragment)
+	}
+
+	if len(acc.Events) > 0 {
+		if contractAbi, err = selectEvents(contractAbi, acc.Events); err != nil {
+			return nil, fmt.Errorf("failed to select events for account %s: %w", acc.Address, err)
+		}
+	}
+
 	return &config.EventConfig{
-		ABI:      contractAbi,
-		HeadSlot: head,
+		ABI:         contractAbi,
+		HeadSlot:    head,
+		InitialHead: common.HexToHash(acc.InitialHead),
 	}, nil
 }
 
+// parseProxyConfig parses the proxy configuration
+// for the specified account. If the ABI of a proxy
+// is resolved, so is its current implementation.
+// Note that if the account is no proxy, this is
+// no error and the returned ProxyConfig is nil.
+func (p *parser) parseProxyConfig(acc *AccountEntry) (*config.ProxyConfig, error) {
+	if acc.Proxy == empty {
+		return nil, nil
+	}
+
+	proxy := &config.ProxyConfig{
+		ImplementationSlot: config.EIP1967ImplementationSlot,
+	}
+	if acc.ABI == empty && acc.Template == empty && acc.HeadSlot != empty && p.resolver != nil {
+		impl, err := p.resolver.ResolveImplementation(common.HexToAddress(acc.Address))
+		if err != nil {
+			return nil, err
+		}
+		proxy.Implementation = impl
+	}
+	return proxy, nil
+}
+
+// parseAlertRules parses the specified alert
+// rules, which must be valid. Note that if no
+// rules are specified, the result is nil.
+func parseAlertRules(entry *AlertRulesEntry) *config.AlertRules {
+	if entry == nil {
+		return nil
+	}
+
+	rules := &config.AlertRules{
+		CodeChange: entry.CodeChange,
+	}
+	if entry.MinBalance != empty {
+		rules.MinBalance, _ = parseWei(entry.MinBalance)
+	}
+	if entry.MaxOutgoing != empty {
+		rules.MaxOutgoing, _ = parseWei(entry.MaxOutgoing)
+	}
+	return rules
+}
+
+// parseWei parses the specified non-negative
+// amount in wei, as decimal or hex string.
+func parseWei(s string) (*big.Int, error) {
+	val, ok := new(big.Int).SetString(s, 0)
+	if !ok || val.Sign() < 0 {
+		return nil, fmt.Errorf("invalid amount: %s", s)
+	}
+	return val, nil
+}
+
 // parseSparseConfig parses the contract
 // configuration for the specified account.
 // Note that if no count slot is found, this
 // is no error and the returned SparseConfig
 // is nil.
-func (p *parser) parseSparseConfig(acc *account) (*config.SparseConfig, error) {
+func (p *parser) parseSparseConfig(acc *AccountEntry) (*config.SparseConfig, error) {
 	if acc.CountSlot == empty {
 		p.log.Debug("no sparse contract config found for account", "address", acc.Address)
 		return nil, nil
@@ -110,14 +207,37 @@ func (p *parser) parseSparseConfig(acc *account) (*config.SparseConfig, error) {
 	}, nil
 }
 
-// parseABI reads the ABI file and parses
-// it into an Ethereum ABI structure.
-func (p *parser) parseABI(path string) (abi.ABI, error) {
-	data, err := os.ReadFile(path)
+// loadABI loads the ABI of the specified account,
+// either from its template, from its ABI file or,
+// if no ABI file is specified, via the ABI
+// resolver. The ABI of a proxy is the ABI of its
+// implementation.
+func (p *parser) loadABI(acc *AccountEntry, proxy *config.ProxyConfig) (abi.ABI, error) {
+	if t, ok := config.LookupTemplate(acc.Template); ok {
+		return parseABI([]byte(t.ABI))
+	}
+	if acc.ABI == empty && p.resolver != nil {
+		addr := common.HexToAddress(acc.Address)
+		if proxy != nil && proxy.Implementation != (common.Address{}) {
+			addr = proxy.Implementation
+		}
+		data, err := p.resolver.Resolve(addr)
+		if err != nil {
+			return abi.ABI{}, err
+		}
+		return parseABI(data)
+	}
+
+	data, err := os.ReadFile(acc.ABI)
 	if err != nil {
-		return abi.ABI{}, fmt.Errorf("failed to read file %s: %w", path, err)
+		return abi.ABI{}, fmt.Errorf("failed to read file %s: %w", acc.ABI, err)
 	}
+	return parseABI(data)
+}
 
+// parseABI parses the specified JSON ABI
+// into an Ethereum ABI structure.
+func parseABI(data []byte) (abi.ABI, error) {
 	parsed, err := abi.JSON(strings.NewReader(string(data)))
 	if err != nil {
 		return abi.ABI{}, fmt.Errorf("failed to parse ABI: %w", err)
@@ -125,3 +245,54 @@ func (p *parser) parseABI(path string) (abi.ABI, error) {
 
 	return parsed, nil
 }
+
+// mergeEvents adds all events of the specified
+// fragment to the specified ABI. Events already
+// defined are skipped, and events whose name is
+// taken are renamed, as for overloaded events.
+func mergeEvents(dst *abi.ABI, fragment abi.ABI) {
+	if dst.Events == nil {
+		dst.Events = make(map[string]abi.Event)
+	}
+
+	defined := make(map[common.Hash]struct{}, len(dst.Events))
+	for _, event := range dst.Events {
+		defined[event.ID] = struct{}{}
+	}
+
+	// Renaming must not depend on map order
+	for _, name := range slices.Sorted(maps.Keys(fragment.Events)) {
+		event := fragment.Events[name]
+		if _, ok := defined[event.ID]; ok {
+			continue
+		}
+		defined[event.ID] = struct{}{}
+
+		name = abi.ResolveNameConflict(event.RawName, func(s string) bool {
+			_, ok := dst.Events[s]
+			return ok
+		})
+		dst.Events[name] = abi.NewEvent(name, event.RawName, event.Anonymous, event.Inputs)
+	}
+}
+
+// selectEvents returns an ABI with only the specified
+// events of the specified ABI. Events are specified
+// by name, e.g., Transfer, or by signature, e.g.,
+// Transfer(address,address,uint256).
+func selectEvents(contractAbi abi.ABI, events []string) (abi.ABI, error) {
+	selected := abi.ABI{Events: make(map[string]abi.Event, len(events))}
+	for _, want := range events {
+		found := false
+		for name, event := range contractAbi.Events {
+			if name == want || event.Sig == want {
+				selected.Events[name] = event
+				found = true
+			}
+		}
+		if !found {
+			return abi.ABI{}, fmt.Errorf("event %s not found in ABI", want)
+		}
+	}
+	return selected, nil
+}
@@ -0,0 +1,26 @@
+package config
+
+import (
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"sparseth/config"
+)
+
+// applyTemplate returns a copy of the specified
+// entry with the slots of its template applied,
+// unless specified explicitly. Entries without
+// a known template are returned unchanged.
+func applyTemplate(acc *AccountEntry) *AccountEntry {
+	t, ok := config.LookupTemplate(acc.Template)
+	if !ok {
+		return acc
+	}
+
+	applied := *acc
+	if applied.HeadSlot == empty {
+		applied.HeadSlot = hexutil.EncodeBig(t.HeadSlot.Big())
+	}
+	if applied.CountSlot == empty {
+		applied.CountSlot = hexutil.EncodeBig(t.CountSlot.Big())
+	}
+	return &applied
+}
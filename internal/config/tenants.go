@@ -0,0 +1,73 @@
+package config
+
+import (
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"gopkg.in/yaml.v3"
+	"os"
+	"sparseth/config"
+)
+
+// rawTenantsConfig represents the raw YAML
+// structure of the API keys file.
+type rawTenantsConfig struct {
+	Tenants []*TenantEntry `yaml:"tenants"`
+}
+
+// TenantEntry represents a raw tenant entry,
+// as found in the API keys file.
+type TenantEntry struct {
+	Name string `yaml:"name"`
+	Key  string `yaml:"key"`
+	// Accounts is optional, all accounts are
+	// visible to the tenant if omitted.
+	Accounts []string `yaml:"accounts"`
+}
+
+// LoadTenants reads the API keys file at the
+// specified path. Each key must be unique.
+func (l *Loader) LoadTenants(path string) ([]*config.Tenant, error) {
+	l.log.Info("load API keys from file", "path", path)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read API keys file: %w", err)
+	}
+
+	var raw *rawTenantsConfig
+	if err = yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse API keys: %w", err)
+	}
+	if raw == nil || len(raw.Tenants) == 0 {
+		return nil, fmt.Errorf("no tenants defined")
+	}
+
+	keys := make(map[string]bool, len(raw.Tenants))
+	tenants := make([]*config.Tenant, 0, len(raw.Tenants))
+	for idx, entry := range raw.Tenants {
+		if entry.Name == "" || entry.Key == "" {
+			return nil, fmt.Errorf("tenant at index %d: name and key are required", idx)
+		}
+		if keys[entry.Key] {
+			return nil, fmt.Errorf("tenant %s: duplicate key", entry.Name)
+		}
+		keys[entry.Key] = true
+
+		tenant := &config.Tenant{
+			Name: entry.Name,
+			Key:  entry.Key,
+		}
+		if entry.Accounts != nil {
+			tenant.Accounts = make([]common.Address, 0, len(entry.Accounts))
+			for _, hex := range entry.Accounts {
+				if err = config.ValidateAddress(hex, l.validator.checksum); err != nil {
+					return nil, fmt.Errorf("tenant %s: %w", entry.Name, err)
+				}
+				tenant.Accounts = append(tenant.Accounts, common.HexToAddress(hex))
+			}
+		}
+		tenants = append(tenants, tenant)
+	}
+
+	return tenants, nil
+}
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sparseth/internal/log"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func writeTenants(t *testing.T, data string) string {
+	path := filepath.Join(t.TempDir(), "keys.yaml")
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatalf("failed to write API keys: %v", err)
+	}
+	return path
+}
+
+func TestLoader_LoadTenants(t *testing.T) {
+	loader := NewLoader(true, log.New(slog.DiscardHandler))
+	addr := common.HexToAddress("0xdeadbeefdeadbeefdeadbeefdeadbeefdeadbeef")
+
+	t.Run("should load scoped and unscoped tenants", func(t *testing.T) {
+		path := writeTenants(t, "tenants:\n  - name: ops\n    key: a\n  - name: team\n    key: b\n    accounts:\n      - "+addr.Hex()+"\n  - name: none\n    key: c\n    accounts: []\n")
+
+		tenants, err := loader.LoadTenants(path)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(tenants) != 3 {
+			t.Fatalf("expected 3 tenants, got %d", len(tenants))
+		}
+		if tenants[0].Scoped() {
+			t.Errorf("expected ops to see all accounts")
+		}
+		if !tenants[1].Scoped() || !tenants[1].Allows(addr) {
+			t.Errorf("expected team to see only %s", addr.Hex())
+		}
+		if !tenants[2].Scoped() || tenants[2].Allows(addr) {
+			t.Errorf("expected none to see no accounts")
+		}
+	})
+
+	t.Run("should return error for duplicate keys", func(t *testing.T) {
+		path := writeTenants(t, "tenants:\n  - name: a\n    key: k\n  - name: b\n    key: k\n")
+
+		if _, err := loader.LoadTenants(path); err == nil {
+			t.Errorf("expected error, got nil")
+		}
+	})
+
+	t.Run("should return error for invalid address", func(t *testing.T) {
+		path := writeTenants(t, "tenants:\n  - name: a\n    key: k\n    accounts:\n      - 0x1234\n")
+
+		if _, err := loader.LoadTenants(path); err == nil {
+			t.Errorf("expected error, got nil")
+		}
+	})
+}
@@ -1,64 +1,132 @@
 package config
 
 import (
+	"encoding/hex"
 	"fmt"
 	"github.com/ethereum/go-ethereum/common"
+	"sparseth/config"
 	"sparseth/log"
-	"strconv"
 	"strings"
 )
 
 // validator validates monitoring configs.
 type validator struct {
-	log log.Logger
+	// checksum indicates whether addresses
+	// must be in EIP-55 checksummed form.
+	checksum bool
+	// resolveABIs indicates whether the ABIs of
+	// accounts without an ABI path are resolved.
+	resolveABIs bool
+	log         log.Logger
 }
 
 // newValidator creates a new validator
 // with the specified logger.
-func newValidator(log log.Logger) *validator {
+func newValidator(checksum bool, log log.Logger) *validator {
 	return &validator{
-		log: log.With("component", "config-validator"),
+		checksum: checksum,
+		log:      log.With("component", "config-validator"),
 	}
 }
 
 // validate validates the raw config.
 func (v *validator) validate(raw *rawConfig) error {
+	starts := make(map[common.Address]uint64)
 	for idx, acc := range raw.Accounts {
 		v.log.Debug("validate account", "address", acc.Address, "index", idx)
 		if err := v.validateAccount(acc); err != nil {
 			return fmt.Errorf("failed to validate account at index %d: %w", idx, err)
 		}
+
+		addr := common.HexToAddress(acc.Address)
+		if start, exists := starts[addr]; exists && start != acc.StartBlock {
+			v.log.Error("account listed with conflicting start blocks", "address", acc.Address)
+			return fmt.Errorf("account %s listed with conflicting start blocks %d and %d", acc.Address, start, acc.StartBlock)
+		}
+		starts[addr] = acc.StartBlock
 	}
 	return nil
 }
 
 // validateAccount validates a single account config.
-func (v *validator) validateAccount(acc *account) error {
+func (v *validator) validateAccount(acc *AccountEntry) error {
 	if acc.Address == "" {
 		v.log.Error("address must not be empty")
 		return fmt.Errorf("address is empty")
 	}
 
-	if !common.IsHexAddress(acc.Address) {
-		v.log.Error("address must be a valid hex address", "address", acc.Address)
-		return fmt.Errorf("invalid address: %s", acc.Address)
+	if err := config.ValidateAddress(acc.Address, v.checksum); err != nil {
+		v.log.Error("address must be a valid hex address", "address", acc.Address, "err", err)
+		return err
+	}
+
+	if acc.Template != empty {
+		if _, ok := config.LookupTemplate(acc.Template); !ok {
+			v.log.Error("unknown template", "template", acc.Template)
+			return fmt.Errorf("unknown template %s for account %s", acc.Template, acc.Address)
+		}
+		if acc.ABI != empty {
+			v.log.Error("template and ABI must not both be specified", "address", acc.Address)
+			return fmt.Errorf("invalid event config for account %s: both template and ABI specified", acc.Address)
+		}
+		acc = applyTemplate(acc)
+	}
+
+	if acc.Proxy != empty && acc.Proxy != proxyEIP1967 {
+		v.log.Error("unknown proxy kind", "proxy", acc.Proxy)
+		return fmt.Errorf("unknown proxy kind %s for account %s", acc.Proxy, acc.Address)
+	}
+
+	resolved, err := resolveLayoutSlots(acc)
+	if err != nil {
+		v.log.Error("failed to resolve slots via storage layout", "address", acc.Address, "err", err)
+		return fmt.Errorf("invalid storage layout for account %s: %w", acc.Address, err)
 	}
+	acc = resolved
 
 	if acc.HeadSlot != "" {
-		if err := isValidHexUint(acc.HeadSlot); err != nil {
-			v.log.Error("head slot must be a valid hex uint", "headSlot", acc.HeadSlot)
+		if err := isValidHexHash(acc.HeadSlot); err != nil {
+			v.log.Error("head slot must be a valid hex slot or, with a storage layout, a variable", "headSlot", acc.HeadSlot)
 			return fmt.Errorf("invalid head slot: %w", err)
 		}
 	}
 
-	if (acc.ABI == empty && acc.HeadSlot != empty) || (acc.ABI != empty && acc.HeadSlot == empty) {
+	if (acc.ABI == empty && acc.Template == empty && acc.HeadSlot != empty && !v.resolveABIs) || (acc.ABI != empty && acc.HeadSlot == empty) {
 		v.log.Error("both ABI and head slot must be specified for event monitoring")
 		return fmt.Errorf("invalid event config for account %s: both ABI and head slot must be specified", acc.Address)
 	}
 
+	if acc.InitialHead != "" {
+		if err := isValidHexHash(acc.InitialHead); err != nil {
+			v.log.Error("initial head must be a valid hex hash", "initialHead", acc.InitialHead)
+			return fmt.Errorf("invalid initial head: %w", err)
+		}
+		if acc.HeadSlot == empty {
+			v.log.Error("initial head requires head slot", "address", acc.Address)
+			return fmt.Errorf("invalid event config for account %s: initial head without head slot", acc.Address)
+		}
+	}
+
+	if (len(acc.ABIFragments) > 0 || len(acc.Events) > 0) && acc.HeadSlot == empty {
+		v.log.Error("ABI fragments and events require head slot", "address", acc.Address)
+		return fmt.Errorf("invalid event config for account %s: ABI fragments or events without head slot", acc.Address)
+	}
+
+	if acc.Alerts != nil {
+		for _, val := range []string{acc.Alerts.MinBalance, acc.Alerts.MaxOutgoing} {
+			if val == empty {
+				continue
+			}
+			if _, err := parseWei(val); err != nil {
+				v.log.Error("alert threshold must be a valid amount in wei", "value", val)
+				return fmt.Errorf("invalid alert threshold for account %s: %w", acc.Address, err)
+			}
+		}
+	}
+
 	if acc.CountSlot != "" {
-		if err := isValidHexUint(acc.CountSlot); err != nil {
-			v.log.Error("count slot must be a valid hex uint", "countSlot", acc.CountSlot)
+		if err := isValidHexHash(acc.CountSlot); err != nil {
+			v.log.Error("count slot must be a valid hex slot or, with a storage layout, a variable", "countSlot", acc.CountSlot)
 			return fmt.Errorf("invalid count slot: %w", err)
 		}
 	}
@@ -66,12 +134,16 @@ func (v *validator) validateAccount(acc *account) error {
 	return nil
 }
 
-// isValidHexUint checks if the given string
-// represents a valid hexadecimal unsigned integer.
-func isValidHexUint(s string) error {
+// isValidHexHash checks if the given string
+// represents a valid hex hash of at most 32
+// bytes.
+func isValidHexHash(s string) error {
 	trimmed := strings.TrimPrefix(s, "0x")
-	if _, err := strconv.ParseUint(trimmed, 16, 64); err != nil {
-		return fmt.Errorf("invalid hex number: %s", s)
+	if len(trimmed) == 0 || len(trimmed) > 2*common.HashLength {
+		return fmt.Errorf("invalid hex hash: %s", s)
+	}
+	if _, err := hex.DecodeString(strings.Repeat("0", len(trimmed)%2) + trimmed); err != nil {
+		return fmt.Errorf("invalid hex hash: %s", s)
 	}
 	return nil
 }
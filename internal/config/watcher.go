@@ -0,0 +1,125 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"os"
+	"sparseth/config"
+	"sparseth/log"
+	"time"
+)
+
+// Watcher periodically checks the config file
+// for changes. Whenever the file content changes,
+// the new config is loaded, validated and, if
+// valid, published to subscribers.
+//
+// Invalid configs are rejected and logged, the
+// previously published config stays in effect.
+type Watcher struct {
+	path     string
+	interval time.Duration
+	loader   *Loader
+	digest   []byte
+	pub      chan *config.AccountsConfig
+	log      log.Logger
+}
+
+// NewWatcher creates a new Watcher for the config
+// file at the specified path, checking for changes
+// at the specified interval. New configs are
+// published at the returned channel. If checksum
+// is set, addresses must be in EIP-55 checksummed
+// form.
+func NewWatcher(path string, interval time.Duration, checksum bool, log log.Logger) (*Watcher, <-chan *config.AccountsConfig) {
+	ch := make(chan *config.AccountsConfig, 1)
+
+	return &Watcher{
+		path:     path,
+		interval: interval,
+		loader:   NewLoader(checksum, log),
+		pub:      ch,
+		log:      log.With("component", "config-watcher"),
+	}, ch
+}
+
+// SetABIResolver sets the resolver of the ABIs of
+// accounts with a head slot but no ABI path.
+func (w *Watcher) SetABIResolver(r *ABIResolver) {
+	w.loader.SetABIResolver(r)
+}
+
+// SetNameResolver sets the resolver of accounts
+// whose address is an ENS name.
+func (w *Watcher) SetNameResolver(r NameResolver) {
+	w.loader.SetNameResolver(r)
+}
+
+// RunContext watches the config file until
+// the context is canceled.
+//
+// The content of the config file at the time
+// RunContext is called is considered the
+// current config, i.e., it is not published.
+func (w *Watcher) RunContext(ctx context.Context) error {
+	defer close(w.pub)
+
+	digest, err := w.checksum()
+	if err != nil {
+		w.log.Warn("failed to read config file", "path", w.path, "err", err)
+	}
+	w.digest = digest
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	w.log.Info("start watching config file", "path", w.path, "interval", w.interval)
+	for {
+		select {
+		case <-ticker.C:
+			w.check(ctx)
+		case <-ctx.Done():
+			w.log.Info("stop watching config file")
+			return nil
+		}
+	}
+}
+
+// check reloads the config file if its
+// content changed since the last check.
+func (w *Watcher) check(ctx context.Context) {
+	digest, err := w.checksum()
+	if err != nil {
+		w.log.Warn("failed to read config file", "path", w.path, "err", err)
+		return
+	}
+	if bytes.Equal(digest, w.digest) {
+		return
+	}
+	w.digest = digest
+
+	w.log.Info("config file changed, reload", "path", w.path)
+	accs, err := w.loader.Load(w.path)
+	if err != nil {
+		w.log.Error("rejected new config, keep current config", "err", err)
+		return
+	}
+
+	select {
+	case w.pub <- accs:
+	case <-ctx.Done():
+	}
+}
+
+// checksum computes the digest of the
+// current config file content.
+func (w *Watcher) checksum() ([]byte, error) {
+	data, err := os.ReadFile(w.path)
+	if err != nil {
+		return nil, err
+	}
+
+	digest := sha256.Sum256(data)
+	return digest[:], nil
+}
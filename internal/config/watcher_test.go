@@ -0,0 +1,68 @@
+package config
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sparseth/internal/log"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestWatcher_RunContext(t *testing.T) {
+	testLogger := log.New(slog.DiscardHandler)
+
+	t.Run("should publish new config on change", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		if err := os.WriteFile(path, []byte("accounts:\n  - address: \"0xdeadbeefdeadbeefdeadbeefdeadbeefdeadbeef\"\n"), 0o644); err != nil {
+			t.Fatalf("failed to write config: %v", err)
+		}
+
+		watcher, updates := NewWatcher(path, 10*time.Millisecond, false, testLogger)
+		go watcher.RunContext(t.Context())
+
+		// Give the watcher time to read the initial config
+		time.Sleep(50 * time.Millisecond)
+
+		if err := os.WriteFile(path, []byte("accounts:\n  - address: \"0xf39fd6e51aad88f6f4ce6ab8827279cfffb92266\"\n"), 0o644); err != nil {
+			t.Fatalf("failed to write config: %v", err)
+		}
+
+		select {
+		case accs := <-updates:
+			if len(accs.Accounts) != 1 {
+				t.Fatalf("expected 1 account, got %d", len(accs.Accounts))
+			}
+			expected := common.HexToAddress("0xf39fd6e51aad88f6f4ce6ab8827279cfffb92266")
+			if accs.Accounts[0].Addr != expected {
+				t.Errorf("expected %s, got %s", expected.Hex(), accs.Accounts[0].Addr.Hex())
+			}
+		case <-time.After(time.Second):
+			t.Errorf("timeout: did not receive new config")
+		}
+	})
+
+	t.Run("should not publish invalid config", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		if err := os.WriteFile(path, []byte("accounts:\n  - address: \"0xdeadbeefdeadbeefdeadbeefdeadbeefdeadbeef\"\n"), 0o644); err != nil {
+			t.Fatalf("failed to write config: %v", err)
+		}
+
+		watcher, updates := NewWatcher(path, 10*time.Millisecond, false, testLogger)
+		go watcher.RunContext(t.Context())
+
+		time.Sleep(50 * time.Millisecond)
+
+		if err := os.WriteFile(path, []byte("accounts:\n  - address: \"not an address\"\n"), 0o644); err != nil {
+			t.Fatalf("failed to write config: %v", err)
+		}
+
+		select {
+		case <-updates:
+			t.Errorf("expected no config, got one")
+		case <-time.After(200 * time.Millisecond):
+		}
+	})
+}
@@ -0,0 +1,115 @@
+package log
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+const (
+	// FormatText is the format of
+	// the terminal handler.
+	FormatText = "text"
+	// FormatJSON is the format of
+	// the JSON handler.
+	FormatJSON = "json"
+)
+
+// ParseLevel parses the specified level,
+// i.e., debug, info, warn, or error.
+func ParseLevel(s string) (slog.Level, error) {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(strings.ToLower(s))); err != nil {
+		return 0, fmt.Errorf("expected debug, info, warn, or error, got %q", s)
+	}
+	return lvl, nil
+}
+
+// Levels holds the minimum level of logged
+// messages, optionally overridden for some
+// components.
+type Levels struct {
+	// Default is the level of all
+	// components not overridden.
+	Default slog.Level
+	// Components maps components
+	// to their level.
+	Components map[string]slog.Level
+}
+
+// ParseLevels parses the specified comma-separated
+// levels, i.e., an optional default level followed
+// by <component>=<level> overrides, e.g.,
+// info,state-verifier=debug. The default level is
+// debug, unless specified.
+func ParseLevels(s string) (*Levels, error) {
+	levels := &Levels{
+		Default:    slog.LevelDebug,
+		Components: make(map[string]slog.Level),
+	}
+	for i, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		component, value, found := strings.Cut(part, "=")
+		if !found {
+			if i > 0 {
+				return nil, fmt.Errorf("expected <component>=<level>, got %q", part)
+			}
+			value = part
+		}
+
+		lvl, err := ParseLevel(value)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			levels.Default = lvl
+			continue
+		}
+		if component == "" {
+			return nil, fmt.Errorf("missing component of level %q", part)
+		}
+		levels.Components[component] = lvl
+	}
+	return levels, nil
+}
+
+// Level returns the level of the
+// specified component.
+func (l *Levels) Level(component string) slog.Level {
+	if lvl, ok := l.Components[component]; ok {
+		return lvl
+	}
+	return l.Default
+}
+
+// min returns the lowest level
+// of all components.
+func (l *Levels) min() slog.Level {
+	lowest := l.Default
+	for _, lvl := range l.Components {
+		lowest = min(lowest, lvl)
+	}
+	return lowest
+}
+
+// NewHandler creates a new log handler of the
+// specified format that writes messages of at
+// least the level of their component to the
+// specified writer.
+func NewHandler(w io.Writer, format string, levels *Levels) (slog.Handler, error) {
+	var handler slog.Handler
+	switch format {
+	case FormatText:
+		handler = NewTextHandler(w, levels.min())
+	case FormatJSON:
+		handler = NewJSONHandler(w, levels.min())
+	default:
+		return nil, fmt.Errorf("expected %s or %s format, got %q", FormatText, FormatJSON, format)
+	}
+
+	if len(levels.Components) == 0 {
+		return handler, nil
+	}
+	return NewComponentHandler(handler, levels), nil
+}
@@ -3,10 +3,14 @@ package log
 import (
 	"context"
 	"fmt"
+	"io"
 	"log/slog"
+	"os"
 )
 
 type TerminalHandler struct {
+	out       io.Writer
+	colored   bool
 	lvl       slog.Level
 	attrs     []slog.Attr
 	component string
@@ -21,15 +25,17 @@ func (h *TerminalHandler) Handle(_ context.Context, r slog.Record) error {
 	lvl := r.Level.String()
 
 	color := ""
-	switch r.Level {
-	case slog.LevelDebug:
-		color = "\x1b[37m" // grey
-	case slog.LevelInfo:
-		color = "\x1b[32m" // green
-	case slog.LevelWarn:
-		color = "\x1b[33m" // yellow
-	case slog.LevelError:
-		color = "\x1b[31m" // red
+	if h.colored {
+		switch r.Level {
+		case slog.LevelDebug:
+			color = "\x1b[37m" // grey
+		case slog.LevelInfo:
+			color = "\x1b[32m" // green
+		case slog.LevelWarn:
+			color = "\x1b[33m" // yellow
+		case slog.LevelError:
+			color = "\x1b[31m" // red
+		}
 	}
 
 	time := ""
@@ -43,7 +49,7 @@ func (h *TerminalHandler) Handle(_ context.Context, r slog.Record) error {
 		return true
 	})
 
-	_, err := fmt.Println(color, time, lvl, h.component, msg, attrs)
+	_, err := fmt.Fprintln(h.out, color, time, lvl, h.component, msg, attrs)
 
 	return err
 }
@@ -57,6 +63,8 @@ func (h *TerminalHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
 	}
 
 	return &TerminalHandler{
+		out:       h.out,
+		colored:   h.colored,
 		lvl:       h.lvl,
 		attrs:     append(h.attrs, attrs...),
 		component: component,
@@ -71,9 +79,135 @@ func (h *TerminalHandler) WithGroup(_ string) slog.Handler {
 // log handler that prints colorful messages
 // to stdout.
 func NewTerminalHandler() *TerminalHandler {
+	return NewTextHandler(os.Stdout, slog.LevelDebug)
+}
+
+// NewTextHandler creates a new log handler that
+// prints messages of at least the specified level
+// to the specified writer, in the format of the
+// terminal handler. Messages are colorful only if
+// the writer is a terminal.
+func NewTextHandler(w io.Writer, lvl slog.Level) *TerminalHandler {
 	return &TerminalHandler{
-		lvl:       slog.LevelDebug,
+		out:       w,
+		colored:   isTerminal(w),
+		lvl:       lvl,
 		attrs:     []slog.Attr{},
 		component: "[]",
 	}
 }
+
+// JSONHandler writes messages as JSON, one per
+// line. Like the terminal handler, it keeps only
+// the innermost component of nested loggers.
+type JSONHandler struct {
+	inner     slog.Handler
+	component string
+}
+
+func (h *JSONHandler) Enabled(ctx context.Context, lvl slog.Level) bool {
+	return h.inner.Enabled(ctx, lvl)
+}
+
+func (h *JSONHandler) Handle(ctx context.Context, r slog.Record) error {
+	if h.component != "" {
+		r = r.Clone()
+		r.AddAttrs(slog.String("component", h.component))
+	}
+	return h.inner.Handle(ctx, r)
+}
+
+func (h *JSONHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	component := h.component
+	rest := make([]slog.Attr, 0, len(attrs))
+	for _, attr := range attrs {
+		if attr.Key == "component" {
+			component = attr.Value.String()
+			continue
+		}
+		rest = append(rest, attr)
+	}
+
+	return &JSONHandler{
+		inner:     h.inner.WithAttrs(rest),
+		component: component,
+	}
+}
+
+func (h *JSONHandler) WithGroup(name string) slog.Handler {
+	return &JSONHandler{
+		inner:     h.inner.WithGroup(name),
+		component: h.component,
+	}
+}
+
+// NewJSONHandler creates a new log handler that
+// writes messages of at least the specified level
+// to the specified writer as JSON, one per line.
+func NewJSONHandler(w io.Writer, lvl slog.Level) *JSONHandler {
+	return &JSONHandler{
+		inner: slog.NewJSONHandler(w, &slog.HandlerOptions{Level: lvl}),
+	}
+}
+
+// isTerminal returns whether the
+// specified writer is a terminal.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// ComponentHandler filters the messages of another
+// handler by the level of their component, i.e.,
+// the innermost component of nested loggers.
+type ComponentHandler struct {
+	inner  slog.Handler
+	levels *Levels
+	lvl    slog.Level
+}
+
+func (h *ComponentHandler) Enabled(ctx context.Context, lvl slog.Level) bool {
+	return lvl >= h.lvl && h.inner.Enabled(ctx, lvl)
+}
+
+func (h *ComponentHandler) Handle(ctx context.Context, r slog.Record) error {
+	return h.inner.Handle(ctx, r)
+}
+
+func (h *ComponentHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	lvl := h.lvl
+	for _, attr := range attrs {
+		if attr.Key == "component" {
+			lvl = h.levels.Level(attr.Value.String())
+		}
+	}
+
+	return &ComponentHandler{
+		inner:  h.inner.WithAttrs(attrs),
+		levels: h.levels,
+		lvl:    lvl,
+	}
+}
+
+func (h *ComponentHandler) WithGroup(name string) slog.Handler {
+	return &ComponentHandler{
+		inner:  h.inner.WithGroup(name),
+		levels: h.levels,
+		lvl:    h.lvl,
+	}
+}
+
+// NewComponentHandler creates a new log handler
+// that passes messages of at least the level of
+// their component to the specified handler.
+func NewComponentHandler(inner slog.Handler, levels *Levels) *ComponentHandler {
+	return &ComponentHandler{
+		inner:  inner,
+		levels: levels,
+		lvl:    levels.Default,
+	}
+}
@@ -0,0 +1,92 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+)
+
+func TestJSONHandler(t *testing.T) {
+	t.Run("should keep innermost component", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := New(NewJSONHandler(&buf, slog.LevelInfo)).With("component", "main").With("component", "node", "block", 7)
+		logger.Info("processed block")
+
+		var got map[string]any
+		if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if got["component"] != "node" || got["block"] != float64(7) || got["msg"] != "processed block" {
+			t.Errorf("expected message of node at block 7, got %v", got)
+		}
+		if bytes.Count(buf.Bytes(), []byte(`"component"`)) != 1 {
+			t.Errorf("expected single component, got %s", buf.String())
+		}
+	})
+
+	t.Run("should drop messages below level", func(t *testing.T) {
+		var buf bytes.Buffer
+		New(NewJSONHandler(&buf, slog.LevelWarn)).Info("ignored")
+		if buf.Len() != 0 {
+			t.Errorf("expected no output, got %s", buf.String())
+		}
+	})
+}
+
+func TestComponentHandler(t *testing.T) {
+	t.Run("should filter by level of component", func(t *testing.T) {
+		levels, err := ParseLevels("warn,state-verifier=debug")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		var buf bytes.Buffer
+		handler, err := NewHandler(&buf, FormatJSON, levels)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		logger := New(handler).With("component", "main")
+		logger.Info("ignored")
+		logger.With("component", "state-verifier").Debug("verified")
+		logger.With("component", "sync-client").Info("ignored")
+
+		var got map[string]any
+		if err = json.Unmarshal(buf.Bytes(), &got); err != nil {
+			t.Fatalf("expected single message, got %s", buf.String())
+		}
+		if got["component"] != "state-verifier" || got["msg"] != "verified" {
+			t.Errorf("expected message of state verifier, got %v", got)
+		}
+	})
+}
+
+func TestParseLevels(t *testing.T) {
+	t.Run("should parse default and overrides", func(t *testing.T) {
+		levels, err := ParseLevels("info, sync-client=WARN")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if levels.Level("node") != slog.LevelInfo || levels.Level("sync-client") != slog.LevelWarn {
+			t.Errorf("expected info and warn, got %v and %v", levels.Level("node"), levels.Level("sync-client"))
+		}
+	})
+
+	t.Run("should default to debug", func(t *testing.T) {
+		levels, err := ParseLevels("sync-client=error")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if levels.Default != slog.LevelDebug {
+			t.Errorf("expected debug, got %v", levels.Default)
+		}
+	})
+
+	t.Run("should fail on invalid levels", func(t *testing.T) {
+		for _, s := range []string{"verbose", "sync-client=warn,info", "=info", "node=loud"} {
+			if _, err := ParseLevels(s); err == nil {
+				t.Errorf("expected error for %q, got nil", s)
+			}
+		}
+	})
+}
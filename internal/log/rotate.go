@@ -0,0 +1,111 @@
+package log
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+// RotatingFile is a log file that is rotated
+// once it exceeds a maximum size. On rotation,
+// the file is renamed to <path>.1, an existing
+// <path>.1 to <path>.2, and so on, where the
+// oldest backups are removed.
+type RotatingFile struct {
+	mu      sync.Mutex
+	path    string
+	maxSize int64
+	backups int
+	file    *os.File
+	size    int64
+}
+
+// OpenRotatingFile opens the log file at the
+// specified path for appending, which is rotated
+// once it exceeds the specified size in bytes,
+// keeping the specified number of backups.
+func OpenRotatingFile(path string, maxSize int64, backups int) (*RotatingFile, error) {
+	f := &RotatingFile{
+		path:    path,
+		maxSize: maxSize,
+		backups: backups,
+	}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Write writes the specified bytes to the log
+// file, and rotates it first if the bytes would
+// exceed its maximum size.
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.size > 0 && f.size+int64(len(p)) > f.maxSize {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Close closes the log file.
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.file.Close()
+}
+
+// open opens the log file,
+// creating it if missing.
+func (f *RotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat log file: %w", err)
+	}
+
+	f.file = file
+	f.size = info.Size()
+	return nil
+}
+
+// rotate shifts the backups, renames the
+// log file, and opens a new log file.
+func (f *RotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return fmt.Errorf("failed to close log file: %w", err)
+	}
+
+	if f.backups == 0 {
+		if err := os.Remove(f.path); err != nil {
+			return fmt.Errorf("failed to remove log file: %w", err)
+		}
+		return f.open()
+	}
+	for i := f.backups - 1; i > 0; i-- {
+		err := os.Rename(backupPath(f.path, i), backupPath(f.path, i+1))
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to rotate log file: %w", err)
+		}
+	}
+	if err := os.Rename(f.path, backupPath(f.path, 1)); err != nil {
+		return fmt.Errorf("failed to rotate log file: %w", err)
+	}
+	return f.open()
+}
+
+// backupPath returns the path of the
+// specified backup of the log file.
+func backupPath(path string, i int) string {
+	return fmt.Sprintf("%s.%d", path, i)
+}
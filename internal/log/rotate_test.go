@@ -0,0 +1,66 @@
+package log
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRotatingFile_Write(t *testing.T) {
+	t.Run("should rotate file exceeding max size", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "sparseth.log")
+		f, err := OpenRotatingFile(path, 8, 2)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		defer f.Close()
+
+		for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+			if _, err = f.Write([]byte(line)); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+		}
+
+		expected := map[string]string{
+			path:        "fourth\n",
+			path + ".1": "third\n",
+			path + ".2": "second\n",
+		}
+		for p, content := range expected {
+			got, err := os.ReadFile(p)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if string(got) != content {
+				t.Errorf("expected %q in %s, got %q", content, filepath.Base(p), got)
+			}
+		}
+		if _, err = os.Stat(path + ".3"); !os.IsNotExist(err) {
+			t.Errorf("expected oldest backup to be removed, got %v", err)
+		}
+	})
+
+	t.Run("should append to existing file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "sparseth.log")
+		if err := os.WriteFile(path, []byte("old\n"), 0o644); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		f, err := OpenRotatingFile(path, 1024, 1)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if _, err = f.Write([]byte("new\n")); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		f.Close()
+
+		got, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if string(got) != "old\nnew\n" {
+			t.Errorf("expected old and new line, got %q", got)
+		}
+	})
+}
@@ -0,0 +1,338 @@
+package node
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"sparseth/config"
+	internalconfig "sparseth/internal/config"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// AdminAPI provides the admin_ JSON-RPC namespace,
+// which allows operators to manage the monitored
+// accounts of a running node.
+type AdminAPI struct {
+	n      *Node
+	loader *internalconfig.Loader
+	// tenant is the API consumer the API
+	// is served to, or nil if unrestricted.
+	tenant *config.Tenant
+}
+
+// AccountStatus describes a monitored account.
+type AccountStatus struct {
+	Address      config.ChecksumAddress `json:"address"`
+	EventMonitor bool                   `json:"eventMonitor"`
+	StateMonitor bool                   `json:"stateMonitor"`
+	HeadSlot     *common.Hash           `json:"headSlot,omitempty"`
+	CountSlot    *common.Hash           `json:"countSlot,omitempty"`
+	// ProofOnly indicates that the account exceeded
+	// its RPC budget, and its transactions are no
+	// longer re-executed.
+	ProofOnly bool `json:"proofOnly"`
+	// Guarantees are the verification guarantees
+	// currently active for the account.
+	Guarantees *Guarantees `json:"guarantees"`
+}
+
+// NodeStatus describes the operational
+// state of the node.
+type NodeStatus struct {
+	Mode        string                   `json:"mode"`
+	Accounts    int                      `json:"accounts"`
+	Monitors    []config.ChecksumAddress `json:"monitors"`
+	WatchConfig bool                     `json:"watchConfig"`
+	// Committed is the last block processed
+	// by all monitors, if any.
+	Committed *CommittedBlock `json:"committed,omitempty"`
+}
+
+// CommittedBlock describes a block that
+// has been processed by all monitors.
+type CommittedBlock struct {
+	Number   hexutil.Uint64 `json:"number"`
+	Hash     common.Hash    `json:"hash"`
+	Verified bool           `json:"verified"`
+}
+
+// newAdminAPI creates a new AdminAPI for the
+// specified node, as seen by the specified
+// tenant. A nil tenant is unrestricted.
+func newAdminAPI(n *Node, tenant *config.Tenant) *AdminAPI {
+	loader := internalconfig.NewLoader(n.config.ChecksumAddresses, n.log)
+	loader.SetABIResolver(n.config.ABIResolver)
+	loader.SetNameResolver(n.config.NameResolver)
+
+	return &AdminAPI{
+		n:      n,
+		loader: loader,
+		tenant: tenant,
+	}
+}
+
+// AddAccount adds the specified account to the
+// set of monitored accounts. The account entry
+// has the same format as in the config file.
+func (api *AdminAPI) AddAccount(ctx context.Context, entry internalconfig.AccountEntry) (bool, error) {
+	if api.tenant.Scoped() {
+		return false, errForbidden
+	}
+	acc, err := api.loader.LoadAccount(&entry)
+	if err != nil {
+		return false, err
+	}
+
+	err = api.n.setAccounts(ctx, func(accs *config.AccountsConfig) (*config.AccountsConfig, error) {
+		if accs.Contains(acc.Addr) {
+			return nil, fmt.Errorf("account %s is already monitored", acc.Addr.Hex())
+		}
+
+		updated := make([]*config.AccountConfig, 0, len(accs.Accounts)+1)
+		updated = append(updated, accs.Accounts...)
+		updated = append(updated, acc)
+		return &config.AccountsConfig{Accounts: updated}, nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	api.n.log.Info("account added via admin API", "account", acc.Addr.Hex())
+	return true, nil
+}
+
+// RemoveAccount removes the specified account
+// from the set of monitored accounts.
+func (api *AdminAPI) RemoveAccount(ctx context.Context, hex string) (bool, error) {
+	if api.tenant.Scoped() {
+		return false, errForbidden
+	}
+	if err := config.ValidateAddress(hex, api.n.config.ChecksumAddresses); err != nil {
+		return false, err
+	}
+	addr := common.HexToAddress(hex)
+
+	err := api.n.setAccounts(ctx, func(accs *config.AccountsConfig) (*config.AccountsConfig, error) {
+		if !accs.Contains(addr) {
+			return nil, fmt.Errorf("account %s is not monitored", addr.Hex())
+		}
+
+		updated := make([]*config.AccountConfig, 0, len(accs.Accounts))
+		for _, acc := range accs.Accounts {
+			if acc.Addr != addr {
+				updated = append(updated, acc)
+			}
+		}
+		return &config.AccountsConfig{Accounts: updated}, nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	api.n.log.Info("account removed via admin API", "account", addr.Hex())
+	return true, nil
+}
+
+// ApplyConfig replaces the set of monitored accounts
+// with the accounts of the config file at the specified
+// path, and returns the resulting changes. If dryRun is
+// set, the changes are only computed, not applied.
+//
+// Note that the path is resolved on the node's host.
+func (api *AdminAPI) ApplyConfig(ctx context.Context, path string, dryRun bool) (*ConfigPlan, error) {
+	if api.tenant.Scoped() {
+		return nil, errForbidden
+	}
+	accs, err := api.loader.Load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if dryRun {
+		return api.n.planAccounts(api.n.accounts(), accs), nil
+	}
+
+	var plan *ConfigPlan
+	err = api.n.setAccounts(ctx, func(current *config.AccountsConfig) (*config.AccountsConfig, error) {
+		plan = api.n.planAccounts(current, accs)
+		return accs, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	api.n.log.Info("config applied via admin API", "path", path, "added", len(plan.Added), "removed", len(plan.Removed), "changed", len(plan.Changed))
+	return plan, nil
+}
+
+// ExportState returns the world state after the last
+// processed block, along with the header of that block,
+// as RLP-encoded StateExport. As the state includes all
+// monitored accounts, it is not available to scoped
+// tenants.
+func (api *AdminAPI) ExportState() (hexutil.Bytes, error) {
+	if api.tenant.Scoped() {
+		return nil, errForbidden
+	}
+	if api.n.txProc == nil {
+		return nil, errNoWorldState
+	}
+
+	var buf bytes.Buffer
+	header, err := api.n.txProc.ExportState(&buf)
+	if err != nil {
+		return nil, err
+	}
+
+	api.n.log.Info("world state exported via admin API", "num", header.Number, "hash", header.Hash().Hex(), "size", buf.Len())
+	return buf.Bytes(), nil
+}
+
+// ListAccounts returns all monitored accounts
+// visible to the tenant.
+func (api *AdminAPI) ListAccounts() []*AccountStatus {
+	accs := api.n.accounts()
+
+	proofOnly := make(map[common.Address]bool)
+	if api.n.txProc != nil {
+		for _, addr := range api.n.txProc.ProofOnlyAccounts() {
+			proofOnly[addr] = true
+		}
+	}
+
+	result := make([]*AccountStatus, 0, len(accs.Accounts))
+	for _, acc := range accs.Accounts {
+		if !api.tenant.Allows(acc.Addr) {
+			continue
+		}
+		status := &AccountStatus{
+			Address:      config.ChecksumAddress(acc.Addr),
+			EventMonitor: acc.ContractConfig.HasEventConfig(),
+			StateMonitor: !api.n.config.IsEventMode,
+			ProofOnly:    proofOnly[acc.Addr],
+			Guarantees:   api.n.guarantees(acc, proofOnly[acc.Addr]),
+		}
+		if acc.ContractConfig.HasEventConfig() {
+			slot := acc.ContractConfig.Event.HeadSlot
+			status.HeadSlot = &slot
+		}
+		if acc.ContractConfig.HasSparseConfig() {
+			slot := acc.ContractConfig.State.CountSlot
+			status.CountSlot = &slot
+		}
+		result = append(result, status)
+	}
+
+	return result
+}
+
+// Status returns the operational state of the node.
+// Accounts and monitors are limited to the accounts
+// visible to the tenant.
+func (api *AdminAPI) Status() *NodeStatus {
+	mode := "sparse"
+	if api.n.config.IsEventMode {
+		mode = "event"
+	}
+
+	status := &NodeStatus{
+		Mode:        mode,
+		Accounts:    api.visibleAccounts(),
+		Monitors:    api.visibleMonitors(),
+		WatchConfig: api.n.config.WatchConfig,
+	}
+	if latest := api.n.barrier.Latest(); latest != nil {
+		status.Committed = &CommittedBlock{
+			Number:   hexutil.Uint64(latest.Number),
+			Hash:     latest.Hash,
+			Verified: latest.Verified(),
+		}
+	}
+	return status
+}
+
+// visibleAccounts returns the number of monitored
+// accounts visible to the tenant.
+func (api *AdminAPI) visibleAccounts() int {
+	visible := 0
+	for _, acc := range api.n.accounts().Accounts {
+		if api.tenant.Allows(acc.Addr) {
+			visible++
+		}
+	}
+	return visible
+}
+
+// visibleMonitors returns the accounts of all
+// running event monitors visible to the tenant.
+func (api *AdminAPI) visibleMonitors() []config.ChecksumAddress {
+	monitors := api.n.runningMonitors()
+
+	visible := make([]config.ChecksumAddress, 0, len(monitors))
+	for _, addr := range monitors {
+		if api.tenant.Allows(addr.Address()) {
+			visible = append(visible, addr)
+		}
+	}
+	return visible
+}
+
+// runningMonitors returns the accounts of
+// all running event monitors.
+func (n *Node) runningMonitors() []config.ChecksumAddress {
+	n.monitorsMu.Lock()
+	defer n.monitorsMu.Unlock()
+
+	addrs := make([]config.ChecksumAddress, 0, len(n.monitors))
+	for addr := range n.monitors {
+		addrs = append(addrs, config.ChecksumAddress(addr))
+	}
+	sort.Slice(addrs, func(i, j int) bool {
+		return addrs[i].Address().Cmp(addrs[j].Address()) < 0
+	})
+	return addrs
+}
+
+// startAPIServer serves the JSON-RPC API over
+// HTTP and WebSocket until the context is
+// canceled.
+func (n *Node) startAPIServer(ctx context.Context) func() error {
+	return func() error {
+		handler, stop, err := n.apiHandler()
+		if err != nil {
+			return err
+		}
+		defer stop()
+
+		listener, err := net.Listen("tcp", n.config.ApiAddr)
+		if err != nil {
+			n.log.Error("failed to listen", "addr", n.config.ApiAddr, "err", err)
+			return fmt.Errorf("failed to listen on %s: %w", n.config.ApiAddr, err)
+		}
+
+		srv := &http.Server{
+			Handler:           handler,
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+
+		go func() {
+			<-ctx.Done()
+			n.log.Info("stop API server")
+			srv.Close()
+		}()
+
+		if err = srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			n.log.Error("API server failed", "err", err)
+			return fmt.Errorf("API server failed: %w", err)
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,27 @@
+package node
+
+import (
+	"sparseth/execution/monitor"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// sampler returns whether a block is verified by
+// the event monitors, or nil if every block is.
+//
+// When verifying a block range, the last block is
+// always verified, so that the summary covers the
+// logs of all blocks in the range.
+func (n *Node) sampler() func(*types.Header) bool {
+	if n.config.AuditRate <= 1 {
+		return nil
+	}
+
+	s := monitor.NewSampler(n.config.AuditRate, n.config.AuditSeed)
+	return func(head *types.Header) bool {
+		if n.config.Range != nil && head.Number.Uint64() == n.config.Range.To {
+			return true
+		}
+		return s.Sampled(head)
+	}
+}
@@ -0,0 +1,203 @@
+package node
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sparseth/config"
+	"sparseth/log"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+var (
+	// errForbidden is returned if a method is
+	// not permitted for the tenant's API key.
+	errForbidden = errors.New("not permitted for this API key")
+)
+
+// apiHandler returns the HTTP handler of the JSON-RPC
+// API, along with a function that stops it.
+//
+// If tenants are configured, each tenant is served
+// by a separate server, selected by its API key, that
+// only exposes the accounts visible to the tenant.
+// Otherwise, all namespaces but admin are served
+// without restriction, see adminHandler.
+func (n *Node) apiHandler() (http.Handler, func(), error) {
+	if len(n.config.Tenants) == 0 {
+		public, err := n.newAPIServer(nil, false)
+		if err != nil {
+			return nil, nil, err
+		}
+		admin, err := n.newAPIServer(nil, true)
+		if err != nil {
+			public.Stop()
+			return nil, nil, err
+		}
+		stop := func() {
+			public.Stop()
+			admin.Stop()
+		}
+
+		if n.config.AdminKey == "" {
+			n.log.Info("admin API restricted to local clients")
+		}
+		return &adminHandler{
+			key:    n.config.AdminKey,
+			admin:  n.rpcHandler(admin),
+			public: n.rpcHandler(public),
+		}, stop, nil
+	}
+
+	servers := make([]*rpc.Server, 0, len(n.config.Tenants))
+	stop := func() {
+		for _, server := range servers {
+			server.Stop()
+		}
+	}
+
+	handler := &tenantHandler{
+		tenants:  n.config.Tenants,
+		handlers: make([]http.Handler, len(n.config.Tenants)),
+		log:      n.log,
+	}
+	for i, tenant := range n.config.Tenants {
+		server, err := n.newAPIServer(tenant, true)
+		if err != nil {
+			stop()
+			return nil, nil, err
+		}
+		servers = append(servers, server)
+		handler.handlers[i] = n.rpcHandler(server)
+	}
+
+	n.log.Info("API keys required", "tenants", len(n.config.Tenants))
+	return handler, stop, nil
+}
+
+// newAPIServer creates a new JSON-RPC server serving
+// all namespaces as seen by the specified tenant, and
+// the admin namespace only if specified. A nil tenant
+// is unrestricted.
+func (n *Node) newAPIServer(tenant *config.Tenant, admin bool) (*rpc.Server, error) {
+	server := rpc.NewServer()
+
+	if admin {
+		if err := server.RegisterName("admin", newAdminAPI(n, tenant)); err != nil {
+			server.Stop()
+			return nil, fmt.Errorf("failed to register admin API: %w", err)
+		}
+	}
+	if err := server.RegisterName("stats", newStatsAPI(n, tenant)); err != nil {
+		server.Stop()
+		return nil, fmt.Errorf("failed to register stats API: %w", err)
+	}
+	if err := server.RegisterName("eth", newEthAPI(n, tenant)); err != nil {
+		server.Stop()
+		return nil, fmt.Errorf("failed to register eth API: %w", err)
+	}
+	if err := server.RegisterName("sparseth", newSparsethAPI(n, tenant)); err != nil {
+		server.Stop()
+		return nil, fmt.Errorf("failed to register sparseth API: %w", err)
+	}
+	if n.config.ServeHeaders {
+		if err := server.RegisterName("headers", newHeadersAPI(n)); err != nil {
+			server.Stop()
+			return nil, fmt.Errorf("failed to register headers API: %w", err)
+		}
+	}
+	return server, nil
+}
+
+// rpcHandler serves the specified server over both
+// HTTP and WebSocket. WebSocket connections are only
+// accepted from the configured origins, see
+// Config.ApiOrigins.
+func (n *Node) rpcHandler(server *rpc.Server) http.Handler {
+	ws := server.WebsocketHandler(n.config.ApiOrigins)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Upgrade") == "websocket" {
+			ws.ServeHTTP(w, r)
+			return
+		}
+		server.ServeHTTP(w, r)
+	})
+}
+
+// adminHandler dispatches requests to the server
+// with the admin namespace if the request presents
+// the admin key, or, if no admin key is configured,
+// if it is sent from the loopback interface without
+// an Origin header, i.e., not by a browser. All other
+// requests are dispatched to the server without it.
+type adminHandler struct {
+	key    string
+	admin  http.Handler
+	public http.Handler
+}
+
+// ServeHTTP dispatches the specified request.
+func (h *adminHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.allowed(r) {
+		h.admin.ServeHTTP(w, r)
+		return
+	}
+	h.public.ServeHTTP(w, r)
+}
+
+// allowed checks if the specified request
+// may access the admin namespace.
+func (h *adminHandler) allowed(r *http.Request) bool {
+	if h.key != "" {
+		return subtle.ConstantTimeCompare([]byte(apiKey(r)), []byte(h.key)) == 1
+	}
+	if _, ok := r.Header["Origin"]; ok {
+		return false
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// tenantHandler dispatches requests to the
+// handler of the tenant whose API key is
+// presented with the request.
+type tenantHandler struct {
+	tenants  []*config.Tenant
+	handlers []http.Handler
+	log      log.Logger
+}
+
+// ServeHTTP dispatches the specified request, or
+// rejects it if no valid API key is presented.
+func (h *tenantHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := apiKey(r)
+	if key != "" {
+		for i, tenant := range h.tenants {
+			if subtle.ConstantTimeCompare([]byte(key), []byte(tenant.Key)) == 1 {
+				h.handlers[i].ServeHTTP(w, r)
+				return
+			}
+		}
+	}
+
+	h.log.Debug("reject API request without valid key", "remote", r.RemoteAddr)
+	http.Error(w, "missing or invalid API key", http.StatusUnauthorized)
+}
+
+// apiKey returns the API key presented with the
+// specified request, either as bearer token or
+// in the X-API-Key header.
+func apiKey(r *http.Request) string {
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		return token
+	}
+	return r.Header.Get("X-API-Key")
+}
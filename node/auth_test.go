@@ -0,0 +1,324 @@
+package node
+
+import (
+	"encoding/json"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sparseth/bus"
+	"sparseth/config"
+	"sparseth/ethstore"
+	internallog "sparseth/internal/log"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// newAuthTestNode creates a node that
+// only serves the API with the specified
+// config.
+func newAuthTestNode(cfg *Config) *Node {
+	cfg.AccsConfig = &config.AccountsConfig{}
+	return &Node{
+		config: cfg,
+		log:    internallog.New(slog.DiscardHandler),
+	}
+}
+
+// callAdmin calls admin_listAccounts with the
+// specified request options, and returns if
+// the method is available.
+func callAdmin(t *testing.T, h http.Handler, setup func(r *http.Request)) bool {
+	body := `{"jsonrpc":"2.0","id":1,"method":"admin_listAccounts","params":[]}`
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+	setup(r)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	var resp struct {
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Code int `json:"code"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	return resp.Error == nil
+}
+
+func TestNode_AdminAccess(t *testing.T) {
+	t.Run("should serve admin to local clients without key", func(t *testing.T) {
+		h, stop, err := newAuthTestNode(&Config{}).apiHandler()
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		defer stop()
+
+		local := func(r *http.Request) { r.RemoteAddr = "127.0.0.1:4000" }
+		if !callAdmin(t, h, local) {
+			t.Errorf("expected admin served to local client, got rejected")
+		}
+	})
+
+	t.Run("should not serve admin to remote clients without key", func(t *testing.T) {
+		h, stop, err := newAuthTestNode(&Config{}).apiHandler()
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		defer stop()
+
+		remote := func(r *http.Request) { r.RemoteAddr = "203.0.113.7:4000" }
+		if callAdmin(t, h, remote) {
+			t.Errorf("expected admin rejected for remote client, got served")
+		}
+	})
+
+	t.Run("should not serve admin to local browsers without key", func(t *testing.T) {
+		h, stop, err := newAuthTestNode(&Config{}).apiHandler()
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		defer stop()
+
+		browser := func(r *http.Request) {
+			r.RemoteAddr = "127.0.0.1:4000"
+			r.Header.Set("Origin", "https://evil.example")
+		}
+		if callAdmin(t, h, browser) {
+			t.Errorf("expected admin rejected for browser, got served")
+		}
+	})
+
+	t.Run("should require admin key if set", func(t *testing.T) {
+		h, stop, err := newAuthTestNode(&Config{AdminKey: "secret"}).apiHandler()
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		defer stop()
+
+		local := func(r *http.Request) { r.RemoteAddr = "127.0.0.1:4000" }
+		if callAdmin(t, h, local) {
+			t.Errorf("expected admin rejected without key, got served")
+		}
+		withKey := func(r *http.Request) {
+			r.RemoteAddr = "203.0.113.7:4000"
+			r.Header.Set("Authorization", "Bearer secret")
+		}
+		if !callAdmin(t, h, withKey) {
+			t.Errorf("expected admin served with key, got rejected")
+		}
+	})
+}
+
+func TestNode_WebsocketOrigins(t *testing.T) {
+	t.Run("should reject foreign origin by default", func(t *testing.T) {
+		h, stop, err := newAuthTestNode(&Config{}).apiHandler()
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		defer stop()
+		srv := httptest.NewServer(h)
+		defer srv.Close()
+
+		url := "ws" + strings.TrimPrefix(srv.URL, "http")
+		if client, err := rpc.DialWebsocket(t.Context(), url, "https://evil.example"); err == nil {
+			client.Close()
+			t.Errorf("expected error, got nil")
+		}
+	})
+
+	t.Run("should accept configured origin", func(t *testing.T) {
+		h, stop, err := newAuthTestNode(&Config{ApiOrigins: []string{"https://dashboard.example"}}).apiHandler()
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		defer stop()
+		srv := httptest.NewServer(h)
+		defer srv.Close()
+
+		url := "ws" + strings.TrimPrefix(srv.URL, "http")
+		client, err := rpc.DialWebsocket(t.Context(), url, "https://dashboard.example")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		client.Close()
+	})
+}
+
+// seedTenantTestNode stores a committed block in
+// which each of the specified accounts changed,
+// emitted a log, and was attested.
+func seedTenantTestNode(t *testing.T, n *Node, block, tx common.Hash, accs ...common.Address) {
+	if err := ethstore.NewRootStore(n.db).Put(&ethstore.StateRoot{Number: 1, BlockHash: block, Root: types.EmptyRootHash}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	diff := &ethstore.StateDiff{Number: 1, BlockHash: block}
+	for i, addr := range accs {
+		diff.Accounts = append(diff.Accounts, &ethstore.AccountDiff{
+			Address:       addr,
+			BalanceBefore: big.NewInt(0),
+			BalanceAfter:  big.NewInt(1),
+		})
+
+		log := &types.Log{Address: addr, BlockNumber: 1, BlockHash: block, TxHash: tx, Index: uint(i)}
+		if err := ethstore.NewEventStore(n.db, n.config.DbEncoding).PutAll([]*types.Log{log}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		head := &ethstore.ChainHead{
+			Number:    1,
+			BlockHash: block,
+			Logs:      []*ethstore.LogID{{BlockNumber: 1, TxHash: tx, Index: uint64(i)}},
+		}
+		if err := ethstore.NewChainHeadStore(n.db).Put(addr, head); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		attestation := &ethstore.Attestation{Number: 1, BlockHash: block, Account: addr, Signature: []byte{1}}
+		if err := ethstore.NewAttestationStore(n.db).Put(attestation); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+	if err := ethstore.NewDiffStore(n.db).Put(diff); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	outcome := &ethstore.TxOutcome{TxHash: tx, Number: 1, BlockHash: block, Verified: true, Accounts: accs}
+	if err := ethstore.NewTxStore(n.db).PutAll([]*ethstore.TxOutcome{outcome}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// Commit the block, so that
+	// its logs are served
+	commits := n.events.Commits.Subscribe("test")
+	defer n.events.Commits.Unsubscribe("test")
+	n.barrier.Register("test")
+	go n.barrier.RunContext(t.Context())
+	n.events.Results.Publish(&bus.Result{Monitor: "test", Number: 1, Hash: block})
+	select {
+	case <-commits:
+	case <-time.After(time.Second):
+		t.Fatalf("expected block committed, got timeout")
+	}
+}
+
+// reveals checks whether the specified
+// response contains the specified address.
+func reveals(res []byte, addr common.Address) bool {
+	return strings.Contains(strings.ToLower(string(res)), strings.ToLower(addr.Hex()[2:]))
+}
+
+func TestNode_TenantScope(t *testing.T) {
+	mine := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	other := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	block := common.HexToHash("0xb1")
+	tx := common.HexToHash("0xaa")
+
+	n := newTestNode(t, mine, other)
+	seedTenantTestNode(t, n, block, tx, mine, other)
+
+	server, err := n.newAPIServer(&config.Tenant{Accounts: []common.Address{mine}}, false)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer server.Stop()
+	client := rpc.DialInProc(server)
+	defer client.Close()
+
+	calls := []struct {
+		method string
+		args   []any
+		// own indicates whether the response
+		// must contain the tenant's account.
+		own bool
+	}{
+		{method: "stats_stateRoot"},
+		{method: "stats_rootHistory", args: []any{"0x1", "0x1"}},
+		{method: "stats_trieStats"},
+		{method: "stats_blockDigest", args: []any{"0x1"}},
+		{method: "stats_txOutcome", args: []any{tx}, own: true},
+		{method: "stats_stateDiff", args: []any{"0x1"}, own: true},
+		{method: "stats_providers"},
+		{method: "stats_pressure"},
+		{method: "stats_queues"},
+		{method: "stats_deadLetters", args: []any{"0x0"}},
+		{method: "stats_attestations", args: []any{"0x1"}, own: true},
+		{method: "stats_peers"},
+		{method: "stats_gC"},
+		{method: "stats_verificationCounts"},
+		{method: "eth_call", args: []any{map[string]any{"to": other}, "latest"}},
+		{method: "eth_getLogs", args: []any{map[string]any{"fromBlock": "0x1", "toBlock": "0x1"}}, own: true},
+		{method: "eth_getLogs", args: []any{map[string]any{"fromBlock": "0x1", "toBlock": "0x1", "address": other}}},
+		{method: "eth_getProof", args: []any{mine, []any{}, "0x1"}},
+		{method: "eth_getProof", args: []any{other, []any{}, "0x1"}},
+		{method: "sparseth_status"},
+	}
+	for _, c := range calls {
+		t.Run("should not reveal other accounts via "+c.method, func(t *testing.T) {
+			var res json.RawMessage
+			if err := client.Call(&res, c.method, c.args...); err != nil {
+				if c.own {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				res = []byte(err.Error())
+			}
+
+			if reveals(res, other) {
+				t.Errorf("expected no account %s, got %s", other.Hex(), res)
+			}
+			if c.own && !reveals(res, mine) {
+				t.Errorf("expected account %s, got %s", mine.Hex(), res)
+			}
+		})
+	}
+
+	t.Run("should not reveal verifications of other accounts", func(t *testing.T) {
+		ch := make(chan json.RawMessage, 2)
+		sub, err := client.Subscribe(t.Context(), "stats", ch, "verifications")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		defer sub.Unsubscribe()
+
+		n.events.Verifications.Publish(&bus.Verification{Kind: bus.VerificationOK, Account: other, Number: 1, Hash: block})
+		n.events.Verifications.Publish(&bus.Verification{Kind: bus.VerificationOK, Account: mine, Number: 1, Hash: block})
+		select {
+		case res := <-ch:
+			if !reveals(res, mine) {
+				t.Errorf("expected verification of %s, got %s", mine.Hex(), res)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("expected verification, got timeout")
+		}
+	})
+
+	t.Run("should not reveal other accounts via stats_commits", func(t *testing.T) {
+		ch := make(chan json.RawMessage, 1)
+		sub, err := client.Subscribe(t.Context(), "stats", ch, "commits", "0x0")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		defer sub.Unsubscribe()
+
+		n.events.Persisted.Publish(&bus.BlockCommit{
+			Number:  2,
+			Hash:    common.HexToHash("0xb2"),
+			Results: []*bus.Result{{Monitor: "test", Number: 2, Activity: map[common.Address]uint64{mine: 1, other: 1}}},
+		})
+		select {
+		case res := <-ch:
+			if reveals(res, other) {
+				t.Errorf("expected no account %s, got %s", other.Hex(), res)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("expected commit, got timeout")
+		}
+	})
+}
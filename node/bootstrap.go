@@ -0,0 +1,111 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sparseth/ethstore"
+	"sparseth/execution/monitor/state"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// bootstrapState seeds the world state of the
+// specified processor with the proven state of
+// all monitored accounts at the starting point
+// of the node, i.e., the checkpoint block, or
+// the parent of the first block of the range.
+// If a complete world state was imported for
+// that block, it is resumed instead. Outside of
+// a range, the world state after the last block
+// acknowledged by the transaction monitor is
+// resumed first, if not before the checkpoint.
+func (n *Node) bootstrapState(ctx context.Context, proc *state.TxProcessor) error {
+	var head *types.Header
+	if n.config.Range != nil {
+		if n.config.Range.From == 0 {
+			return nil
+		}
+		num := new(big.Int).SetUint64(n.config.Range.From - 1)
+		if err := n.pool.CallContext(ctx, &head, "eth_getBlockByNumber", hexutil.EncodeBig(num), false); err != nil {
+			return fmt.Errorf("failed to fetch block %d: %w", num, err)
+		}
+	} else {
+		if err := n.pool.CallContext(ctx, &head, "eth_getBlockByHash", n.config.Checkpoint.Hash, false); err != nil {
+			return fmt.Errorf("failed to fetch checkpoint block: %w", err)
+		}
+		if head != nil {
+			if err := n.config.Checkpoint.Verify(head); err != nil {
+				return fmt.Errorf("invalid checkpoint block: %w", err)
+			}
+		}
+	}
+	if head == nil {
+		return fmt.Errorf("starting block not found")
+	}
+
+	if n.config.Range == nil {
+		resumed, err := n.resumeAcknowledged(proc, head)
+		if err != nil {
+			return fmt.Errorf("failed to resume acknowledged world state: %w", err)
+		}
+		if resumed {
+			return nil
+		}
+	}
+
+	resumed, err := proc.Resume(head)
+	if err != nil {
+		return fmt.Errorf("failed to resume world state: %w", err)
+	}
+	if resumed {
+		n.log.Info("resumed imported world state", "num", head.Number, "hash", head.Hash().Hex(), "root", proc.LatestRoot().Root.Hex())
+		return nil
+	}
+
+	incomplete, err := proc.Bootstrap(ctx, head)
+	if err != nil {
+		return err
+	}
+	for _, addr := range incomplete {
+		n.log.Warn("storage of account not fully seeded, completeness checks fail until reconstructed", "account", addr.Hex(), "num", head.Number)
+	}
+	n.log.Info("bootstrapped world state", "num", head.Number, "hash", head.Hash().Hex(), "incomplete", len(incomplete))
+	return nil
+}
+
+// resumeAcknowledged restores the world state of the
+// specified processor after the last block that the
+// transaction monitor acknowledged, if it is not
+// before the specified starting block. Blocks up to
+// it are then replayed, not processed again, when
+// the consensus client replays them.
+func (n *Node) resumeAcknowledged(proc *state.TxProcessor, start *types.Header) (bool, error) {
+	cursor, err := ethstore.NewCursorStore(n.db).Get(txMonitorName)
+	if errors.Is(err, ethstore.ErrCursorNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to load cursor: %w", err)
+	}
+	if cursor.Number < start.Number.Uint64() {
+		return false, nil
+	}
+
+	head, err := ethstore.NewHeaderStore(n.db).GetByHash(cursor.Hash)
+	if errors.Is(err, ethstore.ErrHeaderNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to load acknowledged header: %w", err)
+	}
+
+	resumed, err := proc.Resume(head)
+	if err != nil || !resumed {
+		return false, err
+	}
+	n.log.Info("resumed world state after acknowledged block", "num", head.Number, "hash", head.Hash().Hex(), "root", proc.LatestRoot().Root.Hex())
+	return true, nil
+}
@@ -0,0 +1,102 @@
+package node
+
+import (
+	"errors"
+	"math/big"
+	"sparseth/execution/monitor/state"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// errHistoricalCall is returned if a call is
+// requested against a block other than the
+// last processed one.
+var errHistoricalCall = errors.New("calls are only executed after the last processed block")
+
+// CallArgs are the arguments
+// of a read-only call.
+type CallArgs struct {
+	From  common.Address  `json:"from"`
+	To    common.Address  `json:"to"`
+	Data  hexutil.Bytes   `json:"data"`
+	Value *hexutil.Big    `json:"value"`
+	Gas   *hexutil.Uint64 `json:"gas"`
+}
+
+// CallResult is the result of a read-only
+// call against the verified world state.
+type CallResult struct {
+	Number    hexutil.Uint64 `json:"number"`
+	BlockHash common.Hash    `json:"blockHash"`
+	Return    hexutil.Bytes  `json:"return"`
+	GasUsed   hexutil.Uint64 `json:"gasUsed"`
+	// Error is the execution error, e.g.,
+	// "execution reverted", if any.
+	Error string `json:"error,omitempty"`
+	// Reverted indicates that the call reverted,
+	// in which case Return is the revert data.
+	Reverted bool `json:"reverted"`
+}
+
+// Call executes the specified read-only call against
+// the verified world state after the last processed
+// block, see state.TxProcessor.Call. It fails if the
+// call reads the state of an unmonitored account.
+func (n *Node) Call(msg *state.CallMsg) (*state.CallResult, error) {
+	if n.txProc == nil {
+		return nil, errNoWorldState
+	}
+	return n.txProc.Call(msg)
+}
+
+// Call executes a read-only call, e.g., of a view
+// function, against the verified world state of the
+// monitored accounts after the last processed block,
+// which the optional block refers to. Scoped tenants
+// may only call their accounts.
+func (api *EthAPI) Call(args CallArgs, block *rpc.BlockNumberOrHash) (*CallResult, error) {
+	if !api.tenant.Allows(args.To) {
+		return nil, errForbidden
+	}
+	if block != nil {
+		n, ok := block.Number()
+		if !ok || (n != rpc.LatestBlockNumber && n != rpc.SafeBlockNumber && n != rpc.FinalizedBlockNumber) {
+			return nil, errHistoricalCall
+		}
+	}
+
+	msg := &state.CallMsg{
+		From:  args.From,
+		To:    args.To,
+		Data:  args.Data,
+		Value: (*big.Int)(args.Value),
+	}
+	if args.Gas != nil {
+		msg.Gas = uint64(*args.Gas)
+	}
+
+	res, err := api.n.Call(msg)
+	if err != nil {
+		return nil, err
+	}
+	return toCallResult(res), nil
+}
+
+// toCallResult converts the
+// specified call result.
+func toCallResult(res *state.CallResult) *CallResult {
+	result := &CallResult{
+		Number:    hexutil.Uint64(res.Number),
+		BlockHash: res.BlockHash,
+		Return:    res.Return,
+		GasUsed:   hexutil.Uint64(res.GasUsed),
+	}
+	if res.Err != nil {
+		result.Error = res.Err.Error()
+		result.Reverted = errors.Is(res.Err, vm.ErrExecutionReverted)
+	}
+	return result
+}
@@ -1,32 +1,220 @@
 package node
 
 import (
+	"sparseth/bus"
 	"sparseth/config"
+	"sparseth/ethstore"
+	"sparseth/execution"
+	"sparseth/execution/ethclient"
+	internalconfig "sparseth/internal/config"
+	"sparseth/storage/engine"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/mclock"
 	"github.com/ethereum/go-ethereum/params"
 )
 
+// BlockRange is an inclusive
+// range of block numbers.
+type BlockRange struct {
+	From uint64
+	To   uint64
+}
+
 // Config represents a collection of configuration
 // values required to initialize and run the node.
 type Config struct {
 	// ChainConfig specifies the Ethereum
 	// chain parameters to use.
 	ChainConfig *params.ChainConfig
-	// Checkpoint is the hash of the block
-	// to use as the starting point for the
+	// Checkpoint identifies the block to
+	// use as the starting point for the
 	// node, this may be the genesis block.
-	Checkpoint common.Hash
+	Checkpoint *config.Checkpoint
 	// AccountsConfig contains the configuration
 	// for all accounts to be monitored.
 	AccsConfig *config.AccountsConfig
-	// RpcURL specified the URL to use to connect
-	// to the Ethereum RPC provider.
-	RpcURL string
+	// Endpoints specifies the Ethereum RPC providers
+	// to connect to, in order of priority. Calls fail
+	// over to the next provider if a provider fails.
+	Endpoints []*ethclient.EndpointConfig
+	// BeaconURL specifies the Beacon API of a
+	// consensus client. If set, the node follows
+	// finalized blocks only, instead of the latest
+	// blocks of the RPC provider.
+	BeaconURL string
+	// BlobsURL specifies the Beacon API to fetch
+	// the blobs of relevant blob transactions
+	// from. If set, blobs are verified against
+	// their KZG commitments.
+	BlobsURL string
+	// Range specifies a range of past blocks to
+	// verify, see Node.VerifyRange. If nil, the
+	// node follows new blocks.
+	Range *BlockRange
 	// DbPath specifies the path to the database
 	// to use for persistent storage.
 	DbPath string
+	// DbEngine is the storage engine of the
+	// database, badger if not set.
+	DbEngine engine.Engine
+	// DbEncryptionKey is the key the database is
+	// encrypted with at rest, or nil if it is
+	// unencrypted, see engine.Options.
+	DbEncryptionKey []byte
+	// DbKeyRotation is the interval at which
+	// new data keys of an encrypted database
+	// are generated.
+	DbKeyRotation time.Duration
+	// DbEncoding is the encoding of newly stored
+	// logs and block digests, records of either
+	// encoding are read.
+	DbEncoding ethstore.Encoding
 	// IsEventMode indicates whether the node
 	// runs in event monitoring mode.
 	IsEventMode bool
+	// AllowModeFallback indicates whether the node
+	// runs in the other mode if the providers do
+	// not allow the methods of the configured mode.
+	AllowModeFallback bool
+	// ConfigPath specifies the path to the
+	// accounts config file.
+	ConfigPath string
+	// WatchConfig indicates whether the accounts
+	// config file is watched for changes, which
+	// are applied without restarting the node.
+	WatchConfig bool
+	// ABIResolver resolves the ABIs of accounts
+	// added or reloaded without an ABI path, or
+	// is nil if ABIs are not resolved.
+	ABIResolver *internalconfig.ABIResolver
+	// NameResolver resolves the ENS names of
+	// reloaded configs and admin API calls,
+	// names are rejected if nil.
+	NameResolver internalconfig.NameResolver
+	// Invariants specifies the predicates over the
+	// verified state evaluated after each block.
+	// Invariants require state monitoring mode.
+	Invariants []*config.Invariant
+	// ApiAddr specifies the address to serve the
+	// JSON-RPC API on, the API is disabled if empty.
+	ApiAddr string
+	// Tenants specifies the API keys accepted by the
+	// JSON-RPC API, each scoped to a subset of the
+	// monitored accounts. If empty, the API is
+	// served without authentication, except for the
+	// admin namespace, see AdminKey.
+	Tenants []*config.Tenant
+	// AdminKey is the API key required for the admin
+	// namespace if no tenants are configured. If empty,
+	// the admin namespace is only served to non-browser
+	// clients on the loopback interface.
+	AdminKey string
+	// ApiOrigins specifies the origins allowed to open
+	// WebSocket connections to the API, or "*" for any.
+	// If empty, only localhost is allowed. Clients
+	// without an Origin header, i.e., non-browser
+	// clients, are always allowed.
+	ApiOrigins []string
+	// MonitorConcurrency is the maximum number of
+	// blocks processed concurrently across all
+	// monitors.
+	MonitorConcurrency int
+	// MonitorQueuePolicy is the overflow policy of the
+	// queues of new blocks of the monitors. With
+	// bus.Block, the default, the slowest monitor holds
+	// back all others. With bus.DropOldest, monitors
+	// that fall behind skip to the latest blocks, and
+	// backfill the dropped ones from the store.
+	MonitorQueuePolicy bus.OverflowPolicy
+	// Confirmations specifies when a block is
+	// considered safe to be processed by the
+	// monitors.
+	Confirmations execution.Confirmations
+	// ProcessRetries is the number of retries of a
+	// block a monitor failed to process, before it
+	// is recorded as a dead letter.
+	ProcessRetries int
+	// CallBudget is the default maximum number of
+	// RPC calls per block spent on re-executing
+	// transactions of a single account, zero
+	// disables the budget.
+	CallBudget uint64
+	// ReadAllowlist specifies additional addresses
+	// whose uninitialized reads during re-execution
+	// are not verified.
+	ReadAllowlist []common.Address
+	// ReadAllowlistDefaults indicates whether the
+	// precompiles and system contracts of the chain
+	// are allowlisted, see ReadAllowlist.
+	ReadAllowlistDefaults bool
+	// ChecksumAddresses indicates whether addresses
+	// of reloaded configs and admin API calls must
+	// be in EIP-55 checksummed form.
+	ChecksumAddresses bool
+	// FetchParallelism is the maximum number of
+	// concurrent RPC calls to fetch the state
+	// required to re-execute a block.
+	FetchParallelism int
+	// AuditRate is the number of blocks per verified
+	// block in event mode: about one in AuditRate
+	// blocks is sampled to verify the hash chain
+	// head, see monitor.Sampler. At most one
+	// verifies every block.
+	AuditRate uint64
+	// AuditSeed is the secret seed of the
+	// pseudo-random sample of audited blocks.
+	AuditSeed common.Hash
+	// SnapshotBlocks is the number of most recent
+	// blocks for which the verified state of all
+	// monitored accounts is kept, zero disables
+	// snapshots.
+	SnapshotBlocks uint64
+	// PressureThreshold is the pressure, between
+	// zero and one, at which the node is considered
+	// under pressure, see Pressure.
+	PressureThreshold float64
+	// PressureWebhook specifies a URL to which
+	// pressure samples are posted whenever the
+	// pressure crosses the threshold, the webhook
+	// is disabled if empty.
+	PressureWebhook string
+	// ReportInterval is the length of the periods
+	// summarized by digest reports, e.g., a day,
+	// zero disables reports. Periods are aligned
+	// to multiples of the interval since the Unix
+	// epoch, i.e., daily reports end at midnight UTC.
+	ReportInterval time.Duration
+	// GCInterval is the interval at which the
+	// garbage of the database is collected, e.g.,
+	// the value log of badger, zero disables it.
+	GCInterval time.Duration
+	// GCDiscardRatio is the minimum ratio of
+	// discarded data in a file, between zero
+	// and one, for it to be rewritten.
+	GCDiscardRatio float64
+	// Peers specifies the API URLs of other nodes
+	// monitoring the same accounts, whose signed
+	// attestations are compared with those of the
+	// node. Requires a signer, see Node.SetSigner.
+	Peers []string
+	// PeerKey is the API key sent to all
+	// peers, or empty if not required.
+	PeerKey string
+	// ServeHeaders enables the headers namespace
+	// of the API, which serves the confirmed block
+	// headers to other instances.
+	ServeHeaders bool
+	// HeaderSource specifies the API URL of another
+	// instance serving its headers, from which block
+	// headers are fetched instead of the RPC provider.
+	HeaderSource string
+	// HeaderSourceKey is the API key sent to
+	// the header source, or empty if not required.
+	HeaderSourceKey string
+	// Clock is used for all time-dependent behavior,
+	// such as retries and stall detection. If nil,
+	// the system clock is used.
+	Clock mclock.Clock
 }
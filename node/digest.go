@@ -0,0 +1,44 @@
+package node
+
+import (
+	"context"
+	"sparseth/ethstore"
+)
+
+// startDigestRecorder logs and persists the digest
+// of each committed block, so that the digests of
+// two nodes with the same config can be compared
+// block by block, see StatsAPI.BlockDigest.
+//
+// Each commit is then published to the persisted
+// topic, so that subscribers can fill any gap in
+// the commits they received from the store.
+func (n *Node) startDigestRecorder(ctx context.Context) func() error {
+	return func() error {
+		store := ethstore.NewDigestStore(n.db, n.config.DbEncoding)
+
+		commits := n.events.Commits.Subscribe("digest-recorder")
+		for {
+			select {
+			case commit, ok := <-commits:
+				if !ok {
+					return nil
+				}
+
+				n.log.Info("block committed", "num", commit.Number, "hash", commit.Hash.Hex(), "verified", commit.Verified(), "digest", commit.Digest.Hex())
+				err := store.Put(&ethstore.BlockDigest{
+					Number:    commit.Number,
+					BlockHash: commit.Hash,
+					Digest:    commit.Digest,
+					Verified:  commit.Verified(),
+				})
+				if err != nil {
+					n.log.Warn("failed to persist block digest", "num", commit.Number, "err", err)
+				}
+				n.events.Persisted.Publish(commit)
+			case <-ctx.Done():
+				return nil
+			}
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package node
+
+import (
+	"context"
+	"sparseth/storage"
+	gosync "sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// GC summarizes the garbage collections of
+// the database since start, see Config.GCInterval.
+type GC struct {
+	// Enabled indicates whether the database is
+	// collected periodically, which is only the
+	// case for engines that need it, i.e., badger.
+	Enabled bool `json:"enabled"`
+	// Runs is the number of completed runs.
+	Runs hexutil.Uint64 `json:"runs"`
+	// Failures is the number of failed runs.
+	Failures hexutil.Uint64 `json:"failures"`
+	// Reclaimed is the total number
+	// of bytes reclaimed.
+	Reclaimed hexutil.Uint64 `json:"reclaimed"`
+	// LastReclaimed is the number of bytes
+	// reclaimed by the most recent run.
+	LastReclaimed hexutil.Uint64 `json:"lastReclaimed"`
+	// LastRun is the time of the most recent
+	// run, or nil if none completed yet.
+	LastRun *time.Time `json:"lastRun,omitempty"`
+	// LastDuration is the duration of
+	// the most recent run.
+	LastDuration string `json:"lastDuration,omitempty"`
+}
+
+// gcStats records the garbage
+// collections of the database.
+type gcStats struct {
+	gc GC
+	mu gosync.Mutex
+}
+
+// add records a run that took the specified
+// duration, and reclaimed the specified
+// number of bytes unless it failed.
+func (s *gcStats) add(at time.Time, took time.Duration, reclaimed uint64, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err != nil {
+		s.gc.Failures++
+		return
+	}
+	s.gc.Runs++
+	s.gc.Reclaimed += hexutil.Uint64(reclaimed)
+	s.gc.LastReclaimed = hexutil.Uint64(reclaimed)
+	s.gc.LastRun = &at
+	s.gc.LastDuration = took.String()
+}
+
+// snapshot returns a copy of the stats.
+func (s *gcStats) snapshot() *GC {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	gc := s.gc
+	return &gc
+}
+
+// startGarbageCollector periodically collects the
+// garbage of the specified database, i.e., rewrites
+// all files discarded by at least the configured
+// ratio, see StatsAPI.GC.
+func (n *Node) startGarbageCollector(ctx context.Context, db storage.KeyValCollector) func() error {
+	return func() error {
+		ticker := time.NewTicker(n.config.GCInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				start := time.Now()
+				reclaimed, err := db.CollectGarbage(n.config.GCDiscardRatio)
+				took := time.Since(start)
+				n.gc.add(start, took, reclaimed, err)
+				if err != nil {
+					n.log.Warn("failed to collect database garbage", "err", err)
+					continue
+				}
+				n.log.Debug("collected database garbage", "reclaimed", reclaimed, "took", took)
+			case <-ctx.Done():
+				return nil
+			}
+		}
+	}
+}
+
+// GC returns the garbage collections of the
+// database since start, see GC. As the database
+// is shared by all tenants, it is not available
+// to scoped tenants.
+func (api *StatsAPI) GC() (*GC, error) {
+	if api.tenant.Scoped() {
+		return nil, errForbidden
+	}
+
+	gc := api.n.gc.snapshot()
+	_, collectable := api.n.db.(storage.KeyValCollector)
+	gc.Enabled = collectable && api.n.config.GCInterval > 0
+	return gc, nil
+}
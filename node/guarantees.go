@@ -0,0 +1,105 @@
+package node
+
+import (
+	"fmt"
+	"sparseth/config"
+)
+
+// Verification guarantees that may be
+// active for a monitored account.
+const (
+	// GuaranteeStateProof indicates that the state
+	// of the account is proven against the state
+	// root of each block.
+	GuaranteeStateProof = "state-proof"
+	// GuaranteeReExecution indicates that all
+	// transactions touching the account are
+	// re-executed locally.
+	GuaranteeReExecution = "tx-reexecution"
+	// GuaranteeCounterCheck indicates that the
+	// interaction counter of the account is
+	// compared to the re-executed state.
+	GuaranteeCounterCheck = "counter-check"
+	// GuaranteeEventChain indicates that the events
+	// of the account are checked against its
+	// on-chain hash chain head.
+	GuaranteeEventChain = "event-hash-chain"
+	// GuaranteeBeaconHeaders indicates that block
+	// headers are taken from finalized blocks of
+	// a consensus client, not an RPC provider.
+	GuaranteeBeaconHeaders = "beacon-headers"
+	// GuaranteeBlobSidecars indicates that the
+	// blobs of re-executed blob transactions are
+	// verified against their KZG commitments.
+	GuaranteeBlobSidecars = "blob-sidecars"
+)
+
+// Trust levels of a monitored account.
+const (
+	// LevelFull indicates that all guarantees
+	// the account is configured for are active.
+	LevelFull = "full"
+	// LevelReduced indicates that some
+	// guarantees are currently inactive.
+	LevelReduced = "reduced"
+	// LevelNone indicates that the
+	// account is not verified at all.
+	LevelNone = "none"
+)
+
+// Guarantees describes the verification guarantees
+// currently active for a monitored account, so that
+// consumers know how much to trust its data.
+type Guarantees struct {
+	Level  string   `json:"level"`
+	Active []string `json:"active"`
+	// Degraded holds the reasons why guarantees
+	// are inactive, if any.
+	Degraded []string `json:"degraded,omitempty"`
+}
+
+// guarantees returns the verification guarantees
+// currently active for the specified account.
+func (n *Node) guarantees(acc *config.AccountConfig, proofOnly bool) *Guarantees {
+	g := &Guarantees{
+		Active: make([]string, 0),
+	}
+
+	if n.config.IsEventMode {
+		if acc.ContractConfig.HasEventConfig() {
+			g.Active = append(g.Active, GuaranteeStateProof, GuaranteeEventChain)
+		} else {
+			g.Degraded = append(g.Degraded, "not monitored: no event config in event mode")
+		}
+		if err := sparseModeAvailable(n.pool.Supports); err != nil {
+			g.Degraded = append(g.Degraded, fmt.Sprintf("tx re-execution unavailable: %v", err))
+		}
+	} else {
+		g.Active = append(g.Active, GuaranteeStateProof)
+		if proofOnly {
+			g.Degraded = append(g.Degraded, "tx re-execution disabled: RPC budget exceeded")
+		} else {
+			g.Active = append(g.Active, GuaranteeReExecution)
+			if n.config.BlobsURL != "" {
+				g.Active = append(g.Active, GuaranteeBlobSidecars)
+			}
+			if acc.ContractConfig.HasSparseConfig() {
+				g.Active = append(g.Active, GuaranteeCounterCheck)
+			}
+		}
+	}
+
+	if len(g.Active) > 0 && n.config.BeaconURL != "" {
+		g.Active = append(g.Active, GuaranteeBeaconHeaders)
+	}
+
+	switch {
+	case len(g.Active) == 0:
+		g.Level = LevelNone
+	case len(g.Degraded) > 0:
+		g.Level = LevelReduced
+	default:
+		g.Level = LevelFull
+	}
+	return g
+}
@@ -0,0 +1,105 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sparseth/bus"
+	"sparseth/ethstore"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// errNotConfirmed is returned by the headers API
+// for blocks that are not yet confirmed.
+var errNotConfirmed = errors.New("block not yet confirmed")
+
+// HeadersAPI provides the headers_ JSON-RPC
+// namespace, which serves the confirmed block
+// headers of the node to other instances, see
+// sync.PeerClient.
+type HeadersAPI struct {
+	n     *Node
+	store *ethstore.HeaderStore
+}
+
+// newHeadersAPI creates a new
+// HeadersAPI for the specified node.
+func newHeadersAPI(n *Node) *HeadersAPI {
+	return &HeadersAPI{
+		n:     n,
+		store: ethstore.NewHeaderStore(n.db),
+	}
+}
+
+// Latest returns the header of the
+// last confirmed block.
+func (api *HeadersAPI) Latest() (*types.Header, error) {
+	num := api.n.listener.Published()
+	if num == 0 {
+		return nil, errNotConfirmed
+	}
+	return api.store.GetByNumber(num)
+}
+
+// GetByNumber returns the header of the
+// confirmed block with the specified number.
+func (api *HeadersAPI) GetByNumber(num hexutil.Uint64) (*types.Header, error) {
+	if uint64(num) > api.n.listener.Published() {
+		return nil, fmt.Errorf("%w: %d", errNotConfirmed, uint64(num))
+	}
+	return api.store.GetByNumber(uint64(num))
+}
+
+// GetByHash returns the header of the
+// block with the specified hash, e.g.,
+// the checkpoint block.
+func (api *HeadersAPI) GetByHash(hash common.Hash) (*types.Header, error) {
+	header, err := api.store.GetByHash(hash)
+	if err != nil {
+		return nil, err
+	}
+	if header.Number.Uint64() > api.n.listener.Published() {
+		return nil, fmt.Errorf("%w: %d", errNotConfirmed, header.Number.Uint64())
+	}
+	return header, nil
+}
+
+// NewHeads subscribes to the headers of all
+// confirmed blocks, in order. Once the client
+// falls behind, the oldest headers are dropped,
+// which the client backfills by number.
+func (api *HeadersAPI) NewHeads(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return nil, rpc.ErrNotificationsUnsupported
+	}
+
+	sub := notifier.CreateSubscription()
+	id := "headers-subscription-" + string(sub.ID)
+	heads := api.n.events.Headers.SubscribeWith(id, bus.Options{Policy: bus.DropOldest})
+
+	go func() {
+		defer api.n.events.Headers.Unsubscribe(id)
+
+		for {
+			select {
+			case head, ok := <-heads:
+				if !ok {
+					return
+				}
+				if err := notifier.Notify(sub.ID, head); err != nil {
+					api.n.log.Debug("failed to send block header", "subscription", sub.ID, "err", err)
+					return
+				}
+			case <-sub.Err():
+				return
+			}
+		}
+	}()
+
+	return sub, nil
+}
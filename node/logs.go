@@ -0,0 +1,284 @@
+package node
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sparseth/config"
+	"sparseth/ethstore"
+	"sparseth/storage"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	// maxLogRange is the maximum number of
+	// blocks queried by a single call.
+	maxLogRange = 10_000
+	// maxLogs is the maximum number of logs
+	// returned by a single call.
+	maxLogs = 10_000
+)
+
+var (
+	// errInvalidLogRange is returned if the
+	// range of a log filter is empty.
+	errInvalidLogRange = errors.New("invalid block range")
+	// errLogRangeTooLarge is returned if the range
+	// of a log filter exceeds maxLogRange blocks.
+	errLogRangeTooLarge = fmt.Errorf("block range exceeds %d blocks", maxLogRange)
+	// errTooManyLogs is returned if a log
+	// filter matches more than maxLogs logs.
+	errTooManyLogs = fmt.Errorf("query returned more than %d logs", maxLogs)
+)
+
+// EthAPI provides the eth_ JSON-RPC namespace,
+// which serves the verified logs of the event
+// monitors, and proofs of the world state, so
+// that dapps and light clients can query them
+// instead of an untrusted provider.
+type EthAPI struct {
+	n       *Node
+	headers *ethstore.HeaderStore
+	// tenant is the API consumer the API
+	// is served to, or nil if unrestricted.
+	tenant *config.Tenant
+}
+
+// LogFilter is the filter of eth_getLogs,
+// see https://ethereum.org/developers/docs/apis/json-rpc#eth_getlogs.
+type LogFilter struct {
+	BlockHash *common.Hash
+	FromBlock *rpc.BlockNumber
+	ToBlock   *rpc.BlockNumber
+	// Addresses are the emitting accounts,
+	// or empty for all accounts.
+	Addresses []common.Address
+	// Topics are the alternatives of each
+	// topic position, where an empty position
+	// matches any topic.
+	Topics [][]common.Hash
+}
+
+// UnmarshalJSON decodes the specified filter,
+// where the address and each topic position
+// is either a single value, a list, or null.
+func (f *LogFilter) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		BlockHash *common.Hash     `json:"blockHash"`
+		FromBlock *rpc.BlockNumber `json:"fromBlock"`
+		ToBlock   *rpc.BlockNumber `json:"toBlock"`
+		Address   json.RawMessage  `json:"address"`
+		Topics    []any            `json:"topics"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if raw.BlockHash != nil && (raw.FromBlock != nil || raw.ToBlock != nil) {
+		return errors.New("blockHash excludes fromBlock and toBlock")
+	}
+
+	*f = LogFilter{
+		BlockHash: raw.BlockHash,
+		FromBlock: raw.FromBlock,
+		ToBlock:   raw.ToBlock,
+	}
+	if len(raw.Address) > 0 && string(raw.Address) != "null" {
+		var addr common.Address
+		if err := json.Unmarshal(raw.Address, &addr); err == nil {
+			f.Addresses = []common.Address{addr}
+		} else if err = json.Unmarshal(raw.Address, &f.Addresses); err != nil {
+			return fmt.Errorf("invalid address: %w", err)
+		}
+	}
+
+	f.Topics = make([][]common.Hash, len(raw.Topics))
+	for i, topic := range raw.Topics {
+		switch t := topic.(type) {
+		case nil:
+			// Matches any topic
+		case string:
+			hash, err := parseTopic(t)
+			if err != nil {
+				return err
+			}
+			f.Topics[i] = []common.Hash{hash}
+		case []any:
+			for _, alt := range t {
+				s, ok := alt.(string)
+				if !ok {
+					return fmt.Errorf("invalid topic at position %d", i)
+				}
+				hash, err := parseTopic(s)
+				if err != nil {
+					return err
+				}
+				f.Topics[i] = append(f.Topics[i], hash)
+			}
+		default:
+			return fmt.Errorf("invalid topic at position %d", i)
+		}
+	}
+	return nil
+}
+
+// parseTopic parses the
+// specified hex topic.
+func parseTopic(s string) (common.Hash, error) {
+	var hash common.Hash
+	if err := hash.UnmarshalText([]byte(s)); err != nil {
+		return common.Hash{}, fmt.Errorf("invalid topic %q: %w", s, err)
+	}
+	return hash, nil
+}
+
+// newEthAPI creates a new EthAPI for the
+// specified node, as seen by the specified
+// tenant. A nil tenant is unrestricted.
+func newEthAPI(n *Node, tenant *config.Tenant) *EthAPI {
+	return &EthAPI{
+		n:       n,
+		headers: ethstore.NewHeaderStore(n.db),
+		tenant:  tenant,
+	}
+}
+
+// GetLogs returns the verified logs matching the
+// specified filter, ordered by block and index.
+//
+// Only logs of accounts with an event monitor are
+// known, and only up to the last committed block,
+// which "latest", "safe", and "finalized" refer
+// to. Scoped tenants only see their accounts.
+//
+// All logs are read from a single snapshot of the
+// database, so that blocks committed meanwhile,
+// including reorgs, are not partially seen.
+func (api *EthAPI) GetLogs(filter LogFilter) ([]*types.Log, error) {
+	logs := make([]*types.Log, 0)
+
+	// Committed before the snapshot is taken
+	latest := api.n.barrier.Latest()
+	if latest == nil {
+		return logs, nil
+	}
+
+	snap, err := api.n.db.Snapshot()
+	if err != nil {
+		return nil, fmt.Errorf("failed to snapshot database: %w", err)
+	}
+	db := storage.NewSnapshotStore(snap)
+	defer db.Close()
+	var (
+		headers    = ethstore.NewHeaderStore(db)
+		chainHeads = ethstore.NewChainHeadStore(db)
+		events     = ethstore.NewEventStore(db, api.n.config.DbEncoding)
+	)
+
+	from, to := latest.Number, latest.Number
+	if filter.BlockHash != nil {
+		header, err := headers.GetByHash(*filter.BlockHash)
+		if errors.Is(err, ethstore.ErrHeaderNotFound) {
+			return logs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		from, to = header.Number.Uint64(), header.Number.Uint64()
+	}
+	if filter.FromBlock != nil {
+		from = resolveBlockNumber(*filter.FromBlock, latest.Number)
+	}
+	if filter.ToBlock != nil {
+		to = resolveBlockNumber(*filter.ToBlock, latest.Number)
+	}
+	// Later blocks are not yet verified
+	to = min(to, latest.Number)
+	if from > to {
+		if from > latest.Number {
+			return logs, nil
+		}
+		return nil, errInvalidLogRange
+	}
+	if to-from >= maxLogRange {
+		return nil, errLogRangeTooLarge
+	}
+
+	for addr := range eventAccounts(api.n.accounts()) {
+		if !api.tenant.Allows(addr) {
+			continue
+		}
+		if len(filter.Addresses) > 0 && !slices.Contains(filter.Addresses, addr) {
+			continue
+		}
+
+		heads, err := chainHeads.Range(addr, from, to)
+		if err != nil {
+			return nil, err
+		}
+		for _, head := range heads {
+			if filter.BlockHash != nil && head.BlockHash != *filter.BlockHash {
+				continue
+			}
+			for _, id := range head.Logs {
+				log, err := events.GetLog(id.TxHash, uint(id.Index))
+				if err != nil {
+					return nil, fmt.Errorf("failed to get log %s/%d: %w", id.TxHash.Hex(), id.Index, err)
+				}
+				// Metadata is not kept by all encodings
+				log.Address = addr
+				log.BlockNumber = id.BlockNumber
+				log.BlockHash = head.BlockHash
+				log.TxHash = id.TxHash
+				log.Index = uint(id.Index)
+
+				if !matchTopics(log, filter.Topics) {
+					continue
+				}
+				if len(logs) == maxLogs {
+					return nil, errTooManyLogs
+				}
+				logs = append(logs, log)
+			}
+		}
+	}
+
+	slices.SortFunc(logs, func(a, b *types.Log) int {
+		if a.BlockNumber != b.BlockNumber {
+			return cmp.Compare(a.BlockNumber, b.BlockNumber)
+		}
+		return cmp.Compare(a.Index, b.Index)
+	})
+	return logs, nil
+}
+
+// resolveBlockNumber resolves the specified
+// block number, where all tags except for
+// "earliest" refer to the latest block.
+func resolveBlockNumber(num rpc.BlockNumber, latest uint64) uint64 {
+	if num == rpc.EarliestBlockNumber {
+		return 0
+	}
+	if num < 0 {
+		return latest
+	}
+	return uint64(num)
+}
+
+// matchTopics checks whether the specified
+// log matches the specified topic filter.
+func matchTopics(log *types.Log, topics [][]common.Hash) bool {
+	if len(topics) > len(log.Topics) {
+		return false
+	}
+	for i, alts := range topics {
+		if len(alts) > 0 && !slices.Contains(alts, log.Topics[i]) {
+			return false
+		}
+	}
+	return true
+}
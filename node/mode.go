@@ -0,0 +1,112 @@
+package node
+
+import (
+	"fmt"
+	"sparseth/config"
+	"sparseth/log"
+	"strings"
+)
+
+var (
+	// sparseModeMethods are the RPC methods
+	// required to run the node in sparse mode.
+	sparseModeMethods = []string{
+		"eth_getBlockByNumber",
+		"eth_getProof",
+		"eth_getCode",
+	}
+	// traceMethods are the RPC methods that trace
+	// transactions in sparse mode, of which at least
+	// one is required. Blocks are traced with a single
+	// call, falling back to one call per transaction.
+	traceMethods = []string{
+		"debug_traceBlockByNumber",
+		"debug_traceTransaction",
+	}
+	// eventModeMethods are the RPC methods
+	// required to run the node in event mode.
+	eventModeMethods = []string{
+		"eth_getLogs",
+		"eth_getProof",
+	}
+)
+
+// selectMode selects the execution mode of the node
+// based on the RPC methods supported by the providers.
+//
+// The configured mode is preferred. If it requires a
+// method that no provider allows, the node only falls
+// back to the other mode if the fallback is allowed,
+// and that mode covers all monitored accounts.
+// Otherwise, an error explains why the configured
+// mode is not available.
+func selectMode(cfg *Config, supports func(string) bool, log log.Logger) (bool, error) {
+	sparseErr := sparseModeAvailable(supports)
+	eventErr := eventModeAvailable(cfg.AccsConfig, supports)
+
+	preferred, fallback := sparseErr, eventErr
+	if cfg.IsEventMode {
+		preferred, fallback = eventErr, sparseErr
+	}
+
+	switch {
+	case preferred == nil:
+		return cfg.IsEventMode, nil
+	case fallback == nil && cfg.AllowModeFallback:
+		log.Warn("configured mode unavailable, fall back", "eventMode", !cfg.IsEventMode, "reason", preferred)
+		return !cfg.IsEventMode, nil
+	case fallback == nil:
+		return false, fmt.Errorf("configured mode unavailable, fallback to %s not allowed: %w", modeName(!cfg.IsEventMode), preferred)
+	default:
+		return false, fmt.Errorf("no mode available: sparse mode: %v, event mode: %v", sparseErr, eventErr)
+	}
+}
+
+// sparseModeAvailable checks whether the node
+// can run in sparse mode.
+func sparseModeAvailable(supports func(string) bool) error {
+	if err := requireMethods(sparseModeMethods, supports); err != nil {
+		return err
+	}
+	for _, method := range traceMethods {
+		if supports(method) {
+			return nil
+		}
+	}
+	return fmt.Errorf("neither of methods %s allowed by any RPC provider", strings.Join(traceMethods, ", "))
+}
+
+// eventModeAvailable checks whether the node can
+// run in event mode for the specified accounts.
+func eventModeAvailable(accs *config.AccountsConfig, supports func(string) bool) error {
+	if err := requireMethods(eventModeMethods, supports); err != nil {
+		return err
+	}
+
+	for _, acc := range accs.Accounts {
+		if !acc.ContractConfig.HasEventConfig() {
+			return fmt.Errorf("account %s has no event config", acc.Addr.Hex())
+		}
+	}
+	return nil
+}
+
+// requireMethods checks whether all
+// specified methods are supported.
+func requireMethods(methods []string, supports func(string) bool) error {
+	for _, method := range methods {
+		if !supports(method) {
+			return fmt.Errorf("method %s not allowed by any RPC provider", method)
+		}
+	}
+	return nil
+}
+
+// modeName returns the name of event
+// mode or sparse mode, respectively.
+func modeName(eventMode bool) string {
+	if eventMode {
+		return "event mode"
+	}
+	return "sparse mode"
+}
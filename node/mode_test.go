@@ -0,0 +1,60 @@
+package node
+
+import (
+	"log/slog"
+	"sparseth/config"
+	internallog "sparseth/internal/log"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestSelectMode(t *testing.T) {
+	logger := internallog.New(slog.DiscardHandler)
+	accs := &config.AccountsConfig{
+		Accounts: []*config.AccountConfig{{
+			Addr:           common.HexToAddress("0x01"),
+			ContractConfig: &config.ContractConfig{Event: &config.EventConfig{}},
+		}},
+	}
+
+	// allowing returns a probe that allows
+	// all methods except the specified ones
+	allowing := func(denied ...string) func(string) bool {
+		return func(method string) bool {
+			for _, d := range denied {
+				if method == d {
+					return false
+				}
+			}
+			return true
+		}
+	}
+
+	t.Run("should run sparse mode with block traces only", func(t *testing.T) {
+		eventMode, err := selectMode(&Config{AccsConfig: accs}, allowing("debug_traceTransaction"), logger)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if eventMode {
+			t.Errorf("expected sparse mode")
+		}
+	})
+
+	t.Run("should fail without any trace method", func(t *testing.T) {
+		_, err := selectMode(&Config{AccsConfig: accs}, allowing(traceMethods...), logger)
+		if err == nil {
+			t.Errorf("expected error, got nil")
+		}
+	})
+
+	t.Run("should fall back only if allowed", func(t *testing.T) {
+		eventMode, err := selectMode(&Config{AccsConfig: accs, AllowModeFallback: true}, allowing(traceMethods...), logger)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if !eventMode {
+			t.Errorf("expected event mode")
+		}
+	})
+}
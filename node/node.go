@@ -3,57 +3,237 @@ package node
 import (
 	"context"
 	"fmt"
-	"math/big"
+	"io"
+	"reflect"
+	"sparseth/attest"
+	"sparseth/bus"
 	"sparseth/config"
+	"sparseth/ethstore"
 	"sparseth/execution"
 	"sparseth/execution/ethclient"
 	"sparseth/execution/monitor"
 	"sparseth/execution/monitor/event"
 	"sparseth/execution/monitor/state"
+	"sparseth/hook"
+	internalconfig "sparseth/internal/config"
 	"sparseth/log"
+	"sparseth/notify"
+	"sparseth/sink"
 	"sparseth/storage"
-	"sparseth/storage/badger"
+	"sparseth/storage/engine"
 	"sparseth/sync"
+	gosync "sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/mclock"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/rpc"
 	"golang.org/x/sync/errgroup"
 )
 
+// configWatchInterval is the interval at which
+// the accounts config file is checked for changes.
+const configWatchInterval = 5 * time.Second
+
+// txMonitorName is the name of the transaction
+// monitor, which covers all accounts.
+const txMonitorName = "transaction"
+
 // Node is the coordinator of the node's
 // various subsystems, such as the consensus
 // client, block listener and monitors.
 type Node struct {
 	config *Config
-	disp   *execution.Dispatcher
+	events *bus.Bus
 	db     storage.KeyValStore
-	rpc    *rpc.Client
+	pool   *ethclient.Pool
 	log    log.Logger
+
+	// monitors holds the cancel functions of
+	// all running event monitors by account.
+	monitors   map[common.Address]context.CancelFunc
+	monitorsMu gosync.Mutex
+	// txProc is the transaction processor, which
+	// is only set if the node runs in sparse mode.
+	txProc *state.TxProcessor
+	// sched bounds concurrent block
+	// processing across all monitors.
+	sched *monitor.Scheduler
+	// barrier commits blocks once all
+	// monitors have processed them.
+	barrier *monitor.Barrier
+	// listener publishes confirmed blocks
+	// to the monitors, once started.
+	listener *execution.Listener
+	// sinks receive all committed blocks,
+	// see AddSink.
+	sinks []sink.Sink
+	// hooks check all blocks before
+	// they are committed, see AddHook.
+	hooks []hook.Hook
+	// notifiers receive all alerts,
+	// see AddNotifier.
+	notifiers []notify.Notifier
+	// signer signs attestations of all
+	// verified roots, see SetSigner.
+	signer attest.Signer
+	// federation compares attestations with
+	// the peers, if started, see Config.Peers.
+	federation *attest.Federation
+	// updates receives new sets of monitored
+	// accounts, which are applied in order.
+	updates chan *config.AccountsConfig
+	// pressure holds the most recent
+	// pressure sample, see Pressure.
+	pressure atomic.Pointer[Pressure]
+	// verifications counts the published
+	// verifications by kind.
+	verifications verificationCounts
+	// gc records the garbage collections
+	// of the database, see StatsAPI.GC.
+	gc gcStats
+	// started is the time the
+	// node was created at.
+	started mclock.AbsTime
+	mu      gosync.Mutex
 }
 
 // NewNode initializes a new Node instance
 // with the provided configuration.
-func NewNode(ctx context.Context, config *Config, log log.Logger) (*Node, error) {
-	conn, err := rpc.DialContext(ctx, config.RpcURL)
+func NewNode(ctx context.Context, cfg *Config, log log.Logger) (*Node, error) {
+	pool, err := ethclient.DialPool(ctx, cfg.Endpoints, cfg.Clock, log)
 	if err != nil {
 		return nil, fmt.Errorf("could not connect to RPC provider: %w", err)
 	}
 
-	db, err := badger.New(config.DbPath)
+	eventMode, err := selectMode(cfg, pool.Supports, log)
+	if err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("could not select mode: %w", err)
+	}
+	selected := *cfg
+	selected.IsEventMode = eventMode
+	if cfg.Range != nil && cfg.Confirmations != (execution.Confirmations{}) {
+		// Past blocks are confirmed already, and held
+		// back blocks would never be processed
+		log.Warn("confirmations are ignored when verifying a block range")
+		selected.Confirmations = execution.Confirmations{}
+	}
+
+	if cfg.AuditRate > 1 {
+		if eventMode {
+			log.Info("audit sampling enabled", "rate", cfg.AuditRate, "commitment", monitor.NewSampler(cfg.AuditRate, cfg.AuditSeed).Commitment().Hex())
+		} else {
+			log.Warn("audit sampling is ignored in sparse mode, as every block must be re-executed")
+		}
+	}
+
+	dbEngine := cfg.DbEngine
+	if dbEngine == "" {
+		dbEngine = engine.Badger
+	}
+	db, err := engine.OpenWith(dbEngine, cfg.DbPath, engine.Options{
+		EncryptionKey: cfg.DbEncryptionKey,
+		KeyRotation:   cfg.DbKeyRotation,
+	})
 	if err != nil {
-		conn.Close()
+		pool.Close()
 		return nil, fmt.Errorf("could not open database: %w", err)
 	}
 
-	disp := execution.NewDispatcher(log)
+	events := bus.New(log)
+	// Results are never dropped, as a block is
+	// only committed once all monitors are done
+	results := events.Results.SubscribeWith("barrier", bus.Options{Policy: bus.Block})
+	barrier := monitor.NewBarrier(results, events.Commits, log)
 
-	return &Node{
-		config: config,
-		disp:   disp,
-		db:     db,
-		rpc:    conn,
-		log:    log.With("component", "node"),
-	}, nil
+	n := &Node{
+		config:   &selected,
+		events:   events,
+		db:       db,
+		pool:     pool,
+		log:      log.With("component", "node"),
+		monitors: make(map[common.Address]context.CancelFunc),
+		sched:    monitor.NewScheduler(cfg.MonitorConcurrency),
+		barrier:  barrier,
+		updates:  make(chan *config.AccountsConfig),
+	}
+	n.started = n.clock().Now()
+	return n, nil
+}
+
+// clock returns the configured clock, or the
+// system clock if none is configured.
+func (n *Node) clock() mclock.Clock {
+	if n.config.Clock == nil {
+		return mclock.System{}
+	}
+	return n.config.Clock
+}
+
+// Events returns the event bus of the node,
+// e.g., to subscribe to verification results.
+func (n *Node) Events() *bus.Bus {
+	return n.events
+}
+
+// AddSink registers the specified sink, which
+// receives every committed block exactly once,
+// see sink.Sink. Sinks must be added before
+// the node is started.
+func (n *Node) AddSink(s sink.Sink) {
+	n.sinks = append(n.sinks, s)
+}
+
+// AddPublisher registers a sink that publishes
+// every verified event and per-block state diff
+// of the monitored accounts via the specified
+// publisher, see sink.StreamSink. Publishers
+// must be added before the node is started.
+func (n *Node) AddPublisher(pub sink.Publisher) {
+	n.AddSink(sink.NewStreamSink(pub, sink.NewLoader(n.db, n.accountAddrs)))
+}
+
+// AddSQLite registers a sink that writes every
+// verified decoded event and the balances and
+// nonces of the monitored accounts into the
+// SQLite database at the specified path, see
+// sink.SQLite. Sinks must be added before the
+// node is started.
+func (n *Node) AddSQLite(path string) error {
+	s, err := sink.NewSQLite(path, sink.NewLoader(n.db, n.accountAddrs), n.decodeLog)
+	if err != nil {
+		return err
+	}
+	n.AddSink(s)
+	return nil
+}
+
+// AddHook registers the specified hook, which
+// checks every block before it is committed,
+// and may veto the block, see hook.Hook. Hooks
+// must be added before the node is started.
+func (n *Node) AddHook(h hook.Hook) {
+	n.hooks = append(n.hooks, h)
+}
+
+// AddNotifier registers the specified notifier,
+// which receives every alert, e.g., of a failed
+// verification, see notify.Notifier. Notifiers
+// must be added before the node is started.
+func (n *Node) AddNotifier(notifier notify.Notifier) {
+	n.notifiers = append(n.notifiers, notifier)
+}
+
+// SetSigner sets the signer of attestations,
+// which are produced for every verified storage
+// root or event hash chain head of a monitored
+// account, see attest.Attester. The signer must
+// be set before the node is started.
+func (n *Node) SetSigner(signer attest.Signer) {
+	n.signer = signer
 }
 
 // Start launches the consensus and
@@ -61,22 +241,143 @@ func NewNode(ctx context.Context, config *Config, log log.Logger) (*Node, error)
 func (n *Node) Start(ctx context.Context) error {
 	g, ctx := errgroup.WithContext(ctx)
 
-	consensus, pipe := sync.NewMockClient(n.log, n.rpc, n.config.Checkpoint, n.db)
-	listener := execution.NewListener(pipe, n.disp, n.log)
-	ec := ethclient.NewClient(n.rpc)
+	consensus, pipe, err := n.newConsensusClient(ctx)
+	if err != nil {
+		return err
+	}
+	ec := ethclient.NewClient(n.pool)
+	listener := execution.NewListener(pipe, ec, n.db, n.events.Headers, n.events.Upcoming, n.config.Confirmations, n.log)
+	n.listener = listener
 
 	if n.config.IsEventMode {
 		// Start up a single log monitor for each contract account
 		for _, acc := range n.config.AccsConfig.Accounts {
 			if acc.ContractConfig.HasEventConfig() {
-				n.log.Info("start event monitor", "account", acc.Addr.Hex())
-				g.Go(n.startEventMonitor(ctx, ec, acc))
+				n.startEventMonitor(ctx, g, ec, acc)
 			}
 		}
 	} else {
 		// Start up a single transaction monitor for all accounts
+		proc, err := state.NewTxProcessor(n.config.AccsConfig, n.config.ChainConfig, n.db, n.config.SnapshotBlocks, n.config.CallBudget, n.readAllowlist(), n.config.FetchParallelism, ec, n.blobProvider(), n.events.Alerts, n.events.Verifications, n.log)
+		if err != nil {
+			n.log.Error("failed to create transaction-processor", "err", err)
+			return fmt.Errorf("failed to create transaction-processor: %w", err)
+		}
+		if len(n.config.Invariants) > 0 {
+			proc.SetInvariants(monitor.NewInvariantChecker(n.config.Invariants, n.events.Alerts, n.log))
+		}
+		n.txProc = proc
+
 		n.log.Info("start transaction monitor")
-		g.Go(n.startTxMonitor(ctx, ec))
+		g.Go(n.startTxMonitor(ctx, proc))
+		g.Go(n.startPrefetcher(ctx, proc))
+	}
+
+	g.Go(n.applyAccountUpdates(ctx, g, ec))
+
+	if n.config.WatchConfig {
+		watcher, updates := internalconfig.NewWatcher(n.config.ConfigPath, configWatchInterval, n.config.ChecksumAddresses, n.log)
+		watcher.SetABIResolver(n.config.ABIResolver)
+		watcher.SetNameResolver(n.config.NameResolver)
+
+		n.log.Info("start config watcher")
+		g.Go(func() error {
+			return watcher.RunContext(ctx)
+		})
+		g.Go(n.forwardConfigUpdates(ctx, updates))
+	}
+
+	if n.signer != nil {
+		// Every verified root must be attested,
+		// so the processors wait for the attester
+		verifications := n.events.Verifications.SubscribeWith("attester", bus.Options{Policy: bus.Block})
+		attester := attest.NewAttester(verifications, n.signer, ethstore.NewAttestationStore(n.db), n.log)
+
+		n.log.Info("start attester", "signer", n.signer.Address().Hex())
+		g.Go(func() error {
+			defer n.events.Verifications.Unsubscribe("attester")
+			return attester.RunContext(ctx)
+		})
+	}
+
+	if n.signer != nil && len(n.config.Peers) > 0 {
+		commits := n.events.Persisted.Subscribe("federation")
+		n.federation = attest.NewFederation(commits, n.config.Peers, n.config.PeerKey, ethstore.NewAttestationStore(n.db), n.events.Alerts, n.log)
+
+		n.log.Info("start federation", "peers", len(n.config.Peers))
+		g.Go(func() error {
+			defer n.events.Persisted.Unsubscribe("federation")
+			return n.federation.RunContext(ctx)
+		})
+	}
+
+	if n.config.ApiAddr != "" {
+		n.log.Info("start API server", "addr", n.config.ApiAddr)
+		g.Go(n.startAPIServer(ctx))
+	}
+
+	if len(n.sinks) > 0 {
+		// Sinks must receive every committed block,
+		// so the barrier waits for the dispatcher
+		commits := n.events.Commits.SubscribeWith("sink-dispatcher", bus.Options{Policy: bus.Block})
+		dispatcher := sink.NewDispatcher(commits, ethstore.NewDeliveryLog(n.db), n.sinks, n.log)
+
+		n.log.Info("start sink dispatcher", "sinks", len(n.sinks))
+		g.Go(func() error {
+			defer n.events.Commits.Unsubscribe("sink-dispatcher")
+			return dispatcher.RunContext(ctx)
+		})
+	}
+
+	if len(n.notifiers) > 0 {
+		alerts := n.events.Alerts.Subscribe("alert-dispatcher")
+		dispatcher := notify.NewDispatcher(alerts, n.notifiers, n.log)
+
+		n.log.Info("start alert dispatcher", "notifiers", len(n.notifiers))
+		g.Go(func() error {
+			return dispatcher.RunContext(ctx)
+		})
+	}
+
+	if len(n.hooks) > 0 {
+		runner := hook.NewRunner(n.hooks, n.db, n.accountAddrs, n.events.Alerts, n.log)
+		n.barrier.SetCheck(runner.Check)
+		n.log.Info("use verification hooks", "hooks", len(n.hooks))
+	}
+
+	n.log.Info("start block barrier")
+	g.Go(func() error {
+		return n.barrier.RunContext(ctx)
+	})
+
+	n.log.Info("start block digest recorder")
+	g.Go(n.startDigestRecorder(ctx))
+
+	n.log.Info("start verification counter")
+	g.Go(n.startVerificationCounter(ctx))
+
+	n.log.Info("start provider trust tracker")
+	g.Go(n.startTrustTracker(ctx))
+
+	n.log.Info("start RPC health checks")
+	g.Go(func() error {
+		return n.pool.RunContext(ctx)
+	})
+
+	n.log.Info("start proxy monitor")
+	g.Go(n.startProxyMonitor(ctx, ec))
+
+	n.log.Info("start pressure monitor", "threshold", n.config.PressureThreshold)
+	g.Go(n.startPressureMonitor(ctx))
+
+	if collector, ok := n.db.(storage.KeyValCollector); ok && n.config.GCInterval > 0 {
+		n.log.Info("start database garbage collector", "interval", n.config.GCInterval, "ratio", n.config.GCDiscardRatio)
+		g.Go(n.startGarbageCollector(ctx, collector))
+	}
+
+	if n.config.ReportInterval > 0 {
+		n.log.Info("start digest reporter", "interval", n.config.ReportInterval)
+		g.Go(n.startReporter(ctx))
 	}
 
 	n.log.Info("start block listener")
@@ -97,24 +398,101 @@ func (n *Node) Start(ctx context.Context) error {
 func (n *Node) Shutdown() {
 	n.log.Info("shut down")
 
-	n.rpc.Close()
-	n.disp.Close()
+	n.pool.Close()
+	n.events.Close()
+	for _, s := range n.sinks {
+		if c, ok := s.(io.Closer); ok {
+			c.Close()
+		}
+	}
 	n.db.Close()
 }
 
-// startTxMonitor initializes and runs a transaction monitor.
-func (n *Node) startTxMonitor(ctx context.Context, ec *ethclient.Client) func() error {
-	return func() error {
-		proc, err := state.NewTxProcessor(n.config.AccsConfig, n.config.ChainConfig, n.db, ec, n.log)
+// consensusClient is the source
+// of new block headers.
+type consensusClient interface {
+	// RunContext publishes new block headers
+	// until the context is canceled.
+	RunContext(ctx context.Context) error
+}
+
+// readAllowlist creates the allowlist of addresses
+// whose uninitialized reads are not verified.
+func (n *Node) readAllowlist() *state.ReadAllowlist {
+	return state.NewReadAllowlist(n.config.ChainConfig, n.config.ReadAllowlistDefaults, n.config.ReadAllowlist)
+}
+
+// blobProvider returns the provider of the blobs
+// of relevant blob transactions, or nil if blobs
+// are not verified.
+func (n *Node) blobProvider() *ethclient.BlobProvider {
+	if n.config.BlobsURL == "" {
+		return nil
+	}
+	return ethclient.NewBlobProvider(n.config.BlobsURL)
+}
+
+// newConsensusClient creates the consensus client
+// of the node, which follows the confirmed blocks of
+// another instance, if configured, the finalized
+// blocks of the Beacon API, if configured, or the
+// latest blocks of the RPC provider otherwise.
+func (n *Node) newConsensusClient(ctx context.Context) (consensusClient, <-chan *types.Header, error) {
+	if n.config.HeaderSource != "" {
+		var opts []rpc.ClientOption
+		if n.config.HeaderSourceKey != "" {
+			opts = append(opts, rpc.WithHeader("Authorization", "Bearer "+n.config.HeaderSourceKey))
+		}
+		c, err := rpc.DialOptions(ctx, n.config.HeaderSource, opts...)
 		if err != nil {
-			n.log.Error("failed to create transaction-processor", "err", err)
-			return fmt.Errorf("failed to create transaction-processor: %w", err)
+			return nil, nil, fmt.Errorf("failed to connect to header source: %w", err)
+		}
+		context.AfterFunc(ctx, c.Close)
+
+		client, ch := sync.NewPeerClient(n.log, c, n.config.Checkpoint, n.db, n.clock(), n.events.Sync)
+		return client, ch, nil
+	}
+
+	// Subscriptions cannot fail over transparently,
+	// so the consensus client uses a single provider
+	if n.config.BeaconURL != "" {
+		client, ch := sync.NewBeaconClient(n.log, n.config.BeaconURL, n.pool.Primary(), n.config.Checkpoint, n.db, n.clock(), n.events.Sync)
+		return client, ch, nil
+	}
+	client, ch := sync.NewMockClient(n.log, n.pool.Primary(), n.config.Checkpoint, n.db, n.clock(), n.events.Sync)
+	return client, ch, nil
+}
+
+// startTxMonitor runs a transaction monitor
+// using the specified processor, preparing
+// each block ahead of processing. The world
+// state is bootstrapped first, see
+// bootstrapState.
+func (n *Node) startTxMonitor(ctx context.Context, proc *state.TxProcessor) func() error {
+	return func() error {
+		sub := n.events.Headers.SubscribeWith("transaction-monitor", n.monitorQueue())
+		n.barrier.Register(txMonitorName)
+
+		if err := n.bootstrapState(ctx, proc); err != nil {
+			n.log.Error("failed to bootstrap world state", "err", err)
+			return fmt.Errorf("failed to bootstrap world state: %w", err)
 		}
 
-		sub := n.disp.Subscribe("transaction-monitor")
-		mntr := monitor.NewMonitor("transaction", sub, proc, n.log)
+		// Blocks are prepared while their
+		// predecessor is being processed
+		pipeline := monitor.NewPipeline(sub, proc, n.log)
+		mntr := n.newMonitor(txMonitorName, pipeline.Out(), proc)
+		if n.config.Range == nil {
+			// Blocks replayed by the consensus client
+			// after a restart are not processed again
+			mntr.SetCursors(ethstore.NewCursorStore(n.db))
+		}
 
-		if err := mntr.RunContext(ctx); err != nil {
+		g, ctx := errgroup.WithContext(ctx)
+		g.Go(func() error { return pipeline.RunContext(ctx) })
+		g.Go(func() error { return mntr.RunContext(ctx) })
+
+		if err := g.Wait(); err != nil {
 			n.log.Error("failed to start transaction-monitor", "err", err)
 			return fmt.Errorf("failed to start transaction-monitor: %w", err)
 		}
@@ -123,20 +501,92 @@ func (n *Node) startTxMonitor(ctx context.Context, ec *ethclient.Client) func()
 	}
 }
 
-// startEventMonitor initializes and runs an event monitor
-// for a specific account.
-func (n *Node) startEventMonitor(ctx context.Context, ec *ethclient.Client, acc *config.AccountConfig) func() error {
+// newMonitor creates a new monitor with the
+// specified name, which processes the blocks
+// received on the specified channel with the
+// specified processor. Blocks that fail after
+// all retries are recorded as dead letters, and
+// halt the monitor until processed successfully.
+func (n *Node) newMonitor(name string, sub <-chan *types.Header, proc monitor.Processor) *monitor.Monitor {
+	mntr := monitor.NewMonitor(name, sub, proc, n.sched, n.events.Results, n.log)
+	mntr.SetRetries(n.config.ProcessRetries)
+	mntr.SetClock(n.clock())
+	mntr.SetDeadLetters(ethstore.NewDeadLetterStore(n.db))
+	if n.monitorQueue().Policy != bus.Block {
+		mntr.SetBackfill(ethstore.NewHeaderStore(n.db))
+	}
+	return mntr
+}
+
+// monitorQueue returns the options of the queues
+// of new blocks of the monitors, see
+// Config.MonitorQueuePolicy.
+func (n *Node) monitorQueue() bus.Options {
+	if n.config.MonitorQueuePolicy == "" {
+		return bus.Options{Policy: bus.Block}
+	}
+	return bus.Options{Policy: n.config.MonitorQueuePolicy}
+}
+
+// startPrefetcher downloads the data of held back
+// blocks shortly before they are dispatched, so
+// processing them is not delayed by downloads.
+func (n *Node) startPrefetcher(ctx context.Context, proc *state.TxProcessor) func() error {
 	return func() error {
-		info := &monitor.AccountInfo{
-			Addr:        acc.Addr,
-			ABI:         acc.ContractConfig.Event.ABI,
-			Slot:        acc.ContractConfig.Event.HeadSlot,
-			InitialHead: common.BigToHash(big.NewInt(0)),
+		upcoming := n.events.Upcoming.Subscribe("prefetcher")
+		for {
+			select {
+			case head, ok := <-upcoming:
+				if !ok {
+					return nil
+				}
+				if err := proc.Prefetch(ctx, head); err != nil {
+					// Prefetching is an optimization only,
+					// the block is downloaded when processed
+					n.log.Debug("failed to prefetch block", "num", head.Number, "hash", head.Hash().Hex(), "err", err)
+				}
+			case <-ctx.Done():
+				return nil
+			}
 		}
+	}
+}
+
+// startEventMonitor initializes an event monitor
+// for a specific account and runs it in the
+// specified group. The monitor runs until it
+// is stopped via stopEventMonitor, or the
+// context is canceled.
+func (n *Node) startEventMonitor(ctx context.Context, g *errgroup.Group, ec *ethclient.Client, acc *config.AccountConfig) {
+	n.log.Info("start event monitor", "account", acc.Addr.Hex())
+
+	ctx, cancel := context.WithCancel(ctx)
 
-		sub := n.disp.Subscribe(acc.Addr.Hex())
-		proc := event.NewLogProcessor(info, ec, n.db, n.log)
-		mntr := monitor.NewMonitor(acc.Addr.Hex()+"-event", sub, proc, n.log)
+	n.monitorsMu.Lock()
+	n.monitors[acc.Addr] = cancel
+	n.monitorsMu.Unlock()
+
+	info := &monitor.AccountInfo{
+		Addr:        acc.Addr,
+		ABI:         acc.ContractConfig.Event.ABI,
+		Slot:        acc.ContractConfig.Event.HeadSlot,
+		InitialHead: acc.ContractConfig.Event.InitialHead,
+		StartBlock:  acc.StartBlock,
+	}
+
+	name := eventMonitorName(acc.Addr)
+	sub := n.events.Headers.SubscribeWith(acc.Addr.Hex(), n.monitorQueue())
+	n.barrier.Register(name)
+	proc := event.NewLogProcessor(info, ec, n.db, n.config.DbEncoding, n.published, n.sampler(), n.events.Alerts, n.events.Verifications, n.log)
+	mntr := n.newMonitor(name, sub, proc)
+	if n.config.Range == nil {
+		// Blocks replayed by the consensus client
+		// after a restart are not processed again
+		mntr.SetCursors(ethstore.NewCursorStore(n.db))
+	}
+
+	g.Go(func() error {
+		defer cancel()
 
 		if err := mntr.RunContext(ctx); err != nil {
 			n.log.Error("failed to start event-monitor", "err", err, "account", acc.Addr.Hex())
@@ -144,7 +594,191 @@ func (n *Node) startEventMonitor(ctx context.Context, ec *ethclient.Client, acc
 		}
 
 		return nil
+	})
+}
+
+// published returns the number of the last
+// block published to the monitors, or zero if
+// the listener is not started.
+func (n *Node) published() uint64 {
+	if n.listener == nil {
+		return 0
+	}
+	return n.listener.Published()
+}
+
+// head returns the last block header received
+// from the consensus client, or nil if none was
+// received or the listener is not started.
+func (n *Node) head() *types.Header {
+	if n.listener == nil {
+		return nil
+	}
+	return n.listener.Head()
+}
+
+// stopEventMonitor stops the event monitor
+// for the specified account, if running.
+func (n *Node) stopEventMonitor(addr common.Address) {
+	n.monitorsMu.Lock()
+	cancel, exists := n.monitors[addr]
+	delete(n.monitors, addr)
+	n.monitorsMu.Unlock()
+
+	if !exists {
+		return
+	}
+
+	n.log.Info("stop event monitor", "account", addr.Hex())
+	cancel()
+	n.events.Headers.Unsubscribe(addr.Hex())
+	n.barrier.Deregister(eventMonitorName(addr))
+}
+
+// eventMonitorName returns the name of the
+// event monitor for the specified account.
+func eventMonitorName(addr common.Address) string {
+	return addr.Hex() + "-event"
+}
+
+// forwardConfigUpdates forwards all accounts configs
+// received from the specified channel to the node.
+func (n *Node) forwardConfigUpdates(ctx context.Context, updates <-chan *config.AccountsConfig) func() error {
+	return func() error {
+		for {
+			select {
+			case accs, ok := <-updates:
+				if !ok {
+					return nil
+				}
+				if err := n.setAccounts(ctx, func(*config.AccountsConfig) (*config.AccountsConfig, error) {
+					return accs, nil
+				}); err != nil {
+					return nil
+				}
+			case <-ctx.Done():
+				return nil
+			}
+		}
+	}
+}
+
+// setAccounts replaces the set of monitored accounts
+// with the result of the specified update function,
+// which receives the current set. If the update
+// function fails, the current set is retained.
+//
+// Updates are applied in the order in which they
+// are submitted, see applyAccountUpdates.
+func (n *Node) setAccounts(ctx context.Context, update func(*config.AccountsConfig) (*config.AccountsConfig, error)) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	accs, err := update(n.config.AccsConfig)
+	if err != nil {
+		return err
+	}
+
+	select {
+	case n.updates <- accs:
+		n.config.AccsConfig = accs
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// accounts returns the current set of
+// monitored accounts.
+func (n *Node) accounts() *config.AccountsConfig {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return n.config.AccsConfig
+}
+
+// accountAddrs returns the addresses
+// of all monitored accounts.
+func (n *Node) accountAddrs() []common.Address {
+	accs := n.accounts()
+
+	addrs := make([]common.Address, 0, len(accs.Accounts))
+	for _, acc := range accs.Accounts {
+		addrs = append(addrs, acc.Addr)
+	}
+	return addrs
+}
+
+// decodeLog decodes the specified log with
+// the ABI of the emitting account.
+func (n *Node) decodeLog(log *types.Log) (*event.Event, error) {
+	for _, acc := range n.accounts().Accounts {
+		if acc.Addr != log.Address || !acc.ContractConfig.HasEventConfig() {
+			continue
+		}
+		return event.NewLogVerifier(acc.ContractConfig.Event.ABI, common.Hash{}).Decode(log)
+	}
+	return nil, fmt.Errorf("no ABI for account %s", log.Address.Hex())
+}
+
+// applyAccountUpdates applies all submitted sets
+// of monitored accounts to the running monitors.
+func (n *Node) applyAccountUpdates(ctx context.Context, g *errgroup.Group, ec *ethclient.Client) func() error {
+	return func() error {
+		current := n.accounts()
+		for {
+			select {
+			case accs := <-n.updates:
+				n.updateAccounts(ctx, g, ec, current, accs)
+				current = accs
+			case <-ctx.Done():
+				return nil
+			}
+		}
+	}
+}
+
+// updateAccounts replaces the set of monitored
+// accounts. In event mode, monitors of removed
+// accounts are stopped, monitors of new accounts
+// are started, and monitors of changed accounts
+// are restarted. In sparse mode, the new set is
+// handed over to the transaction processor, which
+// applies it at the next block boundary.
+func (n *Node) updateAccounts(ctx context.Context, g *errgroup.Group, ec *ethclient.Client, prev, accs *config.AccountsConfig) {
+	n.log.Info("apply new accounts config", "accounts", len(accs.Accounts))
+
+	if !n.config.IsEventMode {
+		n.txProc.SetAccounts(accs)
+		return
+	}
+
+	current := eventAccounts(prev)
+	next := eventAccounts(accs)
+
+	for addr, acc := range current {
+		if upd, exists := next[addr]; !exists || !reflect.DeepEqual(acc.ContractConfig.Event, upd.ContractConfig.Event) {
+			n.stopEventMonitor(addr)
+		}
+	}
+
+	for addr, acc := range next {
+		if old, exists := current[addr]; !exists || !reflect.DeepEqual(acc.ContractConfig.Event, old.ContractConfig.Event) {
+			n.startEventMonitor(ctx, g, ec, acc)
+		}
+	}
+}
+
+// eventAccounts indexes all accounts with an
+// event config by their address.
+func eventAccounts(accs *config.AccountsConfig) map[common.Address]*config.AccountConfig {
+	indexed := make(map[common.Address]*config.AccountConfig)
+	for _, acc := range accs.Accounts {
+		if acc.ContractConfig.HasEventConfig() {
+			indexed[acc.Addr] = acc
+		}
 	}
+	return indexed
 }
 
 // startBlockListener runs the block listener.
@@ -159,7 +793,7 @@ func (n *Node) startBlockListener(ctx context.Context, l *execution.Listener) fu
 }
 
 // startConsensusClient runs the consensus client.
-func (n *Node) startConsensusClient(ctx context.Context, c *sync.MockClient) func() error {
+func (n *Node) startConsensusClient(ctx context.Context, c consensusClient) func() error {
 	return func() error {
 		if err := c.RunContext(ctx); err != nil {
 			n.log.Error("failed to start consensus client", "err", err)
@@ -0,0 +1,64 @@
+package node
+
+import (
+	"context"
+	"log/slog"
+	"sparseth/bus"
+	"sparseth/config"
+	"sparseth/execution/ethclient"
+	"sparseth/execution/monitor"
+	"sparseth/execution/monitor/state"
+	internallog "sparseth/internal/log"
+	"sparseth/storage/mem"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// newTestNode creates a node in sparse mode that
+// monitors the events of the specified accounts,
+// backed by an in-memory database, with a single
+// RPC provider that is never called.
+func newTestNode(t *testing.T, accs ...common.Address) *Node {
+	logger := internallog.New(slog.DiscardHandler)
+	db := mem.New()
+	t.Cleanup(func() {
+		db.Close()
+	})
+
+	accsConfig := &config.AccountsConfig{}
+	for _, addr := range accs {
+		accsConfig.Accounts = append(accsConfig.Accounts, &config.AccountConfig{
+			Addr:           addr,
+			ContractConfig: &config.ContractConfig{Event: &config.EventConfig{}},
+		})
+	}
+
+	endpoints := []*ethclient.EndpointConfig{{URL: "http://127.0.0.1:8545"}}
+	pool, err := ethclient.DialPool(t.Context(), endpoints, nil, logger)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	events := bus.New(logger)
+	txProc, err := state.NewTxProcessor(accsConfig, params.MainnetChainConfig, db, 128, 0, nil, 1, nil, nil, events.Alerts, events.Verifications, logger)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	return &Node{
+		config: &Config{
+			ChainConfig: params.MainnetChainConfig,
+			AccsConfig:  accsConfig,
+		},
+		events:   events,
+		db:       db,
+		pool:     pool,
+		log:      logger,
+		monitors: make(map[common.Address]context.CancelFunc),
+		txProc:   txProc,
+		sched:    monitor.NewScheduler(1),
+		barrier:  monitor.NewBarrier(events.Results.Subscribe("barrier"), events.Commits, logger),
+	}
+}
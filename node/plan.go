@@ -0,0 +1,140 @@
+package node
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"sparseth/config"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ConfigPlan describes the changes to the running
+// monitors that applying a new accounts config
+// would cause.
+type ConfigPlan struct {
+	Added     []*AccountChange `json:"added"`
+	Removed   []*AccountChange `json:"removed"`
+	Changed   []*AccountChange `json:"changed"`
+	Unchanged int              `json:"unchanged"`
+}
+
+// AccountChange describes the change of a single
+// monitored account, and the bootstrapping work
+// it triggers.
+type AccountChange struct {
+	Address config.ChecksumAddress `json:"address"`
+	// Monitors lists the monitors affected
+	// by the change, e.g., event or state.
+	Monitors []string `json:"monitors"`
+	// Work lists the bootstrapping work
+	// triggered by the change.
+	Work []string `json:"work,omitempty"`
+}
+
+// IsEmpty checks whether the plan
+// contains no changes.
+func (p *ConfigPlan) IsEmpty() bool {
+	return len(p.Added) == 0 && len(p.Removed) == 0 && len(p.Changed) == 0
+}
+
+// planAccounts computes the changes caused by
+// replacing the specified set of monitored
+// accounts with the specified new set.
+func (n *Node) planAccounts(prev, next *config.AccountsConfig) *ConfigPlan {
+	current := indexAccounts(prev)
+	updated := indexAccounts(next)
+
+	plan := &ConfigPlan{
+		Added:   make([]*AccountChange, 0),
+		Removed: make([]*AccountChange, 0),
+		Changed: make([]*AccountChange, 0),
+	}
+
+	for addr, acc := range current {
+		if _, exists := updated[addr]; !exists {
+			plan.Removed = append(plan.Removed, &AccountChange{
+				Address:  config.ChecksumAddress(addr),
+				Monitors: n.monitorsOf(acc),
+			})
+		}
+	}
+
+	for addr, acc := range updated {
+		old, exists := current[addr]
+		switch {
+		case !exists:
+			plan.Added = append(plan.Added, &AccountChange{
+				Address:  config.ChecksumAddress(addr),
+				Monitors: n.monitorsOf(acc),
+				Work:     n.bootstrapWork(acc),
+			})
+		case !reflect.DeepEqual(old, acc):
+			plan.Changed = append(plan.Changed, &AccountChange{
+				Address:  config.ChecksumAddress(addr),
+				Monitors: n.monitorsOf(acc),
+				Work:     n.bootstrapWork(acc),
+			})
+		default:
+			plan.Unchanged++
+		}
+	}
+
+	for _, changes := range [][]*AccountChange{plan.Added, plan.Removed, plan.Changed} {
+		sort.Slice(changes, func(i, j int) bool {
+			return changes[i].Address.Address().Cmp(changes[j].Address.Address()) < 0
+		})
+	}
+
+	return plan
+}
+
+// monitorsOf returns the monitors that
+// process the specified account.
+func (n *Node) monitorsOf(acc *config.AccountConfig) []string {
+	if !n.config.IsEventMode {
+		return []string{"state"}
+	}
+	if acc.ContractConfig.HasEventConfig() {
+		return []string{"event"}
+	}
+	return []string{}
+}
+
+// bootstrapWork describes the work needed
+// to start monitoring the specified account.
+func (n *Node) bootstrapWork(acc *config.AccountConfig) []string {
+	var work []string
+	if n.config.IsEventMode {
+		if !acc.ContractConfig.HasEventConfig() {
+			return []string{"none: account has no event config, not monitored in event mode"}
+		}
+		work = []string{
+			"start event monitor at next block",
+			"fetch logs and storage proof of event head slot per block",
+		}
+	} else {
+		work = []string{
+			"track account from next block boundary",
+			"fetch account proof per processed block",
+		}
+		if acc.ContractConfig.HasSparseConfig() {
+			work = append(work, "fetch storage proof of interaction counter per processed block")
+		}
+	}
+
+	if acc.StartBlock > 0 {
+		work = append(work, fmt.Sprintf("ignore blocks before start block %d", acc.StartBlock))
+	}
+	return work
+}
+
+// indexAccounts indexes all
+// accounts by their address.
+func indexAccounts(accs *config.AccountsConfig) map[common.Address]*config.AccountConfig {
+	indexed := make(map[common.Address]*config.AccountConfig, len(accs.Accounts))
+	for _, acc := range accs.Accounts {
+		indexed[acc.Addr] = acc
+	}
+	return indexed
+}
@@ -0,0 +1,166 @@
+package node
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+const (
+	// pressureInterval is the interval at
+	// which the pressure is sampled.
+	pressureInterval = 15 * time.Second
+	// pressureLagBlocks is the monitor lag, in
+	// blocks, that counts as full pressure.
+	pressureLagBlocks = 64
+	// pressureHysteresis is the margin below the
+	// threshold the pressure must drop to before
+	// it is considered low again, so that the
+	// webhook does not flap.
+	pressureHysteresis = 0.1
+	// webhookTimeout is the maximum
+	// duration of a webhook call.
+	webhookTimeout = 10 * time.Second
+)
+
+// Pressure is a normalized measure of how close
+// the node is to falling behind the chain, e.g.,
+// to drive autoscaling of RPC provider plans or
+// sharded deployments.
+type Pressure struct {
+	// Pressure is the maximum of all normalized
+	// signals, between zero (idle) and one.
+	Pressure float64 `json:"pressure"`
+	// Lag is the number of blocks published to
+	// the monitors but not yet committed.
+	Lag hexutil.Uint64 `json:"lag"`
+	// Queued is the number of monitors waiting
+	// for a slot to process a block.
+	Queued hexutil.Uint64 `json:"queued"`
+	// Saturation is the average share of the
+	// capacity of all RPC providers used.
+	Saturation float64 `json:"saturation"`
+	// High indicates that the pressure
+	// crossed the configured threshold.
+	High bool `json:"high"`
+}
+
+// samplePressure samples the current pressure,
+// given the number of the last block published
+// to the monitors.
+func (n *Node) samplePressure(head uint64) *Pressure {
+	var lag uint64
+	if latest := n.barrier.Latest(); latest != nil && head > latest.Number {
+		lag = head - latest.Number
+	}
+	queued := n.sched.Queued()
+	saturation := n.pool.Saturation()
+
+	pressure := max(
+		float64(lag)/pressureLagBlocks,
+		float64(queued)/float64(n.sched.Limit()),
+		saturation,
+	)
+
+	return &Pressure{
+		Pressure:   min(pressure, 1),
+		Lag:        hexutil.Uint64(lag),
+		Queued:     hexutil.Uint64(queued),
+		Saturation: saturation,
+	}
+}
+
+// startPressureMonitor periodically samples the
+// pressure, see StatsAPI.Pressure. Whenever the
+// pressure crosses the configured threshold, a
+// message is logged and, if configured, the
+// sample is posted to the webhook.
+func (n *Node) startPressureMonitor(ctx context.Context) func() error {
+	return func() error {
+		headers := n.events.Headers.Subscribe("pressure-monitor")
+		defer n.events.Headers.Unsubscribe("pressure-monitor")
+
+		ticker := time.NewTicker(pressureInterval)
+		defer ticker.Stop()
+
+		var head uint64
+		high := false
+		for {
+			select {
+			case header, ok := <-headers:
+				if !ok {
+					return nil
+				}
+				head = header.Number.Uint64()
+			case <-ticker.C:
+				sample := n.samplePressure(head)
+
+				crossed := false
+				switch {
+				case !high && sample.Pressure >= n.config.PressureThreshold:
+					high, crossed = true, true
+				case high && sample.Pressure < n.config.PressureThreshold-pressureHysteresis:
+					high, crossed = false, true
+				}
+				sample.High = high
+				n.pressure.Store(sample)
+
+				if !crossed {
+					continue
+				}
+				n.log.Info("pressure crossed threshold", "high", high, "pressure", sample.Pressure, "lag", uint64(sample.Lag), "queued", uint64(sample.Queued), "saturation", sample.Saturation)
+				if n.config.PressureWebhook == "" {
+					continue
+				}
+				if err := postWebhook(ctx, n.config.PressureWebhook, sample); err != nil {
+					n.log.Warn("failed to post pressure webhook", "err", err)
+				}
+			case <-ctx.Done():
+				return nil
+			}
+		}
+	}
+}
+
+// Pressure returns the most recent pressure
+// sample, see Pressure.
+func (api *StatsAPI) Pressure() *Pressure {
+	if sample := api.n.pressure.Load(); sample != nil {
+		return sample
+	}
+	return api.n.samplePressure(0)
+}
+
+// postWebhook posts the specified value as
+// JSON to the webhook at the specified URL.
+func postWebhook(ctx context.Context, url string, v any) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode body: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, webhookTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post webhook: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode/100 != 2 {
+		return fmt.Errorf("failed to post webhook: status %d", res.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package node
+
+import (
+	"errors"
+	"fmt"
+	"sparseth/execution/monitor/state"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// errStorageKeyTooLong is returned if
+// a storage key exceeds 32 bytes.
+var errStorageKeyTooLong = errors.New("storage key exceeds 32 bytes")
+
+// AccountProof is the result of eth_getProof,
+// along with the block and world state root
+// the proof is made against.
+type AccountProof struct {
+	BlockNumber  hexutil.Uint64  `json:"blockNumber"`
+	BlockHash    common.Hash     `json:"blockHash"`
+	StateRoot    common.Hash     `json:"stateRoot"`
+	Address      common.Address  `json:"address"`
+	AccountProof []hexutil.Bytes `json:"accountProof"`
+	Balance      *hexutil.Big    `json:"balance"`
+	CodeHash     common.Hash     `json:"codeHash"`
+	Nonce        hexutil.Uint64  `json:"nonce"`
+	StorageHash  common.Hash     `json:"storageHash"`
+	StorageProof []*StorageProof `json:"storageProof"`
+}
+
+// StorageProof is the proof of
+// a storage slot of an account.
+type StorageProof struct {
+	Key   common.Hash     `json:"key"`
+	Value *hexutil.Big    `json:"value"`
+	Proof []hexutil.Bytes `json:"proof"`
+}
+
+// GetProof returns the Merkle proof of the specified
+// monitored account and storage slots against the
+// world state of the node after the specified block.
+//
+// The world state only includes the monitored accounts,
+// so that light clients verify the proof against the
+// root of the node, e.g., an attested root, instead of
+// the state root of the block header. Scoped tenants
+// may only request their accounts.
+func (api *EthAPI) GetProof(addr common.Address, keys []hexutil.Bytes, block rpc.BlockNumberOrHash) (*AccountProof, error) {
+	if !api.tenant.Allows(addr) {
+		return nil, errForbidden
+	}
+	if api.n.txProc == nil {
+		return nil, errNoWorldState
+	}
+
+	slots := make([]common.Hash, len(keys))
+	for i, key := range keys {
+		if len(key) > common.HashLength {
+			return nil, fmt.Errorf("%w: %s", errStorageKeyTooLong, key)
+		}
+		slots[i] = common.BytesToHash(key)
+	}
+
+	latest := api.n.txProc.LatestRoot()
+	if latest == nil {
+		return nil, state.ErrNoVerifiedState
+	}
+	num := latest.Number
+	if n, ok := block.Number(); ok && n >= 0 {
+		num = uint64(n)
+	}
+	if hash, ok := block.Hash(); ok {
+		header, err := api.headers.GetByHash(hash)
+		if err != nil {
+			return nil, err
+		}
+		num = header.Number.Uint64()
+	}
+
+	proof, err := api.n.txProc.Proof(num, addr, slots)
+	if err != nil {
+		return nil, err
+	}
+	if hash, ok := block.Hash(); ok && proof.BlockHash != hash {
+		// World state of a reorged block
+		return nil, fmt.Errorf("block %s is not canonical", hash.Hex())
+	}
+
+	return toAccountProof(proof), nil
+}
+
+// toAccountProof converts the
+// specified account proof.
+func toAccountProof(proof *state.AccountProof) *AccountProof {
+	storage := make([]*StorageProof, len(proof.Storage))
+	for i, slot := range proof.Storage {
+		storage[i] = &StorageProof{
+			Key:   slot.Key,
+			Value: (*hexutil.Big)(slot.Value.Big()),
+			Proof: toHexBytes(slot.Proof),
+		}
+	}
+
+	return &AccountProof{
+		BlockNumber:  hexutil.Uint64(proof.Number),
+		BlockHash:    proof.BlockHash,
+		StateRoot:    proof.Root,
+		Address:      proof.Address,
+		AccountProof: toHexBytes(proof.Proof),
+		Balance:      (*hexutil.Big)(proof.Balance),
+		CodeHash:     proof.CodeHash,
+		Nonce:        hexutil.Uint64(proof.Nonce),
+		StorageHash:  proof.StorageRoot,
+		StorageProof: storage,
+	}
+}
+
+// toHexBytes converts the
+// specified proof nodes.
+func toHexBytes(nodes [][]byte) []hexutil.Bytes {
+	converted := make([]hexutil.Bytes, len(nodes))
+	for i, node := range nodes {
+		converted[i] = node
+	}
+	return converted
+}
@@ -0,0 +1,100 @@
+package node
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sparseth/config"
+	"sparseth/execution/ethclient"
+	"sparseth/execution/monitor"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// startProxyMonitor checks the verified implementation
+// of all monitored proxies after each block, see
+// monitor.ProxyChecker.
+//
+// If the ABI of a proxy was resolved for its former
+// implementation, the ABI of the new implementation
+// is resolved, and the account is reloaded with it.
+func (n *Node) startProxyMonitor(ctx context.Context, ec *ethclient.Client) func() error {
+	return func() error {
+		checker := monitor.NewProxyChecker(ethclient.NewRpcProvider(ec), n.events.Alerts, n.log)
+
+		headers := n.events.Headers.Subscribe("proxy-monitor")
+		defer n.events.Headers.Unsubscribe("proxy-monitor")
+		for {
+			select {
+			case head, ok := <-headers:
+				if !ok {
+					return nil
+				}
+				upgrades, err := checker.Check(ctx, head, n.accounts().Accounts)
+				if err != nil {
+					n.log.Warn("failed to check proxy implementations", "num", head.Number, "hash", head.Hash().Hex(), "err", err)
+					continue
+				}
+				for _, upgrade := range upgrades {
+					if err = n.upgradeProxy(ctx, upgrade); err != nil {
+						n.log.Warn("failed to reload upgraded proxy", "proxy", upgrade.Proxy.Hex(), "implementation", upgrade.To.Hex(), "err", err)
+					}
+				}
+			case <-ctx.Done():
+				return nil
+			}
+		}
+	}
+}
+
+// upgradeProxy reloads the upgraded proxy with the
+// ABI of its new implementation, if the ABI of the
+// proxy was resolved for its former implementation.
+func (n *Node) upgradeProxy(ctx context.Context, upgrade *monitor.Upgrade) error {
+	if n.config.ABIResolver == nil {
+		return nil
+	}
+
+	return n.setAccounts(ctx, func(accs *config.AccountsConfig) (*config.AccountsConfig, error) {
+		updated := make([]*config.AccountConfig, 0, len(accs.Accounts))
+		for _, acc := range accs.Accounts {
+			if acc.Addr == upgrade.Proxy && acc.Proxy != nil && acc.Proxy.Implementation != (common.Address{}) && acc.ContractConfig.HasEventConfig() {
+				reloaded, err := n.withImplementation(acc, upgrade.To)
+				if err != nil {
+					return nil, err
+				}
+				acc = reloaded
+			}
+			updated = append(updated, acc)
+		}
+		return &config.AccountsConfig{Accounts: updated}, nil
+	})
+}
+
+// withImplementation returns a copy of the specified
+// proxy account with the ABI of the specified
+// implementation.
+func (n *Node) withImplementation(acc *config.AccountConfig, impl common.Address) (*config.AccountConfig, error) {
+	data, err := n.config.ABIResolver.Resolve(impl)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := abi.JSON(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ABI of %s: %w", impl.Hex(), err)
+	}
+
+	event := *acc.ContractConfig.Event
+	event.ABI = parsed
+	proxy := *acc.Proxy
+	proxy.Implementation = impl
+
+	reloaded := *acc
+	reloaded.ContractConfig = &config.ContractConfig{
+		Event: &event,
+		State: acc.ContractConfig.State,
+	}
+	reloaded.Proxy = &proxy
+	return &reloaded, nil
+}
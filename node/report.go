@@ -0,0 +1,175 @@
+package node
+
+import (
+	"context"
+	"slices"
+	"sparseth/bus"
+	"sparseth/ethstore"
+	"sparseth/sink"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// startReporter summarizes the committed blocks of
+// each period of the configured report interval per
+// account. At the end of each period, the report is
+// stored, logged, and delivered to all sinks that
+// accept reports, see sink.ReportSink.
+func (n *Node) startReporter(ctx context.Context) func() error {
+	return func() error {
+		store := ethstore.NewReportStore(n.db)
+
+		commits := n.events.Persisted.Subscribe("digest-reporter")
+		defer n.events.Persisted.Unsubscribe("digest-reporter")
+		alerts := n.events.Alerts.Subscribe("digest-reporter")
+		defer n.events.Alerts.Unsubscribe("digest-reporter")
+
+		report := newReportBuilder(time.Now(), n.config.ReportInterval)
+		timer := time.NewTimer(time.Until(report.end))
+		defer timer.Stop()
+
+		for {
+			select {
+			case commit, ok := <-commits:
+				if !ok {
+					return nil
+				}
+				report.addCommit(commit, n.eventMonitorAccounts(), n.accountAddrs())
+			case alert, ok := <-alerts:
+				if !ok {
+					return nil
+				}
+				report.addAlert(alert)
+			case <-timer.C:
+				n.publishReport(ctx, store, report.build())
+
+				report = newReportBuilder(report.end, n.config.ReportInterval)
+				timer.Reset(time.Until(report.end))
+			case <-ctx.Done():
+				return nil
+			}
+		}
+	}
+}
+
+// publishReport stores and logs the specified report,
+// and delivers it to all sinks that accept reports.
+func (n *Node) publishReport(ctx context.Context, store *ethstore.ReportStore, report *ethstore.Report) {
+	if err := store.Put(report); err != nil {
+		n.log.Warn("failed to store digest report", "start", report.Start, "err", err)
+	}
+
+	var failed uint64
+	for _, acc := range report.Accounts {
+		failed += acc.Failed
+	}
+	n.log.Info("digest report", "start", time.Unix(int64(report.Start), 0).UTC(), "end", time.Unix(int64(report.End), 0).UTC(), "blocks", report.Blocks, "accounts", len(report.Accounts), "failed", failed)
+
+	for _, s := range n.sinks {
+		rs, ok := s.(sink.ReportSink)
+		if !ok {
+			continue
+		}
+		if err := rs.DeliverReport(ctx, report); err != nil {
+			n.log.Warn("failed to deliver digest report", "sink", s.Name(), "start", report.Start, "err", err)
+		}
+	}
+}
+
+// reportBuilder accumulates the
+// report of a single period.
+type reportBuilder struct {
+	report   *ethstore.Report
+	end      time.Time
+	accounts map[common.Address]*ethstore.AccountReport
+}
+
+// newReportBuilder creates a new reportBuilder for
+// the period of the specified interval that
+// includes the specified time.
+func newReportBuilder(now time.Time, interval time.Duration) *reportBuilder {
+	start := now.Truncate(interval)
+	end := start.Add(interval)
+
+	return &reportBuilder{
+		report: &ethstore.Report{
+			Start: uint64(start.Unix()),
+			End:   uint64(end.Unix()),
+		},
+		end:      end,
+		accounts: make(map[common.Address]*ethstore.AccountReport),
+	}
+}
+
+// addCommit adds the specified committed block. The
+// results of event monitors, indexed by monitor name,
+// count for their account, and the result of the
+// transaction monitor for all specified accounts.
+func (b *reportBuilder) addCommit(commit *bus.BlockCommit, monitors map[string]common.Address, accounts []common.Address) {
+	if b.report.Blocks == 0 {
+		b.report.FirstBlock = commit.Number
+	}
+	b.report.LastBlock = commit.Number
+	b.report.Blocks++
+
+	verified := make(map[common.Address]bool)
+	for _, res := range commit.Results {
+		covered := accounts
+		if addr, ok := monitors[res.Monitor]; ok {
+			covered = []common.Address{addr}
+		} else if res.Monitor != txMonitorName {
+			continue
+		}
+
+		// An account is only verified if
+		// all of its monitors verified
+		for _, addr := range covered {
+			if ok, seen := verified[addr]; !seen || ok {
+				verified[addr] = res.Verified()
+			}
+		}
+		for addr, count := range res.Activity {
+			b.account(addr).Activity += count
+		}
+	}
+
+	for addr, ok := range verified {
+		if ok {
+			b.account(addr).Verified++
+		} else {
+			b.account(addr).Failed++
+		}
+	}
+}
+
+// addAlert adds the specified alert.
+func (b *reportBuilder) addAlert(alert *bus.Alert) {
+	if alert.Account != (common.Address{}) {
+		b.account(alert.Account).Alerts++
+	}
+}
+
+// account returns the report of the specified
+// account, which is created if missing.
+func (b *reportBuilder) account(addr common.Address) *ethstore.AccountReport {
+	acc, ok := b.accounts[addr]
+	if !ok {
+		acc = &ethstore.AccountReport{Address: addr}
+		b.accounts[addr] = acc
+	}
+	return acc
+}
+
+// build returns the report, with
+// accounts sorted by address.
+func (b *reportBuilder) build() *ethstore.Report {
+	b.report.Accounts = make([]*ethstore.AccountReport, 0, len(b.accounts))
+	for _, acc := range b.accounts {
+		b.report.Accounts = append(b.report.Accounts, acc)
+	}
+	slices.SortFunc(b.report.Accounts, func(a, b *ethstore.AccountReport) int {
+		return a.Address.Cmp(b.Address)
+	})
+	return b.report
+}
@@ -0,0 +1,535 @@
+package node
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"sparseth/config"
+	"sparseth/ethstore"
+	"sparseth/execution/monitor/state"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// maxRootHistory is the maximum number of
+// state roots returned by a single call.
+const maxRootHistory = 1024
+
+var (
+	// errNoWorldState is returned by the stats API
+	// if the node does not track the world state.
+	errNoWorldState = errors.New("world state is only tracked in sparse mode")
+)
+
+// StatsAPI provides the stats_ JSON-RPC namespace,
+// which allows operators to corroborate the world
+// state of the node externally.
+type StatsAPI struct {
+	n *Node
+	// tenant is the API consumer the API
+	// is served to, or nil if unrestricted.
+	tenant *config.Tenant
+}
+
+// StateRoot is the root of the world
+// state after a processed block.
+type StateRoot struct {
+	Number    hexutil.Uint64 `json:"number"`
+	BlockHash common.Hash    `json:"blockHash"`
+	Root      common.Hash    `json:"root"`
+}
+
+// TrieStats describes the size of
+// the world state trie.
+type TrieStats struct {
+	Root         common.Hash    `json:"root"`
+	Accounts     hexutil.Uint64 `json:"accounts"`
+	AccountNodes hexutil.Uint64 `json:"accountNodes"`
+	StorageNodes hexutil.Uint64 `json:"storageNodes"`
+}
+
+// BlockDigest is the digest of the verified
+// outputs of a committed block.
+type BlockDigest struct {
+	Number    hexutil.Uint64 `json:"number"`
+	BlockHash common.Hash    `json:"blockHash"`
+	Digest    common.Hash    `json:"digest"`
+	Verified  bool           `json:"verified"`
+}
+
+// TxOutcome is the verification outcome
+// of a re-executed transaction.
+type TxOutcome struct {
+	TxHash      common.Hash      `json:"txHash"`
+	BlockNumber hexutil.Uint64   `json:"blockNumber"`
+	BlockHash   common.Hash      `json:"blockHash"`
+	Status      hexutil.Uint64   `json:"status"`
+	Verified    bool             `json:"verified"`
+	Accounts    []common.Address `json:"accounts"`
+}
+
+// StateDiff holds the verified changes to
+// the monitored accounts within a block.
+type StateDiff struct {
+	Number    hexutil.Uint64 `json:"number"`
+	BlockHash common.Hash    `json:"blockHash"`
+	Accounts  []*AccountDiff `json:"accounts"`
+}
+
+// AccountDiff is the verified change of an
+// account within a block. Unchanged fields
+// hold equal values before and after.
+type AccountDiff struct {
+	Address  common.Address `json:"address"`
+	Nonce    *ValueDiff     `json:"nonce"`
+	Balance  *ValueDiff     `json:"balance"`
+	CodeHash *ValueDiff     `json:"codeHash"`
+	Slots    []*SlotDiff    `json:"slots"`
+}
+
+// ValueDiff is the value of an account
+// field before and after a block.
+type ValueDiff struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+// SlotDiff is the change of a
+// storage slot within a block.
+type SlotDiff struct {
+	Slot   common.Hash `json:"slot"`
+	Before common.Hash `json:"before"`
+	After  common.Hash `json:"after"`
+}
+
+// ProviderStatus describes the health and
+// trust of an RPC provider.
+type ProviderStatus struct {
+	URL     string `json:"url"`
+	Healthy bool   `json:"healthy"`
+	// TrustScore is a moving average of the share
+	// of blocks for which the data claimed by the
+	// provider matched the proven state.
+	TrustScore  float64        `json:"trustScore"`
+	Agreements  hexutil.Uint64 `json:"agreements"`
+	Divergences hexutil.Uint64 `json:"divergences"`
+}
+
+// QueueStatus describes the queue of
+// a subscriber of the internal bus.
+type QueueStatus struct {
+	Topic    string         `json:"topic"`
+	ID       string         `json:"id"`
+	Policy   string         `json:"policy"`
+	Depth    hexutil.Uint64 `json:"depth"`
+	Capacity hexutil.Uint64 `json:"capacity"`
+	Dropped  hexutil.Uint64 `json:"dropped"`
+}
+
+// DeadLetter describes a block that a
+// monitor failed to process, even
+// after retries.
+type DeadLetter struct {
+	Monitor  string         `json:"monitor"`
+	Number   hexutil.Uint64 `json:"number"`
+	Hash     common.Hash    `json:"hash"`
+	Error    string         `json:"error"`
+	Attempts hexutil.Uint64 `json:"attempts"`
+	Time     hexutil.Uint64 `json:"time"`
+}
+
+// Attestation is a signed attestation of the
+// verified storage root or event hash chain
+// head of an account at a block.
+type Attestation struct {
+	Number    hexutil.Uint64 `json:"number"`
+	BlockHash common.Hash    `json:"blockHash"`
+	Account   common.Address `json:"account"`
+	Root      common.Hash    `json:"root"`
+	Signer    common.Address `json:"signer"`
+	Signature hexutil.Bytes  `json:"signature"`
+}
+
+// PeerStatus describes a peer whose
+// attestations are compared with
+// those of the node.
+type PeerStatus struct {
+	URL         string         `json:"url"`
+	Signer      common.Address `json:"signer"`
+	Checked     hexutil.Uint64 `json:"checked"`
+	Agreements  hexutil.Uint64 `json:"agreements"`
+	Divergences hexutil.Uint64 `json:"divergences"`
+	Error       string         `json:"error,omitempty"`
+}
+
+// newStatsAPI creates a new StatsAPI for the
+// specified node, as seen by the specified
+// tenant. A nil tenant is unrestricted.
+func newStatsAPI(n *Node, tenant *config.Tenant) *StatsAPI {
+	return &StatsAPI{n: n, tenant: tenant}
+}
+
+// StateRoot returns the world state root
+// after the last processed block.
+func (api *StatsAPI) StateRoot() (*StateRoot, error) {
+	if api.n.txProc == nil {
+		return nil, errNoWorldState
+	}
+
+	latest := api.n.txProc.LatestRoot()
+	if latest == nil {
+		return nil, fmt.Errorf("no block processed yet")
+	}
+	return toStateRoot(latest), nil
+}
+
+// RootHistory returns the world state roots after
+// all processed blocks in the specified inclusive
+// range. Blocks without a recorded root, e.g., as
+// they failed verification, are omitted.
+func (api *StatsAPI) RootHistory(from, to hexutil.Uint64) ([]*StateRoot, error) {
+	if api.n.txProc == nil {
+		return nil, errNoWorldState
+	}
+	if from > to {
+		return nil, fmt.Errorf("invalid range: from %d > to %d", from, to)
+	}
+	if to-from >= maxRootHistory {
+		return nil, fmt.Errorf("range exceeds limit of %d blocks", maxRootHistory)
+	}
+
+	history := make([]*StateRoot, 0, to-from+1)
+	for num := uint64(from); num <= uint64(to); num++ {
+		root, err := api.n.txProc.RootAt(num)
+		if errors.Is(err, ethstore.ErrRootNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		history = append(history, toStateRoot(root))
+	}
+
+	return history, nil
+}
+
+// TrieStats returns the number of accounts and trie
+// nodes of the world state after the last processed
+// block. As the number of accounts reveals the size
+// of all watchlists, it is not available to scoped
+// tenants.
+func (api *StatsAPI) TrieStats() (*TrieStats, error) {
+	if api.tenant.Scoped() {
+		return nil, errForbidden
+	}
+	if api.n.txProc == nil {
+		return nil, errNoWorldState
+	}
+
+	stats, err := api.n.txProc.TrieStats()
+	if err != nil {
+		return nil, err
+	}
+	return toTrieStats(stats), nil
+}
+
+// BlockDigest returns the digest of the committed
+// block with the specified number. Two nodes with
+// the same config agree on a block if and only if
+// their digests match.
+func (api *StatsAPI) BlockDigest(num hexutil.Uint64) (*BlockDigest, error) {
+	digest, err := ethstore.NewDigestStore(api.n.db, api.n.config.DbEncoding).Get(uint64(num))
+	if err != nil {
+		return nil, err
+	}
+
+	return toBlockDigest(digest), nil
+}
+
+// TxOutcome returns the verification outcome of the
+// re-executed transaction with the specified hash,
+// i.e., whether its effects are included in the
+// verified state. Scoped tenants only see
+// transactions touching their accounts, and
+// only their accounts among the touched ones.
+func (api *StatsAPI) TxOutcome(hash common.Hash) (*TxOutcome, error) {
+	if api.n.txProc == nil {
+		return nil, errNoWorldState
+	}
+
+	outcome, err := ethstore.NewTxStore(api.n.db).Get(hash)
+	if err != nil {
+		return nil, err
+	}
+	if api.tenant.Scoped() && !slices.ContainsFunc(outcome.Accounts, api.tenant.Allows) {
+		// Do not reveal transactions of
+		// accounts hidden from the tenant
+		return nil, ethstore.ErrTxNotFound
+	}
+
+	res := toTxOutcome(outcome)
+	res.Accounts = slices.DeleteFunc(slices.Clone(res.Accounts), func(addr common.Address) bool {
+		return !api.tenant.Allows(addr)
+	})
+	return res, nil
+}
+
+// StateDiff returns the verified changes to the
+// monitored accounts within the processed block
+// with the specified number, i.e., their balance,
+// nonce, code hash, and changed storage slots.
+// Scoped tenants only see their accounts.
+func (api *StatsAPI) StateDiff(num hexutil.Uint64) (*StateDiff, error) {
+	if api.n.txProc == nil {
+		return nil, errNoWorldState
+	}
+
+	root, err := api.n.txProc.RootAt(uint64(num))
+	if err != nil {
+		return nil, err
+	}
+	res := &StateDiff{
+		Number:    num,
+		BlockHash: root.BlockHash,
+		Accounts:  make([]*AccountDiff, 0),
+	}
+
+	diff, err := api.n.txProc.Diff(uint64(num))
+	if errors.Is(err, ethstore.ErrDiffNotFound) {
+		return res, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if diff.BlockHash != root.BlockHash {
+		// Diff of a reorged block, the
+		// canonical block changed nothing
+		return res, nil
+	}
+
+	for _, acc := range diff.Accounts {
+		if api.tenant.Scoped() && !api.tenant.Allows(acc.Address) {
+			continue
+		}
+		res.Accounts = append(res.Accounts, toAccountDiff(acc))
+	}
+	return res, nil
+}
+
+// Providers returns the health and trust of all
+// RPC providers, in order of configuration. As
+// provider URLs often embed API keys, only their
+// scheme and host are returned.
+func (api *StatsAPI) Providers() []*ProviderStatus {
+	endpoints := api.n.pool.Endpoints()
+
+	statuses := make([]*ProviderStatus, len(endpoints))
+	for i, ep := range endpoints {
+		statuses[i] = &ProviderStatus{
+			URL:         redactURL(ep.URL),
+			Healthy:     ep.Healthy,
+			TrustScore:  ep.Trust.Score,
+			Agreements:  hexutil.Uint64(ep.Trust.Agreements),
+			Divergences: hexutil.Uint64(ep.Trust.Divergences),
+		}
+	}
+	return statuses
+}
+
+// redactURL strips the credentials, path, and
+// query of the specified URL, which may carry
+// API keys of the provider.
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return "<redacted>"
+	}
+	return (&url.URL{Scheme: u.Scheme, Host: u.Host}).String()
+}
+
+// Queues returns the depth, capacity, overflow
+// policy, and number of dropped events of the
+// queue of each subscriber of the internal bus.
+// As the subscribers reveal the monitored
+// accounts, it is not available to scoped
+// tenants.
+func (api *StatsAPI) Queues() ([]*QueueStatus, error) {
+	if api.tenant.Scoped() {
+		return nil, errForbidden
+	}
+
+	stats := api.n.events.Stats()
+	statuses := make([]*QueueStatus, len(stats))
+	for i, s := range stats {
+		statuses[i] = &QueueStatus{
+			Topic:    s.Topic,
+			ID:       s.ID,
+			Policy:   string(s.Policy),
+			Depth:    hexutil.Uint64(s.Depth),
+			Capacity: hexutil.Uint64(s.Capacity),
+			Dropped:  hexutil.Uint64(s.Dropped),
+		}
+	}
+	return statuses, nil
+}
+
+// DeadLetters returns all blocks from the specified
+// number on that a monitor failed to process, even
+// after retries, with the error of the last attempt.
+// As the monitors reveal the monitored accounts, it
+// is not available to scoped tenants.
+func (api *StatsAPI) DeadLetters(from hexutil.Uint64) ([]*DeadLetter, error) {
+	if api.tenant.Scoped() {
+		return nil, errForbidden
+	}
+
+	letters, err := ethstore.NewDeadLetterStore(api.n.db).Since(uint64(from))
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*DeadLetter, len(letters))
+	for i, dl := range letters {
+		res[i] = &DeadLetter{
+			Monitor:  dl.Monitor,
+			Number:   hexutil.Uint64(dl.Number),
+			Hash:     dl.Hash,
+			Error:    dl.Err,
+			Attempts: hexutil.Uint64(dl.Attempts),
+			Time:     hexutil.Uint64(dl.Time),
+		}
+	}
+	return res, nil
+}
+
+// Attestations returns the signed attestations of
+// the verified storage roots and event hash chain
+// heads of the block with the specified number.
+// Scoped tenants only see the attestations of
+// their accounts.
+func (api *StatsAPI) Attestations(num hexutil.Uint64) ([]*Attestation, error) {
+	attestations, err := ethstore.NewAttestationStore(api.n.db).Get(uint64(num))
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*Attestation, 0, len(attestations))
+	for _, a := range attestations {
+		if !api.tenant.Allows(a.Account) {
+			continue
+		}
+		res = append(res, &Attestation{
+			Number:    hexutil.Uint64(a.Number),
+			BlockHash: a.BlockHash,
+			Account:   a.Account,
+			Root:      a.Root,
+			Signer:    a.Signer,
+			Signature: a.Signature,
+		})
+	}
+	return res, nil
+}
+
+// Peers returns the status of each peer whose
+// attestations are compared with those of the
+// node. As the peers reveal the deployment, it
+// is not available to scoped tenants.
+func (api *StatsAPI) Peers() ([]*PeerStatus, error) {
+	if api.tenant.Scoped() {
+		return nil, errForbidden
+	}
+	if api.n.federation == nil {
+		return nil, nil
+	}
+
+	peers := api.n.federation.Status()
+	statuses := make([]*PeerStatus, len(peers))
+	for i, p := range peers {
+		statuses[i] = &PeerStatus{
+			URL:         p.URL,
+			Signer:      p.Signer,
+			Checked:     hexutil.Uint64(p.Checked),
+			Agreements:  hexutil.Uint64(p.Agreements),
+			Divergences: hexutil.Uint64(p.Divergences),
+			Error:       p.Err,
+		}
+	}
+	return statuses, nil
+}
+
+// toStateRoot converts the specified
+// state root to its API representation.
+func toStateRoot(root *ethstore.StateRoot) *StateRoot {
+	return &StateRoot{
+		Number:    hexutil.Uint64(root.Number),
+		BlockHash: root.BlockHash,
+		Root:      root.Root,
+	}
+}
+
+// toBlockDigest converts the specified
+// digest to its API representation.
+func toBlockDigest(digest *ethstore.BlockDigest) *BlockDigest {
+	return &BlockDigest{
+		Number:    hexutil.Uint64(digest.Number),
+		BlockHash: digest.BlockHash,
+		Digest:    digest.Digest,
+		Verified:  digest.Verified,
+	}
+}
+
+// toTxOutcome converts the specified tx
+// outcome to its API representation.
+func toTxOutcome(outcome *ethstore.TxOutcome) *TxOutcome {
+	return &TxOutcome{
+		TxHash:      outcome.TxHash,
+		BlockNumber: hexutil.Uint64(outcome.Number),
+		BlockHash:   outcome.BlockHash,
+		Status:      hexutil.Uint64(outcome.Status),
+		Verified:    outcome.Verified,
+		Accounts:    outcome.Accounts,
+	}
+}
+
+// toTrieStats converts the specified trie
+// stats to their API representation.
+func toTrieStats(stats *state.TrieStats) *TrieStats {
+	return &TrieStats{
+		Root:         stats.Root,
+		Accounts:     hexutil.Uint64(stats.Accounts),
+		AccountNodes: hexutil.Uint64(stats.AccountNodes),
+		StorageNodes: hexutil.Uint64(stats.StorageNodes),
+	}
+}
+
+// toAccountDiff converts the specified account
+// diff to its API representation.
+func toAccountDiff(diff *ethstore.AccountDiff) *AccountDiff {
+	slots := make([]*SlotDiff, len(diff.Slots))
+	for i, slot := range diff.Slots {
+		slots[i] = &SlotDiff{
+			Slot:   slot.Slot,
+			Before: slot.Before,
+			After:  slot.After,
+		}
+	}
+
+	return &AccountDiff{
+		Address: diff.Address,
+		Nonce: &ValueDiff{
+			Before: hexutil.Uint64(diff.NonceBefore),
+			After:  hexutil.Uint64(diff.NonceAfter),
+		},
+		Balance: &ValueDiff{
+			Before: (*hexutil.Big)(diff.BalanceBefore),
+			After:  (*hexutil.Big)(diff.BalanceAfter),
+		},
+		CodeHash: &ValueDiff{
+			Before: diff.CodeHashBefore,
+			After:  diff.CodeHashAfter,
+		},
+		Slots: slots,
+	}
+}
@@ -0,0 +1,137 @@
+package node
+
+import (
+	"sort"
+	"sparseth/bus"
+	"sparseth/config"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// SparsethAPI provides the sparseth_ JSON-RPC
+// namespace, which describes the progress and
+// health of the node as a whole.
+type SparsethAPI struct {
+	n *Node
+	// tenant is the API consumer the API
+	// is served to, or nil if unrestricted.
+	tenant *config.Tenant
+}
+
+// Status describes the progress
+// and health of the node.
+type Status struct {
+	// Head is the last block received from
+	// the consensus client, if any, which
+	// may not be confirmed yet.
+	Head *BlockID `json:"head,omitempty"`
+	// Committed is the last block processed
+	// by all monitors, if any.
+	Committed *CommittedBlock `json:"committed,omitempty"`
+	// Monitors holds the last block processed
+	// by each monitor, by name.
+	Monitors []*MonitorProgress `json:"monitors"`
+	// Failures is the number of failed
+	// verifications since start.
+	Failures hexutil.Uint64 `json:"failures"`
+	// DBSize is the approximate size
+	// of the database in bytes.
+	DBSize hexutil.Uint64 `json:"dbSize"`
+	// Uptime is the number of seconds
+	// since the node was created.
+	Uptime hexutil.Uint64 `json:"uptime"`
+}
+
+// BlockID identifies a block.
+type BlockID struct {
+	Number hexutil.Uint64 `json:"number"`
+	Hash   common.Hash    `json:"hash"`
+}
+
+// MonitorProgress describes the
+// progress of a single monitor.
+type MonitorProgress struct {
+	Name   string         `json:"name"`
+	Number hexutil.Uint64 `json:"number"`
+}
+
+// newSparsethAPI creates a new SparsethAPI
+// for the specified node, as seen by the
+// specified tenant. A nil tenant is
+// unrestricted.
+func newSparsethAPI(n *Node, tenant *config.Tenant) *SparsethAPI {
+	return &SparsethAPI{
+		n:      n,
+		tenant: tenant,
+	}
+}
+
+// Status returns the current head, the last block
+// processed by each monitor, the number of failed
+// verifications, the size of the database, and the
+// uptime of the node. As the monitors reveal the
+// monitored accounts, it is not available to scoped
+// tenants.
+func (api *SparsethAPI) Status() (*Status, error) {
+	if api.tenant.Scoped() {
+		return nil, errForbidden
+	}
+
+	size, err := api.n.db.Size()
+	if err != nil {
+		return nil, err
+	}
+	status := &Status{
+		Monitors: api.monitorProgress(),
+		Failures: hexutil.Uint64(api.failures()),
+		DBSize:   hexutil.Uint64(size),
+		Uptime:   hexutil.Uint64(time.Duration(api.n.clock().Now() - api.n.started).Seconds()),
+	}
+
+	if head := api.n.head(); head != nil {
+		status.Head = &BlockID{
+			Number: hexutil.Uint64(head.Number.Uint64()),
+			Hash:   head.Hash(),
+		}
+	}
+	if latest := api.n.barrier.Latest(); latest != nil {
+		status.Committed = &CommittedBlock{
+			Number:   hexutil.Uint64(latest.Number),
+			Hash:     latest.Hash,
+			Verified: latest.Verified(),
+		}
+	}
+	return status, nil
+}
+
+// monitorProgress returns the progress
+// of all monitors, sorted by name.
+func (api *SparsethAPI) monitorProgress() []*MonitorProgress {
+	progress := api.n.barrier.Progress()
+
+	monitors := make([]*MonitorProgress, 0, len(progress))
+	for name, num := range progress {
+		monitors = append(monitors, &MonitorProgress{
+			Name:   name,
+			Number: hexutil.Uint64(num),
+		})
+	}
+	sort.Slice(monitors, func(i, j int) bool {
+		return monitors[i].Name < monitors[j].Name
+	})
+	return monitors
+}
+
+// failures returns the number of
+// failed verifications since start.
+func (api *SparsethAPI) failures() uint64 {
+	var failed uint64
+	for kind, n := range api.n.verifications.snapshot() {
+		if kind != bus.VerificationOK {
+			failed += n
+		}
+	}
+	return failed
+}
@@ -0,0 +1,153 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sparseth/bus"
+	"sparseth/ethstore"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// maxReplayBlocks is the maximum number of
+// committed blocks replayed to a resumed
+// subscription.
+const maxReplayBlocks = 8192
+
+// Commits subscribes to all committed blocks, in
+// order, along with their digests.
+//
+// If the specified cursor is set, i.e., the number
+// of the last block received before a disconnect,
+// all blocks committed since are replayed from the
+// store first, so that the client receives every
+// block exactly once. Once the client falls behind,
+// the oldest blocks are dropped from the live stream,
+// and filled in from the store as well.
+func (api *StatsAPI) Commits(ctx context.Context, cursor *hexutil.Uint64) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return nil, rpc.ErrNotificationsUnsupported
+	}
+
+	if latest := api.n.barrier.Latest(); cursor != nil && latest != nil && latest.Number > uint64(*cursor)+maxReplayBlocks {
+		return nil, fmt.Errorf("cursor %d too old, at most %d blocks are replayed", uint64(*cursor), maxReplayBlocks)
+	}
+
+	sub := notifier.CreateSubscription()
+	id := "api-subscription-" + string(sub.ID)
+	// Subscribe before replaying, so that blocks
+	// committed in between are not missed
+	persisted := api.n.events.Persisted.SubscribeWith(id, bus.Options{Policy: bus.DropOldest})
+
+	go func() {
+		defer api.n.events.Persisted.Unsubscribe(id)
+
+		stream := &commitStream{
+			store:    ethstore.NewDigestStore(api.n.db, api.n.config.DbEncoding),
+			notifier: notifier,
+			id:       sub.ID,
+		}
+		if cursor != nil {
+			if err := stream.resume(uint64(*cursor) + 1); err != nil {
+				api.n.log.Debug("failed to replay committed blocks", "subscription", sub.ID, "err", err)
+				return
+			}
+		}
+
+		for {
+			select {
+			case commit, ok := <-persisted:
+				if !ok {
+					return
+				}
+				if err := stream.send(commit); err != nil {
+					api.n.log.Debug("failed to send committed block", "subscription", sub.ID, "err", err)
+					return
+				}
+			case <-sub.Err():
+				return
+			}
+		}
+	}()
+
+	return sub, nil
+}
+
+// commitStream sends committed blocks
+// to a single subscription.
+type commitStream struct {
+	store    *ethstore.DigestStore
+	notifier *rpc.Notifier
+	id       rpc.ID
+	// next is the number of the next
+	// block to send, if started.
+	next    uint64
+	started bool
+	// replayed is the number of the first
+	// block not replayed from the store.
+	replayed uint64
+}
+
+// resume replays all committed blocks from the
+// specified number on from the store.
+func (s *commitStream) resume(from uint64) error {
+	s.next, s.started = from, true
+	for {
+		digest, err := s.store.Get(s.next)
+		if errors.Is(err, ethstore.ErrDigestNotFound) {
+			s.replayed = s.next
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err = s.notify(toBlockDigest(digest)); err != nil {
+			return err
+		}
+		s.next++
+	}
+}
+
+// send sends the specified committed block,
+// preceded by all blocks missing since the
+// last one sent.
+func (s *commitStream) send(commit *bus.BlockCommit) error {
+	if commit.Number < s.replayed {
+		// Committed while replaying,
+		// the block is already sent
+		return nil
+	}
+	s.replayed = 0
+
+	if s.started && commit.Number > s.next {
+		for num := s.next; num < commit.Number; num++ {
+			digest, err := s.store.Get(num)
+			if errors.Is(err, ethstore.ErrDigestNotFound) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			if err = s.notify(toBlockDigest(digest)); err != nil {
+				return err
+			}
+		}
+	}
+
+	s.next, s.started = commit.Number+1, true
+	return s.notify(&BlockDigest{
+		Number:    hexutil.Uint64(commit.Number),
+		BlockHash: commit.Hash,
+		Digest:    commit.Digest,
+		Verified:  commit.Verified(),
+	})
+}
+
+// notify sends the specified block
+// digest to the subscription.
+func (s *commitStream) notify(digest *BlockDigest) error {
+	return s.notifier.Notify(s.id, digest)
+}
@@ -0,0 +1,78 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"sparseth/bus"
+	"sparseth/ethstore"
+	"sparseth/execution/ethclient"
+)
+
+// restoreTrust restores the persisted trust of
+// all RPC providers of the node.
+func (n *Node) restoreTrust(store *ethstore.TrustStore) {
+	for _, ep := range n.pool.Endpoints() {
+		trust, err := store.Get(ep.URL)
+		if errors.Is(err, ethstore.ErrTrustNotFound) {
+			continue
+		}
+		if err != nil {
+			n.log.Warn("failed to restore provider trust", "url", ep.URL, "err", err)
+			continue
+		}
+		n.pool.SetTrust(ep.URL, ethclient.Trust{
+			Score:       trust.Score,
+			Agreements:  trust.Agreements,
+			Divergences: trust.Divergences,
+		})
+	}
+}
+
+// startTrustTracker compares the data claimed by
+// the RPC providers with the proven state for each
+// processed block, and persists the trust of each
+// provider, which informs the failover order.
+func (n *Node) startTrustTracker(ctx context.Context) func() error {
+	return func() error {
+		store := ethstore.NewTrustStore(n.db)
+		n.restoreTrust(store)
+
+		results := n.events.Results.Subscribe("trust-tracker")
+		for {
+			select {
+			case r, ok := <-results:
+				if !ok {
+					return nil
+				}
+				n.observe(store, r)
+			case <-ctx.Done():
+				return nil
+			}
+		}
+	}
+}
+
+// observe records the outcome of the specified
+// result for all providers that served its data.
+// Failures other than divergences, e.g., network
+// errors, are not attributed to the providers.
+func (n *Node) observe(store *ethstore.TrustStore, r *bus.Result) {
+	diverged := errors.Is(r.Err, ethclient.ErrDivergence)
+	if len(r.Providers) == 0 || (r.Err != nil && !diverged) {
+		return
+	}
+
+	n.pool.Observe(r.Providers, diverged)
+
+	for _, ep := range n.pool.Endpoints() {
+		err := store.Put(&ethstore.ProviderTrust{
+			URL:         ep.URL,
+			Score:       ep.Trust.Score,
+			Agreements:  ep.Trust.Agreements,
+			Divergences: ep.Trust.Divergences,
+		})
+		if err != nil {
+			n.log.Warn("failed to persist provider trust", "url", ep.URL, "err", err)
+		}
+	}
+}
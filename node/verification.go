@@ -0,0 +1,148 @@
+package node
+
+import (
+	"context"
+	"sparseth/bus"
+	gosync "sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// verificationCounts counts the published
+// verifications by kind.
+type verificationCounts struct {
+	counts map[bus.VerificationKind]uint64
+	mu     gosync.Mutex
+}
+
+// add counts the specified verification.
+func (c *verificationCounts) add(v *bus.Verification) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.counts == nil {
+		c.counts = make(map[bus.VerificationKind]uint64)
+	}
+	c.counts[v.Kind]++
+}
+
+// snapshot returns a copy of the counts.
+func (c *verificationCounts) snapshot() map[bus.VerificationKind]uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	counts := make(map[bus.VerificationKind]uint64, len(c.counts))
+	for kind, n := range c.counts {
+		counts[kind] = n
+	}
+	return counts
+}
+
+// startVerificationCounter counts all
+// verifications published by the
+// processors, see VerificationCounts.
+func (n *Node) startVerificationCounter(ctx context.Context) func() error {
+	return func() error {
+		verifications := n.events.Verifications.Subscribe("verification-counter")
+		for {
+			select {
+			case v, ok := <-verifications:
+				if !ok {
+					return nil
+				}
+				n.verifications.add(v)
+			case <-ctx.Done():
+				return nil
+			}
+		}
+	}
+}
+
+// Verification is the outcome of verifying
+// an account at a block.
+type Verification struct {
+	Kind     string         `json:"kind"`
+	Source   string         `json:"source"`
+	Account  common.Address `json:"account"`
+	Number   hexutil.Uint64 `json:"number"`
+	Hash     common.Hash    `json:"hash"`
+	Message  string         `json:"message,omitempty"`
+	Value    string         `json:"value,omitempty"`
+	Expected string         `json:"expected,omitempty"`
+	Actual   string         `json:"actual,omitempty"`
+}
+
+// VerificationCounts returns the number of
+// verifications of each kind since start.
+// As the counts cover all monitored accounts,
+// it is not available to scoped tenants.
+func (api *StatsAPI) VerificationCounts() (map[string]hexutil.Uint64, error) {
+	if api.tenant.Scoped() {
+		return nil, errForbidden
+	}
+
+	counts := make(map[string]hexutil.Uint64)
+	for kind, n := range api.n.verifications.snapshot() {
+		counts[string(kind)] = hexutil.Uint64(n)
+	}
+	return counts, nil
+}
+
+// Verifications subscribes to the outcome of
+// verifying each account at each block. Scoped
+// tenants only receive the verifications of the
+// accounts they are allowed to access. Once the
+// client falls behind, the oldest verifications
+// are dropped.
+func (api *StatsAPI) Verifications(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return nil, rpc.ErrNotificationsUnsupported
+	}
+
+	sub := notifier.CreateSubscription()
+	id := "api-subscription-" + string(sub.ID)
+	verifications := api.n.events.Verifications.SubscribeWith(id, bus.Options{Policy: bus.DropOldest})
+
+	go func() {
+		defer api.n.events.Verifications.Unsubscribe(id)
+
+		for {
+			select {
+			case v, ok := <-verifications:
+				if !ok {
+					return
+				}
+				if !api.tenant.Allows(v.Account) {
+					continue
+				}
+				if err := notifier.Notify(sub.ID, toVerification(v)); err != nil {
+					api.n.log.Debug("failed to send verification", "subscription", sub.ID, "err", err)
+					return
+				}
+			case <-sub.Err():
+				return
+			}
+		}
+	}()
+
+	return sub, nil
+}
+
+// toVerification converts the specified
+// verification to its API representation.
+func toVerification(v *bus.Verification) *Verification {
+	return &Verification{
+		Kind:     string(v.Kind),
+		Source:   v.Source,
+		Account:  v.Account,
+		Number:   hexutil.Uint64(v.Number),
+		Hash:     v.Hash,
+		Message:  v.Message,
+		Value:    v.Value,
+		Expected: v.Expected,
+		Actual:   v.Actual,
+	}
+}
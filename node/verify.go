@@ -0,0 +1,191 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sparseth/bus"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// RangeReport summarizes the
+// verification of a block range.
+type RangeReport struct {
+	From uint64 `json:"from"`
+	To   uint64 `json:"to"`
+	// Blocks is the number of blocks
+	// processed by all monitors.
+	Blocks int `json:"blocks"`
+	// Verified is the number of blocks
+	// verified by all monitors.
+	Verified int `json:"verified"`
+	// Accounts is the number of
+	// monitored accounts.
+	Accounts int `json:"accounts"`
+	// FailedAccounts lists all accounts with
+	// a mismatch in at least one block.
+	FailedAccounts []common.Address `json:"failedAccounts"`
+	// Mismatches lists all blocks a
+	// monitor failed to verify.
+	Mismatches []*Mismatch `json:"mismatches"`
+	// Failures lists all failed verifications
+	// of an account, e.g., with the expected
+	// and actual value of a storage slot.
+	Failures []*Verification `json:"failures"`
+}
+
+// Mismatch describes a block that
+// a monitor failed to verify.
+type Mismatch struct {
+	Number  uint64      `json:"number"`
+	Hash    common.Hash `json:"hash"`
+	Monitor string      `json:"monitor"`
+	Error   string      `json:"error"`
+}
+
+// VerifiedAccounts returns the number of accounts
+// without a mismatch in any block of the range.
+func (r *RangeReport) VerifiedAccounts() int {
+	return r.Accounts - len(r.FailedAccounts)
+}
+
+// VerifyRange runs the node until all blocks of the
+// configured block range are processed by all monitors,
+// and returns a summary of the verification.
+//
+// Note that the state of monitored accounts is
+// reconstructed starting at the first block of the
+// range, i.e., accounts with state before the range
+// cannot be verified in sparse mode.
+func (n *Node) VerifyRange(ctx context.Context) (*RangeReport, error) {
+	if n.config.Range == nil {
+		return nil, errors.New("no block range configured")
+	}
+
+	commits := n.events.Commits.Subscribe("range-report")
+	alerts := n.events.Alerts.Subscribe("range-report")
+	verifications := n.events.Verifications.Subscribe("range-report")
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- n.Start(ctx)
+	}()
+
+	report := &RangeReport{
+		From:       n.config.Range.From,
+		To:         n.config.Range.To,
+		Accounts:   len(n.accounts().Accounts),
+		Mismatches: make([]*Mismatch, 0),
+		Failures:   make([]*Verification, 0),
+	}
+	failed := make(map[common.Address]bool)
+	monitors := n.eventMonitorAccounts()
+
+	for {
+		select {
+		case commit := <-commits:
+			report.add(commit, monitors, failed)
+			if commit.Number < report.To {
+				continue
+			}
+
+			// Alerts are published before the results
+			// they relate to, collect remaining ones
+			for len(alerts) > 0 {
+				addAlert(<-alerts, failed)
+			}
+			for len(verifications) > 0 {
+				report.addVerification(<-verifications)
+			}
+			cancel()
+			if err := <-done; err != nil {
+				return nil, err
+			}
+
+			report.FailedAccounts = sortedAccounts(failed)
+			return report, nil
+		case alert := <-alerts:
+			addAlert(alert, failed)
+		case v := <-verifications:
+			report.addVerification(v)
+		case err := <-done:
+			if err != nil {
+				return nil, err
+			}
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			return nil, fmt.Errorf("node stopped before block %d was processed", report.To)
+		}
+	}
+}
+
+// add records the results of the specified block.
+// Accounts of failed event monitors are added to
+// the specified set of failed accounts.
+func (r *RangeReport) add(commit *bus.BlockCommit, monitors map[string]common.Address, failed map[common.Address]bool) {
+	r.Blocks++
+	if commit.Verified() {
+		r.Verified++
+		return
+	}
+
+	for _, res := range commit.Results {
+		if res.Verified() {
+			continue
+		}
+		r.Mismatches = append(r.Mismatches, &Mismatch{
+			Number:  res.Number,
+			Hash:    res.Hash,
+			Monitor: res.Monitor,
+			Error:   res.Err.Error(),
+		})
+		if addr, exists := monitors[res.Monitor]; exists {
+			failed[addr] = true
+		}
+	}
+}
+
+// addVerification records the specified
+// verification, if it failed.
+func (r *RangeReport) addVerification(v *bus.Verification) {
+	if v.Failed() {
+		r.Failures = append(r.Failures, toVerification(v))
+	}
+}
+
+// addAlert adds the account of the specified alert,
+// if any, to the specified set of failed accounts.
+func addAlert(alert *bus.Alert, failed map[common.Address]bool) {
+	if alert.Account != (common.Address{}) {
+		failed[alert.Account] = true
+	}
+}
+
+// eventMonitorAccounts indexes the accounts of all
+// event monitors by the name of their monitor.
+func (n *Node) eventMonitorAccounts() map[string]common.Address {
+	indexed := make(map[string]common.Address)
+	for addr := range eventAccounts(n.accounts()) {
+		indexed[eventMonitorName(addr)] = addr
+	}
+	return indexed
+}
+
+// sortedAccounts returns the
+// specified set of accounts sorted.
+func sortedAccounts(set map[common.Address]bool) []common.Address {
+	addrs := make([]common.Address, 0, len(set))
+	for addr := range set {
+		addrs = append(addrs, addr)
+	}
+	sort.Slice(addrs, func(i, j int) bool {
+		return addrs[i].Cmp(addrs[j]) < 0
+	})
+	return addrs
+}
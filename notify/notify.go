@@ -0,0 +1,73 @@
+package notify
+
+import (
+	"context"
+	"sparseth/bus"
+	"sparseth/log"
+	"time"
+)
+
+// notifyTimeout is the maximum duration
+// of a single notification.
+const notifyTimeout = 10 * time.Second
+
+// Notifier is the interface of an alert target,
+// e.g., a webhook or a paging service.
+//
+// Unlike sinks, notifiers are best effort: each
+// alert is passed once, and failed notifications
+// are logged, not retried.
+type Notifier interface {
+	// Name identifies the notifier.
+	Name() string
+
+	// Notify delivers the specified alert.
+	Notify(ctx context.Context, alert *bus.Alert) error
+}
+
+// Dispatcher passes alerts to notifiers.
+type Dispatcher struct {
+	alerts    <-chan *bus.Alert
+	notifiers []Notifier
+	log       log.Logger
+}
+
+// NewDispatcher creates a new Dispatcher that
+// passes the alerts received on the specified
+// channel to the specified notifiers.
+func NewDispatcher(alerts <-chan *bus.Alert, notifiers []Notifier, log log.Logger) *Dispatcher {
+	return &Dispatcher{
+		alerts:    alerts,
+		notifiers: notifiers,
+		log:       log.With("component", "alert-dispatcher"),
+	}
+}
+
+// RunContext passes alerts to all notifiers until
+// the context is canceled, or the alerts channel
+// is closed.
+func (d *Dispatcher) RunContext(ctx context.Context) error {
+	for {
+		select {
+		case alert, ok := <-d.alerts:
+			if !ok {
+				return nil
+			}
+			d.notify(ctx, alert)
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// notify passes the specified
+// alert to all notifiers.
+func (d *Dispatcher) notify(ctx context.Context, alert *bus.Alert) {
+	for _, n := range d.notifiers {
+		ctx, cancel := context.WithTimeout(ctx, notifyTimeout)
+		if err := n.Notify(ctx, alert); err != nil {
+			d.log.Warn("failed to notify", "notifier", n.Name(), "source", alert.Source, "num", alert.Number, "err", err)
+		}
+		cancel()
+	}
+}
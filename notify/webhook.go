@@ -0,0 +1,87 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sparseth/bus"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// webhookPayload is the JSON body
+// posted for each alert.
+type webhookPayload struct {
+	Source   string          `json:"source"`
+	Account  *common.Address `json:"account,omitempty"`
+	Number   uint64          `json:"number"`
+	Hash     *common.Hash    `json:"hash,omitempty"`
+	Message  string          `json:"message"`
+	Kind     string          `json:"kind,omitempty"`
+	Expected string          `json:"expected,omitempty"`
+	Actual   string          `json:"actual,omitempty"`
+}
+
+// Webhook is a Notifier that posts each
+// alert as JSON to an HTTP endpoint.
+type Webhook struct {
+	url string
+	c   *http.Client
+}
+
+// NewWebhook creates a new Webhook that
+// posts to the specified URL.
+func NewWebhook(url string) *Webhook {
+	return &Webhook{
+		url: url,
+		c:   &http.Client{},
+	}
+}
+
+// Name implements Notifier.
+func (w *Webhook) Name() string {
+	return "webhook/" + w.url
+}
+
+// Notify posts the specified alert. Any
+// non-2xx response is an error.
+func (w *Webhook) Notify(ctx context.Context, alert *bus.Alert) error {
+	payload := &webhookPayload{
+		Source:   alert.Source,
+		Number:   alert.Number,
+		Message:  alert.Message,
+		Kind:     alert.Kind,
+		Expected: alert.Expected,
+		Actual:   alert.Actual,
+	}
+	if alert.Account != (common.Address{}) {
+		payload.Account = &alert.Account
+	}
+	if alert.Hash != (common.Hash{}) {
+		payload.Hash = &alert.Hash
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode alert: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := w.c.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post alert: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode/100 != 2 {
+		return fmt.Errorf("failed to post alert: status %d", res.StatusCode)
+	}
+	return nil
+}
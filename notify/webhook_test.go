@@ -0,0 +1,58 @@
+package notify
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sparseth/bus"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestWebhook_Notify(t *testing.T) {
+	t.Run("should post alert as JSON", func(t *testing.T) {
+		var received webhookPayload
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+				t.Errorf("failed to decode body: %v", err)
+			}
+		}))
+		defer srv.Close()
+
+		addr := common.HexToAddress("0xaa")
+		err := NewWebhook(srv.URL).Notify(t.Context(), &bus.Alert{
+			Source:   "transaction-processor",
+			Account:  addr,
+			Number:   10,
+			Message:  "balance mismatch: expected: 1, got: 2",
+			Kind:     "balance",
+			Expected: "1",
+			Actual:   "2",
+		})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if received.Account == nil || *received.Account != addr {
+			t.Errorf("expected account %s, got %v", addr.Hex(), received.Account)
+		}
+		if received.Kind != "balance" || received.Expected != "1" || received.Actual != "2" {
+			t.Errorf("expected balance mismatch, got %+v", received)
+		}
+		if received.Hash != nil {
+			t.Errorf("expected no hash, got %s", received.Hash.Hex())
+		}
+	})
+
+	t.Run("should return error on failure status", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer srv.Close()
+
+		if err := NewWebhook(srv.URL).Notify(t.Context(), &bus.Alert{}); err == nil {
+			t.Errorf("expected error, got nil")
+		}
+	})
+}
@@ -0,0 +1,188 @@
+package sink
+
+import (
+	"errors"
+	"fmt"
+	"sparseth/ethstore"
+	"sparseth/storage"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Content is the verified content of a
+// committed block for the monitored accounts.
+type Content struct {
+	// Events holds the logs verified with
+	// the block, per account (event mode).
+	Events []*types.Log
+	// Diffs holds the state changes of all
+	// monitored accounts changed by the block
+	// (sparse mode).
+	Diffs []*StateDiff
+}
+
+// StateDiff is the verified state change
+// of a single account within a block.
+type StateDiff struct {
+	Address common.Address
+	// Before is the state at the parent
+	// block, or nil if unknown.
+	Before *ethstore.AccountSnapshot
+	// After is the state at the block.
+	After *ethstore.AccountSnapshot
+	// Slots holds the changed storage
+	// slots of the account, if known.
+	Slots []*ethstore.SlotDiff
+}
+
+// Loader reads the verified content of
+// committed blocks from the database.
+type Loader struct {
+	headers   *ethstore.HeaderStore
+	snapshots *ethstore.SnapshotStore
+	heads     *ethstore.ChainHeadStore
+	events    *ethstore.EventStore
+	diffs     *ethstore.DiffStore
+	accounts  func() []common.Address
+}
+
+// NewLoader creates a new Loader for the
+// specified accounts, which reads from the
+// specified key-val store.
+func NewLoader(db storage.KeyValStore, accounts func() []common.Address) *Loader {
+	return &Loader{
+		headers:   ethstore.NewHeaderStore(db),
+		snapshots: ethstore.NewSnapshotStore(db, 0),
+		heads:     ethstore.NewChainHeadStore(db),
+		events:    ethstore.NewEventStore(db, ethstore.EncodingRLP),
+		diffs:     ethstore.NewDiffStore(db),
+		accounts:  accounts,
+	}
+}
+
+// Load returns the verified content of the
+// specified block.
+//
+// Note that state diffs are only available while
+// the account snapshots of the block are kept,
+// see the snapshot-blocks option.
+func (l *Loader) Load(d *ethstore.Delivery) (*Content, error) {
+	slots, err := l.slots(d)
+	if err != nil {
+		return nil, err
+	}
+
+	content := &Content{}
+	for _, addr := range l.accounts() {
+		logs, err := l.logs(addr, d)
+		if err != nil {
+			return nil, err
+		}
+		content.Events = append(content.Events, logs...)
+
+		diff, err := l.diff(addr, d)
+		if err != nil {
+			return nil, err
+		}
+		if diff != nil {
+			diff.Slots = slots[addr]
+			content.Diffs = append(content.Diffs, diff)
+		}
+	}
+	return content, nil
+}
+
+// logs returns the logs of the specified
+// account verified with the specified block.
+func (l *Loader) logs(addr common.Address, d *ethstore.Delivery) ([]*types.Log, error) {
+	head, err := l.heads.At(addr, d.Number)
+	if errors.Is(err, ethstore.ErrChainHeadNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chain head of %s: %w", addr.Hex(), err)
+	}
+	if head.Number != d.Number || head.BlockHash != d.Hash {
+		return nil, nil
+	}
+
+	logs := make([]*types.Log, 0, len(head.Logs))
+	for _, id := range head.Logs {
+		log, err := l.events.GetLog(id.TxHash, uint(id.Index))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get log %s/%d: %w", id.TxHash.Hex(), id.Index, err)
+		}
+		// Metadata is not kept by all encodings
+		log.Address = addr
+		log.BlockNumber = id.BlockNumber
+		log.TxHash = id.TxHash
+		log.Index = uint(id.Index)
+		logs = append(logs, log)
+	}
+	return logs, nil
+}
+
+// diff returns the state change of the specified
+// account within the specified block, or nil if
+// the account is unchanged or has no snapshot.
+func (l *Loader) diff(addr common.Address, d *ethstore.Delivery) (*StateDiff, error) {
+	header, err := l.headers.GetByHash(d.Hash)
+	if errors.Is(err, ethstore.ErrHeaderNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get header of block %d: %w", d.Number, err)
+	}
+
+	after, err := l.snapshots.Get(header, addr)
+	if errors.Is(err, ethstore.ErrSnapshotNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get snapshot of %s: %w", addr.Hex(), err)
+	}
+
+	var before *ethstore.AccountSnapshot
+	if parent, err := l.headers.GetByHash(header.ParentHash); err == nil {
+		before, _ = l.snapshots.Get(parent, addr)
+	}
+	if before != nil && isUnchanged(before, after) {
+		return nil, nil
+	}
+	return &StateDiff{
+		Address: addr,
+		Before:  before,
+		After:   after,
+	}, nil
+}
+
+// slots returns the changed storage slots
+// of all accounts within the specified block.
+func (l *Loader) slots(d *ethstore.Delivery) (map[common.Address][]*ethstore.SlotDiff, error) {
+	diff, err := l.diffs.Get(d.Number)
+	if errors.Is(err, ethstore.ErrDiffNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get state diff of block %d: %w", d.Number, err)
+	}
+	if diff.BlockHash != d.Hash {
+		return nil, nil
+	}
+
+	slots := make(map[common.Address][]*ethstore.SlotDiff, len(diff.Accounts))
+	for _, acc := range diff.Accounts {
+		slots[acc.Address] = acc.Slots
+	}
+	return slots, nil
+}
+
+// isUnchanged checks whether the
+// specified snapshots are equal.
+func isUnchanged(a, b *ethstore.AccountSnapshot) bool {
+	return a.Nonce == b.Nonce &&
+		a.Balance.Cmp(b.Balance) == 0 &&
+		a.CodeHash == b.CodeHash &&
+		a.StorageRoot == b.StorageRoot
+}
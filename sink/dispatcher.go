@@ -0,0 +1,195 @@
+package sink
+
+import (
+	"context"
+	"fmt"
+	"sparseth/bus"
+	"sparseth/ethstore"
+	"sparseth/log"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// retryInterval is the interval at which
+// failed deliveries are retried.
+const retryInterval = 5 * time.Second
+
+// Dispatcher delivers committed blocks to sinks.
+// Each block is appended to a write-ahead log
+// before it is delivered, and each sink's progress
+// is recorded once it acknowledges a delivery, so
+// that every sink receives every committed block
+// in order, and exactly once, even across restarts.
+type Dispatcher struct {
+	commits <-chan *bus.BlockCommit
+	wal     *ethstore.DeliveryLog
+	sinks   []Sink
+	log     log.Logger
+	// notify wakes up the delivery loop
+	// of each sink, by sink name.
+	notify map[string]chan struct{}
+}
+
+// NewDispatcher creates a new Dispatcher that
+// delivers the blocks committed on the specified
+// channel to the specified sinks, using the
+// specified write-ahead log.
+func NewDispatcher(commits <-chan *bus.BlockCommit, wal *ethstore.DeliveryLog, sinks []Sink, log log.Logger) *Dispatcher {
+	notify := make(map[string]chan struct{}, len(sinks))
+	for _, s := range sinks {
+		// Deliver pending entries of
+		// the log on start up
+		notify[s.Name()] = make(chan struct{}, 1)
+		notify[s.Name()] <- struct{}{}
+	}
+
+	return &Dispatcher{
+		commits: commits,
+		wal:     wal,
+		sinks:   sinks,
+		log:     log.With("component", "sink-dispatcher"),
+		notify:  notify,
+	}
+}
+
+// RunContext records and delivers committed
+// blocks until the context is canceled, or
+// the commits channel is closed.
+func (d *Dispatcher) RunContext(ctx context.Context) error {
+	g, ctx := errgroup.WithContext(ctx)
+	for _, s := range d.sinks {
+		g.Go(func() error {
+			d.deliverLoop(ctx, s)
+			return nil
+		})
+	}
+
+	g.Go(func() error {
+		return d.recordLoop(ctx)
+	})
+
+	return g.Wait()
+}
+
+// recordLoop appends each committed block to
+// the write-ahead log, and notifies all sinks.
+func (d *Dispatcher) recordLoop(ctx context.Context) error {
+	for {
+		select {
+		case commit, ok := <-d.commits:
+			if !ok {
+				return nil
+			}
+
+			entry := newDelivery(commit)
+			recorded, err := d.wal.Append(entry)
+			if err != nil {
+				return fmt.Errorf("failed to record delivery of block %d: %w", commit.Number, err)
+			}
+			if !recorded {
+				// Committed again after a restart
+				d.log.Debug("skip recorded block", "num", entry.Number, "hash", entry.Hash.Hex())
+				continue
+			}
+			d.log.Debug("record delivery", "seq", entry.Seq, "num", entry.Number, "hash", entry.Hash.Hex())
+
+			for _, ch := range d.notify {
+				select {
+				case ch <- struct{}{}:
+				default:
+					// Sink is already notified
+				}
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// deliverLoop delivers all pending entries of the
+// write-ahead log to the specified sink whenever
+// new entries are recorded, and retries failed
+// deliveries periodically.
+func (d *Dispatcher) deliverLoop(ctx context.Context, s Sink) {
+	ticker := time.NewTicker(retryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-d.notify[s.Name()]:
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+
+		if err := d.deliverPending(ctx, s); err != nil && ctx.Err() == nil {
+			d.log.Warn("failed to deliver to sink, retry later", "sink", s.Name(), "err", err)
+		}
+	}
+}
+
+// deliverPending delivers all entries of the
+// write-ahead log not yet acknowledged by the
+// specified sink, in order.
+func (d *Dispatcher) deliverPending(ctx context.Context, s Sink) error {
+	acked, err := d.wal.Acked(s.Name())
+	if err != nil {
+		return fmt.Errorf("failed to get acknowledged deliveries: %w", err)
+	}
+
+	pending, err := d.wal.Since(acked)
+	if err != nil {
+		return fmt.Errorf("failed to get pending deliveries: %w", err)
+	}
+
+	for _, entry := range pending {
+		if err = s.Deliver(ctx, entry); err != nil {
+			return fmt.Errorf("failed to deliver block %d: %w", entry.Number, err)
+		}
+		if err = d.wal.Ack(s.Name(), entry.Seq); err != nil {
+			return fmt.Errorf("failed to acknowledge delivery %d: %w", entry.Seq, err)
+		}
+	}
+
+	if len(pending) > 0 {
+		return d.prune()
+	}
+	return nil
+}
+
+// prune removes all entries of the write-ahead
+// log acknowledged by all sinks.
+func (d *Dispatcher) prune() error {
+	var done uint64
+	for i, s := range d.sinks {
+		acked, err := d.wal.Acked(s.Name())
+		if err != nil {
+			return fmt.Errorf("failed to get acknowledged deliveries: %w", err)
+		}
+		if i == 0 || acked < done {
+			done = acked
+		}
+	}
+
+	return d.wal.Prune(done)
+}
+
+// newDelivery creates an entry of the write-ahead
+// log for the specified committed block. Monitors
+// without a result count as failed.
+func newDelivery(commit *bus.BlockCommit) *ethstore.Delivery {
+	var failed []string
+	for _, r := range commit.Results {
+		if !r.Verified() {
+			failed = append(failed, r.Monitor)
+		}
+	}
+	failed = append(failed, commit.Missing()...)
+
+	return &ethstore.Delivery{
+		Number: commit.Number,
+		Hash:   commit.Hash,
+		Failed: failed,
+	}
+}
@@ -0,0 +1,227 @@
+package sink
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sparseth/bus"
+	"sparseth/ethstore"
+	"sparseth/internal/log"
+	"sparseth/storage/mem"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+type testSink struct {
+	name string
+	// failures is the number of deliveries
+	// that fail before deliveries succeed
+	failures int
+	received []uint64
+	mu       sync.Mutex
+}
+
+func (s *testSink) Name() string {
+	return s.name
+}
+
+func (s *testSink) Deliver(_ context.Context, d *ethstore.Delivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.failures > 0 {
+		s.failures--
+		return errors.New("unavailable")
+	}
+	s.received = append(s.received, d.Seq)
+	return nil
+}
+
+func (s *testSink) seqs() []uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]uint64(nil), s.received...)
+}
+
+func testCommit(num uint64) *bus.BlockCommit {
+	return &bus.BlockCommit{
+		Number:  num,
+		Hash:    common.BytesToHash([]byte{byte(num)}),
+		Results: []*bus.Result{{Monitor: "transaction", Number: num}},
+	}
+}
+
+func waitForSeqs(t *testing.T, s *testSink, n int) []uint64 {
+	deadline := time.After(time.Second)
+	for len(s.seqs()) < n {
+		select {
+		case <-deadline:
+			t.Fatalf("expected %d deliveries, got %d", n, len(s.seqs()))
+		case <-time.After(5 * time.Millisecond):
+		}
+	}
+	return s.seqs()
+}
+
+func TestDispatcher_RunContext(t *testing.T) {
+	testLogger := log.New(slog.DiscardHandler)
+
+	t.Run("should deliver committed blocks in order", func(t *testing.T) {
+		db := mem.New()
+		defer db.Close()
+
+		commits := make(chan *bus.BlockCommit, 3)
+		for num := range uint64(3) {
+			commits <- testCommit(num)
+		}
+
+		s := &testSink{name: "test"}
+		d := NewDispatcher(commits, ethstore.NewDeliveryLog(db), []Sink{s}, testLogger)
+
+		ctx, cancel := context.WithCancel(t.Context())
+		defer cancel()
+		go d.RunContext(ctx)
+
+		got := waitForSeqs(t, s, 3)
+		if got[0] != 1 || got[1] != 2 || got[2] != 3 {
+			t.Errorf("expected sequence numbers 1, 2, 3, got %v", got)
+		}
+	})
+
+	t.Run("should resume deliveries after restart", func(t *testing.T) {
+		db := mem.New()
+		defer db.Close()
+		wal := ethstore.NewDeliveryLog(db)
+
+		// Blocks recorded, but not yet
+		// delivered before the restart
+		for num := range uint64(2) {
+			if _, err := wal.Append(newDelivery(testCommit(num))); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+		}
+		if err := wal.Ack("test", 1); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		s := &testSink{name: "test"}
+		d := NewDispatcher(make(chan *bus.BlockCommit), wal, []Sink{s}, testLogger)
+
+		ctx, cancel := context.WithCancel(t.Context())
+		defer cancel()
+		go d.RunContext(ctx)
+
+		got := waitForSeqs(t, s, 1)
+		if len(got) != 1 || got[0] != 2 {
+			t.Errorf("expected sequence number 2 only, got %v", got)
+		}
+	})
+
+	t.Run("should not deliver blocks committed again after restart", func(t *testing.T) {
+		db := mem.New()
+		defer db.Close()
+		s := &testSink{name: "test"}
+
+		run := func(nums ...uint64) {
+			commits := make(chan *bus.BlockCommit, len(nums))
+			for _, num := range nums {
+				commits <- testCommit(num)
+			}
+			close(commits)
+
+			d := NewDispatcher(commits, ethstore.NewDeliveryLog(db), []Sink{s}, testLogger)
+			ctx, cancel := context.WithCancel(t.Context())
+			defer cancel()
+			go d.RunContext(ctx)
+			waitForSeqs(t, s, int(nums[len(nums)-1]))
+		}
+
+		run(1, 2)
+		// Republished from the
+		// checkpoint after restart
+		run(1, 2, 3)
+
+		// Give duplicates time to arrive
+		time.Sleep(50 * time.Millisecond)
+		got := s.seqs()
+		if len(got) != 3 || got[0] != 1 || got[1] != 2 || got[2] != 3 {
+			t.Errorf("expected sequence numbers 1, 2, 3, got %v", got)
+		}
+	})
+
+	t.Run("should prune deliveries acknowledged by all sinks", func(t *testing.T) {
+		db := mem.New()
+		defer db.Close()
+		wal := ethstore.NewDeliveryLog(db)
+
+		commits := make(chan *bus.BlockCommit, 1)
+		commits <- testCommit(1)
+
+		s := &testSink{name: "test"}
+		d := NewDispatcher(commits, wal, []Sink{s}, testLogger)
+
+		ctx, cancel := context.WithCancel(t.Context())
+		defer cancel()
+		go d.RunContext(ctx)
+
+		waitForSeqs(t, s, 1)
+
+		deadline := time.After(time.Second)
+		for {
+			pending, err := wal.Since(0)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if len(pending) == 0 {
+				break
+			}
+
+			select {
+			case <-deadline:
+				t.Fatalf("expected no pending deliveries, got %d", len(pending))
+			case <-time.After(5 * time.Millisecond):
+			}
+		}
+	})
+}
+
+func TestDispatcher_deliverPending(t *testing.T) {
+	testLogger := log.New(slog.DiscardHandler)
+
+	t.Run("should keep failed delivery pending", func(t *testing.T) {
+		db := mem.New()
+		defer db.Close()
+		wal := ethstore.NewDeliveryLog(db)
+
+		if _, err := wal.Append(newDelivery(testCommit(1))); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		s := &testSink{name: "test", failures: 1}
+		d := NewDispatcher(nil, wal, []Sink{s}, testLogger)
+
+		if err := d.deliverPending(t.Context(), s); err == nil {
+			t.Fatalf("expected error, got nil")
+		}
+		if err := d.deliverPending(t.Context(), s); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if got := s.seqs(); len(got) != 1 || got[0] != 1 {
+			t.Errorf("expected sequence number 1 once, got %v", got)
+		}
+	})
+
+	t.Run("should record failed monitors", func(t *testing.T) {
+		commit := testCommit(1)
+		commit.Results[0].Err = errors.New("mismatch")
+
+		if entry := newDelivery(commit); entry.Verified() || entry.Failed[0] != "transaction" {
+			t.Errorf("expected delivery failed by transaction monitor")
+		}
+	})
+}
@@ -0,0 +1,120 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// kafkaContentType is the content type of JSON
+// records of the Kafka REST Proxy v2 API.
+const kafkaContentType = "application/vnd.kafka.json.v2+json"
+
+// Kafka is a Publisher that produces messages to
+// a Kafka topic via a Kafka REST Proxy, e.g., the
+// Confluent REST Proxy, which speaks the binary
+// Kafka protocol to the brokers.
+//
+// Each message is produced with the account as
+// key, so that the messages of an account are
+// kept in order within a single partition.
+type Kafka struct {
+	url   string
+	topic string
+	c     *http.Client
+}
+
+// kafkaRecord is a single record
+// of a produce request.
+type kafkaRecord struct {
+	Key   string          `json:"key"`
+	Value json.RawMessage `json:"value"`
+}
+
+// kafkaOffset is the result of producing
+// a single record.
+type kafkaOffset struct {
+	Partition int     `json:"partition"`
+	Offset    int64   `json:"offset"`
+	ErrorCode *int    `json:"error_code"`
+	Error     *string `json:"error"`
+}
+
+// NewKafka creates a new Kafka publisher that
+// produces to the specified topic via the REST
+// Proxy at the specified URL.
+func NewKafka(url string, topic string) (*Kafka, error) {
+	if topic == "" {
+		return nil, fmt.Errorf("invalid Kafka topic %q", topic)
+	}
+
+	return &Kafka{
+		url:   strings.TrimSuffix(url, "/"),
+		topic: topic,
+		c:     &http.Client{},
+	}, nil
+}
+
+// Name implements Publisher.
+func (k *Kafka) Name() string {
+	return "kafka/" + k.url + "/" + k.topic
+}
+
+// Publish implements Publisher. All messages
+// are produced in a single request, which is
+// acknowledged once every record is written.
+func (k *Kafka) Publish(ctx context.Context, msgs []*Message) error {
+	records := make([]*kafkaRecord, len(msgs))
+	for i, msg := range msgs {
+		records[i] = &kafkaRecord{
+			Key:   msg.Key.Hex(),
+			Value: msg.Value,
+		}
+	}
+	body, err := json.Marshal(map[string]any{"records": records})
+	if err != nil {
+		return fmt.Errorf("failed to encode records: %w", err)
+	}
+
+	endpoint := k.url + "/topics/" + url.PathEscape(k.topic)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", kafkaContentType)
+	req.Header.Set("Accept", "application/vnd.kafka.v2+json")
+
+	res, err := k.c.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to produce to Kafka: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode/100 != 2 {
+		return fmt.Errorf("failed to produce to Kafka: status %d", res.StatusCode)
+	}
+
+	var produced struct {
+		Offsets []*kafkaOffset `json:"offsets"`
+	}
+	if err = json.NewDecoder(res.Body).Decode(&produced); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	if len(produced.Offsets) != len(records) {
+		return fmt.Errorf("failed to produce to Kafka: expected %d offsets, got %d", len(records), len(produced.Offsets))
+	}
+	for i, offset := range produced.Offsets {
+		if offset.ErrorCode != nil || offset.Error != nil {
+			msg := "unknown error"
+			if offset.Error != nil {
+				msg = *offset.Error
+			}
+			return fmt.Errorf("failed to produce record %d to Kafka: %s", i, msg)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package sink
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestKafka_Publish(t *testing.T) {
+	t.Run("should produce records keyed by account", func(t *testing.T) {
+		var got struct {
+			Records []struct {
+				Key   string          `json:"key"`
+				Value json.RawMessage `json:"value"`
+			} `json:"records"`
+		}
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/topics/verified" || r.Header.Get("Content-Type") != kafkaContentType {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			json.NewDecoder(r.Body).Decode(&got)
+			w.Write([]byte(`{"offsets":[{"partition":0,"offset":1,"error_code":null,"error":null}]}`))
+		}))
+		defer srv.Close()
+
+		k, err := NewKafka(srv.URL, "verified")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		acc := common.HexToAddress("0x1")
+		err = k.Publish(t.Context(), []*Message{{Kind: KindEvent, Key: acc, Value: []byte(`{"a":1}`)}})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(got.Records) != 1 || got.Records[0].Key != acc.Hex() || string(got.Records[0].Value) != `{"a":1}` {
+			t.Errorf("expected record keyed by %s, got %+v", acc.Hex(), got.Records)
+		}
+	})
+
+	t.Run("should fail on record error", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"offsets":[{"partition":null,"offset":null,"error_code":50002,"error":"broker unavailable"}]}`))
+		}))
+		defer srv.Close()
+
+		k, err := NewKafka(srv.URL, "verified")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		err = k.Publish(t.Context(), []*Message{{Kind: KindState, Value: []byte(`{}`)}})
+		if err == nil {
+			t.Errorf("expected error, got nil")
+		}
+	})
+
+	t.Run("should fail on error status", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer srv.Close()
+
+		k, err := NewKafka(srv.URL, "verified")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		err = k.Publish(t.Context(), []*Message{{Kind: KindState, Value: []byte(`{}`)}})
+		if err == nil {
+			t.Errorf("expected error, got nil")
+		}
+	})
+}
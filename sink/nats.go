@@ -0,0 +1,241 @@
+package sink
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// natsDefaultPort is the default
+// client port of NATS servers.
+const natsDefaultPort = "4222"
+
+// NATS is a Publisher that publishes messages
+// to a NATS server via the core NATS protocol.
+//
+// Each message is published to the subject
+// <subject>.<kind>.<account>, so that consumers
+// can subscribe to a kind or an account with
+// wildcards. Publishing is confirmed by a round
+// trip to the server, which guarantees that the
+// server received the messages, but not that
+// any subscriber did (use JetStream for that).
+//
+// Note that TLS is not supported.
+type NATS struct {
+	addr    string
+	user    string
+	pass    string
+	subject string
+
+	conn net.Conn
+	r    *bufio.Reader
+	// maxPayload is the maximum message
+	// size announced by the server.
+	maxPayload int
+	mu         sync.Mutex
+}
+
+// natsConnect holds the options of
+// the CONNECT protocol message.
+type natsConnect struct {
+	Verbose  bool   `json:"verbose"`
+	Pedantic bool   `json:"pedantic"`
+	Name     string `json:"name"`
+	Lang     string `json:"lang"`
+	User     string `json:"user,omitempty"`
+	Pass     string `json:"pass,omitempty"`
+}
+
+// natsInfo holds the relevant fields
+// of the INFO protocol message.
+type natsInfo struct {
+	TLSRequired bool `json:"tls_required"`
+	MaxPayload  int  `json:"max_payload"`
+}
+
+// NewNATS creates a new NATS publisher for the
+// server at the specified URL, e.g., nats://
+// user:pass@localhost:4222, which publishes
+// below the specified subject.
+func NewNATS(rawURL string, subject string) (*NATS, error) {
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "nats://" + rawURL
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid NATS URL: %w", err)
+	}
+	if u.Scheme != "nats" {
+		return nil, fmt.Errorf("unsupported NATS scheme %q", u.Scheme)
+	}
+	if subject == "" || strings.ContainsAny(subject, " \t\r\n*>") {
+		return nil, fmt.Errorf("invalid NATS subject %q", subject)
+	}
+
+	addr := u.Host
+	if u.Port() == "" {
+		addr = net.JoinHostPort(u.Hostname(), natsDefaultPort)
+	}
+	pass, _ := u.User.Password()
+
+	return &NATS{
+		addr:    addr,
+		user:    u.User.Username(),
+		pass:    pass,
+		subject: subject,
+	}, nil
+}
+
+// Name implements Publisher.
+func (n *NATS) Name() string {
+	return "nats/" + n.addr + "/" + n.subject
+}
+
+// Publish implements Publisher. The connection
+// is established on demand, and re-established
+// by the next call if publishing fails.
+func (n *NATS) Publish(ctx context.Context, msgs []*Message) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if err := n.publish(ctx, msgs); err != nil {
+		n.close()
+		return err
+	}
+	return nil
+}
+
+// Close closes the connection, if any.
+func (n *NATS) Close() error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.close()
+	return nil
+}
+
+// publish writes all messages followed by a
+// PING, and waits for the server's PONG.
+//
+// Note that the caller must hold the lock.
+func (n *NATS) publish(ctx context.Context, msgs []*Message) error {
+	if n.conn == nil {
+		if err := n.connect(ctx); err != nil {
+			return err
+		}
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		n.conn.SetDeadline(deadline)
+	} else {
+		n.conn.SetDeadline(time.Time{})
+	}
+
+	w := bufio.NewWriter(n.conn)
+	for _, msg := range msgs {
+		if n.maxPayload > 0 && len(msg.Value) > n.maxPayload {
+			return fmt.Errorf("message of %d bytes exceeds NATS max payload of %d bytes", len(msg.Value), n.maxPayload)
+		}
+		subject := n.subject + "." + msg.Kind + "." + msg.Key.Hex()
+		fmt.Fprintf(w, "PUB %s %d\r\n", subject, len(msg.Value))
+		w.Write(msg.Value)
+		w.WriteString("\r\n")
+	}
+	w.WriteString("PING\r\n")
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to publish to NATS: %w", err)
+	}
+
+	for {
+		line, err := n.readLine()
+		if err != nil {
+			return fmt.Errorf("failed to confirm publish to NATS: %w", err)
+		}
+		switch {
+		case line == "PONG":
+			return nil
+		case line == "PING":
+			if _, err = n.conn.Write([]byte("PONG\r\n")); err != nil {
+				return fmt.Errorf("failed to reply to NATS: %w", err)
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			return fmt.Errorf("NATS error: %s", strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+		}
+		// Ignore +OK and INFO updates
+	}
+}
+
+// connect dials the server and completes
+// the handshake.
+//
+// Note that the caller must hold the lock.
+func (n *NATS) connect(ctx context.Context) error {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", n.addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to NATS: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	n.conn = conn
+	n.r = bufio.NewReader(conn)
+
+	line, err := n.readLine()
+	if err != nil {
+		return fmt.Errorf("failed to read NATS info: %w", err)
+	}
+	info, ok := strings.CutPrefix(line, "INFO ")
+	if !ok {
+		return fmt.Errorf("unexpected NATS greeting %q", line)
+	}
+	var parsed natsInfo
+	if err = json.Unmarshal([]byte(info), &parsed); err != nil {
+		return fmt.Errorf("failed to decode NATS info: %w", err)
+	}
+	if parsed.TLSRequired {
+		return fmt.Errorf("NATS server requires TLS, which is not supported")
+	}
+	n.maxPayload = parsed.MaxPayload
+
+	connect, err := json.Marshal(&natsConnect{
+		Name: "sparseth",
+		Lang: "go",
+		User: n.user,
+		Pass: n.pass,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode NATS connect: %w", err)
+	}
+	if _, err = fmt.Fprintf(conn, "CONNECT %s\r\n", connect); err != nil {
+		return fmt.Errorf("failed to send NATS connect: %w", err)
+	}
+	return nil
+}
+
+// readLine reads a single protocol line
+// without the trailing CRLF.
+func (n *NATS) readLine() (string, error) {
+	line, err := n.r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// close closes the connection, if any.
+//
+// Note that the caller must hold the lock.
+func (n *NATS) close() {
+	if n.conn != nil {
+		n.conn.Close()
+		n.conn = nil
+		n.r = nil
+	}
+}
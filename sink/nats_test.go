@@ -0,0 +1,128 @@
+package sink
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// newTestNATSServer starts a minimal NATS server that
+// records published subjects and payloads, and replies
+// with the specified line to each PING.
+func newTestNATSServer(t *testing.T, reply string) (string, <-chan string) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	published := make(chan string, 16)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		fmt.Fprint(conn, "INFO {\"max_payload\":1024}\r\n")
+		r := bufio.NewReader(conn)
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+
+			switch {
+			case strings.HasPrefix(line, "PUB "):
+				var subject string
+				var size int
+				fmt.Sscanf(line, "PUB %s %d", &subject, &size)
+				payload := make([]byte, size+2)
+				if _, err = io.ReadFull(r, payload); err != nil {
+					return
+				}
+				published <- subject + " " + string(payload[:size])
+			case line == "PING":
+				fmt.Fprint(conn, reply+"\r\n")
+			}
+		}
+	}()
+	return ln.Addr().String(), published
+}
+
+func TestNATS_Publish(t *testing.T) {
+	t.Run("should publish to subject of kind and account", func(t *testing.T) {
+		addr, published := newTestNATSServer(t, "PONG")
+
+		n, err := NewNATS("nats://"+addr, "sparseth")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		defer n.Close()
+
+		acc := common.HexToAddress("0x1")
+		err = n.Publish(t.Context(), []*Message{{Kind: KindEvent, Key: acc, Value: []byte(`{"a":1}`)}})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		expected := "sparseth.event." + acc.Hex() + ` {"a":1}`
+		if got := <-published; got != expected {
+			t.Errorf("expected %s, got %s", expected, got)
+		}
+	})
+
+	t.Run("should fail on server error", func(t *testing.T) {
+		addr, _ := newTestNATSServer(t, "-ERR 'Permissions Violation'")
+
+		n, err := NewNATS(addr, "sparseth")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		defer n.Close()
+
+		err = n.Publish(t.Context(), []*Message{{Kind: KindState, Value: []byte(`{}`)}})
+		if err == nil || !strings.Contains(err.Error(), "Permissions Violation") {
+			t.Errorf("expected permissions error, got %v", err)
+		}
+	})
+
+	t.Run("should reject payload above max payload", func(t *testing.T) {
+		addr, _ := newTestNATSServer(t, "PONG")
+
+		n, err := NewNATS(addr, "sparseth")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		defer n.Close()
+
+		err = n.Publish(t.Context(), []*Message{{Kind: KindState, Value: make([]byte, 2048)}})
+		if err == nil {
+			t.Errorf("expected error, got nil")
+		}
+	})
+}
+
+func TestNewNATS(t *testing.T) {
+	t.Run("should use default port", func(t *testing.T) {
+		n, err := NewNATS("nats://localhost", "sparseth")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if n.addr != "localhost:4222" {
+			t.Errorf("expected localhost:4222, got %s", n.addr)
+		}
+	})
+
+	t.Run("should reject wildcard subject", func(t *testing.T) {
+		if _, err := NewNATS("localhost", "sparseth.*"); err == nil {
+			t.Errorf("expected error, got nil")
+		}
+	})
+}
@@ -0,0 +1,41 @@
+package sink
+
+import (
+	"context"
+	"sparseth/ethstore"
+)
+
+// Sink is the interface of an export target for
+// committed blocks, e.g., a message queue or a
+// database.
+//
+// Deliveries are recorded in a write-ahead log
+// before they are passed to a sink, and are
+// retried until the sink acknowledges them by
+// returning no error. After a crash, a delivery
+// may be passed again if it was not acknowledged
+// before. Sinks detect such duplicates by their
+// sequence number, which is strictly increasing
+// without gaps.
+type Sink interface {
+	// Name uniquely identifies the sink, and must
+	// be stable across restarts of the node.
+	Name() string
+
+	// Deliver exports the specified delivery.
+	Deliver(ctx context.Context, d *ethstore.Delivery) error
+}
+
+// ReportSink is implemented by sinks that also
+// receive periodic digest reports, which summarize
+// the committed blocks per account, e.g., for teams
+// that prefer summaries over per-block exports.
+//
+// Unlike blocks, reports are delivered once, without
+// retries, as they remain available in the database.
+type ReportSink interface {
+	Sink
+
+	// DeliverReport exports the specified report.
+	DeliverReport(ctx context.Context, r *ethstore.Report) error
+}
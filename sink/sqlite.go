@@ -0,0 +1,197 @@
+package sink
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sparseth/ethstore"
+	"sparseth/execution/monitor/event"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// sqliteSchema creates the tables
+// of the SQLite sink, if missing.
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS blocks (
+	number INTEGER PRIMARY KEY,
+	hash   TEXT    NOT NULL,
+	seq    INTEGER NOT NULL
+);
+CREATE TABLE IF NOT EXISTS events (
+	number    INTEGER NOT NULL,
+	tx_hash   TEXT    NOT NULL,
+	log_index INTEGER NOT NULL,
+	account   TEXT    NOT NULL,
+	name      TEXT,
+	signature TEXT,
+	args      TEXT,
+	topics    TEXT    NOT NULL,
+	data      TEXT    NOT NULL,
+	PRIMARY KEY (tx_hash, log_index)
+);
+CREATE INDEX IF NOT EXISTS events_by_account ON events (account, name, number);
+CREATE TABLE IF NOT EXISTS accounts (
+	number  INTEGER NOT NULL,
+	account TEXT    NOT NULL,
+	nonce   INTEGER NOT NULL,
+	balance TEXT    NOT NULL,
+	PRIMARY KEY (account, number)
+);
+`
+
+// Decoder decodes a verified log with
+// the ABI of the emitting contract.
+type Decoder func(log *types.Log) (*event.Event, error)
+
+// SQLite is a Sink that writes every verified
+// decoded event, and the balance and nonce of
+// every monitored account changed by a block,
+// into the tables of a SQLite database:
+//
+//   - blocks: the exported blocks
+//   - events: the events by transaction hash
+//     and log index, with their args as JSON
+//   - accounts: the nonce and balance of each
+//     account after each block that changed it
+//
+// Rows are replaced on redelivery, so that a
+// block is written at most once.
+//
+// Note that the SQLite driver requires cgo.
+type SQLite struct {
+	path   string
+	db     *sql.DB
+	loader *Loader
+	decode Decoder
+}
+
+// NewSQLite creates a new SQLite sink that writes
+// the content loaded by the specified loader into
+// the database at the specified path, decoding
+// events with the specified decoder.
+func NewSQLite(path string, loader *Loader, decode Decoder) (*SQLite, error) {
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open SQLite database: %w", err)
+	}
+	if _, err = db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create SQLite tables: %w", err)
+	}
+
+	return &SQLite{
+		path:   path,
+		db:     db,
+		loader: loader,
+		decode: decode,
+	}, nil
+}
+
+// Name implements Sink.
+func (s *SQLite) Name() string {
+	return "sqlite/" + s.path
+}
+
+// Close closes the database.
+func (s *SQLite) Close() error {
+	return s.db.Close()
+}
+
+// Deliver writes the verified content of the
+// specified block in a single transaction.
+// Blocks that failed to verify are skipped.
+func (s *SQLite) Deliver(ctx context.Context, d *ethstore.Delivery) error {
+	if !d.Verified() {
+		return nil
+	}
+
+	content, err := s.loader.Load(d)
+	if err != nil {
+		return fmt.Errorf("failed to load block %d: %w", d.Number, err)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, "INSERT OR REPLACE INTO blocks (number, hash, seq) VALUES (?, ?, ?)", d.Number, d.Hash.Hex(), d.Seq)
+	if err != nil {
+		return fmt.Errorf("failed to insert block %d: %w", d.Number, err)
+	}
+	for _, log := range content.Events {
+		if err = s.insertEvent(ctx, tx, log); err != nil {
+			return err
+		}
+	}
+	for _, diff := range content.Diffs {
+		_, err = tx.ExecContext(ctx, "INSERT OR REPLACE INTO accounts (number, account, nonce, balance) VALUES (?, ?, ?, ?)",
+			d.Number, diff.Address.Hex(), diff.After.Nonce, diff.After.Balance.String())
+		if err != nil {
+			return fmt.Errorf("failed to insert account %s at block %d: %w", diff.Address.Hex(), d.Number, err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit block %d: %w", d.Number, err)
+	}
+	return nil
+}
+
+// insertEvent decodes and inserts the specified
+// log. Logs that cannot be decoded are inserted
+// without name and args.
+func (s *SQLite) insertEvent(ctx context.Context, tx *sql.Tx, log *types.Log) error {
+	var name, sig, args sql.NullString
+	if decoded, err := s.decode(log); err == nil {
+		encoded, err := json.Marshal(toJSONArgs(decoded.Args))
+		if err != nil {
+			return fmt.Errorf("failed to encode args of log %s/%d: %w", log.TxHash.Hex(), log.Index, err)
+		}
+		name = sql.NullString{String: decoded.Name, Valid: true}
+		sig = sql.NullString{String: decoded.Sig, Valid: true}
+		args = sql.NullString{String: string(encoded), Valid: true}
+	}
+
+	topics, err := json.Marshal(log.Topics)
+	if err != nil {
+		return fmt.Errorf("failed to encode topics of log %s/%d: %w", log.TxHash.Hex(), log.Index, err)
+	}
+
+	_, err = tx.ExecContext(ctx, `INSERT OR REPLACE INTO events
+		(number, tx_hash, log_index, account, name, signature, args, topics, data)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		log.BlockNumber, log.TxHash.Hex(), log.Index, log.Address.Hex(), name, sig, args, string(topics), hexutil.Encode(log.Data))
+	if err != nil {
+		return fmt.Errorf("failed to insert log %s/%d: %w", log.TxHash.Hex(), log.Index, err)
+	}
+	return nil
+}
+
+// toJSONArgs converts the specified event args
+// into values that are readable as JSON, i.e.,
+// integers as decimal strings, so that they are
+// not rounded, and bytes as hex strings.
+func toJSONArgs(args map[string]any) map[string]any {
+	converted := make(map[string]any, len(args))
+	for name, arg := range args {
+		switch v := arg.(type) {
+		case *big.Int:
+			converted[name] = v.String()
+		case []byte:
+			converted[name] = hexutil.Encode(v)
+		case [32]byte:
+			converted[name] = common.Hash(v).Hex()
+		default:
+			converted[name] = v
+		}
+	}
+	return converted
+}
@@ -0,0 +1,96 @@
+package sink
+
+import (
+	"errors"
+	"math/big"
+	"path/filepath"
+	"sparseth/ethstore"
+	"sparseth/execution/monitor/event"
+	"sparseth/storage/mem"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestSQLite_Deliver(t *testing.T) {
+	accounts := func() []common.Address {
+		return []common.Address{testAccount}
+	}
+	decode := func(log *types.Log) (*event.Event, error) {
+		return &event.Event{
+			Name: "Transfer",
+			Sig:  "Transfer(uint256)",
+			Log:  log,
+			Args: map[string]any{"value": big.NewInt(42)},
+		}, nil
+	}
+
+	t.Run("should write decoded events and balances", func(t *testing.T) {
+		db := mem.New()
+		parent, header := testHeaders()
+		putTestLogs(t, db, header)
+		putTestSnapshots(t, db, parent, header)
+
+		s, err := NewSQLite(filepath.Join(t.TempDir(), "export.db"), NewLoader(db, accounts), decode)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		defer s.Close()
+
+		d := &ethstore.Delivery{Seq: 1, Number: 10, Hash: header.Hash()}
+		// Redelivery must not duplicate rows
+		for range 2 {
+			if err = s.Deliver(t.Context(), d); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+		}
+
+		var count int
+		var name, args string
+		err = s.db.QueryRow("SELECT COUNT(*), MAX(name), MAX(args) FROM events WHERE account = ?", testAccount.Hex()).Scan(&count, &name, &args)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if count != 1 || name != "Transfer" || args != `{"value":"42"}` {
+			t.Errorf("expected 1 Transfer with value 42, got %d %s %s", count, name, args)
+		}
+
+		var nonce uint64
+		var balance string
+		err = s.db.QueryRow("SELECT nonce, balance FROM accounts WHERE account = ? AND number = 10", testAccount.Hex()).Scan(&nonce, &balance)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if nonce != 1 || balance != "100" {
+			t.Errorf("expected nonce 1 and balance 100, got %d and %s", nonce, balance)
+		}
+	})
+
+	t.Run("should write undecodable event without name", func(t *testing.T) {
+		db := mem.New()
+		_, header := testHeaders()
+		putTestLogs(t, db, header)
+
+		failing := func(*types.Log) (*event.Event, error) {
+			return nil, errors.New("no ABI")
+		}
+		s, err := NewSQLite(filepath.Join(t.TempDir(), "export.db"), NewLoader(db, accounts), failing)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		defer s.Close()
+
+		if err = s.Deliver(t.Context(), &ethstore.Delivery{Seq: 1, Number: 10, Hash: header.Hash()}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		var decoded int
+		if err = s.db.QueryRow("SELECT COUNT(name) FROM events").Scan(&decoded); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if decoded != 0 {
+			t.Errorf("expected no decoded events, got %d", decoded)
+		}
+	})
+}
@@ -0,0 +1,212 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sparseth/ethstore"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// publishTimeout is the maximum duration
+// of publishing a single block.
+const publishTimeout = 10 * time.Second
+
+const (
+	// KindEvent is the kind of messages
+	// holding a verified event.
+	KindEvent = "event"
+	// KindState is the kind of messages
+	// holding a verified state diff.
+	KindState = "state"
+)
+
+// Message is a single message
+// published to a message bus.
+type Message struct {
+	// Kind is the kind of the message,
+	// either KindEvent or KindState.
+	Kind string
+	// Key is the account the
+	// message relates to.
+	Key common.Address
+	// Value is the JSON payload.
+	Value []byte
+}
+
+// Publisher is the interface of a
+// message bus, e.g., Kafka or NATS.
+type Publisher interface {
+	// Name uniquely identifies the publisher,
+	// and must be stable across restarts.
+	Name() string
+
+	// Publish publishes the specified messages,
+	// and returns once they are acknowledged.
+	Publish(ctx context.Context, msgs []*Message) error
+}
+
+// StreamSink is a Sink that publishes every
+// verified event and per-block state diff of
+// the monitored accounts to a message bus.
+//
+// Messages are published at least once. Since
+// each message holds the sequence number of its
+// delivery, consumers detect duplicates.
+type StreamSink struct {
+	pub    Publisher
+	loader *Loader
+}
+
+// NewStreamSink creates a new StreamSink that
+// publishes the content loaded by the specified
+// loader via the specified publisher.
+func NewStreamSink(pub Publisher, loader *Loader) *StreamSink {
+	return &StreamSink{
+		pub:    pub,
+		loader: loader,
+	}
+}
+
+// Name implements Sink.
+func (s *StreamSink) Name() string {
+	return s.pub.Name()
+}
+
+// Deliver publishes the verified content of the
+// specified block. Blocks that failed to verify
+// are skipped.
+func (s *StreamSink) Deliver(ctx context.Context, d *ethstore.Delivery) error {
+	if !d.Verified() {
+		return nil
+	}
+
+	content, err := s.loader.Load(d)
+	if err != nil {
+		return fmt.Errorf("failed to load block %d: %w", d.Number, err)
+	}
+	msgs, err := newMessages(d, content)
+	if err != nil {
+		return err
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, publishTimeout)
+	defer cancel()
+
+	return s.pub.Publish(ctx, msgs)
+}
+
+// messagePayload is the JSON
+// payload of each message.
+type messagePayload struct {
+	Kind    string         `json:"kind"`
+	Seq     uint64         `json:"seq"`
+	Number  uint64         `json:"number"`
+	Hash    common.Hash    `json:"hash"`
+	Account common.Address `json:"account"`
+	Event   *types.Log     `json:"event,omitempty"`
+	Before  *snapshotJSON  `json:"before,omitempty"`
+	After   *snapshotJSON  `json:"after,omitempty"`
+	Slots   []*slotJSON    `json:"slots,omitempty"`
+}
+
+// slotJSON is the JSON encoding
+// of a changed storage slot.
+type slotJSON struct {
+	Slot   common.Hash `json:"slot"`
+	Before common.Hash `json:"before"`
+	After  common.Hash `json:"after"`
+}
+
+// snapshotJSON is the JSON
+// encoding of a snapshot.
+type snapshotJSON struct {
+	Nonce       hexutil.Uint64 `json:"nonce"`
+	Balance     *hexutil.Big   `json:"balance"`
+	CodeHash    common.Hash    `json:"codeHash"`
+	StorageRoot common.Hash    `json:"storageRoot"`
+}
+
+// newMessages encodes the specified
+// content of the specified block.
+func newMessages(d *ethstore.Delivery, content *Content) ([]*Message, error) {
+	msgs := make([]*Message, 0, len(content.Events)+len(content.Diffs))
+	for _, log := range content.Events {
+		msg, err := newMessage(d, &messagePayload{
+			Kind:    KindEvent,
+			Account: log.Address,
+			Event:   log,
+		})
+		if err != nil {
+			return nil, err
+		}
+		msgs = append(msgs, msg)
+	}
+	for _, diff := range content.Diffs {
+		msg, err := newMessage(d, &messagePayload{
+			Kind:    KindState,
+			Account: diff.Address,
+			Before:  toSnapshotJSON(diff.Before),
+			After:   toSnapshotJSON(diff.After),
+			Slots:   toSlotsJSON(diff.Slots),
+		})
+		if err != nil {
+			return nil, err
+		}
+		msgs = append(msgs, msg)
+	}
+	return msgs, nil
+}
+
+// newMessage completes and encodes
+// the specified payload.
+func newMessage(d *ethstore.Delivery, payload *messagePayload) (*Message, error) {
+	payload.Seq = d.Seq
+	payload.Number = d.Number
+	payload.Hash = d.Hash
+
+	value, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s of block %d: %w", payload.Kind, d.Number, err)
+	}
+	return &Message{
+		Kind:  payload.Kind,
+		Key:   payload.Account,
+		Value: value,
+	}, nil
+}
+
+// toSnapshotJSON converts the specified
+// snapshot, which may be nil.
+func toSnapshotJSON(s *ethstore.AccountSnapshot) *snapshotJSON {
+	if s == nil {
+		return nil
+	}
+	return &snapshotJSON{
+		Nonce:       hexutil.Uint64(s.Nonce),
+		Balance:     (*hexutil.Big)(s.Balance),
+		CodeHash:    s.CodeHash,
+		StorageRoot: s.StorageRoot,
+	}
+}
+
+// toSlotsJSON converts the
+// specified slot diffs.
+func toSlotsJSON(slots []*ethstore.SlotDiff) []*slotJSON {
+	converted := make([]*slotJSON, len(slots))
+	for i, slot := range slots {
+		converted[i] = &slotJSON{
+			Slot:   slot.Slot,
+			Before: slot.Before,
+			After:  slot.After,
+		}
+	}
+	return converted
+}
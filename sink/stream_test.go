@@ -0,0 +1,216 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"sparseth/ethstore"
+	"sparseth/storage"
+	"sparseth/storage/mem"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+type testPublisher struct {
+	published [][]*Message
+}
+
+func (p *testPublisher) Name() string {
+	return "test"
+}
+
+func (p *testPublisher) Publish(_ context.Context, msgs []*Message) error {
+	p.published = append(p.published, msgs)
+	return nil
+}
+
+var testAccount = common.HexToAddress("0x1")
+
+func putTestLogs(t *testing.T, db storage.KeyValStore, header *types.Header) {
+	log := &types.Log{
+		Address:     testAccount,
+		Topics:      []common.Hash{common.HexToHash("0xaa")},
+		Data:        []byte{0x01},
+		BlockNumber: header.Number.Uint64(),
+		TxHash:      common.HexToHash("0xbb"),
+		Index:       3,
+	}
+	if err := ethstore.NewEventStore(db, ethstore.EncodingRLP).PutAll([]*types.Log{log}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	err := ethstore.NewChainHeadStore(db).Put(testAccount, &ethstore.ChainHead{
+		Number:    header.Number.Uint64(),
+		BlockHash: header.Hash(),
+		Head:      common.HexToHash("0xcc"),
+		Logs:      []*ethstore.LogID{{BlockNumber: log.BlockNumber, TxHash: log.TxHash, Index: uint64(log.Index)}},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
+func putTestSnapshots(t *testing.T, db storage.KeyValStore, parent, header *types.Header) {
+	headers := ethstore.NewHeaderStore(db)
+	snapshots := ethstore.NewSnapshotStore(db, 128)
+	for i, h := range []*types.Header{parent, header} {
+		if err := headers.Put(h); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		err := snapshots.PutAll(h, []*ethstore.AccountSnapshot{{
+			Address: testAccount,
+			Nonce:   uint64(i),
+			Balance: big.NewInt(100),
+		}})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+}
+
+func testHeaders() (*types.Header, *types.Header) {
+	parent := &types.Header{Number: big.NewInt(9)}
+	header := &types.Header{Number: big.NewInt(10), ParentHash: parent.Hash()}
+	return parent, header
+}
+
+func TestLoader_Load(t *testing.T) {
+	accounts := func() []common.Address {
+		return []common.Address{testAccount}
+	}
+
+	t.Run("should load logs verified with block", func(t *testing.T) {
+		db := mem.New()
+		_, header := testHeaders()
+		putTestLogs(t, db, header)
+
+		content, err := NewLoader(db, accounts).Load(&ethstore.Delivery{Number: 10, Hash: header.Hash()})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(content.Events) != 1 {
+			t.Fatalf("expected 1 event, got %d", len(content.Events))
+		}
+		if content.Events[0].TxHash != common.HexToHash("0xbb") || content.Events[0].Index != 3 {
+			t.Errorf("expected log 0xbb/3, got %s/%d", content.Events[0].TxHash.Hex(), content.Events[0].Index)
+		}
+	})
+
+	t.Run("should not load logs of earlier block", func(t *testing.T) {
+		db := mem.New()
+		_, header := testHeaders()
+		putTestLogs(t, db, header)
+
+		content, err := NewLoader(db, accounts).Load(&ethstore.Delivery{Number: 11, Hash: common.HexToHash("0x11")})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(content.Events) != 0 {
+			t.Errorf("expected no events, got %d", len(content.Events))
+		}
+	})
+
+	t.Run("should load state diff", func(t *testing.T) {
+		db := mem.New()
+		parent, header := testHeaders()
+		putTestSnapshots(t, db, parent, header)
+
+		content, err := NewLoader(db, accounts).Load(&ethstore.Delivery{Number: 10, Hash: header.Hash()})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(content.Diffs) != 1 {
+			t.Fatalf("expected 1 diff, got %d", len(content.Diffs))
+		}
+		if content.Diffs[0].Before.Nonce != 0 || content.Diffs[0].After.Nonce != 1 {
+			t.Errorf("expected nonce 0 -> 1, got %d -> %d", content.Diffs[0].Before.Nonce, content.Diffs[0].After.Nonce)
+		}
+	})
+
+	t.Run("should load changed slots of state diff", func(t *testing.T) {
+		db := mem.New()
+		parent, header := testHeaders()
+		putTestSnapshots(t, db, parent, header)
+		err := ethstore.NewDiffStore(db).Put(&ethstore.StateDiff{
+			Number:    10,
+			BlockHash: header.Hash(),
+			Accounts: []*ethstore.AccountDiff{{
+				Address:       testAccount,
+				BalanceBefore: big.NewInt(100),
+				BalanceAfter:  big.NewInt(100),
+				Slots:         []*ethstore.SlotDiff{{Slot: common.HexToHash("0x01"), After: common.HexToHash("0x02")}},
+			}},
+		})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		content, err := NewLoader(db, accounts).Load(&ethstore.Delivery{Number: 10, Hash: header.Hash()})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(content.Diffs) != 1 || len(content.Diffs[0].Slots) != 1 {
+			t.Fatalf("expected 1 diff with 1 slot, got %+v", content.Diffs)
+		}
+		if content.Diffs[0].Slots[0].After != common.HexToHash("0x02") {
+			t.Errorf("expected slot value 0x02, got %s", content.Diffs[0].Slots[0].After.Hex())
+		}
+	})
+}
+
+func TestStreamSink_Deliver(t *testing.T) {
+	accounts := func() []common.Address {
+		return []common.Address{testAccount}
+	}
+
+	t.Run("should publish events and state diffs", func(t *testing.T) {
+		db := mem.New()
+		parent, header := testHeaders()
+		putTestLogs(t, db, header)
+		putTestSnapshots(t, db, parent, header)
+
+		pub := &testPublisher{}
+		s := NewStreamSink(pub, NewLoader(db, accounts))
+		if err := s.Deliver(t.Context(), &ethstore.Delivery{Seq: 7, Number: 10, Hash: header.Hash()}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if len(pub.published) != 1 || len(pub.published[0]) != 2 {
+			t.Fatalf("expected 2 messages in 1 batch, got %v", pub.published)
+		}
+		kinds := []string{KindEvent, KindState}
+		for i, msg := range pub.published[0] {
+			if msg.Kind != kinds[i] || msg.Key != testAccount {
+				t.Errorf("expected %s of %s, got %s of %s", kinds[i], testAccount.Hex(), msg.Kind, msg.Key.Hex())
+			}
+
+			var payload struct {
+				Kind string `json:"kind"`
+				Seq  uint64 `json:"seq"`
+			}
+			if err := json.Unmarshal(msg.Value, &payload); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if payload.Kind != kinds[i] || payload.Seq != 7 {
+				t.Errorf("expected %s with seq 7, got %s with seq %d", kinds[i], payload.Kind, payload.Seq)
+			}
+		}
+	})
+
+	t.Run("should skip failed block", func(t *testing.T) {
+		db := mem.New()
+		_, header := testHeaders()
+		putTestLogs(t, db, header)
+
+		pub := &testPublisher{}
+		s := NewStreamSink(pub, NewLoader(db, accounts))
+		err := s.Deliver(t.Context(), &ethstore.Delivery{Number: 10, Hash: header.Hash(), Failed: []string{"log-processor"}})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(pub.published) != 0 {
+			t.Errorf("expected nothing published, got %v", pub.published)
+		}
+	})
+}
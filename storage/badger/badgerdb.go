@@ -5,9 +5,36 @@ import (
 	"errors"
 	"fmt"
 	"github.com/dgraph-io/badger/v4"
+	"os"
+	"path/filepath"
 	"sparseth/storage"
+	"time"
 )
 
+// encryptedIndexCacheSize is the size of the
+// cache of decrypted table indices, which are
+// otherwise decrypted on every read.
+const encryptedIndexCacheSize = 100 << 20
+
+// Options configures a badger datastore.
+type Options struct {
+	// ReadOnly opens an existing datastore in
+	// read-only mode, which only takes a shared
+	// lock, so that it can be opened by several
+	// readers at once, but not while opened by
+	// a writer.
+	ReadOnly bool
+	// EncryptionKey is the AES key of 16, 24, or
+	// 32 bytes all data is encrypted with on disk,
+	// or empty if unencrypted. The key cannot be
+	// changed for an existing datastore.
+	EncryptionKey []byte
+	// KeyRotation is the interval at which new data
+	// keys are generated, which are encrypted with
+	// the encryption key, 10 days apply if zero.
+	KeyRotation time.Duration
+}
+
 // Database is a badger key-val store.
 type Database struct {
 	db *badger.DB
@@ -16,7 +43,26 @@ type Database struct {
 // New creates a new badger datastore
 // instance at the specified path.
 func New(path string) (*Database, error) {
-	opts := badger.DefaultOptions(path).WithLogger(nil)
+	return NewWith(path, Options{})
+}
+
+// NewReadOnly opens the existing badger datastore
+// at the specified path in read-only mode, see
+// Options.ReadOnly.
+func NewReadOnly(path string) (*Database, error) {
+	return NewWith(path, Options{ReadOnly: true})
+}
+
+// NewWith creates a new badger datastore instance
+// at the specified path with the specified options.
+func NewWith(path string, o Options) (*Database, error) {
+	opts := badger.DefaultOptions(path).WithLogger(nil).WithReadOnly(o.ReadOnly)
+	if len(o.EncryptionKey) > 0 {
+		opts = opts.WithEncryptionKey(o.EncryptionKey).WithIndexCacheSize(encryptedIndexCacheSize)
+		if o.KeyRotation > 0 {
+			opts = opts.WithEncryptionKeyRotationDuration(o.KeyRotation)
+		}
+	}
 	db, err := badger.Open(opts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open db: %w", err)
@@ -84,6 +130,13 @@ func (db *Database) Stat() (string, error) {
 	return fmt.Sprintf("Badger DB lsm size: %d bytes, value log file size: %d bytes", lsmSize, vlogSize), nil
 }
 
+// Size returns the size of the LSM
+// tree and the value log in bytes.
+func (db *Database) Size() (uint64, error) {
+	lsmSize, vlogSize := db.db.Size()
+	return uint64(lsmSize + vlogSize), nil
+}
+
 // SyncKeyValue ensures that all pending
 // writes are flushed to disk.
 func (db *Database) SyncKeyValue() error {
@@ -118,6 +171,61 @@ func (db *Database) DeleteRange(start, end []byte) error {
 	return err
 }
 
+// CollectGarbage runs value log garbage collection
+// until no more value log file is discarded by at
+// least the specified ratio, and returns the bytes
+// by which the value log shrank.
+func (db *Database) CollectGarbage(discardRatio float64) (uint64, error) {
+	before, err := db.vlogSize()
+	if err != nil {
+		return 0, err
+	}
+
+	for {
+		err = db.db.RunValueLogGC(discardRatio)
+		if errors.Is(err, badger.ErrNoRewrite) {
+			break
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to collect value log garbage: %w", err)
+		}
+	}
+
+	after, err := db.vlogSize()
+	if err != nil {
+		return 0, err
+	}
+	if after >= before {
+		return 0, nil
+	}
+	return uint64(before - after), nil
+}
+
+// vlogSize returns the size of all value
+// log files. Unlike the size reported by
+// badger, which is updated periodically,
+// it is accurate right after a GC.
+func (db *Database) vlogSize() (int64, error) {
+	files, err := filepath.Glob(filepath.Join(db.db.Opts().ValueDir, "*.vlog"))
+	if err != nil {
+		return 0, fmt.Errorf("failed to list value log files: %w", err)
+	}
+
+	var size int64
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if errors.Is(err, os.ErrNotExist) {
+			// Removed by a concurrent GC
+			continue
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to stat value log file: %w", err)
+		}
+		size += info.Size()
+	}
+	return size, nil
+}
+
 // Compact flattens the database. In badger, value
 // log file garbage collection is performed.
 func (db *Database) Compact([]byte, []byte) error {
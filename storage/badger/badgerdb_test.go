@@ -335,3 +335,118 @@ func TestBadgerDb_DeleteRange(t *testing.T) {
 		}
 	})
 }
+
+func TestBadgerDb_Snapshot(t *testing.T) {
+	t.Run("should not see later writes", func(t *testing.T) {
+		db, err := New(t.TempDir())
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		defer db.Close()
+		if err := db.Put([]byte("key1"), []byte("val1")); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		snap, err := db.Snapshot()
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		defer snap.Release()
+
+		if err = db.Put([]byte("key1"), []byte("new1")); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if err = db.Put([]byte("key2"), []byte("val2")); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		val, err := snap.Get([]byte("key1"))
+		if err != nil || string(val) != "val1" {
+			t.Errorf("expected val1, got %s and %v", val, err)
+		}
+		if has, _ := snap.Has([]byte("key2")); has {
+			t.Errorf("expected key2 not to exist in snapshot")
+		}
+
+		it := snap.NewIterator([]byte("key"), nil)
+		defer it.Release()
+		var keys int
+		for it.Next() {
+			keys++
+		}
+		if keys != 1 {
+			t.Errorf("expected 1 key in snapshot, got %d", keys)
+		}
+
+		val, err = db.Get([]byte("key1"))
+		if err != nil || string(val) != "new1" {
+			t.Errorf("expected new1, got %s and %v", val, err)
+		}
+	})
+}
+
+func TestBadgerDb_CollectGarbage(t *testing.T) {
+	t.Run("should reclaim nothing without discarded values", func(t *testing.T) {
+		db, err := New(t.TempDir())
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		defer db.Close()
+
+		if err = db.Put([]byte("key"), []byte("val")); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		reclaimed, err := db.CollectGarbage(0.5)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if reclaimed != 0 {
+			t.Errorf("expected 0 bytes reclaimed, got %d", reclaimed)
+		}
+	})
+}
+
+func TestBadgerDb_NewWith(t *testing.T) {
+	t.Run("should reopen encrypted db with same key", func(t *testing.T) {
+		path := t.TempDir()
+		key := bytes.Repeat([]byte{0x01}, 32)
+		db, err := NewWith(path, Options{EncryptionKey: key})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if err = db.Put([]byte("key"), []byte("val")); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		db.Close()
+
+		db, err = NewWith(path, Options{EncryptionKey: key})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		defer db.Close()
+
+		val, err := db.Get([]byte("key"))
+		if err != nil || string(val) != "val" {
+			t.Errorf("expected val, got %s and %v", val, err)
+		}
+	})
+
+	t.Run("should fail to open encrypted db with other key", func(t *testing.T) {
+		path := t.TempDir()
+		db, err := NewWith(path, Options{EncryptionKey: bytes.Repeat([]byte{0x01}, 32)})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		db.Close()
+
+		if db, err = NewWith(path, Options{EncryptionKey: bytes.Repeat([]byte{0x02}, 32)}); err == nil {
+			db.Close()
+			t.Errorf("expected error, got nil")
+		}
+		if db, err = New(path); err == nil {
+			db.Close()
+			t.Errorf("expected error without key, got nil")
+		}
+	})
+}
@@ -1,6 +1,7 @@
 package badger
 
 import (
+	"bytes"
 	"fmt"
 	"github.com/dgraph-io/badger/v4"
 	"github.com/ethereum/go-ethereum/ethdb"
@@ -9,9 +10,18 @@ import (
 // iterator is a binary-alphabetical
 // iterator over key-value pairs.
 type iterator struct {
+	// tx is discarded on release,
+	// unless owned by a snapshot
 	tx     *badger.Txn
 	it     *badger.Iterator
-	start  []byte
+	prefix []byte
+	// start is the key sought first,
+	// nil rewinds the iterator.
+	start []byte
+	// skip is skipped if sought first, i.e.,
+	// the upper bound of the prefix, which
+	// reverse iterators seek without start.
+	skip   []byte
 	seeked bool
 	err    error
 }
@@ -21,35 +31,85 @@ type iterator struct {
 // content with the specified key prefix,
 // starting at the specified initial key.
 func (db *Database) NewIterator(prefix, start []byte) ethdb.Iterator {
-	opts := badger.DefaultIteratorOptions
-	opts.Prefix = prefix
+	tx := db.db.NewTransaction(false)
+	it := newIterator(tx, prefix, start)
+	it.tx = tx
+	return it
+}
 
+// NewReverseIterator creates a binary-alphabetical
+// iterator in descending order over a subset of the
+// datastore's content with the specified key prefix,
+// starting at the specified initial key, or at the
+// last key with the prefix if nil.
+func (db *Database) NewReverseIterator(prefix, start []byte) ethdb.Iterator {
 	tx := db.db.NewTransaction(false)
-	it := tx.NewIterator(opts)
+	it := newReverseIterator(tx, prefix, start)
+	it.tx = tx
+	return it
+}
+
+// newIterator creates an iterator
+// within the specified transaction.
+func newIterator(tx *badger.Txn, prefix, start []byte) *iterator {
+	opts := badger.DefaultIteratorOptions
+	opts.Prefix = prefix
 
 	return &iterator{
-		tx:     tx,
-		it:     it,
+		it:     tx.NewIterator(opts),
+		prefix: prefix,
 		start:  append(prefix, start...),
 		seeked: false,
 	}
 }
 
+// newReverseIterator creates a reverse
+// iterator within the specified transaction.
+func newReverseIterator(tx *badger.Txn, prefix, start []byte) *iterator {
+	opts := badger.DefaultIteratorOptions
+	opts.Reverse = true
+
+	it := &iterator{
+		it:     tx.NewIterator(opts),
+		prefix: prefix,
+		seeked: false,
+	}
+	if start != nil {
+		it.start = append(append([]byte{}, prefix...), start...)
+	} else {
+		// Seeks the largest key not greater than
+		// the upper bound, which is the bound itself
+		// if present, but lacks the prefix
+		it.start = upperBound(prefix)
+		it.skip = it.start
+	}
+	return it
+}
+
 // Next moves the iterator to the
 // next key-value pair.
 func (it *iterator) Next() bool {
 	if !it.seeked {
 		it.seeked = true
 		it.it.Seek(it.start)
-		return it.it.Valid()
+		if it.skip != nil && it.it.Valid() && bytes.Equal(it.it.Item().Key(), it.skip) {
+			it.it.Next()
+		}
+		return it.valid()
 	}
 
-	if !it.it.Valid() {
+	if !it.valid() {
 		return false
 	}
 
 	it.it.Next()
-	return it.it.Valid()
+	return it.valid()
+}
+
+// valid checks if the iterator is at a
+// key-value pair with the prefix.
+func (it *iterator) valid() bool {
+	return it.it.Valid() && bytes.HasPrefix(it.it.Item().Key(), it.prefix)
 }
 
 // Error returns any accumulated error
@@ -62,7 +122,7 @@ func (it *iterator) Error() error {
 // key-value pair, or nil if the iterator
 // is already exhausted.
 func (it *iterator) Key() []byte {
-	if !it.it.Valid() {
+	if !it.valid() {
 		return nil
 	}
 	return it.it.Item().KeyCopy(nil)
@@ -72,7 +132,7 @@ func (it *iterator) Key() []byte {
 // key-value pair, or nil if the iterator
 // is already exhausted.
 func (it *iterator) Value() []byte {
-	if !it.it.Valid() {
+	if !it.valid() {
 		return nil
 	}
 	val, err := it.it.Item().ValueCopy(nil)
@@ -86,9 +146,27 @@ func (it *iterator) Value() []byte {
 // Release releases associated resources.
 func (it *iterator) Release() {
 	it.it.Close()
-	it.tx.Discard()
+	if it.tx != nil {
+		it.tx.Discard()
+	}
 
 	// Hint GC
 	it.it = nil
 	it.tx = nil
 }
+
+// upperBound returns the smallest key that is
+// larger than all keys with the specified prefix,
+// or nil if there is none.
+func upperBound(prefix []byte) []byte {
+	for i := len(prefix) - 1; i >= 0; i-- {
+		if prefix[i] == 0xff {
+			continue
+		}
+		limit := make([]byte, i+1)
+		copy(limit, prefix)
+		limit[i]++
+		return limit
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package badger
+
+import (
+	"errors"
+	"github.com/dgraph-io/badger/v4"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"sparseth/storage"
+)
+
+// snapshot is a point-in-time read view of
+// the datastore, backed by a read-only
+// transaction.
+type snapshot struct {
+	tx *badger.Txn
+}
+
+// Snapshot creates a snapshot of the current
+// state of the datastore, which is served by
+// a read-only transaction until released.
+func (db *Database) Snapshot() (storage.Snapshot, error) {
+	return &snapshot{tx: db.db.NewTransaction(false)}, nil
+}
+
+// Has checks if the specified key
+// exists in the snapshot.
+func (s *snapshot) Has(key []byte) (bool, error) {
+	_, err := s.tx.Get(key)
+	if errors.Is(err, badger.ErrKeyNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Get retrieves the value associated with
+// the specified key, if present.
+func (s *snapshot) Get(key []byte) ([]byte, error) {
+	item, err := s.tx.Get(key)
+	if errors.Is(err, badger.ErrKeyNotFound) {
+		return nil, storage.ErrKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return item.ValueCopy(nil)
+}
+
+// NewIterator creates a binary-alphabetical
+// iterator over a subset of the snapshot
+// with the specified key prefix, starting
+// at the specified initial key.
+func (s *snapshot) NewIterator(prefix, start []byte) ethdb.Iterator {
+	return newIterator(s.tx, prefix, start)
+}
+
+// NewReverseIterator creates a binary-alphabetical
+// iterator in descending order over a subset of
+// the snapshot, see Database.NewReverseIterator.
+func (s *snapshot) NewReverseIterator(prefix, start []byte) ethdb.Iterator {
+	return newReverseIterator(s.tx, prefix, start)
+}
+
+// Release discards the transaction.
+func (s *snapshot) Release() {
+	s.tx.Discard()
+}
@@ -0,0 +1,173 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sparseth/storage"
+	"sparseth/storage/badger"
+	"sparseth/storage/pebble"
+	"time"
+)
+
+var (
+	// ErrUnknownEngine is returned when an
+	// unsupported storage engine is requested.
+	ErrUnknownEngine = errors.New("unknown storage engine")
+
+	// ErrEngineMismatch is returned when a database
+	// created by another storage engine is opened.
+	ErrEngineMismatch = errors.New("database was created by another storage engine")
+
+	// ErrEncryptionUnsupported is returned when an
+	// encryption key is specified for an engine
+	// without encryption at rest.
+	ErrEncryptionUnsupported = errors.New("storage engine does not support encryption")
+)
+
+// Options configures the database.
+type Options struct {
+	// EncryptionKey is the AES key of 16, 24, or
+	// 32 bytes all data is encrypted with on disk,
+	// or empty if unencrypted. Only supported by
+	// badger.
+	EncryptionKey []byte
+	// KeyRotation is the interval at which new
+	// data keys are generated, see badger.Options.
+	KeyRotation time.Duration
+}
+
+// Engine is the storage engine
+// of the database.
+type Engine string
+
+const (
+	// Badger stores keys and values with
+	// badger, which is the default.
+	Badger Engine = "badger"
+	// Pebble stores keys and values with
+	// pebble, the engine of geth.
+	Pebble Engine = "pebble"
+)
+
+// markers are files that only the
+// respective engine creates.
+var markers = map[Engine]string{
+	Badger: "KEYREGISTRY",
+	Pebble: "CURRENT",
+}
+
+// ParseEngine parses the specified
+// storage engine.
+func ParseEngine(s string) (Engine, error) {
+	switch e := Engine(s); e {
+	case Badger, Pebble:
+		return e, nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnknownEngine, s)
+	}
+}
+
+// Open opens the database at the specified path
+// with the specified storage engine, creating it
+// if missing. Databases created by another engine
+// are refused.
+func Open(e Engine, path string) (storage.KeyValStore, error) {
+	return OpenWith(e, path, Options{})
+}
+
+// OpenWith opens the database at the specified
+// path with the specified storage engine and
+// options, see Open.
+func OpenWith(e Engine, path string, opts Options) (storage.KeyValStore, error) {
+	if err := checkEngine(e, path, opts); err != nil {
+		return nil, err
+	}
+
+	switch e {
+	case Badger:
+		return badger.NewWith(path, badgerOptions(opts, false))
+	case Pebble:
+		return pebble.New(path)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownEngine, e)
+	}
+}
+
+// OpenReadOnly opens the existing database at the
+// specified path with the specified storage engine
+// without taking the write lock, and rejects all
+// writes with storage.ErrReadOnly.
+//
+// If the database is held by a running node, a
+// snapshot of it is copied next to the database
+// and opened instead, which is removed on close.
+func OpenReadOnly(e Engine, path string) (storage.KeyValStore, error) {
+	return OpenReadOnlyWith(e, path, Options{})
+}
+
+// OpenReadOnlyWith opens the existing database at
+// the specified path with the specified storage
+// engine and options, see OpenReadOnly.
+func OpenReadOnlyWith(e Engine, path string, opts Options) (storage.KeyValStore, error) {
+	if err := checkEngine(e, path, opts); err != nil {
+		return nil, err
+	}
+
+	db, err := openReadOnly(e, path, opts)
+	if err == nil {
+		return storage.NewReadOnly(db), nil
+	}
+	if errors.Is(err, ErrUnknownEngine) {
+		return nil, err
+	}
+
+	snap, snapErr := openSnapshot(e, path, opts)
+	if snapErr != nil {
+		return nil, fmt.Errorf("failed to open database read-only: %w, nor a snapshot: %w", err, snapErr)
+	}
+	return snap, nil
+}
+
+// openReadOnly opens the database at the specified
+// path in the read-only mode of the specified engine.
+func openReadOnly(e Engine, path string, opts Options) (storage.KeyValStore, error) {
+	switch e {
+	case Badger:
+		return badger.NewWith(path, badgerOptions(opts, true))
+	case Pebble:
+		return pebble.NewReadOnly(path)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownEngine, e)
+	}
+}
+
+// badgerOptions returns the badger
+// options of the specified options.
+func badgerOptions(opts Options, readOnly bool) badger.Options {
+	return badger.Options{
+		ReadOnly:      readOnly,
+		EncryptionKey: opts.EncryptionKey,
+		KeyRotation:   opts.KeyRotation,
+	}
+}
+
+// checkEngine checks that the database at the
+// specified path was not created by another
+// engine than the specified one, and that the
+// engine supports the specified options.
+func checkEngine(e Engine, path string, opts Options) error {
+	if len(opts.EncryptionKey) > 0 && e != Badger {
+		return fmt.Errorf("%w: %s", ErrEncryptionUnsupported, e)
+	}
+	for other, marker := range markers {
+		if other == e {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(path, marker)); err == nil {
+			return fmt.Errorf("%w: expected %s, got %s", ErrEngineMismatch, e, other)
+		}
+	}
+	return nil
+}
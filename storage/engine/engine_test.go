@@ -0,0 +1,133 @@
+package engine
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sparseth/storage"
+	"testing"
+)
+
+func TestOpen(t *testing.T) {
+	t.Run("should reopen database with same engine", func(t *testing.T) {
+		for _, e := range []Engine{Badger, Pebble} {
+			path := t.TempDir()
+			db, err := Open(e, path)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if err = db.Put([]byte("key"), []byte("val")); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			db.Close()
+
+			db, err = Open(e, path)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			val, err := db.Get([]byte("key"))
+			if err != nil || string(val) != "val" {
+				t.Errorf("expected val of %s, got %s and %v", e, val, err)
+			}
+			db.Close()
+		}
+	})
+
+	t.Run("should refuse database of other engine", func(t *testing.T) {
+		path := t.TempDir()
+		db, err := Open(Badger, path)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		db.Close()
+
+		if _, err = Open(Pebble, path); !errors.Is(err, ErrEngineMismatch) {
+			t.Errorf("expected engine mismatch, got %v", err)
+		}
+	})
+
+	t.Run("should refuse encryption of pebble", func(t *testing.T) {
+		opts := Options{EncryptionKey: make([]byte, 32)}
+		if _, err := OpenWith(Pebble, t.TempDir(), opts); !errors.Is(err, ErrEncryptionUnsupported) {
+			t.Errorf("expected encryption unsupported, got %v", err)
+		}
+	})
+}
+
+func TestOpenReadOnly(t *testing.T) {
+	t.Run("should read closed database", func(t *testing.T) {
+		for _, e := range []Engine{Badger, Pebble} {
+			path := t.TempDir()
+			db, err := Open(e, path)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if err = db.Put([]byte("key"), []byte("val")); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			db.Close()
+
+			db, err = OpenReadOnly(e, path)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			val, err := db.Get([]byte("key"))
+			if err != nil || string(val) != "val" {
+				t.Errorf("expected val of %s, got %s and %v", e, val, err)
+			}
+			if err = db.Put([]byte("key"), []byte("new")); !errors.Is(err, storage.ErrReadOnly) {
+				t.Errorf("expected read-only error of %s, got %v", e, err)
+			}
+			db.Close()
+		}
+	})
+
+	t.Run("should read snapshot of open database", func(t *testing.T) {
+		for _, e := range []Engine{Badger, Pebble} {
+			parent := t.TempDir()
+			path := filepath.Join(parent, "db")
+			db, err := Open(e, path)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			defer db.Close()
+			if err = db.Put([]byte("key"), []byte("val")); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if err = db.SyncKeyValue(); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			ro, err := OpenReadOnly(e, path)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			val, err := ro.Get([]byte("key"))
+			if err != nil || string(val) != "val" {
+				t.Errorf("expected val of %s, got %s and %v", e, val, err)
+			}
+			if err = ro.Close(); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			entries, err := os.ReadDir(parent)
+			if err != nil || len(entries) != 1 {
+				t.Errorf("expected snapshot of %s to be removed, got %v and %v", e, entries, err)
+			}
+		}
+	})
+
+	t.Run("should fail on unknown engine", func(t *testing.T) {
+		if _, err := OpenReadOnly("leveldb", t.TempDir()); !errors.Is(err, ErrUnknownEngine) {
+			t.Errorf("expected unknown engine, got %v", err)
+		}
+	})
+}
+
+func TestParseEngine(t *testing.T) {
+	t.Run("should fail on unknown engine", func(t *testing.T) {
+		if _, err := ParseEngine("leveldb"); !errors.Is(err, ErrUnknownEngine) {
+			t.Errorf("expected unknown engine, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,163 @@
+package engine
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sparseth/storage"
+	"strings"
+)
+
+const (
+	// snapshotAttempts is the number of attempts to
+	// copy a database that is compacted meanwhile.
+	snapshotAttempts = 3
+
+	// snapshotChunk is the size of the chunks in
+	// which files are copied. Chunks of zeros are
+	// skipped, so that sparse files stay sparse.
+	snapshotChunk = 1 << 20
+)
+
+// snapshot is a read-only copy of a database,
+// whose directory is removed on close.
+type snapshot struct {
+	*storage.ReadOnly
+	dir string
+}
+
+// Close closes the copy
+// and removes its directory.
+func (s *snapshot) Close() error {
+	err := s.ReadOnly.Close()
+	if rmErr := os.RemoveAll(s.dir); rmErr != nil && err == nil {
+		err = fmt.Errorf("failed to remove snapshot: %w", rmErr)
+	}
+	return err
+}
+
+// openSnapshot copies the database at the specified
+// path, which may be held by a running node, and
+// opens the copy with the specified engine.
+//
+// The copy is created next to the database, so that
+// the immutable table files are hard-linked instead
+// of copied. Since the copy is opened for writing,
+// the engine recovers its write-ahead log, including
+// a partially copied last entry.
+func openSnapshot(e Engine, path string, opts Options) (storage.KeyValStore, error) {
+	var err error
+	for range snapshotAttempts {
+		var dir string
+		dir, err = os.MkdirTemp(filepath.Dir(filepath.Clean(path)), ".snapshot-")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create snapshot directory: %w", err)
+		}
+
+		err = copyDatabase(path, dir)
+		if err == nil {
+			var db storage.KeyValStore
+			db, err = OpenWith(e, dir, opts)
+			if err == nil {
+				return &snapshot{ReadOnly: storage.NewReadOnly(db), dir: dir}, nil
+			}
+		}
+		os.RemoveAll(dir)
+
+		// Files are removed by compactions,
+		// which is resolved by another copy
+		if !errors.Is(err, fs.ErrNotExist) {
+			break
+		}
+	}
+	return nil, err
+}
+
+// copyDatabase copies the files of the database
+// at the specified path into the specified dir.
+//
+// Manifests are copied first, so that the copied
+// manifest does not refer to table files that
+// are created after copying the tables.
+func copyDatabase(path, dir string) error {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return fmt.Errorf("failed to read database directory: %w", err)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return isManifest(entries[i].Name()) && !isManifest(entries[j].Name())
+	})
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.Type().IsRegular() || name == "LOCK" {
+			continue
+		}
+		src, dst := filepath.Join(path, name), filepath.Join(dir, name)
+		if strings.HasSuffix(name, ".sst") {
+			if err = os.Link(src, dst); err == nil {
+				continue
+			}
+		}
+		if err = copyFile(src, dst); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isManifest checks if the specified file
+// names a manifest of badger or pebble.
+func isManifest(name string) bool {
+	return strings.HasPrefix(name, "MANIFEST") || name == "CURRENT" || strings.HasPrefix(name, "marker.")
+}
+
+// copyFile copies the specified file,
+// skipping chunks of zeros.
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", src, err)
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", dst, err)
+	}
+	defer out.Close()
+
+	buf := make([]byte, snapshotChunk)
+	zeros := make([]byte, snapshotChunk)
+	var size int64
+	for {
+		n, err := io.ReadFull(in, buf)
+		if n > 0 {
+			if bytes.Equal(buf[:n], zeros[:n]) {
+				_, err = out.Seek(int64(n), io.SeekCurrent)
+			} else {
+				_, err = out.Write(buf[:n])
+			}
+			if err != nil {
+				return fmt.Errorf("failed to copy %s: %w", src, err)
+			}
+			size += int64(n)
+			continue
+		}
+		if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+			return fmt.Errorf("failed to copy %s: %w", src, err)
+		}
+		break
+	}
+
+	// Trailing zeros are only skipped
+	if err = out.Truncate(size); err != nil {
+		return fmt.Errorf("failed to copy %s: %w", src, err)
+	}
+	return out.Close()
+}
@@ -24,14 +24,69 @@ type KeyValSyncer interface {
 	SyncKeyValue() error
 }
 
+// KeyValSizer defines the size
+// of the key val store.
+type KeyValSizer interface {
+	// Size returns the approximate number
+	// of bytes used by the store.
+	Size() (uint64, error)
+}
+
+// KeyValReverseIteratee defines iteration
+// of the key val store in descending order.
+type KeyValReverseIteratee interface {
+	// NewReverseIterator creates a binary-alphabetical
+	// iterator in descending order over a subset of
+	// the content with the specified key prefix. It
+	// starts at the largest key not greater than the
+	// prefix followed by the specified start key, or
+	// at the last key with the prefix if start is nil.
+	NewReverseIterator(prefix, start []byte) ethdb.Iterator
+}
+
+// Snapshot is a consistent point-in-time read
+// view of a key val store, which is unaffected
+// by later writes to the store.
+type Snapshot interface {
+	ethdb.KeyValueReader
+	ethdb.Iteratee
+	KeyValReverseIteratee
+
+	// Release releases the snapshot. All of its
+	// iterators must be released beforehand.
+	Release()
+}
+
+// KeyValSnapshotter defines snapshots
+// of the key val store.
+type KeyValSnapshotter interface {
+	// Snapshot creates a snapshot of
+	// the current state of the store.
+	Snapshot() (Snapshot, error)
+}
+
+// KeyValCollector defines garbage collection of
+// key val stores that do not reclaim the space
+// of deleted or overwritten values on their own,
+// e.g., the value log of badger.
+type KeyValCollector interface {
+	// CollectGarbage rewrites all files of which at
+	// least the specified ratio is discarded, and
+	// returns the number of bytes reclaimed.
+	CollectGarbage(discardRatio float64) (uint64, error)
+}
+
 type KeyValStore interface {
 	ethdb.KeyValueReader
 	ethdb.KeyValueWriter
 	ethdb.KeyValueStater
 	KeyValSyncer
+	KeyValSizer
+	KeyValSnapshotter
 	ethdb.KeyValueRangeDeleter
 	ethdb.Batcher
 	ethdb.Iteratee
+	KeyValReverseIteratee
 	ethdb.Compacter
 	io.Closer
 }
@@ -79,6 +79,7 @@ func (b *batch) Write() error {
 		return storage.ErrDbClosed
 	}
 
+	b.db.detach()
 	for _, item := range b.pairs {
 		if item.del {
 			delete(b.db.db, item.key)
@@ -99,6 +100,7 @@ func (b *batch) Reset() {
 // Replay replays the batch contents to
 // the specified writer.
 func (b *batch) Replay(w ethdb.KeyValueWriter) error {
+	b.db.detach()
 	for _, item := range b.pairs {
 		if item.del {
 			if err := w.Delete([]byte(item.key)); err != nil {
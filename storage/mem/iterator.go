@@ -22,11 +22,57 @@ func (db *Database) NewIterator(prefix, start []byte) ethdb.Iterator {
 	db.lock.RLock()
 	defer db.lock.RUnlock()
 
+	return newIterator(db.db, prefix, start)
+}
+
+// NewReverseIterator creates a binary-alphabetical
+// iterator in descending order over a subset of the
+// database content with the specified key prefix,
+// starting at the specified initial key, or at the
+// last key with the prefix if nil.
+func (db *Database) NewReverseIterator(prefix, start []byte) ethdb.Iterator {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	return newReverseIterator(db.db, prefix, start)
+}
+
+// newReverseIterator creates a binary-alphabetical
+// iterator in descending order over the specified
+// key-value pairs, which are copied.
+func newReverseIterator(db map[string][]byte, prefix, start []byte) *iterator {
+	pr := string(prefix)
+	st := string(prefix) + string(start)
+
+	pairs := make([]*pair, 0, len(db))
+	for k, v := range db {
+		if strings.HasPrefix(k, pr) && (start == nil || k <= st) {
+			pairs = append(pairs, &pair{
+				key: k,
+				val: storage.CopyBytes(v),
+			})
+		}
+	}
+
+	sort.Slice(pairs, func(i, j int) bool {
+		return pairs[i].key > pairs[j].key
+	})
+
+	return &iterator{
+		idx:   -1,
+		pairs: pairs,
+	}
+}
+
+// newIterator creates a binary-alphabetical
+// iterator over the specified key-value pairs,
+// which are copied.
+func newIterator(db map[string][]byte, prefix, start []byte) *iterator {
 	pr := string(prefix)
 	st := string(append(prefix, start...))
 
-	pairs := make([]*pair, 0, len(db.db))
-	for k, v := range db.db {
+	pairs := make([]*pair, 0, len(db))
+	for k, v := range db {
 		if strings.HasPrefix(k, pr) && k >= st {
 			pairs = append(pairs, &pair{
 				key: k,
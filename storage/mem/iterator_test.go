@@ -149,3 +149,60 @@ func TestMemDb_Iterator(t *testing.T) {
 		}
 	})
 }
+
+func TestMemDb_ReverseIterator(t *testing.T) {
+	newTestDb := func(t *testing.T) *Database {
+		db := New()
+		t.Cleanup(func() { db.Close() })
+		// The upper bound of the prefix is not iterated
+		for _, key := range []string{"a", "key-1", "key-2", "key-3", "key."} {
+			if err := db.Put([]byte(key), []byte("val-"+key)); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+		}
+		return db
+	}
+	collect := func(it interface {
+		Next() bool
+		Key() []byte
+		Release()
+	}) []string {
+		defer it.Release()
+		var keys []string
+		for it.Next() {
+			keys = append(keys, string(it.Key()))
+		}
+		return keys
+	}
+
+	t.Run("should iterate prefix in descending order", func(t *testing.T) {
+		db := newTestDb(t)
+
+		keys := collect(db.NewReverseIterator([]byte("key-"), nil))
+		if fmt.Sprint(keys) != "[key-3 key-2 key-1]" {
+			t.Errorf("expected [key-3 key-2 key-1], got %v", keys)
+		}
+	})
+
+	t.Run("should start at largest key not greater than start", func(t *testing.T) {
+		db := newTestDb(t)
+
+		keys := collect(db.NewReverseIterator([]byte("key-"), []byte("2")))
+		if fmt.Sprint(keys) != "[key-2 key-1]" {
+			t.Errorf("expected [key-2 key-1], got %v", keys)
+		}
+		keys = collect(db.NewReverseIterator([]byte("key-"), []byte("25")))
+		if fmt.Sprint(keys) != "[key-2 key-1]" {
+			t.Errorf("expected [key-2 key-1], got %v", keys)
+		}
+	})
+
+	t.Run("should iterate all keys without prefix", func(t *testing.T) {
+		db := newTestDb(t)
+
+		keys := collect(db.NewReverseIterator(nil, nil))
+		if len(keys) != 5 || keys[0] != "key." || keys[4] != "a" {
+			t.Errorf("expected all keys from key. to a, got %v", keys)
+		}
+	})
+}
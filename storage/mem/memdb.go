@@ -2,6 +2,7 @@ package mem
 
 import (
 	"fmt"
+	"maps"
 	"sparseth/storage"
 	"sync"
 )
@@ -10,6 +11,10 @@ import (
 type Database struct {
 	db   map[string][]byte
 	lock sync.RWMutex
+	// shared is set while the map is shared
+	// with a snapshot, so that it is copied
+	// on the next write.
+	shared bool
 }
 
 // New creates a new in-memory database.
@@ -70,6 +75,7 @@ func (db *Database) Put(key, value []byte) error {
 		return storage.ErrDbClosed
 	}
 
+	db.detach()
 	db.db[string(key)] = storage.CopyBytes(value)
 	return nil
 }
@@ -83,6 +89,7 @@ func (db *Database) Delete(key []byte) error {
 		return storage.ErrDbClosed
 	}
 
+	db.detach()
 	delete(db.db, string(key))
 	return nil
 }
@@ -99,6 +106,23 @@ func (db *Database) Stat() (string, error) {
 	return fmt.Sprintf("Memory DB: %d keys stored", len(db.db)), nil
 }
 
+// Size returns the total size of all
+// stored keys and values in bytes.
+func (db *Database) Size() (uint64, error) {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	if db.db == nil {
+		return 0, storage.ErrDbClosed
+	}
+
+	var size uint64
+	for key, val := range db.db {
+		size += uint64(len(key) + len(val))
+	}
+	return size, nil
+}
+
 // SyncKeyValue SynKeyValue ensures that all
 // pending writes are flushed to disk. In a
 // memory database, this is a no-op.
@@ -123,6 +147,7 @@ func (db *Database) DeleteRange(start, end []byte) error {
 		return storage.ErrDbClosed
 	}
 
+	db.detach()
 	for key := range db.db {
 		if key >= string(start) && key < string(end) {
 			delete(db.db, key)
@@ -137,3 +162,30 @@ func (db *Database) DeleteRange(start, end []byte) error {
 func (db *Database) Compact([]byte, []byte) error {
 	return nil
 }
+
+// Snapshot creates a snapshot of the current
+// state of the database. The database is only
+// copied on the next write, i.e., taking many
+// snapshots between writes is cheap.
+func (db *Database) Snapshot() (storage.Snapshot, error) {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	if db.db == nil {
+		return nil, storage.ErrDbClosed
+	}
+
+	db.shared = true
+	return &snapshot{db: db.db}, nil
+}
+
+// detach copies the map of the database if it
+// is shared with a snapshot. The lock must be
+// held for writing.
+func (db *Database) detach() {
+	if !db.shared {
+		return
+	}
+	db.db = maps.Clone(db.db)
+	db.shared = false
+}
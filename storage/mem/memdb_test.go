@@ -273,3 +273,67 @@ func TestMemDb_DeleteRange(t *testing.T) {
 		}
 	})
 }
+
+func TestMemDb_Size(t *testing.T) {
+	t.Run("should sum size of keys and values", func(t *testing.T) {
+		db := New()
+
+		if err := db.Put([]byte("key"), []byte("value")); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		size, err := db.Size()
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if size != 8 {
+			t.Errorf("expected size 8, got %d", size)
+		}
+	})
+}
+
+func TestMemDb_Snapshot(t *testing.T) {
+	t.Run("should not see later writes", func(t *testing.T) {
+		db := New()
+		defer db.Close()
+		if err := db.Put([]byte("key1"), []byte("val1")); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		snap, err := db.Snapshot()
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		defer snap.Release()
+
+		if err = db.Put([]byte("key1"), []byte("new1")); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if err = db.Put([]byte("key2"), []byte("val2")); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		val, err := snap.Get([]byte("key1"))
+		if err != nil || string(val) != "val1" {
+			t.Errorf("expected val1, got %s and %v", val, err)
+		}
+		if has, _ := snap.Has([]byte("key2")); has {
+			t.Errorf("expected key2 not to exist in snapshot")
+		}
+
+		it := snap.NewIterator([]byte("key"), nil)
+		defer it.Release()
+		var keys int
+		for it.Next() {
+			keys++
+		}
+		if keys != 1 {
+			t.Errorf("expected 1 key in snapshot, got %d", keys)
+		}
+
+		val, err = db.Get([]byte("key1"))
+		if err != nil || string(val) != "new1" {
+			t.Errorf("expected new1, got %s and %v", val, err)
+		}
+	})
+}
@@ -0,0 +1,57 @@
+package mem
+
+import (
+	"github.com/ethereum/go-ethereum/ethdb"
+	"sparseth/storage"
+)
+
+// snapshot is a point-in-time read view of
+// a memory database. Its map is never written,
+// as the database copies it on write.
+type snapshot struct {
+	db map[string][]byte
+}
+
+// Has checks if the specified key
+// exists in the snapshot.
+func (s *snapshot) Has(key []byte) (bool, error) {
+	if s.db == nil {
+		return false, storage.ErrDbClosed
+	}
+
+	_, ok := s.db[string(key)]
+	return ok, nil
+}
+
+// Get retrieves the value associated with
+// the specified key, if present.
+func (s *snapshot) Get(key []byte) ([]byte, error) {
+	if s.db == nil {
+		return nil, storage.ErrDbClosed
+	}
+
+	if val, ok := s.db[string(key)]; ok {
+		return storage.CopyBytes(val), nil
+	}
+	return nil, storage.ErrKeyNotFound
+}
+
+// NewIterator creates a binary-alphabetical
+// iterator over a subset of the snapshot
+// with the specified key prefix, starting
+// at the specified initial key.
+func (s *snapshot) NewIterator(prefix, start []byte) ethdb.Iterator {
+	return newIterator(s.db, prefix, start)
+}
+
+// NewReverseIterator creates a binary-alphabetical
+// iterator in descending order over a subset of
+// the snapshot, see Database.NewReverseIterator.
+func (s *snapshot) NewReverseIterator(prefix, start []byte) ethdb.Iterator {
+	return newReverseIterator(s.db, prefix, start)
+}
+
+// Release releases the snapshot.
+func (s *snapshot) Release() {
+	s.db = nil
+}
@@ -0,0 +1,165 @@
+package pebble
+
+import (
+	"github.com/cockroachdb/pebble"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"sparseth/storage"
+)
+
+// iterator is a binary-alphabetical
+// iterator over key-value pairs.
+type iterator struct {
+	it      *pebble.Iterator
+	reverse bool
+	seeked  bool
+	err     error
+}
+
+// NewIterator creates a binary-alphabetical
+// iterator over a subset of the datastore's
+// content with the specified key prefix,
+// starting at the specified initial key.
+func (db *Database) NewIterator(prefix, start []byte) ethdb.Iterator {
+	if db.closed.Load() {
+		return &iterator{err: storage.ErrDbClosed}
+	}
+	return newIterator(db.db, prefix, start)
+}
+
+// NewReverseIterator creates a binary-alphabetical
+// iterator in descending order over a subset of the
+// datastore's content with the specified key prefix,
+// starting at the specified initial key, or at the
+// last key with the prefix if nil.
+func (db *Database) NewReverseIterator(prefix, start []byte) ethdb.Iterator {
+	if db.closed.Load() {
+		return &iterator{err: storage.ErrDbClosed}
+	}
+	return newReverseIterator(db.db, prefix, start)
+}
+
+// newIterator creates a binary-alphabetical
+// iterator over the specified reader, i.e.,
+// the datastore or a snapshot of it.
+func newIterator(r pebble.Reader, prefix, start []byte) ethdb.Iterator {
+	lower := make([]byte, 0, len(prefix)+len(start))
+	lower = append(append(lower, prefix...), start...)
+	it, err := r.NewIter(&pebble.IterOptions{
+		LowerBound: lower,
+		UpperBound: upperBound(prefix),
+	})
+	if err != nil {
+		return &iterator{err: err}
+	}
+
+	return &iterator{
+		it:     it,
+		seeked: false,
+	}
+}
+
+// newReverseIterator creates a binary-alphabetical
+// iterator in descending order over the specified
+// reader, i.e., the datastore or a snapshot of it.
+func newReverseIterator(r pebble.Reader, prefix, start []byte) ethdb.Iterator {
+	upper := upperBound(prefix)
+	if start != nil {
+		// The start key itself is included
+		upper = make([]byte, 0, len(prefix)+len(start)+1)
+		upper = append(append(append(upper, prefix...), start...), 0x00)
+	}
+	it, err := r.NewIter(&pebble.IterOptions{
+		LowerBound: prefix,
+		UpperBound: upper,
+	})
+	if err != nil {
+		return &iterator{err: err}
+	}
+
+	return &iterator{
+		it:      it,
+		reverse: true,
+		seeked:  false,
+	}
+}
+
+// Next moves the iterator to the
+// next key-value pair.
+func (it *iterator) Next() bool {
+	if it.it == nil {
+		return false
+	}
+	if !it.seeked {
+		it.seeked = true
+		if it.reverse {
+			return it.it.Last()
+		}
+		return it.it.First()
+	}
+
+	if !it.it.Valid() {
+		return false
+	}
+	if it.reverse {
+		return it.it.Prev()
+	}
+	return it.it.Next()
+}
+
+// Error returns any accumulated error
+// during iteration.
+func (it *iterator) Error() error {
+	if it.err != nil || it.it == nil {
+		return it.err
+	}
+	return it.it.Error()
+}
+
+// Key returns the key of the current
+// key-value pair, or nil if the iterator
+// is already exhausted.
+func (it *iterator) Key() []byte {
+	if it.it == nil || !it.it.Valid() {
+		return nil
+	}
+	return storage.CopyBytes(it.it.Key())
+}
+
+// Value returns the value of the current
+// key-value pair, or nil if the iterator
+// is already exhausted.
+func (it *iterator) Value() []byte {
+	if it.it == nil || !it.it.Valid() {
+		return nil
+	}
+	return storage.CopyBytes(it.it.Value())
+}
+
+// Release releases associated resources.
+func (it *iterator) Release() {
+	if it.it == nil {
+		return
+	}
+	if err := it.it.Close(); err != nil && it.err == nil {
+		it.err = err
+	}
+
+	// Hint GC
+	it.it = nil
+}
+
+// upperBound returns the smallest key that is
+// larger than all keys with the specified prefix,
+// or nil if there is none.
+func upperBound(prefix []byte) []byte {
+	for i := len(prefix) - 1; i >= 0; i-- {
+		if prefix[i] == 0xff {
+			continue
+		}
+		limit := make([]byte, i+1)
+		copy(limit, prefix)
+		limit[i]++
+		return limit
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package pebble
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/cockroachdb/pebble"
+	"sparseth/storage"
+	"sync/atomic"
+)
+
+// Database is a pebble key-val store.
+type Database struct {
+	db     *pebble.DB
+	closed atomic.Bool
+}
+
+// New creates a new pebble datastore
+// instance at the specified path.
+func New(path string) (*Database, error) {
+	db, err := pebble.Open(path, &pebble.Options{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open db: %w", err)
+	}
+
+	return &Database{db: db}, nil
+}
+
+// NewReadOnly opens the existing pebble datastore
+// at the specified path in read-only mode. Note
+// that it cannot be opened while opened by any
+// other process.
+func NewReadOnly(path string) (*Database, error) {
+	db, err := pebble.Open(path, &pebble.Options{ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open db: %w", err)
+	}
+
+	return &Database{db: db}, nil
+}
+
+// Close closes the underlying datastore.
+func (db *Database) Close() error {
+	if db.closed.Swap(true) {
+		return storage.ErrDbClosed
+	}
+	return db.db.Close()
+}
+
+// Has checks if the specified key exists
+// in the datastore.
+func (db *Database) Has(key []byte) (bool, error) {
+	if db.closed.Load() {
+		return false, storage.ErrDbClosed
+	}
+	_, closer, err := db.db.Get(key)
+	if errors.Is(err, pebble.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, closer.Close()
+}
+
+// Get retrieves the value associated with the
+// specified key, if present.
+func (db *Database) Get(key []byte) ([]byte, error) {
+	if db.closed.Load() {
+		return nil, storage.ErrDbClosed
+	}
+	val, closer, err := db.db.Get(key)
+	if errors.Is(err, pebble.ErrNotFound) {
+		return nil, storage.ErrKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer closer.Close()
+
+	return storage.CopyBytes(val), nil
+}
+
+// Put inserts the specified key-value pair
+// into the datastore.
+func (db *Database) Put(key, val []byte) error {
+	if db.closed.Load() {
+		return storage.ErrDbClosed
+	}
+	return db.db.Set(key, val, pebble.NoSync)
+}
+
+// Delete removes the specified key from
+// the datastore.
+func (db *Database) Delete(key []byte) error {
+	if db.closed.Load() {
+		return storage.ErrDbClosed
+	}
+	return db.db.Delete(key, pebble.NoSync)
+}
+
+// Stat returns statistic data of
+// the datastore.
+func (db *Database) Stat() (string, error) {
+	if db.closed.Load() {
+		return "", storage.ErrDbClosed
+	}
+	return fmt.Sprintf("Pebble DB disk space usage: %d bytes", db.db.Metrics().DiskSpaceUsage()), nil
+}
+
+// Size returns the disk space
+// used by the datastore in bytes.
+func (db *Database) Size() (uint64, error) {
+	if db.closed.Load() {
+		return 0, storage.ErrDbClosed
+	}
+	return db.db.Metrics().DiskSpaceUsage(), nil
+}
+
+// SyncKeyValue ensures that all pending
+// writes are flushed to disk.
+func (db *Database) SyncKeyValue() error {
+	if db.closed.Load() {
+		return storage.ErrDbClosed
+	}
+	// Syncing an empty record
+	// syncs all prior writes
+	return db.db.LogData(nil, pebble.Sync)
+}
+
+// DeleteRange deletes all keys (and values)
+// in the range [start, end).
+func (db *Database) DeleteRange(start, end []byte) error {
+	if db.closed.Load() {
+		return storage.ErrDbClosed
+	}
+	return db.db.DeleteRange(start, end, pebble.NoSync)
+}
+
+// Compact flattens the key range [start, limit)
+// of the database, where a nil limit is the end
+// of the key space.
+func (db *Database) Compact(start []byte, limit []byte) error {
+	if db.closed.Load() {
+		return storage.ErrDbClosed
+	}
+	if limit == nil {
+		// Pebble has no open upper bound, but no
+		// key of the schema is larger than this
+		limit = bytes.Repeat([]byte{0xff}, 32)
+	}
+	if err := db.db.Compact(start, limit, true); err != nil {
+		return fmt.Errorf("failed to compact db: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package pebble
+
+import (
+	"errors"
+	"github.com/cockroachdb/pebble"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"sparseth/storage"
+)
+
+// snapshot is a point-in-time
+// read view of the datastore.
+type snapshot struct {
+	snap *pebble.Snapshot
+}
+
+// Snapshot creates a snapshot of the
+// current state of the datastore.
+func (db *Database) Snapshot() (storage.Snapshot, error) {
+	if db.closed.Load() {
+		return nil, storage.ErrDbClosed
+	}
+	return &snapshot{snap: db.db.NewSnapshot()}, nil
+}
+
+// Has checks if the specified key
+// exists in the snapshot.
+func (s *snapshot) Has(key []byte) (bool, error) {
+	_, closer, err := s.snap.Get(key)
+	if errors.Is(err, pebble.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, closer.Close()
+}
+
+// Get retrieves the value associated with
+// the specified key, if present.
+func (s *snapshot) Get(key []byte) ([]byte, error) {
+	val, closer, err := s.snap.Get(key)
+	if errors.Is(err, pebble.ErrNotFound) {
+		return nil, storage.ErrKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer closer.Close()
+
+	return storage.CopyBytes(val), nil
+}
+
+// NewIterator creates a binary-alphabetical
+// iterator over a subset of the snapshot
+// with the specified key prefix, starting
+// at the specified initial key.
+func (s *snapshot) NewIterator(prefix, start []byte) ethdb.Iterator {
+	return newIterator(s.snap, prefix, start)
+}
+
+// NewReverseIterator creates a binary-alphabetical
+// iterator in descending order over a subset of
+// the snapshot, see Database.NewReverseIterator.
+func (s *snapshot) NewReverseIterator(prefix, start []byte) ethdb.Iterator {
+	return newReverseIterator(s.snap, prefix, start)
+}
+
+// Release releases the snapshot.
+func (s *snapshot) Release() {
+	s.snap.Close()
+}
@@ -0,0 +1,146 @@
+package storage
+
+import (
+	"errors"
+	"github.com/ethereum/go-ethereum/ethdb"
+)
+
+var (
+	// ErrReadOnly is returned on writes
+	// to a read-only store.
+	ErrReadOnly = errors.New("storage is read-only")
+)
+
+// ReadOnly is a KeyValStore that rejects all
+// writes with ErrReadOnly, and passes reads on
+// to an underlying store, e.g., for inspection
+// tooling that must not modify the database.
+type ReadOnly struct {
+	db KeyValStore
+}
+
+// NewReadOnly creates a new ReadOnly
+// view of the specified store.
+func NewReadOnly(db KeyValStore) *ReadOnly {
+	return &ReadOnly{db: db}
+}
+
+// Has checks if the specified key
+// exists in the store.
+func (r *ReadOnly) Has(key []byte) (bool, error) {
+	return r.db.Has(key)
+}
+
+// Get retrieves the value associated with
+// the specified key, if present.
+func (r *ReadOnly) Get(key []byte) ([]byte, error) {
+	return r.db.Get(key)
+}
+
+// Put fails with ErrReadOnly.
+func (r *ReadOnly) Put([]byte, []byte) error {
+	return ErrReadOnly
+}
+
+// Delete fails with ErrReadOnly.
+func (r *ReadOnly) Delete([]byte) error {
+	return ErrReadOnly
+}
+
+// Stat returns statistic data
+// of the underlying store.
+func (r *ReadOnly) Stat() (string, error) {
+	return r.db.Stat()
+}
+
+// Size returns the size of
+// the underlying store.
+func (r *ReadOnly) Size() (uint64, error) {
+	return r.db.Size()
+}
+
+// Snapshot creates a snapshot
+// of the underlying store.
+func (r *ReadOnly) Snapshot() (Snapshot, error) {
+	return r.db.Snapshot()
+}
+
+// SyncKeyValue does nothing, as
+// there are no pending writes.
+func (r *ReadOnly) SyncKeyValue() error {
+	return nil
+}
+
+// DeleteRange fails with ErrReadOnly.
+func (r *ReadOnly) DeleteRange([]byte, []byte) error {
+	return ErrReadOnly
+}
+
+// Compact fails with ErrReadOnly.
+func (r *ReadOnly) Compact([]byte, []byte) error {
+	return ErrReadOnly
+}
+
+// NewBatch creates a new batch,
+// which fails on write.
+func (r *ReadOnly) NewBatch() ethdb.Batch {
+	return &readOnlyBatch{}
+}
+
+// NewBatchWithSize creates a new batch,
+// which fails on write.
+func (r *ReadOnly) NewBatchWithSize(int) ethdb.Batch {
+	return &readOnlyBatch{}
+}
+
+// NewIterator creates a binary-alphabetical
+// iterator over the underlying store.
+func (r *ReadOnly) NewIterator(prefix, start []byte) ethdb.Iterator {
+	return r.db.NewIterator(prefix, start)
+}
+
+// NewReverseIterator creates a binary-alphabetical
+// iterator in descending order over the
+// underlying store.
+func (r *ReadOnly) NewReverseIterator(prefix, start []byte) ethdb.Iterator {
+	return r.db.NewReverseIterator(prefix, start)
+}
+
+// Close closes the underlying store.
+func (r *ReadOnly) Close() error {
+	return r.db.Close()
+}
+
+// readOnlyBatch is a batch
+// that rejects all writes.
+type readOnlyBatch struct{}
+
+// Put fails with ErrReadOnly.
+func (b *readOnlyBatch) Put([]byte, []byte) error {
+	return ErrReadOnly
+}
+
+// Delete fails with ErrReadOnly.
+func (b *readOnlyBatch) Delete([]byte) error {
+	return ErrReadOnly
+}
+
+// ValueSize returns zero, as
+// nothing is queued up.
+func (b *readOnlyBatch) ValueSize() int {
+	return 0
+}
+
+// Write fails with ErrReadOnly.
+func (b *readOnlyBatch) Write() error {
+	return ErrReadOnly
+}
+
+// Reset does nothing.
+func (b *readOnlyBatch) Reset() {}
+
+// Replay does nothing, as
+// nothing is queued up.
+func (b *readOnlyBatch) Replay(ethdb.KeyValueWriter) error {
+	return nil
+}
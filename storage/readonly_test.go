@@ -0,0 +1,45 @@
+package storage_test
+
+import (
+	"errors"
+	"sparseth/storage"
+	"sparseth/storage/mem"
+	"testing"
+)
+
+func TestReadOnly(t *testing.T) {
+	t.Run("should read underlying store", func(t *testing.T) {
+		db := mem.New()
+		if err := db.Put([]byte("key"), []byte("val")); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		val, err := storage.NewReadOnly(db).Get([]byte("key"))
+		if err != nil || string(val) != "val" {
+			t.Errorf("expected val, got %s and %v", val, err)
+		}
+	})
+
+	t.Run("should reject writes", func(t *testing.T) {
+		db := mem.New()
+		ro := storage.NewReadOnly(db)
+		if err := ro.Put([]byte("key"), []byte("val")); !errors.Is(err, storage.ErrReadOnly) {
+			t.Errorf("expected read-only error, got %v", err)
+		}
+		if err := ro.Delete([]byte("key")); !errors.Is(err, storage.ErrReadOnly) {
+			t.Errorf("expected read-only error, got %v", err)
+		}
+
+		batch := ro.NewBatch()
+		if err := batch.Put([]byte("key"), []byte("val")); !errors.Is(err, storage.ErrReadOnly) {
+			t.Errorf("expected read-only error, got %v", err)
+		}
+		if err := batch.Write(); !errors.Is(err, storage.ErrReadOnly) {
+			t.Errorf("expected read-only error, got %v", err)
+		}
+
+		if has, _ := db.Has([]byte("key")); has {
+			t.Errorf("expected key not to be written")
+		}
+	})
+}
@@ -0,0 +1,118 @@
+package storage
+
+import (
+	"github.com/ethereum/go-ethereum/ethdb"
+)
+
+// SnapshotStore is a KeyValStore that reads from
+// a snapshot, and rejects all writes with
+// ErrReadOnly, so that stores built on a
+// KeyValStore can read from snapshots.
+//
+// Closing the store releases the snapshot.
+type SnapshotStore struct {
+	snap Snapshot
+}
+
+// NewSnapshotStore creates a new SnapshotStore
+// reading from the specified snapshot.
+func NewSnapshotStore(snap Snapshot) *SnapshotStore {
+	return &SnapshotStore{snap: snap}
+}
+
+// Has checks if the specified key
+// exists in the snapshot.
+func (s *SnapshotStore) Has(key []byte) (bool, error) {
+	return s.snap.Has(key)
+}
+
+// Get retrieves the value associated with
+// the specified key, if present.
+func (s *SnapshotStore) Get(key []byte) ([]byte, error) {
+	return s.snap.Get(key)
+}
+
+// Put fails with ErrReadOnly.
+func (s *SnapshotStore) Put([]byte, []byte) error {
+	return ErrReadOnly
+}
+
+// Delete fails with ErrReadOnly.
+func (s *SnapshotStore) Delete([]byte) error {
+	return ErrReadOnly
+}
+
+// Stat returns no statistics,
+// as they are not snapshotted.
+func (s *SnapshotStore) Stat() (string, error) {
+	return "Snapshot", nil
+}
+
+// Size returns zero, as the size
+// is not snapshotted.
+func (s *SnapshotStore) Size() (uint64, error) {
+	return 0, nil
+}
+
+// Snapshot returns the snapshot
+// itself, which is not released
+// before the store is closed.
+func (s *SnapshotStore) Snapshot() (Snapshot, error) {
+	return &nestedSnapshot{s.snap}, nil
+}
+
+// SyncKeyValue does nothing, as
+// there are no pending writes.
+func (s *SnapshotStore) SyncKeyValue() error {
+	return nil
+}
+
+// DeleteRange fails with ErrReadOnly.
+func (s *SnapshotStore) DeleteRange([]byte, []byte) error {
+	return ErrReadOnly
+}
+
+// Compact fails with ErrReadOnly.
+func (s *SnapshotStore) Compact([]byte, []byte) error {
+	return ErrReadOnly
+}
+
+// NewBatch creates a new batch,
+// which fails on write.
+func (s *SnapshotStore) NewBatch() ethdb.Batch {
+	return &readOnlyBatch{}
+}
+
+// NewBatchWithSize creates a new batch,
+// which fails on write.
+func (s *SnapshotStore) NewBatchWithSize(int) ethdb.Batch {
+	return &readOnlyBatch{}
+}
+
+// NewIterator creates a binary-alphabetical
+// iterator over the snapshot.
+func (s *SnapshotStore) NewIterator(prefix, start []byte) ethdb.Iterator {
+	return s.snap.NewIterator(prefix, start)
+}
+
+// NewReverseIterator creates a binary-alphabetical
+// iterator in descending order over the snapshot.
+func (s *SnapshotStore) NewReverseIterator(prefix, start []byte) ethdb.Iterator {
+	return s.snap.NewReverseIterator(prefix, start)
+}
+
+// Close releases the snapshot.
+func (s *SnapshotStore) Close() error {
+	s.snap.Release()
+	return nil
+}
+
+// nestedSnapshot is a snapshot of a
+// SnapshotStore, which is released
+// with the store.
+type nestedSnapshot struct {
+	Snapshot
+}
+
+// Release does nothing.
+func (s *nestedSnapshot) Release() {}
@@ -0,0 +1,322 @@
+package storage
+
+import (
+	"github.com/ethereum/go-ethereum/ethdb"
+)
+
+// Table is a KeyValStore that prefixes all keys
+// with a fixed prefix before passing them on to
+// an underlying store, so that subsystems sharing
+// a store cannot collide, and all keys of one
+// subsystem can be pruned at once via DeleteRange.
+//
+// Closing a table does not close the
+// underlying store.
+type Table struct {
+	db     KeyValStore
+	prefix string
+}
+
+// NewTable creates a new Table that prefixes all
+// keys with the specified prefix, and stores them
+// in the specified store.
+func NewTable(db KeyValStore, prefix string) *Table {
+	return &Table{
+		db:     db,
+		prefix: prefix,
+	}
+}
+
+// Has checks if the specified key
+// exists in the table.
+func (t *Table) Has(key []byte) (bool, error) {
+	return t.db.Has(t.key(key))
+}
+
+// Get retrieves the value associated with
+// the specified key, if present.
+func (t *Table) Get(key []byte) ([]byte, error) {
+	return t.db.Get(t.key(key))
+}
+
+// Put inserts the specified key-value
+// pair into the table.
+func (t *Table) Put(key, val []byte) error {
+	return t.db.Put(t.key(key), val)
+}
+
+// Delete removes the specified
+// key from the table.
+func (t *Table) Delete(key []byte) error {
+	return t.db.Delete(t.key(key))
+}
+
+// Stat returns statistic data
+// of the underlying store.
+func (t *Table) Stat() (string, error) {
+	return t.db.Stat()
+}
+
+// Size returns the size of
+// the underlying store.
+func (t *Table) Size() (uint64, error) {
+	return t.db.Size()
+}
+
+// Snapshot creates a snapshot of the
+// table, whose keys are read without
+// the prefix of the table.
+func (t *Table) Snapshot() (Snapshot, error) {
+	snap, err := t.db.Snapshot()
+	if err != nil {
+		return nil, err
+	}
+	return &tableSnapshot{snap: snap, prefix: t.prefix}, nil
+}
+
+// SyncKeyValue ensures that all pending
+// writes are flushed to disk.
+func (t *Table) SyncKeyValue() error {
+	return t.db.SyncKeyValue()
+}
+
+// DeleteRange deletes all keys (and values)
+// in the range [start, end) of the table,
+// where a nil end is the end of the table.
+func (t *Table) DeleteRange(start, end []byte) error {
+	return t.db.DeleteRange(t.key(start), t.limit(end))
+}
+
+// Compact flattens the key range [start, limit)
+// of the table, where a nil limit is the end of
+// the table.
+func (t *Table) Compact(start []byte, limit []byte) error {
+	return t.db.Compact(t.key(start), t.limit(limit))
+}
+
+// Close does nothing, as the underlying
+// store is shared with other tables.
+func (t *Table) Close() error {
+	return nil
+}
+
+// NewBatch creates a new write-only batch,
+// whose keys are prefixed on write.
+func (t *Table) NewBatch() ethdb.Batch {
+	return &tableBatch{
+		batch:  t.db.NewBatch(),
+		prefix: t.prefix,
+	}
+}
+
+// NewBatchWithSize creates a new batch with
+// a pre-allocated buffer of the specified
+// size.
+func (t *Table) NewBatchWithSize(size int) ethdb.Batch {
+	return &tableBatch{
+		batch:  t.db.NewBatchWithSize(size),
+		prefix: t.prefix,
+	}
+}
+
+// NewIterator creates a binary-alphabetical
+// iterator over a subset of the table with
+// the specified key prefix, starting at the
+// specified initial key. Keys are returned
+// without the prefix of the table.
+func (t *Table) NewIterator(prefix, start []byte) ethdb.Iterator {
+	return &tableIterator{
+		it:     t.db.NewIterator(t.key(prefix), start),
+		prefix: t.prefix,
+	}
+}
+
+// NewReverseIterator creates a binary-alphabetical
+// iterator in descending order over a subset of the
+// table, see NewIterator. Without a start key, it
+// starts at the last key of the table.
+func (t *Table) NewReverseIterator(prefix, start []byte) ethdb.Iterator {
+	return &tableIterator{
+		it:     t.db.NewReverseIterator(t.key(prefix), start),
+		prefix: t.prefix,
+	}
+}
+
+// key returns the specified
+// key with the table prefix.
+func (t *Table) key(key []byte) []byte {
+	prefixed := make([]byte, 0, len(t.prefix)+len(key))
+	return append(append(prefixed, t.prefix...), key...)
+}
+
+// limit returns the specified limit of a range
+// with the table prefix, where nil is the first
+// key after the table.
+func (t *Table) limit(limit []byte) []byte {
+	if limit != nil {
+		return t.key(limit)
+	}
+	for i := len(t.prefix) - 1; i >= 0; i-- {
+		if t.prefix[i] == 0xff {
+			continue
+		}
+		next := []byte(t.prefix[:i+1])
+		next[i]++
+		return next
+	}
+	// No key follows the table
+	return nil
+}
+
+// tableBatch is a batch
+// that prefixes all keys.
+type tableBatch struct {
+	batch  ethdb.Batch
+	prefix string
+}
+
+// Put inserts the specified key-value
+// pair into the batch.
+func (b *tableBatch) Put(key, val []byte) error {
+	return b.batch.Put(append([]byte(b.prefix), key...), val)
+}
+
+// Delete marks the specified key
+// for deletion in the batch.
+func (b *tableBatch) Delete(key []byte) error {
+	return b.batch.Delete(append([]byte(b.prefix), key...))
+}
+
+// ValueSize retrieves the total size of data
+// queued up for writing in the batch.
+func (b *tableBatch) ValueSize() int {
+	return b.batch.ValueSize()
+}
+
+// Write commits changes in the batch
+// to the underlying store.
+func (b *tableBatch) Write() error {
+	return b.batch.Write()
+}
+
+// Reset clears the batch for reuse.
+func (b *tableBatch) Reset() {
+	b.batch.Reset()
+}
+
+// Replay replays the batch contents to the
+// specified writer, without the prefix of
+// the table.
+func (b *tableBatch) Replay(w ethdb.KeyValueWriter) error {
+	return b.batch.Replay(&tableReplayer{
+		w:      w,
+		prefix: b.prefix,
+	})
+}
+
+// tableReplayer strips the prefix of
+// the table from all replayed keys.
+type tableReplayer struct {
+	w      ethdb.KeyValueWriter
+	prefix string
+}
+
+// Put inserts the specified key-value
+// pair without the table prefix.
+func (r *tableReplayer) Put(key, val []byte) error {
+	return r.w.Put(key[len(r.prefix):], val)
+}
+
+// Delete removes the specified
+// key without the table prefix.
+func (r *tableReplayer) Delete(key []byte) error {
+	return r.w.Delete(key[len(r.prefix):])
+}
+
+// tableSnapshot is a snapshot that
+// prefixes all keys with the prefix
+// of the table.
+type tableSnapshot struct {
+	snap   Snapshot
+	prefix string
+}
+
+// Has checks if the specified key
+// exists in the snapshot.
+func (s *tableSnapshot) Has(key []byte) (bool, error) {
+	return s.snap.Has(append([]byte(s.prefix), key...))
+}
+
+// Get retrieves the value associated with
+// the specified key, if present.
+func (s *tableSnapshot) Get(key []byte) ([]byte, error) {
+	return s.snap.Get(append([]byte(s.prefix), key...))
+}
+
+// NewIterator creates a binary-alphabetical
+// iterator over a subset of the snapshot,
+// see Table.NewIterator.
+func (s *tableSnapshot) NewIterator(prefix, start []byte) ethdb.Iterator {
+	return &tableIterator{
+		it:     s.snap.NewIterator(append([]byte(s.prefix), prefix...), start),
+		prefix: s.prefix,
+	}
+}
+
+// NewReverseIterator creates a binary-alphabetical
+// iterator in descending order over a subset of
+// the snapshot, see Table.NewReverseIterator.
+func (s *tableSnapshot) NewReverseIterator(prefix, start []byte) ethdb.Iterator {
+	return &tableIterator{
+		it:     s.snap.NewReverseIterator(append([]byte(s.prefix), prefix...), start),
+		prefix: s.prefix,
+	}
+}
+
+// Release releases the
+// underlying snapshot.
+func (s *tableSnapshot) Release() {
+	s.snap.Release()
+}
+
+// tableIterator is an iterator that strips
+// the prefix of the table from all keys.
+type tableIterator struct {
+	it     ethdb.Iterator
+	prefix string
+}
+
+// Next moves the iterator to the
+// next key-value pair.
+func (it *tableIterator) Next() bool {
+	return it.it.Next()
+}
+
+// Error returns any accumulated
+// error during iteration.
+func (it *tableIterator) Error() error {
+	return it.it.Error()
+}
+
+// Key returns the key of the current key-value
+// pair without the table prefix, or nil if the
+// iterator is already exhausted.
+func (it *tableIterator) Key() []byte {
+	key := it.it.Key()
+	if key == nil {
+		return nil
+	}
+	return key[len(it.prefix):]
+}
+
+// Value returns the value of the current
+// key-value pair, or nil if the iterator
+// is already exhausted.
+func (it *tableIterator) Value() []byte {
+	return it.it.Value()
+}
+
+// Release releases associated resources.
+func (it *tableIterator) Release() {
+	it.it.Release()
+}
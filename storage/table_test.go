@@ -0,0 +1,155 @@
+package storage_test
+
+import (
+	"bytes"
+	"sparseth/storage"
+	"sparseth/storage/mem"
+	"testing"
+)
+
+func newTestTables(t *testing.T) (storage.KeyValStore, storage.KeyValStore, storage.KeyValStore) {
+	db := mem.New()
+	headers := storage.NewTable(db, "h")
+	events := storage.NewTable(db, "e")
+	for _, key := range []string{"1", "2", "3"} {
+		if err := headers.Put([]byte(key), []byte("header")); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if err := events.Put([]byte(key), []byte("event")); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+	return db, headers, events
+}
+
+func TestTable_Get(t *testing.T) {
+	t.Run("should not collide with other table", func(t *testing.T) {
+		db, headers, events := newTestTables(t)
+
+		header, err := headers.Get([]byte("1"))
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		event, err := events.Get([]byte("1"))
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if string(header) != "header" || string(event) != "event" {
+			t.Errorf("expected header and event, got %s and %s", header, event)
+		}
+
+		if _, err = db.Get([]byte("h1")); err != nil {
+			t.Errorf("expected prefixed key in store, got %v", err)
+		}
+	})
+}
+
+func TestTable_NewIterator(t *testing.T) {
+	t.Run("should iterate keys of table without prefix", func(t *testing.T) {
+		_, headers, _ := newTestTables(t)
+
+		it := headers.NewIterator(nil, []byte("2"))
+		defer it.Release()
+
+		var keys []string
+		for it.Next() {
+			keys = append(keys, string(it.Key()))
+			if !bytes.Equal(it.Value(), []byte("header")) {
+				t.Errorf("expected header, got %s", it.Value())
+			}
+		}
+		if len(keys) != 2 || keys[0] != "2" || keys[1] != "3" {
+			t.Errorf("expected keys 2 and 3, got %v", keys)
+		}
+	})
+}
+
+func TestTable_DeleteRange(t *testing.T) {
+	t.Run("should delete whole table only", func(t *testing.T) {
+		_, headers, events := newTestTables(t)
+
+		if err := headers.DeleteRange(nil, nil); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		for _, key := range []string{"1", "2", "3"} {
+			if exists, _ := headers.Has([]byte(key)); exists {
+				t.Errorf("expected header %s to be deleted", key)
+			}
+			if exists, _ := events.Has([]byte(key)); !exists {
+				t.Errorf("expected event %s to exist", key)
+			}
+		}
+	})
+}
+
+func TestTable_NewBatch(t *testing.T) {
+	t.Run("should write and replay keys of table", func(t *testing.T) {
+		db, headers, _ := newTestTables(t)
+
+		b := headers.NewBatch()
+		if err := b.Put([]byte("4"), []byte("header")); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if err := b.Write(); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if exists, _ := db.Has([]byte("h4")); !exists {
+			t.Errorf("expected prefixed key in store")
+		}
+
+		replayed := mem.New()
+		if err := b.Replay(replayed); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if exists, _ := replayed.Has([]byte("4")); !exists {
+			t.Errorf("expected replayed key without prefix")
+		}
+	})
+}
+
+func TestTable_Snapshot(t *testing.T) {
+	t.Run("should read keys of table at snapshot", func(t *testing.T) {
+		_, headers, _ := newTestTables(t)
+
+		snap, err := headers.Snapshot()
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		defer snap.Release()
+		if err = headers.Put([]byte("4"), []byte("header")); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		val, err := snap.Get([]byte("1"))
+		if err != nil || string(val) != "header" {
+			t.Errorf("expected header, got %s and %v", val, err)
+		}
+
+		it := snap.NewIterator(nil, nil)
+		defer it.Release()
+		var keys []string
+		for it.Next() {
+			keys = append(keys, string(it.Key()))
+		}
+		if len(keys) != 3 || keys[0] != "1" {
+			t.Errorf("expected keys 1 to 3, got %v", keys)
+		}
+	})
+}
+
+func TestTable_NewReverseIterator(t *testing.T) {
+	t.Run("should iterate keys of table in descending order", func(t *testing.T) {
+		_, headers, _ := newTestTables(t)
+
+		it := headers.NewReverseIterator(nil, nil)
+		defer it.Release()
+		var keys []string
+		for it.Next() {
+			keys = append(keys, string(it.Key()))
+		}
+		if len(keys) != 3 || keys[0] != "3" || keys[2] != "1" {
+			t.Errorf("expected keys 3 to 1, got %v", keys)
+		}
+	})
+}
@@ -0,0 +1,81 @@
+package sync
+
+import (
+	"context"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/mclock"
+)
+
+// backoff computes exponentially growing
+// delays between consecutive attempts of
+// a failing operation.
+type backoff struct {
+	clock   mclock.Clock
+	base    time.Duration
+	max     time.Duration
+	attempt int
+}
+
+// newBackoff creates a new backoff starting at
+// the specified base delay, doubling the delay
+// after each attempt up to the specified max
+// delay.
+func newBackoff(clock mclock.Clock, base, max time.Duration) *backoff {
+	return &backoff{
+		clock: clock,
+		base:  base,
+		max:   max,
+	}
+}
+
+// Next returns the delay before the next
+// attempt, and advances the backoff.
+func (b *backoff) Next() time.Duration {
+	delay := b.base << b.attempt
+	if delay > b.max || delay <= 0 {
+		delay = b.max
+	} else {
+		b.attempt++
+	}
+	return delay
+}
+
+// Wait blocks for the next delay, or until the
+// context is canceled, whichever comes first.
+func (b *backoff) Wait(ctx context.Context) error {
+	select {
+	case <-b.clock.After(b.Next()):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Reset restarts the backoff at the base delay.
+func (b *backoff) Reset() {
+	b.attempt = 0
+}
+
+// retry calls the specified function until it
+// succeeds, the maximum number of attempts is
+// reached, or the context is canceled. Between
+// attempts, retry waits according to the
+// specified backoff.
+//
+// The error of the last attempt is returned.
+func retry(ctx context.Context, b *backoff, attempts int, fn func() error) error {
+	var err error
+	for i := 0; i < attempts; i++ {
+		if err = fn(); err == nil {
+			return nil
+		}
+		if i == attempts-1 {
+			break
+		}
+		if waitErr := b.Wait(ctx); waitErr != nil {
+			return err
+		}
+	}
+	return err
+}
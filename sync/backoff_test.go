@@ -0,0 +1,109 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/mclock"
+)
+
+func TestBackoff_Next(t *testing.T) {
+	t.Run("should double delay up to max", func(t *testing.T) {
+		b := newBackoff(new(mclock.Simulated), time.Second, 5*time.Second)
+
+		expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+		for i, want := range expected {
+			if got := b.Next(); got != want {
+				t.Errorf("attempt %d: expected %v, got %v", i, want, got)
+			}
+		}
+	})
+
+	t.Run("should restart at base delay after reset", func(t *testing.T) {
+		b := newBackoff(new(mclock.Simulated), time.Second, 5*time.Second)
+
+		b.Next()
+		b.Next()
+		b.Reset()
+
+		if got := b.Next(); got != time.Second {
+			t.Errorf("expected %v, got %v", time.Second, got)
+		}
+	})
+}
+
+func TestRetry(t *testing.T) {
+	t.Run("should not wait if first attempt succeeds", func(t *testing.T) {
+		clock := new(mclock.Simulated)
+
+		calls := 0
+		err := retry(t.Context(), newBackoff(clock, time.Second, time.Minute), 3, func() error {
+			calls++
+			return nil
+		})
+		if err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+		if calls != 1 {
+			t.Errorf("expected 1 call, got %d", calls)
+		}
+	})
+
+	t.Run("should return last error after all attempts", func(t *testing.T) {
+		clock := new(mclock.Simulated)
+		failure := errors.New("failure")
+
+		done := make(chan error)
+		calls := 0
+		go func() {
+			done <- retry(t.Context(), newBackoff(clock, time.Second, time.Minute), 3, func() error {
+				calls++
+				return failure
+			})
+		}()
+
+		// Two waits between three attempts: 1s, 2s
+		for _, d := range []time.Duration{time.Second, 2 * time.Second} {
+			clock.WaitForTimers(1)
+			clock.Run(d)
+		}
+
+		select {
+		case err := <-done:
+			if !errors.Is(err, failure) {
+				t.Errorf("expected %v, got %v", failure, err)
+			}
+			if calls != 3 {
+				t.Errorf("expected 3 calls, got %d", calls)
+			}
+		case <-time.After(time.Second):
+			t.Errorf("timeout: retry did not return")
+		}
+	})
+
+	t.Run("should stop retrying when context is canceled", func(t *testing.T) {
+		clock := new(mclock.Simulated)
+		ctx, cancel := context.WithCancel(t.Context())
+
+		done := make(chan error)
+		go func() {
+			done <- retry(ctx, newBackoff(clock, time.Second, time.Minute), 3, func() error {
+				return errors.New("failure")
+			})
+		}()
+
+		clock.WaitForTimers(1)
+		cancel()
+
+		select {
+		case err := <-done:
+			if err == nil {
+				t.Errorf("expected error, got nil")
+			}
+		case <-time.After(time.Second):
+			t.Errorf("timeout: retry did not return")
+		}
+	})
+}
@@ -0,0 +1,150 @@
+package sync
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// finalizedCheckpointTopic is the Beacon API event
+// topic of finalized checkpoints.
+const finalizedCheckpointTopic = "finalized_checkpoint"
+
+// executionBlock identifies the execution
+// payload of a beacon block.
+type executionBlock struct {
+	Number uint64
+	Hash   common.Hash
+}
+
+// finalizedCheckpoint is a finalized
+// checkpoint event of the Beacon API.
+type finalizedCheckpoint struct {
+	Block common.Hash `json:"block"`
+	Epoch string      `json:"epoch"`
+}
+
+// beaconAPI is a minimal client of the
+// Beacon Node API of a consensus client.
+type beaconAPI struct {
+	url string
+	c   *http.Client
+}
+
+// newBeaconAPI creates a new client of
+// the Beacon API at the specified URL.
+func newBeaconAPI(url string) *beaconAPI {
+	return &beaconAPI{
+		url: strings.TrimSuffix(url, "/"),
+		c:   &http.Client{},
+	}
+}
+
+// executionBlock fetches the execution payload of
+// the beacon block with the specified id, which is
+// either a block root or a named block, such as
+// finalized.
+func (a *beaconAPI) executionBlock(ctx context.Context, id string) (*executionBlock, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, a.url+"/eth/v2/beacon/blocks/"+id, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	res, err := a.c.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch beacon block %s: %w", id, err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch beacon block %s: status %d", id, res.StatusCode)
+	}
+
+	var block struct {
+		Data struct {
+			Message struct {
+				Body struct {
+					ExecutionPayload *struct {
+						BlockNumber string      `json:"block_number"`
+						BlockHash   common.Hash `json:"block_hash"`
+					} `json:"execution_payload"`
+				} `json:"body"`
+			} `json:"message"`
+		} `json:"data"`
+	}
+	if err = json.NewDecoder(res.Body).Decode(&block); err != nil {
+		return nil, fmt.Errorf("failed to decode beacon block %s: %w", id, err)
+	}
+
+	payload := block.Data.Message.Body.ExecutionPayload
+	if payload == nil {
+		return nil, fmt.Errorf("beacon block %s has no execution payload", id)
+	}
+	num, err := strconv.ParseUint(payload.BlockNumber, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid execution block number %q: %w", payload.BlockNumber, err)
+	}
+
+	return &executionBlock{
+		Number: num,
+		Hash:   payload.BlockHash,
+	}, nil
+}
+
+// subscribeFinalized streams finalized checkpoint
+// events, passing each to the specified function,
+// until the stream fails or the context is canceled.
+func (a *beaconAPI) subscribeFinalized(ctx context.Context, fn func(*finalizedCheckpoint) error) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, a.url+"/eth/v1/events?topics="+finalizedCheckpointTopic, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	res, err := a.c.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to events: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to subscribe to events: status %d", res.StatusCode)
+	}
+
+	// Events are separated by blank lines, and
+	// consist of an event and a data field
+	var event, data string
+	scanner := bufio.NewScanner(res.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if event == finalizedCheckpointTopic && data != "" {
+				var cp finalizedCheckpoint
+				if err = json.Unmarshal([]byte(data), &cp); err != nil {
+					return fmt.Errorf("failed to decode finalized checkpoint: %w", err)
+				}
+				if err = fn(&cp); err != nil {
+					return err
+				}
+			}
+			event, data = "", ""
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data += strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		}
+	}
+
+	if err = scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read events: %w", err)
+	}
+	return fmt.Errorf("event stream closed")
+}
@@ -0,0 +1,102 @@
+package sync
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func newTestBeaconAPI(t *testing.T, handler http.HandlerFunc) *beaconAPI {
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	return newBeaconAPI(srv.URL + "/")
+}
+
+func TestBeaconAPI_ExecutionBlock(t *testing.T) {
+	t.Run("should decode execution payload", func(t *testing.T) {
+		hash := common.HexToHash("0x01")
+		api := newTestBeaconAPI(t, func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/eth/v2/beacon/blocks/finalized" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			fmt.Fprintf(w, `{"version":"deneb","data":{"message":{"slot":"64","body":{"execution_payload":{"block_number":"42","block_hash":"%s"}}}}}`, hash.Hex())
+		})
+
+		block, err := api.executionBlock(t.Context(), "finalized")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if block.Number != 42 {
+			t.Errorf("expected 42, got %d", block.Number)
+		}
+		if block.Hash != hash {
+			t.Errorf("expected %s, got %s", hash.Hex(), block.Hash.Hex())
+		}
+	})
+
+	t.Run("should fail without execution payload", func(t *testing.T) {
+		api := newTestBeaconAPI(t, func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"version":"phase0","data":{"message":{"slot":"64","body":{}}}}`)
+		})
+
+		if _, err := api.executionBlock(t.Context(), "finalized"); err == nil {
+			t.Errorf("expected error, got nil")
+		}
+	})
+
+	t.Run("should fail on error status", func(t *testing.T) {
+		api := newTestBeaconAPI(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		})
+
+		if _, err := api.executionBlock(t.Context(), "finalized"); err == nil {
+			t.Errorf("expected error, got nil")
+		}
+	})
+}
+
+func TestBeaconAPI_SubscribeFinalized(t *testing.T) {
+	t.Run("should pass finalized checkpoints only", func(t *testing.T) {
+		api := newTestBeaconAPI(t, func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("topics") != finalizedCheckpointTopic {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			fmt.Fprint(w, "event: head\ndata: {\"slot\":\"1\"}\n\n")
+			fmt.Fprint(w, "event: finalized_checkpoint\ndata: {\"block\":\"0x0000000000000000000000000000000000000000000000000000000000000001\",\"epoch\":\"2\"}\n\n")
+			fmt.Fprint(w, ": keep-alive\n\n")
+			fmt.Fprint(w, "event: finalized_checkpoint\ndata: {\"block\":\"0x0000000000000000000000000000000000000000000000000000000000000002\",\"epoch\":\"3\"}\n\n")
+		})
+
+		var epochs []string
+		err := api.subscribeFinalized(t.Context(), func(cp *finalizedCheckpoint) error {
+			epochs = append(epochs, cp.Epoch)
+			return nil
+		})
+		if err == nil {
+			t.Errorf("expected error on closed stream, got nil")
+		}
+		if len(epochs) != 2 || epochs[0] != "2" || epochs[1] != "3" {
+			t.Errorf("expected epochs [2 3], got %v", epochs)
+		}
+	})
+
+	t.Run("should stop on callback error", func(t *testing.T) {
+		api := newTestBeaconAPI(t, func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, "event: finalized_checkpoint\ndata: {\"block\":\"0x0000000000000000000000000000000000000000000000000000000000000001\",\"epoch\":\"2\"}\n\n")
+		})
+
+		want := errors.New("stop")
+		err := api.subscribeFinalized(t.Context(), func(*finalizedCheckpoint) error {
+			return want
+		})
+		if !errors.Is(err, want) {
+			t.Errorf("expected %v, got %v", want, err)
+		}
+	})
+}
@@ -0,0 +1,28 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"sparseth/config"
+	"sparseth/ethstore"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// loadCheckpoint fetches the header of the specified
+// checkpoint block, verifies it against the checkpoint,
+// and initializes the specified header store with it.
+func loadCheckpoint(ctx context.Context, ec *ethclient.Client, cp *config.Checkpoint, db *ethstore.HeaderStore) (*types.Header, error) {
+	header, err := ec.HeaderByHash(ctx, cp.Hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch checkpoint block: %w", err)
+	}
+	if err = cp.Verify(header); err != nil {
+		return nil, fmt.Errorf("invalid checkpoint block: %w", err)
+	}
+	if err = db.Put(header); err != nil {
+		return nil, fmt.Errorf("failed to store checkpoint block header: %w", err)
+	}
+	return header, nil
+}
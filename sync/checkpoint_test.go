@@ -0,0 +1,44 @@
+package sync
+
+import (
+	"sparseth/config"
+	"sparseth/ethstore"
+	"sparseth/storage/mem"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+func TestLoadCheckpoint(t *testing.T) {
+	t.Run("should store verified checkpoint header", func(t *testing.T) {
+		chain, rc := newTestChainClient(t, 4)
+		defer rc.Close()
+
+		store := ethstore.NewHeaderStore(mem.New())
+		num := uint64(2)
+		cp := &config.Checkpoint{Hash: chain[2].Hash(), Number: &num}
+
+		head, err := loadCheckpoint(t.Context(), ethclient.NewClient(rc), cp, store)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if head.Hash() != chain[2].Hash() {
+			t.Errorf("expected hash %s, got %s", chain[2].Hash(), head.Hash())
+		}
+		if _, err = store.GetByNumber(2); err != nil {
+			t.Errorf("expected stored header, got %v", err)
+		}
+	})
+
+	t.Run("should reject mismatching checkpoint", func(t *testing.T) {
+		chain, rc := newTestChainClient(t, 4)
+		defer rc.Close()
+
+		num := uint64(3)
+		cp := &config.Checkpoint{Hash: chain[2].Hash(), Number: &num}
+
+		if _, err := loadCheckpoint(t.Context(), ethclient.NewClient(rc), cp, ethstore.NewHeaderStore(mem.New())); err == nil {
+			t.Errorf("expected error, got nil")
+		}
+	})
+}
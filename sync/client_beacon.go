@@ -0,0 +1,180 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"sparseth/bus"
+	"sparseth/config"
+	"sparseth/ethstore"
+	"sparseth/log"
+	"sparseth/storage"
+
+	"github.com/ethereum/go-ethereum/common/mclock"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// BeaconClient is a consensus client that follows
+// the finalized checkpoints of a Beacon API, and
+// only publishes finalized block headers, i.e.,
+// published block headers are never reorged.
+//
+// Execution block headers are downloaded from the
+// execution layer, and must link to the previously
+// published block header as well as to the block
+// hash of the finalized execution payload.
+type BeaconClient struct {
+	api    *beaconAPI
+	db     *ethstore.HeaderStore
+	ec     *ethclient.Client
+	cp     *config.Checkpoint
+	clock  mclock.Clock
+	log    log.Logger
+	pub    chan<- *types.Header
+	status *bus.Topic[*bus.SyncStatus]
+	// last is the last published
+	// block header.
+	last *types.Header
+}
+
+// NewBeaconClient creates a new consensus client
+// following the finalized checkpoints of the Beacon
+// API at the specified URL, syncing from the specified
+// checkpoint, publishing finalized block headers at
+// the returned channel. Changes of the sync status
+// are published to the specified topic.
+func NewBeaconClient(log log.Logger, beaconURL string, rpc *rpc.Client, cp *config.Checkpoint, db storage.KeyValStore, clock mclock.Clock, status *bus.Topic[*bus.SyncStatus]) (*BeaconClient, <-chan *types.Header) {
+	ch := make(chan *types.Header, 128)
+
+	return &BeaconClient{
+		api:    newBeaconAPI(beaconURL),
+		db:     ethstore.NewHeaderStore(db),
+		ec:     ethclient.NewClient(rpc),
+		cp:     cp,
+		clock:  clock,
+		pub:    ch,
+		status: status,
+		log:    log.With("component", "beacon-sync-client"),
+	}, ch
+}
+
+// RunContext starts the consensus client, i.e.,
+// finalized block headers are fetched and published
+// until the context is canceled.
+//
+// If the event stream of the Beacon API is lost,
+// RunContext re-subscribes with exponential backoff,
+// and catches up with the latest finalized block.
+func (c *BeaconClient) RunContext(ctx context.Context) error {
+	defer close(c.pub)
+
+	checkpoint, err := loadCheckpoint(ctx, c.ec, c.cp, c.db)
+	if err != nil {
+		return err
+	}
+	c.last = checkpoint
+
+	b := newBackoff(c.clock, retryBaseDelay, retryMaxDelay)
+	for {
+		err = c.follow(ctx, b)
+		if ctx.Err() != nil {
+			c.log.Info("stop finalized block sync")
+			return nil
+		}
+
+		c.log.Warn("finalized block sync interrupted, reconnect", "err", err)
+		c.publishStatus(bus.SyncStateReconnecting)
+		if err = b.Wait(ctx); err != nil {
+			c.log.Info("stop finalized block sync")
+			return nil
+		}
+	}
+}
+
+// follow catches up with the latest finalized block,
+// and then publishes the block headers of all newly
+// finalized blocks until the event stream fails or
+// the context is canceled.
+func (c *BeaconClient) follow(ctx context.Context, b *backoff) error {
+	finalized, err := c.api.executionBlock(ctx, "finalized")
+	if err != nil {
+		return fmt.Errorf("failed to fetch finalized block: %w", err)
+	}
+
+	c.log.Info("catch up with finalized block", "num", finalized.Number, "hash", finalized.Hash.Hex())
+	c.publishStatus(bus.SyncStateSyncing)
+	if err = c.advance(ctx, finalized); err != nil {
+		return err
+	}
+
+	c.publishStatus(bus.SyncStateFollowing)
+	return c.api.subscribeFinalized(ctx, func(cp *finalizedCheckpoint) error {
+		b.Reset()
+
+		finalized, err := c.api.executionBlock(ctx, cp.Block.Hex())
+		if err != nil {
+			return fmt.Errorf("failed to fetch finalized block: %w", err)
+		}
+
+		c.log.Info("new finalized checkpoint", "epoch", cp.Epoch, "num", finalized.Number, "hash", finalized.Hash.Hex())
+		return c.advance(ctx, finalized)
+	})
+}
+
+// advance publishes all block headers after the
+// last published block header up to the specified
+// finalized block.
+//
+// The block headers are fetched by walking back
+// the parent hashes from the finalized block to
+// the last published block header, so that none
+// is stored or published unless all link to the
+// finalized block.
+func (c *BeaconClient) advance(ctx context.Context, finalized *executionBlock) error {
+	last := c.last.Number.Uint64()
+	if finalized.Number <= last {
+		return nil
+	}
+
+	headers := make([]*types.Header, finalized.Number-last)
+	hash := finalized.Hash
+	for i := len(headers) - 1; i >= 0; i-- {
+		num := last + uint64(i) + 1
+		head, err := fetchHeaderByHash(ctx, c.ec, c.clock, c.log, hash)
+		if err != nil {
+			return fmt.Errorf("failed to fetch header at block %d: %w", num, err)
+		}
+		if head.Hash() != hash || head.Number.Uint64() != num {
+			return fmt.Errorf("header at block %d does not link to finalized block %s", num, finalized.Hash.Hex())
+		}
+		headers[i] = head
+		hash = head.ParentHash
+	}
+	if hash != c.last.Hash() {
+		return fmt.Errorf("finalized block %s does not link to previous header", finalized.Hash.Hex())
+	}
+
+	if err := c.db.PutAll(headers); err != nil {
+		c.log.Error("failed to store new block headers", "from", last+1, "to", finalized.Number, "err", err)
+	}
+	for _, head := range headers {
+		select {
+		case c.pub <- head:
+			c.last = head
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return nil
+}
+
+// publishStatus publishes the specified sync
+// state along with the last published block.
+func (c *BeaconClient) publishStatus(state bus.SyncState) {
+	c.status.Publish(&bus.SyncStatus{
+		State: state,
+		Head:  c.last.Number.Uint64(),
+	})
+}
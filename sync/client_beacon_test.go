@@ -0,0 +1,93 @@
+package sync
+
+import (
+	"log/slog"
+	"math/big"
+	"sparseth/config"
+	"sparseth/ethstore"
+	"sparseth/internal/log"
+	"sparseth/storage/mem"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/mclock"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestBeaconClient_Advance(t *testing.T) {
+	t.Run("should publish all headers up to finalized block in order", func(t *testing.T) {
+		chain, rc := newTestChainClient(t, 5)
+		defer rc.Close()
+
+		db := mem.New()
+		defer db.Close()
+
+		c, ch := NewBeaconClient(log.New(slog.DiscardHandler), "", rc, &config.Checkpoint{Hash: chain[0].Hash()}, db, mclock.System{}, nil)
+		c.last = chain[0]
+
+		if err := c.advance(t.Context(), &executionBlock{Number: 4, Hash: chain[4].Hash()}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		for i := 1; i < len(chain); i++ {
+			head := <-ch
+			if head.Hash() != chain[i].Hash() {
+				t.Fatalf("expected header %d, got %d", i, head.Number)
+			}
+		}
+	})
+
+	t.Run("should not publish headers of another fork", func(t *testing.T) {
+		chain, rc := newTestChainClient(t, 5)
+		defer rc.Close()
+
+		db := mem.New()
+		defer db.Close()
+
+		clock := new(mclock.Simulated)
+		c, ch := NewBeaconClient(log.New(slog.DiscardHandler), "", rc, &config.Checkpoint{Hash: chain[0].Hash()}, db, clock, nil)
+		c.last = chain[0]
+
+		// Finalized on a fork the execution layer does not serve
+		fork := &types.Header{Number: big.NewInt(4), ParentHash: common.HexToHash("0x01"), Difficulty: common.Big0}
+		done := make(chan error)
+		go func() {
+			done <- c.advance(t.Context(), &executionBlock{Number: 4, Hash: fork.Hash()})
+		}()
+		for range headerFetchAttempts - 1 {
+			clock.WaitForTimers(1)
+			clock.Run(retryMaxDelay)
+		}
+
+		if err := <-done; err == nil {
+			t.Fatalf("expected error, got nil")
+		}
+		select {
+		case head := <-ch:
+			t.Errorf("expected no header, got %d", head.Number)
+		default:
+		}
+		if _, err := ethstore.NewHeaderStore(db).GetByNumber(1); err == nil {
+			t.Errorf("expected no stored header")
+		}
+	})
+
+	t.Run("should not publish headers not linking to previous header", func(t *testing.T) {
+		chain, rc := newTestChainClient(t, 5)
+		defer rc.Close()
+
+		db := mem.New()
+		defer db.Close()
+
+		c, ch := NewBeaconClient(log.New(slog.DiscardHandler), "", rc, &config.Checkpoint{Hash: chain[0].Hash()}, db, mclock.System{}, nil)
+		c.last = &types.Header{Number: big.NewInt(1), Extra: []byte("published"), Difficulty: common.Big0}
+
+		if err := c.advance(t.Context(), &executionBlock{Number: 4, Hash: chain[4].Hash()}); err == nil {
+			t.Fatalf("expected error, got nil")
+		}
+		select {
+		case head := <-ch:
+			t.Errorf("expected no header, got %d", head.Number)
+		default:
+		}
+	})
+}
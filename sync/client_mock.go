@@ -4,42 +4,82 @@ import (
 	"context"
 	"fmt"
 	"math/big"
+	"sparseth/bus"
+	"sparseth/config"
 	"sparseth/ethstore"
 	"sparseth/log"
 	"sparseth/storage"
+	"sync/atomic"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/mclock"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/rpc"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	// headerFetchAttempts is the maximum number of
+	// attempts to download a single block header.
+	headerFetchAttempts = 5
+	// retryBaseDelay is the initial delay between
+	// two attempts of a failed operation.
+	retryBaseDelay = 500 * time.Millisecond
+	// retryMaxDelay is the maximum delay between
+	// two attempts of a failed operation.
+	retryMaxDelay = 30 * time.Second
+	// stallTimeout is the duration after which the
+	// block sync is considered stalled if no new
+	// block header has been received.
+	stallTimeout = 2 * time.Minute
+	// syncBatchSize is the number of block headers
+	// downloaded per batch during sync-up.
+	syncBatchSize = 256
+	// syncWorkers is the maximum number of block
+	// headers downloaded concurrently during sync-up.
+	syncWorkers = 16
 )
 
 // MockClient is a mock implementation of a
 // consensus client. Later, the Altair Light
 // Client Protocol will be used.
 type MockClient struct {
-	db  *ethstore.HeaderStore
-	ec  *ethclient.Client
-	cp  common.Hash
-	log log.Logger
-	pub chan<- *types.Header
+	db    *ethstore.HeaderStore
+	ec    *ethclient.Client
+	cp    *config.Checkpoint
+	clock mclock.Clock
+	log   log.Logger
+	pub   chan<- *types.Header
+	// status receives changes
+	// of the sync status.
+	status *bus.Topic[*bus.SyncStatus]
+	// last is the number of the last
+	// published block header.
+	last atomic.Uint64
 }
 
 // NewMockClient creates a new mock consensus
 // client, syncing from the specified checkpoint,
 // publishing new block headers at the returned
-// channel.
-func NewMockClient(log log.Logger, rpc *rpc.Client, cp common.Hash, db storage.KeyValStore) (*MockClient, <-chan *types.Header) {
+// channel. All time-dependent behavior, such as
+// retries and stall detection, uses the specified
+// clock. Changes of the sync status are published
+// to the specified topic.
+func NewMockClient(log log.Logger, rpc *rpc.Client, cp *config.Checkpoint, db storage.KeyValStore, clock mclock.Clock, status *bus.Topic[*bus.SyncStatus]) (*MockClient, <-chan *types.Header) {
 	ch := make(chan *types.Header, 128)
 	ec := ethclient.NewClient(rpc)
 	store := ethstore.NewHeaderStore(db)
 
 	return &MockClient{
-		db:  store,
-		ec:  ec,
-		cp:  cp,
-		pub: ch,
-		log: log.With("component", "sync-client"),
+		db:     store,
+		ec:     ec,
+		cp:     cp,
+		clock:  clock,
+		pub:    ch,
+		status: status,
+		log:    log.With("component", "sync-client"),
 	}, ch
 }
 
@@ -70,35 +110,124 @@ func (c *MockClient) RunContext(ctx context.Context) error {
 
 // syncUp fetches all block headers from
 // the checkpoint block to the latest block.
+//
+// Block headers are downloaded concurrently in
+// batches, while the previous batch is stored
+// and published in order.
 func (c *MockClient) syncUp(ctx context.Context, latest uint64) error {
-	checkpoint, err := c.ec.HeaderByHash(ctx, c.cp)
+	checkpoint, err := loadCheckpoint(ctx, c.ec, c.cp, c.db)
 	if err != nil {
-		return fmt.Errorf("failed to fetch checkpoint block: %w", err)
-	}
-	if err = c.db.Put(checkpoint); err != nil {
-		return fmt.Errorf("failed to store checkpoint block header: %w", err)
+		return err
 	}
+	c.last.Store(checkpoint.Number.Uint64())
+	c.publishStatus(bus.SyncStateSyncing)
 
-	for num := checkpoint.Number.Uint64() + 1; num <= latest; num++ {
-		c.log.Debug("download block header", "num", num)
-		head, err := c.ec.HeaderByNumber(ctx, big.NewInt(int64(num)))
-		if err != nil {
-			return fmt.Errorf("failed to fetch header at block %d: %w", num, err)
+	g, ctx := errgroup.WithContext(ctx)
+	batches := make(chan []*types.Header, 1)
+
+	g.Go(func() error {
+		defer close(batches)
+
+		for from := checkpoint.Number.Uint64() + 1; from <= latest; from += syncBatchSize {
+			to := min(from+syncBatchSize-1, latest)
+			c.log.Debug("download block headers", "from", from, "to", to)
+
+			headers, err := c.fetchHeaders(ctx, from, to)
+			if err != nil {
+				return err
+			}
+
+			select {
+			case batches <- headers:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
 		}
-		if err = c.handleNewBlockHead(head); err != nil {
-			c.log.Warn("failed to handle new block head", "num", head.Number, "hash", head.Hash().Hex(), "err", err)
-			return err
+		return nil
+	})
+
+	g.Go(func() error {
+		for headers := range batches {
+			if err := c.db.PutAll(headers); err != nil {
+				return fmt.Errorf("failed to store block headers: %w", err)
+			}
+			for _, head := range headers {
+				if err := c.publish(ctx, head); err != nil {
+					return err
+				}
+			}
 		}
+		return nil
+	})
+
+	return g.Wait()
+}
+
+// fetchHeaders downloads all block headers in
+// the specified inclusive range concurrently,
+// and returns them in order.
+func (c *MockClient) fetchHeaders(ctx context.Context, from, to uint64) ([]*types.Header, error) {
+	headers := make([]*types.Header, to-from+1)
+
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(syncWorkers)
+	for num := from; num <= to; num++ {
+		g.Go(func() error {
+			head, err := fetchHeader(ctx, c.ec, c.clock, c.log, num)
+			if err != nil {
+				return fmt.Errorf("failed to fetch header at block %d: %w", num, err)
+			}
+			headers[num-from] = head
+			return nil
+		})
 	}
 
-	return nil
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	return headers, nil
 }
 
 // syncNew listens for new block headers and
 // publishes them to the execution layer.
+//
+// If the subscription is lost, e.g., as the
+// connection dropped, syncNew re-subscribes
+// with exponential backoff, and backfills all
+// block headers missed during the outage.
 func (c *MockClient) syncNew(ctx context.Context) error {
 	c.log.Info("start new block sync")
 
+	stall := newWatchdog(c.clock, stallTimeout, func() {
+		c.log.Warn("block sync stalled, no new block head received", "timeout", stallTimeout)
+		c.publishStatus(bus.SyncStateStalled)
+	})
+	stall.Feed()
+	defer stall.Stop()
+
+	b := newBackoff(c.clock, retryBaseDelay, retryMaxDelay)
+	for resumed := false; ; resumed = true {
+		err := c.follow(ctx, b, stall, resumed)
+		if ctx.Err() != nil {
+			c.log.Info("stop block sync")
+			return nil
+		}
+
+		c.log.Warn("block sync interrupted, reconnect", "err", err)
+		c.publishStatus(bus.SyncStateReconnecting)
+		if err = b.Wait(ctx); err != nil {
+			c.log.Info("stop block sync")
+			return nil
+		}
+	}
+}
+
+// follow subscribes to new block headers and
+// publishes them until the subscription fails
+// or the context is canceled. If resumed, all
+// block headers missed since the last published
+// block header are backfilled first.
+func (c *MockClient) follow(ctx context.Context, b *backoff, stall *watchdog, resumed bool) error {
 	headers := make(chan *types.Header)
 
 	sub, err := c.ec.SubscribeNewHead(ctx, headers)
@@ -107,9 +236,22 @@ func (c *MockClient) syncNew(ctx context.Context) error {
 	}
 	defer sub.Unsubscribe()
 
+	if resumed {
+		c.log.Info("subscription resumed, backfill missed block headers", "from", c.last.Load()+1)
+		if err = c.backfill(ctx); err != nil {
+			return fmt.Errorf("failed to backfill: %w", err)
+		}
+	}
+	b.Reset()
+	c.publishStatus(bus.SyncStateFollowing)
+
 	for {
 		select {
 		case head := <-headers:
+			stall.Feed()
+			if c.isKnown(head) {
+				continue
+			}
 			if err = c.handleNewBlockHead(head); err != nil {
 				c.log.Warn("failed to handle new block head", "hash", head.Hash().Hex(), "err", err)
 			}
@@ -117,12 +259,50 @@ func (c *MockClient) syncNew(ctx context.Context) error {
 			c.log.Error("subscription error", "err", err)
 			return err
 		case <-ctx.Done():
-			c.log.Info("stop block sync")
-			return nil
+			return ctx.Err()
 		}
 	}
 }
 
+// backfill fetches and publishes all block headers
+// after the last published block header up to the
+// latest block. Each block header must link to the
+// previous one.
+func (c *MockClient) backfill(ctx context.Context) error {
+	latest, err := c.ec.HeaderByNumber(ctx, big.NewInt(int64(rpc.LatestBlockNumber)))
+	if err != nil {
+		return fmt.Errorf("failed to fetch latest block: %w", err)
+	}
+	prev, err := c.db.GetByNumber(c.last.Load())
+	if err != nil {
+		return fmt.Errorf("failed to get last published header: %w", err)
+	}
+
+	for num := c.last.Load() + 1; num <= latest.Number.Uint64(); num++ {
+		head, err := fetchHeader(ctx, c.ec, c.clock, c.log, num)
+		if err != nil {
+			return fmt.Errorf("failed to fetch header at block %d: %w", num, err)
+		}
+		if head.ParentHash != prev.Hash() {
+			return fmt.Errorf("header at block %d does not link to previous header", num)
+		}
+		if err = c.handleNewBlockHead(head); err != nil {
+			return err
+		}
+		prev = head
+	}
+
+	return nil
+}
+
+// isKnown checks whether the specified block
+// header has already been published, e.g.,
+// by a backfill.
+func (c *MockClient) isKnown(head *types.Header) bool {
+	known, err := c.db.GetByNumber(head.Number.Uint64())
+	return err == nil && known.Hash() == head.Hash()
+}
+
 // handleNewBlockHead processes a new block header.
 func (c *MockClient) handleNewBlockHead(head *types.Header) error {
 	c.log.Info("block sync got new head", "hash", head.Hash())
@@ -134,5 +314,58 @@ func (c *MockClient) handleNewBlockHead(head *types.Header) error {
 	}
 
 	c.pub <- head
+	c.last.Store(head.Number.Uint64())
 	return nil
 }
+
+// publish publishes the specified block header,
+// which must already be stored, unless the context
+// is canceled.
+func (c *MockClient) publish(ctx context.Context, head *types.Header) error {
+	select {
+	case c.pub <- head:
+		c.last.Store(head.Number.Uint64())
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// publishStatus publishes the specified sync
+// state along with the last published block.
+func (c *MockClient) publishStatus(state bus.SyncState) {
+	c.status.Publish(&bus.SyncStatus{
+		State: state,
+		Head:  c.last.Load(),
+	})
+}
+
+// fetchHeader downloads the block header with the
+// specified number, retrying failed downloads with
+// exponential backoff.
+func fetchHeader(ctx context.Context, ec *ethclient.Client, clock mclock.Clock, log log.Logger, num uint64) (*types.Header, error) {
+	var head *types.Header
+	err := retry(ctx, newBackoff(clock, retryBaseDelay, retryMaxDelay), headerFetchAttempts, func() error {
+		var err error
+		if head, err = ec.HeaderByNumber(ctx, new(big.Int).SetUint64(num)); err != nil {
+			log.Debug("failed to download block header", "num", num, "err", err)
+		}
+		return err
+	})
+	return head, err
+}
+
+// fetchHeaderByHash downloads the block header with
+// the specified hash, retrying failed downloads with
+// exponential backoff.
+func fetchHeaderByHash(ctx context.Context, ec *ethclient.Client, clock mclock.Clock, log log.Logger, hash common.Hash) (*types.Header, error) {
+	var head *types.Header
+	err := retry(ctx, newBackoff(clock, retryBaseDelay, retryMaxDelay), headerFetchAttempts, func() error {
+		var err error
+		if head, err = ec.HeaderByHash(ctx, hash); err != nil {
+			log.Debug("failed to download block header", "hash", hash.Hex(), "err", err)
+		}
+		return err
+	})
+	return head, err
+}
@@ -0,0 +1,295 @@
+package sync
+
+import (
+	"context"
+	"log/slog"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sparseth/config"
+	"sparseth/ethstore"
+	"sparseth/internal/log"
+	"sparseth/storage/mem"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/mclock"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// testChainService serves the block
+// headers of a chain via the eth API.
+type testChainService struct {
+	mu    sync.Mutex
+	chain []*types.Header
+	// subs are the active
+	// new head subscriptions.
+	subs map[*rpc.Subscription]*rpc.Notifier
+}
+
+func (s *testChainService) GetBlockByNumber(_ context.Context, num rpc.BlockNumber, _ bool) (*types.Header, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if num == rpc.LatestBlockNumber {
+		num = rpc.BlockNumber(len(s.chain) - 1)
+	}
+	if num < 0 || int(num) >= len(s.chain) {
+		return nil, nil
+	}
+	return s.chain[num], nil
+}
+
+func (s *testChainService) GetBlockByHash(_ context.Context, hash common.Hash, _ bool) (*types.Header, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, head := range s.chain {
+		if head.Hash() == hash {
+			return head, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *testChainService) NewHeads(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, _ := rpc.NotifierFromContext(ctx)
+	sub := notifier.CreateSubscription()
+
+	s.mu.Lock()
+	s.subs[sub] = notifier
+	s.mu.Unlock()
+
+	go func() {
+		<-sub.Err()
+		s.mu.Lock()
+		delete(s.subs, sub)
+		s.mu.Unlock()
+	}()
+	return sub, nil
+}
+
+// extend appends the specified number of
+// block headers to the chain, without
+// notifying subscribers.
+func (s *testChainService) extend(n int) []*types.Header {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Test chains of any length share
+	// the headers of their prefix
+	s.chain = newTestChain(len(s.chain) + n)
+	return s.chain
+}
+
+// notify sends the specified block
+// headers to all subscribers.
+func (s *testChainService) notify(heads ...*types.Header) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for sub, notifier := range s.subs {
+		for _, head := range heads {
+			_ = notifier.Notify(sub.ID, head)
+		}
+	}
+}
+
+// waitForSubscription blocks until the
+// service has exactly one subscription.
+func (s *testChainService) waitForSubscription(t *testing.T) {
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		s.mu.Lock()
+		n := len(s.subs)
+		s.mu.Unlock()
+		if n == 1 {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("expected subscription")
+}
+
+// newTestChain creates a chain of the
+// specified number of block headers.
+func newTestChain(length int) []*types.Header {
+	chain := make([]*types.Header, length)
+	for i := range chain {
+		chain[i] = &types.Header{Number: big.NewInt(int64(i)), Difficulty: common.Big0}
+		if i > 0 {
+			chain[i].ParentHash = chain[i-1].Hash()
+		}
+	}
+	return chain
+}
+
+// newTestChainServer creates a server with a
+// chain service of the specified length.
+func newTestChainServer(t *testing.T, length int) (*testChainService, *rpc.Server) {
+	service := &testChainService{
+		chain: newTestChain(length),
+		subs:  make(map[*rpc.Subscription]*rpc.Notifier),
+	}
+
+	server := rpc.NewServer()
+	if err := server.RegisterName("eth", service); err != nil {
+		t.Fatalf("failed to register service: %v", err)
+	}
+	t.Cleanup(server.Stop)
+
+	return service, server
+}
+
+func newTestChainClient(t *testing.T, length int) ([]*types.Header, *rpc.Client) {
+	service, server := newTestChainServer(t, length)
+	return service.chain, rpc.DialInProc(server)
+}
+
+func TestMockClient_SyncUp(t *testing.T) {
+	t.Run("should publish and store all headers in order", func(t *testing.T) {
+		length := 2*syncBatchSize + 10
+		chain, rc := newTestChainClient(t, length)
+		defer rc.Close()
+
+		db := mem.New()
+		defer db.Close()
+
+		c, ch := NewMockClient(log.New(slog.DiscardHandler), rc, &config.Checkpoint{Hash: chain[0].Hash()}, db, mclock.System{}, nil)
+
+		received := make([]*types.Header, 0, length-1)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for head := range ch {
+				received = append(received, head)
+			}
+		}()
+
+		err := c.syncUp(t.Context(), uint64(length-1))
+		close(c.pub)
+		<-done
+
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(received) != length-1 {
+			t.Fatalf("expected %d headers, got %d", length-1, len(received))
+		}
+		for i, head := range received {
+			if head.Hash() != chain[i+1].Hash() {
+				t.Fatalf("expected header %d at position %d, got %d", i+1, i, head.Number)
+			}
+		}
+
+		store := ethstore.NewHeaderStore(db)
+		last, err := store.GetByNumber(uint64(length - 1))
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if last.Hash() != chain[length-1].Hash() {
+			t.Errorf("expected hash %s, got %s", chain[length-1].Hash(), last.Hash())
+		}
+	})
+}
+
+func TestMockClient_SyncNew(t *testing.T) {
+	t.Run("should backfill missed headers once after subscription is lost", func(t *testing.T) {
+		service, server := newTestChainServer(t, 3)
+		srv := httptest.NewUnstartedServer(server.WebsocketHandler([]string{"*"}))
+		// WebSocket connections are hijacked,
+		// so they are closed by the test
+		var conns []net.Conn
+		srv.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+			if state == http.StateHijacked {
+				conns = append(conns, conn)
+			}
+		}
+		srv.Start()
+		defer srv.Close()
+
+		rc, err := rpc.Dial("ws" + strings.TrimPrefix(srv.URL, "http"))
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		defer rc.Close()
+
+		db := mem.New()
+		defer db.Close()
+
+		ctx, cancel := context.WithCancel(t.Context())
+		defer cancel()
+
+		clock := new(mclock.Simulated)
+		c, ch := NewMockClient(log.New(slog.DiscardHandler), rc, &config.Checkpoint{Hash: service.chain[0].Hash()}, db, clock, nil)
+		done := make(chan error)
+		go func() {
+			done <- c.RunContext(ctx)
+		}()
+
+		expect := func(head *types.Header) {
+			t.Helper()
+			select {
+			case got := <-ch:
+				if got.Hash() != head.Hash() {
+					t.Fatalf("expected header %d, got %d", head.Number, got.Number)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatalf("expected header %d", head.Number)
+			}
+		}
+		expect(service.chain[1])
+		expect(service.chain[2])
+		service.waitForSubscription(t)
+
+		// Missed while the subscription is lost
+		chain := service.extend(2)
+		for _, conn := range conns {
+			conn.Close()
+		}
+
+		// Stall watchdog and reconnect backoff
+		clock.WaitForTimers(2)
+		clock.Run(retryBaseDelay)
+
+		expect(chain[3])
+		expect(chain[4])
+		service.waitForSubscription(t)
+
+		// Already backfilled heads are skipped
+		chain = service.extend(1)
+		service.notify(chain[4], chain[5])
+		expect(chain[5])
+
+		cancel()
+		if err = <-done; err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+	})
+}
+
+func TestMockClient_Backfill(t *testing.T) {
+	t.Run("should reject header not linking to previous header", func(t *testing.T) {
+		chain, rc := newTestChainClient(t, 3)
+		defer rc.Close()
+
+		db := mem.New()
+		defer db.Close()
+
+		c, _ := NewMockClient(log.New(slog.DiscardHandler), rc, &config.Checkpoint{Hash: chain[0].Hash()}, db, mclock.System{}, nil)
+		forged := &types.Header{Number: big.NewInt(1), ParentHash: common.HexToHash("0x01"), Difficulty: common.Big0}
+		if err := ethstore.NewHeaderStore(db).Put(forged); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		c.last.Store(1)
+
+		if err := c.backfill(t.Context()); err == nil {
+			t.Errorf("expected error, got nil")
+		}
+	})
+}
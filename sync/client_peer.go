@@ -0,0 +1,215 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"sparseth/bus"
+	"sparseth/config"
+	"sparseth/ethstore"
+	"sparseth/log"
+	"sparseth/storage"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/mclock"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// PeerClient is a consensus client that follows
+// the confirmed block headers served by another
+// sparseth instance via its headers API, instead
+// of a full node, i.e., block headers are only
+// fetched once for several instances.
+//
+// Block headers must link to the checkpoint,
+// so that a faulty instance cannot serve an
+// unrelated chain.
+type PeerClient struct {
+	c      *rpc.Client
+	db     *ethstore.HeaderStore
+	cp     *config.Checkpoint
+	clock  mclock.Clock
+	log    log.Logger
+	pub    chan<- *types.Header
+	status *bus.Topic[*bus.SyncStatus]
+	// last is the last published
+	// block header.
+	last *types.Header
+}
+
+// NewPeerClient creates a new consensus client
+// following the instance served by the specified
+// client, syncing from the specified checkpoint,
+// publishing block headers at the returned channel.
+// Changes of the sync status are published to the
+// specified topic.
+func NewPeerClient(log log.Logger, c *rpc.Client, cp *config.Checkpoint, db storage.KeyValStore, clock mclock.Clock, status *bus.Topic[*bus.SyncStatus]) (*PeerClient, <-chan *types.Header) {
+	ch := make(chan *types.Header, 128)
+
+	return &PeerClient{
+		c:      c,
+		db:     ethstore.NewHeaderStore(db),
+		cp:     cp,
+		clock:  clock,
+		pub:    ch,
+		status: status,
+		log:    log.With("component", "peer-sync-client"),
+	}, ch
+}
+
+// RunContext starts the consensus client, i.e.,
+// block headers are fetched and published until
+// the context is canceled.
+//
+// If the subscription is lost, RunContext
+// re-subscribes with exponential backoff, and
+// backfills all block headers missed.
+func (c *PeerClient) RunContext(ctx context.Context) error {
+	defer close(c.pub)
+
+	checkpoint, err := c.fetch(ctx, "headers_getByHash", c.cp.Hash)
+	if err != nil {
+		return fmt.Errorf("failed to fetch checkpoint block: %w", err)
+	}
+	if err = c.cp.Verify(checkpoint); err != nil {
+		return fmt.Errorf("invalid checkpoint block: %w", err)
+	}
+	if err = c.db.Put(checkpoint); err != nil {
+		return fmt.Errorf("failed to store checkpoint block header: %w", err)
+	}
+	c.last = checkpoint
+
+	b := newBackoff(c.clock, retryBaseDelay, retryMaxDelay)
+	for {
+		err = c.follow(ctx, b)
+		if ctx.Err() != nil {
+			c.log.Info("stop peer block sync")
+			return nil
+		}
+
+		c.log.Warn("peer block sync interrupted, reconnect", "err", err)
+		c.publishStatus(bus.SyncStateReconnecting)
+		if err = b.Wait(ctx); err != nil {
+			c.log.Info("stop peer block sync")
+			return nil
+		}
+	}
+}
+
+// follow subscribes to new block headers, catches
+// up with the latest block header, and publishes
+// new block headers until the subscription fails
+// or the context is canceled.
+func (c *PeerClient) follow(ctx context.Context, b *backoff) error {
+	heads := make(chan *types.Header)
+
+	// Subscribe before catching up, so
+	// that no block header is missed
+	sub, err := c.c.Subscribe(ctx, "headers", heads, "newHeads")
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to new heads: %w", err)
+	}
+	defer sub.Unsubscribe()
+
+	latest, err := c.fetch(ctx, "headers_latest")
+	if err != nil {
+		return fmt.Errorf("failed to fetch latest block: %w", err)
+	}
+
+	c.log.Info("catch up with peer", "num", latest.Number, "hash", latest.Hash().Hex())
+	c.publishStatus(bus.SyncStateSyncing)
+	if err = c.advance(ctx, latest); err != nil {
+		return err
+	}
+	b.Reset()
+	c.publishStatus(bus.SyncStateFollowing)
+
+	for {
+		select {
+		case head := <-heads:
+			if err = c.advance(ctx, head); err != nil {
+				return err
+			}
+		case err = <-sub.Err():
+			return err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// advance publishes all block headers after the
+// last published block header up to the specified
+// block header. A block header replacing published
+// ones, e.g., after a reorg, must link to a stored
+// block header.
+func (c *PeerClient) advance(ctx context.Context, head *types.Header) error {
+	if head.Number.Uint64() <= c.last.Number.Uint64() {
+		if known, err := c.db.GetByNumber(head.Number.Uint64()); err == nil && known.Hash() == head.Hash() {
+			return nil
+		}
+		if _, err := c.db.GetByHash(head.ParentHash); err != nil {
+			return fmt.Errorf("header at block %d does not link to a known header", head.Number.Uint64())
+		}
+		return c.publish(ctx, head)
+	}
+
+	for num := c.last.Number.Uint64() + 1; num < head.Number.Uint64(); num++ {
+		missed, err := c.fetch(ctx, "headers_getByNumber", hexutil.Uint64(num))
+		if err != nil {
+			return fmt.Errorf("failed to fetch header at block %d: %w", num, err)
+		}
+		if err = c.publish(ctx, missed); err != nil {
+			return err
+		}
+	}
+	return c.publish(ctx, head)
+}
+
+// publish stores and publishes the specified
+// block header, which must link to the last
+// published block header, if it follows it.
+func (c *PeerClient) publish(ctx context.Context, head *types.Header) error {
+	if head.Number.Uint64() == c.last.Number.Uint64()+1 && head.ParentHash != c.last.Hash() {
+		return fmt.Errorf("header at block %d does not link to previous header", head.Number.Uint64())
+	}
+
+	if err := c.db.Put(head); err != nil {
+		c.log.Error("failed to store new block header", "num", head.Number, "hash", head.Hash().Hex(), "err", err)
+	}
+
+	select {
+	case c.pub <- head:
+		c.last = head
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// fetch calls the specified method of the headers
+// API, retrying failed calls with exponential
+// backoff.
+func (c *PeerClient) fetch(ctx context.Context, method string, args ...any) (*types.Header, error) {
+	var head *types.Header
+	err := retry(ctx, newBackoff(c.clock, retryBaseDelay, retryMaxDelay), headerFetchAttempts, func() error {
+		err := c.c.CallContext(ctx, &head, method, args...)
+		if err == nil && head == nil {
+			err = ethstore.ErrHeaderNotFound
+		}
+		if err != nil {
+			c.log.Debug("failed to download block header", "method", method, "err", err)
+		}
+		return err
+	})
+	return head, err
+}
+
+// publishStatus publishes the specified sync
+// state along with the last published block.
+func (c *PeerClient) publishStatus(state bus.SyncState) {
+	c.status.Publish(&bus.SyncStatus{
+		State: state,
+		Head:  c.last.Number.Uint64(),
+	})
+}
@@ -0,0 +1,112 @@
+package sync
+
+import (
+	"context"
+	"log/slog"
+	"math/big"
+	"sparseth/config"
+	"sparseth/internal/log"
+	"sparseth/storage/mem"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/mclock"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// testHeadersService serves the block headers
+// of a chain via the headers API.
+type testHeadersService struct {
+	chain []*types.Header
+}
+
+func (s *testHeadersService) Latest() *types.Header {
+	return s.chain[len(s.chain)-1]
+}
+
+func (s *testHeadersService) GetByNumber(num hexutil.Uint64) *types.Header {
+	return s.chain[num]
+}
+
+func (s *testHeadersService) GetByHash(hash common.Hash) *types.Header {
+	for _, head := range s.chain {
+		if head.Hash() == hash {
+			return head
+		}
+	}
+	return nil
+}
+
+func (s *testHeadersService) NewHeads(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, _ := rpc.NotifierFromContext(ctx)
+	return notifier.CreateSubscription(), nil
+}
+
+func newTestHeadersClient(t *testing.T, length int) ([]*types.Header, *rpc.Client) {
+	chain := make([]*types.Header, length)
+	for i := range chain {
+		chain[i] = &types.Header{Number: big.NewInt(int64(i)), Difficulty: common.Big0}
+		if i > 0 {
+			chain[i].ParentHash = chain[i-1].Hash()
+		}
+	}
+
+	server := rpc.NewServer()
+	if err := server.RegisterName("headers", &testHeadersService{chain: chain}); err != nil {
+		t.Fatalf("failed to register service: %v", err)
+	}
+	t.Cleanup(server.Stop)
+
+	return chain, rpc.DialInProc(server)
+}
+
+func TestPeerClient_RunContext(t *testing.T) {
+	t.Run("should publish all headers after checkpoint in order", func(t *testing.T) {
+		chain, rc := newTestHeadersClient(t, 5)
+		defer rc.Close()
+
+		db := mem.New()
+		defer db.Close()
+
+		ctx, cancel := context.WithCancel(t.Context())
+		defer cancel()
+
+		c, ch := NewPeerClient(log.New(slog.DiscardHandler), rc, &config.Checkpoint{Hash: chain[0].Hash()}, db, mclock.System{}, nil)
+		done := make(chan error)
+		go func() {
+			done <- c.RunContext(ctx)
+		}()
+
+		for i := 1; i < len(chain); i++ {
+			head := <-ch
+			if head.Hash() != chain[i].Hash() {
+				t.Fatalf("expected header %d, got %d", i, head.Number)
+			}
+		}
+
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+	})
+}
+
+func TestPeerClient_Advance(t *testing.T) {
+	t.Run("should reject header not linking to previous header", func(t *testing.T) {
+		chain, rc := newTestHeadersClient(t, 3)
+		defer rc.Close()
+
+		db := mem.New()
+		defer db.Close()
+
+		c, _ := NewPeerClient(log.New(slog.DiscardHandler), rc, &config.Checkpoint{Hash: chain[0].Hash()}, db, mclock.System{}, nil)
+		c.last = chain[0]
+
+		forged := &types.Header{Number: big.NewInt(1), ParentHash: common.HexToHash("0x01"), Difficulty: common.Big0}
+		if err := c.advance(t.Context(), forged); err == nil {
+			t.Errorf("expected error, got nil")
+		}
+	})
+}
@@ -0,0 +1,94 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"sparseth/bus"
+	"sparseth/ethstore"
+	"sparseth/log"
+	"sparseth/storage"
+
+	"github.com/ethereum/go-ethereum/common/mclock"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// RangeClient is a consensus client that
+// publishes the block headers of a fixed
+// range of past blocks, and then stops.
+type RangeClient struct {
+	db     *ethstore.HeaderStore
+	ec     *ethclient.Client
+	from   uint64
+	to     uint64
+	clock  mclock.Clock
+	log    log.Logger
+	pub    chan<- *types.Header
+	status *bus.Topic[*bus.SyncStatus]
+}
+
+// NewRangeClient creates a new consensus client
+// publishing the block headers of the specified
+// inclusive range of blocks at the returned
+// channel, which is closed afterward. Changes
+// of the sync status are published to the
+// specified topic.
+func NewRangeClient(log log.Logger, rpc *rpc.Client, from, to uint64, db storage.KeyValStore, clock mclock.Clock, status *bus.Topic[*bus.SyncStatus]) (*RangeClient, <-chan *types.Header) {
+	ch := make(chan *types.Header, 128)
+
+	return &RangeClient{
+		db:     ethstore.NewHeaderStore(db),
+		ec:     ethclient.NewClient(rpc),
+		from:   from,
+		to:     to,
+		clock:  clock,
+		pub:    ch,
+		status: status,
+		log:    log.With("component", "range-sync-client"),
+	}, ch
+}
+
+// RunContext publishes all block headers of
+// the range, or until the context is canceled.
+//
+// The parent of the first block is stored
+// as well, as it is the starting point of
+// the node, but it is not published.
+func (c *RangeClient) RunContext(ctx context.Context) error {
+	defer close(c.pub)
+
+	c.log.Info("start range sync", "from", c.from, "to", c.to)
+	c.status.Publish(&bus.SyncStatus{
+		State: bus.SyncStateSyncing,
+		Head:  c.from,
+	})
+
+	start := c.from
+	if start > 0 {
+		start--
+	}
+
+	for num := start; num <= c.to; num++ {
+		head, err := fetchHeader(ctx, c.ec, c.clock, c.log, num)
+		if err != nil {
+			return fmt.Errorf("failed to fetch header at block %d: %w", num, err)
+		}
+		if err = c.db.Put(head); err != nil {
+			return fmt.Errorf("failed to store header at block %d: %w", num, err)
+		}
+		if num < c.from {
+			continue
+		}
+
+		select {
+		case c.pub <- head:
+		case <-ctx.Done():
+			c.log.Info("stop range sync")
+			return nil
+		}
+	}
+
+	c.log.Info("range sync finished", "from", c.from, "to", c.to)
+	return nil
+}
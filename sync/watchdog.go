@@ -0,0 +1,90 @@
+package sync
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/mclock"
+)
+
+// watchdog invokes a callback whenever it has
+// not been fed for the specified timeout, e.g.,
+// to detect a stalled block sync.
+type watchdog struct {
+	clock   mclock.Clock
+	timeout time.Duration
+	onStall func()
+	timer   mclock.Timer
+	// armed counts how often the watchdog was
+	// fed or stopped, so that a timer that
+	// fires after being replaced is ignored.
+	armed uint64
+	mu    sync.Mutex
+}
+
+// newWatchdog creates a new, stopped watchdog
+// with the specified timeout and callback.
+func newWatchdog(clock mclock.Clock, timeout time.Duration, onStall func()) *watchdog {
+	return &watchdog{
+		clock:   clock,
+		timeout: timeout,
+		onStall: onStall,
+	}
+}
+
+// Feed resets the watchdog timeout. If the
+// watchdog is stopped, it is started.
+func (w *watchdog) Feed() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+	w.armed++
+	w.arm(w.armed)
+}
+
+// Stop stops the watchdog, the callback is
+// not invoked until the watchdog is fed again.
+func (w *watchdog) Stop() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.timer != nil {
+		w.timer.Stop()
+		w.timer = nil
+	}
+	w.armed++
+}
+
+// arm starts the timer of the specified arming
+// of the watchdog. The mutex must be held.
+func (w *watchdog) arm(armed uint64) {
+	w.timer = w.clock.AfterFunc(w.timeout, func() {
+		w.fire(armed)
+	})
+}
+
+// fire invokes the callback and re-arms the
+// watchdog, so the callback is invoked again
+// if the stall persists. Nothing happens if
+// the watchdog was fed or stopped since the
+// specified arming.
+func (w *watchdog) fire(armed uint64) {
+	w.mu.Lock()
+	current := w.armed == armed
+	w.mu.Unlock()
+	if !current {
+		return
+	}
+
+	w.onStall()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.armed == armed {
+		w.arm(armed)
+	}
+}
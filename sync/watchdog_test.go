@@ -0,0 +1,88 @@
+package sync
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/mclock"
+)
+
+func TestWatchdog(t *testing.T) {
+	t.Run("should fire when not fed within timeout", func(t *testing.T) {
+		clock := new(mclock.Simulated)
+
+		stalls := 0
+		w := newWatchdog(clock, time.Minute, func() { stalls++ })
+		w.Feed()
+
+		clock.Run(time.Minute)
+		if stalls != 1 {
+			t.Errorf("expected 1 stall, got %d", stalls)
+		}
+	})
+
+	t.Run("should not fire when fed within timeout", func(t *testing.T) {
+		clock := new(mclock.Simulated)
+
+		stalls := 0
+		w := newWatchdog(clock, time.Minute, func() { stalls++ })
+		w.Feed()
+
+		for i := 0; i < 5; i++ {
+			clock.Run(30 * time.Second)
+			w.Feed()
+		}
+		if stalls != 0 {
+			t.Errorf("expected no stall, got %d", stalls)
+		}
+	})
+
+	t.Run("should fire repeatedly while stalled", func(t *testing.T) {
+		clock := new(mclock.Simulated)
+
+		stalls := 0
+		w := newWatchdog(clock, time.Minute, func() { stalls++ })
+		w.Feed()
+
+		clock.Run(time.Minute)
+		clock.Run(time.Minute)
+		clock.Run(time.Minute)
+		if stalls != 3 {
+			t.Errorf("expected 3 stalls, got %d", stalls)
+		}
+	})
+
+	t.Run("should not fire when stopped", func(t *testing.T) {
+		clock := new(mclock.Simulated)
+
+		stalls := 0
+		w := newWatchdog(clock, time.Minute, func() { stalls++ })
+		w.Feed()
+		w.Stop()
+
+		clock.Run(time.Hour)
+		if stalls != 0 {
+			t.Errorf("expected no stall, got %d", stalls)
+		}
+	})
+
+	t.Run("should not re-arm when fed while firing", func(t *testing.T) {
+		clock := new(mclock.Simulated)
+
+		stalls := 0
+		var w *watchdog
+		w = newWatchdog(clock, time.Minute, func() {
+			stalls++
+			if stalls == 1 {
+				w.Feed()
+			}
+		})
+		w.Feed()
+
+		clock.Run(time.Minute)
+		clock.Run(time.Minute)
+		if stalls != 2 {
+			t.Errorf("expected 2 stalls, got %d", stalls)
+		}
+	})
+}